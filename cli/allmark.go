@@ -7,22 +7,29 @@ package main
 import (
 	"fmt"
 
+	"github.com/andreaskoch/allmark/common/benchmark"
+	"github.com/andreaskoch/allmark/common/budget"
 	"github.com/andreaskoch/allmark/common/config"
-	"github.com/andreaskoch/allmark/common/logger/console"
-	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/shutdown"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
 	"github.com/andreaskoch/allmark/dataaccess/filesystem"
 	"github.com/andreaskoch/allmark/services/initialization"
 	"github.com/andreaskoch/allmark/services/parser"
 	"github.com/andreaskoch/allmark/services/thumbnail"
+	"github.com/andreaskoch/allmark/services/webhooks"
 	"github.com/andreaskoch/allmark/web/server"
 	// "github.com/davecheney/profile"
 	"flag"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
+	"runtime/pprof"
 	"strings"
+	"syscall"
+	"time"
 )
 
 const (
@@ -33,33 +40,74 @@ const (
 	// CommandNameServe contains the name of the serve action
 	CommandNameServe = "serve"
 
+	// CommandNameExport contains the name of the export action
+	CommandNameExport = "export"
+
+	// CommandNameCheckConfig contains the name of the configuration check action
+	CommandNameCheckConfig = "check-config"
+
+	// CommandNameBench contains the name of the benchmark action
+	CommandNameBench = "bench"
+
 	// CommandNameVersion contains the name of the version action
 	CommandNameVersion = "version"
 )
 
 var version = "v0.10.0-dev"
 
+// configurationFileCheckInterval defines how often the configuration file is checked for changes while serving.
+const configurationFileCheckInterval = 2 * time.Second
+
 var (
 	serveFlags       = flag.NewFlagSet("serve-flags", flag.ContinueOnError)
 	secure           = serveFlags.Bool("secure", false, "Use HTTPs only")
-	logLevelOverride = serveFlags.String("loglevel", "", "Log level")
+	logLevelOverride = serveFlags.String("loglevel", "", "Log level, optionally followed by the log levels of modules (e.g. warn,watcher=debug)")
 	reindex          = serveFlags.Bool("reindex", false, "Enable reindexing")
 	livereload       = serveFlags.Bool("livereload", false, "Enable live-reload")
+	exportBaseURL    = serveFlags.String("baseurl", "", "The absolute URL of exported sites (e.g. https://example.com/docs/); without it all links are relative")
+	exportPDF        = serveFlags.Bool("pdf", false, "Add the PDF documents of the items to exported sites (requires wkhtmltopdf)")
+	port             = serveFlags.Int("port", 0, "The port of the HTTP bindings (0 for a random port)")
+	httpsPort        = serveFlags.Int("https-port", 0, "The port of the HTTPS bindings (0 for a random port)")
+	domainName       = serveFlags.String("domain", "", "The domain name (e.g. www.example.com)")
+	basePath         = serveFlags.String("basepath", "", "The path prefix under which allmark is served (e.g. /wiki/)")
+	themeFolder      = serveFlags.String("theme", "", "The path of a theme folder")
+	language         = serveFlags.String("language", "", "The default language of the documents (e.g. en)")
+	authentication   = serveFlags.Bool("auth", false, "Enable authentication (requires HTTPS)")
+	readonly         = serveFlags.Bool("readonly", false, "Disable the write API")
+	profile          = serveFlags.String("profile", "", "The name of the configuration profile (e.g. dev); default: $"+config.ProfileEnvironmentVariable)
+	cpuProfile       = serveFlags.String("cpuprofile", "", "Write a CPU profile of the benchmark to the given file")
+	memProfile       = serveFlags.String("memprofile", "", "Write a heap profile to the given file after the benchmark")
 )
 
+func init() {
+	registerSettingFlags(serveFlags)
+
+	// the usage information is printed by printUsageInformation
+	serveFlags.Usage = func() {}
+}
+
+// exportFolder is the folder the export action writes the site to.
+var exportFolder string
+
 func main() {
 
 	// defer profile.Start(profile.CPUProfile).Stop()
 
-	// Handle CTRL-C
+	// Handle CTRL-C and SIGTERM
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		select {
 		case _ = <-c:
 			{
 				fmt.Println("Stopping")
 
+				// a second signal stops immediately
+				go func() {
+					<-c
+					os.Exit(1)
+				}()
+
 				// Execute shutdown handlers
 				shutdown.Shutdown()
 
@@ -78,6 +126,22 @@ func main() {
 			serve(repositoryPath)
 			return true
 
+		case CommandNameExport:
+			export(repositoryPath, exportFolder)
+			return true
+
+		case CommandNameBench:
+			if !bench(repositoryPath) {
+				os.Exit(1)
+			}
+			return true
+
+		case CommandNameCheckConfig:
+			if !checkConfig(repositoryPath) {
+				os.Exit(1)
+			}
+			return true
+
 		case CommandNameVersion:
 			printVersionInformation()
 			return true
@@ -101,15 +165,21 @@ func parseCommandLineArguments(args []string, commandHandler func(commandName, r
 	}
 
 	commandName := strings.ToLower(remainingArguments[1])
-	remainingArguments = remainingArguments[2:]
+
+	// the flags can be given before and after the repository path
+	positionalArguments, err := parseFlags(serveFlags, remainingArguments[2:])
+	if err != nil {
+		printUsageInformation(args)
+		return
+	}
 
 	// Read the repository path parameters
 	var repositoryPath string
-	if len(remainingArguments) > 0 && !isCommandlineFlag(remainingArguments[0]) {
+	if len(positionalArguments) > 0 {
 
 		// use supplied repository path
-		repositoryPath = remainingArguments[0]
-		remainingArguments = remainingArguments[1:]
+		repositoryPath = positionalArguments[0]
+		positionalArguments = positionalArguments[1:]
 
 		if isFile, _ := fsutil.IsFile(repositoryPath); isFile {
 			repositoryPath = filepath.Dir(repositoryPath)
@@ -122,9 +192,20 @@ func parseCommandLineArguments(args []string, commandHandler func(commandName, r
 
 	}
 
-	// use the rest of the arguments to parse flags
-	if len(remainingArguments) > 0 {
-		serveFlags.Parse(remainingArguments)
+	// the export action expects the target folder after the repository path
+	if commandName == CommandNameExport {
+		if len(positionalArguments) == 0 {
+			printUsageInformation(args)
+			return
+		}
+
+		exportFolder = positionalArguments[0]
+		positionalArguments = positionalArguments[1:]
+	}
+
+	if len(positionalArguments) > 0 {
+		printUsageInformation(args)
+		return
 	}
 
 	// validate the supplied repository paths
@@ -146,8 +227,13 @@ func printUsageInformation(args []string) {
 	fmt.Fprintf(os.Stderr, "%s - %s (Version: %s)\n", executeableName, "The standalone markdown webserver", version)
 	fmt.Fprintf(os.Stderr, "\nUsage:\n%s %s %s\n", executeableName, "<command>", "<repository path>")
 	fmt.Fprintf(os.Stderr, "\nAvailable commands:\n")
-	fmt.Fprintf(os.Stderr, "  %7s  %s\n", CommandNameInit, "Initialize the configuration")
-	fmt.Fprintf(os.Stderr, "  %7s  %s\n", CommandNameServe, "Start serving the supplied repository via HTTP and HTTPs")
+	fmt.Fprintf(os.Stderr, "  %12s  %s\n", CommandNameInit, "Initialize the configuration")
+	fmt.Fprintf(os.Stderr, "  %12s  %s\n", CommandNameServe, "Start serving the supplied repository via HTTP and HTTPs")
+	fmt.Fprintf(os.Stderr, "  %12s  %s\n", CommandNameCheckConfig, "Check the configuration for errors (exits with status 1 if there are any)")
+	fmt.Fprintf(os.Stderr, "  %12s  %s\n", CommandNameBench, "Parse, convert and index the supplied repository once and print the timings and allocations per phase and item type: "+CommandNameBench+" <repository path> [-cpuprofile <file>] [-memprofile <file>]")
+	fmt.Fprintf(os.Stderr, "  %12s  %s\n", CommandNameExport, "Render the supplied repository to static files: "+CommandNameExport+" <repository path> <folder> [-baseurl <url>] [-pdf]")
+	fmt.Fprintf(os.Stderr, "\nFlags (override the environment variables and the configuration file):\n")
+	printFlags(os.Stderr, serveFlags)
 	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "Fork me on GitHub %q\n", "https://github.com/andreaskoch/allmark")
 
@@ -156,40 +242,348 @@ func printUsageInformation(args []string) {
 
 func serve(repositoryPath string) bool {
 
+	configuration, err := getServeConfiguration(repositoryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return false
+	}
+
+	// remove the secrets of environment variables which have been written to files
+	shutdown.Register(config.RemoveSecretFiles)
+
+	// create a logger
+	logger := newLogger(configuration)
+
+	server, _, err := newServer(logger, configuration, repositoryPath, len(configuration.Webhooks) > 0)
+	if err != nil {
+		logger.Error("Unable to instantiate a server. Error: %s", err.Error())
+		return false
+	}
+
+	result := server.Start()
+
+	// apply the changed settings without a restart and report the ones which require a restart
+	reload := func() {
+
+		// keep the current configuration while the configuration file cannot be read (e.g. while it is being edited)
+		if configFile := config.New(configuration.BaseFolder()); fsutil.FileExists(configFile.Filepath()) {
+			if _, err := configFile.Load(); err != nil {
+				logger.Error("Unable to reload the configuration. Error: %s", err)
+				return
+			}
+		}
+
+		updated, err := getServeConfiguration(repositoryPath)
+		if err != nil {
+			logger.Error("Unable to reload the configuration. Error: %s", err)
+			return
+		}
+
+		restartRequired, err := server.Reload(*updated)
+		if err != nil {
+			logger.Error("Unable to reload the configuration. Error: %s", err)
+			return
+		}
+
+		updateLogger(logger, updated)
+		budget.Default.SetLimits(updated.Resources.MemoryLimit(), updated.Resources.TaskLimit())
+
+		for _, setting := range restartRequired {
+			logger.Warn("The setting %q has changed and takes effect after a restart", setting)
+		}
+	}
+
+	// reload the configuration on SIGHUP
+	go func() {
+		reloadSignal := make(chan os.Signal, 1)
+		signal.Notify(reloadSignal, syscall.SIGHUP)
+
+		for _ = range reloadSignal {
+			logger.Info("Reloading the configuration")
+			reload()
+		}
+	}()
+
+	// reload the configuration when the configuration file changes
+	go watchFile(configuration.Filepath(), configurationFileCheckInterval, func() {
+		logger.Info("The configuration file %q has changed", configuration.Filepath())
+		reload()
+	})
+
+	// restart without dropping connections on SIGUSR2
+	go func() {
+		restart := make(chan os.Signal, 1)
+		notifyRestart(restart)
+
+		for _ = range restart {
+			logger.Info("Restarting")
+
+			if err := server.Restart(); err != nil {
+				logger.Error("Unable to restart the server. Error: %s", err)
+				continue
+			}
+
+			// hand over to the new instance
+			shutdown.Shutdown()
+			os.Exit(0)
+		}
+	}()
+
+	if err := <-result; err != nil {
+		logger.Error("%s", err)
+		return false
+	}
+
+	return true
+}
+
+// getServeConfiguration returns the configuration of the repository with the given path (see config.Get)
+// with the values of the ALLMARK_* environment variables and of the command line flags.
+func getServeConfiguration(repositoryPath string) (*config.Config, error) {
+
+	// get the configuration
+	configuration := config.Get(repositoryPath)
+
+	// override the configuration with the selected profile
+	if err := configuration.ApplyProfile(getProfileName()); err != nil {
+		return nil, err
+	}
+
+	// override the configuration with the ALLMARK_* environment variables
+	if err := configuration.ApplyEnvironment(os.Environ()); err != nil {
+		return nil, err
+	}
+
+	// override the configuration with the command line flags
+	if err := applyCommandLineFlags(configuration); err != nil {
+		return nil, err
+	}
+
+	return configuration, nil
+}
+
+// getProfileName returns the name of the configuration profile which is selected on the command line
+// or with the ALLMARK_PROFILE environment variable (empty if no profile is selected).
+func getProfileName() string {
+	if *profile != "" {
+		return *profile
+	}
+
+	return os.Getenv(config.ProfileEnvironmentVariable)
+}
+
+// watchFile calls the given function whenever the modification time or the size of the file with the given path
+// changes (including the creation and the removal of the file). The file is checked in the given interval.
+func watchFile(path string, interval time.Duration, changed func()) {
+
+	getState := func() (modTime time.Time, size int64) {
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+
+		return fileInfo.ModTime(), fileInfo.Size()
+	}
+
+	lastModTime, lastSize := getState()
+	for range time.Tick(interval) {
+		modTime, size := getState()
+		if modTime.Equal(lastModTime) && size == lastSize {
+			continue
+		}
+
+		lastModTime, lastSize = modTime, size
+		changed()
+	}
+}
+
+// export renders the repository with the given path to static files in the given folder.
+func export(repositoryPath, targetFolder string) bool {
+
 	// get the configuration
 	configuration := config.Get(repositoryPath)
 
-	// check if https shall be forced
-	if *secure {
-		configuration.Server.HTTPS.Enabled = true
-		configuration.Server.HTTPS.Force = true
+	// override the configuration with the selected profile
+	if err := configuration.ApplyProfile(getProfileName()); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return false
+	}
+
+	// override the configuration with the ALLMARK_* environment variables
+	if err := configuration.ApplyEnvironment(os.Environ()); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return false
+	}
+
+	// override the configuration with the command line flags
+	if err := applyCommandLineFlags(configuration); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return false
+	}
+
+	// the exported pages are static
+	configuration.Indexing.Enabled = false
+	configuration.LiveReload.Enabled = false
+
+	// the ZIP downloads require a running server, so the exported pages don't link to them
+	configuration.Server.ZIPDownload.Enabled = false
+
+	// the PDF documents are only exported on request because the conversion is slow
+	configuration.Conversion.PDF.Enabled = configuration.Conversion.PDF.Enabled && *exportPDF
+	if *exportPDF && !configuration.Conversion.PDF.IsEnabled() {
+		fmt.Fprintf(os.Stderr, "The PDF documents cannot be exported because PDF conversion is disabled or %q was not found.\n", configuration.Conversion.PDF.Tool())
+		return false
+	}
+
+	// the pages are served under the path of the base URL
+	if *exportBaseURL != "" {
+		baseURL, err := url.Parse(*exportBaseURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "The base URL %q is invalid. Error: %s\n", *exportBaseURL, err)
+			return false
+		}
+
+		configuration.Server.BasePath = baseURL.Path
+	}
+
+	// create a logger
+	logger := newLogger(configuration)
+
+	server, thumbnailConversion, err := newServer(logger, configuration, repositoryPath, false)
+	if err != nil {
+		logger.Error("Unable to instantiate a server. Error: %s", err.Error())
+		return false
+	}
+
+	// wait for the thumbnails of all images
+	if thumbnailConversion != nil {
+		logger.Info("Waiting for the thumbnail conversion")
+		for !thumbnailConversion.Status().InitialConversionCompleted {
+			time.Sleep(100 * time.Millisecond)
+		}
 	}
 
-	// check if indexing is enabled
-	if *reindex {
-		configuration.Indexing.Enabled = true
-		configuration.Indexing.IntervalInSeconds = config.DefaultIndexingIntervalInSeconds
+	targetFolder, err = filepath.Abs(targetFolder)
+	if err != nil {
+		logger.Error("Invalid export folder %q. Error: %s", targetFolder, err)
+		return false
 	}
 
-	// check if live-reload is enabled
-	if *livereload {
-		configuration.LiveReload.Enabled = true
+	if _, err := server.Export(targetFolder, *exportBaseURL); err != nil {
+		logger.Error("Unable to export the repository %q. Error: %s", repositoryPath, err)
+		return false
 	}
 
+	return true
+}
+
+// bench parses, converts and indexes the repository with the given path once and prints the duration and
+// the memory allocations of every phase by item type. Optionally CPU and heap profiles are written.
+func bench(repositoryPath string) bool {
+
+	configuration, err := getServeConfiguration(repositoryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return false
+	}
+
+	// only the phases which are measured run
+	configuration.Indexing.Enabled = false
+	configuration.LiveReload.Enabled = false
+	configuration.Conversion.Thumbnails.Enabled = false
+	configuration.Caching.HTML.Enabled = false
+
 	// create a logger
-	logger := console.New(loglevel.FromString(configuration.LogLevel))
-	if *logLevelOverride != "" {
-		logger = console.New(loglevel.FromString(*logLevelOverride))
+	logger := newLogger(configuration)
+
+	if *cpuProfile != "" {
+		cpuProfileFile, err := os.Create(*cpuProfile)
+		if err != nil {
+			logger.Error("Unable to create the CPU profile %q. Error: %s", *cpuProfile, err)
+			return false
+		}
+
+		defer cpuProfileFile.Close()
+
+		if err := pprof.StartCPUProfile(cpuProfileFile); err != nil {
+			logger.Error("Unable to start the CPU profile. Error: %s", err)
+			return false
+		}
+
+		defer pprof.StopCPUProfile()
+	}
+
+	// the startup includes the scan of the repository
+	startup := benchmark.NewPhase("Startup")
+	var server *server.Server
+	startup.MeasureAll(func() int {
+		server, _, err = newServer(logger, configuration, repositoryPath, false)
+		return 0
+	})
+
+	if err != nil {
+		logger.Error("Unable to instantiate a server. Error: %s", err.Error())
+		return false
+	}
+
+	phases := append([]*benchmark.Phase{startup}, server.Benchmark()...)
+
+	if *memProfile != "" {
+		runtime.GC()
+		if err := writeHeapProfile(*memProfile); err != nil {
+			logger.Error("Unable to write the heap profile %q. Error: %s", *memProfile, err)
+			return false
+		}
+	}
+
+	fmt.Println()
+	if err := benchmark.Write(os.Stdout, phases); err != nil {
+		logger.Error("%s", err)
+		return false
+	}
+
+	return true
+}
+
+// writeHeapProfile writes a heap profile to the file with the given path.
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return pprof.WriteHeapProfile(file)
+}
+
+// newServer creates a server for the repository with the given path and returns it together with the
+// thumbnail conversion service (nil if thumbnails are disabled). Webhooks are only sent if enabled.
+func newServer(logger logger.Logger, configuration *config.Config, repositoryPath string, webhooksEnabled bool) (*server.Server, *thumbnail.ConversionService, error) {
+
+	// custom MIME types (must be registered before the files are indexed)
+	if err := configuration.Web.MIMETypes.Register(); err != nil {
+		logger.Fatal("Unable to register the custom MIME types. Error: %s", err)
 	}
 
 	// data access
+	// the budget of the background services
+	budget.Default.SetLimits(configuration.Resources.MemoryLimit(), configuration.Resources.TaskLimit())
+
 	repository, err := filesystem.NewRepository(logger, repositoryPath, *configuration)
 	if err != nil {
 		logger.Fatal("Unable to create a repository. Error: %s", err)
 	}
 
+	// webhooks
+	if webhooksEnabled {
+		logger.Info("Webhooks: %d", len(configuration.Webhooks))
+		webhooks.NewService(logger, *configuration, repository)
+	}
+
 	// thumbnail index
 	thumbnailIndex := thumbnail.EmptyIndex()
+	var thumbnailConversion *thumbnail.ConversionService
 	if configuration.Conversion.Thumbnails.Enabled {
 
 		thumbnailIndexFilePath := configuration.ThumbnailIndexFilePath()
@@ -202,7 +596,7 @@ func serve(repositoryPath string) bool {
 		thumbnailIndex = thumbnail.NewIndex(logger, thumbnailIndexFilePath, thumbnailFolder)
 
 		// thumbnail conversion service
-		thumbnail.NewConversionService(logger, repository, thumbnailIndex)
+		thumbnailConversion = thumbnail.NewConversionService(logger, repository, thumbnailIndex)
 
 	}
 
@@ -213,24 +607,18 @@ func serve(repositoryPath string) bool {
 	}
 
 	// server
-	server, err := server.New(logger, *configuration, repository, itemParser, thumbnailIndex)
+	server, err := server.New(logger, *configuration, repository, itemParser, thumbnailIndex, thumbnailConversion)
 	if err != nil {
-		logger.Error("Unable to instantiate a server. Error: %s", err.Error())
-		return false
+		return nil, nil, err
 	}
 
-	if result := <-server.Start(); result != nil {
-		logger.Error("%s", result)
-		return false
-	}
-
-	return true
+	return server, thumbnailConversion, nil
 }
 
 func initialize(repositoryPath string) bool {
 
 	config := config.Get(repositoryPath)
-	logger := console.New(loglevel.FromString(config.LogLevel))
+	logger := newLogger(config)
 
 	if success, err := initialization.Initialize(repositoryPath); !success {
 		logger.Error("Error initializing folder %q. Error: %s", repositoryPath, err.Error())
@@ -240,10 +628,35 @@ func initialize(repositoryPath string) bool {
 	return true
 }
 
-func printVersionInformation() {
-	fmt.Println(version)
+// checkConfig prints the problems of the configuration of the repository with the given path
+// and returns false if the configuration has errors.
+func checkConfig(repositoryPath string) bool {
+
+	configFilePath, problems := config.Check(repositoryPath, getProfileName(), os.Environ())
+	if configFilePath != "" {
+		fmt.Printf("Checking %q\n", configFilePath)
+	}
+
+	errors, warnings := 0, 0
+	for _, problem := range problems {
+		fmt.Println(problem)
+
+		if problem.IsWarning {
+			warnings++
+		} else {
+			errors++
+		}
+	}
+
+	if errors == 0 && warnings == 0 {
+		fmt.Println("The configuration is valid.")
+	} else {
+		fmt.Printf("%d error(s), %d warning(s)\n", errors, warnings)
+	}
+
+	return errors == 0
 }
 
-func isCommandlineFlag(argument string) bool {
-	return strings.HasPrefix(argument, "-")
+func printVersionInformation() {
+	fmt.Println(version)
 }
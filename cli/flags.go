@@ -0,0 +1,168 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/andreaskoch/allmark/common/config"
+)
+
+// settingValues contains the values of the configuration settings which were set on the command line (by the name of the setting).
+var settingValues = make(map[string]string)
+
+// settingFlag is a command line flag for a value of the configuration (e.g. -server.http.bindings.0.port).
+type settingFlag struct {
+	setting config.Setting
+}
+
+func (settingFlag *settingFlag) String() string {
+	if settingFlag == nil {
+		return ""
+	}
+
+	return settingFlag.setting.DefaultValue
+}
+
+func (settingFlag *settingFlag) Set(value string) error {
+	settingValues[settingFlag.setting.Name] = value
+	return nil
+}
+
+// IsBoolFlag allows boolean settings to be enabled without a value (e.g. -server.metrics.enabled).
+func (settingFlag *settingFlag) IsBoolFlag() bool {
+	return settingFlag.setting.IsBool
+}
+
+// registerSettingFlags adds a flag for every value of the configuration to the given flag set
+// unless a flag with the same name already exists (e.g. -loglevel).
+func registerSettingFlags(flagSet *flag.FlagSet) {
+	for _, setting := range config.Settings() {
+		if flagSet.Lookup(setting.Name) != nil {
+			continue
+		}
+
+		flagSet.Var(&settingFlag{setting}, setting.Name, "Configuration setting")
+	}
+}
+
+// parseFlags parses the flags of the given command line arguments which can be given before, between
+// and after the positional arguments and returns the positional arguments.
+func parseFlags(flagSet *flag.FlagSet, arguments []string) (positionalArguments []string, err error) {
+	for {
+		if err := flagSet.Parse(arguments); err != nil {
+			return nil, err
+		}
+
+		arguments = flagSet.Args()
+		if len(arguments) == 0 {
+			return positionalArguments, nil
+		}
+
+		positionalArguments = append(positionalArguments, arguments[0])
+		arguments = arguments[1:]
+	}
+}
+
+// isFlagSet returns a flag indicating whether the flag with the given name was set on the command line.
+func isFlagSet(flagSet *flag.FlagSet, name string) bool {
+	isSet := false
+	flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			isSet = true
+		}
+	})
+
+	return isSet
+}
+
+// printFlags prints the flags of the given flag set which are not configuration settings.
+func printFlags(writer io.Writer, flagSet *flag.FlagSet) {
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if _, isSetting := f.Value.(*settingFlag); isSetting {
+			return
+		}
+
+		fmt.Fprintf(writer, "  -%-12s %s\n", f.Name, f.Usage)
+	})
+
+	fmt.Fprintf(writer, "  -%-12s %s\n", "<setting>", "Any value of the configuration file by its path (e.g. -server.http.bindings.0.port 8080, -web.defaultlanguage en)")
+}
+
+// applyCommandLineFlags overrides the given configuration with the values of the command line flags.
+func applyCommandLineFlags(configuration *config.Config) error {
+
+	// the settings of the configuration
+	if err := configuration.ApplySettings(settingValues); err != nil {
+		return err
+	}
+
+	// check if https shall be forced
+	if *secure {
+		configuration.Server.HTTPS.Enabled = true
+		configuration.Server.HTTPS.Force = true
+	}
+
+	// check if indexing is enabled
+	if *reindex {
+		configuration.Indexing.Enabled = true
+		configuration.Indexing.IntervalInSeconds = config.DefaultIndexingIntervalInSeconds
+	}
+
+	// check if live-reload is enabled
+	if *livereload {
+		configuration.LiveReload.Enabled = true
+	}
+
+	// ports
+	if isFlagSet(serveFlags, "port") {
+		configuration.Server.HTTP.Enabled = true
+		for _, binding := range configuration.Server.HTTP.Bindings {
+			binding.Port = *port
+		}
+	}
+
+	if isFlagSet(serveFlags, "https-port") {
+		configuration.Server.HTTPS.Enabled = true
+		for _, binding := range configuration.Server.HTTPS.Bindings {
+			binding.Port = *httpsPort
+		}
+	}
+
+	if *domainName != "" {
+		configuration.Server.DomainName = *domainName
+	}
+
+	if *basePath != "" {
+		configuration.Server.BasePath = *basePath
+	}
+
+	// theme folders on the command line are relative to the working directory
+	if *themeFolder != "" {
+		folder, err := filepath.Abs(*themeFolder)
+		if err != nil {
+			return fmt.Errorf("Invalid theme folder %q. Error: %s", *themeFolder, err)
+		}
+
+		configuration.Theme.Folder = config.ThemeFolders{folder}
+	}
+
+	if *language != "" {
+		configuration.Web.DefaultLanguage = *language
+	}
+
+	if *authentication {
+		configuration.Server.Authentication.Enabled = true
+	}
+
+	if *readonly {
+		configuration.Server.WriteAPI.Enabled = false
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/logfile"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"github.com/andreaskoch/allmark/common/logger/syslog"
+	"github.com/andreaskoch/allmark/common/shutdown"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// logBackend is the backend the log messages of the application logger are written to (see newLogger).
+var logBackend = logger.BackendConsole
+
+// newLogger creates the application logger with the logging settings of the given configuration.
+// The messages are written to the configured backend; the log file or the connection to syslog is
+// closed on shutdown. If the backend cannot be used the messages are written to the standard error output.
+func newLogger(configuration *config.Config) *console.ConsoleLogger {
+	applicationLogger := console.Default()
+
+	var err error
+	logBackend, err = setLogOutput(applicationLogger, configuration)
+	updateLogger(applicationLogger, configuration)
+
+	if err != nil {
+		applicationLogger.Error("%s; the messages are written to the standard error output.", err)
+	}
+
+	return applicationLogger
+}
+
+// setLogOutput sets the output of the given logger to the configured backend and returns the name of the
+// backend. If the backend cannot be used the output is not changed and the console backend is returned.
+func setLogOutput(applicationLogger *console.ConsoleLogger, configuration *config.Config) (string, error) {
+	backend := strings.ToLower(strings.TrimSpace(configuration.Logging.Backend))
+	if backend == "" && configuration.LogFilePath() == "" && syslog.IsJournalStream() {
+		backend = logger.BackendJournald
+	}
+
+	switch backend {
+	case logger.BackendSyslog:
+		settings := configuration.Logging.Syslog
+		writer, err := syslog.New(settings.Network, settings.Address, settings.Tag)
+		if err != nil {
+			return logger.BackendConsole, fmt.Errorf("Unable to connect to syslog. Error: %s", err)
+		}
+
+		applicationLogger.SetOutput(writer)
+		shutdown.Register(writer.Close)
+		return logger.BackendSyslog, nil
+
+	case logger.BackendJournald:
+		applicationLogger.SetOutput(syslog.NewJournalWriter(os.Stderr))
+		return logger.BackendJournald, nil
+	}
+
+	if logFilePath := configuration.LogFilePath(); logFilePath != "" {
+		file := configuration.Logging.File
+		logFile, err := logfile.New(logFilePath, logfile.Options{
+			MaxSize:          int64(file.MaxSizeInMegabytes) * 1024 * 1024,
+			RotationInterval: time.Duration(file.RotationIntervalInHours) * time.Hour,
+			MaxBackups:       file.MaxBackups,
+			MaxAge:           time.Duration(file.MaxAgeInDays) * 24 * time.Hour,
+		})
+
+		if err != nil {
+			return logger.BackendConsole, fmt.Errorf("Unable to open the log file %q. Error: %s", logFilePath, err)
+		}
+
+		applicationLogger.SetOutput(logFile)
+		shutdown.Register(logFile.Close)
+	}
+
+	return logger.BackendConsole, nil
+}
+
+// updateLogger applies the logging settings of the given configuration to the given logger
+// (e.g. when the configuration is reloaded). The -loglevel flag overrides the configured log levels.
+func updateLogger(applicationLogger *console.ConsoleLogger, configuration *config.Config) {
+	logLevel := configuration.LogLevel
+	moduleLevels := configuration.Logging.ModuleLogLevels()
+
+	if *logLevelOverride != "" {
+		overriddenLevel, overriddenModuleLevels := parseLogLevels(*logLevelOverride)
+		if overriddenLevel != "" {
+			logLevel = overriddenLevel
+		}
+
+		for module, level := range overriddenModuleLevels {
+			moduleLevels[module] = level
+		}
+	}
+
+	applicationLogger.SetLevel(loglevel.FromString(logLevel))
+	applicationLogger.SetModuleLevels(moduleLevels)
+	// syslog and the journal record the time and the level of the messages on their own
+	encoder := logger.NewEncoder(configuration.Logging.Format)
+	if _, isText := encoder.(logger.TextEncoder); isText && logBackend != logger.BackendConsole {
+		encoder = logger.MessageEncoder{}
+	}
+
+	applicationLogger.SetEncoder(encoder)
+}
+
+// parseLogLevels returns the log level and the log levels of the modules of the given list
+// (e.g. "warn,watcher=debug,thumbnail=error"). The log level is empty if the list only contains modules.
+func parseLogLevels(list string) (level string, moduleLevels map[string]loglevel.LogLevel) {
+	moduleLevels = make(map[string]loglevel.LogLevel)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		module, moduleLevel, isModule := strings.Cut(entry, "=")
+		if !isModule {
+			level = entry
+			continue
+		}
+
+		moduleLevels[strings.ToLower(strings.TrimSpace(module))] = loglevel.FromString(moduleLevel)
+	}
+
+	return level, moduleLevels
+}
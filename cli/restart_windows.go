@@ -0,0 +1,13 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+)
+
+// notifyRestart does nothing on Windows because the server cannot be restarted without dropping connections.
+func notifyRestart(c chan<- os.Signal) {
+}
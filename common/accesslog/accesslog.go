@@ -0,0 +1,134 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package accesslog formats HTTP access log entries in the
+// Apache common and combined log formats or as JSON.
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The supported access log formats.
+const (
+	FormatCommon   = "common"
+	FormatCombined = "combined"
+	FormatJSON     = "json"
+)
+
+// Entry contains the details of a single request.
+type Entry struct {
+	Time       time.Time
+	RemoteAddr string
+	User       string
+	Method     string
+	URI        string
+	Protocol   string
+	Status     int
+	Bytes      int64
+	Latency    time.Duration
+	Referer    string
+	UserAgent  string
+	RequestID  string
+}
+
+// Format returns the given entry as a single line (without trailing line break) in the given format.
+// Unknown formats fall back to the combined log format.
+func Format(format string, entry Entry) string {
+	switch strings.ToLower(format) {
+	case FormatCommon:
+		return FormatCommonLine(entry)
+
+	case FormatJSON:
+		return FormatJSONLine(entry)
+	}
+
+	return FormatCombinedLine(entry)
+}
+
+// FormatCommonLine returns the given entry in the Apache common log format.
+func FormatCommonLine(entry Entry) string {
+	return fmt.Sprintf("%s - %s [%s] %s %d %s",
+		orDash(entry.RemoteAddr),
+		orDash(entry.User),
+		entry.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(fmt.Sprintf("%s %s %s", entry.Method, entry.URI, entry.Protocol)),
+		entry.Status,
+		formatBytes(entry.Bytes))
+}
+
+// FormatCombinedLine returns the given entry in the Apache combined log format.
+// The request ID is appended as an additional quoted field if the entry has one.
+func FormatCombinedLine(entry Entry) string {
+	line := fmt.Sprintf("%s %s %s",
+		FormatCommonLine(entry),
+		strconv.Quote(orDash(entry.Referer)),
+		strconv.Quote(orDash(entry.UserAgent)))
+
+	if entry.RequestID != "" {
+		line += " " + strconv.Quote(entry.RequestID)
+	}
+
+	return line
+}
+
+// jsonEntry is the JSON representation of an Entry.
+type jsonEntry struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remoteAddr"`
+	User       string  `json:"user,omitempty"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Protocol   string  `json:"protocol"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	LatencyMS  float64 `json:"latencyMs"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"userAgent,omitempty"`
+	RequestID  string  `json:"requestId,omitempty"`
+}
+
+// FormatJSONLine returns the given entry as a JSON object.
+func FormatJSONLine(entry Entry) string {
+	bytes, err := json.Marshal(jsonEntry{
+		Time:       entry.Time.Format(time.RFC3339Nano),
+		RemoteAddr: entry.RemoteAddr,
+		User:       entry.User,
+		Method:     entry.Method,
+		URI:        entry.URI,
+		Protocol:   entry.Protocol,
+		Status:     entry.Status,
+		Bytes:      entry.Bytes,
+		LatencyMS:  float64(entry.Latency) / float64(time.Millisecond),
+		Referer:    entry.Referer,
+		UserAgent:  entry.UserAgent,
+		RequestID:  entry.RequestID,
+	})
+
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+
+	return string(bytes)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+func formatBytes(bytes int64) string {
+	if bytes == 0 {
+		return "-"
+	}
+
+	return strconv.FormatInt(bytes, 10)
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accesslog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func getSampleEntry() Entry {
+	return Entry{
+		Time:       time.Date(2015, time.August, 3, 14, 5, 9, 0, time.UTC),
+		RemoteAddr: "192.168.0.10",
+		User:       "john",
+		Method:     "GET",
+		URI:        "/documents/sample?page=2",
+		Protocol:   "HTTP/1.1",
+		Status:     200,
+		Bytes:      5120,
+		Latency:    1500 * time.Microsecond,
+		Referer:    "http://example.com/",
+		UserAgent:  "curl/7.43.0",
+	}
+}
+
+func Test_FormatCommonLine_SampleEntry_ApacheCommonLogFormatIsReturned(t *testing.T) {
+	// arrange
+	entry := getSampleEntry()
+
+	// act
+	result := FormatCommonLine(entry)
+
+	// assert
+	expected := `192.168.0.10 - john [03/Aug/2015:14:05:09 +0000] "GET /documents/sample?page=2 HTTP/1.1" 200 5120`
+	if result != expected {
+		t.Errorf("FormatCommonLine returned %q; expected %q", result, expected)
+	}
+}
+
+func Test_FormatCombinedLine_NoUserAndNoReferer_DashesAreUsed(t *testing.T) {
+	// arrange
+	entry := getSampleEntry()
+	entry.User = ""
+	entry.Referer = ""
+	entry.Bytes = 0
+
+	// act
+	result := FormatCombinedLine(entry)
+
+	// assert
+	expected := `192.168.0.10 - - [03/Aug/2015:14:05:09 +0000] "GET /documents/sample?page=2 HTTP/1.1" 200 - "-" "curl/7.43.0"`
+	if result != expected {
+		t.Errorf("FormatCombinedLine returned %q; expected %q", result, expected)
+	}
+}
+
+func Test_FormatJSONLine_SampleEntry_ValidJSONIsReturned(t *testing.T) {
+	// arrange
+	entry := getSampleEntry()
+
+	// act
+	result := FormatJSONLine(entry)
+
+	// assert
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(result), &decoded); err != nil {
+		t.Fatalf("FormatJSONLine returned invalid JSON %q. Error: %s", result, err)
+	}
+
+	if decoded["status"] != float64(200) || decoded["latencyMs"] != 1.5 || decoded["user"] != "john" {
+		t.Errorf("FormatJSONLine returned unexpected values: %s", result)
+	}
+}
+
+func Test_Format_UnknownFormat_CombinedFormatIsUsed(t *testing.T) {
+	// arrange
+	entry := getSampleEntry()
+
+	// act
+	result := Format("unknown", entry)
+
+	// assert
+	if result != FormatCombinedLine(entry) {
+		t.Errorf("Format should fall back to the combined log format but returned %q", result)
+	}
+}
+
+func Test_FormatCombinedLine_RequestID_RequestIDIsAppended(t *testing.T) {
+	// arrange
+	entry := getSampleEntry()
+	entry.RequestID = "3f2a9c1d7e5b8a40"
+
+	// act
+	result := FormatCombinedLine(entry)
+
+	// assert
+	expected := `192.168.0.10 - john [03/Aug/2015:14:05:09 +0000] "GET /documents/sample?page=2 HTTP/1.1" 200 5120 "http://example.com/" "curl/7.43.0" "3f2a9c1d7e5b8a40"`
+	if result != expected {
+		t.Errorf("FormatCombinedLine returned %q; expected %q", result, expected)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package benchmark measures the duration and the memory allocations of the phases of the
+// processing of a repository (e.g. the parsing and the conversion of the items) by item type.
+package benchmark
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// numberOfSlowestItems is the number of items which are listed as the slowest items of a phase.
+const numberOfSlowestItems = 5
+
+// Measurement contains the duration and the memory allocations of an action.
+type Measurement struct {
+	Duration       time.Duration
+	Allocations    uint64
+	AllocatedBytes uint64
+}
+
+// Measure executes the given action and returns its duration and memory allocations.
+// The allocations of other goroutines which run at the same time are included.
+func Measure(action func()) Measurement {
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+	startTime := time.Now()
+
+	action()
+
+	duration := time.Since(startTime)
+	runtime.ReadMemStats(&after)
+
+	return Measurement{
+		Duration:       duration,
+		Allocations:    after.Mallocs - before.Mallocs,
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+}
+
+// add adds the given measurement to the current one.
+func (measurement *Measurement) add(other Measurement) {
+	measurement.Duration += other.Duration
+	measurement.Allocations += other.Allocations
+	measurement.AllocatedBytes += other.AllocatedBytes
+}
+
+// NewPhase creates a new phase with the given name (e.g. "Conversion").
+func NewPhase(name string) *Phase {
+	return &Phase{
+		Name: name,
+	}
+}
+
+// Phase contains the measurements of a phase in total, by item type and of its slowest items.
+type Phase struct {
+	Name string
+
+	Total Measurement
+	Items int
+
+	Types        []*Type
+	SlowestItems []Item
+}
+
+// Type contains the measurements of the items of a type (e.g. "document") in a phase.
+type Type struct {
+	Name  string
+	Items int
+
+	Measurement
+}
+
+// Item contains the measurement of a single item in a phase.
+type Item struct {
+	Route string
+	Type  string
+
+	Measurement
+}
+
+// Measure executes the given action for the item with the given route and adds its measurement to the phase.
+// The type of the item is returned by the action because it is not known before some phases (e.g. the parsing).
+func (phase *Phase) Measure(itemRoute string, action func() (itemType string)) {
+	var itemType string
+	measurement := Measure(func() {
+		itemType = action()
+	})
+
+	phase.Add(Item{
+		Route:       itemRoute,
+		Type:        itemType,
+		Measurement: measurement,
+	})
+}
+
+// MeasureAll executes the given action which processes all items of the phase at once (e.g. the scan of the
+// repository) and returns their number. The measurement is not broken down by item type.
+func (phase *Phase) MeasureAll(action func() (items int)) {
+	var items int
+	phase.Total.add(Measure(func() {
+		items = action()
+	}))
+
+	phase.Items += items
+}
+
+// Add adds the given item measurement to the phase.
+func (phase *Phase) Add(item Item) {
+	phase.Total.add(item.Measurement)
+	phase.Items++
+
+	phase.getType(item.Type).Items++
+	phase.getType(item.Type).add(item.Measurement)
+
+	// keep the slowest items (the slowest first)
+	phase.SlowestItems = append(phase.SlowestItems, item)
+	sort.SliceStable(phase.SlowestItems, func(i, j int) bool {
+		return phase.SlowestItems[i].Duration > phase.SlowestItems[j].Duration
+	})
+
+	if len(phase.SlowestItems) > numberOfSlowestItems {
+		phase.SlowestItems = phase.SlowestItems[:numberOfSlowestItems]
+	}
+}
+
+// getType returns the measurements of the given item type.
+func (phase *Phase) getType(name string) *Type {
+	for _, itemType := range phase.Types {
+		if itemType.Name == name {
+			return itemType
+		}
+	}
+
+	itemType := &Type{Name: name}
+	phase.Types = append(phase.Types, itemType)
+	return itemType
+}
+
+// Write writes a table with the measurements of the given phases to the given writer. The item types of every
+// phase are listed in the order of their total duration (the slowest first), followed by the slowest items.
+func Write(writer io.Writer, phases []*Phase) error {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(table, "Phase\tItems\tTime\tTime/Item\tAllocations\tAllocated\n")
+
+	var total Measurement
+	for _, phase := range phases {
+		total.add(phase.Total)
+		writeRow(table, phase.Name, phase.Items, phase.Total)
+
+		types := append([]*Type(nil), phase.Types...)
+		sort.SliceStable(types, func(i, j int) bool {
+			return types[i].Duration > types[j].Duration
+		})
+
+		for _, itemType := range types {
+			writeRow(table, "  "+itemType.Name, itemType.Items, itemType.Measurement)
+		}
+	}
+
+	writeRow(table, "Total", 0, total)
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, phase := range phases {
+		if len(phase.SlowestItems) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(writer, "\nSlowest items (%s):\n", phase.Name)
+		for _, item := range phase.SlowestItems {
+			fmt.Fprintf(writer, "  %10s  %-12s  /%s\n", formatDuration(item.Duration), item.Type, item.Route)
+		}
+	}
+
+	return nil
+}
+
+// writeRow writes a table row with the given measurement.
+func writeRow(writer io.Writer, name string, items int, measurement Measurement) {
+	numberOfItems, durationPerItem := "", ""
+	if items > 0 {
+		numberOfItems = fmt.Sprintf("%d", items)
+		durationPerItem = formatDuration(measurement.Duration / time.Duration(items))
+	}
+
+	fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\n",
+		name,
+		numberOfItems,
+		formatDuration(measurement.Duration),
+		durationPerItem,
+		measurement.Allocations,
+		formatBytes(measurement.AllocatedBytes))
+}
+
+// formatDuration returns the given duration rounded to a precision which fits its magnitude.
+func formatDuration(duration time.Duration) string {
+	switch {
+	case duration >= time.Second:
+		return duration.Round(time.Millisecond).String()
+	case duration >= time.Millisecond:
+		return duration.Round(10 * time.Microsecond).String()
+	default:
+		return duration.Round(100 * time.Nanosecond).String()
+	}
+}
+
+// formatBytes returns the given number of bytes in a human-readable unit (e.g. "12.3 MB").
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	divisor, exponent := uint64(unit), 0
+	for value := bytes / unit; value >= unit; value /= unit {
+		divisor *= unit
+		exponent++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(divisor), "KMGTPE"[exponent])
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmark
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Add_SeveralItemTypes_MeasurementsAreGroupedByType(t *testing.T) {
+	// arrange
+	phase := NewPhase("Conversion")
+
+	// act
+	phase.Add(Item{Route: "a", Type: "document", Measurement: Measurement{Duration: time.Millisecond, Allocations: 10}})
+	phase.Add(Item{Route: "b", Type: "presentation", Measurement: Measurement{Duration: 5 * time.Millisecond, Allocations: 20}})
+	phase.Add(Item{Route: "c", Type: "document", Measurement: Measurement{Duration: 2 * time.Millisecond, Allocations: 30}})
+
+	// assert
+	if phase.Items != 3 || phase.Total.Duration != 8*time.Millisecond || phase.Total.Allocations != 60 {
+		t.Errorf("The total of the phase should contain all items but was %#v (%d items).", phase.Total, phase.Items)
+	}
+
+	if documents := phase.getType("document"); documents.Items != 2 || documents.Duration != 3*time.Millisecond {
+		t.Errorf("The documents should have been measured together but the measurement was %#v.", documents)
+	}
+
+	if phase.SlowestItems[0].Route != "b" {
+		t.Errorf("The slowest item should be listed first but the slowest items were %#v.", phase.SlowestItems)
+	}
+}
+
+func Test_Write_PhasesWithItemTypes_TypesAreListedBelowThePhase(t *testing.T) {
+	// arrange
+	phase := NewPhase("Parsing")
+	phase.Add(Item{Route: "a", Type: "document", Measurement: Measurement{Duration: time.Millisecond}})
+	buffer := new(bytes.Buffer)
+
+	// act
+	Write(buffer, []*Phase{phase})
+
+	// assert
+	output := buffer.String()
+	if !strings.Contains(output, "Parsing") || !strings.Contains(output, "  document") || !strings.Contains(output, "/a") {
+		t.Errorf("The output should contain the phase, its item types and its slowest items but was:\n%s", output)
+	}
+}
+
+func Test_formatBytes_Megabytes_UnitIsMB(t *testing.T) {
+	// act
+	result := formatBytes(3 * 1024 * 1024 / 2)
+
+	// assert
+	if result != "1.5 MB" {
+		t.Errorf("The result should be %q but was %q.", "1.5 MB", result)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package budget limits the memory and the number of tasks which the background services
+// (e.g. the thumbnail conversion and the search indexing) use at the same time.
+// Tasks which would exceed the budget wait until other tasks have finished.
+package budget
+
+import (
+	"github.com/andreaskoch/allmark/common/metrics"
+	"sync"
+)
+
+// The names of the tasks which share the budget.
+const (
+	TaskThumbnail  = "thumbnail"
+	TaskSearch     = "search"
+	TaskConversion = "conversion"
+)
+
+var (
+	memoryInUse = metrics.NewGauge(
+		"allmark_budget_memory_bytes",
+		"Estimated memory in bytes which is used by the running background tasks by task.",
+		"task")
+
+	tasksInUse = metrics.NewGauge(
+		"allmark_budget_tasks",
+		"Number of running background tasks by task.",
+		"task")
+
+	waitingTasks = metrics.NewGauge(
+		"allmark_budget_waiting_tasks",
+		"Number of background tasks which are waiting for a free budget.")
+
+	memoryLimit = metrics.NewGauge(
+		"allmark_budget_memory_limit_bytes",
+		"Memory budget in bytes of the background tasks (0 means unlimited).")
+
+	taskLimit = metrics.NewGauge(
+		"allmark_budget_task_limit",
+		"Maximum number of background tasks which run at the same time (0 means unlimited).")
+)
+
+// Default is the budget which is shared by all background services.
+var Default = New(0, 0)
+
+// Acquire reserves the given memory (in bytes) for a task in the default budget (see Budget.Acquire).
+func Acquire(task string, memory int64) (release func()) {
+	return Default.Acquire(task, memory)
+}
+
+// New creates a new budget with the given memory limit in bytes and the given maximum number of tasks
+// which run at the same time; 0 means unlimited.
+func New(maxMemory int64, maxTasks int) *Budget {
+	budget := &Budget{
+		memory: make(map[string]int64),
+		tasks:  make(map[string]int),
+	}
+
+	budget.cond = sync.NewCond(&budget.lock)
+	budget.SetLimits(maxMemory, maxTasks)
+	return budget
+}
+
+// Budget is a memory and concurrency budget which is shared by several tasks.
+type Budget struct {
+	lock sync.Mutex
+	cond *sync.Cond
+
+	maxMemory int64
+	maxTasks  int
+
+	memory  map[string]int64
+	tasks   map[string]int
+	waiting int
+}
+
+// Usage contains the memory and the number of tasks which are currently in use.
+type Usage struct {
+	Memory  int64
+	Tasks   int
+	Waiting int
+}
+
+// SetLimits changes the memory limit in bytes and the maximum number of tasks; 0 means unlimited.
+// Waiting tasks are started if the new limits permit it.
+func (budget *Budget) SetLimits(maxMemory int64, maxTasks int) {
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	budget.maxMemory = maxMemory
+	budget.maxTasks = maxTasks
+
+	memoryLimit.Set(float64(maxMemory))
+	taskLimit.Set(float64(maxTasks))
+
+	budget.cond.Broadcast()
+}
+
+// Acquire reserves the given (estimated) memory in bytes for a task with the given name (e.g. "thumbnail") and
+// waits until the budget permits it. Tasks which need more memory than the whole budget run when no other
+// task is running. The returned function must be called when the task has finished.
+func (budget *Budget) Acquire(task string, memory int64) (release func()) {
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	if memory < 0 {
+		memory = 0
+	}
+
+	budget.waiting++
+	waitingTasks.Set(float64(budget.waiting))
+
+	for !budget.permits(memory) {
+		budget.cond.Wait()
+	}
+
+	budget.waiting--
+	waitingTasks.Set(float64(budget.waiting))
+
+	budget.memory[task] += memory
+	budget.tasks[task]++
+	budget.updateMetrics(task)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			budget.lock.Lock()
+			defer budget.lock.Unlock()
+
+			budget.memory[task] -= memory
+			budget.tasks[task]--
+			budget.updateMetrics(task)
+
+			budget.cond.Broadcast()
+		})
+	}
+}
+
+// Usage returns the memory and the number of tasks which are currently in use.
+func (budget *Budget) Usage() Usage {
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	usage := Usage{Waiting: budget.waiting}
+	for task := range budget.tasks {
+		usage.Memory += budget.memory[task]
+		usage.Tasks += budget.tasks[task]
+	}
+
+	return usage
+}
+
+// permits returns true if a task with the given memory can be started.
+func (budget *Budget) permits(memory int64) bool {
+	var usedMemory int64
+	var runningTasks int
+	for task := range budget.tasks {
+		usedMemory += budget.memory[task]
+		runningTasks += budget.tasks[task]
+	}
+
+	if budget.maxTasks > 0 && runningTasks >= budget.maxTasks {
+		return false
+	}
+
+	if budget.maxMemory > 0 && runningTasks > 0 && usedMemory+memory > budget.maxMemory {
+		return false
+	}
+
+	return true
+}
+
+// updateMetrics updates the metrics of the given task.
+func (budget *Budget) updateMetrics(task string) {
+	memoryInUse.Set(float64(budget.memory[task]), task)
+	tasksInUse.Set(float64(budget.tasks[task]), task)
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Acquire_MemoryLimitExceeded_TaskWaitsForRelease(t *testing.T) {
+	// arrange
+	budget := New(100, 0)
+	release := budget.Acquire(TaskThumbnail, 80)
+
+	started := make(chan bool)
+	go func() {
+		releaseSecond := budget.Acquire(TaskSearch, 40)
+		defer releaseSecond()
+		started <- true
+	}()
+
+	// act
+	select {
+	case <-started:
+		t.Fatalf("The second task should wait until the memory of the first task is released.")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	// assert
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("The second task should have been started after the first task was released.")
+	}
+}
+
+func Test_Acquire_MoreMemoryThanTheBudget_TaskRunsAlone(t *testing.T) {
+	// arrange
+	budget := New(100, 0)
+
+	// act
+	release := budget.Acquire(TaskConversion, 500)
+	usage := budget.Usage()
+	release()
+
+	// assert
+	if usage.Tasks != 1 || usage.Memory != 500 {
+		t.Errorf("A task which exceeds the budget should run when no other task is running but the usage was %#v.", usage)
+	}
+
+	if usage := budget.Usage(); usage.Tasks != 0 || usage.Memory != 0 {
+		t.Errorf("The budget should be free after the release but the usage was %#v.", usage)
+	}
+}
+
+func Test_SetLimits_HigherTaskLimit_WaitingTaskIsStarted(t *testing.T) {
+	// arrange
+	budget := New(0, 1)
+	release := budget.Acquire(TaskThumbnail, 0)
+	defer release()
+
+	started := make(chan bool)
+	go func() {
+		releaseSecond := budget.Acquire(TaskThumbnail, 0)
+		defer releaseSecond()
+		started <- true
+	}()
+
+	// act
+	time.Sleep(20 * time.Millisecond)
+	budget.SetLimits(0, 2)
+
+	// assert
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("The waiting task should have been started after the task limit was raised.")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"strings"
+)
+
+// AuthenticationRule defines the authentication requirements for all routes with a given prefix.
+type AuthenticationRule struct {
+	// Route is the route prefix this rule applies to (e.g. "/private", "/private/**").
+	// The rule also applies to all alternative representations of the route (e.g. "/private.json").
+	Route string
+
+	// Public is a flag indicating whether the routes can be accessed without authentication.
+	Public bool
+
+	// Users is the list of users that are allowed to access the routes.
+	Users []string
+
+	// Groups is the list of groups whose users are allowed to access the routes.
+	// If neither users nor groups are specified all authenticated users can access the routes.
+	Groups []string
+}
+
+// normalizedRoute returns the route prefix of the rule without wildcards and trailing slashes (e.g. "/private").
+func (rule AuthenticationRule) normalizedRoute() string {
+	return normalizeRoutePrefix(rule.Route)
+}
+
+// Matches returns a flag indicating whether the given request path is covered by the current rule.
+func (rule AuthenticationRule) Matches(path string) bool {
+	return routePrefixMatches(rule.normalizedRoute(), path)
+}
+
+// normalizeRoutePrefix removes wildcards and trailing slashes from the given route prefix (e.g. "/private/**" → "/private").
+func normalizeRoutePrefix(route string) string {
+	route = strings.TrimSpace(route)
+	route = strings.TrimSuffix(route, "**")
+	route = strings.TrimSuffix(route, "*")
+	route = strings.TrimRight(route, "/")
+
+	if !strings.HasPrefix(route, "/") {
+		route = "/" + route
+	}
+
+	return route
+}
+
+// routePrefixMatches returns a flag indicating whether the given request path is covered by the given
+// normalized route prefix, including all alternative representations of the route (e.g. "/private.json").
+func routePrefixMatches(prefix, path string) bool {
+	if prefix == "/" {
+		return true
+	}
+
+	return path == prefix || strings.HasPrefix(path, prefix+"/") || strings.HasPrefix(path, prefix+".")
+}
+
+// GetRule returns the most specific authentication rule for the given request path
+// and a flag indicating whether the path requires authentication.
+func (authentication *Authentication) GetRule(path string) (rule AuthenticationRule, requiresAuthentication bool) {
+
+	// all routes require authentication if there are no rules
+	if len(authentication.Rules) == 0 {
+		return AuthenticationRule{Route: "/"}, true
+	}
+
+	matchFound := false
+	for _, candidate := range authentication.Rules {
+		if !candidate.Matches(path) {
+			continue
+		}
+
+		if matchFound && len(candidate.normalizedRoute()) <= len(rule.normalizedRoute()) {
+			continue
+		}
+
+		rule = candidate
+		matchFound = true
+	}
+
+	if !matchFound {
+		return AuthenticationRule{}, false
+	}
+
+	return rule, !rule.Public
+}
+
+// IsAuthorized returns a flag indicating whether the user with the given name is allowed to access the routes of the given rule.
+func (authentication *Authentication) IsAuthorized(rule AuthenticationRule, username string) bool {
+	if rule.Public {
+		return true
+	}
+
+	if username == "" {
+		return false
+	}
+
+	// all authenticated users are allowed
+	if len(rule.Users) == 0 && len(rule.Groups) == 0 {
+		return true
+	}
+
+	for _, user := range rule.Users {
+		if user == username {
+			return true
+		}
+	}
+
+	for _, group := range rule.Groups {
+		for _, member := range authentication.Groups[group] {
+			if member == username {
+				return true
+			}
+		}
+	}
+
+	return false
+}
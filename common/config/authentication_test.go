@@ -0,0 +1,100 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func Test_GetRule_NoRules_AuthenticationIsRequired(t *testing.T) {
+	// arrange
+	authentication := Authentication{}
+
+	// act
+	_, requiresAuthentication := authentication.GetRule("/documents/sample")
+
+	// assert
+	if !requiresAuthentication {
+		t.Errorf("All routes should require authentication if no rules are configured.")
+	}
+}
+
+func Test_GetRule_PrivatePrefix_OnlyPrivateRoutesRequireAuthentication(t *testing.T) {
+	// arrange
+	authentication := Authentication{
+		Rules: []AuthenticationRule{
+			{Route: "/private/**"},
+		},
+	}
+
+	inputs := map[string]bool{
+		"/":                     false,
+		"/documents/sample":     false,
+		"/privateer":            false,
+		"/private":              true,
+		"/private/":             true,
+		"/private.json":         true,
+		"/private/notes/a.json": true,
+	}
+
+	for path, expected := range inputs {
+
+		// act
+		_, requiresAuthentication := authentication.GetRule(path)
+
+		// assert
+		if requiresAuthentication != expected {
+			t.Errorf("GetRule(%q) should return %v but returned %v.", path, expected, requiresAuthentication)
+		}
+	}
+}
+
+func Test_GetRule_PublicSubRoute_MostSpecificRuleWins(t *testing.T) {
+	// arrange
+	authentication := Authentication{
+		Rules: []AuthenticationRule{
+			{Route: "/"},
+			{Route: "/public", Public: true},
+		},
+	}
+
+	// act
+	_, publicRouteRequiresAuthentication := authentication.GetRule("/public/sample")
+	_, otherRouteRequiresAuthentication := authentication.GetRule("/other")
+
+	// assert
+	if publicRouteRequiresAuthentication {
+		t.Errorf("The route %q should not require authentication.", "/public/sample")
+	}
+
+	if !otherRouteRequiresAuthentication {
+		t.Errorf("The route %q should require authentication.", "/other")
+	}
+}
+
+func Test_IsAuthorized_UserInGroup_UserIsAuthorized(t *testing.T) {
+	// arrange
+	authentication := Authentication{
+		Groups: map[string][]string{
+			"editors": {"jane"},
+		},
+	}
+
+	rule := AuthenticationRule{Route: "/private", Users: []string{"john"}, Groups: []string{"editors"}}
+
+	// act
+	janeIsAuthorized := authentication.IsAuthorized(rule, "jane")
+	johnIsAuthorized := authentication.IsAuthorized(rule, "john")
+	joeIsAuthorized := authentication.IsAuthorized(rule, "joe")
+
+	// assert
+	if !janeIsAuthorized || !johnIsAuthorized {
+		t.Errorf("The users %q and %q should be authorized.", "jane", "john")
+	}
+
+	if joeIsAuthorized {
+		t.Errorf("The user %q should not be authorized.", "joe")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+)
+
+// CacheControl contains the Cache-Control policies for the different types of responses.
+// Policies which are not configured use the built-in defaults, which depend on the reindexing interval.
+type CacheControl struct {
+	// Items is the policy for the HTML pages of the items (including the print and reader views, the sitemap and the tags page).
+	Items CachePolicy
+
+	// JSON is the policy for the JSON representations of the items, the titles, the latest items and the type-ahead search.
+	JSON CachePolicy
+
+	// RSS is the policy for the RSS feeds.
+	RSS CachePolicy
+
+	// Thumbnails is the policy for the thumbnail images.
+	Thumbnails CachePolicy
+
+	// Files is the policy for the files that are attached to the items.
+	Files CachePolicy
+
+	// Theme is the policy for the theme assets (stylesheets, scripts, images).
+	Theme CachePolicy
+}
+
+// CachePolicy defines the Cache-Control header for a type of response.
+type CachePolicy struct {
+	// MaxAgeInSeconds defines for how long the response can be cached without revalidation.
+	MaxAgeInSeconds int
+
+	// NoCache is flag indicating whether caches must revalidate the response before every use.
+	NoCache bool
+
+	// Private is flag indicating whether only the browser (but no shared cache) may store the response.
+	Private bool
+
+	// Immutable is flag indicating whether the response will never change while it is fresh
+	// (e.g. for fingerprinted theme assets), so browsers don't need to revalidate it on reloads.
+	Immutable bool
+}
+
+// IsConfigured returns a flag indicating whether the policy has been configured.
+// An empty policy means that the built-in default applies.
+func (policy CachePolicy) IsConfigured() bool {
+	return policy.MaxAgeInSeconds > 0 || policy.NoCache || policy.Private || policy.Immutable
+}
+
+// Header returns the value of the Cache-Control header for the policy.
+func (policy CachePolicy) Header() string {
+	visibility := "public"
+	if policy.Private {
+		visibility = "private"
+	}
+
+	if policy.NoCache {
+		return visibility + ", no-cache"
+	}
+
+	value := fmt.Sprintf("%s, max-age=%d", visibility, policy.MaxAgeInSeconds)
+	if policy.Immutable {
+		value += ", immutable"
+	}
+
+	return value
+}
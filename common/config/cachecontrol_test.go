@@ -0,0 +1,46 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func Test_Header_CachePolicies_CacheControlValuesAreReturned(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		policy   CachePolicy
+		expected string
+	}{
+		{CachePolicy{MaxAgeInSeconds: 300}, "public, max-age=300"},
+		{CachePolicy{MaxAgeInSeconds: 31536000, Immutable: true}, "public, max-age=31536000, immutable"},
+		{CachePolicy{MaxAgeInSeconds: 60, Private: true}, "private, max-age=60"},
+		{CachePolicy{NoCache: true, MaxAgeInSeconds: 60}, "public, no-cache"},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result := input.policy.Header()
+
+		// assert
+		if result != input.expected {
+			t.Errorf("Header() returned %q for %+v; expected %q", result, input.policy, input.expected)
+		}
+	}
+}
+
+func Test_IsConfigured_EmptyPolicy_ResultIsFalse(t *testing.T) {
+	// arrange
+	policy := CachePolicy{}
+
+	// act
+	result := policy.IsConfigured()
+
+	// assert
+	if result {
+		t.Errorf("An empty cache policy should not be considered as configured.")
+	}
+}
@@ -0,0 +1,712 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// certificateExpiryWarningPeriod is the remaining validity of a certificate below which Check warns about its expiration.
+const certificateExpiryWarningPeriod = 30 * 24 * time.Hour
+
+// logLevelNames contains the names of the log levels which can be configured.
+var logLevelNames = []string{"off", "debug", "info", "statistics", "warn", "error", "fatal"}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+var profileType = reflect.TypeOf(Profile{})
+
+// A Problem is an error or a warning which was found in a configuration.
+type Problem struct {
+	// Setting is the path of the setting (e.g. "Server.HTTP.Bindings[0].Port");
+	// it is empty if the problem concerns the configuration as a whole.
+	Setting string
+
+	Message string
+
+	// IsWarning is flag indicating whether the problem does not prevent the setting from being used as configured.
+	IsWarning bool
+}
+
+func (problem Problem) String() string {
+	severity := "error"
+	if problem.IsWarning {
+		severity = "warning"
+	}
+
+	if problem.Setting == "" {
+		return fmt.Sprintf("%s: %s", severity, problem.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", severity, problem.Setting, problem.Message)
+}
+
+// HasErrors returns true if at least one of the given problems is an error.
+func HasErrors(problems []Problem) bool {
+	for _, problem := range problems {
+		if !problem.IsWarning {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check locates the configuration of the given repository the same way Get does, applies the profile with the given
+// name (see ApplyProfile; optional) and the given environment variables (see ApplyEnvironment) and returns the path of the configuration file (empty if the default configuration
+// is used) and the problems of the configuration: invalid JSON, values of the wrong type, unknown settings (which
+// allmark ignores), missing files and folders, ports which are not available, certificates which do not match their
+// keys and authentication settings which cannot work.
+func Check(baseFolder, profile string, environment []string) (configFilePath string, problems []Problem) {
+
+	config := New(baseFolder)
+	if !fsutil.FileExists(config.Filepath()) && !isHomeDir(baseFolder) {
+		if globalConfig := New(homeDirectory()); fsutil.FileExists(globalConfig.Filepath()) {
+			config = globalConfig
+		}
+	}
+
+	if configFilePath = config.Filepath(); !fsutil.FileExists(configFilePath) {
+		configFilePath = ""
+		config = Default(baseFolder)
+		problems = append(problems, Problem{
+			Message:   "No configuration file was found; the default configuration is used.",
+			IsWarning: true,
+		})
+
+	} else {
+
+		data, err := os.ReadFile(configFilePath)
+		if err != nil {
+			return configFilePath, append(problems, Problem{Message: fmt.Sprintf("Cannot read the configuration file. Error: %s", err)})
+		}
+
+		fileProblems := checkConfigFile(data)
+		problems = append(problems, fileProblems...)
+		if HasErrors(fileProblems) && !isReadable(data) {
+			return configFilePath, append(problems, Problem{Message: "allmark cannot read the configuration file and uses the default configuration instead."})
+		}
+
+		if _, err := config.Load(); err != nil {
+			return configFilePath, append(problems, Problem{Message: err.Error()})
+		}
+	}
+
+	if err := config.ApplyProfile(profile); err != nil {
+		problems = append(problems, Problem{Setting: "Profiles", Message: err.Error()})
+	}
+
+	if err := config.ApplyEnvironment(environment); err != nil {
+		problems = append(problems, Problem{Message: err.Error()})
+	}
+
+	return configFilePath, append(problems, config.check()...)
+}
+
+// isReadable returns true if the given configuration file can be deserialized.
+func isReadable(data []byte) bool {
+	_, err := NewJSONSerializer().DeserializeConfig(bytes.NewReader(data))
+	return err == nil
+}
+
+// checkConfigFile returns the syntax errors, the values of the wrong type and the unknown settings of the given configuration file.
+func checkConfigFile(data []byte) []Problem {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		if syntaxError, isSyntaxError := err.(*json.SyntaxError); isSyntaxError {
+			line, column := getLineAndColumn(data, syntaxError.Offset)
+			return []Problem{{Message: fmt.Sprintf("The configuration file is not valid JSON (line %d, column %d): %s.", line, column, syntaxError)}}
+		}
+
+		return []Problem{{Message: fmt.Sprintf("The configuration file is not valid JSON: %s.", err)}}
+	}
+
+	return checkJSONValue("", document, reflect.TypeOf(Config{}))
+}
+
+// getLineAndColumn returns the line and column (starting at 1) of the given byte offset of the given data.
+func getLineAndColumn(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// checkJSONValue returns the problems of the given JSON value of the setting with the given path and type.
+func checkJSONValue(path string, value interface{}, valueType reflect.Type) []Problem {
+	if value == nil {
+		return nil
+	}
+
+	// profiles contain the settings of the configuration
+	if valueType == profileType {
+		if _, isObject := value.(map[string]interface{}); !isObject {
+			return []Problem{getTypeProblem(path, "an object", value)}
+		}
+
+		return checkJSONValue(path, value, reflect.TypeOf(Config{}))
+	}
+
+	// types with a JSON representation of their own (e.g. the theme folders)
+	if reflect.PtrTo(valueType).Implements(jsonUnmarshalerType) {
+		data, _ := json.Marshal(value)
+		if err := json.Unmarshal(data, reflect.New(valueType).Interface()); err != nil {
+			return []Problem{{Setting: path, Message: err.Error()}}
+		}
+
+		return nil
+	}
+
+	var problems []Problem
+	switch valueType.Kind() {
+
+	case reflect.Ptr:
+		return checkJSONValue(path, value, valueType.Elem())
+
+	case reflect.Struct:
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return []Problem{getTypeProblem(path, "an object", value)}
+		}
+
+		for _, key := range getSortedKeys(object) {
+			field, found := findJSONField(valueType, key)
+			if !found {
+				problems = append(problems, Problem{Setting: joinSettingPath(path, key), Message: "This setting does not exist and is ignored."})
+				continue
+			}
+
+			problems = append(problems, checkJSONValue(joinSettingPath(path, field.Name), object[key], field.Type)...)
+		}
+
+	case reflect.Map:
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return []Problem{getTypeProblem(path, "an object", value)}
+		}
+
+		for _, key := range getSortedKeys(object) {
+			problems = append(problems, checkJSONValue(fmt.Sprintf("%s[%q]", path, key), object[key], valueType.Elem())...)
+		}
+
+	case reflect.Slice:
+		list, isList := value.([]interface{})
+		if !isList {
+			return []Problem{getTypeProblem(path, "a list", value)}
+		}
+
+		for index, element := range list {
+			problems = append(problems, checkJSONValue(fmt.Sprintf("%s[%d]", path, index), element, valueType.Elem())...)
+		}
+
+	case reflect.String:
+		if _, isString := value.(string); !isString {
+			return []Problem{getTypeProblem(path, "a string", value)}
+		}
+
+	case reflect.Bool:
+		if _, isBool := value.(bool); !isBool {
+			return []Problem{getTypeProblem(path, "a boolean (true or false)", value)}
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, isNumber := value.(json.Number)
+		if _, err := strconv.ParseInt(number.String(), 10, valueType.Bits()); !isNumber || err != nil {
+			return []Problem{getTypeProblem(path, "an integer", value)}
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, isNumber := value.(json.Number)
+		if _, err := strconv.ParseUint(number.String(), 10, valueType.Bits()); !isNumber || err != nil {
+			return []Problem{getTypeProblem(path, "a positive integer", value)}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if _, isNumber := value.(json.Number); !isNumber {
+			return []Problem{getTypeProblem(path, "a number", value)}
+		}
+	}
+
+	return problems
+}
+
+// findJSONField returns the field of the given struct (or of its embedded structs) into which the
+// JSON decoder reads the value with the given key.
+func findJSONField(structType reflect.Type, key string) (reflect.StructField, bool) {
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if embeddedField, found := findJSONField(field.Type, key); found {
+				return embeddedField, true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(field.Name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// getTypeProblem returns the problem of a setting whose value is not of the expected type.
+func getTypeProblem(path, expected string, value interface{}) Problem {
+	var found string
+	switch typedValue := value.(type) {
+	case string:
+		found = fmt.Sprintf("the string %q", typedValue)
+	case json.Number:
+		found = fmt.Sprintf("the number %s", typedValue)
+	case bool:
+		found = fmt.Sprintf("the boolean %t", typedValue)
+	case []interface{}:
+		found = "a list"
+	default:
+		found = "an object"
+	}
+
+	return Problem{Setting: path, Message: fmt.Sprintf("Expected %s but found %s.", expected, found)}
+}
+
+func getSortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func joinSettingPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// check returns the problems of the values of the configuration.
+func (config *Config) check() []Problem {
+	var problems []Problem
+
+	if logLevel := strings.ToLower(strings.TrimSpace(config.LogLevel)); logLevel != "" && !containsString(logLevelNames, logLevel) {
+		problems = append(problems, Problem{Setting: "LogLevel", Message: fmt.Sprintf("Unknown log level %q; use one of %s.", config.LogLevel, strings.Join(logLevelNames, ", "))})
+	}
+
+	if !logger.IsFormat(config.Logging.Format) {
+		problems = append(problems, Problem{Setting: "Logging.Format", Message: fmt.Sprintf("Unknown log format %q; use %q or %q.", config.Logging.Format, logger.FormatText, logger.FormatJSON), IsWarning: true})
+	}
+
+	if !logger.IsBackend(config.Logging.Backend) {
+		problems = append(problems, Problem{Setting: "Logging.Backend", Message: fmt.Sprintf("Unknown log backend %q; use %q, %q or %q.", config.Logging.Backend, logger.BackendConsole, logger.BackendSyslog, logger.BackendJournald), IsWarning: true})
+	}
+
+	modules := make([]string, 0, len(config.Logging.Levels))
+	for module := range config.Logging.Levels {
+		modules = append(modules, module)
+	}
+
+	sort.Strings(modules)
+	for _, module := range modules {
+		setting := fmt.Sprintf("Logging.Levels[%q]", module)
+		if logLevel := strings.ToLower(strings.TrimSpace(config.Logging.Levels[module])); !containsString(logLevelNames, logLevel) {
+			problems = append(problems, Problem{Setting: setting, Message: fmt.Sprintf("Unknown log level %q; use one of %s.", config.Logging.Levels[module], strings.Join(logLevelNames, ", "))})
+		}
+
+		if !containsString(logger.Modules, strings.ToLower(strings.TrimSpace(module))) {
+			problems = append(problems, Problem{Setting: setting, Message: fmt.Sprintf("Unknown module %q; use one of %s.", module, strings.Join(logger.Modules, ", ")), IsWarning: true})
+		}
+	}
+
+	problems = append(problems, config.checkEndpoints()...)
+
+	for index, listener := range config.Server.Listeners {
+		if listener.Internal && !listener.Binding.IsLoopback() {
+			problems = append(problems, Problem{Setting: fmt.Sprintf("Server.Listeners[%d].Binding.IP", index), Message: fmt.Sprintf("The internal listener serves requests without authentication and network access rules; bind it to a loopback address (e.g. 127.0.0.1) instead of %q, otherwise allmark stops on startup.", listener.Binding.IP)})
+		}
+	}
+	problems = append(problems, config.checkCertificates()...)
+	problems = append(problems, config.checkAuthentication()...)
+	problems = append(problems, config.checkPaths()...)
+
+	if config.Conversion.DOCX.Enabled && !config.Conversion.DOCX.IsEnabled() {
+		problems = append(problems, Problem{Setting: "Conversion.DOCX.ToolPath", Message: fmt.Sprintf("The conversion tool %q was not found; the DOCX and ODT downloads are disabled.", config.Conversion.DOCX.Tool()), IsWarning: true})
+	}
+
+	if config.Conversion.PDF.Enabled && !config.Conversion.PDF.IsEnabled() {
+		problems = append(problems, Problem{Setting: "Conversion.PDF.ToolPath", Message: fmt.Sprintf("The conversion tool %q was not found; the PDF downloads are disabled.", config.Conversion.PDF.Tool()), IsWarning: true})
+	}
+
+	for index, webhook := range config.Webhooks {
+		if _, err := config.Secret(webhook.Secret); err != nil {
+			problems = append(problems, Problem{Setting: fmt.Sprintf("Webhooks[%d].Secret", index), Message: err.Error()})
+		}
+	}
+
+	if _, err := config.NetworkPolicy(); err != nil {
+		problems = append(problems, Problem{Setting: "Server.Network", Message: err.Error()})
+	}
+
+	if _, err := config.Server.UnixSocket.FileMode(); err != nil {
+		problems = append(problems, Problem{Setting: "Server.UnixSocket.Mode", Message: err.Error()})
+	}
+
+	if err := config.Web.MIMETypes.Register(); err != nil {
+		problems = append(problems, Problem{Setting: "Web.MIMETypes", Message: err.Error()})
+	}
+
+	if _, err := config.Redirects(); err != nil {
+		problems = append(problems, Problem{Message: fmt.Sprintf("The redirects file %q is invalid: %s", config.RedirectsFilePath(), err)})
+	}
+
+	if _, err := config.Synonyms(); err != nil {
+		problems = append(problems, Problem{Message: fmt.Sprintf("The synonyms file %q is invalid: %s", config.SynonymsFilePath(), err)})
+	}
+
+	if _, err := config.Locales(); err != nil {
+		problems = append(problems, Problem{Message: fmt.Sprintf("The locales are invalid: %s", err)})
+	}
+
+	return problems
+}
+
+// checkEndpoints returns the problems of the TCP bindings of the enabled endpoints: invalid ports,
+// addresses which are used by several endpoints and addresses which are not available.
+func (config *Config) checkEndpoints() []Problem {
+	type endpoint struct {
+		setting string
+		binding *TCPBinding
+	}
+
+	var endpoints []endpoint
+	if config.Server.HTTP.Enabled {
+		for index, binding := range config.Server.HTTP.Bindings {
+			endpoints = append(endpoints, endpoint{fmt.Sprintf("Server.HTTP.Bindings[%d]", index), binding})
+		}
+	}
+
+	if config.Server.HTTPS.Enabled {
+		for index, binding := range config.Server.HTTPS.Bindings {
+			endpoints = append(endpoints, endpoint{fmt.Sprintf("Server.HTTPS.Bindings[%d]", index), binding})
+		}
+	}
+
+	for index := range config.Server.Listeners {
+		endpoints = append(endpoints, endpoint{fmt.Sprintf("Server.Listeners[%d].Binding", index), &config.Server.Listeners[index].Binding})
+	}
+
+	var problems []Problem
+	if len(endpoints) == 0 && config.UnixSocketFilePath() == "" && !config.Server.UnixSocket.SystemdActivation {
+		problems = append(problems, Problem{Setting: "Server", Message: "No endpoint is enabled; enable HTTP, HTTPS, a listener or the Unix socket.", IsWarning: true})
+	}
+
+	usedAddresses := make(map[string]string)
+	for _, endpoint := range endpoints {
+		if endpoint.binding == nil {
+			problems = append(problems, Problem{Setting: endpoint.setting, Message: "The binding is empty."})
+			continue
+		}
+
+		if endpoint.binding.IP != "" && net.ParseIP(endpoint.binding.IP) == nil {
+			problems = append(problems, Problem{Setting: endpoint.setting + ".IP", Message: fmt.Sprintf("%q is not an IP address.", endpoint.binding.IP)})
+			continue
+		}
+
+		if endpoint.binding.Port < 0 || endpoint.binding.Port > 65535 {
+			problems = append(problems, Problem{Setting: endpoint.setting + ".Port", Message: fmt.Sprintf("%d is not a valid port number.", endpoint.binding.Port)})
+			continue
+		}
+
+		// random ports are always available
+		if endpoint.binding.Port == 0 {
+			continue
+		}
+
+		network := endpoint.binding.Network
+		if network == "" {
+			network = "tcp"
+		}
+
+		tcpAddress := endpoint.binding.GetTCPAddress()
+		address := tcpAddress.String()
+		if otherSetting, isUsed := usedAddresses[address]; isUsed {
+			problems = append(problems, Problem{Setting: endpoint.setting, Message: fmt.Sprintf("The address %s is also used by %s.", address, otherSetting)})
+			continue
+		}
+
+		usedAddresses[address] = endpoint.setting
+
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			problems = append(problems, Problem{Setting: endpoint.setting, Message: fmt.Sprintf("The address %s is not available: %s", address, err)})
+			continue
+		}
+
+		listener.Close()
+	}
+
+	return problems
+}
+
+// checkCertificates returns the problems of the certificates of the HTTPS endpoint and of the secure listeners.
+func (config *Config) checkCertificates() []Problem {
+	var problems []Problem
+
+	if config.Server.HTTPS.Enabled {
+		if config.Server.HTTPS.LetsEncrypt.Enabled {
+			if len(config.LetsEncryptHostnames()) == 0 {
+				problems = append(problems, Problem{Setting: "Server.HTTPS.LetsEncrypt.Hostnames", Message: "No hostnames are configured for the certificates."})
+			}
+
+		} else {
+			certificateFilePath, keyFilePath, err := config.httpsCertificateFilePaths()
+			if err != nil {
+				problems = append(problems, Problem{Setting: "Server.HTTPS.CertFileName", Message: err.Error()})
+
+			} else if !fsutil.FileExists(certificateFilePath) && !fsutil.FileExists(keyFilePath) {
+				problems = append(problems, Problem{
+					Setting:   "Server.HTTPS.CertFileName",
+					Message:   fmt.Sprintf("The certificate %q and the key %q do not exist; a self-signed certificate is created on startup.", certificateFilePath, keyFilePath),
+					IsWarning: true,
+				})
+
+			} else {
+				problems = append(problems, config.checkCertificate("Server.HTTPS", certificateFilePath, keyFilePath)...)
+			}
+		}
+	}
+
+	for index, listener := range config.Server.Listeners {
+		setting := fmt.Sprintf("Server.Listeners[%d]", index)
+		certificateFilePath, keyFilePath, err := config.ListenerCertificateFilePaths(listener)
+		if err != nil {
+			problems = append(problems, Problem{Setting: setting + ".KeyFileName", Message: err.Error()})
+			continue
+		}
+
+		if listener.IsSecure() {
+			problems = append(problems, config.checkCertificate(setting, certificateFilePath, keyFilePath)...)
+		}
+	}
+
+	return problems
+}
+
+// checkCertificate returns the problems of the given certificate and key file of the endpoint with the given setting.
+func (config *Config) checkCertificate(setting, certificateFilePath, keyFilePath string) []Problem {
+	if !fsutil.FileExists(certificateFilePath) {
+		return []Problem{{Setting: setting + ".CertFileName", Message: fmt.Sprintf("The certificate %q does not exist.", certificateFilePath)}}
+	}
+
+	if !fsutil.FileExists(keyFilePath) {
+		return []Problem{{Setting: setting + ".KeyFileName", Message: fmt.Sprintf("The key %q does not exist.", keyFilePath)}}
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certificateFilePath, keyFilePath)
+	if err != nil {
+		return []Problem{{Setting: setting + ".CertFileName", Message: fmt.Sprintf("The certificate %q cannot be used with the key %q: %s", certificateFilePath, keyFilePath, err)}}
+	}
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return []Problem{{Setting: setting + ".CertFileName", Message: fmt.Sprintf("The certificate %q cannot be parsed: %s", certificateFilePath, err)}}
+	}
+
+	var problems []Problem
+	if remainingValidity := time.Until(leaf.NotAfter); remainingValidity <= 0 {
+		problems = append(problems, Problem{Setting: setting + ".CertFileName", Message: fmt.Sprintf("The certificate %q expired on %s.", certificateFilePath, leaf.NotAfter.Format("2006-01-02"))})
+	} else if remainingValidity < certificateExpiryWarningPeriod {
+		problems = append(problems, Problem{Setting: setting + ".CertFileName", Message: fmt.Sprintf("The certificate %q expires on %s.", certificateFilePath, leaf.NotAfter.Format("2006-01-02")), IsWarning: true})
+	}
+
+	if domainName := strings.TrimSpace(config.Server.DomainName); domainName != "" && leaf.VerifyHostname(domainName) != nil {
+		problems = append(problems, Problem{Setting: setting + ".CertFileName", Message: fmt.Sprintf("The certificate %q is not valid for the domain name %q.", certificateFilePath, domainName), IsWarning: true})
+	}
+
+	return problems
+}
+
+// checkAuthentication returns the problems of the authentication settings and of the users and groups of
+// the authentication rules, the write API and the ZIP downloads.
+func (config *Config) checkAuthentication() []Problem {
+	authentication := config.Server.Authentication
+	if !authentication.Enabled {
+		var problems []Problem
+		if config.Server.WriteAPI.Enabled {
+			problems = append(problems, Problem{Setting: "Server.WriteAPI.Enabled", Message: "The write API requires authentication; it is disabled."})
+		}
+
+		if config.Server.ZIPDownload.Enabled {
+			problems = append(problems, Problem{Setting: "Server.ZIPDownload.Enabled", Message: "The ZIP downloads require authentication; they are disabled."})
+		}
+
+		return problems
+	}
+
+	if config.Server.HTTP.Enabled && !config.Server.HTTPS.HTTPSIsForced() {
+		return []Problem{{Setting: "Server.Authentication.Enabled", Message: "Authentication is only available over HTTPS; disable HTTP or force HTTPS (Server.HTTPS.Force), otherwise allmark stops on startup."}}
+	}
+
+	var listenerProblems []Problem
+	for index, listener := range config.Server.Listeners {
+		if listener.ServesUnencryptedContent() {
+			listenerProblems = append(listenerProblems, Problem{Setting: fmt.Sprintf("Server.Listeners[%d]", index), Message: "Authentication is only available over HTTPS; add a certificate, redirect the requests (RedirectTo) or make the listener internal, otherwise allmark stops on startup."})
+		}
+	}
+
+	if len(listenerProblems) > 0 {
+		return listenerProblems
+	}
+
+	userStoreFilePath, err := config.AuthenticationFilePath()
+	if err != nil {
+		return []Problem{{Setting: "Server.Authentication.UserStoreFileName", Message: err.Error()}}
+	}
+
+	users, err := readUserStoreUsers(userStoreFilePath)
+	if err != nil {
+		return []Problem{{Setting: "Server.Authentication.UserStoreFileName", Message: fmt.Sprintf("The user store %q cannot be read: %s", userStoreFilePath, err)}}
+	}
+
+	var problems []Problem
+	if len(users) == 0 {
+		problems = append(problems, Problem{Setting: "Server.Authentication.UserStoreFileName", Message: fmt.Sprintf("The user store %q contains no users.", userStoreFilePath), IsWarning: true})
+	}
+
+	checkUsersAndGroups := func(setting string, userNames, groupNames []string) {
+		for _, userName := range userNames {
+			if !users[userName] {
+				problems = append(problems, Problem{Setting: setting + ".Users", Message: fmt.Sprintf("The user %q does not exist in the user store.", userName), IsWarning: true})
+			}
+		}
+
+		for _, groupName := range groupNames {
+			if _, exists := authentication.Groups[groupName]; !exists {
+				problems = append(problems, Problem{Setting: setting + ".Groups", Message: fmt.Sprintf("The group %q does not exist.", groupName), IsWarning: true})
+			}
+		}
+	}
+
+	groupNames := make([]string, 0, len(authentication.Groups))
+	for groupName := range authentication.Groups {
+		groupNames = append(groupNames, groupName)
+	}
+
+	sort.Strings(groupNames)
+	for _, groupName := range groupNames {
+		for _, userName := range authentication.Groups[groupName] {
+			if !users[userName] {
+				problems = append(problems, Problem{Setting: fmt.Sprintf("Server.Authentication.Groups[%q]", groupName), Message: fmt.Sprintf("The user %q does not exist in the user store.", userName), IsWarning: true})
+			}
+		}
+	}
+
+	for index, rule := range authentication.Rules {
+		checkUsersAndGroups(fmt.Sprintf("Server.Authentication.Rules[%d]", index), rule.Users, rule.Groups)
+	}
+
+	checkUsersAndGroups("Server.WriteAPI", config.Server.WriteAPI.Users, config.Server.WriteAPI.Groups)
+	checkUsersAndGroups("Server.ZIPDownload", config.Server.ZIPDownload.Users, config.Server.ZIPDownload.Groups)
+
+	return problems
+}
+
+// readUserStoreUsers returns the names of the users of the given user store file ("name:..." lines).
+func readUserStoreUsers(userStoreFilePath string) (map[string]bool, error) {
+	file, err := os.Open(userStoreFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	users := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if userName, _, found := strings.Cut(line, ":"); found {
+			users[userName] = true
+		}
+	}
+
+	return users, scanner.Err()
+}
+
+// checkPaths returns the problems of the configured folders and files.
+func (config *Config) checkPaths() []Problem {
+	var problems []Problem
+
+	for index, folder := range config.Theme.Folder {
+		if folder = strings.TrimSpace(folder); folder == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(folder) {
+			folder = filepath.Join(config.MetaDataFolder(), folder)
+		}
+
+		if !fsutil.DirectoryExists(folder) {
+			problems = append(problems, Problem{Setting: fmt.Sprintf("Theme.Folder[%d]", index), Message: fmt.Sprintf("The theme folder %q does not exist.", folder)})
+		}
+	}
+
+	if themeName := strings.TrimSpace(config.Theme.Name); themeName != "" {
+		if themeFolder := filepath.Join(config.InstalledThemesFolder(), filepath.Base(themeName)); !fsutil.DirectoryExists(themeFolder) {
+			problems = append(problems, Problem{Setting: "Theme.Name", Message: fmt.Sprintf("The theme %q is not installed (%q does not exist).", themeName, themeFolder)})
+		}
+	}
+
+	if accessLogFilePath := config.AccessLogFilePath(); accessLogFilePath != "" && !fsutil.DirectoryExists(filepath.Dir(accessLogFilePath)) {
+		problems = append(problems, Problem{Setting: "Server.AccessLog.FileName", Message: fmt.Sprintf("The folder of the access log %q does not exist.", accessLogFilePath)})
+	}
+
+	if socketFilePath := config.UnixSocketFilePath(); socketFilePath != "" && !fsutil.DirectoryExists(filepath.Dir(socketFilePath)) {
+		problems = append(problems, Problem{Setting: "Server.UnixSocket.Path", Message: fmt.Sprintf("The folder of the socket %q does not exist.", socketFilePath)})
+	}
+
+	return problems
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"github.com/andreaskoch/allmark/common/certificates"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getProblemSettings(problems []Problem) []string {
+	settings := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		settings = append(settings, problem.Setting)
+	}
+
+	return settings
+}
+
+func Test_checkConfigFile_UnknownSettingsAndWrongTypes_ProblemsWithPathsAreReturned(t *testing.T) {
+	// arrange
+	data := []byte(`{
+		"Server": {
+			"http": {"Enabeld": true, "Bindings": [{"Port": "8080"}]},
+			"HTTPS": {"Enabled": true, "Force": "yes"}
+		},
+		"Theme": {"Folder": "themes/dark"},
+		"Web": {"MIMETypes": {".gpx": 1}}
+	}`)
+
+	// act
+	problems := checkConfigFile(data)
+
+	// assert
+	expected := []string{"Server.HTTPS.Force", "Server.HTTP.Bindings[0].Port", "Server.HTTP.Enabeld", `Web.MIMETypes[".gpx"]`}
+	if settings := getProblemSettings(problems); !reflect.DeepEqual(settings, expected) {
+		t.Errorf("checkConfigFile should report %v but reported %v.", expected, problems)
+	}
+}
+
+func Test_checkConfigFile_InvalidJSON_LineAndColumnAreReported(t *testing.T) {
+	// arrange
+	data := []byte("{\n  \"Server\": {\n    \"HTTP\": {\"Enabled\": true,}\n  }\n}")
+
+	// act
+	problems := checkConfigFile(data)
+
+	// assert
+	if len(problems) != 1 || problems[0].Message != "The configuration file is not valid JSON (line 3, column 31): invalid character '}' looking for beginning of object key string." {
+		t.Errorf("checkConfigFile should report the position of the syntax error but reported %v.", problems)
+	}
+}
+
+func Test_checkEndpoints_SameAddressTwice_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := New(t.TempDir())
+	config.Server.HTTP = HTTP{Enabled: true, Bindings: []*TCPBinding{{Network: "tcp4", IP: "127.0.0.1", Port: 1}}}
+	config.Server.Listeners = []Listener{{Binding: TCPBinding{Network: "tcp4", IP: "127.0.0.1", Port: 1}}}
+
+	// act
+	problems := config.checkEndpoints()
+
+	// assert
+	found := false
+	for _, problem := range problems {
+		if problem.Setting == "Server.Listeners[0].Binding" && problem.Message == "The address 127.0.0.1:1 is also used by Server.HTTP.Bindings[0]." {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("checkEndpoints should report the address which is used twice but reported %v.", problems)
+	}
+}
+
+func Test_checkCertificate_KeyOfAnotherCertificate_ErrorIsReturned(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	certificates.GenerateDummyCert(filepath.Join(folder, "first.pem"), filepath.Join(folder, "first.key"), "localhost")
+	certificates.GenerateDummyCert(filepath.Join(folder, "second.pem"), filepath.Join(folder, "second.key"), "localhost")
+	config := New(folder)
+
+	// act
+	matchingProblems := config.checkCertificate("Server.HTTPS", filepath.Join(folder, "first.pem"), filepath.Join(folder, "first.key"))
+	mismatchingProblems := config.checkCertificate("Server.HTTPS", filepath.Join(folder, "first.pem"), filepath.Join(folder, "second.key"))
+
+	// assert
+	if HasErrors(matchingProblems) {
+		t.Errorf("A certificate with its own key should have no errors but had %v.", matchingProblems)
+	}
+
+	if len(mismatchingProblems) != 1 || mismatchingProblems[0].Setting != "Server.HTTPS.CertFileName" || mismatchingProblems[0].IsWarning {
+		t.Errorf("A certificate with another key should be an error but the problems were %v.", mismatchingProblems)
+	}
+}
+
+func Test_checkAuthentication_UnknownUsersAndGroups_WarningsAreReturned(t *testing.T) {
+	// arrange
+	config := New(t.TempDir())
+	os.MkdirAll(config.MetaDataFolder(), 0700)
+	os.WriteFile(filepath.Join(config.MetaDataFolder(), DefaultUserStoreFileName), []byte("alice:allmark:0123456789abcdef\n"), 0600)
+
+	config.Server.HTTPS = HTTPS{HTTP: HTTP{Enabled: true}}
+	config.Server.Authentication = Authentication{
+		Enabled: true,
+		Groups:  map[string][]string{"editors": {"alice", "bob"}},
+		Rules:   []AuthenticationRule{{Route: "/private", Users: []string{"alice"}, Groups: []string{"admins"}}},
+	}
+
+	// act
+	problems := config.checkAuthentication()
+
+	// assert
+	expected := []string{`Server.Authentication.Groups["editors"]`, "Server.Authentication.Rules[0].Groups"}
+	if settings := getProblemSettings(problems); !reflect.DeepEqual(settings, expected) || HasErrors(problems) {
+		t.Errorf("checkAuthentication should warn about %v but reported %v.", expected, problems)
+	}
+}
+
+func Test_checkAuthentication_HTTPNotForcedToHTTPS_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := New(t.TempDir())
+	config.Server.HTTP.Enabled = true
+	config.Server.Authentication.Enabled = true
+
+	// act
+	problems := config.checkAuthentication()
+
+	// assert
+	if len(problems) != 1 || problems[0].Setting != "Server.Authentication.Enabled" || problems[0].IsWarning {
+		t.Errorf("Authentication over HTTP should be an error but the problems were %v.", problems)
+	}
+}
+
+func Test_checkAuthentication_UnencryptedListener_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := New(t.TempDir())
+	config.Server.Authentication.Enabled = true
+	config.Server.Listeners = []Listener{
+		{Name: "redirect", RedirectTo: "https://example.com"},
+		{Name: "internal", Internal: true},
+		{Name: "public"},
+	}
+
+	// act
+	problems := config.checkAuthentication()
+
+	// assert
+	if len(problems) != 1 || problems[0].Setting != "Server.Listeners[2]" || problems[0].IsWarning {
+		t.Errorf("Authentication over the unencrypted listener should be an error but the problems were %v.", problems)
+	}
+}
+
+func Test_check_InternalListenerOnPublicAddress_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := Default(t.TempDir())
+	config.Server.Listeners = []Listener{
+		{Name: "loopback", Binding: TCPBinding{Network: "tcp6", IP: "::1", Port: 8081}, Internal: true},
+		{Name: "public", Binding: TCPBinding{Network: "tcp4", IP: "0.0.0.0", Port: 8082}, Internal: true},
+	}
+
+	// act
+	problems := config.check()
+
+	// assert
+	var listenerProblems []Problem
+	for _, problem := range problems {
+		if strings.HasPrefix(problem.Setting, "Server.Listeners") && strings.HasSuffix(problem.Setting, ".IP") {
+			listenerProblems = append(listenerProblems, problem)
+		}
+	}
+
+	if len(listenerProblems) != 1 || listenerProblems[0].Setting != "Server.Listeners[1].Binding.IP" || listenerProblems[0].IsWarning {
+		t.Errorf("Only the internal listener on the public address should be an error but the problems were %v.", listenerProblems)
+	}
+}
+
+func Test_Check_NoConfigurationFile_DefaultConfigurationIsChecked(t *testing.T) {
+	// arrange
+	repository := t.TempDir()
+
+	// act
+	configFilePath, problems := Check(repository, "", []string{"ALLMARK_SERVER_HTTP_ENABLED=perhaps"})
+
+	// assert
+	if configFilePath != "" && configFilePath != filepath.Join(homeDirectory(), MetaDataFolderName, ConfigurationFileName) {
+		t.Errorf("Check should not use the configuration file %q.", configFilePath)
+	}
+
+	if !HasErrors(problems) {
+		t.Errorf("Check should report the invalid environment variable but reported %v.", problems)
+	}
+}
+
+func Test_check_UnknownModuleAndLogLevel_ProblemsAreReturned(t *testing.T) {
+	// arrange
+	config := Default(t.TempDir())
+	config.Logging.Levels = map[string]string{"watcher": "verbose", "indexer": "debug", "Thumbnail": "warn"}
+
+	// act
+	var problems []Problem
+	for _, problem := range config.check() {
+		if strings.HasPrefix(problem.Setting, "Logging.") {
+			problems = append(problems, problem)
+		}
+	}
+
+	// assert
+	if len(problems) != 2 {
+		t.Fatalf("check should report the unknown module and the unknown log level but reported %v.", problems)
+	}
+
+	if problems[0].Setting != `Logging.Levels["indexer"]` || !problems[0].IsWarning {
+		t.Errorf("An unknown module should be a warning but was %v.", problems[0])
+	}
+
+	if problems[1].Setting != `Logging.Levels["watcher"]` || problems[1].IsWarning {
+		t.Errorf("An unknown log level should be an error but was %v.", problems[1])
+	}
+}
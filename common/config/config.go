@@ -14,9 +14,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	auth "github.com/abbot/go-http-auth"
 	"github.com/andreaskoch/allmark/common/certificates"
+	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/logger/loglevel"
 	"github.com/andreaskoch/allmark/common/ports"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
@@ -29,39 +35,118 @@ const (
 	FilesDirectoryName     = "files"
 	ConfigurationFileName  = "config"
 	ThemeFolderName        = "theme"
+	ThemesFolderName       = "themes"
 	TemplatesFolderName    = "templates"
 	ThumbnailIndexFileName = "thumbnail.index"
+	SearchIndexFileName    = "search.index"
 	ThumbnailsFolderName   = "thumbnails"
+	ConversionsFolderName  = "conversions"
 	SSLCertsFolderName     = "certs"
+	LetsEncryptFolderName  = "letsencrypt"
+	RedirectsFileName      = "redirects"
+	SynonymsFileName       = "synonyms"
+	LocalesFolderName      = "locales"
+	HTMLCacheFolderName    = "htmlcache"
 )
 
 // Global default values.
 const (
 	DefaultDomainName                = "localhost"
+	DefaultBasePath                  = "/"
 	DefaultHTTPPortEnabled           = true
 	DefaultHTTPSPortEnabled          = false
 	DefaultHTTPSCertName             = "cert.pem"
 	DefaultHTTPSKeyName              = "cert.key"
 	DefaultForceHTTPS                = false
+	DefaultLetsEncryptEnabled        = false
+	DefaultHTTP2Enabled              = true
+	DefaultH2CEnabled                = false
 	DefaultLanguage                  = "fa"
 	DefaultDirection                 = "rtl"
 	DefaultLogLevel                  = loglevel.Error
+	DefaultLogFormat                 = logger.FormatText
+	DefaultLogFileMaxSizeInMB        = 100
+	DefaultLogFileMaxBackups         = 7
+	DefaultLogFileMaxAgeInDays       = 30
+	DefaultSyslogTag                 = "allmark"
 	DefaultIndexingEnabled           = true
 	DefaultIndexingIntervalInSeconds = 60
+	DefaultIndexingWorkers           = 0
+	DefaultHTMLCacheEnabled          = true
+	DefaultHTMLCacheMaxSizeInMB      = 64
+	DefaultHTMLCachePersistent       = false
+	DefaultWarmUpEnabled             = false
+	DefaultWarmUpMostLinkedItems     = 20
+	DefaultMaxMemoryInMB             = 512
+	DefaultMaxConcurrentTasks        = 0
 	DefaultLiveReloadEnabled         = true
+	DefaultLiveReloadMode            = LiveReloadModeMorph
+	DefaultLiveReloadDebounceInMS    = 300
 	DefaultConversionDocxEnabled     = true
+	DefaultConversionPDFEnabled      = true
+	DefaultConversionEPUBEnabled     = true
+	DefaultPDFConversionToolPath     = "wkhtmltopdf"
+	DefaultPDFPageSize               = "A4"
+	DefaultPDFHeader                 = "[title]"
+	DefaultPDFFooter                 = "[page] / [topage]"
 	DefaultAuthenticationEnabled     = false
 	DefaultUserStoreFileName         = "users.htpasswd"
+	DefaultMinificationEnabled       = false
+	DefaultCORSEnabled               = false
+	DefaultCORSMaxAgeInSeconds       = 600
+	DefaultRateLimitingEnabled       = false
+	DefaultRequestsPerSecond         = 10
+	DefaultRequestBurst              = 20
+	DefaultMaxConcurrentRequests     = 0
+	DefaultMaxExpensiveRequests      = 4
+	DefaultMetricsEnabled            = false
+	DefaultDebugEnabled              = false
+	DefaultDebugLocalhostOnly        = true
+	DefaultAccessLogEnabled          = true
+	DefaultAccessLogFormat           = "common"
+	DefaultUnixSocketMode            = "0660"
+	DefaultShutdownTimeoutInSeconds  = 30
+	DefaultSecurityHeadersEnabled    = true
+	DefaultReferrerPolicy            = "strict-origin-when-cross-origin"
+	DefaultWriteAPIEnabled           = false
+	DefaultWriteAPIMaxUploadSizeInMB = 32
+	DefaultZIPDownloadEnabled        = false
+	DefaultGraphQLEnabled            = false
+	DefaultGraphQLMaxDepth           = 10
+	DefaultFeedItemsPerPage          = 5
+	DefaultFeedSortBy                = FeedSortByCreated
+	DefaultRSSContent                = RSSContentFull
+	DefaultRSSEnclosuresEnabled      = false
+	DefaultRelatedItemsEnabled       = true
+	DefaultRelatedItemsCount         = 5
+	DefaultRecentChangesCount        = 50
+	DefaultItemsPerPage              = 50
+	DefaultChildSortOrder            = ChildSortOrderDate
+	DefaultWebAppEnabled             = false
+	DefaultWebAppThemeColor          = "#ffffff"
+	DefaultWebAppBackgroundColor     = "#ffffff"
+	DefaultWebAppMaxPrecachedItems   = 50
+	DefaultSearchAttachmentsEnabled  = true
+	DefaultSearchAttachmentMaxSizeMB = 16
+	DefaultPDFToTextToolPath         = "pdftotext"
+	DefaultSearchTitleBoost          = 3.0
+	DefaultSearchDescriptionBoost    = 1.5
+	DefaultSearchTagsBoost           = 2.0
+	DefaultSearchAliasesBoost        = 2.0
+	DefaultSearchRouteBoost          = 1.0
+	DefaultSearchContentBoost        = 1.0
+	DefaultSearchRecencyBoost        = 0.0
+	DefaultSearchRecencyHalfLifeDays = 30
 )
 
 // homeDirectory returns the current users home directory path.
 var homeDirectory func() string
 
-// A flag indicating whether the DOCX conversion tool is available
-var docxConversionToolIsAvailable bool
-
 var conversionEndpointBinding *TCPBinding
 
+// availableTools caches whether the external tools with the given paths were found.
+var availableTools sync.Map
+
 func init() {
 
 	homeDirPath, err := homedir.Dir()
@@ -73,12 +158,6 @@ func init() {
 		return filepath.Clean(homeDirPath)
 	}
 
-	// check if pandoc is available in the path
-	command := exec.Command(DefaultConversionToolPath, "--help")
-	if err := command.Run(); err == nil {
-		docxConversionToolIsAvailable = true
-	}
-
 	// conversion endpoint binding
 	conversionEndpointBinding = &TCPBinding{
 		Network: "tcp4",
@@ -90,6 +169,18 @@ func init() {
 	conversionEndpointBinding.AssignFreePort()
 }
 
+// isToolAvailable returns true if the external tool with the given path (or name) is an executable
+// in the PATH; the result is only determined once per path.
+func isToolAvailable(toolPath string) bool {
+	if isAvailable, exists := availableTools.Load(toolPath); exists {
+		return isAvailable.(bool)
+	}
+
+	_, err := exec.LookPath(toolPath)
+	availableTools.Store(toolPath, err == nil)
+	return err == nil
+}
+
 func isHomeDir(directory string) bool {
 	return filepath.Clean(directory) == homeDirectory()
 }
@@ -148,6 +239,8 @@ func Default(baseFolder string) *Config {
 	// apply default values
 	config.Server.ThemeFolderName = ThemeFolderName
 	config.Server.DomainName = DefaultDomainName
+	config.Server.BasePath = DefaultBasePath
+	config.Server.ShutdownTimeoutInSeconds = DefaultShutdownTimeoutInSeconds
 
 	// HTTP
 	config.Server.HTTP.Enabled = DefaultHTTPPortEnabled
@@ -171,6 +264,7 @@ func Default(baseFolder string) *Config {
 	config.Server.HTTPS.CertFileName = DefaultHTTPSCertName
 	config.Server.HTTPS.KeyFileName = DefaultHTTPSKeyName
 	config.Server.HTTPS.Force = DefaultForceHTTPS
+	config.Server.HTTPS.LetsEncrypt.Enabled = DefaultLetsEncryptEnabled
 	config.Server.HTTPS.Bindings = []*TCPBinding{
 		&TCPBinding{
 			Network: "tcp4",
@@ -186,10 +280,70 @@ func Default(baseFolder string) *Config {
 		},
 	}
 
+	// HTTP/2
+	config.Server.HTTP2.Enabled = DefaultHTTP2Enabled
+	config.Server.HTTP2.H2C = DefaultH2CEnabled
+
+	// Unix Socket
+	config.Server.UnixSocket.Mode = DefaultUnixSocketMode
+
+	// Additional Listeners
+	config.Server.Listeners = []Listener{}
+
 	// Authentication
 	config.Server.Authentication.Enabled = DefaultAuthenticationEnabled
 	config.Server.Authentication.UserStoreFileName = DefaultUserStoreFileName
 
+	// Minification
+	config.Server.Minification.Enabled = DefaultMinificationEnabled
+
+	// CORS
+	config.Server.CORS.Enabled = DefaultCORSEnabled
+	config.Server.CORS.AllowedOrigins = []string{"*"}
+	config.Server.CORS.AllowedMethods = []string{"GET", "HEAD", "OPTIONS"}
+	config.Server.CORS.AllowedHeaders = []string{}
+	config.Server.CORS.MaxAgeInSeconds = DefaultCORSMaxAgeInSeconds
+
+	// Security Headers
+	config.Server.SecurityHeaders.Enabled = DefaultSecurityHeadersEnabled
+	config.Server.SecurityHeaders.ContentSecurityPolicy = DefaultContentSecurityPolicy
+	config.Server.SecurityHeaders.FrameAncestors = []string{"'self'"}
+	config.Server.SecurityHeaders.ContentTypeNoSniff = true
+	config.Server.SecurityHeaders.ReferrerPolicy = DefaultReferrerPolicy
+
+	// Rate Limiting
+	config.Server.RateLimiting.Enabled = DefaultRateLimitingEnabled
+	config.Server.RateLimiting.RequestsPerSecond = DefaultRequestsPerSecond
+	config.Server.RateLimiting.Burst = DefaultRequestBurst
+	config.Server.RateLimiting.MaxConcurrentRequests = DefaultMaxConcurrentRequests
+	config.Server.RateLimiting.MaxConcurrentExpensiveRequests = DefaultMaxExpensiveRequests
+
+	// Metrics
+	config.Server.Metrics.Enabled = DefaultMetricsEnabled
+
+	// Debug
+	config.Server.Debug.Enabled = DefaultDebugEnabled
+	config.Server.Debug.LocalhostOnly = DefaultDebugLocalhostOnly
+
+	// Access Log
+	config.Server.AccessLog.Enabled = DefaultAccessLogEnabled
+	config.Server.AccessLog.Format = DefaultAccessLogFormat
+
+	// Write API
+	config.Server.WriteAPI.Enabled = DefaultWriteAPIEnabled
+	config.Server.WriteAPI.Users = []string{}
+	config.Server.WriteAPI.Groups = []string{}
+	config.Server.WriteAPI.MaxUploadSizeInMegabytes = DefaultWriteAPIMaxUploadSizeInMB
+
+	// ZIP Download
+	config.Server.ZIPDownload.Enabled = DefaultZIPDownloadEnabled
+	config.Server.ZIPDownload.Users = []string{}
+	config.Server.ZIPDownload.Groups = []string{}
+
+	// GraphQL
+	config.Server.GraphQL.Enabled = DefaultGraphQLEnabled
+	config.Server.GraphQL.MaxDepth = DefaultGraphQLMaxDepth
+
 	config.Web.DefaultLanguage = DefaultLanguage
 	config.Web.DefaultDirection = DefaultDirection
 
@@ -201,22 +355,122 @@ func Default(baseFolder string) *Config {
 		"Unknown": UserInformation{},
 	}
 
+	// Custom MIME types
+	config.Web.MIMETypes = MIMETypes{}
+
+	// Snippets
+	config.Web.Snippets.Named = map[string]string{}
+
+	// Feeds
+	config.Web.Feeds.ItemsPerPage = DefaultFeedItemsPerPage
+	config.Web.Feeds.SortBy = DefaultFeedSortBy
+	config.Web.Feeds.RSS.Content = DefaultRSSContent
+	config.Web.Feeds.RSS.Enclosures = DefaultRSSEnclosuresEnabled
+
+	// Related Items
+	config.Web.RelatedItems.Enabled = DefaultRelatedItemsEnabled
+	config.Web.RelatedItems.Count = DefaultRelatedItemsCount
+
+	// Recent Changes
+	config.Web.RecentChanges.Count = DefaultRecentChangesCount
+
+	// Pagination
+	config.Web.Pagination.ItemsPerPage = DefaultItemsPerPage
+
+	// Child sort order
+	config.Web.Children.SortOrder = DefaultChildSortOrder
+
+	// Web App
+	config.Web.WebApp.Enabled = DefaultWebAppEnabled
+	config.Web.WebApp.ThemeColor = DefaultWebAppThemeColor
+	config.Web.WebApp.BackgroundColor = DefaultWebAppBackgroundColor
+	config.Web.WebApp.MaxPrecachedItems = DefaultWebAppMaxPrecachedItems
+
 	// Thumbnail conversion
 	config.Conversion.Thumbnails.IndexFileName = ThumbnailIndexFileName
 	config.Conversion.Thumbnails.FolderName = ThumbnailsFolderName
 
-	// DOCX Conversion
-	config.Conversion.DOCX.Enabled = DefaultConversionDocxEnabled
+	// Rich-text (DOCX and ODT) Conversion
+	config.Conversion.DOCX = DOCXConversion{
+		Enabled:    DefaultConversionDocxEnabled,
+		ToolPath:   DefaultConversionToolPath,
+		FolderName: ConversionsFolderName,
+	}
+
+	// EPUB Conversion
+	config.Conversion.EPUB.Enabled = DefaultConversionEPUBEnabled
+
+	// PDF Conversion
+	config.Conversion.PDF = PDFConversion{
+		Enabled:  DefaultConversionPDFEnabled,
+		ToolPath: DefaultPDFConversionToolPath,
+		PageSize: DefaultPDFPageSize,
+		Header:   DefaultPDFHeader,
+		Footer:   DefaultPDFFooter,
+	}
 
 	// Logging
 	config.LogLevel = DefaultLogLevel.String()
+	config.Logging.Format = DefaultLogFormat
+	config.Logging.Levels = map[string]string{}
+	config.Logging.File.MaxSizeInMegabytes = DefaultLogFileMaxSizeInMB
+	config.Logging.File.MaxBackups = DefaultLogFileMaxBackups
+	config.Logging.File.MaxAgeInDays = DefaultLogFileMaxAgeInDays
+	config.Logging.Syslog.Tag = DefaultSyslogTag
 
 	// Indexing
 	config.Indexing.Enabled = DefaultIndexingEnabled
 	config.Indexing.IntervalInSeconds = DefaultIndexingIntervalInSeconds
+	config.Indexing.Workers = DefaultIndexingWorkers
+
+	// Caching
+	config.Caching.HTML = HTMLCache{
+		Enabled:            DefaultHTMLCacheEnabled,
+		MaxSizeInMegabytes: DefaultHTMLCacheMaxSizeInMB,
+		Persistent:         DefaultHTMLCachePersistent,
+		FolderName:         HTMLCacheFolderName,
+	}
+
+	config.Caching.WarmUp = WarmUp{
+		Enabled:         DefaultWarmUpEnabled,
+		MostLinkedItems: DefaultWarmUpMostLinkedItems,
+		Routes:          []string{},
+	}
+
+	// Resources
+	config.Resources.MaxMemoryInMegabytes = DefaultMaxMemoryInMB
+	config.Resources.MaxConcurrentTasks = DefaultMaxConcurrentTasks
+
+	// Search
+	config.Search.IndexFileName = SearchIndexFileName
+	config.Search.Attachments.Enabled = DefaultSearchAttachmentsEnabled
+	config.Search.Attachments.MaxFileSizeInMB = DefaultSearchAttachmentMaxSizeMB
+	config.Search.Attachments.PDFToTextPath = DefaultPDFToTextToolPath
+	config.Search.Ranking = SearchRanking{
+		TitleBoost:            DefaultSearchTitleBoost,
+		DescriptionBoost:      DefaultSearchDescriptionBoost,
+		TagsBoost:             DefaultSearchTagsBoost,
+		AliasesBoost:          DefaultSearchAliasesBoost,
+		RouteBoost:            DefaultSearchRouteBoost,
+		ContentBoost:          DefaultSearchContentBoost,
+		RecencyBoost:          DefaultSearchRecencyBoost,
+		RecencyHalfLifeInDays: DefaultSearchRecencyHalfLifeDays,
+	}
+
+	// Theme
+	config.Theme.Folder = ThemeFolders{}
+	config.Theme.Templates = map[string]string{}
 
 	// Live-Reload
 	config.LiveReload.Enabled = DefaultLiveReloadEnabled
+	config.LiveReload.Mode = DefaultLiveReloadMode
+	config.LiveReload.DebounceInMilliseconds = DefaultLiveReloadDebounceInMS
+
+	// Webhooks
+	config.Webhooks = []Webhook{}
+
+	// Profiles
+	config.Profiles = map[string]Profile{}
 
 	return config
 }
@@ -248,6 +502,12 @@ func (binding *TCPBinding) GetTCPAddress() net.TCPAddr {
 	}
 }
 
+// IsLoopback returns a flag indicating whether the binding only accepts connections from the local host.
+func (binding *TCPBinding) IsLoopback() bool {
+	ip := net.ParseIP(binding.IP)
+	return ip != nil && ip.IsLoopback()
+}
+
 // AssignFreePort locates a free port and assigns it the the current binding.
 func (binding *TCPBinding) AssignFreePort() {
 	if binding.Port > 0 && binding.Port < math.MaxUint16 {
@@ -271,15 +531,116 @@ type HTTPS struct {
 	KeyFileName  string
 
 	Force bool
+
+	LetsEncrypt LetsEncrypt
 }
 
 // HTTPSIsForced indicates whether HTTPS is forced or not.
+// HTTPS is always forced if the certificates are obtained from Let's Encrypt.
 func (https *HTTPS) HTTPSIsForced() bool {
 	if https.Enabled == false {
 		return false
 	}
 
-	return https.Force
+	return https.Force || https.LetsEncrypt.Enabled
+}
+
+// LetsEncrypt contains the parameters for obtaining and renewing SSL certificates from Let's Encrypt automatically.
+type LetsEncrypt struct {
+	// Enabled is flag indicating whether the certificates shall be obtained from Let's Encrypt.
+	Enabled bool
+
+	// Email is the (optional) contact email address of the certificate owner.
+	Email string
+
+	// Hostnames is the list of hostnames for which certificates will be requested.
+	// If no hostnames are configured the domain name of the server is used.
+	Hostnames []string
+}
+
+// HTTP2 contains the HTTP/2 settings of the server endpoints.
+type HTTP2 struct {
+	// Enabled is flag indicating whether HTTP/2 is offered on the HTTPS endpoints.
+	Enabled bool
+
+	// H2C is flag indicating whether HTTP/2 without TLS (h2c) is offered on the HTTP endpoints.
+	H2C bool
+}
+
+// Listener defines an additional server endpoint with its own binding, TLS and redirect settings
+// (e.g. a listener on port 80 which redirects to HTTPS or an internal listener for monitoring tools).
+type Listener struct {
+	// Name is the name of the listener that is used in the logs (e.g. "internal").
+	Name string
+
+	// Binding is the TCP binding of the listener.
+	Binding TCPBinding
+
+	// CertFileName is the SSL certificate file of the listener. Relative paths are relative to the certs-folder.
+	// If no certificate is configured the listener serves unencrypted HTTP.
+	CertFileName string
+
+	// KeyFileName is the SSL certificate key file of the listener. Relative paths are relative to the certs-folder.
+	KeyFileName string
+
+	// RedirectTo is the base URL (e.g. "https://example.com") to which all requests are redirected
+	// instead of being served.
+	RedirectTo string
+
+	// Internal is flag indicating whether the requests are served without authentication,
+	// network access rules and compression (like the DOCX conversion endpoint).
+	// Internal listeners must be bound to a loopback address.
+	Internal bool
+}
+
+// IsSecure returns a flag indicating whether the listener serves HTTPS.
+func (listener *Listener) IsSecure() bool {
+	return strings.TrimSpace(listener.CertFileName) != ""
+}
+
+// ServesUnencryptedContent returns a flag indicating whether the listener serves the repository over plain HTTP,
+// i.e. it neither has a certificate nor redirects its requests nor is internal.
+func (listener *Listener) ServesUnencryptedContent() bool {
+	return !listener.IsSecure() && listener.RedirectTo == "" && !listener.Internal
+}
+
+// String returns the name of the listener or its binding if no name is configured.
+func (listener *Listener) String() string {
+	if listener.Name != "" {
+		return listener.Name
+	}
+
+	tcpAddress := listener.Binding.GetTCPAddress()
+	return tcpAddress.String()
+}
+
+// UnixSocket contains the settings for serving HTTP on a Unix domain socket
+// or on sockets that are passed in by systemd (socket activation).
+type UnixSocket struct {
+	// Path is the file path of the Unix domain socket (e.g. "/run/allmark/allmark.sock").
+	// Relative paths are relative to the meta-data folder. If no path is given no socket is created.
+	Path string
+
+	// Mode is the octal file mode of the socket file (e.g. "0660").
+	Mode string
+
+	// SystemdActivation is flag indicating whether the sockets passed in by systemd shall be used.
+	SystemdActivation bool
+}
+
+// FileMode returns the file mode of the socket file.
+func (socket *UnixSocket) FileMode() (os.FileMode, error) {
+	mode := strings.TrimSpace(socket.Mode)
+	if mode == "" {
+		mode = DefaultUnixSocketMode
+	}
+
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > 0777 {
+		return 0, fmt.Errorf("%q is not a valid file mode", socket.Mode)
+	}
+
+	return os.FileMode(value), nil
 }
 
 // Authentication contains authentication settings.
@@ -289,6 +650,129 @@ type Authentication struct {
 
 	// UserStoreFileName defines the file name for the authentication user-store file (e.g. "users.htpasswd").
 	UserStoreFileName string
+
+	// Groups maps group names to the names of the users that belong to the group.
+	Groups map[string][]string
+
+	// Rules define which routes require authentication.
+	// If no rules are defined all routes require authentication.
+	Rules []AuthenticationRule
+}
+
+// Minification contains the HTML minification settings.
+type Minification struct {
+	// Enabled is flag indicating whether rendered HTML is minified before it is sent to the client.
+	Enabled bool
+}
+
+// CORS contains the cross-origin resource sharing settings for the JSON, RSS and search endpoints.
+type CORS struct {
+	// Enabled is flag indicating whether cross-origin requests are allowed.
+	Enabled bool
+
+	// AllowedOrigins is the list of origins (e.g. "https://app.example.com") that are allowed to access the endpoints. "*" allows all origins.
+	AllowedOrigins []string
+
+	// AllowedMethods is the list of HTTP methods that can be used for cross-origin requests.
+	AllowedMethods []string
+
+	// AllowedHeaders is the list of additional request headers that can be used for cross-origin requests.
+	AllowedHeaders []string
+
+	// MaxAgeInSeconds defines for how long the results of a preflight request can be cached.
+	MaxAgeInSeconds int
+}
+
+// RateLimiting contains the per-client rate limits and the concurrent request limits of the web server.
+type RateLimiting struct {
+	// Enabled is flag indicating whether the rate and concurrency limits are enforced.
+	Enabled bool
+
+	// RequestsPerSecond is the number of requests per second a single client (IP address) can make on average.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests a single client can make at once before the rate limit kicks in.
+	Burst int
+
+	// MaxConcurrentRequests is the maximum number of requests that are processed at the same time (0 = unlimited).
+	MaxConcurrentRequests int
+
+	// MaxConcurrentExpensiveRequests is the maximum number of search and conversion requests that are processed at the same time (0 = unlimited).
+	MaxConcurrentExpensiveRequests int
+}
+
+// Metrics contains the settings for the Prometheus metrics endpoint.
+type Metrics struct {
+	// Enabled is flag indicating whether the metrics are exposed under /metrics.
+	Enabled bool
+}
+
+// Debug contains the settings for the profiling and diagnostics endpoints.
+type Debug struct {
+	// Enabled is flag indicating whether the pprof profiles are exposed under /-/debug/pprof/ and
+	// a snapshot of the runtime statistics under /-/debug/vars.
+	Enabled bool
+
+	// LocalhostOnly is flag indicating whether the debug endpoints only answer requests from the loopback interface.
+	// Otherwise the debug endpoints always require authentication.
+	LocalhostOnly bool
+
+	// UnixSocketIsLocal is flag indicating whether requests over the Unix domain sockets count as requests
+	// from the loopback interface (see LocalhostOnly).
+	UnixSocketIsLocal bool
+}
+
+// AccessLog contains the settings for the HTTP access log.
+type AccessLog struct {
+	// Enabled is flag indicating whether requests are written to the access log.
+	Enabled bool
+
+	// Format is the format of the access log entries ("common", "combined" or "json").
+	Format string
+
+	// FileName is the path of the access log file. Relative paths are relative to the meta-data folder.
+	// If no file name is given the entries are written to the standard output.
+	FileName string
+}
+
+// WriteAPI contains the settings for the endpoints of the REST API which create, update and delete items and files.
+type WriteAPI struct {
+	// Enabled is flag indicating whether items and files can be modified through the REST API.
+	// The write API is only available if authentication is enabled.
+	Enabled bool
+
+	// Users is the list of users that are allowed to modify items and files.
+	Users []string
+
+	// Groups is the list of groups whose users are allowed to modify items and files.
+	// If neither users nor groups are specified all authenticated users can modify items and files.
+	Groups []string
+
+	// MaxUploadSizeInMegabytes is the maximum size of a markdown document or file that can be uploaded.
+	MaxUploadSizeInMegabytes int
+}
+
+// ZIPDownload contains the settings for the endpoint which creates ZIP archives of items and their descendants.
+type ZIPDownload struct {
+	// Enabled is flag indicating whether items and their descendants can be downloaded as ZIP archives.
+	// The ZIP downloads are only available if authentication is enabled.
+	Enabled bool
+
+	// Users is the list of users that are allowed to download ZIP archives.
+	Users []string
+
+	// Groups is the list of groups whose users are allowed to download ZIP archives.
+	// If neither users nor groups are specified all authenticated users can download ZIP archives.
+	Groups []string
+}
+
+// GraphQL contains the settings for the GraphQL endpoint.
+type GraphQL struct {
+	// Enabled is flag indicating whether the items, files and tags can be queried with GraphQL.
+	Enabled bool
+
+	// MaxDepth is the maximum nesting depth of the selections of a query.
+	MaxDepth int
 }
 
 // Web contains all web-site related properties such as the language, authors and publisher information.
@@ -298,6 +782,290 @@ type Web struct {
 	DefaultAuthor    string
 	Publisher        UserInformation
 	Authors          map[string]UserInformation
+	ErrorPages       ErrorPages
+
+	// Locale is the locale of the user interface labels (e.g. "de") for pages whose language has no locale of its own.
+	// If empty, English is used.
+	Locale string
+
+	// Snippets contains the HTML snippets which are inserted into every page.
+	Snippets Snippets
+
+	// MIMETypes contains custom MIME types for the file extensions of attachments.
+	MIMETypes MIMETypes
+
+	// Feeds contains the settings of the RSS, Atom and JSON feeds.
+	Feeds Feeds
+
+	// RobotsTxt contains the rules of the robots.txt.
+	RobotsTxt RobotsTxt
+
+	// RelatedItems contains the settings of the related items which are listed on the item pages.
+	RelatedItems RelatedItems
+
+	// RecentChanges contains the settings of the recent changes page and feed.
+	RecentChanges RecentChanges
+
+	// Pagination contains the settings of the paged child and tag listings.
+	Pagination Pagination
+
+	// Children contains the settings of the child listings.
+	Children Children
+
+	// Branding contains the logo and the favicon of the site.
+	Branding Branding
+
+	// WebApp contains the settings of the web app manifest and the service worker which make the site
+	// installable and readable offline.
+	WebApp WebApp
+}
+
+// WebApp contains the settings of the web app manifest and the service worker of the site.
+type WebApp struct {
+	// Enabled is flag indicating whether the web app manifest and the service worker are served and linked from every page.
+	Enabled bool
+
+	// ShortName is the name of the installed app on the home screen; if empty the name of the repository is used.
+	ShortName string
+
+	// ThemeColor and BackgroundColor are the colors of the browser interface and of the splash screen of the installed app.
+	ThemeColor      string
+	BackgroundColor string
+
+	// PrecacheRoute is the route of an item (e.g. "documentation") which is cached with all of its descendants
+	// when the service worker is installed. The start page, the theme and the visited items are always cached.
+	PrecacheRoute string
+
+	// MaxPrecachedItems is the maximum number of items of the precache route which are cached on installation.
+	MaxPrecachedItems int
+}
+
+// MaxItems returns the maximum number of items which are cached when the service worker is installed.
+func (webApp WebApp) MaxItems() int {
+	if webApp.MaxPrecachedItems <= 0 {
+		return DefaultWebAppMaxPrecachedItems
+	}
+
+	return webApp.MaxPrecachedItems
+}
+
+// Branding contains the logo of the site and the image from which its favicons and touch icons are generated.
+type Branding struct {
+	// Logo is the path of an image of the repository (e.g. "files/logo.png") which is displayed in the top navigation.
+	Logo string
+
+	// Favicon is the path of a PNG or JPEG image of the repository (e.g. "files/icon.png") from which the
+	// favicons and touch icons are generated. If empty, the logo is used.
+	Favicon string
+}
+
+// IconSource returns the path of the image from which the favicons and touch icons are generated;
+// it returns an empty string if neither a favicon nor a logo is configured.
+func (branding Branding) IconSource() string {
+	if favicon := strings.TrimSpace(branding.Favicon); favicon != "" {
+		return favicon
+	}
+
+	return strings.TrimSpace(branding.Logo)
+}
+
+// The orders in which the children of an item can be listed.
+const (
+	// ChildSortOrderDate lists the newest children first.
+	ChildSortOrderDate = "date"
+
+	// ChildSortOrderTitle lists the children alphabetically by their title.
+	ChildSortOrderTitle = "title"
+
+	// ChildSortOrderWeight lists the children by their weight (lower weights first).
+	ChildSortOrderWeight = "weight"
+)
+
+// IsChildSortOrder returns true if the given name (e.g. "title") is one of the known child sort orders.
+func IsChildSortOrder(name string) bool {
+	switch name {
+	case ChildSortOrderDate, ChildSortOrderTitle, ChildSortOrderWeight:
+		return true
+	}
+
+	return false
+}
+
+// Children contains the settings of the listings of the children of an item.
+type Children struct {
+	// SortOrder is the order of the children of the items which don't define an order of their own
+	// ("date", "title" or "weight").
+	SortOrder string
+}
+
+// Order returns the configured child sort order or the default order if the configured one is unknown.
+func (children Children) Order() string {
+	if order := strings.ToLower(strings.TrimSpace(children.SortOrder)); IsChildSortOrder(order) {
+		return order
+	}
+
+	return DefaultChildSortOrder
+}
+
+// Pagination contains the settings of the listings of the children of an item and of the items of a tag
+// which are split into pages.
+type Pagination struct {
+	// ItemsPerPage is the maximum number of items per page.
+	ItemsPerPage int
+}
+
+// PageSize returns the maximum number of items per page.
+func (pagination Pagination) PageSize() int {
+	if pagination.ItemsPerPage <= 0 {
+		return DefaultItemsPerPage
+	}
+
+	return pagination.ItemsPerPage
+}
+
+// RecentChanges contains the settings of the page and the feed of the most recently modified items.
+type RecentChanges struct {
+	// Count is the maximum number of items on the recent changes page and in its feed.
+	Count int
+}
+
+// Limit returns the maximum number of recently modified items.
+func (recentChanges RecentChanges) Limit() int {
+	if recentChanges.Count <= 0 {
+		return DefaultRecentChangesCount
+	}
+
+	return recentChanges.Count
+}
+
+// RelatedItems contains the settings of the items which are related to an item because they share its tags
+// or are linked together with it by other items.
+type RelatedItems struct {
+	// Enabled is a flag indicating whether the related items are listed on the item pages and in the API.
+	Enabled bool
+
+	// Count is the maximum number of related items per item.
+	Count int
+}
+
+// Limit returns the maximum number of related items per item; or zero if the related items are disabled.
+func (relatedItems RelatedItems) Limit() int {
+	if !relatedItems.Enabled {
+		return 0
+	}
+
+	if relatedItems.Count <= 0 {
+		return DefaultRelatedItemsCount
+	}
+
+	return relatedItems.Count
+}
+
+// RobotsTxt contains the rules of the robots.txt.
+type RobotsTxt struct {
+	// Rules replace the built-in rules of the robots.txt if at least one rule is configured.
+	Rules []RobotsTxtRule
+
+	// DisableSitemap removes the link to the XML sitemap from the robots.txt.
+	DisableSitemap bool
+}
+
+// HasRules returns a flag indicating whether custom rules are configured for the robots.txt.
+func (robotsTxt RobotsTxt) HasRules() bool {
+	return len(robotsTxt.Rules) > 0
+}
+
+// RobotsTxtRule contains the paths a user agent (e.g. "*" or "Googlebot") is allowed or not allowed to crawl.
+type RobotsTxtRule struct {
+	UserAgent string
+	Allow     []string
+	Disallow  []string
+}
+
+// Agent returns the user agent of the rule; or "*" (all user agents) if no user agent is configured.
+func (rule RobotsTxtRule) Agent() string {
+	if strings.TrimSpace(rule.UserAgent) == "" {
+		return "*"
+	}
+
+	return strings.TrimSpace(rule.UserAgent)
+}
+
+// The sort orders of the feed items.
+const (
+	// FeedSortByCreated sorts the feed items by their creation date (newest first).
+	FeedSortByCreated = "created"
+
+	// FeedSortByModified sorts the feed items by their last modification date (most recently modified first).
+	FeedSortByModified = "modified"
+
+	// FeedSortByTitle sorts the feed items alphabetically by their title.
+	FeedSortByTitle = "title"
+)
+
+// The content modes of the RSS feeds.
+const (
+	// RSSContentFull embeds the complete HTML of the items into the RSS feeds.
+	RSSContentFull = "full"
+
+	// RSSContentSummary only adds the descriptions of the items to the RSS feeds.
+	RSSContentSummary = "summary"
+)
+
+// Feeds contains the settings of the RSS, Atom and JSON feeds.
+type Feeds struct {
+	// ItemsPerPage is the number of items per feed page.
+	ItemsPerPage int
+
+	// SortBy defines the order of the feed items ("created", "modified" or "title").
+	SortBy string
+
+	// RSS contains the settings which only apply to the RSS feeds.
+	RSS RSSFeeds
+}
+
+// PageSize returns the number of items per feed page.
+func (feeds Feeds) PageSize() int {
+	if feeds.ItemsPerPage <= 0 {
+		return DefaultFeedItemsPerPage
+	}
+
+	return feeds.ItemsPerPage
+}
+
+// SortOrder returns the configured sort order of the feed items; or the default sort order if the configured one is unknown.
+func (feeds Feeds) SortOrder() string {
+	sortBy := strings.ToLower(strings.TrimSpace(feeds.SortBy))
+
+	switch sortBy {
+	case FeedSortByCreated, FeedSortByModified, FeedSortByTitle:
+		return sortBy
+	}
+
+	return DefaultFeedSortBy
+}
+
+// RSSFeeds contains the settings of the RSS feeds.
+type RSSFeeds struct {
+	// Content defines whether the complete HTML ("full") or only the description ("summary") of the items is embedded.
+	Content string
+
+	// Enclosures defines whether an audio, video or image file of each item is added as an enclosure (e.g. for podcasts).
+	Enclosures bool
+}
+
+// FullContent returns a flag indicating whether the complete HTML of the items shall be embedded into the RSS feeds.
+func (rss RSSFeeds) FullContent() bool {
+	return !strings.EqualFold(strings.TrimSpace(rss.Content), RSSContentSummary)
+}
+
+// ErrorPages contains the routes of repository items that are displayed instead of the built-in error pages.
+type ErrorPages struct {
+	// NotFound is the route of the item that is displayed if a resource was not found (e.g. "/_errors/404").
+	NotFound string
+
+	// InternalServerError is the route of the item that is displayed if an internal error occurred (e.g. "/_errors/500").
+	InternalServerError string
 }
 
 // UserInformation contains user-related properties such as the Name and Email address.
@@ -315,25 +1083,203 @@ type UserInformation struct {
 type Server struct {
 	ThemeFolderName string
 	DomainName      string
+	BasePath        string
 	HTTP            HTTP
 	HTTPS           HTTPS
+	HTTP2           HTTP2
+	UnixSocket      UnixSocket
+	Listeners       []Listener
 	Authentication  Authentication
+	Minification    Minification
+	CORS            CORS
+	SecurityHeaders SecurityHeaders
+	CacheControl    CacheControl
+	RateLimiting    RateLimiting
+	Metrics         Metrics
+	Debug           Debug
+	AccessLog       AccessLog
+	Network         Network
+	WriteAPI        WriteAPI
+	ZIPDownload     ZIPDownload
+	GraphQL         GraphQL
+
+	// ShutdownTimeoutInSeconds is the maximum time the server waits for in-flight requests
+	// to complete when it is stopped or restarted.
+	ShutdownTimeoutInSeconds int
 }
 
 // Indexing defines the reindexing parameters of the repository.
 type Indexing struct {
 	Enabled           bool
 	IntervalInSeconds int
+
+	// Workers is the number of items which are read, hashed and parsed at the same time (0 = number of CPUs).
+	Workers int
+}
+
+// NumberOfWorkers returns the number of items which are read, hashed and parsed at the same time.
+func (indexing Indexing) NumberOfWorkers() int {
+	if indexing.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+
+	return indexing.Workers
+}
+
+// Caching contains the settings of the caches for rendered content.
+type Caching struct {
+	HTML   HTMLCache
+	WarmUp WarmUp
+}
+
+// HTMLCache contains the settings of the cache for the converted HTML of the items.
+type HTMLCache struct {
+	Enabled bool
+
+	// MaxSizeInMegabytes is the maximum size of the converted HTML which is kept in memory; 0 means unlimited.
+	MaxSizeInMegabytes int
+
+	// Persistent defines whether the converted HTML is also stored in the meta-data folder, so it is
+	// still available after a restart.
+	Persistent bool
+
+	// FolderName is the name of the folder in the meta-data folder in which the converted HTML is stored.
+	FolderName string
+}
+
+// WarmUp defines which items are rendered right after startup, so the first visitors
+// don't have to wait for the conversion of these items and their thumbnails.
+type WarmUp struct {
+	Enabled bool
+
+	// MostLinkedItems is the number of items with the most backlinks which are rendered.
+	MostLinkedItems int
+
+	// Routes contains the routes of additional items which are rendered (e.g. "documents/getting-started").
+	Routes []string
+}
+
+// Resources contains the budget of the background tasks (the thumbnail conversion, the search indexing
+// and the conversion of the items). Tasks which would exceed the budget wait for the running tasks.
+type Resources struct {
+	// MaxMemoryInMegabytes is the estimated memory which the background tasks may use at the same time; 0 means unlimited.
+	MaxMemoryInMegabytes int
+
+	// MaxConcurrentTasks is the number of background tasks which run at the same time;
+	// 0 means the number of CPUs.
+	MaxConcurrentTasks int
+}
+
+// MemoryLimit returns the memory budget of the background tasks in bytes (0 means unlimited).
+func (resources Resources) MemoryLimit() int64 {
+	if resources.MaxMemoryInMegabytes <= 0 {
+		return 0
+	}
+
+	return int64(resources.MaxMemoryInMegabytes) * 1024 * 1024
+}
+
+// TaskLimit returns the number of background tasks which run at the same time.
+func (resources Resources) TaskLimit() int {
+	if resources.MaxConcurrentTasks <= 0 {
+		return runtime.NumCPU()
+	}
+
+	return resources.MaxConcurrentTasks
+}
+
+// Search contains the settings of the full-text search.
+type Search struct {
+	// IndexFileName is the name of the directory in the meta-data folder in which the search index is stored
+	// between restarts, so only new and modified items have to be indexed on startup.
+	IndexFileName string
+
+	// Language is the language (e.g. "en", "de" or "fr") whose stemmer and stop words are applied
+	// to the items without a language. If not set, the default language of the web settings is used.
+	Language string
+
+	Attachments SearchAttachments
+	Ranking     SearchRanking
+}
+
+// SearchRanking defines how matches in the different parts of the items and the age of the items are weighted.
+type SearchRanking struct {
+	// The factors by which the scores of matches in the titles, descriptions, tags, aliases, routes and contents
+	// of the items are multiplied. Factors which are not positive are replaced by the defaults.
+	TitleBoost       float64
+	DescriptionBoost float64
+	TagsBoost        float64
+	AliasesBoost     float64
+	RouteBoost       float64
+	ContentBoost     float64
+
+	// RecencyBoost is the share by which the scores of items which have just been modified are increased;
+	// it is halved with every RecencyHalfLifeInDays the item is older. 0 disables the recency boost.
+	RecencyBoost          float64
+	RecencyHalfLifeInDays int
 }
 
+// SearchAttachments defines whether and how the text of attached files (PDF, DOCX and text files) is indexed.
+type SearchAttachments struct {
+	Enabled bool
+
+	// MaxFileSizeInMB is the size of the largest file whose text is extracted.
+	MaxFileSizeInMB int
+
+	// PDFToTextPath is the path of the pdftotext tool (poppler). If the tool is not available
+	// the text of PDF files is extracted with a built-in parser which is less accurate.
+	PDFToTextPath string
+}
+
+// MaxFileSize returns the size (in bytes) of the largest file whose text is extracted.
+func (attachments SearchAttachments) MaxFileSize() int64 {
+	if attachments.MaxFileSizeInMB <= 0 {
+		return DefaultSearchAttachmentMaxSizeMB << 20
+	}
+
+	return int64(attachments.MaxFileSizeInMB) << 20
+}
+
+// The live-reload modes.
+const (
+	// LiveReloadModeMorph updates the content and the navigation of an open page in place.
+	LiveReloadModeMorph = "morph"
+
+	// LiveReloadModeReload reloads an open page completely.
+	LiveReloadModeReload = "reload"
+)
+
 // LiveReload defines the live-reload capabilities.
 type LiveReload struct {
 	Enabled bool
+
+	// Mode defines how open pages are updated ("morph" or "reload").
+	Mode string
+
+	// DebounceInMilliseconds is the quiet period after a change before the open pages are updated,
+	// so that a burst of changes (e.g. an editor saving several files) results in a single update.
+	DebounceInMilliseconds int
+}
+
+// Debounce returns the quiet period after a change before the open pages are updated.
+func (liveReload LiveReload) Debounce() time.Duration {
+	if liveReload.DebounceInMilliseconds <= 0 {
+		return DefaultLiveReloadDebounceInMS * time.Millisecond
+	}
+
+	return time.Duration(liveReload.DebounceInMilliseconds) * time.Millisecond
+}
+
+// ReloadPages returns a flag indicating whether open pages shall be reloaded completely instead of being updated in place.
+func (liveReload LiveReload) ReloadPages() bool {
+	return strings.EqualFold(strings.TrimSpace(liveReload.Mode), LiveReloadModeReload)
 }
 
 // Conversion defines the rich-text and thumbnail conversion paramters.
 type Conversion struct {
 	DOCX       DOCXConversion
+	PDF        PDFConversion
+	EPUB       EPUBConversion
 	Thumbnails ThumbnailConversion
 }
 
@@ -342,21 +1288,76 @@ func (c Conversion) EndpointBinding() *TCPBinding {
 	return conversionEndpointBinding
 }
 
-// DOCXConversion contains rich-text (DOCX) conversion parameters.
+// DOCXConversion contains rich-text (DOCX and ODT) conversion parameters.
 type DOCXConversion struct {
 	Enabled bool
+
+	// ToolPath is the path of the rich-text conversion tool (pandoc).
+	ToolPath string
+
+	// FolderName is the name of the folder in the meta data folder where the converted documents are cached.
+	FolderName string
 }
 
 // Tool returns the path of the external rich-text conversion tool (pandoc) used
 // to create Rich-text documents from repository items.
 func (docx DOCXConversion) Tool() string {
+	if toolPath := strings.TrimSpace(docx.ToolPath); toolPath != "" {
+		return toolPath
+	}
+
 	return DefaultConversionToolPath
 }
 
 // IsEnabled returns a flag indicating if rich-text conversion is enabled or not.
-// Rich-text conversion can only be enabled if the conversion tool was found in the PATH on startup.
+// Rich-text conversion can only be enabled if the conversion tool is available.
 func (docx DOCXConversion) IsEnabled() bool {
-	return docx.Enabled && docxConversionToolIsAvailable
+	return docx.Enabled && isToolAvailable(docx.Tool())
+}
+
+// PDFConversion contains the parameters of the conversion of the print views to PDF documents.
+type PDFConversion struct {
+	Enabled bool
+
+	// ToolPath is the path of the HTML-to-PDF conversion tool (wkhtmltopdf).
+	ToolPath string
+
+	// PageSize is the paper size of the PDF documents (e.g. "A4" or "Letter").
+	PageSize string
+
+	// Header and Footer are the texts of the page headers and footers; "[title]", "[section]",
+	// "[page]", "[topage]" and "[date]" are replaced with the respective values.
+	Header string
+	Footer string
+}
+
+// Tool returns the path of the external HTML-to-PDF conversion tool.
+func (pdf PDFConversion) Tool() string {
+	if toolPath := strings.TrimSpace(pdf.ToolPath); toolPath != "" {
+		return toolPath
+	}
+
+	return DefaultPDFConversionToolPath
+}
+
+// GetPageSize returns the paper size of the PDF documents.
+func (pdf PDFConversion) GetPageSize() string {
+	if pageSize := strings.TrimSpace(pdf.PageSize); pageSize != "" {
+		return pageSize
+	}
+
+	return DefaultPDFPageSize
+}
+
+// IsEnabled returns a flag indicating if PDF conversion is enabled or not.
+// PDF conversion can only be enabled if the conversion tool is available.
+func (pdf PDFConversion) IsEnabled() bool {
+	return pdf.Enabled && isToolAvailable(pdf.Tool())
+}
+
+// EPUBConversion contains the parameters of the conversion of items and their descendants to e-books.
+type EPUBConversion struct {
+	Enabled bool
 }
 
 // ThumbnailConversion defines the image-thumbnail conversion capabilities.
@@ -378,15 +1379,125 @@ type GoogleAnalytics struct {
 	TrackingID string
 }
 
+// The events which can be sent to webhooks.
+const (
+	WebhookEventItemCreated       = "item.created"
+	WebhookEventItemUpdated       = "item.updated"
+	WebhookEventItemDeleted       = "item.deleted"
+	WebhookEventRepositoryIndexed = "repository.indexed"
+)
+
+// Webhook is a URL which is notified about changes in the repository.
+type Webhook struct {
+	// URL is the address the events are posted to (e.g. "https://ci.example.com/hooks/allmark").
+	URL string
+
+	// Secret is used to sign the payloads. If set, the HMAC-SHA256 signature of the request body
+	// is sent in the "X-Allmark-Signature" header.
+	Secret string
+
+	// Events is the list of events the webhook receives (e.g. "item.created", "repository.indexed").
+	// If no events are specified the webhook receives all events.
+	Events []string
+}
+
+// ReceivesEvent returns a flag indicating whether the webhook has subscribed to the given event.
+func (webhook Webhook) ReceivesEvent(event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+
+	for _, subscribedEvent := range webhook.Events {
+		if strings.EqualFold(strings.TrimSpace(subscribedEvent), event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Logging contains the settings of the application log (the log level is configured by Config.LogLevel).
+type Logging struct {
+	// Format is the format of the log messages: "text" or "json" (one JSON object per line with the
+	// time, the level, the module, the message and the fields of a message).
+	Format string
+
+	// Levels contains the log levels of the modules (e.g. {"watcher": "debug", "thumbnail": "warn"})
+	// which override the log level for the messages of the modules.
+	Levels map[string]string
+
+	// Backend is where the log messages are written to: "console" (the standard error output or the log file),
+	// "syslog" or "journald". If no backend is configured the messages are written to the systemd journal if
+	// the standard error output is connected to the journal and to the console otherwise.
+	Backend string
+
+	// File contains the settings of the log file of the console backend.
+	File LogFile
+
+	// Syslog contains the settings of the syslog backend.
+	Syslog Syslog
+}
+
+// Syslog contains the settings of the syslog backend.
+type Syslog struct {
+	// Network and Address are the address of the syslog daemon (e.g. "udp" and "logs.example.com:514").
+	// If they are empty the messages are sent to the local syslog daemon.
+	Network string
+	Address string
+
+	// Tag is the tag of the messages (e.g. "allmark").
+	Tag string
+}
+
+// LogFile contains the settings of the log file and of its rotation.
+type LogFile struct {
+	// FileName is the path of the log file. Relative paths are relative to the meta-data folder.
+	// If no file name is given the messages are written to the standard error output.
+	FileName string
+
+	// MaxSizeInMegabytes is the size at which the log file is rotated; 0 disables the size-based rotation.
+	MaxSizeInMegabytes int
+
+	// RotationIntervalInHours is the interval after which the log file is rotated (e.g. 24 for a
+	// daily rotation at midnight UTC); 0 disables the time-based rotation.
+	RotationIntervalInHours int
+
+	// MaxBackups is the number of rotated log files which are kept; 0 keeps all of them.
+	MaxBackups int
+
+	// MaxAgeInDays is the age after which rotated log files are removed; 0 keeps all of them.
+	MaxAgeInDays int
+}
+
+// ModuleLogLevels returns the log levels of the modules by their lower-case names.
+func (logging Logging) ModuleLogLevels() map[string]loglevel.LogLevel {
+	levels := make(map[string]loglevel.LogLevel, len(logging.Levels))
+	for module, level := range logging.Levels {
+		levels[strings.ToLower(strings.TrimSpace(module))] = loglevel.FromString(level)
+	}
+
+	return levels
+}
+
 // Config is the main configuration model for all parts of allmark.
 type Config struct {
 	Server     Server
 	Web        Web
 	Conversion Conversion
 	LogLevel   string
+	Logging    Logging
 	Indexing   Indexing
+	Caching    Caching
+	Resources  Resources
+	Search     Search
+	Theme      Theme
 	LiveReload LiveReload
 	Analytics  Analytics
+	Webhooks   []Webhook
+
+	// Profiles contains named sets of settings (e.g. "dev", "public") which override the
+	// settings of the configuration if they are selected (see ApplyProfile).
+	Profiles map[string]Profile
 
 	baseFolder      string
 	metaDataFolder  string
@@ -399,6 +1510,44 @@ func (config *Config) CertificateDirectory() string {
 	return filepath.Join(config.MetaDataFolder(), SSLCertsFolderName)
 }
 
+// ListenerCertificateFilePaths returns the paths of the SSL certificate and key file of the given listener.
+func (config *Config) ListenerCertificateFilePaths(listener Listener) (certificateFilePath, keyFilePath string, err error) {
+	if !listener.IsSecure() {
+		return "", "", nil
+	}
+
+	if strings.TrimSpace(listener.KeyFileName) == "" {
+		return "", "", fmt.Errorf("No key file configured for the listener %q", listener.String())
+	}
+
+	certificateFilePath, err = config.secretFilePath(listener.CertFileName, config.CertificateDirectory())
+	if err != nil {
+		return "", "", err
+	}
+
+	keyFilePath, err = config.secretFilePath(listener.KeyFileName, config.CertificateDirectory())
+	if err != nil {
+		return "", "", err
+	}
+
+	return certificateFilePath, keyFilePath, nil
+}
+
+// LetsEncryptCacheDirectory returns the path of the directory in the meta-data folder
+// where the certificates obtained from Let's Encrypt are stored.
+func (config *Config) LetsEncryptCacheDirectory() string {
+	return filepath.Join(config.MetaDataFolder(), LetsEncryptFolderName)
+}
+
+// LetsEncryptHostnames returns the hostnames for which certificates will be requested from Let's Encrypt.
+func (config *Config) LetsEncryptHostnames() []string {
+	if len(config.Server.HTTPS.LetsEncrypt.Hostnames) > 0 {
+		return config.Server.HTTPS.LetsEncrypt.Hostnames
+	}
+
+	return []string{config.Server.DomainName}
+}
+
 // CertificateFilePaths returns the SSL certificate and key file paths.
 // If none are configured or the configured ones don't exist it will create new
 // ones and return the paths of the newly generates certificate/key pair.
@@ -410,24 +1559,17 @@ func (config *Config) CertificateFilePaths() (certificateFilePath, keyFilePath s
 		domainname = DefaultDomainName
 	}
 
-	// Determine  the cert name
-	certificateFileName := config.Server.HTTPS.CertFileName
-	if certificateFileName == "" {
-		certificateFileName = DefaultHTTPSCertName
-	}
-
-	// Determine the key name
-	keyFileName := config.Server.HTTPS.KeyFileName
-	if keyFileName == "" {
-		keyFileName = DefaultHTTPSKeyName
-	}
-
 	// Determine the base directory for the certificates
 	certificateBaseDirectory := config.CertificateDirectory()
 
 	// Default cert and key path
-	certificateFilePath = filepath.Join(certificateBaseDirectory, certificateFileName)
-	keyFilePath = filepath.Join(certificateBaseDirectory, keyFileName)
+	certificateFilePath, keyFilePath, err := config.httpsCertificateFilePaths()
+	if err != nil {
+		panic(fmt.Sprintf("Could not determine the certificate and key of the HTTPS endpoint. Error: %s", err.Error()))
+	}
+
+	certificateFileName := filepath.Base(certificateFilePath)
+	keyFileName := filepath.Base(keyFilePath)
 
 	// check if the specified file exists
 	if fsutil.FileExists(certificateFilePath) && fsutil.FileExists(keyFilePath) {
@@ -464,6 +1606,34 @@ func (config *Config) CertificateFilePaths() (certificateFilePath, keyFilePath s
 	return certificateFilePath, keyFilePath, true
 }
 
+// httpsCertificateFilePaths returns the paths of the configured (or default) SSL certificate and key file of the HTTPS endpoint.
+func (config *Config) httpsCertificateFilePaths() (certificateFilePath, keyFilePath string, err error) {
+
+	// Determine  the cert name
+	certificateFileName := config.Server.HTTPS.CertFileName
+	if certificateFileName == "" {
+		certificateFileName = DefaultHTTPSCertName
+	}
+
+	// Determine the key name
+	keyFileName := config.Server.HTTPS.KeyFileName
+	if keyFileName == "" {
+		keyFileName = DefaultHTTPSKeyName
+	}
+
+	certificateFilePath, err = config.secretFilePath(certificateFileName, config.CertificateDirectory())
+	if err != nil {
+		return "", "", err
+	}
+
+	keyFilePath, err = config.secretFilePath(keyFileName, config.CertificateDirectory())
+	if err != nil {
+		return "", "", err
+	}
+
+	return certificateFilePath, keyFilePath, nil
+}
+
 // BaseFolder returns the path of the base folder of the current configuration model.
 func (config *Config) BaseFolder() string {
 	return config.baseFolder
@@ -504,6 +1674,40 @@ func (config *Config) ThumbnailIndexFilePath() string {
 	return filepath.Join(config.MetaDataFolder(), filename)
 }
 
+// SearchIndexFilePath returns the path of the search index file.
+func (config *Config) SearchIndexFilePath() string {
+	filename := SearchIndexFileName
+	if config.Search.IndexFileName != "" {
+		filename = config.Search.IndexFileName
+	}
+
+	return filepath.Join(config.MetaDataFolder(), filename)
+}
+
+// SearchLanguage returns the language by which items without a language of their own are analyzed for the search.
+func (config *Config) SearchLanguage() string {
+	if config.Search.Language != "" {
+		return config.Search.Language
+	}
+
+	return config.Web.DefaultLanguage
+}
+
+// HTMLCacheFolder returns the path of the folder in which the converted HTML is stored
+// or an empty string if the HTML cache is not persistent.
+func (config *Config) HTMLCacheFolder() string {
+	if !config.Caching.HTML.Persistent {
+		return ""
+	}
+
+	folderName := HTMLCacheFolderName
+	if config.Caching.HTML.FolderName != "" {
+		folderName = config.Caching.HTML.FolderName
+	}
+
+	return filepath.Join(config.MetaDataFolder(), folderName)
+}
+
 // ThumbnailFolder returns the path of the thumbnail folder.
 func (config *Config) ThumbnailFolder() string {
 	folderName := ThumbnailsFolderName
@@ -514,6 +1718,16 @@ func (config *Config) ThumbnailFolder() string {
 	return filepath.Join(config.MetaDataFolder(), folderName)
 }
 
+// ConversionFolder returns the path of the folder where the converted rich-text documents are cached.
+func (config *Config) ConversionFolder() string {
+	folderName := ConversionsFolderName
+	if config.Conversion.DOCX.FolderName != "" {
+		folderName = config.Conversion.DOCX.FolderName
+	}
+
+	return filepath.Join(config.MetaDataFolder(), folderName)
+}
+
 // Load reads the configuration-model from disk.
 func (config *Config) Load() (*Config, error) {
 
@@ -539,9 +1753,16 @@ func (config *Config) Load() (*Config, error) {
 	config.Web = loadedConfig.Web
 	config.Conversion = loadedConfig.Conversion
 	config.LogLevel = loadedConfig.LogLevel
+	config.Logging = loadedConfig.Logging
 	config.Indexing = loadedConfig.Indexing
+	config.Caching = loadedConfig.Caching
+	config.Resources = loadedConfig.Resources
+	config.Search = loadedConfig.Search
+	config.Theme = loadedConfig.Theme
 	config.LiveReload = loadedConfig.LiveReload
 	config.Analytics = loadedConfig.Analytics
+	config.Webhooks = loadedConfig.Webhooks
+	config.Profiles = loadedConfig.Profiles
 
 	return config, nil
 }
@@ -587,13 +1808,30 @@ func (config *Config) apply(newConfig *Config) (*Config, error) {
 	config.Web = newConfig.Web
 	config.Conversion = newConfig.Conversion
 	config.LogLevel = newConfig.LogLevel
+	config.Logging = newConfig.Logging
 	config.Indexing = newConfig.Indexing
+	config.Caching = newConfig.Caching
+	config.Resources = newConfig.Resources
+	config.Search = newConfig.Search
+	config.Theme = newConfig.Theme
 	config.LiveReload = newConfig.LiveReload
 	config.Analytics = newConfig.Analytics
+	config.Webhooks = newConfig.Webhooks
+	config.Profiles = newConfig.Profiles
 
 	return config, nil
 }
 
+// BasePath returns the normalized path prefix (e.g. "/", "/wiki/") under which the repository is served.
+func (config *Config) BasePath() string {
+	basePath := strings.Trim(strings.TrimSpace(config.Server.BasePath), "/")
+	if basePath == "" {
+		return "/"
+	}
+
+	return "/" + basePath + "/"
+}
+
 // AuthenticationIsEnabled get a flag indicating if authentication is enabled.
 func (config *Config) AuthenticationIsEnabled() bool {
 
@@ -610,15 +1848,107 @@ func (config *Config) AuthenticationIsEnabled() bool {
 	return true
 }
 
+// WriteAPIMaxUploadSize returns the maximum size of markdown documents and files in bytes that can be uploaded through the write API.
+func (config *Config) WriteAPIMaxUploadSize() int64 {
+	if config.Server.WriteAPI.MaxUploadSizeInMegabytes <= 0 {
+		return DefaultWriteAPIMaxUploadSizeInMB << 20
+	}
+
+	return int64(config.Server.WriteAPI.MaxUploadSizeInMegabytes) << 20
+}
+
+// GraphQLMaxDepth returns the maximum nesting depth of GraphQL queries.
+func (config *Config) GraphQLMaxDepth() int {
+	if config.Server.GraphQL.MaxDepth <= 0 {
+		return DefaultGraphQLMaxDepth
+	}
+
+	return config.Server.GraphQL.MaxDepth
+}
+
+// WriteAPIAuthentication returns the authentication settings which restrict the write API to the configured users and groups.
+func (config *Config) WriteAPIAuthentication() Authentication {
+	return config.getRestrictedAuthentication(config.Server.WriteAPI.Users, config.Server.WriteAPI.Groups)
+}
+
+// ZIPDownloadAuthentication returns the authentication settings which restrict the ZIP downloads to the configured users and groups.
+func (config *Config) ZIPDownloadAuthentication() Authentication {
+	return config.getRestrictedAuthentication(config.Server.ZIPDownload.Users, config.Server.ZIPDownload.Groups)
+}
+
+// DebugAuthentication returns the authentication settings which restrict the debug endpoints to authenticated users.
+func (config *Config) DebugAuthentication() Authentication {
+	return config.getRestrictedAuthentication(nil, nil)
+}
+
+// getRestrictedAuthentication returns the authentication settings which require all routes to be accessed by
+// the given users and groups (or by any authenticated user if neither users nor groups are given).
+func (config *Config) getRestrictedAuthentication(users, groups []string) Authentication {
+	return Authentication{
+		Enabled:           config.Server.Authentication.Enabled,
+		UserStoreFileName: config.Server.Authentication.UserStoreFileName,
+		Groups:            config.Server.Authentication.Groups,
+		Rules: []AuthenticationRule{
+			{
+				Route:  "/",
+				Users:  users,
+				Groups: groups,
+			},
+		},
+	}
+}
+
+// AccessLogFilePath returns the path of the access log file or an empty string if
+// the access log is written to the standard output.
+func (config *Config) AccessLogFilePath() string {
+	fileName := strings.TrimSpace(config.Server.AccessLog.FileName)
+	if fileName == "" || filepath.IsAbs(fileName) {
+		return fileName
+	}
+
+	return filepath.Join(config.MetaDataFolder(), fileName)
+}
+
+// LogFilePath returns the path of the log file or an empty string if
+// the log messages are written to the standard error output.
+func (config *Config) LogFilePath() string {
+	fileName := strings.TrimSpace(config.Logging.File.FileName)
+	if fileName == "" || filepath.IsAbs(fileName) {
+		return fileName
+	}
+
+	return filepath.Join(config.MetaDataFolder(), fileName)
+}
+
+// ShutdownTimeout returns the maximum duration the server waits for in-flight requests during a shutdown.
+func (config *Config) ShutdownTimeout() time.Duration {
+	if config.Server.ShutdownTimeoutInSeconds <= 0 {
+		return DefaultShutdownTimeoutInSeconds * time.Second
+	}
+
+	return time.Duration(config.Server.ShutdownTimeoutInSeconds) * time.Second
+}
+
+// UnixSocketFilePath returns the path of the Unix domain socket or an empty string if
+// no socket is configured.
+func (config *Config) UnixSocketFilePath() string {
+	path := strings.TrimSpace(config.Server.UnixSocket.Path)
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(config.MetaDataFolder(), path)
+}
+
 // AuthenticationFilePath returns the path of the authentication file.
-func (config *Config) AuthenticationFilePath() string {
+// Relative paths are relative to the meta-data folder.
+func (config *Config) AuthenticationFilePath() (string, error) {
 
 	if config.Server.Authentication.UserStoreFileName == "" {
 		config.Server.Authentication.UserStoreFileName = DefaultUserStoreFileName
 	}
 
-	digestFilePath := filepath.Join(config.MetaDataFolder(), config.Server.Authentication.UserStoreFileName)
-	return digestFilePath
+	return config.secretFilePath(config.Server.Authentication.UserStoreFileName, config.MetaDataFolder())
 }
 
 // GetAuthenticationUserStore returns a digest-access authentication secret provider function
@@ -630,7 +1960,11 @@ func (config *Config) GetAuthenticationUserStore() auth.SecretProvider {
 	}
 
 	// panic if authentication is enabled but the auth file does not exist.
-	digestFilePath := config.AuthenticationFilePath()
+	digestFilePath, err := config.AuthenticationFilePath()
+	if err != nil {
+		panic(fmt.Sprintf("The authentication user store cannot be used. Error: %s", err.Error()))
+	}
+
 	if !fsutil.FileExists(digestFilePath) {
 		panic(fmt.Sprintf("The specified authentication user store %q does not exist.", digestFilePath))
 	}
@@ -0,0 +1,543 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_BasePath_NoBasePathConfigured_SlashIsReturned(t *testing.T) {
+	// arrange
+	config := &Config{}
+
+	// act
+	result := config.BasePath()
+
+	// assert
+	if result != "/" {
+		t.Errorf("BasePath() should return %q if no base path is configured but returned %q.", "/", result)
+	}
+}
+
+func Test_BasePath_BasePathWithoutSlashes_BasePathIsNormalized(t *testing.T) {
+	// arrange
+	inputs := []string{"wiki", "/wiki", "wiki/", "/wiki/", " /wiki/ "}
+	expected := "/wiki/"
+
+	for _, input := range inputs {
+		config := &Config{
+			Server: Server{
+				BasePath: input,
+			},
+		}
+
+		// act
+		result := config.BasePath()
+
+		// assert
+		if result != expected {
+			t.Errorf("BasePath() should return %q for the base path %q but returned %q.", expected, input, result)
+		}
+	}
+}
+
+func Test_FileMode_InvalidMode_ErrorIsReturned(t *testing.T) {
+	// arrange
+	inputs := []string{"rw-rw----", "0999", "01777"}
+
+	for _, input := range inputs {
+		socket := UnixSocket{Mode: input}
+
+		// act
+		_, err := socket.FileMode()
+
+		// assert
+		if err == nil {
+			t.Errorf("FileMode() should return an error for the mode %q.", input)
+		}
+	}
+}
+
+func Test_FileMode_NoModeConfigured_DefaultModeIsReturned(t *testing.T) {
+	// arrange
+	socket := UnixSocket{}
+
+	// act
+	result, err := socket.FileMode()
+
+	// assert
+	if err != nil || result != 0660 {
+		t.Errorf("FileMode() should return %v if no mode is configured but returned %v (%v).", 0660, result, err)
+	}
+}
+
+func Test_ListenerCertificateFilePaths_RelativeAndAbsolutePaths_PathsAreResolved(t *testing.T) {
+	// arrange
+	config := New("/srv/wiki")
+	listener := Listener{
+		CertFileName: "internal.pem",
+		KeyFileName:  "/etc/ssl/private/internal.key",
+	}
+
+	// act
+	certificateFilePath, keyFilePath, err := config.ListenerCertificateFilePaths(listener)
+
+	// assert
+	if err != nil {
+		t.Fatalf("ListenerCertificateFilePaths() returned an error: %s", err)
+	}
+
+	if certificateFilePath != filepath.Join(config.CertificateDirectory(), "internal.pem") {
+		t.Errorf("The certificate path %q should be relative to the certs folder.", certificateFilePath)
+	}
+
+	if keyFilePath != "/etc/ssl/private/internal.key" {
+		t.Errorf("The absolute key path should be used as-is but was %q.", keyFilePath)
+	}
+}
+
+func Test_ListenerCertificateFilePaths_NoKeyFile_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := New("/srv/wiki")
+	listener := Listener{
+		Name:         "internal",
+		CertFileName: "internal.pem",
+	}
+
+	// act
+	_, _, err := config.ListenerCertificateFilePaths(listener)
+
+	// assert
+	if err == nil {
+		t.Errorf("ListenerCertificateFilePaths() should return an error if no key file is configured.")
+	}
+}
+
+func Test_LiveReloadDebounce_NoDebounceConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	liveReload := LiveReload{}
+
+	// act
+	result := liveReload.Debounce()
+
+	// assert
+	if result != DefaultLiveReloadDebounceInMS*time.Millisecond {
+		t.Errorf("Debounce() should return the default debounce period but returned %s.", result)
+	}
+}
+
+func Test_LiveReloadDebounce_DebounceConfigured_ConfiguredValueIsReturned(t *testing.T) {
+	// arrange
+	liveReload := LiveReload{DebounceInMilliseconds: 1500}
+
+	// act
+	result := liveReload.Debounce()
+
+	// assert
+	if result != 1500*time.Millisecond {
+		t.Errorf("Debounce() should return 1.5s but returned %s.", result)
+	}
+}
+
+func Test_PDFConversionTool_NoToolConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	pdf := PDFConversion{}
+
+	// act
+	result := pdf.Tool()
+
+	// assert
+	if result != DefaultPDFConversionToolPath {
+		t.Errorf("Tool() should return %q but returned %q.", DefaultPDFConversionToolPath, result)
+	}
+}
+
+func Test_PDFConversionIsEnabled_ToolNotFound_FalseIsReturned(t *testing.T) {
+	// arrange
+	pdf := PDFConversion{Enabled: true, ToolPath: "allmark-missing-pdf-tool"}
+
+	// act
+	result := pdf.IsEnabled()
+
+	// assert
+	if result {
+		t.Errorf("IsEnabled() should return false if the conversion tool does not exist.")
+	}
+}
+
+func Test_DOCXConversionIsEnabled_ToolNotFound_FalseIsReturned(t *testing.T) {
+	// arrange
+	docx := DOCXConversion{Enabled: true, ToolPath: "allmark-missing-pandoc"}
+
+	// act
+	result := docx.IsEnabled()
+
+	// assert
+	if result {
+		t.Errorf("IsEnabled() should return false if the conversion tool does not exist.")
+	}
+}
+
+func Test_ConversionFolder_NoFolderNameConfigured_DefaultFolderIsReturned(t *testing.T) {
+	// arrange
+	config := New("/repository")
+
+	// act
+	result := config.ConversionFolder()
+
+	// assert
+	expected := filepath.Join("/repository", MetaDataFolderName, ConversionsFolderName)
+	if result != expected {
+		t.Errorf("ConversionFolder() should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_LiveReloadReloadPages_ModeReload_TrueIsReturned(t *testing.T) {
+	// arrange
+	liveReload := LiveReload{Mode: "Reload"}
+
+	// act
+	result := liveReload.ReloadPages()
+
+	// assert
+	if !result {
+		t.Errorf("ReloadPages() should return true for the mode %q.", liveReload.Mode)
+	}
+}
+
+func Test_LiveReloadReloadPages_NoModeConfigured_FalseIsReturned(t *testing.T) {
+	// arrange
+	liveReload := LiveReload{}
+
+	// act
+	result := liveReload.ReloadPages()
+
+	// assert
+	if result {
+		t.Errorf("ReloadPages() should return false if no mode is configured.")
+	}
+}
+
+func Test_WriteAPIAuthentication_PublicRules_WriteAPIRequiresAuthentication(t *testing.T) {
+	// arrange
+	config := Default("/tmp")
+	config.Server.Authentication.Rules = []AuthenticationRule{{Route: "/", Public: true}}
+
+	// act
+	authentication := config.WriteAPIAuthentication()
+	_, requiresAuthentication := authentication.GetRule("/api/v1/items/documents")
+
+	// assert
+	if !requiresAuthentication {
+		t.Errorf("The write API should require authentication even if all routes are public.")
+	}
+}
+
+func Test_WriteAPIAuthentication_GroupConfigured_OnlyGroupMembersAreAuthorized(t *testing.T) {
+	// arrange
+	config := Default("/tmp")
+	config.Server.Authentication.Groups = map[string][]string{"editors": {"alice"}}
+	config.Server.WriteAPI.Groups = []string{"editors"}
+
+	// act
+	authentication := config.WriteAPIAuthentication()
+	rule, _ := authentication.GetRule("/api/v1/items/documents")
+
+	// assert
+	if !authentication.IsAuthorized(rule, "alice") {
+		t.Errorf("Members of the configured groups should be authorized to use the write API.")
+	}
+
+	if authentication.IsAuthorized(rule, "bob") {
+		t.Errorf("Users who are not members of the configured groups should not be authorized to use the write API.")
+	}
+}
+
+func Test_ZIPDownloadAuthentication_UsersConfigured_OnlyTheUsersAreAuthorized(t *testing.T) {
+	// arrange
+	config := Default("/tmp")
+	config.Server.Authentication.Rules = []AuthenticationRule{{Route: "/", Public: true}}
+	config.Server.ZIPDownload.Users = []string{"alice"}
+
+	// act
+	authentication := config.ZIPDownloadAuthentication()
+	rule, requiresAuthentication := authentication.GetRule("/documents.zip")
+
+	// assert
+	if !requiresAuthentication {
+		t.Errorf("The ZIP downloads should require authentication even if all routes are public.")
+	}
+
+	if !authentication.IsAuthorized(rule, "alice") {
+		t.Errorf("The configured users should be authorized to download ZIP archives.")
+	}
+
+	if authentication.IsAuthorized(rule, "bob") {
+		t.Errorf("Users who are not configured should not be authorized to download ZIP archives.")
+	}
+}
+
+func Test_DebugAuthentication_AllRoutesPublic_AuthenticationIsRequired(t *testing.T) {
+	// arrange
+	config := Default("/tmp")
+	config.Server.Authentication.Rules = []AuthenticationRule{{Route: "/", Public: true}}
+
+	// act
+	authentication := config.DebugAuthentication()
+	rule, requiresAuthentication := authentication.GetRule("/-/debug/vars")
+
+	// assert
+	if !requiresAuthentication {
+		t.Errorf("The debug endpoints should require authentication even if all routes are public.")
+	}
+
+	if !authentication.IsAuthorized(rule, "alice") {
+		t.Errorf("All authenticated users should be authorized to use the debug endpoints.")
+	}
+
+	if authentication.IsAuthorized(rule, "") {
+		t.Errorf("Anonymous users should not be authorized to use the debug endpoints.")
+	}
+}
+
+func Test_WriteAPIMaxUploadSize_NoSizeConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	config := Config{}
+
+	// act
+	result := config.WriteAPIMaxUploadSize()
+
+	// assert
+	if result != DefaultWriteAPIMaxUploadSizeInMB<<20 {
+		t.Errorf("WriteAPIMaxUploadSize() should return the default size but returned %d.", result)
+	}
+}
+
+func Test_WebhookReceivesEvent_NoEventsConfigured_AllEventsAreReceived(t *testing.T) {
+	// arrange
+	webhook := Webhook{URL: "https://example.com/hooks"}
+
+	// act
+	result := webhook.ReceivesEvent(WebhookEventRepositoryIndexed)
+
+	// assert
+	if !result {
+		t.Errorf("Webhooks without events should receive all events.")
+	}
+}
+
+func Test_WebhookReceivesEvent_EventsConfigured_OnlyConfiguredEventsAreReceived(t *testing.T) {
+	// arrange
+	webhook := Webhook{URL: "https://example.com/hooks", Events: []string{"item.created", " Item.Deleted "}}
+
+	// act
+	created := webhook.ReceivesEvent(WebhookEventItemCreated)
+	deleted := webhook.ReceivesEvent(WebhookEventItemDeleted)
+	updated := webhook.ReceivesEvent(WebhookEventItemUpdated)
+
+	// assert
+	if !created || !deleted || updated {
+		t.Errorf("The webhook should only receive the configured events (created: %t, deleted: %t, updated: %t).", created, deleted, updated)
+	}
+}
+
+func Test_FeedsSortOrder_UnknownSortOrder_DefaultIsReturned(t *testing.T) {
+	// arrange
+	feeds := Feeds{SortBy: "random"}
+
+	// act
+	result := feeds.SortOrder()
+
+	// assert
+	if result != DefaultFeedSortBy {
+		t.Errorf("SortOrder should return %q for unknown sort orders but returned %q.", DefaultFeedSortBy, result)
+	}
+}
+
+func Test_FeedsSortOrder_SortOrderWithDifferentCase_NormalizedSortOrderIsReturned(t *testing.T) {
+	// arrange
+	feeds := Feeds{SortBy: " Modified "}
+
+	// act
+	result := feeds.SortOrder()
+
+	// assert
+	if result != FeedSortByModified {
+		t.Errorf("SortOrder should return %q but returned %q.", FeedSortByModified, result)
+	}
+}
+
+func Test_RSSFeedsFullContent_NoContentConfigured_TrueIsReturned(t *testing.T) {
+	// arrange
+	rss := RSSFeeds{}
+
+	// act
+	result := rss.FullContent()
+
+	// assert
+	if !result {
+		t.Errorf("RSS feeds should embed the full content if no content mode is configured.")
+	}
+}
+
+func Test_RobotsTxtRuleAgent_NoUserAgentConfigured_AllUserAgentsAreReturned(t *testing.T) {
+	// arrange
+	rule := RobotsTxtRule{Disallow: []string{"/private"}}
+
+	// act
+	result := rule.Agent()
+
+	// assert
+	if result != "*" {
+		t.Errorf("Agent should return %q if no user agent is configured but returned %q.", "*", result)
+	}
+}
+
+func Test_SearchLanguage_NoSearchLanguageConfigured_DefaultLanguageIsReturned(t *testing.T) {
+	// arrange
+	config := Config{}
+	config.Web.DefaultLanguage = "de"
+
+	// act
+	result := config.SearchLanguage()
+
+	// assert
+	if result != "de" {
+		t.Errorf("SearchLanguage should return the default language %q if no search language is configured but returned %q.", "de", result)
+	}
+}
+
+func Test_MaxFileSize_NoSizeConfigured_DefaultSizeIsReturned(t *testing.T) {
+	// arrange
+	attachments := SearchAttachments{}
+
+	// act
+	result := attachments.MaxFileSize()
+
+	// assert
+	expected := int64(DefaultSearchAttachmentMaxSizeMB) << 20
+	if result != expected {
+		t.Errorf("MaxFileSize should return %d if no size is configured but returned %d.", expected, result)
+	}
+}
+
+func Test_RelatedItemsLimit_Disabled_ZeroIsReturned(t *testing.T) {
+	// arrange
+	relatedItems := RelatedItems{Enabled: false, Count: 3}
+
+	// act
+	result := relatedItems.Limit()
+
+	// assert
+	if result != 0 {
+		t.Errorf("Limit should return 0 if the related items are disabled but returned %d.", result)
+	}
+}
+
+func Test_RelatedItemsLimit_NoCountConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	relatedItems := RelatedItems{Enabled: true}
+
+	// act
+	result := relatedItems.Limit()
+
+	// assert
+	if result != DefaultRelatedItemsCount {
+		t.Errorf("Limit should return %d if no count is configured but returned %d.", DefaultRelatedItemsCount, result)
+	}
+}
+
+func Test_RecentChangesLimit_NoCountConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	recentChanges := RecentChanges{}
+
+	// act
+	result := recentChanges.Limit()
+
+	// assert
+	if result != DefaultRecentChangesCount {
+		t.Errorf("Limit should return %d if no count is configured but returned %d.", DefaultRecentChangesCount, result)
+	}
+}
+
+func Test_PaginationPageSize_NoItemsPerPageConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	pagination := Pagination{}
+
+	// act
+	result := pagination.PageSize()
+
+	// assert
+	if result != DefaultItemsPerPage {
+		t.Errorf("PageSize should return %d if no number of items per page is configured but returned %d.", DefaultItemsPerPage, result)
+	}
+}
+
+func Test_ChildrenOrder_UnknownSortOrder_DefaultIsReturned(t *testing.T) {
+	// arrange
+	children := Children{SortOrder: "size"}
+
+	// act
+	result := children.Order()
+
+	// assert
+	if result != DefaultChildSortOrder {
+		t.Errorf("Order should return %q for an unknown sort order but returned %q.", DefaultChildSortOrder, result)
+	}
+}
+
+func Test_ChildrenOrder_SortOrderWithDifferentCase_NormalizedOrderIsReturned(t *testing.T) {
+	// arrange
+	children := Children{SortOrder: " Weight"}
+
+	// act
+	result := children.Order()
+
+	// assert
+	if result != ChildSortOrderWeight {
+		t.Errorf("Order should return %q but returned %q.", ChildSortOrderWeight, result)
+	}
+}
+
+func Test_BrandingIconSource_OnlyLogoConfigured_LogoIsReturned(t *testing.T) {
+	// arrange
+	branding := Branding{Logo: "files/logo.png"}
+
+	// act
+	result := branding.IconSource()
+
+	// assert
+	if result != "files/logo.png" {
+		t.Errorf("IconSource should return the logo %q but returned %q.", "files/logo.png", result)
+	}
+}
+
+func Test_BrandingIconSource_FaviconConfigured_FaviconIsReturned(t *testing.T) {
+	// arrange
+	branding := Branding{Logo: "files/logo.svg", Favicon: "files/icon.png"}
+
+	// act
+	result := branding.IconSource()
+
+	// assert
+	if result != "files/icon.png" {
+		t.Errorf("IconSource should return the favicon %q but returned %q.", "files/icon.png", result)
+	}
+}
+
+func Test_WebAppMaxItems_NoMaximumConfigured_DefaultIsReturned(t *testing.T) {
+	// arrange
+	webApp := WebApp{}
+
+	// act
+	result := webApp.MaxItems()
+
+	// assert
+	if result != DefaultWebAppMaxPrecachedItems {
+		t.Errorf("MaxItems should return %d if no maximum is configured but returned %d.", DefaultWebAppMaxPrecachedItems, result)
+	}
+}
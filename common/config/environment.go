@@ -0,0 +1,213 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvironmentVariablePrefix is the prefix of the environment variables which override the values of the configuration.
+const EnvironmentVariablePrefix = "ALLMARK_"
+
+// ApplyEnvironment overrides the values of the configuration with the values of the given environment variables
+// (in the "NAME=value" form of os.Environ()). The name of the variable of a value is the prefix "ALLMARK_" and the
+// path of the value in the configuration in upper case, separated by underscores (e.g. "ALLMARK_SERVER_BASEPATH"
+// or "ALLMARK_SERVER_HTTPS_CERTFILENAME"); the elements of lists are addressed by their index
+// (e.g. "ALLMARK_SERVER_HTTP_BINDINGS_0_PORT").
+//
+// Strings are used as they are, booleans and numbers are parsed, lists of strings are either JSON arrays or
+// comma-separated values and all other lists and maps are JSON (e.g. ALLMARK_WEB_MIMETYPES={".gpx": "application/gpx+xml"}).
+// Variables which do not belong to a value of the configuration are ignored.
+func (config *Config) ApplyEnvironment(environment []string) error {
+
+	values := make(map[string]string)
+	for _, variable := range environment {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || !strings.HasPrefix(strings.ToUpper(name), EnvironmentVariablePrefix) {
+			continue
+		}
+
+		values[strings.ToUpper(name)] = value
+	}
+
+	if name, err := config.applyValues(values, false); err != nil {
+		return fmt.Errorf("The value of the environment variable %q is invalid. Error: %s", name, err)
+	}
+
+	return nil
+}
+
+// applyValues assigns the given values (by the names of their environment variables) to the configuration.
+// Unknown names are ignored unless strict is set. It returns the name of the value which could not be assigned.
+func (config *Config) applyValues(values map[string]string, strict bool) (name string, err error) {
+
+	fields := make(map[string]reflect.Value)
+	getEnvironmentFields(strings.TrimSuffix(EnvironmentVariablePrefix, "_"), reflect.ValueOf(config).Elem(), fields)
+
+	// apply the values in a fixed order, so a list is replaced before its elements are changed
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		field, exists := fields[name]
+		if !exists {
+			if strict {
+				return name, fmt.Errorf("There is no such setting")
+			}
+
+			continue
+		}
+
+		if err := setEnvironmentValue(field, values[name]); err != nil {
+			return name, err
+		}
+
+		// replacing a list changes the values of its elements
+		if field.Kind() == reflect.Slice {
+			getEnvironmentFields(name, field, fields)
+		}
+	}
+
+	return "", nil
+}
+
+// getEnvironmentFields adds the settable values of the given struct or list to the given fields (by the names of
+// their environment variables below the given prefix). The fields of embedded structs belong to the outer struct.
+func getEnvironmentFields(prefix string, value reflect.Value, fields map[string]reflect.Value) {
+
+	switch value.Kind() {
+
+	case reflect.Ptr:
+		if !value.IsNil() {
+			getEnvironmentFields(prefix, value.Elem(), fields)
+		}
+
+	case reflect.Struct:
+		valueType := value.Type()
+		for index := 0; index < valueType.NumField(); index++ {
+			field := valueType.Field(index)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			if field.Anonymous {
+				getEnvironmentFields(prefix, value.Field(index), fields)
+				continue
+			}
+
+			name := prefix + "_" + strings.ToUpper(field.Name)
+			fieldValue := value.Field(index)
+			if fieldValue.Kind() == reflect.Struct {
+				getEnvironmentFields(name, fieldValue, fields)
+				continue
+			}
+
+			fields[name] = fieldValue
+			if fieldValue.Kind() == reflect.Slice {
+				getEnvironmentFields(name, fieldValue, fields)
+			}
+		}
+
+	case reflect.Slice:
+		for index := 0; index < value.Len(); index++ {
+			name := prefix + "_" + strconv.Itoa(index)
+			element := value.Index(index)
+			if element.Kind() == reflect.Struct || element.Kind() == reflect.Ptr {
+				getEnvironmentFields(name, element, fields)
+				continue
+			}
+
+			fields[name] = element
+		}
+	}
+}
+
+// setEnvironmentValue parses the given value of an environment variable and assigns it to the given field.
+func setEnvironmentValue(field reflect.Value, value string) error {
+
+	// types with a JSON representation of their own (e.g. the theme folders)
+	if _, isUnmarshaler := field.Addr().Interface().(json.Unmarshaler); isUnmarshaler {
+		data := []byte(value)
+		if !json.Valid(data) {
+			data, _ = json.Marshal(value)
+		}
+
+		return json.Unmarshal(data, field.Addr().Interface())
+	}
+
+	switch field.Kind() {
+
+	case reflect.String:
+		field.SetString(value)
+		return nil
+
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(parsed)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(parsed)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(strings.TrimSpace(value), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(parsed)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(parsed)
+		return nil
+
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			values := reflect.MakeSlice(field.Type(), 0, 0)
+			for _, element := range strings.Split(value, ",") {
+				if element = strings.TrimSpace(element); element != "" {
+					values = reflect.Append(values, reflect.ValueOf(element).Convert(field.Type().Elem()))
+				}
+			}
+
+			field.Set(values)
+			return nil
+		}
+	}
+
+	// lists, maps and everything else
+	parsed := reflect.New(field.Type())
+	if err := json.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+		return err
+	}
+
+	field.Set(parsed.Elem())
+	return nil
+}
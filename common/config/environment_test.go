@@ -0,0 +1,138 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_ApplyEnvironment_NestedValues_ValuesAreOverridden(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	environment := []string{
+		"ALLMARK_SERVER_BASEPATH=/wiki/",
+		"ALLMARK_SERVER_HTTPS_ENABLED=true",
+		"ALLMARK_SERVER_HTTPS_CERTFILENAME=/etc/ssl/wiki.crt",
+		"ALLMARK_SERVER_AUTHENTICATION_ENABLED=1",
+		"ALLMARK_SEARCH_RANKING_TITLEBOOST=2.5",
+		"ALLMARK_INDEXING_INTERVALINSECONDS=120",
+	}
+
+	// act
+	err := config.ApplyEnvironment(environment)
+
+	// assert
+	if err != nil {
+		t.Fatalf("ApplyEnvironment returned an error: %s", err)
+	}
+
+	if config.Server.BasePath != "/wiki/" || !config.Server.HTTPS.Enabled || config.Server.HTTPS.CertFileName != "/etc/ssl/wiki.crt" || !config.Server.Authentication.Enabled {
+		t.Errorf("The server values should have been overridden but were %+v.", config.Server)
+	}
+
+	if config.Search.Ranking.TitleBoost != 2.5 || config.Indexing.IntervalInSeconds != 120 {
+		t.Errorf("The numbers should have been overridden but were %v and %v.", config.Search.Ranking.TitleBoost, config.Indexing.IntervalInSeconds)
+	}
+}
+
+func Test_ApplyEnvironment_ListElement_ElementIsOverridden(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	config.Server.HTTP.Bindings = []*TCPBinding{{Network: "tcp4", IP: "0.0.0.0", Port: 80}}
+
+	// act
+	err := config.ApplyEnvironment([]string{"ALLMARK_SERVER_HTTP_BINDINGS_0_PORT=8080"})
+
+	// assert
+	if err != nil || config.Server.HTTP.Bindings[0].Port != 8080 || config.Server.HTTP.Bindings[0].IP != "0.0.0.0" {
+		t.Errorf("Only the port of the binding should have been overridden but the binding was %s (error: %v).", config.Server.HTTP.Bindings[0], err)
+	}
+}
+
+func Test_ApplyEnvironment_ListAndElement_ListIsReplacedBeforeTheElementIsChanged(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	environment := []string{
+		"ALLMARK_SERVER_HTTP_BINDINGS_1_PORT=9090",
+		`ALLMARK_SERVER_HTTP_BINDINGS=[{"Network": "tcp4", "IP": "127.0.0.1", "Port": 8080}, {"Network": "tcp6", "IP": "::1", "Port": 8080}]`,
+	}
+
+	// act
+	err := config.ApplyEnvironment(environment)
+
+	// assert
+	bindings := config.Server.HTTP.Bindings
+	if err != nil || len(bindings) != 2 || bindings[0].Port != 8080 || bindings[1].IP != "::1" || bindings[1].Port != 9090 {
+		t.Errorf("The bindings should have been replaced and the port of the second binding changed (error: %v).", err)
+	}
+}
+
+func Test_ApplyEnvironment_CommaSeparatedStrings_ListIsReturned(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+
+	// act
+	err := config.ApplyEnvironment([]string{"ALLMARK_SERVER_WRITEAPI_USERS=alice, bob,"})
+
+	// assert
+	expected := []string{"alice", "bob"}
+	if err != nil || !reflect.DeepEqual(config.Server.WriteAPI.Users, expected) {
+		t.Errorf("The users should be %v but were %v (error: %v).", expected, config.Server.WriteAPI.Users, err)
+	}
+}
+
+func Test_ApplyEnvironment_ThemeFolder_SinglePathIsAccepted(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+
+	// act
+	err := config.ApplyEnvironment([]string{"ALLMARK_THEME_FOLDER=themes/dark"})
+
+	// assert
+	if err != nil || !reflect.DeepEqual([]string(config.Theme.Folder), []string{"themes/dark"}) {
+		t.Errorf("The theme folder should be %q but was %v (error: %v).", "themes/dark", config.Theme.Folder, err)
+	}
+}
+
+func Test_ApplyEnvironment_Map_JSONIsParsed(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+
+	// act
+	err := config.ApplyEnvironment([]string{`ALLMARK_WEB_MIMETYPES={".gpx": "application/gpx+xml"}`})
+
+	// assert
+	if err != nil || config.Web.MIMETypes[".gpx"] != "application/gpx+xml" {
+		t.Errorf("The MIME types should have been parsed but were %v (error: %v).", config.Web.MIMETypes, err)
+	}
+}
+
+func Test_ApplyEnvironment_InvalidValue_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+
+	// act
+	err := config.ApplyEnvironment([]string{"ALLMARK_SERVER_HTTPS_FORCE=maybe"})
+
+	// assert
+	if err == nil {
+		t.Errorf("ApplyEnvironment should return an error for an invalid boolean.")
+	}
+}
+
+func Test_ApplyEnvironment_OtherVariables_ConfigurationIsNotChanged(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	expected := *Default("/repository")
+
+	// act
+	err := config.ApplyEnvironment([]string{"PATH=/usr/bin", "ALLMARK_LISTENERS=3", "ALLMARK_SERVER_UNKNOWN=1", "ALLMARK_LOGLEVEL"})
+
+	// assert
+	if err != nil || !reflect.DeepEqual(*config, expected) {
+		t.Errorf("The configuration should not have been changed (error: %v).", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FolderSettingsFileName is the name of the file in a content folder which overrides selected
+// settings for all items in the folder and its sub-folders.
+const FolderSettingsFileName = ".allmark.json"
+
+// FolderSettings contains the settings which a content folder overrides for its subtree, e.g.
+//
+//	{
+//		"Language": "de",
+//		"ChildSortOrder": "title",
+//		"NoIndex": true,
+//		"Template": "landing",
+//		"Authentication": { "Groups": ["editors"] }
+//	}
+//
+// The settings of a folder are merged with the settings of its parent folders; the settings of the
+// meta data of an item take precedence over the settings of its folder.
+type FolderSettings struct {
+	// Language and Direction are the language (e.g. "de") and the text direction (e.g. "ltr") of the items.
+	Language  string
+	Direction string
+
+	// ChildSortOrder is the order of the children of the items ("date", "title" or "weight").
+	ChildSortOrder string
+
+	// NoIndex, if set, defines whether the items are hidden from search engines and readers.
+	NoIndex *bool
+
+	// Template is the name of the template variant the items are rendered with (e.g. "landing").
+	Template string
+
+	// Authentication, if set, defines who can access the items of the folder. It is only used
+	// if the authentication is enabled.
+	Authentication *FolderAuthentication
+}
+
+// FolderAuthentication contains the authentication requirements of the items of a content folder.
+type FolderAuthentication struct {
+	// Public is a flag indicating whether the items can be accessed without authentication.
+	Public bool
+
+	// Users and Groups are the users and groups that are allowed to access the items.
+	// If neither users nor groups are specified all authenticated users can access the items.
+	Users  []string
+	Groups []string
+}
+
+// Rule returns the authentication rule for the folder with the given route (e.g. "documents/private").
+func (authentication FolderAuthentication) Rule(route string) AuthenticationRule {
+	return AuthenticationRule{
+		Route:  "/" + strings.Trim(route, "/"),
+		Public: authentication.Public,
+		Users:  authentication.Users,
+		Groups: authentication.Groups,
+	}
+}
+
+// IsEmpty returns a flag indicating whether the folder settings don't override any setting.
+func (settings FolderSettings) IsEmpty() bool {
+	return settings.Language == "" &&
+		settings.Direction == "" &&
+		settings.ChildSortOrder == "" &&
+		settings.NoIndex == nil &&
+		settings.Template == "" &&
+		settings.Authentication == nil
+}
+
+// Merge returns the settings of a sub-folder with the given settings which inherits all
+// settings of the current folder that it doesn't override itself.
+func (settings FolderSettings) Merge(subFolderSettings FolderSettings) FolderSettings {
+	merged := settings
+
+	if subFolderSettings.Language != "" {
+		merged.Language = subFolderSettings.Language
+	}
+
+	if subFolderSettings.Direction != "" {
+		merged.Direction = subFolderSettings.Direction
+	}
+
+	if subFolderSettings.ChildSortOrder != "" {
+		merged.ChildSortOrder = subFolderSettings.ChildSortOrder
+	}
+
+	if subFolderSettings.NoIndex != nil {
+		merged.NoIndex = subFolderSettings.NoIndex
+	}
+
+	if subFolderSettings.Template != "" {
+		merged.Template = subFolderSettings.Template
+	}
+
+	if subFolderSettings.Authentication != nil {
+		merged.Authentication = subFolderSettings.Authentication
+	}
+
+	return merged
+}
+
+// ReadFolderSettings reads the folder settings file of the given content folder.
+// If the folder has no settings file empty settings are returned.
+func ReadFolderSettings(folder string) (FolderSettings, error) {
+	var settings FolderSettings
+
+	settingsFilePath := filepath.Join(folder, FolderSettingsFileName)
+	file, err := os.Open(settingsFilePath)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+
+	if err != nil {
+		return settings, err
+	}
+
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&settings); err != nil {
+		return settings, fmt.Errorf("Unable to read the folder settings %q. Error: %s", settingsFilePath, err)
+	}
+
+	settings.Language = strings.TrimSpace(settings.Language)
+	settings.Direction = strings.ToLower(strings.TrimSpace(settings.Direction))
+	settings.ChildSortOrder = strings.ToLower(strings.TrimSpace(settings.ChildSortOrder))
+	settings.Template = strings.ToLower(strings.TrimSpace(settings.Template))
+
+	if settings.ChildSortOrder != "" && !IsChildSortOrder(settings.ChildSortOrder) {
+		return settings, fmt.Errorf("The folder settings %q contain the unknown child sort order %q.", settingsFilePath, settings.ChildSortOrder)
+	}
+
+	return settings, nil
+}
+
+// WithRules returns a copy of the authentication settings to which the given rules are added.
+// If no rules are configured all routes which are not covered by the given rules still require authentication.
+func (authentication Authentication) WithRules(rules []AuthenticationRule) Authentication {
+	if len(rules) == 0 {
+		return authentication
+	}
+
+	combined := make([]AuthenticationRule, 0, len(authentication.Rules)+len(rules)+1)
+	if len(authentication.Rules) == 0 {
+		combined = append(combined, AuthenticationRule{Route: "/"})
+	}
+
+	combined = append(combined, authentication.Rules...)
+	authentication.Rules = append(combined, rules...)
+
+	return authentication
+}
@@ -0,0 +1,111 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ReadFolderSettings_NoSettingsFile_EmptySettingsAreReturned(t *testing.T) {
+	// act
+	settings, err := ReadFolderSettings(t.TempDir())
+
+	// assert
+	if err != nil {
+		t.Fatalf("ReadFolderSettings returned an error: %s", err)
+	}
+
+	if !settings.IsEmpty() {
+		t.Errorf("The settings of a folder without a settings file should be empty but were %#v", settings)
+	}
+}
+
+func Test_ReadFolderSettings_ValidFile_SettingsAreNormalized(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	content := `{"Language": " de ", "ChildSortOrder": "Title", "NoIndex": true, "Template": "Landing", "Authentication": {"Groups": ["editors"]}}`
+	if err := os.WriteFile(filepath.Join(folder, FolderSettingsFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	settings, err := ReadFolderSettings(folder)
+
+	// assert
+	if err != nil {
+		t.Fatalf("ReadFolderSettings returned an error: %s", err)
+	}
+
+	if settings.Language != "de" || settings.ChildSortOrder != "title" || settings.Template != "landing" {
+		t.Errorf("The settings were not normalized: %#v", settings)
+	}
+
+	if settings.NoIndex == nil || !*settings.NoIndex {
+		t.Errorf("NoIndex should be set")
+	}
+
+	if settings.Authentication == nil || len(settings.Authentication.Groups) != 1 {
+		t.Errorf("The authentication should be read but was %#v", settings.Authentication)
+	}
+}
+
+func Test_ReadFolderSettings_UnknownSortOrder_ErrorIsReturned(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	if err := os.WriteFile(filepath.Join(folder, FolderSettingsFileName), []byte(`{"ChildSortOrder": "size"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err := ReadFolderSettings(folder)
+
+	// assert
+	if err == nil {
+		t.Errorf("ReadFolderSettings should return an error for an unknown child sort order")
+	}
+}
+
+func Test_Merge_SubFolderOverridesSomeSettings_OtherSettingsAreInherited(t *testing.T) {
+	// arrange
+	noIndex, index := true, false
+	parent := FolderSettings{Language: "de", Template: "landing", NoIndex: &noIndex}
+	subFolder := FolderSettings{Language: "en", NoIndex: &index}
+
+	// act
+	merged := parent.Merge(subFolder)
+
+	// assert
+	if merged.Language != "en" {
+		t.Errorf("The language of the sub-folder should be used but was %q", merged.Language)
+	}
+
+	if merged.Template != "landing" {
+		t.Errorf("The template of the parent folder should be inherited but was %q", merged.Template)
+	}
+
+	if merged.NoIndex == nil || *merged.NoIndex {
+		t.Errorf("The sub-folder should be able to reset NoIndex")
+	}
+}
+
+func Test_WithRules_NoConfiguredRules_OtherRoutesStillRequireAuthentication(t *testing.T) {
+	// arrange
+	authentication := Authentication{}
+	folderRule := FolderAuthentication{Public: true}.Rule("documents/public")
+
+	// act
+	combined := authentication.WithRules([]AuthenticationRule{folderRule})
+
+	// assert
+	if _, requiresAuthentication := combined.GetRule("/documents/public/notes"); requiresAuthentication {
+		t.Errorf("The items of the public folder should not require authentication")
+	}
+
+	if _, requiresAuthentication := combined.GetRule("/documents/private"); !requiresAuthentication {
+		t.Errorf("The routes which are not covered by the folder rules should still require authentication")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocaleLabels contains the user interface labels of a locale by their key.
+//
+// A locale file is a JSON object in the "locales" folder which is named after the locale
+// (e.g. "de.json"). It only needs to contain the labels it translates:
+//
+//	{
+//		"search.placeholder": "Suchen",
+//		"footer.sitemap": "Inhaltsverzeichnis"
+//	}
+type LocaleLabels map[string]string
+
+// ParseLocale reads the labels of a locale file from the given reader.
+func ParseLocale(reader io.Reader) (LocaleLabels, error) {
+	var entries map[string]interface{}
+	if err := json.NewDecoder(reader).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("The locale must be a JSON object. Error: %s", err)
+	}
+
+	labels := make(LocaleLabels, len(entries))
+	for key, value := range entries {
+		text, isText := value.(string)
+		if !isText {
+			return nil, fmt.Errorf("The label %q is not a text", key)
+		}
+
+		labels[strings.TrimSpace(key)] = text
+	}
+
+	return labels, nil
+}
+
+// LocaleFolders returns the folders from which the locale files are read in the order of their precedence:
+// the locale folders of the configured theme folders and the locales folder of the repository.
+func (config *Config) LocaleFolders() []string {
+	var folders []string
+	for _, themeFolder := range config.ThemeFolders() {
+		folders = append(folders, filepath.Join(themeFolder, LocalesFolderName))
+	}
+
+	return append(folders, filepath.Join(config.MetaDataFolder(), LocalesFolderName))
+}
+
+// Locales returns the labels of the locale files in the locale folders by their lowercase locale (e.g. "de").
+// If several folders contain a file for the same locale, the labels of the folder with the higher
+// precedence replace the ones of the other folders.
+func (config *Config) Locales() (map[string]LocaleLabels, error) {
+	locales := make(map[string]LocaleLabels)
+
+	folders := config.LocaleFolders()
+	for index := len(folders) - 1; index >= 0; index-- {
+		files, err := os.ReadDir(folders[index])
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if file.IsDir() || strings.ToLower(filepath.Ext(file.Name())) != ".json" {
+				continue
+			}
+
+			path := filepath.Join(folders[index], file.Name())
+			labels, err := readLocaleFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read the locale file %q. Error: %s", path, err)
+			}
+
+			locale := strings.ToLower(strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())))
+			if locales[locale] == nil {
+				locales[locale] = make(LocaleLabels)
+			}
+
+			for key, label := range labels {
+				locales[locale][key] = label
+			}
+		}
+	}
+
+	return locales, nil
+}
+
+// readLocaleFile reads the labels of the locale file with the given path.
+func readLocaleFile(path string) (LocaleLabels, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ParseLocale(file)
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_ParseLocale_JSONObject_LabelsAreReturned(t *testing.T) {
+	// arrange
+	input := `{ "search.placeholder": "Suchen", " footer.sitemap ": "Inhaltsverzeichnis" }`
+
+	// act
+	labels, err := ParseLocale(strings.NewReader(input))
+
+	// assert
+	if err != nil {
+		t.Fatalf("ParseLocale returned an error: %s", err)
+	}
+
+	if labels["search.placeholder"] != "Suchen" || labels["footer.sitemap"] != "Inhaltsverzeichnis" {
+		t.Errorf("ParseLocale returned the wrong labels: %v", labels)
+	}
+}
+
+func Test_ParseLocale_LabelIsNotAText_ErrorIsReturned(t *testing.T) {
+	// arrange
+	inputs := []string{`{ "search.placeholder": 1 }`, `["Suchen"]`, `not json`}
+
+	for _, input := range inputs {
+
+		// act
+		_, err := ParseLocale(strings.NewReader(input))
+
+		// assert
+		if err == nil {
+			t.Errorf("ParseLocale(%q) should return an error.", input)
+		}
+	}
+}
+
+func Test_Locales_ThemeAndRepositoryLocales_ThemeLabelsTakePrecedence(t *testing.T) {
+	// arrange
+	repositoryFolder := t.TempDir()
+	themeFolder := t.TempDir()
+
+	writeLocaleFile(t, filepath.Join(themeFolder, LocalesFolderName, "DE.json"), `{ "search.placeholder": "Suche", "footer.tags": "Schlagworte" }`)
+	writeLocaleFile(t, filepath.Join(repositoryFolder, MetaDataFolderName, LocalesFolderName, "de.json"), `{ "search.placeholder": "Suchen" }`)
+
+	config := New(repositoryFolder)
+	config.Theme.Folder = ThemeFolders{themeFolder}
+
+	// act
+	locales, err := config.Locales()
+
+	// assert
+	if err != nil {
+		t.Fatalf("Locales returned an error: %s", err)
+	}
+
+	if locales["de"]["search.placeholder"] != "Suche" {
+		t.Errorf("The label of the theme should replace the label of the repository but the label was %q.", locales["de"]["search.placeholder"])
+	}
+
+	if locales["de"]["footer.tags"] != "Schlagworte" {
+		t.Errorf("The labels of the theme should be merged with the labels of the repository but the label was %q.", locales["de"]["footer.tags"])
+	}
+}
+
+func Test_Locales_NoLocalesFolder_NoLocalesAreReturned(t *testing.T) {
+	// arrange
+	config := New(t.TempDir())
+
+	// act
+	locales, err := config.Locales()
+
+	// assert
+	if err != nil || len(locales) != 0 {
+		t.Errorf("Locales should return no locales and no error but returned %v (error: %v).", locales, err)
+	}
+}
+
+// writeLocaleFile creates the given locale file.
+func writeLocaleFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
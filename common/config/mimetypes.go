@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// MIMETypes maps file extensions (e.g. ".gpx") to MIME types (e.g. "application/gpx+xml").
+// The mappings override or extend the built-in MIME types for files that are attached to the items.
+type MIMETypes map[string]string
+
+// Register adds the mappings to the MIME type table of the process, so that they are used
+// for the Content-Type of the files and for the detection of images, audio and video files.
+func (mimeTypes MIMETypes) Register() error {
+	for extension, mimeType := range mimeTypes {
+		normalizedExtension := normalizeFileExtension(extension)
+		if normalizedExtension == "." {
+			return fmt.Errorf("The MIME type %q has no file extension", mimeType)
+		}
+
+		if err := mime.AddExtensionType(normalizedExtension, strings.TrimSpace(mimeType)); err != nil {
+			return fmt.Errorf("Invalid MIME type %q for the file extension %q. Error: %s", mimeType, extension, err)
+		}
+	}
+
+	return nil
+}
+
+// normalizeFileExtension returns the given file extension in lower case and with a leading dot (e.g. "GPX" -> ".gpx").
+func normalizeFileExtension(extension string) string {
+	extension = strings.ToLower(strings.TrimSpace(extension))
+	return "." + strings.TrimPrefix(extension, ".")
+}
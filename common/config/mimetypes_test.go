@@ -0,0 +1,85 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"mime"
+	"testing"
+)
+
+func Test_normalizeFileExtension_ExtensionWithoutDot_DotIsAdded(t *testing.T) {
+	// arrange
+	extension := " GPX "
+
+	// act
+	result := normalizeFileExtension(extension)
+
+	// assert
+	if result != ".gpx" {
+		t.Errorf("normalizeFileExtension(%q) should return %q but returned %q.", extension, ".gpx", result)
+	}
+}
+
+func Test_normalizeFileExtension_ExtensionWithDot_ExtensionIsNotChanged(t *testing.T) {
+	// arrange
+	extension := ".stl"
+
+	// act
+	result := normalizeFileExtension(extension)
+
+	// assert
+	if result != ".stl" {
+		t.Errorf("normalizeFileExtension(%q) should return %q but returned %q.", extension, ".stl", result)
+	}
+}
+
+func Test_MIMETypesRegister_ValidMapping_MIMETypeIsRegistered(t *testing.T) {
+	// arrange
+	mimeTypes := MIMETypes{
+		"allmarktest": "application/x-allmark-test",
+	}
+
+	// act
+	err := mimeTypes.Register()
+
+	// assert
+	if err != nil {
+		t.Fatalf("Register() returned an error: %s", err)
+	}
+
+	if mimeType := mime.TypeByExtension(".allmarktest"); mimeType != "application/x-allmark-test" {
+		t.Errorf("The MIME type of %q should be %q but was %q.", ".allmarktest", "application/x-allmark-test", mimeType)
+	}
+}
+
+func Test_MIMETypesRegister_InvalidMIMEType_ErrorIsReturned(t *testing.T) {
+	// arrange
+	mimeTypes := MIMETypes{
+		".allmarkinvalid": "not a mime type",
+	}
+
+	// act
+	err := mimeTypes.Register()
+
+	// assert
+	if err == nil {
+		t.Errorf("Register() should return an error for an invalid MIME type.")
+	}
+}
+
+func Test_MIMETypesRegister_EmptyExtension_ErrorIsReturned(t *testing.T) {
+	// arrange
+	mimeTypes := MIMETypes{
+		" ": "text/plain",
+	}
+
+	// act
+	err := mimeTypes.Register()
+
+	// assert
+	if err == nil {
+		t.Errorf("Register() should return an error for an empty file extension.")
+	}
+}
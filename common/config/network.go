@@ -0,0 +1,216 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Network contains the trusted reverse proxies and the network access rules of the web server.
+type Network struct {
+	// TrustedProxies is a list of IP addresses or CIDR ranges (e.g. "10.0.0.0/8") of reverse proxies
+	// whose X-Forwarded-For headers are used to determine the client address.
+	TrustedProxies []string
+
+	// AccessRules is a list of network access rules for route prefixes.
+	AccessRules []NetworkAccessRule
+}
+
+// NetworkAccessRule defines which client networks are allowed to access the routes with a given prefix.
+type NetworkAccessRule struct {
+	// Route is the route prefix this rule applies to (e.g. "/private", "/private/**").
+	Route string
+
+	// Allow is a list of IP addresses or CIDR ranges that are allowed to access the routes.
+	// If the list is empty all clients which are not denied are allowed.
+	Allow []string
+
+	// Deny is a list of IP addresses or CIDR ranges that are not allowed to access the routes.
+	Deny []string
+}
+
+// NewNetworkPolicy creates a network policy from the given network settings.
+func NewNetworkPolicy(network Network) (*NetworkPolicy, error) {
+
+	trustedProxies, err := parseNetworks(network.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid trusted proxy. %s", err)
+	}
+
+	policy := &NetworkPolicy{
+		trustedProxies: trustedProxies,
+	}
+
+	for _, rule := range network.AccessRules {
+		allow, err := parseNetworks(rule.Allow)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid allow entry in the network access rule for %q. %s", rule.Route, err)
+		}
+
+		deny, err := parseNetworks(rule.Deny)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid deny entry in the network access rule for %q. %s", rule.Route, err)
+		}
+
+		policy.rules = append(policy.rules, networkRule{
+			route: normalizeRoutePrefix(rule.Route),
+			allow: allow,
+			deny:  deny,
+		})
+	}
+
+	return policy, nil
+}
+
+// NetworkPolicy returns the network policy for the configured trusted proxies and network access rules.
+func (config *Config) NetworkPolicy() (*NetworkPolicy, error) {
+	return NewNetworkPolicy(config.Server.Network)
+}
+
+// NetworkPolicy determines client addresses and decides which clients can access which routes.
+type NetworkPolicy struct {
+	trustedProxies []*net.IPNet
+	rules          []networkRule
+}
+
+type networkRule struct {
+	route string
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// HasTrustedProxies returns a flag indicating whether any trusted proxies are configured.
+func (policy *NetworkPolicy) HasTrustedProxies() bool {
+	return len(policy.trustedProxies) > 0
+}
+
+// HasAccessRules returns a flag indicating whether any network access rules are configured.
+func (policy *NetworkPolicy) HasAccessRules() bool {
+	return len(policy.rules) > 0
+}
+
+// ClientIP returns the IP address of the client for a request from the given remote IP address
+// with the given X-Forwarded-For header values. The forwarded addresses are only used if the
+// request was sent by a trusted proxy; the first address (from the right) which does not belong
+// to a trusted proxy is the client address.
+func (policy *NetworkPolicy) ClientIP(remoteIP string, forwardedFor []string) string {
+
+	if !policy.isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+
+	var forwardedAddresses []string
+	for _, headerValue := range forwardedFor {
+		for _, address := range strings.Split(headerValue, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				forwardedAddresses = append(forwardedAddresses, address)
+			}
+		}
+	}
+
+	clientIP := remoteIP
+	for index := len(forwardedAddresses) - 1; index >= 0; index-- {
+		address := forwardedAddresses[index]
+		if net.ParseIP(address) == nil {
+			break
+		}
+
+		clientIP = address
+		if !policy.isTrustedProxy(address) {
+			break
+		}
+	}
+
+	return clientIP
+}
+
+// IsAllowed returns a flag indicating whether the client with the given IP address is allowed to
+// access the given request path. The most specific matching rule decides.
+func (policy *NetworkPolicy) IsAllowed(path string, clientIP string) bool {
+
+	var matchingRule *networkRule
+	for index, rule := range policy.rules {
+		if !routePrefixMatches(rule.route, path) {
+			continue
+		}
+
+		if matchingRule != nil && len(rule.route) <= len(matchingRule.route) {
+			continue
+		}
+
+		matchingRule = &policy.rules[index]
+	}
+
+	if matchingRule == nil {
+		return true
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	if containsIP(matchingRule.deny, ip) {
+		return false
+	}
+
+	return len(matchingRule.allow) == 0 || containsIP(matchingRule.allow, ip)
+}
+
+// IsTrustedProxy returns a flag indicating whether the given remote IP address belongs to a trusted proxy.
+func (policy *NetworkPolicy) IsTrustedProxy(remoteIP string) bool {
+	return policy.isTrustedProxy(remoteIP)
+}
+
+func (policy *NetworkPolicy) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && containsIP(policy.trustedProxies, ip)
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseNetworks parses the given list of IP addresses and CIDR ranges.
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is neither an IP address nor a CIDR range", entry)
+			}
+
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither an IP address nor a CIDR range", entry)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
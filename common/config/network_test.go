@@ -0,0 +1,86 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func Test_NewNetworkPolicy_InvalidNetwork_ErrorIsReturned(t *testing.T) {
+	// arrange
+	network := Network{
+		AccessRules: []NetworkAccessRule{
+			{Route: "/private", Allow: []string{"10.0.0.0/33"}},
+		},
+	}
+
+	// act
+	_, err := NewNetworkPolicy(network)
+
+	// assert
+	if err == nil {
+		t.Errorf("NewNetworkPolicy should return an error for an invalid CIDR range")
+	}
+}
+
+func Test_ClientIP_UntrustedRemoteAddress_ForwardedForIsIgnored(t *testing.T) {
+	// arrange
+	policy, _ := NewNetworkPolicy(Network{TrustedProxies: []string{"10.0.0.1"}})
+
+	// act
+	clientIP := policy.ClientIP("192.168.0.5", []string{"1.2.3.4"})
+
+	// assert
+	if clientIP != "192.168.0.5" {
+		t.Errorf("ClientIP returned %q; expected the remote address because it is not a trusted proxy", clientIP)
+	}
+}
+
+func Test_ClientIP_ChainOfTrustedProxies_FirstUntrustedAddressIsReturned(t *testing.T) {
+	// arrange
+	policy, _ := NewNetworkPolicy(Network{TrustedProxies: []string{"10.0.0.0/8"}})
+
+	// act
+	clientIP := policy.ClientIP("10.0.0.1", []string{"6.6.6.6, 1.2.3.4", "10.0.0.2"})
+
+	// assert
+	if clientIP != "1.2.3.4" {
+		t.Errorf("ClientIP returned %q; expected %q", clientIP, "1.2.3.4")
+	}
+}
+
+func Test_IsAllowed_MostSpecificRuleDecides(t *testing.T) {
+	// arrange
+	policy, _ := NewNetworkPolicy(Network{
+		AccessRules: []NetworkAccessRule{
+			{Route: "/", Deny: []string{"6.6.6.0/24"}},
+			{Route: "/internal/**", Allow: []string{"10.0.0.0/8", "::1"}},
+		},
+	})
+
+	inputs := []struct {
+		path     string
+		clientIP string
+		expected bool
+	}{
+		{"/documents/sample", "1.2.3.4", true},
+		{"/documents/sample", "6.6.6.6", false},
+		{"/internal", "1.2.3.4", false},
+		{"/internal/notes.json", "10.1.2.3", true},
+		{"/internal/notes", "::1", true},
+		{"/internally", "1.2.3.4", true},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result := policy.IsAllowed(input.path, input.clientIP)
+
+		// assert
+		if result != input.expected {
+			t.Errorf("IsAllowed(%q, %q) returned %v; expected %v", input.path, input.clientIP, result, input.expected)
+		}
+	}
+}
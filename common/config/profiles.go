@@ -0,0 +1,84 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProfileEnvironmentVariable is the name of the environment variable which selects a profile if none is selected on the command line.
+const ProfileEnvironmentVariable = "ALLMARK_PROFILE"
+
+// A Profile contains the settings which override the configuration if the profile is selected, in the format of the
+// configuration file (e.g. {"LiveReload": {"Enabled": true}}). Objects are merged with the configuration; all other
+// values replace the configured ones.
+type Profile json.RawMessage
+
+// MarshalJSON returns the settings of the profile.
+func (profile Profile) MarshalJSON() ([]byte, error) {
+	if len(profile) == 0 {
+		return []byte("{}"), nil
+	}
+
+	return []byte(profile), nil
+}
+
+// UnmarshalJSON reads the settings of the profile which must be an object.
+func (profile *Profile) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return fmt.Errorf("A profile must be an object with the settings it overrides")
+	}
+
+	*profile = append((*profile)[:0], data...)
+	return nil
+}
+
+// ProfileNames returns the sorted names of the configured profiles.
+func (config *Config) ProfileNames() []string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile overrides the settings of the configuration with the settings of the profile with the given name
+// (case-insensitive). An empty name selects no profile.
+func (config *Config) ApplyProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+
+	for profileName, profile := range config.Profiles {
+		if !strings.EqualFold(profileName, name) {
+			continue
+		}
+
+		// the profiles of a profile are ignored
+		profiles := config.Profiles
+		defer func() {
+			config.Profiles = profiles
+		}()
+
+		if err := json.Unmarshal([]byte(profile), config); err != nil {
+			return fmt.Errorf("The profile %q cannot be applied. Error: %s", profileName, err)
+		}
+
+		return nil
+	}
+
+	if len(config.Profiles) == 0 {
+		return fmt.Errorf("The profile %q does not exist; the configuration has no profiles.", name)
+	}
+
+	return fmt.Errorf("The profile %q does not exist; use one of %s.", name, strings.Join(config.ProfileNames(), ", "))
+}
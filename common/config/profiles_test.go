@@ -0,0 +1,90 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ApplyProfile_ExistingProfile_SettingsAreOverridden(t *testing.T) {
+	// arrange
+	config, err := NewJSONSerializer().DeserializeConfig(strings.NewReader(`{
+		"Server": {"DomainName": "localhost", "Authentication": {"Enabled": true}},
+		"LiveReload": {"Enabled": false, "Mode": "morph"},
+		"Profiles": {
+			"dev": {"Server": {"Authentication": {"Enabled": false}}, "LiveReload": {"Enabled": true}},
+			"public": {"Server": {"DomainName": "example.com"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = config.ApplyProfile("Dev")
+
+	// assert
+	if err != nil {
+		t.Fatalf("ApplyProfile returned an error: %s", err)
+	}
+
+	if config.Server.Authentication.Enabled || !config.LiveReload.Enabled {
+		t.Errorf("The settings of the profile should have been applied.")
+	}
+
+	if config.Server.DomainName != "localhost" || config.LiveReload.Mode != "morph" {
+		t.Errorf("The settings which the profile does not contain should not change but the domain was %q and the mode %q.", config.Server.DomainName, config.LiveReload.Mode)
+	}
+
+	if names := config.ProfileNames(); !reflect.DeepEqual(names, []string{"dev", "public"}) {
+		t.Errorf("The profiles should be kept but were %v.", names)
+	}
+}
+
+func Test_ApplyProfile_UnknownProfile_ErrorListsTheProfiles(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	config.Profiles = map[string]Profile{"dev": Profile(`{}`), "public": Profile(`{}`)}
+
+	// act
+	err := config.ApplyProfile("staging")
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), "dev, public") {
+		t.Errorf("ApplyProfile should return an error with the available profiles but returned %v.", err)
+	}
+}
+
+func Test_Profile_SaveConfiguration_ProfileIsWrittenUnchanged(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	config.Profiles = map[string]Profile{"dev": Profile(`{"LiveReload":{"Enabled":true}}`)}
+
+	// act
+	var buffer bytes.Buffer
+	err := NewJSONSerializer().SerializeConfig(&buffer, config)
+
+	// assert
+	if err != nil || !strings.Contains(buffer.String(), `"dev": {`) || !strings.Contains(buffer.String(), `"Enabled": true`) {
+		t.Errorf("The profile should be serialized as an object but the configuration was %s (error: %v).", buffer.String(), err)
+	}
+}
+
+func Test_checkConfigFile_UnknownSettingInProfile_ProblemIsReturned(t *testing.T) {
+	// arrange
+	data := []byte(`{"Profiles": {"dev": {"LiveReload": {"Enabeld": true}}, "public": true}}`)
+
+	// act
+	problems := checkConfigFile(data)
+
+	// assert
+	expected := []string{`Profiles["dev"].LiveReload.Enabeld`, `Profiles["public"]`}
+	if settings := getProblemSettings(problems); !reflect.DeepEqual(settings, expected) {
+		t.Errorf("checkConfigFile should report %v but reported %v.", expected, problems)
+	}
+}
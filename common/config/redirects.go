@@ -0,0 +1,162 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Redirect defines a redirect from a source route to a target route or URL.
+//
+// A redirects file contains one redirect per line:
+//
+//	# source          target                       [status code]
+//	/old-page         /new-page
+//	/blog/*           https://blog.example.com/*   302
+//
+// A trailing "*" in the source matches all routes with the given prefix. The matched
+// remainder replaces a trailing "*" in the target.
+type Redirect struct {
+	Source     string
+	Target     string
+	StatusCode int
+}
+
+// Match checks if the given request path matches the source of the current redirect
+// and returns the target of the redirect.
+func (redirect Redirect) Match(requestPath string) (target string, matches bool) {
+
+	if !strings.HasSuffix(redirect.Source, "*") {
+		if strings.TrimRight(requestPath, "/") != strings.TrimRight(redirect.Source, "/") {
+			return "", false
+		}
+
+		return redirect.Target, true
+	}
+
+	prefix := strings.TrimSuffix(redirect.Source, "*")
+	if !strings.HasPrefix(requestPath, prefix) {
+		return "", false
+	}
+
+	if !strings.HasSuffix(redirect.Target, "*") {
+		return redirect.Target, true
+	}
+
+	return strings.TrimSuffix(redirect.Target, "*") + cleanRemainder(strings.TrimPrefix(requestPath, prefix)), true
+}
+
+// cleanRemainder normalizes the given remainder of a wildcard match so that it cannot
+// leave the target (e.g. "../" or "//host") but keeps a trailing slash.
+func cleanRemainder(remainder string) string {
+	if remainder == "" {
+		return ""
+	}
+
+	cleaned := strings.TrimLeft(path.Clean("/"+remainder), "/\\")
+	if cleaned != "" && strings.HasSuffix(remainder, "/") {
+		cleaned += "/"
+	}
+
+	return cleaned
+}
+
+// FindRedirect returns the target and status code of the first redirect that matches the given request path.
+func FindRedirect(redirects []Redirect, path string) (target string, statusCode int, found bool) {
+	for _, redirect := range redirects {
+		if target, matches := redirect.Match(path); matches {
+			return target, redirect.StatusCode, true
+		}
+	}
+
+	return "", 0, false
+}
+
+// ParseRedirects reads the redirects from the given reader.
+func ParseRedirects(reader io.Reader) ([]Redirect, error) {
+	var redirects []Redirect
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("Invalid redirect in line %d: %q", lineNumber, line)
+		}
+
+		redirect := Redirect{
+			Source:     fields[0],
+			Target:     fields[1],
+			StatusCode: http.StatusMovedPermanently,
+		}
+
+		if !strings.HasPrefix(redirect.Source, "/") {
+			return nil, fmt.Errorf("Invalid redirect in line %d: the source %q must start with a slash", lineNumber, redirect.Source)
+		}
+
+		if len(fields) == 3 {
+			statusCode, err := strconv.Atoi(fields[2])
+			if err != nil || !isRedirectStatusCode(statusCode) {
+				return nil, fmt.Errorf("Invalid redirect in line %d: %q is not a redirect status code", lineNumber, fields[2])
+			}
+
+			redirect.StatusCode = statusCode
+		}
+
+		redirects = append(redirects, redirect)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return redirects, nil
+}
+
+func isRedirectStatusCode(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+
+	return false
+}
+
+// RedirectsFilePath returns the path of the redirects file.
+func (config *Config) RedirectsFilePath() string {
+	return filepath.Join(config.MetaDataFolder(), RedirectsFileName)
+}
+
+// Redirects returns the redirects defined in the redirects file of the repository.
+// If there is no redirects file an empty list is returned.
+func (config *Config) Redirects() ([]Redirect, error) {
+	file, err := os.Open(config.RedirectsFilePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ParseRedirects(file)
+}
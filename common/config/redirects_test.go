@@ -0,0 +1,133 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_ParseRedirects_ValidFile_RedirectsAreReturned(t *testing.T) {
+	// arrange
+	input := `
+# moved documents
+/old-page   /new-page
+/blog/*     https://blog.example.com/*   302
+`
+
+	// act
+	redirects, err := ParseRedirects(strings.NewReader(input))
+
+	// assert
+	if err != nil {
+		t.Fatalf("ParseRedirects returned an error: %s", err)
+	}
+
+	if len(redirects) != 2 {
+		t.Fatalf("ParseRedirects returned %d redirects; expected 2", len(redirects))
+	}
+
+	if redirects[0].StatusCode != http.StatusMovedPermanently {
+		t.Errorf("The default status code should be %d but was %d", http.StatusMovedPermanently, redirects[0].StatusCode)
+	}
+
+	if redirects[1].StatusCode != http.StatusFound {
+		t.Errorf("The status code should be %d but was %d", http.StatusFound, redirects[1].StatusCode)
+	}
+}
+
+func Test_ParseRedirects_InvalidLines_ErrorIsReturned(t *testing.T) {
+	// arrange
+	inputs := []string{
+		"/only-a-source",
+		"/a /b 200",
+		"/a /b 301 extra",
+		"no-slash /b",
+	}
+
+	for _, input := range inputs {
+
+		// act
+		_, err := ParseRedirects(strings.NewReader(input))
+
+		// assert
+		if err == nil {
+			t.Errorf("ParseRedirects(%q) should return an error", input)
+		}
+	}
+}
+
+func Test_FindRedirect_VariousPaths_ExpectedTargetsAreReturned(t *testing.T) {
+	// arrange
+	redirects := []Redirect{
+		{Source: "/old-page", Target: "/new-page", StatusCode: http.StatusMovedPermanently},
+		{Source: "/blog/*", Target: "https://blog.example.com/*", StatusCode: http.StatusFound},
+		{Source: "/archive/*", Target: "/", StatusCode: http.StatusMovedPermanently},
+	}
+
+	inputs := map[string]string{
+		"/old-page":          "/new-page",
+		"/old-page/":         "/new-page",
+		"/blog/2015/post":    "https://blog.example.com/2015/post",
+		"/archive/2014/note": "/",
+	}
+
+	for path, expected := range inputs {
+
+		// act
+		target, _, found := FindRedirect(redirects, path)
+
+		// assert
+		if !found || target != expected {
+			t.Errorf("FindRedirect(%q) returned %q (found: %v); expected %q", path, target, found, expected)
+		}
+	}
+}
+
+func Test_FindRedirect_NoMatch_NothingIsFound(t *testing.T) {
+	// arrange
+	redirects := []Redirect{
+		{Source: "/old-page", Target: "/new-page", StatusCode: http.StatusMovedPermanently},
+	}
+
+	// act
+	_, _, found := FindRedirect(redirects, "/old-page-2")
+
+	// assert
+	if found {
+		t.Errorf("FindRedirect should not find a redirect for an unrelated path")
+	}
+}
+
+func Test_FindRedirect_WildcardRemainderWithSlashesOrDots_RemainderIsCleaned(t *testing.T) {
+	// arrange
+	redirects := []Redirect{
+		{Source: "/old/*", Target: "/*", StatusCode: http.StatusMovedPermanently},
+		{Source: "/blog/*", Target: "https://blog.example.com/*", StatusCode: http.StatusFound},
+	}
+
+	inputs := map[string]string{
+		"/old//evil.com":      "/evil.com",
+		"/old///evil.com/":    "/evil.com/",
+		"/old/\\evil.com":     "/evil.com",
+		"/old/../../evil":     "/evil",
+		"/old/docs/./a//b":    "/docs/a/b",
+		"/blog//2015/../post": "https://blog.example.com/post",
+		"/blog/../../admin/":  "https://blog.example.com/admin/",
+		"/old/":               "/",
+	}
+
+	for path, expected := range inputs {
+
+		// act
+		target, _, found := FindRedirect(redirects, path)
+
+		// assert
+		if !found || target != expected {
+			t.Errorf("FindRedirect(%q) returned %q (found: %v); expected %q", path, target, found, expected)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// reloadableSettings are the settings which a running server applies when the configuration is reloaded.
+// All other settings (e.g. the bindings, the base path, the theme folders or the authentication, which the
+// orchestrators and the write, ZIP and debug handlers are created with) only take effect after a restart.
+var reloadableSettings = []string{
+	"LogLevel",
+	"Logging.Format",
+	"Logging.Levels",
+	"Server.Minification",
+	"Server.SecurityHeaders",
+	"Server.RateLimiting.Enabled",
+	"Server.RateLimiting.RequestsPerSecond",
+	"Server.RateLimiting.Burst",
+	"Server.RateLimiting.MaxConcurrentRequests",
+	"Server.Network",
+	"Resources",
+	"Profiles",
+}
+
+// RequiresRestart returns true if a change of the setting with the given path (e.g. "Server.HTTP.Bindings[0].Port")
+// only takes effect after a restart of the server.
+func RequiresRestart(setting string) bool {
+	for _, reloadableSetting := range reloadableSettings {
+		if setting == reloadableSetting || strings.HasPrefix(setting, reloadableSetting+".") || strings.HasPrefix(setting, reloadableSetting+"[") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Changes returns the sorted paths of the settings (e.g. "LogLevel", "Server.HTTP.Bindings[0].Port") whose
+// values differ between the given configurations. Lists with a different number of elements and maps are
+// reported as a whole.
+func Changes(previous, current Config) []string {
+	var changes []string
+	compareSettings("", reflect.ValueOf(previous), reflect.ValueOf(current), func(setting string) {
+		changes = append(changes, setting)
+	})
+
+	sort.Strings(changes)
+	return changes
+}
+
+// Reload returns the previous configuration with the values of the current configuration for all settings
+// which can be applied without a restart (see RequiresRestart) and the sorted paths of the changed settings
+// which require one.
+func Reload(previous, current Config) (reloaded Config, restartRequired []string) {
+	for _, setting := range Changes(previous, current) {
+		if RequiresRestart(setting) {
+			restartRequired = append(restartRequired, setting)
+		}
+	}
+
+	// replace the reloadable settings as a whole, so the lists and maps of the previous configuration are not modified
+	reloaded = previous
+	for _, setting := range reloadableSettings {
+		getSetting(&reloaded, setting).Set(getSetting(&current, setting))
+	}
+
+	return reloaded, restartRequired
+}
+
+// getSetting returns the value of the setting with the given path (e.g. "Server.Authentication") of the given configuration.
+func getSetting(config *Config, setting string) reflect.Value {
+	value := reflect.ValueOf(config).Elem()
+	for _, name := range strings.Split(setting, ".") {
+		value = value.FieldByName(name)
+	}
+
+	return value
+}
+
+// compareSettings calls the given function for every setting below the given path whose previous value differs
+// from its current value. The fields of embedded structs belong to the outer struct.
+func compareSettings(path string, previous, current reflect.Value, changed func(setting string)) {
+
+	switch previous.Kind() {
+
+	case reflect.Struct:
+		valueType := previous.Type()
+		for index := 0; index < valueType.NumField(); index++ {
+			field := valueType.Field(index)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			fieldPath := joinSettingPath(path, field.Name)
+			if field.Anonymous {
+				fieldPath = path
+			}
+
+			compareSettings(fieldPath, previous.Field(index), current.Field(index), changed)
+		}
+
+	case reflect.Ptr:
+		if previous.IsNil() || current.IsNil() {
+			if previous.IsNil() != current.IsNil() {
+				changed(path)
+			}
+
+			return
+		}
+
+		compareSettings(path, previous.Elem(), current.Elem(), changed)
+
+	case reflect.Slice:
+		if previous.Len() != current.Len() {
+			changed(path)
+			return
+		}
+
+		for index := 0; index < previous.Len(); index++ {
+			compareSettings(fmt.Sprintf("%s[%d]", path, index), previous.Index(index), current.Index(index), changed)
+		}
+
+	default:
+		if !reflect.DeepEqual(previous.Interface(), current.Interface()) {
+			changed(path)
+		}
+	}
+}
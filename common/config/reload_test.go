@@ -0,0 +1,99 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Changes_SameConfiguration_NoChanges(t *testing.T) {
+	// arrange
+	previous := Default("/repository")
+	current := Default("/repository")
+
+	// act
+	changes := Changes(*previous, *current)
+
+	// assert
+	if len(changes) > 0 {
+		t.Errorf("Two default configurations should not differ but the settings %v were reported as changed.", changes)
+	}
+}
+
+func Test_Changes_ChangedValuesAndLists_PathsOfTheChangedSettingsAreReturned(t *testing.T) {
+	// arrange
+	previous := Default("/repository")
+	current := Default("/repository")
+	current.LogLevel = "Debug"
+	current.Server.HTTP.Bindings[0].Port = 8080
+	current.Theme.Folder = ThemeFolders{"themes/dark"}
+
+	// act
+	changes := Changes(*previous, *current)
+
+	// assert
+	expected := []string{"LogLevel", "Server.HTTP.Bindings[0].Port", "Theme.Folder"}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("The changed settings should be %v but were %v.", expected, changes)
+	}
+}
+
+func Test_RequiresRestart_ReloadableAndOtherSettings_OnlyOtherSettingsRequireARestart(t *testing.T) {
+	// arrange
+	settings := map[string]bool{
+		"LogLevel":                                           false,
+		"Server.Network.AccessRules":                         false,
+		"Server.RateLimiting.Burst":                          false,
+		"Server.RateLimiting.MaxConcurrentExpensiveRequests": true,
+		"Server.HTTP.Bindings[0].Port":                       true,
+		"Server.Authentication.Enabled":                      true,
+		"Server.Authentication.Rules[0].Route":               true,
+		"Server.BasePath":                                    true,
+		"Theme.Folder":                                       true,
+	}
+
+	for setting, expected := range settings {
+
+		// act
+		result := RequiresRestart(setting)
+
+		// assert
+		if result != expected {
+			t.Errorf("RequiresRestart(%q) should return %v but returned %v.", setting, expected, result)
+		}
+	}
+}
+
+func Test_Reload_ReloadableAndOtherChanges_OnlyReloadableChangesAreApplied(t *testing.T) {
+	// arrange
+	previous := Default("/repository")
+	previous.Logging.Levels = map[string]string{"search": "Debug"}
+
+	current := Default("/repository")
+	current.LogLevel = "Error"
+	current.Server.BasePath = "/wiki/"
+	current.Logging.Levels = map[string]string{"search": "Warn"}
+
+	// act
+	reloaded, restartRequired := Reload(*previous, *current)
+
+	// assert
+	if reloaded.LogLevel != "Error" || reloaded.Logging.Levels["search"] != "Warn" {
+		t.Errorf("The log level and the module log levels should have been reloaded but were %q and %v.", reloaded.LogLevel, reloaded.Logging.Levels)
+	}
+
+	if reloaded.Server.BasePath != previous.Server.BasePath {
+		t.Errorf("The base path should not have been changed but was %q.", reloaded.Server.BasePath)
+	}
+
+	if !reflect.DeepEqual(restartRequired, []string{"Server.BasePath"}) {
+		t.Errorf("Only the base path should require a restart but %v were reported.", restartRequired)
+	}
+
+	if previous.Logging.Levels["search"] != "Debug" {
+		t.Errorf("The previous configuration should not have been modified but its module log levels are %v.", previous.Logging.Levels)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// The prefixes of the values of the secret settings (the webhook secrets, the authentication user store and the
+// certificates and keys) which reference the secret instead of containing it, so that the configuration file can be
+// committed without the secrets:
+//
+//	"env:ALLMARK_WEBHOOK_SECRET"   the secret is the value of the environment variable
+//	"file:/run/secrets/webhook"    the secret is the content of the file
+const (
+	SecretEnvironmentPrefix = "env:"
+	SecretFilePrefix        = "file:"
+)
+
+var (
+	// secretsDirectory is the private temporary directory the secrets of environment variables are written to
+	// for the consumers which read their secrets from files (the user store and the certificates).
+	secretsDirectory      string
+	secretsDirectoryMutex sync.Mutex
+)
+
+// IsSecretReference returns a flag indicating whether the given value references a secret in an environment variable or a file.
+func IsSecretReference(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, SecretEnvironmentPrefix) || strings.HasPrefix(value, SecretFilePrefix)
+}
+
+// Secret returns the secret of the given value of a secret setting (e.g. the secret of a webhook). References to
+// environment variables and files are resolved; relative file paths are relative to the meta-data folder.
+// All other values are returned as they are.
+func (config *Config) Secret(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+
+	switch {
+	case strings.HasPrefix(trimmed, SecretEnvironmentPrefix):
+		return lookupSecretEnvironmentVariable(strings.TrimPrefix(trimmed, SecretEnvironmentPrefix))
+
+	case strings.HasPrefix(trimmed, SecretFilePrefix):
+		path := getSecretPath(strings.TrimPrefix(trimmed, SecretFilePrefix), config.MetaDataFolder())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("The secret file %q cannot be read. Error: %s", path, err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	return value, nil
+}
+
+// secretFilePath returns the path of the file which contains the secret of the given file name setting (e.g. the key
+// of a certificate). Relative file names are relative to the given folder. The secrets of environment variables are
+// written to a file in a private temporary directory because the consumers of these settings read them from files.
+func (config *Config) secretFilePath(fileName, folder string) (string, error) {
+	fileName = strings.TrimSpace(fileName)
+
+	if !strings.HasPrefix(fileName, SecretEnvironmentPrefix) {
+		return getSecretPath(strings.TrimPrefix(fileName, SecretFilePrefix), folder), nil
+	}
+
+	name := strings.TrimSpace(strings.TrimPrefix(fileName, SecretEnvironmentPrefix))
+	secret, err := lookupSecretEnvironmentVariable(name)
+	if err != nil {
+		return "", err
+	}
+
+	return writeSecretFile(name, secret)
+}
+
+// getSecretPath returns the absolute path of the given secret file.
+func getSecretPath(path, folder string) string {
+	path = strings.TrimSpace(path)
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(folder, path)
+}
+
+// lookupSecretEnvironmentVariable returns the value of the environment variable with the given name.
+func lookupSecretEnvironmentVariable(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("The secret references no environment variable.")
+	}
+
+	secret, exists := os.LookupEnv(name)
+	if !exists {
+		return "", fmt.Errorf("The environment variable %q of the secret is not set.", name)
+	}
+
+	return secret, nil
+}
+
+// writeSecretFile writes the given secret of the environment variable with the given name to the secrets directory
+// (unless it is already up to date) and returns the path of the file.
+func writeSecretFile(name, secret string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%q is not a valid environment variable name.", name)
+	}
+
+	secretsDirectoryMutex.Lock()
+	defer secretsDirectoryMutex.Unlock()
+
+	if secretsDirectory == "" {
+		directory, err := os.MkdirTemp("", "allmark-secrets-")
+		if err != nil {
+			return "", fmt.Errorf("Unable to create a directory for the secret of the environment variable %q. Error: %s", name, err)
+		}
+
+		secretsDirectory = directory
+	}
+
+	path := filepath.Join(secretsDirectory, name)
+	if content, err := os.ReadFile(path); err == nil && bytes.Equal(content, []byte(secret)) {
+		return path, nil
+	}
+
+	if err := os.WriteFile(path, []byte(secret), 0600); err != nil {
+		return "", fmt.Errorf("Unable to write the secret of the environment variable %q. Error: %s", name, err)
+	}
+
+	return path, nil
+}
+
+// RemoveSecretFiles removes the files the secrets of environment variables have been written to.
+func RemoveSecretFiles() error {
+	secretsDirectoryMutex.Lock()
+	defer secretsDirectoryMutex.Unlock()
+
+	if secretsDirectory == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(secretsDirectory); err != nil {
+		return err
+	}
+
+	secretsDirectory = ""
+	return nil
+}
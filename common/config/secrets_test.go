@@ -0,0 +1,130 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Secret_EnvironmentVariable_ValueOfTheVariableIsReturned(t *testing.T) {
+	// arrange
+	t.Setenv("ALLMARK_TEST_WEBHOOK_SECRET", "s3cr3t")
+	config := Default(t.TempDir())
+
+	// act
+	secret, err := config.Secret("env:ALLMARK_TEST_WEBHOOK_SECRET")
+
+	// assert
+	if err != nil {
+		t.Fatalf("Secret() returned an error: %s", err)
+	}
+
+	if secret != "s3cr3t" {
+		t.Errorf("Secret() should return %q but returned %q.", "s3cr3t", secret)
+	}
+}
+
+func Test_Secret_UnsetEnvironmentVariable_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := Default(t.TempDir())
+
+	// act
+	_, err := config.Secret("env:ALLMARK_TEST_SECRET_WHICH_IS_NOT_SET")
+
+	// assert
+	if err == nil {
+		t.Errorf("Secret() should return an error if the environment variable is not set.")
+	}
+}
+
+func Test_Secret_RelativeFile_FileInTheMetaDataFolderIsReadWithoutTrailingNewline(t *testing.T) {
+	// arrange
+	config := Default(t.TempDir())
+	if err := os.MkdirAll(config.MetaDataFolder(), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(config.MetaDataFolder(), "webhook.secret"), []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	secret, err := config.Secret("file:webhook.secret")
+
+	// assert
+	if err != nil {
+		t.Fatalf("Secret() returned an error: %s", err)
+	}
+
+	if secret != "s3cr3t" {
+		t.Errorf("Secret() should return %q but returned %q.", "s3cr3t", secret)
+	}
+}
+
+func Test_Secret_NoReference_ValueIsReturnedAsItIs(t *testing.T) {
+	// arrange
+	config := Default(t.TempDir())
+
+	// act
+	secret, err := config.Secret("file-less secret")
+
+	// assert
+	if err != nil || secret != "file-less secret" {
+		t.Errorf("Secret() should return the value as it is but returned %q (%v).", secret, err)
+	}
+}
+
+func Test_AuthenticationFilePath_EnvironmentVariable_UserStoreIsWrittenToAPrivateFile(t *testing.T) {
+	// arrange
+	t.Setenv("ALLMARK_TEST_USERS", "alice:$apr1$abc$def\n")
+	config := Default(t.TempDir())
+	config.Server.Authentication.UserStoreFileName = "env:ALLMARK_TEST_USERS"
+	defer RemoveSecretFiles()
+
+	// act
+	path, err := config.AuthenticationFilePath()
+
+	// assert
+	if err != nil {
+		t.Fatalf("AuthenticationFilePath() returned an error: %s", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("The user store %q cannot be read: %s", path, err)
+	}
+
+	if string(content) != "alice:$apr1$abc$def\n" {
+		t.Errorf("The user store should contain the value of the environment variable but contained %q.", content)
+	}
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("The user store should only be readable by the owner but its mode is %s.", info.Mode())
+	}
+}
+
+func Test_ListenerCertificateFilePaths_FileReferences_PathsAreResolved(t *testing.T) {
+	// arrange
+	config := Default(t.TempDir())
+	listener := Listener{CertFileName: "file:/run/secrets/site.crt", KeyFileName: "file:site.key"}
+
+	// act
+	certificateFilePath, keyFilePath, err := config.ListenerCertificateFilePaths(listener)
+
+	// assert
+	if err != nil {
+		t.Fatalf("ListenerCertificateFilePaths() returned an error: %s", err)
+	}
+
+	if certificateFilePath != "/run/secrets/site.crt" {
+		t.Errorf("The certificate path should be %q but was %q.", "/run/secrets/site.crt", certificateFilePath)
+	}
+
+	if expected := filepath.Join(config.CertificateDirectory(), "site.key"); keyFilePath != expected {
+		t.Errorf("The key path should be %q but was %q.", expected, keyFilePath)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContentSecurityPolicy allows the theme assets (including the inline scripts and styles of the
+// default theme), the live-reload websocket, Google Analytics, external images and media and the
+// embedded YouTube and Vimeo videos.
+const DefaultContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' https://www.google-analytics.com; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src * data:; " +
+	"media-src *; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self' ws: wss:; " +
+	"frame-src https://www.youtube.com https://player.vimeo.com"
+
+// SecurityHeaders contains the security-related response headers of the web server.
+type SecurityHeaders struct {
+	// Enabled is flag indicating whether the security headers are added to the responses.
+	Enabled bool
+
+	// HSTS contains the HTTP Strict Transport Security settings.
+	HSTS HSTS
+
+	// ContentSecurityPolicy is the value of the Content-Security-Policy header (without the frame-ancestors directive).
+	// If empty, only the frame-ancestors directive is sent.
+	ContentSecurityPolicy string
+
+	// FrameAncestors is the list of sources which may embed the pages in frames (e.g. "'self'", "'none'", "https://example.com").
+	// If empty, any site may embed the pages.
+	FrameAncestors []string
+
+	// ContentTypeNoSniff is flag indicating whether the "X-Content-Type-Options: nosniff" header is sent.
+	ContentTypeNoSniff bool
+
+	// ReferrerPolicy is the value of the Referrer-Policy header (e.g. "strict-origin-when-cross-origin").
+	ReferrerPolicy string
+}
+
+// HSTS contains the HTTP Strict Transport Security settings for HTTPS responses.
+type HSTS struct {
+	// MaxAgeInSeconds defines for how long browsers shall only use HTTPS. Zero disables HSTS.
+	MaxAgeInSeconds int
+
+	// IncludeSubDomains is flag indicating whether the policy also applies to all subdomains.
+	IncludeSubDomains bool
+
+	// Preload is flag indicating whether the domain may be included in the browsers' HSTS preload lists.
+	Preload bool
+}
+
+// StrictTransportSecurityHeader returns the value of the Strict-Transport-Security header
+// or an empty string if HSTS is disabled.
+func (securityHeaders *SecurityHeaders) StrictTransportSecurityHeader() string {
+	if securityHeaders.HSTS.MaxAgeInSeconds <= 0 {
+		return ""
+	}
+
+	value := fmt.Sprintf("max-age=%d", securityHeaders.HSTS.MaxAgeInSeconds)
+
+	if securityHeaders.HSTS.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+
+	if securityHeaders.HSTS.Preload {
+		value += "; preload"
+	}
+
+	return value
+}
+
+// ContentSecurityPolicyHeader returns the value of the Content-Security-Policy header
+// including the frame-ancestors directive.
+func (securityHeaders *SecurityHeaders) ContentSecurityPolicyHeader() string {
+	var directives []string
+
+	for _, directive := range strings.Split(securityHeaders.ContentSecurityPolicy, ";") {
+		if directive = strings.TrimSpace(directive); directive != "" {
+			directives = append(directives, directive)
+		}
+	}
+
+	if len(securityHeaders.FrameAncestors) > 0 {
+		directives = append(directives, "frame-ancestors "+strings.Join(securityHeaders.FrameAncestors, " "))
+	}
+
+	return strings.Join(directives, "; ")
+}
+
+// FrameOptionsHeader returns the value of the legacy X-Frame-Options header for the configured
+// frame ancestors or an empty string if the frame ancestors cannot be expressed with it.
+func (securityHeaders *SecurityHeaders) FrameOptionsHeader() string {
+	if len(securityHeaders.FrameAncestors) != 1 {
+		return ""
+	}
+
+	switch securityHeaders.FrameAncestors[0] {
+	case "'none'":
+		return "DENY"
+
+	case "'self'":
+		return "SAMEORIGIN"
+	}
+
+	return ""
+}
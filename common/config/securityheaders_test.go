@@ -0,0 +1,67 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func Test_ContentSecurityPolicyHeader_FrameAncestors_DirectiveIsAppended(t *testing.T) {
+	// arrange
+	securityHeaders := SecurityHeaders{
+		ContentSecurityPolicy: " default-src 'self';; img-src * ;",
+		FrameAncestors:        []string{"'self'", "https://example.com"},
+	}
+
+	// act
+	result := securityHeaders.ContentSecurityPolicyHeader()
+
+	// assert
+	expected := "default-src 'self'; img-src *; frame-ancestors 'self' https://example.com"
+	if result != expected {
+		t.Errorf("ContentSecurityPolicyHeader() returned %q; expected %q", result, expected)
+	}
+}
+
+func Test_StrictTransportSecurityHeader_AllOptions_AllDirectivesAreIncluded(t *testing.T) {
+	// arrange
+	securityHeaders := SecurityHeaders{
+		HSTS: HSTS{MaxAgeInSeconds: 31536000, IncludeSubDomains: true, Preload: true},
+	}
+
+	// act
+	result := securityHeaders.StrictTransportSecurityHeader()
+
+	// assert
+	expected := "max-age=31536000; includeSubDomains; preload"
+	if result != expected {
+		t.Errorf("StrictTransportSecurityHeader() returned %q; expected %q", result, expected)
+	}
+}
+
+func Test_FrameOptionsHeader_FrameAncestors_LegacyValueIsReturned(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		frameAncestors []string
+		expected       string
+	}{
+		{[]string{"'none'"}, "DENY"},
+		{[]string{"'self'"}, "SAMEORIGIN"},
+		{[]string{"'self'", "https://example.com"}, ""},
+		{[]string{}, ""},
+	}
+
+	for _, input := range inputs {
+		securityHeaders := SecurityHeaders{FrameAncestors: input.frameAncestors}
+
+		// act
+		result := securityHeaders.FrameOptionsHeader()
+
+		// assert
+		if result != input.expected {
+			t.Errorf("FrameOptionsHeader() returned %q for %v; expected %q", result, input.frameAncestors, input.expected)
+		}
+	}
+}
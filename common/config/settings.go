@@ -0,0 +1,93 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A Setting is a value of the configuration which can be set by its name, e.g. with a command line flag.
+type Setting struct {
+	// Name is the path of the value in the configuration in lower case, separated by dots
+	// (e.g. "server.basepath" or "server.http.bindings.0.port").
+	Name string
+
+	// IsBool is a flag indicating whether the value is a boolean.
+	IsBool bool
+
+	// DefaultValue is the default value of the setting in the form it is set (e.g. "true", "0" or "[]").
+	DefaultValue string
+}
+
+// Settings returns all settings of the default configuration, sorted by their name.
+func Settings() []Setting {
+
+	defaultConfig := Default("")
+
+	fields := make(map[string]reflect.Value)
+	getEnvironmentFields(strings.TrimSuffix(EnvironmentVariablePrefix, "_"), reflect.ValueOf(defaultConfig).Elem(), fields)
+
+	settings := make([]Setting, 0, len(fields))
+	for environmentName, field := range fields {
+		settings = append(settings, Setting{
+			Name:         getSettingName(environmentName),
+			IsBool:       field.Kind() == reflect.Bool,
+			DefaultValue: getSettingValue(field),
+		})
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+
+	return settings
+}
+
+// ApplySettings overrides the values of the configuration with the given values by the names of their settings
+// (see Settings). The values are parsed like the values of the environment variables (see ApplyEnvironment),
+// but unknown settings are an error.
+func (config *Config) ApplySettings(values map[string]string) error {
+
+	environmentValues := make(map[string]string, len(values))
+	for name, value := range values {
+		environmentValues[getEnvironmentVariableName(name)] = value
+	}
+
+	if name, err := config.applyValues(environmentValues, true); err != nil {
+		return fmt.Errorf("The value of the setting %q is invalid. Error: %s", getSettingName(name), err)
+	}
+
+	return nil
+}
+
+// getSettingName returns the name of the setting of the given environment variable
+// (e.g. "ALLMARK_SERVER_BASEPATH" → "server.basepath").
+func getSettingName(environmentVariableName string) string {
+	name := strings.TrimPrefix(environmentVariableName, EnvironmentVariablePrefix)
+	return strings.ToLower(strings.Replace(name, "_", ".", -1))
+}
+
+// getEnvironmentVariableName returns the name of the environment variable of the given setting
+// (e.g. "server.basepath" → "ALLMARK_SERVER_BASEPATH").
+func getEnvironmentVariableName(settingName string) string {
+	name := strings.Trim(strings.TrimSpace(settingName), ".")
+	return EnvironmentVariablePrefix + strings.ToUpper(strings.Replace(name, ".", "_", -1))
+}
+
+// getSettingValue returns the given value in the form it is set.
+func getSettingValue(field reflect.Value) string {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map:
+		if serialized, err := json.Marshal(field.Interface()); err == nil {
+			return string(serialized)
+		}
+	}
+
+	return fmt.Sprintf("%v", field.Interface())
+}
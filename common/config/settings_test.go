@@ -0,0 +1,76 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func Test_Settings_DefaultConfiguration_NestedValuesAndListElementsAreListed(t *testing.T) {
+	// act
+	settings := Settings()
+
+	// assert
+	expected := map[string]bool{
+		"server.basepath":             false,
+		"server.http.enabled":         true,
+		"server.http.bindings":        false,
+		"server.http.bindings.0.port": false,
+		"web.defaultlanguage":         false,
+		"loglevel":                    false,
+	}
+
+	for _, setting := range settings {
+		isBool, isExpected := expected[setting.Name]
+		if !isExpected {
+			continue
+		}
+
+		if setting.IsBool != isBool {
+			t.Errorf("The setting %q should be a boolean: %v", setting.Name, isBool)
+		}
+
+		delete(expected, setting.Name)
+	}
+
+	if len(expected) > 0 {
+		t.Errorf("The settings %v were not listed", expected)
+	}
+}
+
+func Test_ApplySettings_ValidValues_ValuesAreOverridden(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+	values := map[string]string{
+		"server.basepath":             "/wiki/",
+		"server.http.bindings.0.port": "8081",
+		"server.writeapi.users":       "alice,bob",
+	}
+
+	// act
+	err := config.ApplySettings(values)
+
+	// assert
+	if err != nil {
+		t.Fatalf("ApplySettings returned an error: %s", err)
+	}
+
+	if config.Server.BasePath != "/wiki/" || config.Server.HTTP.Bindings[0].Port != 8081 || len(config.Server.WriteAPI.Users) != 2 {
+		t.Errorf("The values should have been overridden but the server configuration was %+v", config.Server)
+	}
+}
+
+func Test_ApplySettings_UnknownSetting_ErrorIsReturned(t *testing.T) {
+	// arrange
+	config := Default("/repository")
+
+	// act
+	err := config.ApplySettings(map[string]string{"server.http.enabeld": "true"})
+
+	// assert
+	if err == nil {
+		t.Errorf("ApplySettings should return an error for an unknown setting")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"strings"
+)
+
+// The positions of the HTML snippets on a page.
+const (
+	SnippetPositionHead   = "head"
+	SnippetPositionFooter = "footer"
+)
+
+// SnippetNone is the name by which an item disables the snippet of a position.
+const SnippetNone = "none"
+
+// Snippets contains HTML snippets which are inserted into every page without changing the theme
+// (e.g. analytics scripts, site verification tags or web fonts).
+type Snippets struct {
+	// Head is inserted at the end of the head element, Footer before the end of the body element.
+	Head   string
+	Footer string
+
+	// Named contains alternative snippets which items can select by their name in their meta data
+	// (e.g. "head snippet: landing") instead of the default snippet of a position.
+	Named map[string]string
+}
+
+// Snippet returns the HTML snippet for the given position (see SnippetPositionHead and SnippetPositionFooter):
+// the named snippet if a name is given, nothing for the name "none" and the default snippet of the position
+// otherwise or if there is no snippet with the given name.
+func (snippets Snippets) Snippet(position, name string) string {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, SnippetNone) {
+		return ""
+	}
+
+	if name != "" {
+		for snippetName, snippet := range snippets.Named {
+			if strings.EqualFold(strings.TrimSpace(snippetName), name) {
+				return snippet
+			}
+		}
+	}
+
+	switch position {
+	case SnippetPositionHead:
+		return snippets.Head
+	case SnippetPositionFooter:
+		return snippets.Footer
+	}
+
+	return ""
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+)
+
+func Test_Snippet_NoName_DefaultSnippetOfThePositionIsReturned(t *testing.T) {
+	// arrange
+	snippets := Snippets{Head: "<meta name=\"verification\">", Footer: "<script></script>"}
+
+	// act
+	head := snippets.Snippet(SnippetPositionHead, "")
+	footer := snippets.Snippet(SnippetPositionFooter, "")
+
+	// assert
+	if head != snippets.Head || footer != snippets.Footer {
+		t.Errorf("Snippet should return the default snippets but returned %q and %q.", head, footer)
+	}
+}
+
+func Test_Snippet_NamedSnippet_NamedSnippetIsReturned(t *testing.T) {
+	// arrange
+	snippets := Snippets{Head: "default", Named: map[string]string{"Landing": "landing"}}
+
+	// act
+	result := snippets.Snippet(SnippetPositionHead, "landing")
+
+	// assert
+	if result != "landing" {
+		t.Errorf("Snippet should return the named snippet but returned %q.", result)
+	}
+}
+
+func Test_Snippet_UnknownName_DefaultSnippetIsReturned(t *testing.T) {
+	// arrange
+	snippets := Snippets{Footer: "default"}
+
+	// act
+	result := snippets.Snippet(SnippetPositionFooter, "missing")
+
+	// assert
+	if result != "default" {
+		t.Errorf("Snippet should return the default snippet for unknown names but returned %q.", result)
+	}
+}
+
+func Test_Snippet_None_NothingIsReturned(t *testing.T) {
+	// arrange
+	snippets := Snippets{Head: "default", Named: map[string]string{"none": "named"}}
+
+	// act
+	result := snippets.Snippet(SnippetPositionHead, "None")
+
+	// assert
+	if result != "" {
+		t.Errorf("Snippet should return nothing for the name %q but returned %q.", SnippetNone, result)
+	}
+}
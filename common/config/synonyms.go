@@ -0,0 +1,84 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SynonymGroup is a list of words or phrases which the search treats as equivalent.
+//
+// A synonyms file contains one group per line. The entries of a group are separated
+// by "=" or ",":
+//
+//	# abbreviation = official term
+//	k8s = kubernetes
+//	pr, merge request = pull request
+type SynonymGroup []string
+
+// ParseSynonyms reads the synonym groups from the given reader.
+func ParseSynonyms(reader io.Reader) ([]SynonymGroup, error) {
+	var groups []SynonymGroup
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var group SynonymGroup
+		for _, entry := range strings.FieldsFunc(line, func(character rune) bool { return character == '=' || character == ',' }) {
+			entry = strings.Join(strings.Fields(entry), " ")
+			if entry == "" {
+				continue
+			}
+
+			group = append(group, entry)
+		}
+
+		if len(group) < 2 {
+			return nil, fmt.Errorf("Invalid synonyms in line %d: %q (at least two terms are required)", lineNumber, line)
+		}
+
+		groups = append(groups, group)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// SynonymsFilePath returns the path of the synonyms file.
+func (config *Config) SynonymsFilePath() string {
+	return filepath.Join(config.MetaDataFolder(), SynonymsFileName)
+}
+
+// Synonyms returns the synonym groups defined in the synonyms file of the repository.
+// If there is no synonyms file an empty list is returned.
+func (config *Config) Synonyms() ([]SynonymGroup, error) {
+	file, err := os.Open(config.SynonymsFilePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ParseSynonyms(file)
+}
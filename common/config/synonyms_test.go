@@ -0,0 +1,54 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ParseSynonyms_ValidFile_GroupsAreReturned(t *testing.T) {
+	// arrange
+	input := `
+# team jargon
+k8s = kubernetes
+PR,  merge   request = pull request
+`
+
+	// act
+	groups, err := ParseSynonyms(strings.NewReader(input))
+
+	// assert
+	if err != nil {
+		t.Fatalf("ParseSynonyms returned an error: %s", err)
+	}
+
+	expected := []SynonymGroup{
+		{"k8s", "kubernetes"},
+		{"PR", "merge request", "pull request"},
+	}
+
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("ParseSynonyms should return %q but returned %q", expected, groups)
+	}
+}
+
+func Test_ParseSynonyms_LineWithASingleTerm_ErrorContainsLineNumber(t *testing.T) {
+	// arrange
+	input := "k8s = kubernetes\nk8s =\n"
+
+	// act
+	_, err := ParseSynonyms(strings.NewReader(input))
+
+	// assert
+	if err == nil {
+		t.Fatalf("ParseSynonyms should return an error for a line with a single term")
+	}
+
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("The error should contain the line number but was %q", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Theme contains the settings for themes which are not compiled into allmark.
+//
+// A theme folder is laid out like the meta-data folder of a repository: the "templates"
+// sub-folder contains the templates (e.g. "master.gohtml") and the "theme" sub-folder the
+// assets (e.g. "screen.css"). Both sub-folders are optional; templates and assets which a
+// theme folder does not contain are taken from the next folder or the built-in theme.
+type Theme struct {
+	// Name selects one of the themes installed in the "themes" folder of the meta-data folder
+	// (e.g. "dark" for ".allmark/themes/dark"). The installed theme is used after the theme folders.
+	Name string
+
+	// Folder is the path of a theme folder or a list of paths in the order of their precedence.
+	// Relative paths are relative to the meta-data folder.
+	Folder ThemeFolders
+
+	// Templates maps item types (e.g. "presentation") to the names of the templates the items
+	// of this type are rendered with (e.g. "slides") unless an item selects a template itself.
+	Templates map[string]string
+}
+
+// ItemTemplate returns the name of the template for items of the given type or an empty string
+// if the items are rendered with the default template of their type.
+func (theme Theme) ItemTemplate(itemType string) string {
+	for configuredType, templateName := range theme.Templates {
+		if strings.EqualFold(strings.TrimSpace(configuredType), itemType) {
+			return strings.ToLower(strings.TrimSpace(templateName))
+		}
+	}
+
+	return ""
+}
+
+// ThemeFolders is a list of theme folders. In the configuration file it can be a single path or a list of paths.
+type ThemeFolders []string
+
+// UnmarshalJSON reads a single path or a list of paths.
+func (folders *ThemeFolders) UnmarshalJSON(data []byte) error {
+	var folder string
+	if err := json.Unmarshal(data, &folder); err == nil {
+		*folders = nil
+		if strings.TrimSpace(folder) != "" {
+			*folders = ThemeFolders{folder}
+		}
+
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("The theme folder must be a path or a list of paths")
+	}
+
+	*folders = list
+	return nil
+}
+
+// ThemeFolders returns the paths of the configured theme folders and of the selected installed theme
+// in the order of their precedence.
+func (config *Config) ThemeFolders() []string {
+	var folders []string
+	for _, folder := range config.Theme.Folder {
+		folder = strings.TrimSpace(folder)
+		if folder == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(folder) {
+			folder = filepath.Join(config.MetaDataFolder(), folder)
+		}
+
+		folders = append(folders, filepath.Clean(folder))
+	}
+
+	if themeName := strings.TrimSpace(config.Theme.Name); themeName != "" {
+		folders = append(folders, filepath.Join(config.InstalledThemesFolder(), filepath.Base(themeName)))
+	}
+
+	return folders
+}
+
+// InstalledThemesFolder returns the path of the folder that contains the installed themes (see Theme.Name).
+func (config *Config) InstalledThemesFolder() string {
+	return filepath.Join(config.MetaDataFolder(), ThemesFolderName)
+}
+
+// ThemeAssetFolders returns the folders from which the theme files are served in the order of their precedence:
+// the asset folders of the configured theme folders and the theme folder of the repository.
+func (config *Config) ThemeAssetFolders() []string {
+	var folders []string
+	for _, themeFolder := range config.ThemeFolders() {
+		folders = append(folders, filepath.Join(themeFolder, ThemeFolderName))
+	}
+
+	return append(folders, config.ThemeFolder())
+}
+
+// TemplateFolders returns the folders from which the templates are read in the order of their precedence:
+// the template folders of the configured theme folders and the templates folder of the repository.
+func (config *Config) TemplateFolders() []string {
+	var folders []string
+	for _, themeFolder := range config.ThemeFolders() {
+		folders = append(folders, filepath.Join(themeFolder, TemplatesFolderName))
+	}
+
+	return append(folders, config.TemplatesFolder())
+}
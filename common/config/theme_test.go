@@ -0,0 +1,104 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_UnmarshalJSON_SinglePathOrList_FoldersAreReturned(t *testing.T) {
+	// arrange
+	inputs := map[string]ThemeFolders{
+		`"themes/dark"`:                   {"themes/dark"},
+		`["themes/dark", "/opt/allmark"]`: {"themes/dark", "/opt/allmark"},
+		`""`:                              nil,
+	}
+
+	for input, expected := range inputs {
+		var theme Theme
+
+		// act
+		err := json.Unmarshal([]byte(`{"Folder": `+input+`}`), &theme)
+
+		// assert
+		if err != nil {
+			t.Errorf("Unmarshal(%s) returned an error: %s", input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(theme.Folder, expected) {
+			t.Errorf("Unmarshal(%s) should return %q but returned %q.", input, expected, theme.Folder)
+		}
+	}
+}
+
+func Test_UnmarshalJSON_Number_ErrorIsReturned(t *testing.T) {
+	// arrange
+	var theme Theme
+
+	// act
+	err := json.Unmarshal([]byte(`{"Folder": 1}`), &theme)
+
+	// assert
+	if err == nil {
+		t.Errorf("Unmarshal should return an error if the theme folder is neither a path nor a list of paths.")
+	}
+}
+
+func Test_TemplateFolders_RelativeAndAbsoluteThemeFolders_ThemeFoldersPrecedeTheRepositoryTemplates(t *testing.T) {
+	// arrange
+	config := New("/repository")
+	config.Theme.Folder = ThemeFolders{"themes/dark", "/opt/allmark"}
+
+	// act
+	result := config.TemplateFolders()
+
+	// assert
+	expected := []string{
+		filepath.Join("/repository", MetaDataFolderName, "themes", "dark", TemplatesFolderName),
+		filepath.Join("/opt/allmark", TemplatesFolderName),
+		filepath.Join("/repository", MetaDataFolderName, TemplatesFolderName),
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("TemplateFolders() should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_ThemeFolders_InstalledThemeSelected_InstalledThemeFollowsTheThemeFolders(t *testing.T) {
+	// arrange
+	config := New("/repository")
+	config.Theme.Name = "dark"
+	config.Theme.Folder = ThemeFolders{"overrides"}
+
+	// act
+	result := config.ThemeFolders()
+
+	// assert
+	expected := []string{
+		filepath.Join("/repository", MetaDataFolderName, "overrides"),
+		filepath.Join("/repository", MetaDataFolderName, ThemesFolderName, "dark"),
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ThemeFolders() should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_ItemTemplate_TemplateConfiguredForType_TemplateNameIsReturned(t *testing.T) {
+	// arrange
+	theme := Theme{Templates: map[string]string{"Presentation": " Slides"}}
+
+	// act
+	result := theme.ItemTemplate("presentation")
+
+	// assert
+	if result != "slides" {
+		t.Errorf("ItemTemplate(%q) should return %q but returned %q.", "presentation", "slides", result)
+	}
+}
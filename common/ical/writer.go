@@ -0,0 +1,123 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ical writes the content lines of iCalendar objects (see https://tools.ietf.org/html/rfc5545).
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// lineBreak separates the content lines of iCalendar objects.
+	lineBreak = "\r\n"
+
+	// maxLineLength is the maximum number of octets of a content line; longer lines are folded.
+	maxLineLength = 75
+
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+)
+
+// NewWriter creates a new writer which writes content lines to the given writer.
+func NewWriter(writer io.Writer) *Writer {
+	return &Writer{writer: writer}
+}
+
+// Writer writes iCalendar content lines. The first error stops all further writes and is returned by Err.
+type Writer struct {
+	writer io.Writer
+	err    error
+}
+
+// Begin starts a component (e.g. "VCALENDAR", "VEVENT").
+func (writer *Writer) Begin(component string) {
+	writer.Property("BEGIN", component)
+}
+
+// End ends a component.
+func (writer *Writer) End(component string) {
+	writer.Property("END", component)
+}
+
+// Property writes a property with the given name and (already encoded) value.
+func (writer *Writer) Property(name, value string) {
+	if writer.err != nil {
+		return
+	}
+
+	_, writer.err = io.WriteString(writer.writer, Fold(name+":"+value)+lineBreak)
+}
+
+// Text writes a text property. The value is escaped; empty values are skipped.
+func (writer *Writer) Text(name, value string) {
+	if value == "" {
+		return
+	}
+
+	writer.Property(name, Escape(value))
+}
+
+// Date writes a date property (e.g. "DTSTART;VALUE=DATE:20150803").
+func (writer *Writer) Date(name string, value time.Time) {
+	writer.Property(name+";VALUE=DATE", value.Format(dateFormat))
+}
+
+// DateTime writes a date-time property with the local time of the given value and without
+// time zone (e.g. "DTSTART:20150803T183000"). Such "floating" times are displayed as they are in every time zone.
+func (writer *Writer) DateTime(name string, value time.Time) {
+	writer.Property(name, value.Format(dateTimeFormat))
+}
+
+// Timestamp writes a date-time property in UTC (e.g. "DTSTAMP:20150803T163000Z").
+func (writer *Writer) Timestamp(name string, value time.Time) {
+	writer.Property(name, value.UTC().Format(dateTimeFormat)+"Z")
+}
+
+// Err returns the first error which occurred while writing.
+func (writer *Writer) Err() error {
+	return writer.err
+}
+
+// textEscaper escapes the characters which have a special meaning in text values.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// Escape escapes the backslashes, semicolons, commas and line breaks of the given text value.
+func Escape(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// Fold splits the given content line into lines of at most 75 octets. The continuation lines start with a space.
+// Multi-octet UTF-8 characters are not split.
+func Fold(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+
+	var folded strings.Builder
+	lineLength := 0
+
+	for _, character := range line {
+		characterLength := utf8.RuneLen(character)
+		if lineLength+characterLength > maxLineLength {
+			folded.WriteString(lineBreak + " ")
+			lineLength = 1
+		}
+
+		folded.WriteRune(character)
+		lineLength += characterLength
+	}
+
+	return folded.String()
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Escape_SpecialCharacters_CharactersAreEscaped(t *testing.T) {
+	// arrange
+	input := "Meeting; room 1, floor 2\nBring C:\\notes"
+
+	// act
+	result := Escape(input)
+
+	// assert
+	expected := `Meeting\; room 1\, floor 2\nBring C:\\notes`
+	if result != expected {
+		t.Errorf("Escape(%q) should return %q but returned %q.", input, expected, result)
+	}
+}
+
+func Test_Fold_LongLine_LinesHaveAtMost75Octets(t *testing.T) {
+	// arrange
+	input := "DESCRIPTION:" + strings.Repeat("äbc", 40)
+
+	// act
+	result := Fold(input)
+
+	// assert
+	lines := strings.Split(result, "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("The line should have been folded but was not: %q", result)
+	}
+
+	for index, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("Line %d has %d octets but should have at most 75.", index+1, len(line))
+		}
+
+		if index > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("The continuation line %d should start with a space: %q", index+1, line)
+		}
+	}
+
+	unfolded := strings.Replace(result, "\r\n ", "", -1)
+	if unfolded != input {
+		t.Errorf("The unfolded line should be %q but was %q.", input, unfolded)
+	}
+}
+
+func Test_Writer_DateAndDateTime_ValuesAreFormatted(t *testing.T) {
+	// arrange
+	buffer := new(bytes.Buffer)
+	writer := NewWriter(buffer)
+	value := time.Date(2015, 8, 3, 18, 30, 0, 0, time.UTC)
+
+	// act
+	writer.Date("DTSTART", value)
+	writer.DateTime("DTEND", value)
+	writer.Text("LOCATION", "")
+
+	// assert
+	expected := "DTSTART;VALUE=DATE:20150803\r\nDTEND:20150803T183000\r\n"
+	if buffer.String() != expected {
+		t.Errorf("The writer should have written %q but wrote %q.", expected, buffer.String())
+	}
+}
@@ -7,11 +7,14 @@
 package console
 
 import (
+	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/logger/loglevel"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -23,83 +26,180 @@ const (
 	LogLevelFatal      = "Fatal"
 )
 
-// Default creates a default ConsoleLogger with the Info log level and os.Stdout as the output target.
+// Default creates a default ConsoleLogger with the Info log level and os.Stderr as the output target.
 func Default() *ConsoleLogger {
 	return New(loglevel.Info)
 }
 
-// New creates a new instance of the ConsoleLogger with os.Stdout as the output target.
+// New creates a new instance of the ConsoleLogger with the text format and os.Stderr as the output target.
 func New(level loglevel.LogLevel) *ConsoleLogger {
-	return &ConsoleLogger{
-		output: os.Stdout,
-		level:  level,
+	consoleLogger := &ConsoleLogger{
+		output: &output{
+			writer:  os.Stderr,
+			encoder: logger.TextEncoder{},
+		},
+		levels: &levels{},
 	}
+
+	consoleLogger.SetLevel(level)
+	return consoleLogger
 }
 
 // ConsoleLogger implements the allmark.io/modules/common/logger.Logger interface
 // and provides the ability to write log messages to a given output writer.
 type ConsoleLogger struct {
-	output io.Writer
-	level  loglevel.LogLevel
+	// the output and the levels are shared with the loggers of the modules
+	output *output
+	levels *levels
+
+	module string
+	fields map[string]interface{}
+}
+
+// output is the writer and the encoder of the log messages.
+type output struct {
+	sync.Mutex
+
+	writer  io.Writer
+	encoder logger.Encoder
+}
+
+// levels are the log level of the logger and the log levels of the modules which override it.
+type levels struct {
+	sync.RWMutex
+
+	level        loglevel.LogLevel
+	moduleLevels map[string]loglevel.LogLevel
 }
 
 // SetOutput sets the output of this logger to the supplied io.Writer.
-func (logger *ConsoleLogger) SetOutput(w io.Writer) {
-	log.SetOutput(w)
+func (consoleLogger *ConsoleLogger) SetOutput(w io.Writer) {
+	consoleLogger.output.Lock()
+	defer consoleLogger.output.Unlock()
+
+	consoleLogger.output.writer = w
 }
 
-// Level returns the current log level.
-func (logger *ConsoleLogger) Level() loglevel.LogLevel {
-	return logger.level
+// SetEncoder changes the format of the log messages of this logger (e.g. to logger.JSONEncoder).
+func (consoleLogger *ConsoleLogger) SetEncoder(encoder logger.Encoder) {
+	consoleLogger.output.Lock()
+	defer consoleLogger.output.Unlock()
+
+	consoleLogger.output.encoder = encoder
 }
 
-// Debug formats according to a format specifier and writes a debug log message to standard output.
-func (logger *ConsoleLogger) Debug(format string, v ...interface{}) {
-	if logger.level > loglevel.Debug {
-		return
+// Level returns the current log level (of the module of this logger).
+func (consoleLogger *ConsoleLogger) Level() loglevel.LogLevel {
+	consoleLogger.levels.RLock()
+	defer consoleLogger.levels.RUnlock()
+
+	if level, exists := consoleLogger.levels.moduleLevels[consoleLogger.module]; exists && consoleLogger.module != "" {
+		return level
 	}
 
-	log.Println(fmt.Sprintf("%13s", LogLevelDebug) + fmt.Sprintf("%4s", "") + fmt.Sprintf(format, v...))
+	return consoleLogger.levels.level
 }
 
-// Info formats according to a format specifier and writes an info log message to standard output.
-func (logger *ConsoleLogger) Info(format string, v ...interface{}) {
-	if logger.level > loglevel.Info {
-		return
-	}
+// SetLevel changes the log level of this logger and of all modules without a log level of their own
+// (e.g. when the configuration is reloaded).
+func (consoleLogger *ConsoleLogger) SetLevel(level loglevel.LogLevel) {
+	consoleLogger.levels.Lock()
+	defer consoleLogger.levels.Unlock()
 
-	log.Println(fmt.Sprintf("%13s", LogLevelInfo) + fmt.Sprintf("%4s", "") + fmt.Sprintf(format, v...))
+	consoleLogger.levels.level = level
 }
 
-// Statistics formats according to a format specifier and writes a statistics log message to standard output.
-func (logger *ConsoleLogger) Statistics(format string, v ...interface{}) {
-	if logger.level > loglevel.Statistics {
-		return
+// SetModuleLevels replaces the log levels of the modules (e.g. {"watcher": loglevel.Debug}) which
+// override the log level of this logger for the messages of the modules.
+func (consoleLogger *ConsoleLogger) SetModuleLevels(moduleLevels map[string]loglevel.LogLevel) {
+	normalized := make(map[string]loglevel.LogLevel, len(moduleLevels))
+	for module, level := range moduleLevels {
+		normalized[strings.ToLower(strings.TrimSpace(module))] = level
 	}
 
-	log.Println(fmt.Sprintf("%13s", LogLevelStatistics) + fmt.Sprintf("%4s", "") + fmt.Sprintf(format, v...))
+	consoleLogger.levels.Lock()
+	defer consoleLogger.levels.Unlock()
+
+	consoleLogger.levels.moduleLevels = normalized
 }
 
-// Warn formats according to a format specifier and writes a warn log message to standard output.
-func (logger *ConsoleLogger) Warn(format string, v ...interface{}) {
-	if logger.level > loglevel.Warn {
-		return
+// Module returns a logger whose messages are attributed to the component with the given name (e.g. "thumbnail").
+func (consoleLogger *ConsoleLogger) Module(name string) logger.Logger {
+	moduleLogger := *consoleLogger
+	moduleLogger.module = strings.ToLower(strings.TrimSpace(name))
+	return &moduleLogger
+}
+
+// WithFields returns a logger which adds the given fields to its messages.
+func (consoleLogger *ConsoleLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	combined := make(map[string]interface{}, len(consoleLogger.fields)+len(fields))
+	for name, value := range consoleLogger.fields {
+		combined[name] = value
+	}
+
+	for name, value := range fields {
+		combined[name] = value
 	}
 
-	log.Println(fmt.Sprintf("%13s", LogLevelWarn) + fmt.Sprintf("%4s", "") + fmt.Sprintf(format, v...))
+	fieldLogger := *consoleLogger
+	fieldLogger.fields = combined
+	return &fieldLogger
 }
 
-// Error formats according to a format specifier and writes an error log message to standard output.
-func (logger *ConsoleLogger) Error(format string, v ...interface{}) {
-	if logger.level > loglevel.Error {
-		return
-	}
+// Debug formats according to a format specifier and writes a debug log message to the output.
+func (consoleLogger *ConsoleLogger) Debug(format string, v ...interface{}) {
+	consoleLogger.write(loglevel.Debug, format, v...)
+}
+
+// Info formats according to a format specifier and writes an info log message to the output.
+func (consoleLogger *ConsoleLogger) Info(format string, v ...interface{}) {
+	consoleLogger.write(loglevel.Info, format, v...)
+}
 
-	log.Println(fmt.Sprintf("%13s", LogLevelError) + fmt.Sprintf("%4s", "") + fmt.Sprintf(format, v...))
+// Statistics formats according to a format specifier and writes a statistics log message to the output.
+func (consoleLogger *ConsoleLogger) Statistics(format string, v ...interface{}) {
+	consoleLogger.write(loglevel.Statistics, format, v...)
 }
 
-// Fatal formats according to a format specifier and writes a fatal log message to standard output and exits the application.
-func (logger *ConsoleLogger) Fatal(format string, v ...interface{}) {
-	log.Fatalln(LogLevelFatal + fmt.Sprintf("%4s", "") + fmt.Sprintf(format, v...))
+// Warn formats according to a format specifier and writes a warn log message to the output.
+func (consoleLogger *ConsoleLogger) Warn(format string, v ...interface{}) {
+	consoleLogger.write(loglevel.Warn, format, v...)
+}
+
+// Error formats according to a format specifier and writes an error log message to the output.
+func (consoleLogger *ConsoleLogger) Error(format string, v ...interface{}) {
+	consoleLogger.write(loglevel.Error, format, v...)
+}
+
+// Fatal formats according to a format specifier and writes a fatal log message to the output and exits the application.
+func (consoleLogger *ConsoleLogger) Fatal(format string, v ...interface{}) {
+	consoleLogger.write(loglevel.Fatal, format, v...)
 	os.Exit(1)
 }
+
+// write writes a log message with the given level unless the level is below the level of the logger.
+// Fatal messages are always written.
+func (consoleLogger *ConsoleLogger) write(level loglevel.LogLevel, format string, v ...interface{}) {
+	if level != loglevel.Fatal && consoleLogger.Level() > level {
+		return
+	}
+
+	entry := logger.Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Module:  consoleLogger.module,
+		Message: fmt.Sprintf(format, v...),
+		Fields:  consoleLogger.fields,
+	}
+
+	consoleLogger.output.Lock()
+	defer consoleLogger.output.Unlock()
+
+	line := consoleLogger.output.encoder.Encode(entry)
+	if levelWriter, isLevelWriter := consoleLogger.output.writer.(logger.LevelWriter); isLevelWriter {
+		levelWriter.WriteLevel(level, line)
+		return
+	}
+
+	fmt.Fprintln(consoleLogger.output.writer, line)
+}
@@ -5,6 +5,7 @@
 package console
 
 import (
+	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/logger/loglevel"
 	"bytes"
 	"strings"
@@ -114,3 +115,76 @@ func Test_Error(t *testing.T) {
 		t.Errorf("The function should have written %q to the log.", message)
 	}
 }
+
+func Test_SetLevel_HigherLevel_LowerMessagesAreNoLongerWritten(t *testing.T) {
+	// arrange
+	buf := new(bytes.Buffer)
+
+	logger := New(loglevel.Debug)
+	logger.SetOutput(buf)
+
+	// act
+	logger.SetLevel(loglevel.Warn)
+	logger.Info("An info message")
+
+	// assert
+	if logger.Level() != loglevel.Warn {
+		t.Errorf("The log level should be %q but was %q", loglevel.Warn, logger.Level())
+	}
+
+	if buf.Len() > 0 {
+		t.Errorf("The logger should not have written an info message after the log level was raised but wrote %q", buf.String())
+	}
+}
+
+func Test_Module_JSONEncoder_ModuleAndFieldsAreWrittenToTheSharedOutput(t *testing.T) {
+	// arrange
+	buf := new(bytes.Buffer)
+
+	consoleLogger := New(loglevel.Info)
+	consoleLogger.SetOutput(buf)
+	consoleLogger.SetEncoder(logger.JSONEncoder{})
+
+	moduleLogger := consoleLogger.Module("watcher").WithFields(map[string]interface{}{"path": "documents"})
+
+	// act
+	moduleLogger.Info("Changed")
+	consoleLogger.SetLevel(loglevel.Warn)
+	moduleLogger.Info("Changed again")
+
+	// assert
+	logOutput := strings.TrimSpace(buf.String())
+	if strings.Count(logOutput, "\n") != 0 {
+		t.Fatalf("The module logger should use the level of its parent logger but wrote %q", logOutput)
+	}
+
+	for _, expected := range []string{`"level":"info"`, `"module":"watcher"`, `"message":"Changed"`, `"fields":{"path":"documents"}`} {
+		if !strings.Contains(logOutput, expected) {
+			t.Errorf("The log message %q should contain %s", logOutput, expected)
+		}
+	}
+}
+
+func Test_SetModuleLevels_ModuleWithOwnLevel_OnlyTheModuleLogsDebugMessages(t *testing.T) {
+	// arrange
+	buf := new(bytes.Buffer)
+
+	consoleLogger := New(loglevel.Warn)
+	consoleLogger.SetOutput(buf)
+	consoleLogger.SetModuleLevels(map[string]loglevel.LogLevel{"Watcher": loglevel.Debug})
+
+	// act
+	consoleLogger.Module("watcher").Debug("watcher message")
+	consoleLogger.Module("thumbnail").Debug("thumbnail message")
+	consoleLogger.Debug("application message")
+
+	// assert
+	logOutput := buf.String()
+	if !strings.Contains(logOutput, "watcher message") {
+		t.Errorf("The watcher should log debug messages but wrote %q", logOutput)
+	}
+
+	if strings.Contains(logOutput, "thumbnail message") || strings.Contains(logOutput, "application message") {
+		t.Errorf("The modules without a level of their own should use the log level of the logger but wrote %q", logOutput)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The formats of the log messages.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// The backends the log messages can be written to.
+const (
+	BackendConsole  = "console"
+	BackendSyslog   = "syslog"
+	BackendJournald = "journald"
+)
+
+// IsBackend returns a flag indicating whether the given name (e.g. "syslog") is a known log backend.
+func IsBackend(backend string) bool {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case "", BackendConsole, BackendSyslog, BackendJournald:
+		return true
+	}
+
+	return false
+}
+
+// An Entry is a single log message.
+type Entry struct {
+	Time    time.Time
+	Level   loglevel.LogLevel
+	Message string
+
+	// Module is the name of the component which wrote the message (e.g. "thumbnail"); it is optional.
+	Module string
+
+	// Fields contains additional values of the message (e.g. {"route": "documents/sample"}); they are optional.
+	Fields map[string]interface{}
+}
+
+// A LevelWriter writes encoded log messages together with their level, e.g. to a backend which
+// assigns priorities to the messages (syslog).
+type LevelWriter interface {
+	WriteLevel(level loglevel.LogLevel, line string) error
+}
+
+// An Encoder formats log entries.
+type Encoder interface {
+	// Encode returns the given entry as a single line (without trailing line break).
+	Encode(entry Entry) string
+}
+
+// IsFormat returns a flag indicating whether the given name (e.g. "json") is a known log format.
+func IsFormat(format string) bool {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatText, FormatJSON:
+		return true
+	}
+
+	return false
+}
+
+// NewEncoder returns the encoder for the given format ("text" or "json").
+// Unknown formats fall back to the text format.
+func NewEncoder(format string) Encoder {
+	if strings.ToLower(strings.TrimSpace(format)) == FormatJSON {
+		return JSONEncoder{}
+	}
+
+	return TextEncoder{}
+}
+
+// TextEncoder formats log entries as human-readable lines
+// (e.g. "2015/08/03 10:00:00          Info    [thumbnail] Created 3 thumbnails count=3").
+type TextEncoder struct{}
+
+// Encode returns the given entry as a human-readable line.
+func (TextEncoder) Encode(entry Entry) string {
+	return fmt.Sprintf("%s %13s%4s", entry.Time.Format("2006/01/02 15:04:05"), entry.Level.String(), "") + MessageEncoder{}.Encode(entry)
+}
+
+// MessageEncoder formats log entries without time and level (e.g. "[thumbnail] Created 3 thumbnails count=3")
+// for the backends which record them on their own (e.g. syslog).
+type MessageEncoder struct{}
+
+// Encode returns the module, the message and the fields of the given entry.
+func (MessageEncoder) Encode(entry Entry) string {
+	line := entry.Message
+	if entry.Module != "" {
+		line = "[" + entry.Module + "] " + line
+	}
+
+	for _, name := range sortedFieldNames(entry.Fields) {
+		line += fmt.Sprintf(" %s=%v", name, entry.Fields[name])
+	}
+
+	return line
+}
+
+// JSONEncoder formats log entries as JSON objects
+// (e.g. {"time":"2015-08-03T10:00:00Z","level":"info","module":"thumbnail","message":"...","fields":{"count":3}}).
+type JSONEncoder struct{}
+
+// jsonEntry is the JSON representation of an Entry.
+type jsonEntry struct {
+	Time    string                 `json:"time"`
+	Level   string                 `json:"level"`
+	Module  string                 `json:"module,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Encode returns the given entry as a JSON object.
+func (JSONEncoder) Encode(entry Entry) string {
+	bytes, err := json.Marshal(jsonEntry{
+		Time:    entry.Time.Format(time.RFC3339Nano),
+		Level:   strings.ToLower(entry.Level.String()),
+		Module:  entry.Module,
+		Message: entry.Message,
+		Fields:  entry.Fields,
+	})
+
+	if err != nil {
+		// fields which cannot be serialized (e.g. channels) are written as text
+		fields := make(map[string]interface{}, len(entry.Fields))
+		for name, value := range entry.Fields {
+			fields[name] = fmt.Sprintf("%v", value)
+		}
+
+		entry.Fields = fields
+		return JSONEncoder{}.Encode(entry)
+	}
+
+	return string(bytes)
+}
+
+// sortedFieldNames returns the sorted names of the given fields.
+func sortedFieldNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
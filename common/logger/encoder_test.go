@@ -0,0 +1,89 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_JSONEncoder_EntryWithModuleAndFields_AllValuesAreEncoded(t *testing.T) {
+	// arrange
+	entry := Entry{
+		Time:    time.Date(2015, 8, 3, 10, 0, 0, 0, time.UTC),
+		Level:   loglevel.Warn,
+		Module:  "thumbnail",
+		Message: "Unable to create a thumbnail",
+		Fields:  map[string]interface{}{"route": "documents/sample", "attempt": 2},
+	}
+
+	// act
+	line := JSONEncoder{}.Encode(entry)
+
+	// assert
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("The entry should be encoded as a JSON object but was %q: %s", line, err)
+	}
+
+	expected := map[string]interface{}{
+		"time":    "2015-08-03T10:00:00Z",
+		"level":   "warn",
+		"module":  "thumbnail",
+		"message": "Unable to create a thumbnail",
+	}
+
+	for name, value := range expected {
+		if decoded[name] != value {
+			t.Errorf("The value of %q should be %q but was %q.", name, value, decoded[name])
+		}
+	}
+
+	fields, _ := decoded["fields"].(map[string]interface{})
+	if fields["route"] != "documents/sample" || fields["attempt"] != float64(2) {
+		t.Errorf("The fields were not encoded: %q", line)
+	}
+}
+
+func Test_JSONEncoder_MessageWithLineBreaksAndQuotes_SingleLineIsReturned(t *testing.T) {
+	// act
+	line := JSONEncoder{}.Encode(Entry{Level: loglevel.Error, Message: "first line\nsecond \"line\""})
+
+	// assert
+	if strings.Contains(line, "\n") {
+		t.Errorf("The encoded entry should be a single line but was %q.", line)
+	}
+}
+
+func Test_TextEncoder_EntryWithModuleAndFields_ModuleAndSortedFieldsAreWritten(t *testing.T) {
+	// arrange
+	entry := Entry{
+		Level:   loglevel.Info,
+		Module:  "server",
+		Message: "Listening",
+		Fields:  map[string]interface{}{"port": 8080, "network": "tcp4"},
+	}
+
+	// act
+	line := TextEncoder{}.Encode(entry)
+
+	// assert
+	if !strings.HasSuffix(line, "Info    [server] Listening network=tcp4 port=8080") {
+		t.Errorf("Unexpected text entry %q.", line)
+	}
+}
+
+func Test_NewEncoder_UnknownFormat_TextEncoderIsReturned(t *testing.T) {
+	// act
+	encoder := NewEncoder("xml")
+
+	// assert
+	if _, isText := encoder.(TextEncoder); !isText {
+		t.Errorf("NewEncoder should fall back to the text encoder but returned %T.", encoder)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package logfile provides a log file which is rotated when it reaches a maximum size
+// or after a given interval and which removes old log files.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timestampFormat is the format of the timestamps in the names of the rotated log files
+// (e.g. "allmark-20150803T100000.log").
+const timestampFormat = "20060102T150405"
+
+// Options contains the rotation and retention settings of a log file.
+type Options struct {
+	// MaxSize is the size in bytes at which the log file is rotated; 0 disables the size-based rotation.
+	MaxSize int64
+
+	// RotationInterval is the interval after which the log file is rotated (e.g. 24 hours);
+	// 0 disables the time-based rotation.
+	RotationInterval time.Duration
+
+	// MaxBackups is the number of rotated log files which are kept; 0 keeps all of them.
+	MaxBackups int
+
+	// MaxAge is the age after which rotated log files are removed; 0 keeps all of them.
+	MaxAge time.Duration
+}
+
+// New opens the log file with the given path (the file and its folder are created if they don't exist).
+func New(path string, options Options) (*File, error) {
+	logFile := &File{
+		path:    path,
+		options: options,
+		now:     time.Now,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	if err := logFile.open(); err != nil {
+		return nil, err
+	}
+
+	return logFile, nil
+}
+
+// File is a log file which is rotated according to its options.
+type File struct {
+	sync.Mutex
+
+	path    string
+	options Options
+	now     func() time.Time
+
+	file           *os.File
+	size           int64
+	nextRotationAt time.Time
+}
+
+// Write writes the given bytes to the log file. The log file is rotated before the bytes are
+// written if they would exceed the maximum size or if the rotation interval has elapsed.
+func (logFile *File) Write(p []byte) (int, error) {
+	logFile.Lock()
+	defer logFile.Unlock()
+
+	if logFile.file == nil {
+		return 0, fmt.Errorf("The log file %q is closed.", logFile.path)
+	}
+
+	if logFile.rotationIsDue(int64(len(p))) {
+		if err := logFile.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := logFile.file.Write(p)
+	logFile.size += int64(written)
+	return written, err
+}
+
+// Close closes the log file.
+func (logFile *File) Close() error {
+	logFile.Lock()
+	defer logFile.Unlock()
+
+	if logFile.file == nil {
+		return nil
+	}
+
+	err := logFile.file.Close()
+	logFile.file = nil
+	return err
+}
+
+// Rotate renames the current log file, opens a new one and removes the log files which exceed the retention settings.
+func (logFile *File) Rotate() error {
+	logFile.Lock()
+	defer logFile.Unlock()
+
+	return logFile.rotate()
+}
+
+// rotationIsDue returns a flag indicating whether the log file must be rotated before the given number of bytes is written.
+func (logFile *File) rotationIsDue(length int64) bool {
+	if logFile.size == 0 {
+		return false
+	}
+
+	if logFile.options.MaxSize > 0 && logFile.size+length > logFile.options.MaxSize {
+		return true
+	}
+
+	return logFile.options.RotationInterval > 0 && !logFile.now().Before(logFile.nextRotationAt)
+}
+
+// open opens (or creates) the log file and determines when it must be rotated.
+func (logFile *File) open() error {
+	file, err := os.OpenFile(logFile.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	logFile.file = file
+	logFile.size = info.Size()
+
+	// the rotation times are aligned to the interval (e.g. midnight for daily rotations)
+	if interval := logFile.options.RotationInterval; interval > 0 {
+		logFile.nextRotationAt = logFile.now().Truncate(interval).Add(interval)
+	}
+
+	return nil
+}
+
+// rotate renames the current log file, opens a new one and removes the old log files.
+func (logFile *File) rotate() error {
+	if logFile.file != nil {
+		if err := logFile.file.Close(); err != nil {
+			return err
+		}
+
+		logFile.file = nil
+	}
+
+	if err := os.Rename(logFile.path, logFile.backupPath(logFile.now())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := logFile.open(); err != nil {
+		return err
+	}
+
+	return logFile.removeOldBackups()
+}
+
+// backupPath returns the path a log file which is rotated at the given time is renamed to
+// (e.g. "/var/log/allmark-20150803T100000.log").
+func (logFile *File) backupPath(rotatedAt time.Time) string {
+	extension := filepath.Ext(logFile.path)
+	name := strings.TrimSuffix(logFile.path, extension)
+	path := fmt.Sprintf("%s-%s%s", name, rotatedAt.Format(timestampFormat), extension)
+
+	// several rotations within a second
+	for index := 1; fileExists(path); index++ {
+		path = fmt.Sprintf("%s-%s.%d%s", name, rotatedAt.Format(timestampFormat), index, extension)
+	}
+
+	return path
+}
+
+// backups returns the paths of the rotated log files from the newest to the oldest one.
+func (logFile *File) backups() ([]string, error) {
+	extension := filepath.Ext(logFile.path)
+	prefix := strings.TrimSuffix(filepath.Base(logFile.path), extension) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(logFile.path))
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, extension) {
+			continue
+		}
+
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), extension)
+		if len(timestamp) < len(timestampFormat) {
+			continue
+		}
+
+		if _, err := time.Parse(timestampFormat, timestamp[:len(timestampFormat)]); err != nil {
+			continue
+		}
+
+		backups = append(backups, filepath.Join(filepath.Dir(logFile.path), name))
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
+
+// removeOldBackups removes the rotated log files which exceed the maximum number of backups or the maximum age.
+func (logFile *File) removeOldBackups() error {
+	if logFile.options.MaxBackups <= 0 && logFile.options.MaxAge <= 0 {
+		return nil
+	}
+
+	backups, err := logFile.backups()
+	if err != nil {
+		return err
+	}
+
+	for index, backup := range backups {
+		exceedsMaxBackups := logFile.options.MaxBackups > 0 && index >= logFile.options.MaxBackups
+		exceedsMaxAge := false
+		if logFile.options.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil {
+				exceedsMaxAge = logFile.now().Sub(info.ModTime()) > logFile.options.MaxAge
+			}
+		}
+
+		if !exceedsMaxBackups && !exceedsMaxAge {
+			continue
+		}
+
+		if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
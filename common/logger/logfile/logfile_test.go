@@ -0,0 +1,105 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Write_MaxSizeExceeded_LogFileIsRotated(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "logs", "allmark.log")
+	logFile, err := New(path, Options{MaxSize: 10})
+	if err != nil {
+		t.Fatalf("New returned an error: %s", err)
+	}
+
+	defer logFile.Close()
+
+	// act
+	logFile.Write([]byte("12345678\n"))
+	logFile.Write([]byte("abc\n"))
+
+	// assert
+	content, _ := os.ReadFile(path)
+	if string(content) != "abc\n" {
+		t.Errorf("The log file should only contain the message after the rotation but contained %q.", content)
+	}
+
+	backups, _ := logFile.backups()
+	if len(backups) != 1 {
+		t.Fatalf("There should be one rotated log file but there were %v.", backups)
+	}
+
+	if content, _ := os.ReadFile(backups[0]); string(content) != "12345678\n" {
+		t.Errorf("The rotated log file should contain the first message but contained %q.", content)
+	}
+}
+
+func Test_Write_RotationIntervalElapsed_LogFileIsRotated(t *testing.T) {
+	// arrange
+	now := time.Date(2015, 8, 3, 23, 59, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "allmark.log")
+	logFile, err := New(path, Options{RotationInterval: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("New returned an error: %s", err)
+	}
+
+	defer logFile.Close()
+
+	logFile.now = func() time.Time { return now }
+	logFile.nextRotationAt = now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+	// act
+	logFile.Write([]byte("before midnight\n"))
+	now = now.Add(2 * time.Minute)
+	logFile.Write([]byte("after midnight\n"))
+
+	// assert
+	expectedBackupPath := filepath.Join(filepath.Dir(path), "allmark-20150804T000100.log")
+	if content, _ := os.ReadFile(expectedBackupPath); string(content) != "before midnight\n" {
+		t.Errorf("The log file should have been rotated at midnight to %q.", expectedBackupPath)
+	}
+
+	if logFile.nextRotationAt != time.Date(2015, 8, 5, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("The next rotation should be at the following midnight but was at %s.", logFile.nextRotationAt)
+	}
+}
+
+func Test_Rotate_MaxBackups_OldestLogFilesAreRemoved(t *testing.T) {
+	// arrange
+	now := time.Date(2015, 8, 3, 10, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "allmark.log")
+	logFile, err := New(path, Options{MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("New returned an error: %s", err)
+	}
+
+	defer logFile.Close()
+	logFile.now = func() time.Time { return now }
+
+	// act
+	for hour := 0; hour < 4; hour++ {
+		logFile.Write([]byte("message\n"))
+		now = now.Add(time.Hour)
+		if err := logFile.Rotate(); err != nil {
+			t.Fatalf("Rotate returned an error: %s", err)
+		}
+	}
+
+	// assert
+	backups, _ := logFile.backups()
+	expected := []string{
+		filepath.Join(filepath.Dir(path), "allmark-20150803T140000.log"),
+		filepath.Join(filepath.Dir(path), "allmark-20150803T130000.log"),
+	}
+
+	if len(backups) != 2 || backups[0] != expected[0] || backups[1] != expected[1] {
+		t.Errorf("Only the two newest log files %v should be kept but there were %v.", expected, backups)
+	}
+}
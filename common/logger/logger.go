@@ -29,4 +29,25 @@ type Logger interface {
 
 	// Fatal formats according to a format specifier and writes a fatal log message.
 	Fatal(format string, v ...interface{})
+
+	// Module returns a logger whose messages are attributed to the component with the given name (e.g. "thumbnail").
+	Module(name string) Logger
+
+	// WithFields returns a logger which adds the given fields (e.g. {"route": "documents/sample"}) to its messages.
+	WithFields(fields map[string]interface{}) Logger
+}
+
+// Modules contains the names of the modules of allmark whose messages can be logged with their own log level
+// (e.g. "watcher"). The components name themselves with Logger.Module when they are created.
+var Modules = []string{
+	"server",
+	"repository",
+	"watcher",
+	"parser",
+	"converter",
+	"search",
+	"thumbnail",
+	"conversion",
+	"livereload",
+	"webhooks",
 }
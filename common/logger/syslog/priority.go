@@ -0,0 +1,102 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package syslog provides log backends which write the log messages with their priorities
+// to syslog or to the systemd journal.
+package syslog
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// The syslog priorities (severities) of the log levels.
+const (
+	PriorityCritical = 2
+	PriorityError    = 3
+	PriorityWarning  = 4
+	PriorityNotice   = 5
+	PriorityInfo     = 6
+	PriorityDebug    = 7
+)
+
+// Priority returns the syslog priority of the given log level.
+func Priority(level loglevel.LogLevel) int {
+	switch level {
+	case loglevel.Debug:
+		return PriorityDebug
+
+	case loglevel.Info:
+		return PriorityInfo
+
+	case loglevel.Statistics:
+		return PriorityNotice
+
+	case loglevel.Warn:
+		return PriorityWarning
+
+	case loglevel.Error:
+		return PriorityError
+
+	case loglevel.Fatal:
+		return PriorityCritical
+	}
+
+	return PriorityInfo
+}
+
+// JournalStreamEnvironmentVariable is the environment variable which systemd sets for services
+// whose standard output or standard error output is connected to the journal ("<device>:<inode>").
+const JournalStreamEnvironmentVariable = "JOURNAL_STREAM"
+
+// NewJournalWriter creates a writer which writes the log messages to the given output (usually the
+// standard error output of a systemd service) with the priority prefixes journald understands (e.g. "<4>").
+func NewJournalWriter(output io.Writer) *JournalWriter {
+	return &JournalWriter{output: output}
+}
+
+// JournalWriter writes log messages with priority prefixes to the systemd journal.
+type JournalWriter struct {
+	sync.Mutex
+	output io.Writer
+}
+
+// Write writes the given bytes with the info priority.
+func (journalWriter *JournalWriter) Write(p []byte) (int, error) {
+	if err := journalWriter.WriteLevel(loglevel.Info, strings.TrimRight(string(p), "\n")); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// WriteLevel writes the given line with the priority of the given level. Lines with line breaks are
+// written as separate journal entries with the same priority.
+func (journalWriter *JournalWriter) WriteLevel(level loglevel.LogLevel, line string) error {
+	journalWriter.Lock()
+	defer journalWriter.Unlock()
+
+	priority := Priority(level)
+	for _, part := range strings.Split(line, "\n") {
+		if _, err := fmt.Fprintf(journalWriter.output, "<%d>%s\n", priority, part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsJournalStream returns a flag indicating whether the standard error output is connected to the systemd journal.
+func IsJournalStream() bool {
+	journalStream := strings.TrimSpace(os.Getenv(JournalStreamEnvironmentVariable))
+	if journalStream == "" {
+		return false
+	}
+
+	return isStream(os.Stderr, journalStream)
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package syslog
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"bytes"
+	"testing"
+)
+
+func Test_Priority_AllLogLevels_SyslogSeveritiesAreReturned(t *testing.T) {
+	// arrange
+	expected := map[loglevel.LogLevel]int{
+		loglevel.Debug:      7,
+		loglevel.Info:       6,
+		loglevel.Statistics: 5,
+		loglevel.Warn:       4,
+		loglevel.Error:      3,
+		loglevel.Fatal:      2,
+	}
+
+	for level, priority := range expected {
+		// act
+		result := Priority(level)
+
+		// assert
+		if result != priority {
+			t.Errorf("The priority of the log level %q should be %d but was %d.", level, priority, result)
+		}
+	}
+}
+
+func Test_WriteLevel_MultiLineMessage_EveryLineHasThePriorityPrefix(t *testing.T) {
+	// arrange
+	buf := new(bytes.Buffer)
+	journalWriter := NewJournalWriter(buf)
+
+	// act
+	journalWriter.WriteLevel(loglevel.Warn, "first line\nsecond line")
+
+	// assert
+	if buf.String() != "<4>first line\n<4>second line\n" {
+		t.Errorf("Every line should be prefixed with the priority but the output was %q.", buf.String())
+	}
+}
+
+func Test_IsJournalStream_NoJournalStream_FalseIsReturned(t *testing.T) {
+	// arrange
+	t.Setenv(JournalStreamEnvironmentVariable, "")
+
+	// act
+	result := IsJournalStream()
+
+	// assert
+	if result {
+		t.Errorf("IsJournalStream should return false if %s is not set.", JournalStreamEnvironmentVariable)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package syslog
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"fmt"
+	gosyslog "log/syslog"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// New connects to the syslog daemon at the given address (e.g. "udp", "logs.example.com:514").
+// If no network and address are given it connects to the local syslog daemon. The messages are
+// sent with the daemon facility and the given tag (e.g. "allmark").
+func New(network, address, tag string) (*Writer, error) {
+	syslogWriter, err := gosyslog.Dial(network, address, gosyslog.LOG_DAEMON|gosyslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{syslogWriter: syslogWriter}, nil
+}
+
+// Writer writes log messages with the priorities of their levels to syslog.
+type Writer struct {
+	syslogWriter *gosyslog.Writer
+}
+
+// Write writes the given bytes with the info priority.
+func (writer *Writer) Write(p []byte) (int, error) {
+	return writer.syslogWriter.Write(p)
+}
+
+// WriteLevel writes the given line with the priority of the given level.
+func (writer *Writer) WriteLevel(level loglevel.LogLevel, line string) error {
+	switch Priority(level) {
+	case PriorityDebug:
+		return writer.syslogWriter.Debug(line)
+
+	case PriorityNotice:
+		return writer.syslogWriter.Notice(line)
+
+	case PriorityWarning:
+		return writer.syslogWriter.Warning(line)
+
+	case PriorityError:
+		return writer.syslogWriter.Err(line)
+
+	case PriorityCritical:
+		return writer.syslogWriter.Crit(line)
+	}
+
+	return writer.syslogWriter.Info(line)
+}
+
+// Close closes the connection to the syslog daemon.
+func (writer *Writer) Close() error {
+	return writer.syslogWriter.Close()
+}
+
+// isStream returns a flag indicating whether the given file is the stream with the given
+// device and inode number ("<device>:<inode>").
+func isStream(file *os.File, stream string) bool {
+	var stat syscall.Stat_t
+	if err := syscall.Fstat(int(file.Fd()), &stat); err != nil {
+		return false
+	}
+
+	device, inode, found := strings.Cut(stream, ":")
+	return found && device == fmt.Sprintf("%d", stat.Dev) && inode == fmt.Sprintf("%d", stat.Ino)
+}
@@ -0,0 +1,39 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package syslog
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"fmt"
+	"os"
+)
+
+// New returns an error because syslog is not available on Windows.
+func New(network, address, tag string) (*Writer, error) {
+	return nil, fmt.Errorf("Syslog is not available on Windows.")
+}
+
+// Writer writes log messages to syslog; it is not available on Windows.
+type Writer struct{}
+
+// Write does nothing on Windows.
+func (writer *Writer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel does nothing on Windows.
+func (writer *Writer) WriteLevel(level loglevel.LogLevel, line string) error {
+	return nil
+}
+
+// Close does nothing on Windows.
+func (writer *Writer) Close() error {
+	return nil
+}
+
+// isStream returns false because there is no systemd journal on Windows.
+func isStream(file *os.File, stream string) bool {
+	return false
+}
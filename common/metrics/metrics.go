@@ -0,0 +1,301 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metrics contains counters, gauges and histograms that can be
+// exported in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets contains the default histogram buckets (in seconds) for durations.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry used by the package-level constructors.
+var Default = NewRegistry()
+
+// NewCounter creates a new counter in the default registry.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return Default.NewCounter(name, help, labelNames...)
+}
+
+// NewGauge creates a new gauge in the default registry.
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return Default.NewGauge(name, help, labelNames...)
+}
+
+// NewHistogram creates a new histogram in the default registry.
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labelNames...)
+}
+
+// NewRegistry creates a new and empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Registry is a collection of metrics.
+type Registry struct {
+	sync.Mutex
+	metrics []metric
+}
+
+type metric interface {
+	name() string
+	write(writer io.Writer)
+}
+
+// NewCounter creates a new counter with the given name and label names.
+func (registry *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	counter := &Counter{newVector(name, help, "counter", labelNames)}
+	registry.register(counter)
+	return counter
+}
+
+// NewGauge creates a new gauge with the given name and label names.
+func (registry *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	gauge := &Gauge{newVector(name, help, "gauge", labelNames)}
+	registry.register(gauge)
+	return gauge
+}
+
+// NewHistogram creates a new histogram with the given name, upper bucket bounds and label names.
+func (registry *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	sortedBuckets := append([]float64{}, buckets...)
+	sort.Float64s(sortedBuckets)
+
+	histogram := &Histogram{
+		vector:  newVector(name, help, "histogram", labelNames),
+		buckets: sortedBuckets,
+		series:  make(map[string]*histogramSeries),
+	}
+
+	registry.register(histogram)
+	return histogram
+}
+
+// register adds the given metric to the registry. Existing metrics with the same name are replaced.
+func (registry *Registry) register(newMetric metric) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for index, existingMetric := range registry.metrics {
+		if existingMetric.name() == newMetric.name() {
+			registry.metrics[index] = newMetric
+			return
+		}
+	}
+
+	registry.metrics = append(registry.metrics, newMetric)
+}
+
+// Write writes all metrics of the registry to the given writer using the Prometheus text format.
+func (registry *Registry) Write(writer io.Writer) {
+	registry.Lock()
+	metrics := append([]metric{}, registry.metrics...)
+	registry.Unlock()
+
+	for _, metric := range metrics {
+		metric.write(writer)
+	}
+}
+
+// vector contains the values of a metric for all label combinations.
+type vector struct {
+	sync.Mutex
+
+	metricName string
+	help       string
+	metricType string
+	labelNames []string
+
+	values map[string]float64
+}
+
+func newVector(name, help, metricType string, labelNames []string) vector {
+	return vector{
+		metricName: name,
+		help:       help,
+		metricType: metricType,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+	}
+}
+
+func (v *vector) name() string {
+	return v.metricName
+}
+
+// labels returns the formatted labels (e.g. `handler="item",code="200"`) for the given label values.
+func (v *vector) labels(labelValues []string) string {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("Metric %q expects %d label values but got %d", v.metricName, len(v.labelNames), len(labelValues)))
+	}
+
+	pairs := make([]string, len(labelValues))
+	for index, labelName := range v.labelNames {
+		pairs[index] = fmt.Sprintf("%s=%q", labelName, labelValues[index])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (v *vector) writeHeader(writer io.Writer) {
+	fmt.Fprintf(writer, "# HELP %s %s\n", v.metricName, v.help)
+	fmt.Fprintf(writer, "# TYPE %s %s\n", v.metricName, v.metricType)
+}
+
+func (v *vector) write(writer io.Writer) {
+	v.Lock()
+	defer v.Unlock()
+
+	v.writeHeader(writer)
+	for _, labels := range sortedKeys(v.values) {
+		writeSample(writer, v.metricName, labels, v.values[labels])
+	}
+}
+
+// Counter is a metric whose value can only increase (e.g. the number of requests).
+type Counter struct {
+	vector
+}
+
+// Inc increments the counter for the given label values by one.
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by the given (non-negative) value.
+func (counter *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic(fmt.Sprintf("Counter %q cannot be decreased", counter.metricName))
+	}
+
+	labels := counter.labels(labelValues)
+
+	counter.Lock()
+	defer counter.Unlock()
+	counter.values[labels] += value
+}
+
+// Gauge is a metric whose value can go up and down (e.g. the number of items).
+type Gauge struct {
+	vector
+}
+
+// Set sets the gauge for the given label values to the given value.
+func (gauge *Gauge) Set(value float64, labelValues ...string) {
+	labels := gauge.labels(labelValues)
+
+	gauge.Lock()
+	defer gauge.Unlock()
+	gauge.values[labels] = value
+}
+
+// Histogram counts observations (e.g. request durations) in configurable buckets.
+type Histogram struct {
+	vector
+
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// Observe adds the given value to the histogram for the given label values.
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	labels := histogram.labels(labelValues)
+
+	histogram.Lock()
+	defer histogram.Unlock()
+
+	series, exists := histogram.series[labels]
+	if !exists {
+		series = &histogramSeries{bucketCounts: make([]uint64, len(histogram.buckets))}
+		histogram.series[labels] = series
+	}
+
+	for index, upperBound := range histogram.buckets {
+		if value <= upperBound {
+			series.bucketCounts[index]++
+		}
+	}
+
+	series.count++
+	series.sum += value
+}
+
+func (histogram *Histogram) write(writer io.Writer) {
+	histogram.Lock()
+	defer histogram.Unlock()
+
+	histogram.writeHeader(writer)
+
+	labelSets := make([]string, 0, len(histogram.series))
+	for labels := range histogram.series {
+		labelSets = append(labelSets, labels)
+	}
+	sort.Strings(labelSets)
+
+	for _, labels := range labelSets {
+		series := histogram.series[labels]
+
+		for index, upperBound := range histogram.buckets {
+			writeSample(writer, histogram.metricName+"_bucket", joinLabels(labels, fmt.Sprintf("le=%q", formatFloat(upperBound))), float64(series.bucketCounts[index]))
+		}
+
+		writeSample(writer, histogram.metricName+"_bucket", joinLabels(labels, `le="+Inf"`), float64(series.count))
+		writeSample(writer, histogram.metricName+"_sum", labels, series.sum)
+		writeSample(writer, histogram.metricName+"_count", labels, float64(series.count))
+	}
+}
+
+func writeSample(writer io.Writer, name, labels string, value float64) {
+	if labels == "" {
+		fmt.Fprintf(writer, "%s %s\n", name, formatFloat(value))
+		return
+	}
+
+	fmt.Fprintf(writer, "%s{%s} %s\n", name, labels, formatFloat(value))
+}
+
+func joinLabels(labels, additionalLabel string) string {
+	if labels == "" {
+		return additionalLabel
+	}
+
+	return labels + "," + additionalLabel
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_Counter_IncrementedTwice_ValueIsTwo(t *testing.T) {
+	// arrange
+	registry := NewRegistry()
+	counter := registry.NewCounter("requests_total", "Number of requests.", "handler", "code")
+
+	// act
+	counter.Inc("item", "200")
+	counter.Inc("item", "200")
+	counter.Inc("item", "404")
+
+	buffer := new(bytes.Buffer)
+	registry.Write(buffer)
+
+	// assert
+	expected := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{handler="item",code="200"} 2
+requests_total{handler="item",code="404"} 1
+`
+	if buffer.String() != expected {
+		t.Errorf("Write returned:\n%s\nExpected:\n%s", buffer.String(), expected)
+	}
+}
+
+func Test_Gauge_NoLabels_ValueIsWrittenWithoutBraces(t *testing.T) {
+	// arrange
+	registry := NewRegistry()
+	gauge := registry.NewGauge("items", "Number of items.")
+
+	// act
+	gauge.Set(42)
+
+	buffer := new(bytes.Buffer)
+	registry.Write(buffer)
+
+	// assert
+	if !strings.Contains(buffer.String(), "\nitems 42\n") {
+		t.Errorf("Write returned %q which does not contain the gauge value", buffer.String())
+	}
+}
+
+func Test_Histogram_Observations_BucketsAreCumulative(t *testing.T) {
+	// arrange
+	registry := NewRegistry()
+	histogram := registry.NewHistogram("duration_seconds", "Durations.", []float64{1, 0.1})
+
+	// act
+	histogram.Observe(0.05)
+	histogram.Observe(0.5)
+	histogram.Observe(3)
+
+	buffer := new(bytes.Buffer)
+	registry.Write(buffer)
+
+	// assert
+	expectedLines := []string{
+		`duration_seconds_bucket{le="0.1"} 1`,
+		`duration_seconds_bucket{le="1"} 2`,
+		`duration_seconds_bucket{le="+Inf"} 3`,
+		`duration_seconds_sum 3.55`,
+		`duration_seconds_count 3`,
+	}
+
+	for _, line := range expectedLines {
+		if !strings.Contains(buffer.String(), line+"\n") {
+			t.Errorf("Write returned:\n%s\nExpected line %q", buffer.String(), line)
+		}
+	}
+}
+
+func Test_Register_SameNameTwice_MetricIsReplaced(t *testing.T) {
+	// arrange
+	registry := NewRegistry()
+	registry.NewGauge("items", "Number of items.").Set(1)
+
+	// act
+	registry.NewGauge("items", "Number of items.").Set(2)
+
+	buffer := new(bytes.Buffer)
+	registry.Write(buffer)
+
+	// assert
+	if strings.Count(buffer.String(), "# TYPE items") != 1 || !strings.Contains(buffer.String(), "items 2\n") {
+		t.Errorf("The second gauge should replace the first one. Write returned:\n%s", buffer.String())
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package progress reports the progress of long-running tasks (e.g. the parsing of the items of a large
+// repository). The progress of running tasks is logged periodically and the state of the last run of every
+// task is available for the status endpoints.
+package progress
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// logInterval is the interval in which the progress of running tasks is logged.
+// Tasks which finish faster are not logged at all.
+var logInterval = 5 * time.Second
+
+var (
+	registryLock sync.RWMutex
+	registry     []*Task
+)
+
+// Start creates a new task with the given name (e.g. "item parsing") which processes the given total number
+// of units (e.g. "items"); 0 means the total is not known. The task replaces the previous run of the task
+// with the same name in the list of tasks and its progress is logged until Finish is called.
+func Start(logger logger.Logger, name, unit string, total int) *Task {
+	task := &Task{
+		logger:    logger,
+		name:      name,
+		unit:      unit,
+		total:     int64(total),
+		startTime: time.Now(),
+		done:      make(chan bool),
+	}
+
+	register(task)
+	go task.log()
+
+	return task
+}
+
+// Tasks returns the status of the last run of all tasks in the order in which they were started first.
+func Tasks() []Status {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	tasks := make([]Status, 0, len(registry))
+	for _, task := range registry {
+		tasks = append(tasks, task.Status())
+	}
+
+	return tasks
+}
+
+// register adds the given task to the registry or replaces the previous run of the task.
+func register(task *Task) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	for index, registeredTask := range registry {
+		if registeredTask.name == task.name {
+			registry[index] = task
+			return
+		}
+	}
+
+	registry = append(registry, task)
+}
+
+// Task is a long-running task which processes a number of units (e.g. items).
+// All methods can be called on a nil task, so optional progress reporting doesn't need extra checks.
+type Task struct {
+	logger logger.Logger
+
+	name string
+	unit string
+
+	total int64
+	count int64
+
+	startTime time.Time
+	endTime   atomic.Value
+
+	done     chan bool
+	finished sync.Once
+}
+
+// Status describes the progress of a task.
+type Status struct {
+	Name  string
+	Unit  string
+	Count int
+	Total int
+
+	// RatePerSecond is the average number of units which were processed per second.
+	RatePerSecond float64
+
+	Duration time.Duration
+	Finished bool
+}
+
+// Add records that the given number of units have been processed.
+func (task *Task) Add(units int) {
+	if task == nil {
+		return
+	}
+
+	atomic.AddInt64(&task.count, int64(units))
+}
+
+// Increment records that one unit has been processed.
+func (task *Task) Increment() {
+	task.Add(1)
+}
+
+// Finish marks the task as finished and stops the logging of its progress.
+func (task *Task) Finish() {
+	if task == nil {
+		return
+	}
+
+	task.finished.Do(func() {
+		task.endTime.Store(time.Now())
+		close(task.done)
+	})
+}
+
+// Status returns the current progress of the task.
+func (task *Task) Status() Status {
+	status := Status{
+		Name:  task.name,
+		Unit:  task.unit,
+		Count: int(atomic.LoadInt64(&task.count)),
+		Total: int(task.total),
+	}
+
+	endTime, finished := task.endTime.Load().(time.Time)
+	if !finished {
+		endTime = time.Now()
+	}
+
+	status.Finished = finished
+	status.Duration = endTime.Sub(task.startTime)
+	if seconds := status.Duration.Seconds(); seconds > 0 {
+		status.RatePerSecond = float64(status.Count) / seconds
+	}
+
+	return status
+}
+
+// log logs the progress of the task in the log interval until the task has finished. The completion of
+// the task is only logged if its progress has been logged before.
+func (task *Task) log() {
+	ticker := time.NewTicker(logInterval)
+	defer ticker.Stop()
+
+	progressWasLogged := false
+	for {
+		select {
+		case <-ticker.C:
+			status := task.Status()
+			if status.Total > 0 {
+				task.logger.Info("%s: %d of %d %s (%.1f %s/s)", task.name, status.Count, status.Total, task.unit, status.RatePerSecond, task.unit)
+			} else {
+				task.logger.Info("%s: %d %s (%.1f %s/s)", task.name, status.Count, task.unit, status.RatePerSecond, task.unit)
+			}
+
+			progressWasLogged = true
+
+		case <-task.done:
+			status := task.Status()
+			if progressWasLogged {
+				task.logger.Info("%s finished: %d %s in %s (%.1f %s/s)", task.name, status.Count, task.unit, status.Duration.Truncate(time.Millisecond), status.RatePerSecond, task.unit)
+			} else {
+				task.logger.Debug("%s finished: %d %s in %s", task.name, status.Count, task.unit, status.Duration.Truncate(time.Millisecond))
+			}
+
+			return
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package progress
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"testing"
+)
+
+func Test_Status_UnitsAdded_CountIsReturned(t *testing.T) {
+	// arrange
+	task := Start(console.New(loglevel.Off), "test parsing", "items", 10)
+	task.Add(3)
+	task.Increment()
+
+	// act
+	status := task.Status()
+
+	// assert
+	if status.Count != 4 || status.Total != 10 || status.Finished {
+		t.Errorf("The status should contain 4 of 10 processed items but was %#v.", status)
+	}
+}
+
+func Test_Status_TaskFinished_DurationDoesNotChange(t *testing.T) {
+	// arrange
+	task := Start(console.New(loglevel.Off), "test hashing", "items", 0)
+
+	// act
+	task.Finish()
+	duration := task.Status().Duration
+
+	// assert
+	if status := task.Status(); !status.Finished || status.Duration != duration {
+		t.Errorf("The duration of a finished task should not change but the status was %#v.", status)
+	}
+}
+
+func Test_Tasks_TaskRestarted_LastRunReplacesPreviousRun(t *testing.T) {
+	// arrange
+	Start(console.New(loglevel.Off), "test scan", "items", 0).Finish()
+
+	// act
+	Start(console.New(loglevel.Off), "test scan", "items", 5).Finish()
+
+	// assert
+	numberOfRuns := 0
+	for _, status := range Tasks() {
+		if status.Name != "test scan" {
+			continue
+		}
+
+		numberOfRuns++
+		if status.Total != 5 {
+			t.Errorf("The status of the last run should have been returned but was %#v.", status)
+		}
+	}
+
+	if numberOfRuns != 1 {
+		t.Errorf("Only the last run of the task should have been returned but there were %d runs.", numberOfRuns)
+	}
+}
@@ -6,23 +6,49 @@ package shutdown
 
 import (
 	"fmt"
+	"sync"
 )
 
 var (
 	callbacks = make([]func() error, 0)
+	lock      sync.Mutex
+	once      sync.Once
+
+	// started is closed when the shutdown begins
+	started = make(chan struct{})
 )
 
 func Register(callback func() error) {
+	lock.Lock()
+	defer lock.Unlock()
+
 	callbacks = append(callbacks, callback)
 }
 
+// Started returns a channel which is closed as soon as the shutdown begins,
+// e.g. to end long-running requests which would otherwise delay the shutdown.
+func Started() <-chan struct{} {
+	return started
+}
+
+// Shutdown executes all registered callbacks in the reverse order of their
+// registration (components which have been created last are stopped first).
+// Subsequent calls have no effect.
 func Shutdown() {
 
-	for _, callback := range callbacks {
-		err := callback()
-		if err != nil {
-			fmt.Println(err.Error())
+	once.Do(func() {
+		close(started)
+
+		lock.Lock()
+		registeredCallbacks := callbacks
+		lock.Unlock()
+
+		for index := len(registeredCallbacks) - 1; index >= 0; index-- {
+			err := registeredCallbacks[index]()
+			if err != nil {
+				fmt.Println(err.Error())
+			}
 		}
-	}
+	})
 
 }
@@ -0,0 +1,305 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package htmlutil contains helper functions for working with rendered HTML code.
+package htmlutil
+
+import (
+	"strings"
+)
+
+// rawTextElements are elements whose content must not be treated like regular HTML text.
+var rawTextElements = []string{"pre", "textarea", "script", "style"}
+
+// Minify removes comments and redundant whitespace from the given HTML code.
+// The content of pre-, textarea- and script-elements is left untouched,
+// inlined style-sheets are minified conservatively.
+func Minify(html string) string {
+
+	output := &strings.Builder{}
+	output.Grow(len(html))
+
+	insideTag := false
+	quote := byte(0)
+	pendingWhitespace := false
+
+	for position := 0; position < len(html); {
+
+		character := html[position]
+
+		// quoted attribute values are copied as they are
+		if quote != 0 {
+			output.WriteByte(character)
+			if character == quote {
+				quote = 0
+			}
+
+			position++
+			continue
+		}
+
+		// whitespace is collapsed into a single space
+		if isWhitespace(character) {
+			pendingWhitespace = true
+			position++
+			continue
+		}
+
+		if pendingWhitespace {
+			if output.Len() > 0 && !(insideTag && character == '>') {
+				output.WriteByte(' ')
+			}
+
+			pendingWhitespace = false
+		}
+
+		if insideTag {
+			output.WriteByte(character)
+
+			switch character {
+			case '"', '\'':
+				quote = character
+
+			case '>':
+				insideTag = false
+			}
+
+			position++
+			continue
+		}
+
+		// a "<" which doesn't start a tag is regular text (e.g. "a < b")
+		if character != '<' || !isTagStart(html[position:]) {
+			output.WriteByte(character)
+			position++
+			continue
+		}
+
+		// comments (conditional comments are kept)
+		if strings.HasPrefix(html[position:], "<!--") {
+			end := strings.Index(html[position+4:], "-->")
+			if end == -1 {
+				end = len(html)
+			} else {
+				end = position + 4 + end + 3
+			}
+
+			if strings.HasPrefix(html[position:], "<!--[if") {
+				output.WriteString(html[position:end])
+			}
+
+			position = end
+			continue
+		}
+
+		// raw text elements
+		if elementName, isRawTextElement := getRawTextElementName(html[position:]); isRawTextElement {
+			openingTagEnd := indexOfTagEnd(html, position)
+			closingTag := "</" + elementName
+			contentEnd := indexIgnoreCase(html[openingTagEnd:], closingTag)
+			if contentEnd == -1 {
+				contentEnd = len(html)
+			} else {
+				contentEnd = openingTagEnd + contentEnd
+			}
+
+			output.WriteString(collapseWhitespace(html[position:openingTagEnd]))
+
+			content := html[openingTagEnd:contentEnd]
+			if elementName == "style" {
+				output.WriteString(minifyStyle(content))
+			} else {
+				output.WriteString(content)
+			}
+
+			position = contentEnd
+			if position < len(html) {
+				insideTag = true
+				output.WriteString(html[position : position+len(closingTag)])
+				position += len(closingTag)
+			}
+
+			continue
+		}
+
+		insideTag = true
+		output.WriteByte(character)
+		position++
+	}
+
+	return output.String()
+}
+
+// minifyStyle removes comments and redundant whitespace from the given style-sheet code.
+// Strings and url() values are copied as they are.
+func minifyStyle(code string) string {
+
+	output := &strings.Builder{}
+	output.Grow(len(code))
+
+	previous := byte(0)
+	pendingWhitespace := false
+
+	for position := 0; position < len(code); {
+
+		character := code[position]
+
+		// comments
+		if strings.HasPrefix(code[position:], "/*") {
+			end := strings.Index(code[position+2:], "*/")
+			if end == -1 {
+				position = len(code)
+			} else {
+				position += 2 + end + 2
+			}
+
+			continue
+		}
+
+		// whitespace is collapsed into a single space, or removed next to separators
+		if isWhitespace(character) {
+			pendingWhitespace = true
+			position++
+			continue
+		}
+
+		if pendingWhitespace {
+			if output.Len() > 0 && !isCSSSeparator(previous) && !isCSSSeparator(character) {
+				output.WriteByte(' ')
+			}
+
+			pendingWhitespace = false
+		}
+
+		end := position + 1
+		switch {
+		case character == '"' || character == '\'':
+			end = indexOfCSSStringEnd(code, position)
+
+		case len(code)-position >= 4 && strings.EqualFold(code[position:position+4], "url("):
+			end = indexOfCSSURLEnd(code, position+4)
+		}
+
+		output.WriteString(code[position:end])
+		previous = code[end-1]
+		position = end
+	}
+
+	return output.String()
+}
+
+// indexOfCSSStringEnd returns the position after the closing quote of the string starting at the given position.
+func indexOfCSSStringEnd(code string, start int) int {
+	quote := code[start]
+	for position := start + 1; position < len(code); position++ {
+		switch code[position] {
+		case '\\':
+			position++
+
+		case quote:
+			return position + 1
+		}
+	}
+
+	return len(code)
+}
+
+// indexOfCSSURLEnd returns the position after the closing parenthesis of the url() value starting at the given position.
+func indexOfCSSURLEnd(code string, start int) int {
+	for position := start; position < len(code); position++ {
+		switch code[position] {
+		case '"', '\'':
+			position = indexOfCSSStringEnd(code, position) - 1
+
+		case '\\':
+			position++
+
+		case ')':
+			return position + 1
+		}
+	}
+
+	return len(code)
+}
+
+// isCSSSeparator checks if whitespace next to the given character is redundant.
+func isCSSSeparator(character byte) bool {
+	switch character {
+	case '{', '}', ';', ',':
+		return true
+	}
+
+	return false
+}
+
+// isTagStart checks if the given code starts with a tag, a comment or a declaration
+// (a "<" followed by a letter, "/", "!" or "?").
+func isTagStart(code string) bool {
+	if len(code) < 2 || code[0] != '<' {
+		return false
+	}
+
+	next := code[1]
+	return next == '/' || next == '!' || next == '?' || (next >= 'a' && next <= 'z') || (next >= 'A' && next <= 'Z')
+}
+
+// getRawTextElementName checks if the given code starts with the opening tag of a raw text element.
+func getRawTextElementName(code string) (elementName string, isRawTextElement bool) {
+	for _, name := range rawTextElements {
+		tagStart := "<" + name
+		if len(code) <= len(tagStart) || !strings.EqualFold(code[:len(tagStart)], tagStart) {
+			continue
+		}
+
+		if next := code[len(tagStart)]; next == '>' || next == '/' || isWhitespace(next) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// indexOfTagEnd returns the position after the closing bracket of the tag starting at the given position.
+func indexOfTagEnd(html string, start int) int {
+	quote := byte(0)
+	for position := start; position < len(html); position++ {
+		character := html[position]
+
+		if quote != 0 {
+			if character == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch character {
+		case '"', '\'':
+			quote = character
+
+		case '>':
+			return position + 1
+		}
+	}
+
+	return len(html)
+}
+
+// indexIgnoreCase returns the index of the first case-insensitive occurance of substring in text.
+func indexIgnoreCase(text, substring string) int {
+	return strings.Index(strings.ToLower(text), strings.ToLower(substring))
+}
+
+// collapseWhitespace replaces all whitespace sequences in the given text with a single space.
+func collapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func isWhitespace(character byte) bool {
+	switch character {
+	case ' ', '\t', '\n', '\r', '\f':
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,159 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package htmlutil
+
+import (
+	"testing"
+)
+
+func Test_Minify_WhitespaceBetweenElements_WhitespaceIsCollapsed(t *testing.T) {
+
+	// arrange
+	input := "<ul>\n\t\t<li>One</li>\n\t\t<li>Two</li>\n</ul>\n"
+	expectedResult := "<ul> <li>One</li> <li>Two</li> </ul>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_Comments_CommentsAreRemoved(t *testing.T) {
+
+	// arrange
+	input := "<p>Text</p><!-- a comment --><!--[if lt IE 9]><script src=\"html5.js\"></script><![endif]-->"
+	expectedResult := "<p>Text</p><!--[if lt IE 9]><script src=\"html5.js\"></script><![endif]-->"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_QuotedAttributeValues_ValuesAreNotChanged(t *testing.T) {
+
+	// arrange
+	input := "<img   alt=\"A  picture\n of something\"   src='a.png' >"
+	expectedResult := "<img alt=\"A  picture\n of something\" src='a.png'>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_PreformattedText_ContentIsNotChanged(t *testing.T) {
+
+	// arrange
+	input := "<div>\n  <pre class=\"code\">func main() {\n\n    fmt.Println(\"a  b\")\n}</pre>\n</div>"
+	expectedResult := "<div> <pre class=\"code\">func main() {\n\n    fmt.Println(\"a  b\")\n}</pre> </div>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_InlineStyle_StyleIsMinified(t *testing.T) {
+
+	// arrange
+	input := "<style>\n  /* header */\n  h1 , h2 {\n    color: red ;\n  }\n</style>"
+	expectedResult := "<style>h1,h2{color: red;}</style>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_InlineStyleWithStrings_StringsAreNotChanged(t *testing.T) {
+
+	// arrange
+	input := "<style>\n  a::after { content: \" : \" ; }\n  p { font-family: \"A  B\" , serif; }\n  q::before { content: '/* x */ ; \\' {  }'; }\n</style>"
+	expectedResult := "<style>a::after{content: \" : \";}p{font-family: \"A  B\",serif;}q::before{content: '/* x */ ; \\' {  }';}</style>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_InlineStyleWithURLs_URLsAreNotChanged(t *testing.T) {
+
+	// arrange
+	input := "<style>\n  body { background: url(images/a  b.png) , url( \"c ; d.png\" ) ; }\n</style>"
+	expectedResult := "<style>body{background: url(images/a  b.png),url( \"c ; d.png\" );}</style>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_InlineScript_ScriptIsNotChanged(t *testing.T) {
+
+	// arrange
+	input := "<script type=\"text/javascript\">\n    var a = 1\n\n    var b = \"<b>\"\n</script>"
+	expectedResult := input
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_ScriptWithTemplateLiteral_TemplateLiteralIsNotChanged(t *testing.T) {
+
+	// arrange
+	input := "<script>\nvar html = `\n    <ul>\n\n        <li>${name}</li>\n    </ul>`;\n</script>"
+	expectedResult := input
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
+
+func Test_Minify_LessThanSignInText_TextIsNotTreatedAsTag(t *testing.T) {
+
+	// arrange
+	input := "<p>a < b and  c <= d</p>\n<p>\"quoted\"  text</p>"
+	expectedResult := "<p>a < b and c <= d</p> <p>\"quoted\" text</p>"
+
+	// act
+	result := Minify(input)
+
+	// assert
+	if result != expectedResult {
+		t.Errorf("Minify(%q) should return %q but returned %q.", input, expectedResult, result)
+	}
+}
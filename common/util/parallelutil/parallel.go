@@ -0,0 +1,50 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package parallelutil runs independent actions on a bounded number of goroutines.
+package parallelutil
+
+import (
+	"sync"
+)
+
+// ForEach calls the given action for every index from 0 to numberOfItems-1 using at most numberOfWorkers
+// goroutines and returns when all actions have finished. The actions are started in the order of the
+// indizes; callers which need ordered results should store them by index.
+func ForEach(numberOfItems, numberOfWorkers int, action func(index int)) {
+
+	if numberOfWorkers > numberOfItems {
+		numberOfWorkers = numberOfItems
+	}
+
+	// run sequentially if there is nothing to parallelize
+	if numberOfWorkers <= 1 {
+		for index := 0; index < numberOfItems; index++ {
+			action(index)
+		}
+
+		return
+	}
+
+	indizes := make(chan int)
+
+	var workers sync.WaitGroup
+	workers.Add(numberOfWorkers)
+	for worker := 0; worker < numberOfWorkers; worker++ {
+		go func() {
+			defer workers.Done()
+
+			for index := range indizes {
+				action(index)
+			}
+		}()
+	}
+
+	for index := 0; index < numberOfItems; index++ {
+		indizes <- index
+	}
+
+	close(indizes)
+	workers.Wait()
+}
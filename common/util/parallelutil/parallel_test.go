@@ -0,0 +1,50 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parallelutil
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func Test_ForEach_SeveralWorkers_ResultsAreStoredInOrder(t *testing.T) {
+	// arrange
+	results := make([]int, 100)
+
+	// act
+	ForEach(len(results), 8, func(index int) {
+		results[index] = index * index
+	})
+
+	// assert
+	for index, result := range results {
+		if result != index*index {
+			t.Fatalf("The result at index %d should be %d but was %d.", index, index*index, result)
+		}
+	}
+}
+
+func Test_ForEach_WorkerLimit_NoMoreActionsRunAtTheSameTime(t *testing.T) {
+	// arrange
+	var running, maxRunning int32
+
+	// act
+	ForEach(50, 3, func(index int) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+
+		atomic.AddInt32(&running, -1)
+	})
+
+	// assert
+	if maxRunning > 3 {
+		t.Errorf("At most 3 actions should run at the same time but %d did.", maxRunning)
+	}
+}
@@ -5,6 +5,7 @@
 package filesystem
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
@@ -48,6 +49,11 @@ func (provider *fileProvider) GetFilesFromDirectory(itemDirectory, filesDirector
 
 	for _, directoryEntry := range filesDirectoryEntries {
 
+		// skip the folder settings
+		if directoryEntry.Name() == config.FolderSettingsFileName {
+			continue
+		}
+
 		filePath := filepath.Join(filesDirectory, directoryEntry.Name())
 
 		// recurse if the path is a directory
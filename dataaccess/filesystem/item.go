@@ -5,10 +5,12 @@
 package filesystem
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/content"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/common/util/hashutil"
 	"github.com/andreaskoch/allmark/dataaccess"
+	"encoding/json"
 	"fmt"
 )
 
@@ -18,9 +20,10 @@ func newPhysicalItem(route route.Route,
 	files func() []dataaccess.File,
 	children func() []dataaccess.Item,
 	directory string,
+	folderSettings func() config.FolderSettings,
 	watcherPaths []watcherPather) dataaccess.Item {
 
-	return newItem(dataaccess.TypePhysical, route, contentProvider, files, children, directory, watcherPaths)
+	return newItem(dataaccess.TypePhysical, route, contentProvider, files, children, directory, folderSettings, watcherPaths)
 
 }
 
@@ -30,9 +33,10 @@ func newVirtualItem(route route.Route,
 	files func() []dataaccess.File,
 	children func() []dataaccess.Item,
 	directory string,
+	folderSettings func() config.FolderSettings,
 	watcherPaths []watcherPather) dataaccess.Item {
 
-	return newItem(dataaccess.TypeVirtual, route, contentProvider, files, children, directory, watcherPaths)
+	return newItem(dataaccess.TypeVirtual, route, contentProvider, files, children, directory, folderSettings, watcherPaths)
 
 }
 
@@ -41,9 +45,10 @@ func newFileCollectionItem(route route.Route,
 	contentProvider *content.ContentProvider,
 	files func() []dataaccess.File,
 	directory string,
+	folderSettings func() config.FolderSettings,
 	watcherPaths []watcherPather) dataaccess.Item {
 
-	return newItem(dataaccess.TypeFileCollection, route, contentProvider, files, nil, directory, watcherPaths)
+	return newItem(dataaccess.TypeFileCollection, route, contentProvider, files, nil, directory, folderSettings, watcherPaths)
 
 }
 
@@ -54,18 +59,22 @@ func newItem(itemType dataaccess.ItemType,
 	files func() []dataaccess.File,
 	children func() []dataaccess.Item,
 	directory string,
+	folderSettings func() config.FolderSettings,
 	watcherPaths []watcherPather) dataaccess.Item {
 
 	return &Item{
-		contentProvider,
-		itemType,
-		route,
-		files,
-		children,
+		ContentProvider: contentProvider,
 
-		directory,
+		itemType:     itemType,
+		route:        route,
+		filesFunc:    files,
+		childrenFunc: children,
 
-		watcherPaths,
+		directory:          directory,
+		folderSettingsFunc: folderSettings,
+		lastSettingsHash:   getFolderSettingsHash(folderSettings),
+
+		watcherPaths: watcherPaths,
 	}
 
 }
@@ -81,6 +90,10 @@ type Item struct {
 
 	directory string
 
+	// folderSettingsFunc returns the merged settings of the folder of the item and its parent folders
+	folderSettingsFunc func() config.FolderSettings
+	lastSettingsHash   string
+
 	watcherPaths []watcherPather
 }
 
@@ -135,6 +148,52 @@ func (item *Item) Directory() string {
 	return item.directory
 }
 
+// FolderSettings returns the settings which the folder of the item and its parent folders override.
+func (item *Item) FolderSettings() config.FolderSettings {
+	if item.folderSettingsFunc == nil {
+		return config.FolderSettings{}
+	}
+
+	return item.folderSettingsFunc()
+}
+
+// Hash returns the hash of the content of the item and of its folder settings, so that changes
+// of the folder settings are detected like changes of the content.
+func (item *Item) Hash() (string, error) {
+	hash, err := item.ContentProvider.Hash()
+	if err != nil {
+		return hash, err
+	}
+
+	item.lastSettingsHash = getFolderSettingsHash(item.folderSettingsFunc)
+	return hash + item.lastSettingsHash, nil
+}
+
+// LastHash returns the hash which was determined the last time.
+func (item *Item) LastHash() string {
+	return item.ContentProvider.LastHash() + item.lastSettingsHash
+}
+
 func (item *Item) WatcherPaths() []watcherPather {
 	return item.watcherPaths
 }
+
+// getFolderSettingsHash returns the hash of the given folder settings; or an empty string if the folder
+// settings don't override any setting.
+func getFolderSettingsHash(folderSettings func() config.FolderSettings) string {
+	if folderSettings == nil {
+		return ""
+	}
+
+	settings := folderSettings()
+	if settings.IsEmpty() {
+		return ""
+	}
+
+	serializedSettings, err := json.Marshal(settings)
+	if err != nil {
+		return ""
+	}
+
+	return hashutil.FromString(string(serializedSettings))
+}
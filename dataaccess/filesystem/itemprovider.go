@@ -127,6 +127,7 @@ func (itemProvider *itemProvider) newItemFromFile(itemDirectory, filePath string
 		files,
 		children,
 		itemDirectory,
+		itemProvider.folderSettings(itemDirectory),
 		[]watcherPather{
 			watcherFilePath{filePath},
 			watcherDirectoryPath{itemDirectory, false},
@@ -167,6 +168,7 @@ func (itemProvider *itemProvider) newVirtualItem(itemDirectory string) (dataacce
 		files,
 		children,
 		itemDirectory,
+		itemProvider.folderSettings(itemDirectory),
 		[]watcherPather{
 			watcherDirectoryPath{itemDirectory, false},
 		})
@@ -201,6 +203,7 @@ files: [Attachments](/)`, title)
 		contentProvider,
 		files,
 		itemDirectory,
+		itemProvider.folderSettings(itemDirectory),
 		[]watcherPather{
 			watcherDirectoryPath{itemDirectory, true},
 		},
@@ -209,6 +212,25 @@ files: [Attachments](/)`, title)
 	return item, nil
 }
 
+// folderSettings returns a function which reads the settings of the given item directory merged
+// with the settings of all its parent folders in the repository.
+func (itemProvider *itemProvider) folderSettings(itemDirectory string) func() config.FolderSettings {
+	return func() config.FolderSettings {
+		var settings config.FolderSettings
+		for _, folder := range getFolderHierarchy(itemProvider.repositoryPath, itemDirectory) {
+			folderSettings, err := config.ReadFolderSettings(folder)
+			if err != nil {
+				itemProvider.logger.Warn("%s", err.Error())
+				continue
+			}
+
+			settings = settings.Merge(folderSettings)
+		}
+
+		return settings
+	}
+}
+
 // GetRouteFromDirectory creates a route from the given directory path.
 func (itemProvider *itemProvider) GetRouteFromDirectory(directory string) route.Route {
 	return route.NewFromItemDirectory(itemProvider.repositoryPath, directory)
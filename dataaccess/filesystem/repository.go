@@ -8,15 +8,24 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/metrics"
+	"github.com/andreaskoch/allmark/common/progress"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/common/util/parallelutil"
 	"github.com/andreaskoch/allmark/dataaccess"
 )
 
+var indexDuration = metrics.NewHistogram(
+	"allmark_repository_index_duration_seconds",
+	"Duration of the repository (re-)indexing runs in seconds.",
+	[]float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300})
+
 type Repository struct {
 	logger    logger.Logger
 	directory string
@@ -25,15 +34,29 @@ type Repository struct {
 
 	index *Index
 
+	// workers is the number of items which are created and hashed at the same time
+	workers int
+
 	// Update Subscription
 	watcher           *filesystemWatcher
 	updateSubscribers []chan dataaccess.Update
 
+	// Indexing Subscription
+	indexingSubscribers []chan dataaccess.IndexingResult
+
 	// live reload
 	livereloadIsEnabled bool
+
+	// writeLock serializes the write operations
+	writeLock sync.Mutex
+
+	// authenticationRules contains the rules of the folders which define who can access their items
+	authenticationRules     []config.AuthenticationRule
+	authenticationRulesLock sync.RWMutex
 }
 
 func NewRepository(logger logger.Logger, directory string, config config.Config) (*Repository, error) {
+	logger = logger.Module("repository")
 
 	// check if path exists
 	if !fsutil.PathExists(directory) {
@@ -66,7 +89,8 @@ func NewRepository(logger logger.Logger, directory string, config config.Config)
 		itemProvider: itemProvider,
 
 		// Indizes
-		index: newIndex(),
+		index:   newIndex(),
+		workers: config.Indexing.NumberOfWorkers(),
 
 		// Update Subscription
 		watcher:           newFilesystemWatcher(logger),
@@ -124,10 +148,25 @@ func (repository *Repository) Routes() []route.Route {
 
 // Subscribe registers the supplied updates channel in the repository.
 // All updates (new, modified or deleted items) in the repository will be passed down this channel.
+// AuthenticationRules returns the authentication rules of all folders whose folder settings
+// define who can access their items.
+func (repository *Repository) AuthenticationRules() []config.AuthenticationRule {
+	repository.authenticationRulesLock.RLock()
+	defer repository.authenticationRulesLock.RUnlock()
+
+	return repository.authenticationRules
+}
+
 func (repository *Repository) Subscribe(updates chan dataaccess.Update) {
 	repository.updateSubscribers = append(repository.updateSubscribers, updates)
 }
 
+// SubscribeToIndexing registers the supplied channel in the repository.
+// The result of every scheduled reindex of the whole repository will be passed down this channel.
+func (repository *Repository) SubscribeToIndexing(results chan dataaccess.IndexingResult) {
+	repository.indexingSubscribers = append(repository.indexingSubscribers, results)
+}
+
 // StartWatching starts the watcher for the item with the given route.
 func (repository *Repository) StartWatching(route route.Route) {
 
@@ -163,7 +202,8 @@ func (repository *Repository) StartWatching(route route.Route) {
 				oldIndex := repository.index
 				limitDepth := true
 				maxDepth := 2
-				repository.updateIndex(oldIndex, itemRoute, itemDirectory, limitDepth, maxDepth)
+				reportProgress := false
+				repository.updateIndex(oldIndex, itemRoute, itemDirectory, limitDepth, maxDepth, reportProgress)
 
 			}
 		}
@@ -178,6 +218,8 @@ func (repository *Repository) StopWatching(route route.Route) {
 // Initialize the repository - scan all folders and update the index.
 func (repository *Repository) init() {
 
+	startTime := time.Now()
+
 	var oldIndex *Index
 	if repository.index != nil {
 		repository.logger.Debug("Re-initializing the repository index.")
@@ -190,19 +232,35 @@ func (repository *Repository) init() {
 	limitDepth := false // we want to index all items
 	maxDepth := 0
 
-	repository.updateIndex(oldIndex, route.New(), repository.directory, limitDepth, maxDepth)
+	reportProgress := true
+	update := repository.updateIndex(oldIndex, route.New(), repository.directory, limitDepth, maxDepth, reportProgress)
+
+	duration := time.Since(startTime)
+	indexDuration.Observe(duration.Seconds())
+
+	// notify the indexing subscribers
+	result := dataaccess.IndexingResult{
+		Update:        update,
+		NumberOfItems: len(repository.index.GetAllItems()),
+		Duration:      duration,
+	}
+
+	for _, indexingSubscriber := range repository.indexingSubscribers {
+		indexingSubscriber <- result
+	}
 }
 
 // createIndexFromDirectory scans the supplied directory and creates an index from it.
 // If limitMaxDepth is set to true maxDepth defines the max depth of the scan and of the resulting index.
-func (repository *Repository) createIndexFromDirectory(directory string, limitMaxDepth bool, maxDepth int) *Index {
+// Every item which is found is recorded in the given progress (which can be nil).
+func (repository *Repository) createIndexFromDirectory(directory string, limitMaxDepth bool, maxDepth int, scanProgress *progress.Task) *Index {
 
 	repository.logger.Debug("Scanning directory %q", directory)
 
 	index := newIndex()
 
 	// update the cloned index
-	for _, newItem := range repository.getItemsFromDirectory(directory, limitMaxDepth, maxDepth) {
+	for _, newItem := range repository.getItemsFromDirectory(directory, limitMaxDepth, maxDepth, scanProgress) {
 
 		if _, err := index.Add(newItem); err != nil {
 			repository.logger.Error("Cannot add item %q to index: Error: %s", newItem.String, err.Error())
@@ -215,45 +273,74 @@ func (repository *Repository) createIndexFromDirectory(directory string, limitMa
 
 // getItemsFromDirectory scan the supplied directory for items and returns the list if items found.
 // If limitMaxDepth is set to true maxDepth defines the max depth of the scan.
-func (repository *Repository) getItemsFromDirectory(itemDirectory string, limitDepth bool, maxDepth int) (items []dataaccess.Item) {
+// The items of every level of the tree are created concurrently; the list is in the order of a
+// depth-first scan (every item is followed by its descendants).
+func (repository *Repository) getItemsFromDirectory(itemDirectory string, limitDepth bool, maxDepth int, scanProgress *progress.Task) (items []dataaccess.Item) {
 
-	items = make([]dataaccess.Item, 0)
+	root := &itemNode{directory: itemDirectory}
 
-	// create the item
-	item, err := repository.itemProvider.GetItemFromDirectory(itemDirectory)
-	if err != nil {
-		repository.logger.Error("Could not create an item from folder %q. Error: %s", itemDirectory, err.Error())
-		return
-	}
+	level := []*itemNode{root}
+	for depth := 0; len(level) > 0; depth++ {
 
-	// append the item
-	items = append(items, item)
+		// the children of this level are only scanned if the max depth has not been reached yet
+		scanChildren := !limitDepth || depth < maxDepth
 
-	// abort if the item cannot have children
-	if !item.CanHaveChildren() {
-		return
-	}
+		parallelutil.ForEach(len(level), repository.workers, func(index int) {
+			node := level[index]
+
+			// create the item
+			item, err := repository.itemProvider.GetItemFromDirectory(node.directory)
+			if err != nil {
+				repository.logger.Error("Could not create an item from folder %q. Error: %s", node.directory, err.Error())
+				return
+			}
+
+			node.item = item
+			scanProgress.Increment()
 
-	if limitDepth {
+			// abort if the item cannot have children
+			if !scanChildren || !item.CanHaveChildren() {
+				return
+			}
 
-		// abort if the max depth level has been reached
-		if maxDepth == 0 {
-			return items
+			for _, childItemDirectory := range getChildDirectories(node.directory) {
+				node.children = append(node.children, &itemNode{directory: childItemDirectory})
+			}
+		})
+
+		// continue with the children of all items of this level
+		var nextLevel []*itemNode
+		for _, node := range level {
+			nextLevel = append(nextLevel, node.children...)
 		}
 
-		// count down the max depth
-		maxDepth = maxDepth - 1
+		level = nextLevel
+	}
 
+	return root.items()
+}
+
+// itemNode is an item directory and its child directories which are scanned for the index.
+type itemNode struct {
+	directory string
+	item      dataaccess.Item
+	children  []*itemNode
+}
+
+// items returns the item of the node followed by the items of all its descendants;
+// directories from which no item could be created are skipped together with their descendants.
+func (node *itemNode) items() []dataaccess.Item {
+	items := make([]dataaccess.Item, 0)
+	if node.item == nil {
+		return items
 	}
 
-	// recurse for child items
-	childItemDirectories := getChildDirectories(itemDirectory)
-	for _, childItemDirectory := range childItemDirectories {
-		childItems := repository.getItemsFromDirectory(childItemDirectory, limitDepth, maxDepth)
-		items = append(items, childItems...)
+	items = append(items, node.item)
+	for _, child := range node.children {
+		items = append(items, child.items()...)
 	}
 
-	return
+	return items
 }
 
 // reindex starts the scheduled reindexing process.
@@ -295,21 +382,28 @@ func (repository *Repository) sendUpdate(update dataaccess.Update) {
 }
 
 // updateIndex takes the supplied oldIndex and an updates it with items it found in the specified directory.
-// If limitMaxDepth is set to true maxDepth defines the max depth of the scan.
-func (repository *Repository) updateIndex(oldIndex *Index, itemRoute route.Route, itemDirectory string, limitDepth bool, maxDepth int) {
+// If limitMaxDepth is set to true maxDepth defines the max depth of the scan. If reportProgress is set to true the
+// progress of the scan and of the hashing is reported. It returns the update that was sent to the subscribers.
+func (repository *Repository) updateIndex(oldIndex *Index, itemRoute route.Route, itemDirectory string, limitDepth bool, maxDepth int, reportProgress bool) dataaccess.Update {
 
 	// get the old sub index
 	subIndexOld := oldIndex.GetSubIndex(itemRoute, limitDepth, maxDepth)
 
 	// get the new sub index
-	subIndexNew := repository.createIndexFromDirectory(itemDirectory, limitDepth, maxDepth)
+	var scanProgress *progress.Task
+	if reportProgress {
+		scanProgress = progress.Start(repository.logger, "Repository scan", "items", 0)
+	}
+
+	subIndexNew := repository.createIndexFromDirectory(itemDirectory, limitDepth, maxDepth, scanProgress)
+	scanProgress.Finish()
 
 	repository.logger.Debug("------- Sub Indexes for %q ---------------", itemDirectory)
 	repository.logger.Debug("Sub index (old):\n%s", subIndexOld.String())
 	repository.logger.Debug("Sub index (new):\n%s", subIndexNew.String())
 
 	// determine the diff between the old and new sub indexes
-	newItems, modifiedItems, deletedItems := repository.diffIndexes(subIndexOld, subIndexNew)
+	newItems, modifiedItems, deletedItems := repository.diffIndexes(subIndexOld, subIndexNew, reportProgress)
 
 	repository.logger.Debug("------- Difference ---------------")
 	repository.logger.Debug("New: %v", len(newItems))
@@ -335,17 +429,69 @@ func (repository *Repository) updateIndex(oldIndex *Index, itemRoute route.Route
 
 	// assign the new index
 	repository.index = newIndex
+	repository.updateAuthenticationRules()
 
 	// send out updates
 	changedItems := dataaccess.NewUpdate(itemsToRoutes(newItems), itemsToRoutes(modifiedItems), itemsToRoutes(deletedItems))
+	if !limitDepth {
+		// a scan without depth limit covers the whole repository
+		changedItems = dataaccess.NewIndexUpdate(itemsToRoutes(newItems), itemsToRoutes(modifiedItems), itemsToRoutes(deletedItems))
+	}
 	repository.sendUpdate(changedItems)
+
+	return changedItems
+}
+
+// updateAuthenticationRules collects the authentication rules from the folder settings of all items in the index.
+func (repository *Repository) updateAuthenticationRules() {
+	var rules []config.AuthenticationRule
+	for _, indexItem := range repository.index.GetAllItems() {
+		item, isFilesystemItem := indexItem.(*Item)
+		if !isFilesystemItem {
+			continue
+		}
+
+		// only the folder which defines the authentication needs a rule; the rule covers all sub-folders
+		folderSettings, err := config.ReadFolderSettings(item.Directory())
+		if err != nil || folderSettings.Authentication == nil {
+			continue
+		}
+
+		rules = append(rules, folderSettings.Authentication.Rule(item.Route().Value()))
+	}
+
+	repository.authenticationRulesLock.Lock()
+	defer repository.authenticationRulesLock.Unlock()
+
+	repository.authenticationRules = rules
 }
 
 // diffIndexes calculates the differences between the specified old and new indexes.
-func (repository *Repository) diffIndexes(oldIndex, newIndex *Index) (newItems, modifiedItems, deletedItems []dataaccess.Item) {
+// If reportProgress is set to true the progress of the hashing is reported.
+func (repository *Repository) diffIndexes(oldIndex, newIndex *Index, reportProgress bool) (newItems, modifiedItems, deletedItems []dataaccess.Item) {
+
+	// determine the hashes of the items which exist in both indizes concurrently
+	newIndexItems := newIndex.GetAllItems()
+
+	var hashProgress *progress.Task
+	if reportProgress && oldIndex.Size() > 0 {
+		hashProgress = progress.Start(repository.logger, "Item hashing", "items", len(newIndexItems))
+	}
+
+	newItemHashes := make([]string, len(newIndexItems))
+	newItemHashErrors := make([]error, len(newIndexItems))
+	parallelutil.ForEach(len(newIndexItems), repository.workers, func(index int) {
+		defer hashProgress.Increment()
+
+		if _, existsInOldIndex := oldIndex.IsMatch(newIndexItems[index].Route()); existsInOldIndex {
+			newItemHashes[index], newItemHashErrors[index] = newIndexItems[index].Hash()
+		}
+	})
+
+	hashProgress.Finish()
 
 	// new or modified
-	for _, newItem := range newIndex.GetAllItems() {
+	for index, newItem := range newIndexItems {
 
 		oldItem, existsInOldIndex := oldIndex.IsMatch(newItem.Route())
 		if !existsInOldIndex {
@@ -358,8 +504,7 @@ func (repository *Repository) diffIndexes(oldIndex, newIndex *Index) (newItems,
 		}
 
 		// check if it has changed
-		// determine the hash of the new item
-		newItemHash, err := newItem.Hash()
+		newItemHash, err := newItemHashes[index], newItemHashErrors[index]
 		if err != nil {
 			repository.logger.Error("Skipping item %q because the hash of the new item cannot be determined", newItem.Route())
 			continue
@@ -117,6 +117,28 @@ func getChildDirectories(directory string) []string {
 	return directories
 }
 
+// getFolderHierarchy returns the given directory and all its parent directories up to the repository
+// directory, starting with the repository directory.
+func getFolderHierarchy(repositoryPath, directory string) []string {
+	relativePath, err := filepath.Rel(repositoryPath, directory)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return []string{directory}
+	}
+
+	folders := []string{repositoryPath}
+	if relativePath == "." {
+		return folders
+	}
+
+	folder := repositoryPath
+	for _, component := range strings.Split(relativePath, string(filepath.Separator)) {
+		folder = filepath.Join(folder, component)
+		folders = append(folders, folder)
+	}
+
+	return folders
+}
+
 func isMarkdownFile(fileNameOrPath string) bool {
 	fileExtension := strings.ToLower(filepath.Ext(fileNameOrPath))
 	switch fileExtension {
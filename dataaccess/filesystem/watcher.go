@@ -54,7 +54,7 @@ func (w watcherDirectoryPath) Recurse() bool {
 
 func newFilesystemWatcher(logger logger.Logger) *filesystemWatcher {
 	return &filesystemWatcher{
-		logger:   logger,
+		logger:   logger.Module("watcher"),
 		watchers: make(map[string][]fswatch.Watcher),
 	}
 }
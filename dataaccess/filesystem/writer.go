@@ -0,0 +1,336 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filesystem
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/content"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/dataaccess"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// newItemMarkdownFileName is the name of the markdown file of items which are created
+// by the repository (e.g. "documents/Sample-Document/document.md").
+const newItemMarkdownFileName = "document.md"
+
+// SaveItem creates or updates the markdown document of the item with the given route.
+// The markdown document of virtual items is created, which turns them into physical items.
+func (repository *Repository) SaveItem(itemRoute route.Route, markdown []byte, precondition dataaccess.Precondition) (dataaccess.Item, bool, error) {
+
+	repository.writeLock.Lock()
+	defer repository.writeLock.Unlock()
+
+	// update an existing item
+	if item := repository.Item(itemRoute); item != nil {
+		if err := checkPrecondition(precondition, item); err != nil {
+			return nil, false, err
+		}
+
+		if item.Type() == dataaccess.TypeFileCollection {
+			return nil, false, fmt.Errorf("%w: The item %q is a file collection and cannot have a markdown document.", dataaccess.ErrConflict, itemRoute.Value())
+		}
+
+		itemDirectory := item.(*Item).Directory()
+
+		markdownFilePath := filepath.Join(itemDirectory, newItemMarkdownFileName)
+		if found, existingFilePath := findMarkdownFileInDirectory(itemDirectory); found {
+			markdownFilePath = existingFilePath
+		}
+
+		if err := writeFile(markdownFilePath, bytes.NewReader(markdown)); err != nil {
+			return nil, false, err
+		}
+
+		repository.refreshIndex(itemRoute, itemDirectory)
+		return repository.Item(itemRoute), false, nil
+	}
+
+	// create a new item
+	if !precondition.IsMet(false, "") {
+		return nil, false, fmt.Errorf("%w: The item %q does not exist.", dataaccess.ErrPreconditionFailed, itemRoute.Value())
+	}
+
+	parentRoute, exists := itemRoute.Parent()
+	if !exists {
+		return nil, false, fmt.Errorf("%w: The repository root cannot be created.", dataaccess.ErrConflict)
+	}
+
+	parent := repository.Item(parentRoute)
+	if parent == nil {
+		return nil, false, fmt.Errorf("%w: The parent %q of the item %q does not exist.", dataaccess.ErrConflict, parentRoute.Value(), itemRoute.Value())
+	}
+
+	if !parent.CanHaveChildren() {
+		return nil, false, fmt.Errorf("%w: The parent %q of the item %q cannot have children.", dataaccess.ErrConflict, parentRoute.Value(), itemRoute.Value())
+	}
+
+	parentDirectory := parent.(*Item).Directory()
+	itemDirectory, err := getChildPath(parentDirectory, itemRoute.LastComponentName())
+	if err != nil {
+		return nil, false, err
+	}
+
+	// make sure the directory results in the requested route
+	if directoryRoute := repository.itemProvider.GetRouteFromDirectory(itemDirectory); directoryRoute.Value() != itemRoute.Value() {
+		return nil, false, fmt.Errorf("%w: The route %q cannot be used for an item.", dataaccess.ErrInvalidPath, itemRoute.OriginalValue())
+	}
+
+	if err := os.MkdirAll(itemDirectory, 0755); err != nil {
+		return nil, false, err
+	}
+
+	if err := writeFile(filepath.Join(itemDirectory, newItemMarkdownFileName), bytes.NewReader(markdown)); err != nil {
+		return nil, false, err
+	}
+
+	repository.refreshIndex(parentRoute, parentDirectory)
+
+	item := repository.Item(itemRoute)
+	if item == nil {
+		return nil, false, fmt.Errorf("The item %q was created but could not be indexed.", itemRoute.Value())
+	}
+
+	return item, true, nil
+}
+
+// DeleteItem deletes the directory of the item with the given route.
+func (repository *Repository) DeleteItem(itemRoute route.Route, precondition dataaccess.Precondition) error {
+
+	repository.writeLock.Lock()
+	defer repository.writeLock.Unlock()
+
+	item := repository.Item(itemRoute)
+	if item == nil {
+		return fmt.Errorf("%w: The item %q does not exist.", dataaccess.ErrNotFound, itemRoute.Value())
+	}
+
+	if err := checkPrecondition(precondition, item); err != nil {
+		return err
+	}
+
+	parentRoute, exists := itemRoute.Parent()
+	if !exists {
+		return fmt.Errorf("%w: The repository root cannot be deleted.", dataaccess.ErrConflict)
+	}
+
+	if children := repository.index.GetDirectChildren(itemRoute); len(children) > 0 {
+		return fmt.Errorf("%w: The item %q cannot be deleted because it has %d children.", dataaccess.ErrConflict, itemRoute.Value(), len(children))
+	}
+
+	if err := os.RemoveAll(item.(*Item).Directory()); err != nil {
+		return err
+	}
+
+	parentDirectory := filepath.Dir(item.(*Item).Directory())
+	if parent := repository.Item(parentRoute); parent != nil {
+		parentDirectory = parent.(*Item).Directory()
+	}
+
+	repository.refreshIndex(parentRoute, parentDirectory)
+	return nil
+}
+
+// SaveFile creates or updates a file in the files directory of the item with the given route.
+func (repository *Repository) SaveFile(itemRoute route.Route, filePath string, data io.Reader, precondition dataaccess.Precondition) (dataaccess.File, bool, error) {
+
+	repository.writeLock.Lock()
+	defer repository.writeLock.Unlock()
+
+	item, path, err := repository.getFilePath(itemRoute, filePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// a markdown document would turn a file collection into a physical item
+	if item.Type() == dataaccess.TypeFileCollection && filepath.Dir(path) == item.Directory() && isMarkdownFile(path) {
+		return nil, false, fmt.Errorf("%w: Markdown documents cannot be added to the file collection %q.", dataaccess.ErrConflict, itemRoute.Value())
+	}
+
+	existingFile, _ := createFileFromFilesystem(repository.directory, item.Directory(), path)
+	if err := checkPrecondition(precondition, existingFile); err != nil {
+		return nil, false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, false, err
+	}
+
+	if err := writeFile(path, data); err != nil {
+		return nil, false, err
+	}
+
+	repository.sendFileUpdate(itemRoute)
+
+	file, err := createFileFromFilesystem(repository.directory, item.Directory(), path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return file, existingFile == nil, nil
+}
+
+// DeleteFile deletes a file from the files directory of the item with the given route.
+func (repository *Repository) DeleteFile(itemRoute route.Route, filePath string, precondition dataaccess.Precondition) error {
+
+	repository.writeLock.Lock()
+	defer repository.writeLock.Unlock()
+
+	item, path, err := repository.getFilePath(itemRoute, filePath)
+	if err != nil {
+		return err
+	}
+
+	existingFile, _ := createFileFromFilesystem(repository.directory, item.Directory(), path)
+	if existingFile == nil {
+		return fmt.Errorf("%w: The file %q of item %q does not exist.", dataaccess.ErrNotFound, filePath, itemRoute.Value())
+	}
+
+	if err := checkPrecondition(precondition, existingFile); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	repository.sendFileUpdate(itemRoute)
+	return nil
+}
+
+// getFilePath returns the item with the given route and the path of its file with the given (slash-separated) path.
+func (repository *Repository) getFilePath(itemRoute route.Route, filePath string) (*Item, string, error) {
+	item := repository.Item(itemRoute)
+	if item == nil {
+		return nil, "", fmt.Errorf("%w: The item %q does not exist.", dataaccess.ErrNotFound, itemRoute.Value())
+	}
+
+	fileSystemItem := item.(*Item)
+
+	// the files of file collection items are stored in the item directory itself
+	filesDirectory := fileSystemItem.Directory()
+	if item.Type() != dataaccess.TypeFileCollection {
+		filesDirectory = filepath.Join(filesDirectory, config.FilesDirectoryName)
+	}
+
+	path := filesDirectory
+	for _, name := range strings.Split(filePath, "/") {
+		childPath, err := getChildPath(path, name)
+		if err != nil {
+			return nil, "", err
+		}
+
+		path = childPath
+	}
+
+	if isDirectory, _ := fsutil.IsDirectory(path); isDirectory {
+		return nil, "", fmt.Errorf("%w: The file %q of item %q is a directory.", dataaccess.ErrInvalidPath, filePath, itemRoute.Value())
+	}
+
+	return fileSystemItem, path, nil
+}
+
+// refreshIndex updates the index for the item with the given route and its children.
+func (repository *Repository) refreshIndex(itemRoute route.Route, itemDirectory string) {
+	limitDepth := true
+	maxDepth := 2
+	reportProgress := false
+	repository.updateIndex(repository.index, itemRoute, itemDirectory, limitDepth, maxDepth, reportProgress)
+}
+
+// sendFileUpdate notifies all subscribers that the files of the item with the given route have changed.
+// The hash of an item doesn't depend on its files, so the index doesn't detect these changes.
+func (repository *Repository) sendFileUpdate(itemRoute route.Route) {
+	repository.sendUpdate(dataaccess.NewUpdate(nil, []route.Route{itemRoute}, nil))
+}
+
+// getChildPath returns the path of the file or directory with the given name in the given directory.
+// Names which would leave the directory and names of hidden or reserved files and directories are rejected.
+func getChildPath(directory, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("%w: %q is not a valid name.", dataaccess.ErrInvalidPath, name)
+	}
+
+	childPath := filepath.Join(directory, name)
+	if filepath.Dir(childPath) != filepath.Clean(directory) {
+		return "", fmt.Errorf("%w: %q is not a valid name.", dataaccess.ErrInvalidPath, name)
+	}
+
+	for _, reservedDirectoryName := range ReservedDirectoryNames {
+		if strings.EqualFold(name, reservedDirectoryName) {
+			return "", fmt.Errorf("%w: %q is a reserved name.", dataaccess.ErrInvalidPath, name)
+		}
+	}
+
+	return childPath, nil
+}
+
+// checkPrecondition checks the given precondition against the given item or file (nil if it doesn't exist).
+func checkPrecondition(precondition dataaccess.Precondition, target content.ContentProviderInterface) error {
+
+	exists := target != nil
+
+	currentHash := ""
+	if exists {
+		hash, err := target.Hash()
+		if err != nil {
+			return err
+		}
+
+		currentHash = hash
+	}
+
+	if !precondition.IsMet(exists, currentHash) {
+		if exists {
+			return fmt.Errorf("%w: The current version has the hash %q.", dataaccess.ErrPreconditionFailed, currentHash)
+		}
+
+		return fmt.Errorf("%w: It does not exist.", dataaccess.ErrPreconditionFailed)
+	}
+
+	return nil
+}
+
+// writeFile writes the given data to a temporary file next to the given path and replaces
+// the file with it, so that the file is never read while it is only partially written.
+func writeFile(path string, data io.Reader) error {
+	temporaryFile, err := ioutil.TempFile(filepath.Dir(path), ".allmark-")
+	if err != nil {
+		return err
+	}
+
+	temporaryFilePath := temporaryFile.Name()
+
+	if _, err := io.Copy(temporaryFile, data); err != nil {
+		temporaryFile.Close()
+		os.Remove(temporaryFilePath)
+		return err
+	}
+
+	if err := temporaryFile.Close(); err != nil {
+		os.Remove(temporaryFilePath)
+		return err
+	}
+
+	if err := os.Chmod(temporaryFilePath, 0644); err != nil {
+		os.Remove(temporaryFilePath)
+		return err
+	}
+
+	if err := os.Rename(temporaryFilePath, path); err != nil {
+		os.Remove(temporaryFilePath)
+		return err
+	}
+
+	return nil
+}
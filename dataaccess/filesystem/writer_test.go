@@ -0,0 +1,344 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filesystem
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/dataaccess"
+)
+
+// newTestRepository creates a repository in a temporary directory with the given files (path → content).
+func newTestRepository(t *testing.T, files map[string]string) *Repository {
+	directory := t.TempDir()
+	for path, content := range files {
+		filePath := filepath.Join(directory, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configuration := config.Default(directory)
+	configuration.Indexing.Enabled = false
+	configuration.LiveReload.Enabled = false
+
+	repository, err := NewRepository(console.New(loglevel.Off), directory, *configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repository
+}
+
+func Test_getChildPath_ValidName_PathInTheDirectoryIsReturned(t *testing.T) {
+	// arrange
+	directory := filepath.Join("repository", "documents")
+
+	// act
+	result, err := getChildPath(directory, "Sample-Document")
+
+	// assert
+	if err != nil {
+		t.Fatalf("getChildPath returned an error: %s", err)
+	}
+
+	expected := filepath.Join(directory, "Sample-Document")
+	if result != expected {
+		t.Errorf("getChildPath should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_getChildPath_InvalidNames_ErrInvalidPathIsReturned(t *testing.T) {
+	// arrange
+	names := []string{"", " ", ".", "..", "../documents", "a/b", `a\b`, ".hidden", ".allmark", "files", "FILES"}
+
+	for _, name := range names {
+
+		// act
+		_, err := getChildPath("repository", name)
+
+		// assert
+		if !errors.Is(err, dataaccess.ErrInvalidPath) {
+			t.Errorf("getChildPath should reject the name %q with ErrInvalidPath but returned %v.", name, err)
+		}
+	}
+}
+
+func Test_getFilePath_TraversalAndHiddenNames_ErrInvalidPathIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":                 "# Root",
+		"documents/README.md":       "# Documents",
+		"documents/files/image.png": "png",
+	})
+
+	filePaths := []string{"../README.md", "images/../../README.md", ".hidden", "images/.hidden", "", "images/"}
+
+	for _, filePath := range filePaths {
+
+		// act
+		_, _, err := repository.getFilePath(route.NewFromRequest("documents"), filePath)
+
+		// assert
+		if !errors.Is(err, dataaccess.ErrInvalidPath) {
+			t.Errorf("getFilePath should reject the path %q with ErrInvalidPath but returned %v.", filePath, err)
+		}
+	}
+}
+
+func Test_getFilePath_PhysicalItem_PathInTheFilesDirectoryIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":           "# Root",
+		"documents/README.md": "# Documents",
+	})
+
+	// act
+	item, path, err := repository.getFilePath(route.NewFromRequest("documents"), "images/photo.jpg")
+
+	// assert
+	if err != nil {
+		t.Fatalf("getFilePath returned an error: %s", err)
+	}
+
+	expected := filepath.Join(item.Directory(), config.FilesDirectoryName, "images", "photo.jpg")
+	if path != expected {
+		t.Errorf("getFilePath should return %q but returned %q.", expected, path)
+	}
+}
+
+func Test_getFilePath_ItemDoesNotExist_ErrNotFoundIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md": "# Root",
+	})
+
+	// act
+	_, _, err := repository.getFilePath(route.NewFromRequest("missing"), "image.png")
+
+	// assert
+	if !errors.Is(err, dataaccess.ErrNotFound) {
+		t.Errorf("getFilePath should return ErrNotFound but returned %v.", err)
+	}
+}
+
+func Test_checkPrecondition_StaleHash_ErrPreconditionFailedIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md": "# Root",
+	})
+
+	item := repository.Item(route.NewFromRequest(""))
+	precondition := dataaccess.Precondition{MatchingHashes: []string{"stale-hash"}}
+
+	// act
+	err := checkPrecondition(precondition, item)
+
+	// assert
+	if !errors.Is(err, dataaccess.ErrPreconditionFailed) {
+		t.Errorf("checkPrecondition should return ErrPreconditionFailed but returned %v.", err)
+	}
+}
+
+func Test_checkPrecondition_CurrentHash_NoErrorIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md": "# Root",
+	})
+
+	item := repository.Item(route.NewFromRequest(""))
+	hash, err := item.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	precondition := dataaccess.Precondition{MatchingHashes: []string{hash}}
+
+	// act
+	err = checkPrecondition(precondition, item)
+
+	// assert
+	if err != nil {
+		t.Errorf("checkPrecondition should accept the current hash but returned %v.", err)
+	}
+}
+
+func Test_checkPrecondition_MustNotExistAndItemExists_ErrPreconditionFailedIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md": "# Root",
+	})
+
+	item := repository.Item(route.NewFromRequest(""))
+	precondition := dataaccess.Precondition{MustNotExist: true}
+
+	// act
+	err := checkPrecondition(precondition, item)
+
+	// assert
+	if !errors.Is(err, dataaccess.ErrPreconditionFailed) {
+		t.Errorf("checkPrecondition should return ErrPreconditionFailed but returned %v.", err)
+	}
+}
+
+func Test_writeFile_ExistingFile_FileIsReplacedWithoutTemporaryFiles(t *testing.T) {
+	// arrange
+	directory := t.TempDir()
+	path := filepath.Join(directory, "document.md")
+	if err := ioutil.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err := writeFile(path, strings.NewReader("new"))
+
+	// assert
+	if err != nil {
+		t.Fatalf("writeFile returned an error: %s", err)
+	}
+
+	content, _ := ioutil.ReadFile(path)
+	if string(content) != "new" {
+		t.Errorf("The file should contain %q but contained %q.", "new", string(content))
+	}
+
+	entries, _ := ioutil.ReadDir(directory)
+	if len(entries) != 1 {
+		t.Errorf("The directory should only contain the written file but contained %d entries.", len(entries))
+	}
+}
+
+func Test_writeFile_DirectoryDoesNotExist_ErrorIsReturned(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "missing", "document.md")
+
+	// act
+	err := writeFile(path, strings.NewReader("content"))
+
+	// assert
+	if err == nil {
+		t.Errorf("writeFile should return an error if the directory does not exist.")
+	}
+}
+
+func Test_SaveItem_VirtualParent_ItemIsCreated(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":             "# Root",
+		"documents/a/README.md": "# Document A",
+	})
+
+	if parent := repository.Item(route.NewFromRequest("documents")); parent == nil || parent.Type() != dataaccess.TypeVirtual {
+		t.Fatalf("The parent should be a virtual item.")
+	}
+
+	// act
+	item, created, err := repository.SaveItem(route.NewFromRequest("documents/b"), []byte("# Document B"), dataaccess.Precondition{MustNotExist: true})
+
+	// assert
+	if err != nil {
+		t.Fatalf("SaveItem returned an error: %s", err)
+	}
+
+	if !created || item == nil || item.Route().Value() != "documents/b" {
+		t.Errorf("SaveItem should create the item %q below the virtual parent.", "documents/b")
+	}
+}
+
+func Test_SaveItem_PostOnExistingItem_ErrPreconditionFailedIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":           "# Root",
+		"documents/README.md": "# Documents",
+	})
+
+	// act
+	_, _, err := repository.SaveItem(route.NewFromRequest("documents"), []byte("# Replaced"), dataaccess.Precondition{MustNotExist: true})
+
+	// assert
+	if !errors.Is(err, dataaccess.ErrPreconditionFailed) {
+		t.Errorf("SaveItem should return ErrPreconditionFailed but returned %v.", err)
+	}
+
+	content, _ := ioutil.ReadFile(filepath.Join(repository.Path(), "documents", "README.md"))
+	if string(content) != "# Documents" {
+		t.Errorf("The existing item should not be modified but its content is %q.", string(content))
+	}
+}
+
+func Test_SaveItem_StaleHash_ErrPreconditionFailedIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":           "# Root",
+		"documents/README.md": "# Documents",
+	})
+
+	// act
+	_, _, err := repository.SaveItem(route.NewFromRequest("documents"), []byte("# Replaced"), dataaccess.Precondition{MatchingHashes: []string{"stale-hash"}})
+
+	// assert
+	if !errors.Is(err, dataaccess.ErrPreconditionFailed) {
+		t.Errorf("SaveItem should return ErrPreconditionFailed but returned %v.", err)
+	}
+}
+
+func Test_DeleteItem_ItemHasChildren_ErrConflictIsReturned(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":             "# Root",
+		"documents/README.md":   "# Documents",
+		"documents/a/README.md": "# Document A",
+	})
+
+	// act
+	err := repository.DeleteItem(route.NewFromRequest("documents"), dataaccess.Precondition{})
+
+	// assert
+	if !errors.Is(err, dataaccess.ErrConflict) {
+		t.Errorf("DeleteItem should return ErrConflict but returned %v.", err)
+	}
+
+	if repository.Item(route.NewFromRequest("documents/a")) == nil {
+		t.Errorf("The children of the item should not be deleted.")
+	}
+}
+
+func Test_DeleteItem_ItemWithoutChildren_ItemIsDeleted(t *testing.T) {
+	// arrange
+	repository := newTestRepository(t, map[string]string{
+		"README.md":             "# Root",
+		"documents/README.md":   "# Documents",
+		"documents/a/README.md": "# Document A",
+	})
+
+	// act
+	err := repository.DeleteItem(route.NewFromRequest("documents/a"), dataaccess.Precondition{})
+
+	// assert
+	if err != nil {
+		t.Fatalf("DeleteItem returned an error: %s", err)
+	}
+
+	if repository.Item(route.NewFromRequest("documents/a")) != nil {
+		t.Errorf("The item should have been removed from the index.")
+	}
+
+	if _, err := os.Stat(filepath.Join(repository.Path(), "documents", "a")); !os.IsNotExist(err) {
+		t.Errorf("The directory of the item should have been deleted.")
+	}
+}
@@ -5,6 +5,7 @@
 package dataaccess
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/content"
 	"github.com/andreaskoch/allmark/common/route"
 )
@@ -58,3 +59,9 @@ type Item interface {
 	Files() []File
 	LastHash() string
 }
+
+// FolderSettingsProvider is implemented by items whose folders can override selected settings
+// for all items in their subtree.
+type FolderSettingsProvider interface {
+	FolderSettings() config.FolderSettings
+}
@@ -5,8 +5,12 @@
 package dataaccess
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/route"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 )
 
 type PathProvider interface {
@@ -39,9 +43,105 @@ type Repository interface {
 	LiveReload
 }
 
+// IndexNotifier is implemented by repositories which notify subscribers whenever the whole repository has been (re-)indexed.
+type IndexNotifier interface {
+	SubscribeToIndexing(results chan IndexingResult)
+}
+
+// AuthenticationRulesProvider is implemented by repositories whose folders can define who can access their items.
+type AuthenticationRulesProvider interface {
+	AuthenticationRules() []config.AuthenticationRule
+}
+
+// IndexingResult describes a completed index run of the whole repository.
+type IndexingResult struct {
+	// Update contains the items which were found to be new, modified or deleted.
+	Update Update
+
+	// NumberOfItems is the number of items in the index.
+	NumberOfItems int
+
+	// Duration is the time it took to index the repository.
+	Duration time.Duration
+}
+
+// The errors returned by a Writer. Implementations wrap them with a more detailed description.
+var (
+	// ErrNotFound is returned if the item or file doesn't exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned if the operation conflicts with the current state of the repository
+	// (e.g. the parent of a new item doesn't exist or an item which has children is deleted).
+	ErrConflict = errors.New("conflict")
+
+	// ErrPreconditionFailed is returned if the precondition of the operation was not met.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrInvalidPath is returned if a route or a file name is not acceptable for a file or directory.
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// A Precondition restricts a write operation to a certain state of the item or file that is written.
+type Precondition struct {
+	// MatchingHashes, if set, requires that the item or file exists and that its current hash
+	// is one of the given hashes ("*" matches any existing item or file).
+	MatchingHashes []string
+
+	// MustNotExist, if set, requires that the item or file doesn't exist yet.
+	MustNotExist bool
+}
+
+// IsMet returns a flag indicating whether the precondition is met by an item or file
+// with the given existence and hash.
+func (precondition Precondition) IsMet(exists bool, currentHash string) bool {
+	if precondition.MustNotExist && exists {
+		return false
+	}
+
+	if len(precondition.MatchingHashes) == 0 {
+		return true
+	}
+
+	if !exists {
+		return false
+	}
+
+	for _, hash := range precondition.MatchingHashes {
+		if hash == "*" || hash == currentHash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A Writer creates, updates and deletes the items and files of a repository.
+type Writer interface {
+	// SaveItem creates or updates the markdown document of the item with the given route.
+	// The parent of new items must exist.
+	SaveItem(itemRoute route.Route, markdown []byte, precondition Precondition) (item Item, created bool, err error)
+
+	// DeleteItem deletes the item with the given route including its files.
+	// Items which have children cannot be deleted.
+	DeleteItem(itemRoute route.Route, precondition Precondition) error
+
+	// SaveFile creates or updates the file with the given (slash-separated) path which is attached to the item with the given route.
+	SaveFile(itemRoute route.Route, filePath string, data io.Reader, precondition Precondition) (file File, created bool, err error)
+
+	// DeleteFile deletes the file with the given (slash-separated) path which is attached to the item with the given route.
+	DeleteFile(itemRoute route.Route, filePath string, precondition Precondition) error
+}
+
 // NewUpdate creates a new Update instance from the given new, modified and deleted routes.
 func NewUpdate(newItemRoutes, modifiedItemRoutes, deletedItemRoutes []route.Route) Update {
-	return Update{newItemRoutes, modifiedItemRoutes, deletedItemRoutes}
+	return Update{newItemRoutes: newItemRoutes, modifiedItemRoutes: modifiedItemRoutes, deletedItemRoutes: deletedItemRoutes}
+}
+
+// NewIndexUpdate creates a new Update instance for the changes which were found by an index run of the whole repository.
+func NewIndexUpdate(newItemRoutes, modifiedItemRoutes, deletedItemRoutes []route.Route) Update {
+	update := NewUpdate(newItemRoutes, modifiedItemRoutes, deletedItemRoutes)
+	update.isIndexRun = true
+	return update
 }
 
 // Update contains a list of new, modified and deleted routes.
@@ -49,6 +149,8 @@ type Update struct {
 	newItemRoutes      []route.Route
 	modifiedItemRoutes []route.Route
 	deletedItemRoutes  []route.Route
+
+	isIndexRun bool
 }
 
 func (update *Update) String() string {
@@ -61,6 +163,11 @@ func (update *Update) IsEmpty() bool {
 	return len(update.newItemRoutes) == 0 && len(update.modifiedItemRoutes) == 0 && len(update.deletedItemRoutes) == 0
 }
 
+// IsIndexRun indicates whether this Update was found by an index run of the whole repository.
+func (update *Update) IsIndexRun() bool {
+	return update.isIndexRun
+}
+
 // New returns the routes of new items.
 func (update *Update) New() []route.Route {
 	return update.newItemRoutes
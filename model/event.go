@@ -0,0 +1,25 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"time"
+)
+
+// Event contains the dates and the location of the event an item describes (e.g. a meeting or a conference).
+type Event struct {
+	Start time.Time
+	End   time.Time
+
+	// AllDay indicates that only the dates of the event are known (no start and end times).
+	AllDay bool
+
+	Location string
+}
+
+// IsSet returns a flag indicating whether the item describes an event.
+func (event Event) IsSet() bool {
+	return !event.Start.IsZero()
+}
@@ -18,6 +18,38 @@ type MetaData struct {
 	Aliases          []string
 	Author           string
 	GeoInformation   GeoInformation
+
+	// Event contains the dates and the location of the event the item describes (optional).
+	Event Event
+
+	// NoIndex indicates that the item shall not be listed for search engines and readers
+	// (XML sitemap, feeds and search index) and that search engines shall not index its page.
+	NoIndex bool
+
+	// Template is the name of the template the item is rendered with (e.g. "landing"); if empty the
+	// template of the item type is used.
+	Template string
+
+	// HeadSnippet and FooterSnippet are the names of the configured HTML snippets which are inserted into the
+	// page of the item instead of the default snippets (e.g. "landing"; "none" disables the snippet).
+	HeadSnippet   string
+	FooterSnippet string
+
+	// Weight determines the position of the item among its siblings if they are sorted by weight
+	// (lower weights first).
+	Weight int
+
+	// ChildSortOrder is the order of the children of the item (e.g. "title"); if empty the configured
+	// order is used.
+	ChildSortOrder string
+
+	// Navigation contains the entries of the toplevel navigation in their order; it is only used for the
+	// root item. If empty the toplevel navigation lists the toplevel items.
+	Navigation []NavigationEntry
+
+	// Fields contains the values of all single-line meta data definitions by their lowercase key
+	// (e.g. "hero image"), including the ones that are not known to allmark.
+	Fields map[string]string
 }
 
 // NewMetaData creates a new instance of the the MetaData struct.
@@ -0,0 +1,22 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package model
+
+// NavigationWildcard is the target of a navigation entry which stands for all toplevel items
+// which are not listed in the navigation definition themselves.
+const NavigationWildcard = "*"
+
+// NavigationEntry is an entry of the toplevel navigation which is defined in the meta data of the root item.
+type NavigationEntry struct {
+	// Title is the text of the entry; if empty the title of the target item is used.
+	Title string
+
+	// Target is the route of an item (e.g. "/documentation/setup"), an external url
+	// (e.g. "https://github.com/andreaskoch/allmark") or the NavigationWildcard.
+	Target string
+
+	// Icon is a symbol (e.g. "★") or the path or url of an image which is displayed in front of the title (optional).
+	Icon string
+}
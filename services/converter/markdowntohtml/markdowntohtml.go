@@ -23,6 +23,8 @@ type Converter struct {
 
 // New creates a new Markdown-to-HTML converter instance.
 func New(logger logger.Logger, imageProvider *imageprovider.ImageProvider) *Converter {
+	logger = logger.Module("converter")
+
 	return &Converter{
 		logger:        logger,
 		preprocessor:  preprocessor.New(logger, imageProvider),
@@ -103,7 +103,7 @@ func getAudioFileLink(title, link, mimeType string) string {
 		code += fmt.Sprintf("**%s**\n\n", title)
 	}
 
-	code += "<audio controls>"
+	code += "<audio controls preload=\"metadata\">"
 	code += fmt.Sprintf("<source src=\"%s\" type=\"%s\">", link, mimeType)
 	code += "</audio>"
 
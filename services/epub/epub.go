@@ -0,0 +1,357 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package epub writes EPUB 3 e-books (with an EPUB 2 table of contents for older e-readers).
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// MimeType is the mime type of EPUB documents.
+	MimeType = "application/epub+zip"
+
+	// contentFolder is the folder of the EPUB package which contains the publication.
+	contentFolder = "OEBPS"
+
+	// defaultLanguage is the language of books which do not define one.
+	defaultLanguage = "en"
+)
+
+// Book is an e-book whose chapters are XHTML fragments (see ToXHTML).
+type Book struct {
+	// Identifier is the unique identifier of the book (e.g. the URL of the converted item).
+	Identifier string
+
+	Title       string
+	Description string
+	Author      string
+	Language    string
+	Modified    time.Time
+
+	// Cover is the image which is displayed as the cover of the book (optional).
+	Cover *Image
+
+	// Chapters contains the chapters in reading order.
+	Chapters []Chapter
+
+	// Images contains the images the chapters refer to (see Image.Path).
+	Images []Image
+}
+
+// Chapter is a chapter of a book.
+type Chapter struct {
+	Title string
+
+	// Depth is the level of the chapter in the table of contents (0 for the top level).
+	Depth int
+
+	// Content is the XHTML body of the chapter.
+	Content string
+}
+
+// Image is an image of a book.
+type Image struct {
+	// Path is the path the chapters use to refer to the image (e.g. "images/001.png").
+	Path string
+
+	MediaType string
+	Data      []byte
+}
+
+// packageFile is a file of an EPUB package.
+type packageFile struct {
+	path string
+	data []byte
+}
+
+// ChapterPath returns the path the chapters use to refer to the chapter with the given index (e.g. "chapter-001.xhtml").
+func ChapterPath(index int) string {
+	return fmt.Sprintf("chapter-%03d.xhtml", index+1)
+}
+
+// Write writes the given book as an EPUB document to the given writer.
+func (book *Book) Write(writer io.Writer) error {
+	archive := zip.NewWriter(writer)
+
+	// the mime type must be the first, uncompressed file of the package
+	modified := book.getModified()
+	mimeTypeFile, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: modified})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(mimeTypeFile, MimeType); err != nil {
+		return err
+	}
+
+	files := []packageFile{
+		{"META-INF/container.xml", []byte(containerDocument)},
+		{contentFolder + "/content.opf", book.getPackageDocument()},
+		{contentFolder + "/nav.xhtml", book.getNavigationDocument()},
+		{contentFolder + "/toc.ncx", book.getNCXDocument()},
+	}
+
+	if book.Cover != nil {
+		files = append(files,
+			packageFile{contentFolder + "/cover.xhtml", book.getCoverDocument()},
+			packageFile{contentFolder + "/" + book.Cover.Path, book.Cover.Data})
+	}
+
+	for index, chapter := range book.Chapters {
+		files = append(files, packageFile{contentFolder + "/" + ChapterPath(index), book.getChapterDocument(chapter)})
+	}
+
+	for _, image := range book.Images {
+		files = append(files, packageFile{contentFolder + "/" + image.Path, image.Data})
+	}
+
+	for _, file := range files {
+		fileWriter, err := archive.CreateHeader(&zip.FileHeader{Name: file.path, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+
+		if _, err := fileWriter.Write(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+const containerDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+	<rootfiles>
+		<rootfile full-path="` + contentFolder + `/content.opf" media-type="application/oebps-package+xml"/>
+	</rootfiles>
+</container>
+`
+
+// getPackageDocument returns the package document (content.opf) with the meta data, the manifest and the spine of the book.
+func (book *Book) getPackageDocument() []byte {
+	buffer := new(bytes.Buffer)
+
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buffer.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">` + "\n")
+
+	// meta data
+	buffer.WriteString("\t" + `<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(buffer, "\t\t<dc:identifier id=\"book-id\">%s</dc:identifier>\n", escape(book.Identifier))
+	fmt.Fprintf(buffer, "\t\t<dc:title>%s</dc:title>\n", escape(book.Title))
+	fmt.Fprintf(buffer, "\t\t<dc:language>%s</dc:language>\n", escape(book.getLanguage()))
+
+	if book.Author != "" {
+		fmt.Fprintf(buffer, "\t\t<dc:creator>%s</dc:creator>\n", escape(book.Author))
+	}
+
+	if book.Description != "" {
+		fmt.Fprintf(buffer, "\t\t<dc:description>%s</dc:description>\n", escape(book.Description))
+	}
+
+	fmt.Fprintf(buffer, "\t\t<meta property=\"dcterms:modified\">%s</meta>\n", book.getModified().UTC().Format("2006-01-02T15:04:05Z"))
+
+	if book.Cover != nil {
+		buffer.WriteString("\t\t" + `<meta name="cover" content="cover-image"/>` + "\n")
+	}
+
+	buffer.WriteString("\t</metadata>\n")
+
+	// manifest
+	buffer.WriteString("\t<manifest>\n")
+	buffer.WriteString("\t\t" + `<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	buffer.WriteString("\t\t" + `<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>` + "\n")
+
+	if book.Cover != nil {
+		buffer.WriteString("\t\t" + `<item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>` + "\n")
+		fmt.Fprintf(buffer, "\t\t<item id=\"cover-image\" href=\"%s\" media-type=\"%s\" properties=\"cover-image\"/>\n", escape(book.Cover.Path), escape(book.Cover.MediaType))
+	}
+
+	for index := range book.Chapters {
+		fmt.Fprintf(buffer, "\t\t<item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", index+1, ChapterPath(index))
+	}
+
+	for index, image := range book.Images {
+		fmt.Fprintf(buffer, "\t\t<item id=\"image-%d\" href=\"%s\" media-type=\"%s\"/>\n", index+1, escape(image.Path), escape(image.MediaType))
+	}
+
+	buffer.WriteString("\t</manifest>\n")
+
+	// spine
+	buffer.WriteString("\t" + `<spine toc="ncx">` + "\n")
+
+	if book.Cover != nil {
+		buffer.WriteString("\t\t" + `<itemref idref="cover" linear="no"/>` + "\n")
+	}
+
+	for index := range book.Chapters {
+		fmt.Fprintf(buffer, "\t\t<itemref idref=\"chapter-%d\"/>\n", index+1)
+	}
+
+	buffer.WriteString("\t</spine>\n")
+	buffer.WriteString("</package>\n")
+
+	return buffer.Bytes()
+}
+
+// getNavigationDocument returns the EPUB 3 navigation document (nav.xhtml) with the nested table of contents.
+func (book *Book) getNavigationDocument() []byte {
+	buffer := new(bytes.Buffer)
+
+	buffer.WriteString(book.getDocumentHeader(book.Title, `xmlns:epub="http://www.idpf.org/2007/ops"`))
+	buffer.WriteString(`<nav epub:type="toc" id="toc">` + "\n")
+	fmt.Fprintf(buffer, "<h1>%s</h1>\n", escape(book.Title))
+
+	book.forEachTableOfContentsEntry(
+		func(index int) {
+			fmt.Fprintf(buffer, "<li><a href=\"%s\">%s</a>", ChapterPath(index), escape(book.Chapters[index].Title))
+		},
+		func() { buffer.WriteString("</li>\n") },
+		func() { buffer.WriteString("<ol>\n") },
+		func() { buffer.WriteString("</ol>\n") },
+	)
+
+	buffer.WriteString("</nav>\n</body>\n</html>\n")
+	return buffer.Bytes()
+}
+
+// getNCXDocument returns the EPUB 2 table of contents (toc.ncx).
+func (book *Book) getNCXDocument() []byte {
+	buffer := new(bytes.Buffer)
+
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buffer.WriteString(`<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">` + "\n")
+	fmt.Fprintf(buffer, "<head>\n\t<meta name=\"dtb:uid\" content=\"%s\"/>\n</head>\n", escape(book.Identifier))
+	fmt.Fprintf(buffer, "<docTitle><text>%s</text></docTitle>\n", escape(book.Title))
+	buffer.WriteString("<navMap>\n")
+
+	book.forEachTableOfContentsEntry(
+		func(index int) {
+			fmt.Fprintf(buffer, "<navPoint id=\"nav-%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/>\n", index+1, index+1, escape(book.Chapters[index].Title), ChapterPath(index))
+		},
+		func() { buffer.WriteString("</navPoint>\n") },
+		nil,
+		nil,
+	)
+
+	buffer.WriteString("</navMap>\n</ncx>\n")
+	return buffer.Bytes()
+}
+
+// forEachTableOfContentsEntry calls the given functions to open and close the entries of the chapters and the
+// lists of their sub chapters in the order of a nested table of contents; the list functions are optional.
+func (book *Book) forEachTableOfContentsEntry(openEntry func(index int), closeEntry func(), openList func(), closeList func()) {
+	if openList == nil {
+		openList = func() {}
+	}
+
+	if closeList == nil {
+		closeList = func() {}
+	}
+
+	openList()
+
+	depths := getTableOfContentsDepths(book.Chapters)
+	for index, depth := range depths {
+		if index > 0 {
+			if depth > depths[index-1] {
+				openList()
+			} else {
+				closeEntry()
+				for level := depths[index-1]; level > depth; level-- {
+					closeList()
+					closeEntry()
+				}
+			}
+		}
+
+		openEntry(index)
+	}
+
+	if len(depths) > 0 {
+		closeEntry()
+		for level := depths[len(depths)-1]; level > 0; level-- {
+			closeList()
+			closeEntry()
+		}
+	}
+
+	closeList()
+}
+
+// getTableOfContentsDepths returns the levels of the given chapters in the table of contents; a chapter is
+// at most one level below its predecessor, because the table of contents cannot skip levels.
+func getTableOfContentsDepths(chapters []Chapter) []int {
+	depths := make([]int, len(chapters))
+	for index, chapter := range chapters {
+		depth := chapter.Depth
+		if index == 0 || depth < 0 {
+			depth = 0
+		} else if depth > depths[index-1]+1 {
+			depth = depths[index-1] + 1
+		}
+
+		depths[index] = depth
+	}
+
+	return depths
+}
+
+// getCoverDocument returns the page which displays the cover image.
+func (book *Book) getCoverDocument() []byte {
+	return []byte(book.getDocumentHeader(book.Title, "") +
+		fmt.Sprintf("<div class=\"cover\"><img src=\"%s\" alt=\"%s\" style=\"max-width: 100%%; max-height: 100%%;\"/></div>\n", escape(book.Cover.Path), escape(book.Title)) +
+		"</body>\n</html>\n")
+}
+
+// getChapterDocument returns the XHTML document of the given chapter.
+func (book *Book) getChapterDocument(chapter Chapter) []byte {
+	return []byte(book.getDocumentHeader(chapter.Title, "") +
+		fmt.Sprintf("<h1>%s</h1>\n", escape(chapter.Title)) +
+		chapter.Content + "\n" +
+		"</body>\n</html>\n")
+}
+
+// getDocumentHeader returns the beginning of an XHTML document with the given title (up to the opening body element).
+func (book *Book) getDocumentHeader(title, namespaces string) string {
+	if namespaces != "" {
+		namespaces = " " + namespaces
+	}
+
+	language := escape(book.getLanguage())
+	return `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<!DOCTYPE html>` + "\n" +
+		fmt.Sprintf(`<html xmlns="http://www.w3.org/1999/xhtml"%s xml:lang="%s" lang="%s">`, namespaces, language, language) + "\n" +
+		fmt.Sprintf("<head>\n\t<meta charset=\"utf-8\"/>\n\t<title>%s</title>\n</head>\n<body>\n", escape(title))
+}
+
+func (book *Book) getLanguage() string {
+	if language := strings.TrimSpace(book.Language); language != "" {
+		return language
+	}
+
+	return defaultLanguage
+}
+
+func (book *Book) getModified() time.Time {
+	if book.Modified.IsZero() {
+		return time.Now()
+	}
+
+	return book.Modified
+}
+
+// escape returns the given text with the XML special characters escaped.
+func escape(text string) string {
+	buffer := new(bytes.Buffer)
+	xml.EscapeText(buffer, []byte(text))
+	return buffer.String()
+}
@@ -0,0 +1,169 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Write_Book_MimeTypeIsTheFirstUncompressedEntry(t *testing.T) {
+	// arrange
+	book := &Book{
+		Identifier: "http://example.com/docs",
+		Title:      "Docs",
+		Chapters:   []Chapter{{Title: "Docs", Content: "<p>Content</p>"}},
+	}
+	buffer := new(bytes.Buffer)
+
+	// act
+	err := book.Write(buffer)
+
+	// assert
+	if err != nil {
+		t.Fatalf("Writing the book should not fail but returned %q.", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("The book should be a zip archive but reading it returned %q.", err)
+	}
+
+	first := archive.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("The first entry should be the uncompressed mimetype but was %q (method %d).", first.Name, first.Method)
+	}
+
+	content, _ := first.Open()
+	data, _ := io.ReadAll(content)
+	if string(data) != "application/epub+zip" {
+		t.Errorf("The mimetype should be %q but was %q.", "application/epub+zip", string(data))
+	}
+}
+
+func Test_Write_Book_AllDocumentsAreWellFormed(t *testing.T) {
+	// arrange
+	book := &Book{
+		Identifier: "http://example.com/docs",
+		Title:      "Docs & Notes",
+		Cover:      &Image{Path: "images/cover.png", MediaType: "image/png", Data: []byte("png")},
+		Chapters: []Chapter{
+			{Title: "Docs", Content: "<p>Content</p>"},
+			{Title: "Sample", Depth: 1, Content: "<p>Sample</p>"},
+		},
+	}
+	buffer := new(bytes.Buffer)
+
+	// act
+	book.Write(buffer)
+
+	// assert
+	archive, _ := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	for _, file := range archive.File {
+		if !strings.HasSuffix(file.Name, ".xhtml") && !strings.HasSuffix(file.Name, ".opf") && !strings.HasSuffix(file.Name, ".ncx") && !strings.HasSuffix(file.Name, ".xml") {
+			continue
+		}
+
+		content, _ := file.Open()
+		decoder := xml.NewDecoder(content)
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				t.Errorf("The document %q should be well-formed XML but parsing it returned %q.", file.Name, err)
+				break
+			}
+		}
+	}
+}
+
+func Test_getTableOfContentsDepths_SkippedLevels_DepthsAreLimited(t *testing.T) {
+	// arrange
+	chapters := []Chapter{{Depth: 1}, {Depth: 3}, {Depth: 2}, {Depth: 1}}
+
+	// act
+	depths := getTableOfContentsDepths(chapters)
+
+	// assert
+	expected := []int{0, 1, 2, 1}
+	if !reflect.DeepEqual(depths, expected) {
+		t.Errorf("The depths should be %v but were %v.", expected, depths)
+	}
+}
+
+func Test_getNavigationDocument_NestedChapters_ListsAreNested(t *testing.T) {
+	// arrange
+	book := &Book{
+		Title: "Docs",
+		Chapters: []Chapter{
+			{Title: "Docs"},
+			{Title: "A", Depth: 1},
+			{Title: "B", Depth: 2},
+			{Title: "C", Depth: 1},
+		},
+	}
+
+	// act
+	document := string(book.getNavigationDocument())
+
+	// assert
+	expected := `<ol>
+<li><a href="chapter-001.xhtml">Docs</a><ol>
+<li><a href="chapter-002.xhtml">A</a><ol>
+<li><a href="chapter-003.xhtml">B</a></li>
+</ol>
+</li>
+<li><a href="chapter-004.xhtml">C</a></li>
+</ol>
+</li>
+</ol>
+`
+	if !strings.Contains(document, expected) {
+		t.Errorf("The navigation document should contain %q but was %q.", expected, document)
+	}
+}
+
+func Test_ToXHTML_Scripts_ScriptsAreRemoved(t *testing.T) {
+	// arrange
+	fragment := `<p onclick="alert(1)">Text</p><script>alert(2)</script><div><iframe src="http://example.com"></iframe></div>`
+
+	// act
+	result, err := ToXHTML(fragment, func(element, url string) string { return url })
+
+	// assert
+	if err != nil {
+		t.Fatalf("The conversion should not fail but returned %q.", err)
+	}
+
+	expected := `<p>Text</p><div></div>`
+	if result != expected {
+		t.Errorf("The result should be %q but was %q.", expected, result)
+	}
+}
+
+func Test_ToXHTML_Images_ElementsAreClosedAndURLsAreRewritten(t *testing.T) {
+	// arrange
+	fragment := `<p><img src="http://example.com/pic.png" srcset="pic-2x.png 2x" alt="A"><br><a href="#section-a">A</a></p>`
+	rewriteURL := func(element, url string) string {
+		return element + ":" + url
+	}
+
+	// act
+	result, _ := ToXHTML(fragment, rewriteURL)
+
+	// assert
+	expected := `<p><img src="img:http://example.com/pic.png" alt="A"/><br/><a href="a:#section-a">A</a></p>`
+	if result != expected {
+		t.Errorf("The result should be %q but was %q.", expected, result)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epub
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// removedElements contains the elements which are removed from the chapters because e-readers cannot display
+// them offline (e.g. scripts, frames and the players of remote media).
+var removedElements = map[string]bool{
+	"script":   true,
+	"noscript": true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"video":    true,
+	"audio":    true,
+	"form":     true,
+}
+
+// removedAttributes contains the attributes which are removed from the chapters (e.g. the thumbnails of the images).
+var removedAttributes = map[string]bool{
+	"srcset":      true,
+	"data-srcset": true,
+	"sizes":       true,
+	"data-src":    true,
+}
+
+// ToXHTML returns the given HTML fragment as well-formed XHTML for the chapters of a book. Scripts, frames
+// and media players are removed and the URLs of the links ("a") and images ("img") are replaced with the
+// result of the given function (e.g. with the paths of the images which are embedded into the book).
+func ToXHTML(fragment string, rewriteURL func(element, url string) string) (string, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := new(bytes.Buffer)
+	for _, node := range nodes {
+		if node.Type == html.ElementNode && removedElements[node.Data] {
+			continue
+		}
+
+		cleanNode(node, rewriteURL)
+		if err := html.Render(buffer, node); err != nil {
+			return "", err
+		}
+	}
+
+	return buffer.String(), nil
+}
+
+// cleanNode removes the unsupported elements and attributes of the given node and its descendants and
+// rewrites the URLs of the links and images with the given function.
+func cleanNode(node *html.Node, rewriteURL func(element, url string) string) {
+	if node.Type == html.ElementNode {
+		attributes := make([]html.Attribute, 0, len(node.Attr))
+		for _, attribute := range node.Attr {
+			key := strings.ToLower(attribute.Key)
+			if removedAttributes[key] || strings.HasPrefix(key, "on") {
+				continue
+			}
+
+			if (node.Data == "a" && key == "href") || (node.Data == "img" && key == "src") {
+				attribute.Val = rewriteURL(node.Data, attribute.Val)
+			}
+
+			attributes = append(attributes, attribute)
+		}
+
+		// embedded SVG images need their own namespace in XHTML documents
+		if node.Data == "svg" && node.Namespace == "svg" && !hasAttribute(attributes, "xmlns") {
+			attributes = append(attributes, html.Attribute{Key: "xmlns", Val: "http://www.w3.org/2000/svg"})
+		}
+
+		node.Attr = attributes
+	}
+
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.ElementNode && removedElements[child.Data] {
+			node.RemoveChild(child)
+		} else {
+			cleanNode(child, rewriteURL)
+		}
+
+		child = next
+	}
+}
+
+func hasAttribute(attributes []html.Attribute, key string) bool {
+	for _, attribute := range attributes {
+		if attribute.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,121 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imageconversion
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/nfnt/resize"
+)
+
+// IconImage is a PNG image of an icon file with the given edge length in pixels.
+type IconImage struct {
+	Size uint
+	Data []byte
+}
+
+// ResizeToSquare scales the given image so that it fits into a square with the given edge length (enlarging
+// small images), centers it on a transparent background and writes the result as a PNG image to the target.
+func ResizeToSquare(source io.Reader, mimeType string, size uint, target io.Writer) error {
+
+	// check the mime type
+	if !MimeTypeIsSupported(mimeType) {
+		return fmt.Errorf("The mime-type %q is currently not supported.", mimeType)
+	}
+
+	if size == 0 {
+		return fmt.Errorf("The size of a square image must be greater than zero.")
+	}
+
+	// read the source image
+	img, err := decode(source, mimeType)
+	if err != nil {
+		return err
+	}
+
+	// scale the image to the edge length of the square
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return fmt.Errorf("The image is empty.")
+	}
+
+	width, height := size, size
+	if bounds.Dx() > bounds.Dy() {
+		height = maxUint(1, uint(bounds.Dy())*size/uint(bounds.Dx()))
+	} else {
+		width = maxUint(1, uint(bounds.Dx())*size/uint(bounds.Dy()))
+	}
+
+	scaled := resize.Resize(width, height, img, resize.Lanczos3)
+
+	// center the scaled image on a transparent square
+	square := image.NewNRGBA(image.Rect(0, 0, int(size), int(size)))
+	offset := image.Pt(int(size-width)/2, int(size-height)/2)
+	draw.Draw(square, image.Rect(offset.X, offset.Y, offset.X+int(width), offset.Y+int(height)), scaled, scaled.Bounds().Min, draw.Src)
+
+	return png.Encode(target, square)
+}
+
+// WriteIcon writes an ICO file (e.g. a favicon.ico) which contains the given PNG images to the target.
+func WriteIcon(target io.Writer, images []IconImage) error {
+
+	if len(images) == 0 {
+		return fmt.Errorf("An icon file must contain at least one image.")
+	}
+
+	// header: reserved, type (1 = icon), number of images
+	header := []uint16{0, 1, uint16(len(images))}
+	if err := binary.Write(target, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	// directory: one entry per image, the image data follows the directory
+	offset := uint32(6 + 16*len(images))
+	for _, iconImage := range images {
+		if iconImage.Size == 0 || iconImage.Size > 256 {
+			return fmt.Errorf("The size of an icon image must be between 1 and 256 pixels but was %d.", iconImage.Size)
+		}
+
+		entry := struct {
+			Width, Height, Colors, Reserved uint8
+			Planes, BitsPerPixel            uint16
+			Length, Offset                  uint32
+		}{
+			Width:        uint8(iconImage.Size % 256), // 0 means 256 pixels
+			Height:       uint8(iconImage.Size % 256),
+			Planes:       1,
+			BitsPerPixel: 32,
+			Length:       uint32(len(iconImage.Data)),
+			Offset:       offset,
+		}
+
+		if err := binary.Write(target, binary.LittleEndian, entry); err != nil {
+			return err
+		}
+
+		offset += entry.Length
+	}
+
+	for _, iconImage := range images {
+		if _, err := target.Write(iconImage.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func maxUint(a, b uint) uint {
+	if a > b {
+		return a
+	}
+
+	return b
+}
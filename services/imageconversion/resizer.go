@@ -24,7 +24,6 @@ func MimeTypeIsSupported(mimeType string) bool {
 		return false
 	}
 
-	panic("Unreachable")
 }
 
 func GetFileExtensionFromMimeType(mimeType string) string {
@@ -41,7 +40,21 @@ func GetFileExtensionFromMimeType(mimeType string) string {
 
 	}
 
-	panic("Unreachable")
+}
+
+// EstimateMemory returns the estimated number of bytes which are needed to resize the given image
+// (the decoded image with four bytes per pixel). The source is rewound afterwards.
+// If the dimensions cannot be determined the size of the source is returned.
+func EstimateMemory(source io.ReadSeeker) int64 {
+	defer source.Seek(0, io.SeekStart)
+
+	config, _, err := image.DecodeConfig(source)
+	if err != nil {
+		size, _ := source.Seek(0, io.SeekEnd)
+		return size
+	}
+
+	return int64(config.Width) * int64(config.Height) * 4
 }
 
 func Resize(source io.Reader, mimeType string, width, height uint, target io.Writer) error {
@@ -79,7 +92,6 @@ func encode(mimeType string, thumb image.Image, target io.Writer) error {
 
 	}
 
-	panic("Unreachable")
 }
 
 func decode(source io.Reader, mimeType string) (image.Image, error) {
@@ -97,5 +109,4 @@ func decode(source io.Reader, mimeType string) (image.Image, error) {
 
 	}
 
-	panic("Unreachable")
 }
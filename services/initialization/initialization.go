@@ -38,13 +38,17 @@ func Initialize(baseFolder string) (success bool, err error) {
 	fmt.Printf("Templates stored in folder %q.\n", templateFolder)
 
 	// empty basic-authentication file
-	htpasswdFile := config.AuthenticationFilePath()
+	htpasswdFile, err := config.AuthenticationFilePath()
+	if err != nil {
+		return false, fmt.Errorf("Could not determine the authentication user store. Error: %s", err.Error())
+	}
+
 	if !fsutil.FileExists(htpasswdFile) {
 		if _, err := fsutil.CreateFile(htpasswdFile); err != nil {
 			return false, fmt.Errorf("Could not create a authentication user store. Error: %s", err.Error())
 		}
 
-		fmt.Printf("Created an empty authentication user store file: %q\n", config.AuthenticationFilePath())
+		fmt.Printf("Created an empty authentication user store file: %q\n", htpasswdFile)
 	}
 
 	// certs directory
@@ -71,6 +75,6 @@ func createTheme(baseFolder string) (success bool, err error) {
 }
 
 func createTemplates(baseFolder string) (success bool, err error) {
-	templateProvider := templates.NewProvider(baseFolder)
+	templateProvider := templates.NewProvider([]string{baseFolder}, config.DefaultBasePath, "", nil, config.Snippets{}, nil, nil)
 	return templateProvider.StoreTemplatesOnDisc()
 }
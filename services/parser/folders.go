@@ -0,0 +1,34 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/model"
+)
+
+// applyFolderSettings assigns the given folder settings to all meta data attributes which
+// the item doesn't define itself.
+func applyFolderSettings(metaData *model.MetaData, settings config.FolderSettings) {
+	if metaData.Language == "" {
+		metaData.Language = settings.Language
+	}
+
+	if metaData.Direction == "" {
+		metaData.Direction = settings.Direction
+	}
+
+	if metaData.ChildSortOrder == "" {
+		metaData.ChildSortOrder = settings.ChildSortOrder
+	}
+
+	if metaData.Template == "" {
+		metaData.Template = settings.Template
+	}
+
+	if settings.NoIndex != nil && *settings.NoIndex {
+		metaData.NoIndex = true
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/model"
+)
+
+func Test_applyFolderSettings_EmptyMetaData_FolderSettingsAreUsed(t *testing.T) {
+	// arrange
+	noIndex := true
+	settings := config.FolderSettings{
+		Language:       "de",
+		Direction:      "ltr",
+		ChildSortOrder: "title",
+		Template:       "landing",
+		NoIndex:        &noIndex,
+	}
+	metaData := model.MetaData{}
+
+	// act
+	applyFolderSettings(&metaData, settings)
+
+	// assert
+	if metaData.Language != "de" || metaData.Direction != "ltr" {
+		t.Errorf("The language and direction of the folder should be used but were %q and %q", metaData.Language, metaData.Direction)
+	}
+
+	if metaData.ChildSortOrder != "title" || metaData.Template != "landing" {
+		t.Errorf("The child sort order and the template of the folder should be used but were %q and %q", metaData.ChildSortOrder, metaData.Template)
+	}
+
+	if !metaData.NoIndex {
+		t.Errorf("The item should not be indexed")
+	}
+}
+
+func Test_applyFolderSettings_MetaDataDefinesSettings_MetaDataTakesPrecedence(t *testing.T) {
+	// arrange
+	noIndex := false
+	settings := config.FolderSettings{
+		Language: "de",
+		Template: "landing",
+		NoIndex:  &noIndex,
+	}
+	metaData := model.MetaData{
+		Language: "en",
+		Template: "slides",
+		NoIndex:  true,
+	}
+
+	// act
+	applyFolderSettings(&metaData, settings)
+
+	// assert
+	if metaData.Language != "en" || metaData.Template != "slides" {
+		t.Errorf("The meta data of the item should take precedence but the language was %q and the template %q", metaData.Language, metaData.Template)
+	}
+
+	if !metaData.NoIndex {
+		t.Errorf("The item should not be indexed because its meta data says so")
+	}
+}
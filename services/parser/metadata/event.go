@@ -0,0 +1,54 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metadata
+
+import (
+	"github.com/andreaskoch/allmark/common/util/dateutil"
+	"github.com/andreaskoch/allmark/model"
+	"regexp"
+	"time"
+)
+
+// eventTimePattern matches the time component of a date (e.g. "2015-08-03 18:30").
+var eventTimePattern = regexp.MustCompile(`\s\d{2}:\d{2}`)
+
+// parseEvent parses the start, end and location of the event an item describes.
+// Items without a start ("start: 2015-08-03 18:30") are events if they have an end or a location; their
+// date ("date: 2015-08-03 18:30") is then used as the start of the event.
+func parseEvent(metaData *model.MetaData, lines []string) (remainingLines []string) {
+
+	event := &metaData.Event
+
+	foundStart, startValue, remainingLines := getSingleLineMetaData([]string{"start", "starts", "begin", "event date"}, lines)
+	foundEnd, endValue, remainingLines := getSingleLineMetaData([]string{"end", "ends"}, remainingLines)
+	foundLocation, location, remainingLines := getSingleLineMetaData([]string{"location", "venue"}, remainingLines)
+
+	if !foundStart && (foundEnd || foundLocation) {
+		foundStart, startValue, remainingLines = getSingleLineMetaData([]string{"date"}, remainingLines)
+	}
+
+	if !foundStart {
+		return remainingLines
+	}
+
+	start, err := dateutil.ParseIso8601Date(startValue, time.Time{})
+	if err != nil {
+		return remainingLines
+	}
+
+	event.Start = start
+	event.AllDay = !eventTimePattern.MatchString(startValue)
+	event.Location = location
+
+	if foundEnd {
+		if end, err := dateutil.ParseIso8601Date(endValue, time.Time{}); err == nil && !end.Before(start) {
+			event.End = end
+		}
+	}
+
+	metaData.Event = *event
+
+	return remainingLines
+}
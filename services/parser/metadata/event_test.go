@@ -0,0 +1,80 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metadata
+
+import (
+	"github.com/andreaskoch/allmark/model"
+	"testing"
+	"time"
+)
+
+func Test_parseEvent_StartAndEndWithTimes_EventIsSet(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"start: 2015-08-03 18:30",
+		"end: 2015-08-03 21:00",
+		"location: Room 1",
+	}
+
+	// act
+	parseEvent(metaData, lines)
+
+	// assert
+	expectedStart := time.Date(2015, 8, 3, 18, 30, 0, 0, time.UTC)
+	if !metaData.Event.Start.Equal(expectedStart) {
+		t.Errorf("The event should start at %s but starts at %s.", expectedStart, metaData.Event.Start)
+	}
+
+	expectedEnd := time.Date(2015, 8, 3, 21, 0, 0, 0, time.UTC)
+	if !metaData.Event.End.Equal(expectedEnd) {
+		t.Errorf("The event should end at %s but ends at %s.", expectedEnd, metaData.Event.End)
+	}
+
+	if metaData.Event.AllDay {
+		t.Errorf("The event should not be an all-day event.")
+	}
+
+	if metaData.Event.Location != "Room 1" {
+		t.Errorf("The location of the event should be %q but was %q.", "Room 1", metaData.Event.Location)
+	}
+}
+
+func Test_parseEvent_DateAndLocation_DateIsAllDayStart(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"date: 2015-08-03",
+		"location: Berlin",
+	}
+
+	// act
+	parseEvent(metaData, lines)
+
+	// assert
+	if !metaData.Event.IsSet() {
+		t.Errorf("An item with a date and a location should describe an event.")
+	}
+
+	if !metaData.Event.AllDay {
+		t.Errorf("A start without a time should be an all-day event.")
+	}
+}
+
+func Test_parseEvent_OnlyDate_NoEvent(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"date: 2015-08-03",
+	}
+
+	// act
+	parseEvent(metaData, lines)
+
+	// assert
+	if metaData.Event.IsSet() {
+		t.Errorf("An item with only a date should not describe an event.")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metadata
+
+import (
+	"strings"
+
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/services/parser/pattern"
+)
+
+// navigationEntrySeparator separates the title, the target and the icon of a navigation entry.
+const navigationEntrySeparator = "|"
+
+// parseNavigation parses the entries of a multi-line navigation definition. Every entry has the form
+// "title | target | icon" (e.g. "- Source Code | https://github.com/andreaskoch/allmark | ★"); the title and
+// the icon are optional ("- /documentation").
+func parseNavigation(metaData *model.MetaData, lines []string) (remainingLines []string) {
+
+	hasNavigation, rawEntries := pattern.IsMultiLineNavigationDefinition(strings.Join(lines, "\n"))
+	if !hasNavigation {
+		return lines
+	}
+
+	entries := make([]model.NavigationEntry, 0, len(rawEntries))
+	for _, rawEntry := range rawEntries {
+		if entry, isValid := parseNavigationEntry(rawEntry); isValid {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) > 0 {
+		metaData.Navigation = entries
+	}
+
+	return lines
+}
+
+// parseNavigationEntry parses a single navigation entry (e.g. "Documentation | /documentation").
+func parseNavigationEntry(rawEntry string) (entry model.NavigationEntry, isValid bool) {
+
+	components := strings.Split(rawEntry, navigationEntrySeparator)
+	for index := range components {
+		components[index] = strings.TrimSpace(components[index])
+	}
+
+	switch len(components) {
+	case 1:
+		entry.Target = components[0]
+	case 2:
+		entry.Title, entry.Target = components[0], components[1]
+	default:
+		entry.Title, entry.Target, entry.Icon = components[0], components[1], components[2]
+	}
+
+	return entry, entry.Target != ""
+}
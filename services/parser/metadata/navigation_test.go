@@ -0,0 +1,59 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreaskoch/allmark/model"
+)
+
+func Test_parseNavigation_MultiLineDefinition_EntriesAreParsedInTheirOrder(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"language: en",
+		"navigation:",
+		"- /documentation",
+		"- Setup | /documentation/setup",
+		"- Source Code | https://github.com/andreaskoch/allmark | ★",
+		"- *",
+		"- Empty | ",
+	}
+
+	// act
+	parseNavigation(metaData, lines)
+
+	// assert
+	expected := []model.NavigationEntry{
+		{Target: "/documentation"},
+		{Title: "Setup", Target: "/documentation/setup"},
+		{Title: "Source Code", Target: "https://github.com/andreaskoch/allmark", Icon: "★"},
+		{Target: model.NavigationWildcard},
+	}
+
+	if !reflect.DeepEqual(metaData.Navigation, expected) {
+		t.Errorf("The navigation should be %#v but was %#v.", expected, metaData.Navigation)
+	}
+}
+
+func Test_parseNavigation_NoDefinition_NavigationIsEmpty(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"language: en",
+		"tags:",
+		"- navigation",
+	}
+
+	// act
+	parseNavigation(metaData, lines)
+
+	// assert
+	if len(metaData.Navigation) != 0 {
+		t.Errorf("The navigation should be empty but was %#v.", metaData.Navigation)
+	}
+}
@@ -6,6 +6,7 @@ package metadata
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +34,13 @@ func Parse(item *model.Item, lastModifiedDate time.Time, lines []string) (parseE
 	remainingLines = parseLastModifiedDate(metaData, lastModifiedDate, remainingLines)
 	remainingLines = parseTags(metaData, remainingLines)
 	remainingLines = parseGeoInformation(metaData, remainingLines)
+	remainingLines = parseNoIndex(metaData, remainingLines)
+	remainingLines = parseTemplate(metaData, remainingLines)
+	remainingLines = parseSnippets(metaData, remainingLines)
+	remainingLines = parseOrder(metaData, remainingLines)
+	remainingLines = parseNavigation(metaData, remainingLines)
+	remainingLines = parseEvent(metaData, remainingLines)
+	remainingLines = parseFields(metaData, remainingLines)
 
 	// assign the meta data to the item
 	item.MetaData = *metaData
@@ -100,6 +108,87 @@ func parseTags(metaData *model.MetaData, lines []string) (remainingLines []strin
 	return remainingLines
 }
 
+func parseNoIndex(metaData *model.MetaData, lines []string) (remainingLines []string) {
+	found, value, remainingLines := getSingleLineMetaData([]string{"noindex", "private"}, lines)
+	if found {
+		metaData.NoIndex = isTrue(value)
+	}
+
+	return remainingLines
+}
+
+func parseTemplate(metaData *model.MetaData, lines []string) (remainingLines []string) {
+	found, value, remainingLines := getSingleLineMetaData([]string{"template"}, lines)
+	if found {
+		metaData.Template = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	return remainingLines
+}
+
+func parseSnippets(metaData *model.MetaData, lines []string) (remainingLines []string) {
+	found, value, remainingLines := getSingleLineMetaData([]string{"head snippet"}, lines)
+	if found {
+		metaData.HeadSnippet = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	found, value, remainingLines = getSingleLineMetaData([]string{"footer snippet"}, remainingLines)
+	if found {
+		metaData.FooterSnippet = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	return remainingLines
+}
+
+func parseOrder(metaData *model.MetaData, lines []string) (remainingLines []string) {
+	found, value, remainingLines := getSingleLineMetaData([]string{"weight", "order"}, lines)
+	if found {
+		if weight, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			metaData.Weight = weight
+		}
+	}
+
+	found, value, remainingLines = getSingleLineMetaData([]string{"sort children", "child order"}, remainingLines)
+	if found {
+		metaData.ChildSortOrder = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	return remainingLines
+}
+
+// parseFields collects the values of all single-line meta data definitions so they can be looked up by the templates.
+func parseFields(metaData *model.MetaData, lines []string) (remainingLines []string) {
+	fields := make(map[string]string)
+	for _, line := range lines {
+		key, value := pattern.GetSingleLineMetaDataKeyAndValue(line)
+		key = strings.ToLower(strings.Join(strings.Fields(key), " "))
+		value = strings.TrimSpace(value)
+		if key == "" || value == "" {
+			continue
+		}
+
+		if _, exists := fields[key]; !exists {
+			fields[key] = value
+		}
+	}
+
+	if len(fields) > 0 {
+		metaData.Fields = fields
+	}
+
+	return lines
+}
+
+// isTrue returns true if the given meta data value is "true", "yes", "on" or "1" (ignoring the case).
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+
+	return false
+}
+
 func parseCreationDate(metaData *model.MetaData, fallbackDate time.Time, lines []string) (remainingLines []string) {
 	found, value, remainingLines := getSingleLineMetaData([]string{"created at", "date"}, lines)
 	if found {
@@ -40,3 +40,155 @@ func Test_parseTags(t *testing.T) {
 		t.Errorf("The parser should have found 3 tags but contained only %v.", len(metaData.Tags))
 	}
 }
+
+func Test_parseNoIndex_NoIndexIsYes_ItemIsNotIndexed(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"author: John Doe",
+		"noindex: Yes",
+	}
+
+	// act
+	parseNoIndex(metaData, lines)
+
+	// assert
+	if !metaData.NoIndex {
+		t.Errorf("The parser should have set the noindex flag for the value %q.", "Yes")
+	}
+}
+
+func Test_parseNoIndex_NoIndexIsFalse_ItemIsIndexed(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"noindex: false",
+	}
+
+	// act
+	parseNoIndex(metaData, lines)
+
+	// assert
+	if metaData.NoIndex {
+		t.Errorf("The parser should not have set the noindex flag for the value %q.", "false")
+	}
+}
+
+func Test_parseNoIndex_PrivateIsTrue_ItemIsNotIndexed(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"private: true",
+	}
+
+	// act
+	parseNoIndex(metaData, lines)
+
+	// assert
+	if !metaData.NoIndex {
+		t.Errorf("The parser should have set the noindex flag for the private item.")
+	}
+}
+
+func Test_parseTemplate_TemplateIsSet_TemplateNameIsNormalized(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"author: John Doe",
+		"template: Landing ",
+	}
+
+	// act
+	parseTemplate(metaData, lines)
+
+	// assert
+	if metaData.Template != "landing" {
+		t.Errorf("The parser should have set the template %q but set %q.", "landing", metaData.Template)
+	}
+}
+
+func Test_parseFields_CustomDefinitions_FieldsAreCollectedByLowercaseKey(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"author: John Doe",
+		"Hero  Image: files/hero.jpg ",
+		"hero image: files/other.jpg",
+		"empty:",
+	}
+
+	// act
+	parseFields(metaData, lines)
+
+	// assert
+	if metaData.Fields["hero image"] != "files/hero.jpg" {
+		t.Errorf("The parser should have set the field %q to %q but set %q.", "hero image", "files/hero.jpg", metaData.Fields["hero image"])
+	}
+
+	if metaData.Fields["author"] != "John Doe" {
+		t.Errorf("The parser should have set the field %q to %q but set %q.", "author", "John Doe", metaData.Fields["author"])
+	}
+
+	if _, exists := metaData.Fields["empty"]; exists {
+		t.Errorf("The parser should not have set a field for a definition without a value.")
+	}
+}
+
+func Test_parseSnippets_SnippetsAreSelected_SnippetNamesAreNormalized(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"Head Snippet: Landing",
+		"footer snippet: none",
+	}
+
+	// act
+	parseSnippets(metaData, lines)
+
+	// assert
+	if metaData.HeadSnippet != "landing" {
+		t.Errorf("The parser should have set the head snippet %q but set %q.", "landing", metaData.HeadSnippet)
+	}
+
+	if metaData.FooterSnippet != "none" {
+		t.Errorf("The parser should have set the footer snippet %q but set %q.", "none", metaData.FooterSnippet)
+	}
+}
+
+func Test_parseOrder_WeightAndChildOrderAreSet_OrderIsParsed(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"author: John Doe",
+		"weight: 10",
+		"sort children: Title ",
+	}
+
+	// act
+	parseOrder(metaData, lines)
+
+	// assert
+	if metaData.Weight != 10 {
+		t.Errorf("The parser should have set the weight %d but set %d.", 10, metaData.Weight)
+	}
+
+	if metaData.ChildSortOrder != "title" {
+		t.Errorf("The parser should have set the child sort order %q but set %q.", "title", metaData.ChildSortOrder)
+	}
+}
+
+func Test_parseOrder_OrderIsNotANumber_WeightIsNotSet(t *testing.T) {
+	// arrange
+	metaData := model.NewMetaData()
+	lines := []string{
+		"order: first",
+	}
+
+	// act
+	parseOrder(metaData, lines)
+
+	// assert
+	if metaData.Weight != 0 {
+		t.Errorf("The parser should not have set a weight but set %d.", metaData.Weight)
+	}
+}
@@ -25,7 +25,7 @@ type Parser struct {
 
 func New(logger logger.Logger) (Parser, error) {
 	return Parser{
-		logger: logger,
+		logger: logger.Module("parser"),
 	}, nil
 }
 
@@ -85,6 +85,11 @@ func (parser *Parser) ParseItem(item dataaccess.Item) (*model.Item, error) {
 
 	}
 
+	// apply the settings of the folder
+	if folderSettingsProvider, isFolderSettingsProvider := item.(dataaccess.FolderSettingsProvider); isFolderSettingsProvider {
+		applyFolderSettings(&itemModel.MetaData, folderSettingsProvider.FolderSettings())
+	}
+
 	// item hash
 	hash, err := item.Hash()
 	if err != nil {
@@ -36,6 +36,9 @@ var (
 	// Multi-line alias meta data
 	multiLineAliasPattern = regexp.MustCompile(`(?is)alias:\n{0,2}(\n\s?-\s?[^\n]+)+\n*`)
 
+	// Multi-line navigation meta data
+	multiLineNavigationPattern = regexp.MustCompile(`(?is)navigation:\n{0,2}(\n\s?-\s?[^\n]+)+\n*`)
+
 	// Lines with a meta data label in them syntax
 	metaDataLabelPattern = regexp.MustCompile(`^(\w+[\w\s]+\w+):`)
 
@@ -139,6 +142,12 @@ func IsMultiLineAliasDefinition(text string) (bool, []string) {
 	return isMultiLineDefinition(multiLineAliasPattern, text)
 }
 
+// IsMultiLineNavigationDefinition returns true and the list items if the supplied text contains a
+// multi-line navigation definition.
+func IsMultiLineNavigationDefinition(text string) (bool, []string) {
+	return isMultiLineDefinition(multiLineNavigationPattern, text)
+}
+
 func isMultiLineDefinition(pattern *regexp.Regexp, text string) (bool, []string) {
 	multiLineTagLocation := pattern.FindStringSubmatchIndex(text)
 	if multiLineTagLocation == nil {
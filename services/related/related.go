@@ -0,0 +1,149 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package related finds the items which are related to an item because they share its tags
+// or are linked together with it by other items (co-citation).
+package related
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// The weights of the different kinds of relations.
+const (
+	// coCitationWeight is added for every item which links to both items.
+	coCitationWeight = 1.0
+
+	// directLinkWeight is added if one of the items links to the other one.
+	directLinkWeight = 0.5
+)
+
+// Item is an item of the repository with the properties by which the relations are determined.
+type Item struct {
+	Key   string
+	Tags  []string
+	Links []string // the keys of the items the item links to
+}
+
+// Match is an item which is related to another item.
+type Match struct {
+	Key   string
+	Score float64
+}
+
+// Index finds the related items of the items it has been created for.
+type Index struct {
+	items       map[string]Item
+	itemsByTag  map[string][]string
+	linksByItem map[string]map[string]bool
+	citingItems map[string][]string
+}
+
+// NewIndex creates a new index for the given items.
+func NewIndex(items []Item) *Index {
+	index := &Index{
+		items:       make(map[string]Item, len(items)),
+		itemsByTag:  make(map[string][]string),
+		linksByItem: make(map[string]map[string]bool),
+		citingItems: make(map[string][]string),
+	}
+
+	for _, item := range items {
+		index.items[item.Key] = item
+
+		for tag := range normalizeTags(item.Tags) {
+			index.itemsByTag[tag] = append(index.itemsByTag[tag], item.Key)
+		}
+
+		links := make(map[string]bool)
+		for _, link := range item.Links {
+			if link == item.Key || links[link] {
+				continue
+			}
+
+			links[link] = true
+			index.citingItems[link] = append(index.citingItems[link], item.Key)
+		}
+
+		index.linksByItem[item.Key] = links
+	}
+
+	return index
+}
+
+// Related returns at most limit items which are related to the item with the given key, the most closely
+// related first. Rare tags weigh more than tags which many items have.
+func (index *Index) Related(key string, limit int) []Match {
+	item, exists := index.items[key]
+	if !exists || limit <= 0 {
+		return nil
+	}
+
+	scores := make(map[string]float64)
+
+	// shared tags
+	numberOfItems := float64(len(index.items))
+	for tag := range normalizeTags(item.Tags) {
+		taggedItems := index.itemsByTag[tag]
+		weight := math.Log(1 + numberOfItems/float64(len(taggedItems)))
+		for _, taggedItem := range taggedItems {
+			scores[taggedItem] += weight
+		}
+	}
+
+	// co-citation: the other items which are linked by the items that link to the item
+	for _, citingItem := range index.citingItems[key] {
+		for linkedItem := range index.linksByItem[citingItem] {
+			scores[linkedItem] += coCitationWeight
+		}
+	}
+
+	// direct links
+	for linkedItem := range index.linksByItem[key] {
+		scores[linkedItem] += directLinkWeight
+	}
+
+	for _, citingItem := range index.citingItems[key] {
+		scores[citingItem] += directLinkWeight
+	}
+
+	delete(scores, key)
+
+	matches := make([]Match, 0, len(scores))
+	for relatedItem, score := range scores {
+		if _, exists := index.items[relatedItem]; !exists || score <= 0 {
+			continue
+		}
+
+		matches = append(matches, Match{Key: relatedItem, Score: score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+
+		return matches[i].Key < matches[j].Key
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// normalizeTags returns the distinct lowercase forms of the given tags.
+func normalizeTags(tags []string) map[string]bool {
+	normalizedTags := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			normalizedTags[tag] = true
+		}
+	}
+
+	return normalizedTags
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package related
+
+import (
+	"testing"
+)
+
+func Test_Related_SharedTags_ItemWithTheRarerTagIsRankedFirst(t *testing.T) {
+	// arrange
+	index := NewIndex([]Item{
+		{Key: "a", Tags: []string{"Go", "kubernetes"}},
+		{Key: "b", Tags: []string{"go"}},
+		{Key: "c", Tags: []string{"Kubernetes"}},
+		{Key: "d", Tags: []string{"go"}},
+	})
+
+	// act
+	matches := index.Related("a", 10)
+
+	// assert
+	if len(matches) != 3 || matches[0].Key != "c" {
+		t.Errorf("The item with the rarer shared tag should be ranked first but the matches were %v.", matches)
+	}
+}
+
+func Test_Related_CoCitation_ItemsLinkedTogetherAreRelated(t *testing.T) {
+	// arrange
+	index := NewIndex([]Item{
+		{Key: "a"},
+		{Key: "b"},
+		{Key: "c"},
+		{Key: "overview", Links: []string{"a", "b"}},
+	})
+
+	// act
+	matches := index.Related("a", 10)
+
+	// assert
+	if len(matches) != 2 || matches[0].Key != "b" || matches[1].Key != "overview" {
+		t.Errorf("The co-cited item should be ranked before the citing item but the matches were %v.", matches)
+	}
+}
+
+func Test_Related_Limit_AtMostLimitItemsAreReturned(t *testing.T) {
+	// arrange
+	index := NewIndex([]Item{
+		{Key: "a", Tags: []string{"go"}},
+		{Key: "b", Tags: []string{"go"}},
+		{Key: "c", Tags: []string{"go"}},
+		{Key: "d", Tags: []string{"go"}},
+	})
+
+	// act
+	matches := index.Related("a", 2)
+
+	// assert
+	if len(matches) != 2 || matches[0].Key != "b" || matches[1].Key != "c" {
+		t.Errorf("Related should return the first 2 items ordered by their key but returned %v.", matches)
+	}
+}
+
+func Test_Related_UnrelatedOrUnknownItem_NothingIsReturned(t *testing.T) {
+	// arrange
+	index := NewIndex([]Item{
+		{Key: "a", Tags: []string{"go"}, Links: []string{"a"}},
+		{Key: "b", Tags: []string{"java"}},
+	})
+
+	// act
+	unrelated := index.Related("a", 10)
+	unknown := index.Related("x", 10)
+
+	// assert
+	if len(unrelated) != 0 || len(unknown) != 0 {
+		t.Errorf("Related should not return any items but returned %v and %v.", unrelated, unknown)
+	}
+}
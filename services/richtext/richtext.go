@@ -0,0 +1,125 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package richtext converts HTML documents to rich-text documents (DOCX, ODT) with pandoc
+// and caches the converted documents.
+package richtext
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// The formats of the rich-text documents (the file extensions which pandoc uses to select the output format).
+const (
+	FormatDOCX = "docx"
+	FormatODT  = "odt"
+)
+
+// NewConverter creates a new converter which runs the conversion tool (pandoc) with the given path and
+// stores the converted documents in the given cache folder.
+func NewConverter(logger logger.Logger, toolPath, cacheFolder string) *Converter {
+	return &Converter{
+		logger:      logger.Module("conversion"),
+		toolPath:    toolPath,
+		cacheFolder: cacheFolder,
+	}
+}
+
+// A Converter converts HTML documents to rich-text documents. Every version of a document is only
+// converted once; later requests for the same version are answered from the cache folder.
+type Converter struct {
+	logger logger.Logger
+
+	toolPath    string
+	cacheFolder string
+}
+
+// Convert returns the path of the rich-text document in the given format of the given HTML document.
+// The name identifies the document (e.g. the route of an item) and the version its current state (e.g. a hash of
+// its content). A cached document with the same name and version is returned without a conversion; the cached
+// documents of the previous versions are removed when a new version has been converted.
+func (converter *Converter) Convert(name, version, html, format string) (string, error) {
+
+	cacheFilePath := getCacheFilePath(converter.cacheFolder, name, version, format)
+	if fsutil.FileExists(cacheFilePath) {
+		converter.logger.Debug("Using the cached %s document of %q.", format, name)
+		return cacheFilePath, nil
+	}
+
+	// the temporary working directory
+	workingDirectory := fsutil.GetTempDirectory()
+	defer func() {
+		if err := os.RemoveAll(workingDirectory); err != nil {
+			converter.logger.Error("Could not delete the temporary working directory (%q) that has been created during the conversion. Error: %s", workingDirectory, err.Error())
+		}
+	}()
+
+	// write the html to a temp file (Note: the file extensions are important for pandoc)
+	htmlFilePath := filepath.Join(workingDirectory, "source.html")
+	if err := os.WriteFile(htmlFilePath, []byte(html), 0644); err != nil {
+		return "", fmt.Errorf("Cannot write the HTML file. Error: %s", err.Error())
+	}
+
+	targetFilePath := filepath.Join(workingDirectory, "target."+format)
+
+	cmd := exec.Command(converter.toolPath, "-s", htmlFilePath, "-o", targetFilePath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Dir = workingDirectory
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Could not run %q: %v", converter.toolPath, err)
+	}
+
+	document, err := os.ReadFile(targetFilePath)
+	if err != nil {
+		return "", fmt.Errorf("Cannot read the converted document. Error: %s", err.Error())
+	}
+
+	if err := os.MkdirAll(converter.cacheFolder, 0700); err != nil {
+		return "", fmt.Errorf("Cannot create the cache folder %q. Error: %s", converter.cacheFolder, err.Error())
+	}
+
+	converter.removeCachedVersions(name, format)
+
+	// write to a temporary file first so concurrent requests never read an incomplete document
+	temporaryCacheFilePath := cacheFilePath + ".tmp"
+	if err := os.WriteFile(temporaryCacheFilePath, document, 0600); err != nil {
+		return "", fmt.Errorf("Cannot write the cache file %q. Error: %s", cacheFilePath, err.Error())
+	}
+
+	if err := os.Rename(temporaryCacheFilePath, cacheFilePath); err != nil {
+		return "", fmt.Errorf("Cannot write the cache file %q. Error: %s", cacheFilePath, err.Error())
+	}
+
+	converter.logger.Debug("Cached the %s document of %q in %q.", format, name, cacheFilePath)
+	return cacheFilePath, nil
+}
+
+// removeCachedVersions removes all cached documents in the given format of the document with the given name.
+func (converter *Converter) removeCachedVersions(name, format string) {
+	cachedFilePaths, _ := filepath.Glob(getCacheFilePath(converter.cacheFolder, name, "*", format))
+	for _, cachedFilePath := range cachedFilePaths {
+		if err := os.Remove(cachedFilePath); err != nil {
+			converter.logger.Warn("Could not remove the cached document %q. Error: %s", cachedFilePath, err.Error())
+		}
+	}
+}
+
+// getCacheFilePath returns the path of the cached document in the given format of the given version of the
+// document with the given name (e.g. "conversions/12-0A1B2C3D_2048-4E5F6A7B.docx"). A version of "*" returns
+// a pattern which matches the cached documents of all versions.
+func getCacheFilePath(cacheFolder, name, version, format string) string {
+	if version != "*" {
+		version = hashutil.FromString(version)
+	}
+
+	return filepath.Join(cacheFolder, fmt.Sprintf("%s_%s.%s", hashutil.FromString(name), version, format))
+}
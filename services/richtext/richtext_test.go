@@ -0,0 +1,87 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package richtext
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// newTestTool creates a conversion tool which copies the source file (the second argument) to the target file (the fourth argument).
+func newTestTool(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test conversion tool is a shell script.")
+	}
+
+	toolPath := filepath.Join(t.TempDir(), "pandoc")
+	if err := os.WriteFile(toolPath, []byte("#!/bin/sh\ncp \"$2\" \"$4\"\n"), 0700); err != nil {
+		t.Fatalf("Unable to create the test conversion tool: %s", err)
+	}
+
+	return toolPath
+}
+
+func Test_Convert_NewDocument_ConvertedDocumentIsCached(t *testing.T) {
+	// arrange
+	cacheFolder := filepath.Join(t.TempDir(), "conversions")
+	converter := NewConverter(console.New(loglevel.Off), newTestTool(t), cacheFolder)
+
+	// act
+	filePath, err := converter.Convert("docs/sample", "v1", "<p>Sample</p>", FormatDOCX)
+
+	// assert
+	if err != nil {
+		t.Fatalf("Convert returned an error: %s", err)
+	}
+
+	if filepath.Dir(filePath) != cacheFolder || filepath.Ext(filePath) != ".docx" {
+		t.Errorf("The converted document should be a DOCX file in %q but was %q.", cacheFolder, filePath)
+	}
+
+	if content, _ := os.ReadFile(filePath); string(content) != "<p>Sample</p>" {
+		t.Errorf("The converted document should contain the output of the conversion tool but contained %q.", content)
+	}
+}
+
+func Test_Convert_CachedVersion_ToolIsNotCalled(t *testing.T) {
+	// arrange
+	cacheFolder := t.TempDir()
+	cachedFilePath := getCacheFilePath(cacheFolder, "docs/sample", "v1", FormatODT)
+	os.WriteFile(cachedFilePath, []byte("cached"), 0600)
+
+	converter := NewConverter(console.New(loglevel.Off), filepath.Join(cacheFolder, "missing-tool"), cacheFolder)
+
+	// act
+	filePath, err := converter.Convert("docs/sample", "v1", "<p>Sample</p>", FormatODT)
+
+	// assert
+	if err != nil || filePath != cachedFilePath {
+		t.Errorf("Convert should return the cached document %q but returned %q (error: %v).", cachedFilePath, filePath, err)
+	}
+}
+
+func Test_Convert_NewVersion_PreviousVersionIsRemoved(t *testing.T) {
+	// arrange
+	cacheFolder := t.TempDir()
+	converter := NewConverter(console.New(loglevel.Off), newTestTool(t), cacheFolder)
+	previousFilePath, _ := converter.Convert("docs/sample", "v1", "<p>Version 1</p>", FormatDOCX)
+	otherFormatFilePath, _ := converter.Convert("docs/sample", "v1", "<p>Version 1</p>", FormatODT)
+
+	// act
+	converter.Convert("docs/sample", "v2", "<p>Version 2</p>", FormatDOCX)
+
+	// assert
+	if _, err := os.Stat(previousFilePath); !os.IsNotExist(err) {
+		t.Errorf("The previous version %q should have been removed.", previousFilePath)
+	}
+
+	if _, err := os.Stat(otherFormatFilePath); err != nil {
+		t.Errorf("The document in the other format %q should not have been removed.", otherFormatFilePath)
+	}
+}
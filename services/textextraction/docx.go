@@ -0,0 +1,102 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textextraction
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docxTextParts are the parts of a DOCX package whose text is extracted (in this order).
+var docxTextParts = []string{"word/document.xml", "word/footnotes.xml", "word/endnotes.xml"}
+
+// extractDOCX returns the text of the paragraphs of the given DOCX document.
+func extractDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("Unable to open the DOCX package. Error: %s", err)
+	}
+
+	parts := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	if parts[docxTextParts[0]] == nil {
+		return "", fmt.Errorf("The DOCX package does not contain %q.", docxTextParts[0])
+	}
+
+	var text strings.Builder
+	for _, name := range docxTextParts {
+		part := parts[name]
+		if part == nil {
+			continue
+		}
+
+		reader, err := part.Open()
+		if err != nil {
+			return "", err
+		}
+
+		err = extractWordprocessingText(reader, &text)
+		reader.Close()
+		if err != nil {
+			return "", fmt.Errorf("Unable to read %q of the DOCX package. Error: %s", name, err)
+		}
+	}
+
+	return strings.TrimSpace(text.String()), nil
+}
+
+// extractWordprocessingText writes the text runs of the given WordprocessingML part to the given builder;
+// paragraphs and line breaks become line breaks, tabs become tabs.
+func extractWordprocessingText(reader io.Reader, text *strings.Builder) error {
+	decoder := xml.NewDecoder(reader)
+	isText := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "t":
+				isText = true
+			case "tab":
+				text.WriteString("\t")
+			case "br", "cr":
+				text.WriteString("\n")
+			}
+
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "t":
+				isText = false
+			case "p":
+				text.WriteString("\n")
+			}
+
+		case xml.CharData:
+			if isText {
+				text.Write(element)
+			}
+		}
+
+		if text.Len() > MaximumTextLength {
+			return nil
+		}
+	}
+}
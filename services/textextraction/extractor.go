@@ -0,0 +1,165 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package textextraction extracts the plain text from attached files (PDF, DOCX and text files)
+// so that they can be indexed by the full-text search.
+package textextraction
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaximumTextLength is the number of bytes of text that are extracted from a single file.
+const MaximumTextLength = 1 << 20
+
+// The supported file formats.
+const (
+	formatUnknown = iota
+	formatText
+	formatDOCX
+	formatPDF
+)
+
+// textFileExtensions contains the extensions of the files whose content is indexed as it is.
+var textFileExtensions = map[string]bool{
+	".txt":      true,
+	".text":     true,
+	".md":       true,
+	".markdown": true,
+	".csv":      true,
+	".tsv":      true,
+	".log":      true,
+	".rst":      true,
+	".org":      true,
+	".tex":      true,
+}
+
+// New creates a new text extractor which ignores files that are larger than the given size (in bytes).
+// If the given pdftotext path points to an executable it is used to extract the text from PDF files;
+// otherwise the built-in PDF parser is used.
+func New(maximumFileSize int64, pdfToTextPath string) *Extractor {
+	extractor := &Extractor{
+		maximumFileSize: maximumFileSize,
+	}
+
+	if pdfToTextPath != "" {
+		if path, err := exec.LookPath(pdfToTextPath); err == nil {
+			extractor.pdfToTextPath = path
+		}
+	}
+
+	return extractor
+}
+
+// Extractor extracts the text from files.
+type Extractor struct {
+	maximumFileSize int64
+	pdfToTextPath   string
+}
+
+// Name returns a name for the extraction method; it changes if PDF files are handled by another tool.
+func (extractor *Extractor) Name() string {
+	if extractor.pdfToTextPath != "" {
+		return "pdftotext"
+	}
+
+	return "builtin"
+}
+
+// CanExtract returns true if the text of files with the given name and mime type can be extracted.
+func (extractor *Extractor) CanExtract(fileName, mimeType string) bool {
+	return getFormat(fileName, mimeType) != formatUnknown
+}
+
+// Extract returns the text of the file with the given name and mime type which is read from the given reader.
+func (extractor *Extractor) Extract(fileName, mimeType string, content io.Reader) (string, error) {
+	format := getFormat(fileName, mimeType)
+	if format == formatUnknown {
+		return "", fmt.Errorf("The text of %q (%s) cannot be extracted.", fileName, mimeType)
+	}
+
+	if extractor.maximumFileSize > 0 {
+		content = io.LimitReader(content, extractor.maximumFileSize+1)
+	}
+
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+
+	if extractor.maximumFileSize > 0 && int64(len(data)) > extractor.maximumFileSize {
+		return "", fmt.Errorf("The file %q is larger than %d bytes.", fileName, extractor.maximumFileSize)
+	}
+
+	var text string
+	switch format {
+	case formatText:
+		text = decodeText(data)
+	case formatDOCX:
+		text, err = extractDOCX(data)
+	case formatPDF:
+		if extractor.pdfToTextPath != "" {
+			text, err = extractPDFWithTool(extractor.pdfToTextPath, data)
+		} else {
+			text, err = extractPDF(data)
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return truncate(text, MaximumTextLength), nil
+}
+
+// getFormat returns the format of the file with the given name and mime type.
+func getFormat(fileName, mimeType string) int {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+
+	switch {
+	case extension == ".pdf" || mimeType == "application/pdf":
+		return formatPDF
+	case extension == ".docx" || mimeType == "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return formatDOCX
+	case textFileExtensions[extension] || mimeType == "text/plain" || mimeType == "text/markdown" || mimeType == "text/csv":
+		return formatText
+	}
+
+	return formatUnknown
+}
+
+// decodeText returns the given data as a string. Data which is not valid UTF-8 is decoded as Latin-1.
+func decodeText(data []byte) string {
+	if utf8.Valid(data) {
+		return strings.TrimPrefix(string(data), "\ufeff")
+	}
+
+	characters := make([]rune, 0, len(data))
+	for _, character := range data {
+		characters = append(characters, rune(character))
+	}
+
+	return string(characters)
+}
+
+// truncate cuts the given text after the given number of bytes (at a character boundary).
+func truncate(text string, maximumLength int) string {
+	if len(text) <= maximumLength {
+		return text
+	}
+
+	end := maximumLength
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return text[:end]
+}
@@ -0,0 +1,226 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textextraction
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newPDF returns a minimal PDF document with the given content stream.
+func newPDF(content string, compress bool) []byte {
+	streamData := []byte(content)
+	dictionary := fmt.Sprintf("<< /Length %d >>", len(streamData))
+	if compress {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		writer.Write(streamData)
+		writer.Close()
+
+		streamData = compressed.Bytes()
+		dictionary = fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(streamData))
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+	document.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	document.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	document.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n")
+	document.WriteString("4 0 obj\n" + dictionary + "\nstream\n")
+	document.Write(streamData)
+	document.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+	return document.Bytes()
+}
+
+// newDOCX returns a minimal DOCX package with the given paragraphs.
+func newDOCX(paragraphs ...string) []byte {
+	var document bytes.Buffer
+	archive := zip.NewWriter(&document)
+
+	writer, _ := archive.Create("word/document.xml")
+	fmt.Fprint(writer, `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	for _, paragraph := range paragraphs {
+		fmt.Fprintf(writer, `<w:p><w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, paragraph)
+	}
+
+	fmt.Fprint(writer, `</w:body></w:document>`)
+	archive.Close()
+
+	return document.Bytes()
+}
+
+func Test_CanExtract_SupportedAndUnsupportedFiles_OnlySupportedFilesAreAccepted(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+	inputs := map[string]bool{
+		"report.pdf":  true,
+		"letter.DOCX": true,
+		"notes.txt":   true,
+		"data.csv":    true,
+		"photo.jpg":   false,
+		"archive.zip": false,
+		"letter.doc":  false,
+	}
+
+	for fileName, expected := range inputs {
+
+		// act
+		result := extractor.CanExtract(fileName, "")
+
+		// assert
+		if result != expected {
+			t.Errorf("CanExtract(%q) should return %t but returned %t.", fileName, expected, result)
+		}
+	}
+}
+
+func Test_CanExtract_PlainTextMimeTypeWithoutExtension_FileIsAccepted(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+
+	// act
+	result := extractor.CanExtract("README", "text/plain; charset=utf-8")
+
+	// assert
+	if !result {
+		t.Errorf("Files with the mime type text/plain should be accepted.")
+	}
+}
+
+func Test_Extract_TextFile_ContentIsReturned(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+
+	// act
+	result, err := extractor.Extract("notes.txt", "text/plain", strings.NewReader("Kubernetes cluster notes"))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Extract returned an error: %s", err)
+	}
+
+	if result != "Kubernetes cluster notes" {
+		t.Errorf("Extract should return the content of the file but returned %q.", result)
+	}
+}
+
+func Test_Extract_Latin1TextFile_TextIsDecoded(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+
+	// act
+	result, err := extractor.Extract("notes.txt", "", bytes.NewReader([]byte("Stra\xdfe")))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Extract returned an error: %s", err)
+	}
+
+	if result != "Straße" {
+		t.Errorf("Extract should return %q but returned %q.", "Straße", result)
+	}
+}
+
+func Test_Extract_FileLargerThanMaximumSize_ErrorIsReturned(t *testing.T) {
+	// arrange
+	extractor := New(4, "")
+
+	// act
+	_, err := extractor.Extract("notes.txt", "", strings.NewReader("Too long"))
+
+	// assert
+	if err == nil {
+		t.Errorf("Extract should return an error for files which are larger than the maximum size.")
+	}
+}
+
+func Test_Extract_DOCX_TextOfParagraphsIsReturned(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+	document := newDOCX("Quarterly report", "Revenue grew by 5%")
+
+	// act
+	result, err := extractor.Extract("report.docx", "", bytes.NewReader(document))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Extract returned an error: %s", err)
+	}
+
+	expected := "Quarterly report\nRevenue grew by 5%"
+	if result != expected {
+		t.Errorf("Extract should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_Extract_InvalidDOCX_ErrorIsReturned(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+
+	// act
+	_, err := extractor.Extract("report.docx", "", strings.NewReader("not a zip file"))
+
+	// assert
+	if err == nil {
+		t.Errorf("Extract should return an error for invalid DOCX packages.")
+	}
+}
+
+func Test_Extract_UncompressedPDF_TextOperatorsAreExtracted(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+	document := newPDF("BT /F1 12 Tf 72 712 Td (Hello, \\(PDF\\) World) Tj ET", false)
+
+	// act
+	result, err := extractor.Extract("hello.pdf", "", bytes.NewReader(document))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Extract returned an error: %s", err)
+	}
+
+	if result != "Hello, (PDF) World" {
+		t.Errorf("Extract should return %q but returned %q.", "Hello, (PDF) World", result)
+	}
+}
+
+func Test_Extract_CompressedPDFWithKernedText_TextIsExtracted(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+	content := "BT /F1 12 Tf [(Ker) 20 (ning) -300 (works)] TJ ET\nBT <FEFF0055006E00690063006F00640065> Tj ET"
+	document := newPDF(content, true)
+
+	// act
+	result, err := extractor.Extract("kerning.pdf", "", bytes.NewReader(document))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Extract returned an error: %s", err)
+	}
+
+	expected := "Kerning works\nUnicode"
+	if result != expected {
+		t.Errorf("Extract should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_Extract_EncryptedPDF_ErrorIsReturned(t *testing.T) {
+	// arrange
+	extractor := New(0, "")
+	document := bytes.Replace(newPDF("BT (Secret) Tj ET", false), []byte("<< /Root 1 0 R >>"), []byte("<< /Root 1 0 R /Encrypt 5 0 R >>"), 1)
+
+	// act
+	_, err := extractor.Extract("secret.pdf", "", bytes.NewReader(document))
+
+	// assert
+	if err == nil {
+		t.Errorf("Extract should return an error for encrypted PDF documents.")
+	}
+}
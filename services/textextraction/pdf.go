@@ -0,0 +1,410 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textextraction
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfKerningSpace is the (negative) text adjustment in a TJ array that is treated as a space between words.
+const pdfKerningSpace = -200
+
+// extractPDFWithTool returns the text of the given PDF document as extracted by the pdftotext tool at the given path.
+func extractPDFWithTool(toolPath string, data []byte) (string, error) {
+	file, err := ioutil.TempFile("", "allmark-*.pdf")
+	if err != nil {
+		return "", err
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var output, errorOutput bytes.Buffer
+	command := exec.Command(toolPath, "-enc", "UTF-8", "-q", file.Name(), "-")
+	command.Stdout = &output
+	command.Stderr = &errorOutput
+
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %s %s", toolPath, err, strings.TrimSpace(errorOutput.String()))
+	}
+
+	return strings.TrimSpace(output.String()), nil
+}
+
+// extractPDF returns the text which is shown by the text operators of the content streams of the given PDF document.
+// The built-in parser handles uncompressed and Flate-compressed streams and strings in PDFDocEncoding or UTF-16;
+// the text of fonts with custom encodings (e.g. most CID fonts) and of encrypted documents cannot be extracted.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF")) {
+		return "", fmt.Errorf("The file is not a PDF document.")
+	}
+
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", fmt.Errorf("The text of encrypted PDF documents cannot be extracted.")
+	}
+
+	var text strings.Builder
+	for _, stream := range getPDFStreams(data) {
+		extractPDFContentText(stream, &text)
+		if text.Len() > MaximumTextLength {
+			break
+		}
+	}
+
+	return normalizeExtractedText(text.String()), nil
+}
+
+// getPDFStreams returns the decoded streams of the given PDF document which can contain page content.
+func getPDFStreams(data []byte) [][]byte {
+	streams := make([][]byte, 0)
+
+	for position := 0; ; {
+		index := bytes.Index(data[position:], []byte("stream"))
+		if index < 0 {
+			break
+		}
+
+		keyword := position + index
+		position = keyword + len("stream")
+
+		// skip "endstream" and words that merely end with "stream"
+		if keyword > 0 && isPDFRegular(data[keyword-1]) {
+			continue
+		}
+
+		// the stream data starts after the end of the line
+		start := position
+		if start < len(data) && data[start] == '\r' {
+			start++
+		}
+
+		if start < len(data) && data[start] == '\n' {
+			start++
+		}
+
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+
+		end += start
+		position = end + len("endstream")
+
+		// the dictionary of the stream is located between the beginning of the object and the stream keyword
+		dictionaryStart := bytes.LastIndex(data[:keyword], []byte("obj"))
+		if dictionaryStart < 0 {
+			dictionaryStart = 0
+		}
+
+		dictionary := data[dictionaryStart:keyword]
+		content := bytes.TrimRight(data[start:end], "\r\n")
+
+		if stream, isContent := decodePDFStream(dictionary, content); isContent {
+			streams = append(streams, stream)
+		}
+	}
+
+	return streams
+}
+
+// decodePDFStream decodes the given stream data according to the filters of the given stream dictionary.
+// It returns false for streams which cannot contain text operators (e.g. images, fonts and cross-reference streams).
+func decodePDFStream(dictionary, content []byte) ([]byte, bool) {
+	for _, name := range []string{"/Image", "/FontFile", "/Length1", "/XRef", "/ObjStm", "/Metadata", "/EmbeddedFile"} {
+		if bytes.Contains(dictionary, []byte(name)) {
+			return nil, false
+		}
+	}
+
+	if !bytes.Contains(dictionary, []byte("/Filter")) {
+		return content, true
+	}
+
+	// only Flate-compressed streams are supported
+	for _, name := range []string{"/DCTDecode", "/LZWDecode", "/ASCII85Decode", "/ASCIIHexDecode", "/RunLengthDecode", "/JBIG2Decode", "/JPXDecode", "/CCITTFaxDecode", "/Crypt"} {
+		if bytes.Contains(dictionary, []byte(name)) {
+			return nil, false
+		}
+	}
+
+	if !bytes.Contains(dictionary, []byte("/FlateDecode")) {
+		return nil, false
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, false
+	}
+
+	defer reader.Close()
+
+	// use what could be decoded of damaged streams
+	decoded, _ := ioutil.ReadAll(io.LimitReader(reader, 16*MaximumTextLength))
+	return decoded, len(decoded) > 0
+}
+
+// extractPDFContentText writes the strings shown by the text operators (Tj, TJ, ' and ") of the given
+// content stream to the given builder. Text objects are separated by line breaks.
+func extractPDFContentText(content []byte, text *strings.Builder) {
+	var operand strings.Builder
+	isInArray := false
+
+	for position := 0; position < len(content); {
+		character := content[position]
+
+		switch {
+		case isPDFWhitespace(character):
+			position++
+
+		case character == '%':
+			for position < len(content) && content[position] != '\n' && content[position] != '\r' {
+				position++
+			}
+
+		case character == '(':
+			value, end := readPDFLiteralString(content, position+1)
+			operand.WriteString(decodePDFString(value))
+			position = end
+
+		case character == '<' && position+1 < len(content) && content[position+1] == '<':
+			position += 2
+
+		case character == '<':
+			value, end := readPDFHexString(content, position+1)
+			operand.WriteString(decodePDFString(value))
+			position = end
+
+		case character == '>':
+			position++
+
+		case character == '[':
+			isInArray = true
+			position++
+
+		case character == ']':
+			isInArray = false
+			position++
+
+		case character == '/':
+			position++
+			for position < len(content) && isPDFRegular(content[position]) {
+				position++
+			}
+
+		case character == '{' || character == '}' || character == ')':
+			position++
+
+		default:
+			start := position
+			for position < len(content) && isPDFRegular(content[position]) {
+				position++
+			}
+
+			word := string(content[start:position])
+
+			// numbers in TJ arrays adjust the space between characters
+			if number, err := strconv.ParseFloat(word, 64); err == nil {
+				if isInArray && number <= pdfKerningSpace {
+					operand.WriteString(" ")
+				}
+
+				continue
+			}
+
+			switch word {
+			case "Tj", "TJ":
+				text.WriteString(operand.String())
+			case "'", "\"":
+				text.WriteString("\n")
+				text.WriteString(operand.String())
+			case "Td", "TD", "T*", "Tm":
+				text.WriteString(" ")
+			case "ET":
+				text.WriteString("\n")
+			case "BI":
+				position = skipPDFInlineImage(content, position)
+			}
+
+			operand.Reset()
+		}
+	}
+}
+
+// readPDFLiteralString reads the literal string starting at the given position (after the opening parenthesis).
+// It returns the bytes of the string and the position after the closing parenthesis.
+func readPDFLiteralString(content []byte, position int) ([]byte, int) {
+	value := make([]byte, 0)
+	depth := 1
+
+	for position < len(content) {
+		character := content[position]
+		position++
+
+		switch character {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return value, position
+			}
+		case '\\':
+			if position == len(content) {
+				return value, position
+			}
+
+			escaped := content[position]
+			position++
+
+			switch escaped {
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			case 'b':
+				value = append(value, '\b')
+			case 'f':
+				value = append(value, '\f')
+			case '\r':
+				if position < len(content) && content[position] == '\n' {
+					position++
+				}
+			case '\n':
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				code := int(escaped - '0')
+				for digits := 1; digits < 3 && position < len(content) && content[position] >= '0' && content[position] <= '7'; digits++ {
+					code = code*8 + int(content[position]-'0')
+					position++
+				}
+
+				value = append(value, byte(code))
+			default:
+				value = append(value, escaped)
+			}
+
+			continue
+		}
+
+		value = append(value, character)
+	}
+
+	return value, position
+}
+
+// readPDFHexString reads the hexadecimal string starting at the given position (after the opening angle bracket).
+// It returns the bytes of the string and the position after the closing angle bracket.
+func readPDFHexString(content []byte, position int) ([]byte, int) {
+	digits := make([]byte, 0)
+	for position < len(content) && content[position] != '>' {
+		if isHexDigit(content[position]) {
+			digits = append(digits, content[position])
+		}
+
+		position++
+	}
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	value := make([]byte, len(digits)/2)
+	for index := range value {
+		number, _ := strconv.ParseUint(string(digits[index*2:index*2+2]), 16, 8)
+		value[index] = byte(number)
+	}
+
+	return value, position + 1
+}
+
+// decodePDFString decodes the given PDF string (UTF-16 with a byte order mark or PDFDocEncoding).
+// Strings with control characters use a custom font encoding and are ignored.
+func decodePDFString(value []byte) string {
+	if len(value) >= 2 && value[0] == 0xfe && value[1] == 0xff {
+		units := make([]uint16, 0, len(value)/2)
+		for index := 2; index+1 < len(value); index += 2 {
+			units = append(units, uint16(value[index])<<8|uint16(value[index+1]))
+		}
+
+		return string(utf16.Decode(units))
+	}
+
+	characters := make([]rune, 0, len(value))
+	for _, character := range value {
+		if character < 0x20 && character != '\t' && character != '\n' && character != '\r' {
+			return ""
+		}
+
+		characters = append(characters, rune(character))
+	}
+
+	return string(characters)
+}
+
+// skipPDFInlineImage returns the position after the end ("EI") of the inline image whose data starts at the given position.
+func skipPDFInlineImage(content []byte, position int) int {
+	for position < len(content) {
+		index := bytes.Index(content[position:], []byte("EI"))
+		if index < 0 {
+			return len(content)
+		}
+
+		position += index + 2
+		if isPDFWhitespace(content[position-3]) && (position == len(content) || isPDFWhitespace(content[position])) {
+			return position
+		}
+	}
+
+	return position
+}
+
+// normalizeExtractedText collapses the whitespace in each line of the given text and removes empty lines.
+func normalizeExtractedText(text string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isPDFWhitespace returns true if the given character is a PDF white-space character.
+func isPDFWhitespace(character byte) bool {
+	switch character {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+
+	return false
+}
+
+// isPDFRegular returns true if the given character is neither a white-space nor a delimiter character.
+func isPDFRegular(character byte) bool {
+	return !isPDFWhitespace(character) && !strings.ContainsRune("()<>[]{}/%", rune(character))
+}
+
+// isHexDigit returns true if the given character is a hexadecimal digit.
+func isHexDigit(character byte) bool {
+	return (character >= '0' && character <= '9') || (character >= 'a' && character <= 'f') || (character >= 'A' && character <= 'F')
+}
@@ -5,14 +5,18 @@
 package thumbnail
 
 import (
+	"github.com/andreaskoch/allmark/common/budget"
 	"github.com/andreaskoch/allmark/common/logger"
-	// "github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/common/progress"
+	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
 	"github.com/andreaskoch/allmark/dataaccess"
 	"github.com/andreaskoch/allmark/services/imageconversion"
 	"fmt"
 	"io"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -33,6 +37,7 @@ var (
 )
 
 func NewConversionService(logger logger.Logger, repository dataaccess.Repository, thumbnailIndex *Index) *ConversionService {
+	logger = logger.Module("thumbnail")
 
 	// create a new conversion service
 	conversionService := &ConversionService{
@@ -57,6 +62,31 @@ type ConversionService struct {
 
 	index           *Index
 	thumbnailFolder string
+
+	// conversionLock makes sure that a file is not converted by the background
+	// conversion and by CreateThumbnails at the same time
+	conversionLock sync.Mutex
+
+	// conversion status
+	initialConversionCompleted int32
+	pendingItems               int32
+}
+
+// ConversionStatus describes the current state of a thumbnail conversion service.
+type ConversionStatus struct {
+	// InitialConversionCompleted is flag indicating whether all items that existed at startup have been processed.
+	InitialConversionCompleted bool
+
+	// PendingItems is the number of items that are waiting for thumbnail creation.
+	PendingItems int
+}
+
+// Status returns the current state of the conversion service.
+func (conversion *ConversionService) Status() ConversionStatus {
+	return ConversionStatus{
+		InitialConversionCompleted: atomic.LoadInt32(&conversion.initialConversionCompleted) == 1,
+		PendingItems:               int(atomic.LoadInt32(&conversion.pendingItems)),
+	}
 }
 
 // Start the conversion process.
@@ -69,6 +99,8 @@ func (conversion *ConversionService) startConversion() {
 	go func() {
 		for update := range repositoryUpdates {
 
+			atomic.AddInt32(&conversion.pendingItems, int32(len(update.New())+len(update.Modified())))
+
 			// create thumbnails for new items
 			for _, newItemRoute := range update.New() {
 				conversion.createThumbnailsForItem(conversion.repository.Item(newItemRoute))
@@ -88,14 +120,38 @@ func (conversion *ConversionService) startConversion() {
 
 // Process all items in the repository.
 func (conversion *ConversionService) fullConversion() {
-	for _, item := range conversion.repository.Items() {
+	items := conversion.repository.Items()
+	atomic.AddInt32(&conversion.pendingItems, int32(len(items)))
+
+	conversionProgress := progress.Start(conversion.logger, "Thumbnail conversion", "items", len(items))
+	for _, item := range items {
 		conversion.createThumbnailsForItem(item)
+		conversionProgress.Increment()
 	}
+
+	conversionProgress.Finish()
+
+	atomic.StoreInt32(&conversion.initialConversionCompleted, 1)
 }
 
 // Create thumbnail for all image files found in the supplied item.
 func (conversion *ConversionService) createThumbnailsForItem(item dataaccess.Item) {
 
+	defer atomic.AddInt32(&conversion.pendingItems, -1)
+
+	if item == nil {
+		return
+	}
+
+	for _, file := range item.Files() {
+		conversion.createThumbnailsForFile(file)
+	}
+}
+
+// CreateThumbnails creates the thumbnails of the images of the item with the given route right away
+// (e.g. for the items which are pre-rendered after startup) instead of waiting for the background conversion.
+func (conversion *ConversionService) CreateThumbnails(itemRoute route.Route) {
+	item := conversion.repository.Item(itemRoute)
 	if item == nil {
 		return
 	}
@@ -107,6 +163,9 @@ func (conversion *ConversionService) createThumbnailsForItem(item dataaccess.Ite
 
 // Create thumbnail for all image files found in the supplied item.
 func (conversion *ConversionService) createThumbnailsForFile(file dataaccess.File) {
+	conversion.conversionLock.Lock()
+	defer conversion.conversionLock.Unlock()
+
 	conversion.createThumbnail(file, SizeSmall)
 	conversion.createThumbnail(file, SizeMedium)
 	conversion.createThumbnail(file, SizeLarge)
@@ -161,6 +220,9 @@ func (conversion *ConversionService) createThumbnail(file dataaccess.File, dimen
 
 	// convert the image
 	conversionError := file.Data(func(content io.ReadSeeker) error {
+		release := budget.Acquire(budget.TaskThumbnail, imageconversion.EstimateMemory(content))
+		defer release()
+
 		return imageconversion.Resize(content, mimeType, dimensions.MaxWidth, dimensions.MaxHeight, target)
 	})
 
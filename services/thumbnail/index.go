@@ -22,6 +22,7 @@ import (
 var dimensionPattern = regexp.MustCompile(`-maxWidth:(\d+)-maxHeight:(\d+)$`)
 
 func NewIndex(logger logger.Logger, indexFilePath, thumbnailFolder string) *Index {
+	logger = logger.Module("thumbnail")
 
 	// load the index
 	index, err := loadIndex(indexFilePath)
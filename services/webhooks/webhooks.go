@@ -0,0 +1,275 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webhooks posts JSON payloads to the configured webhook URLs whenever items are created,
+// updated or deleted and whenever the repository has been reindexed.
+package webhooks
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/dataaccess"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// EventHeader is the name of the header which contains the event name.
+	EventHeader = "X-Allmark-Event"
+
+	// DeliveryHeader is the name of the header which contains the unique ID of a delivery.
+	DeliveryHeader = "X-Allmark-Delivery"
+
+	// SignatureHeader is the name of the header which contains the signature of the payload ("sha256=<hex>").
+	SignatureHeader = "X-Allmark-Signature"
+
+	// queueSize is the number of events that can wait for delivery per webhook.
+	queueSize = 256
+
+	// maxItemEventsPerIndexRun is the maximum number of item events that are sent for an index run of the whole
+	// repository. If more items have changed, only the repository.indexed event is sent.
+	maxItemEventsPerIndexRun = queueSize / 2
+
+	// maxAttempts is the number of times a delivery is attempted before it is dropped.
+	maxAttempts = 3
+
+	// requestTimeout is the maximum time a webhook may take to respond.
+	requestTimeout = 10 * time.Second
+)
+
+// Event is the JSON payload which is posted to the webhooks.
+type Event struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// the route and path of the item (item events)
+	Route string `json:"route,omitempty"`
+	Path  string `json:"path,omitempty"`
+
+	// the statistics of the index run (repository.indexed)
+	NumberOfItems          int   `json:"numberOfItems,omitempty"`
+	NewItems               int   `json:"newItems,omitempty"`
+	ModifiedItems          int   `json:"modifiedItems,omitempty"`
+	DeletedItems           int   `json:"deletedItems,omitempty"`
+	DurationInMilliseconds int64 `json:"durationInMilliseconds,omitempty"`
+}
+
+// NewService creates a new webhook service which sends the events of the given repository to the configured webhooks.
+func NewService(logger logger.Logger, config config.Config, repository dataaccess.Repository) *Service {
+	logger = logger.Module("webhooks")
+
+	service := &Service{
+		logger:   logger,
+		basePath: config.BasePath(),
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+
+	for _, webhook := range config.Webhooks {
+		if webhook.URL == "" {
+			logger.Warn("Ignoring a webhook without URL.")
+			continue
+		}
+
+		// the secret can reference an environment variable or a file
+		secret, err := config.Secret(webhook.Secret)
+		if err != nil {
+			logger.Warn("Ignoring the webhook %q. Error: %s", webhook.URL, err)
+			continue
+		}
+
+		webhook.Secret = secret
+
+		subscription := &subscription{
+			webhook: webhook,
+			queue:   make(chan Event, queueSize),
+		}
+
+		service.subscriptions = append(service.subscriptions, subscription)
+		go service.deliver(subscription)
+	}
+
+	service.listen(repository)
+
+	return service
+}
+
+// Service sends the events of a repository to webhooks.
+type Service struct {
+	logger logger.Logger
+
+	basePath      string
+	client        *http.Client
+	subscriptions []*subscription
+}
+
+// subscription is a webhook with the queue of the events that are waiting for delivery.
+type subscription struct {
+	webhook config.Webhook
+	queue   chan Event
+}
+
+// listen converts the updates and the indexing results of the given repository into events.
+func (service *Service) listen(repository dataaccess.Repository) {
+
+	updates := make(chan dataaccess.Update, 1)
+	repository.Subscribe(updates)
+
+	go func() {
+		for update := range updates {
+			service.sendUpdateEvents(update)
+		}
+	}()
+
+	indexNotifier, isIndexNotifier := repository.(dataaccess.IndexNotifier)
+	if !isIndexNotifier {
+		return
+	}
+
+	indexingResults := make(chan dataaccess.IndexingResult, 1)
+	indexNotifier.SubscribeToIndexing(indexingResults)
+
+	go func() {
+		for result := range indexingResults {
+			service.Send(Event{
+				Event:                  config.WebhookEventRepositoryIndexed,
+				NumberOfItems:          result.NumberOfItems,
+				NewItems:               len(result.Update.New()),
+				ModifiedItems:          len(result.Update.Modified()),
+				DeletedItems:           len(result.Update.Deleted()),
+				DurationInMilliseconds: result.Duration.Nanoseconds() / int64(time.Millisecond),
+			})
+		}
+	}()
+}
+
+// sendUpdateEvents sends the item events for the given update. The item events of index runs which
+// found more changes than the queues can hold are coalesced into the repository.indexed event.
+func (service *Service) sendUpdateEvents(update dataaccess.Update) {
+	numberOfChanges := len(update.New()) + len(update.Modified()) + len(update.Deleted())
+	if update.IsIndexRun() && numberOfChanges > maxItemEventsPerIndexRun {
+		service.logger.Info("Skipping the item events for %d changed items. The %q event reports the changes of the index run.", numberOfChanges, config.WebhookEventRepositoryIndexed)
+		return
+	}
+
+	service.sendItemEvents(config.WebhookEventItemCreated, update.New())
+	service.sendItemEvents(config.WebhookEventItemUpdated, update.Modified())
+	service.sendItemEvents(config.WebhookEventItemDeleted, update.Deleted())
+}
+
+// sendItemEvents sends an event with the given name for each of the given routes.
+func (service *Service) sendItemEvents(eventName string, routes []route.Route) {
+	for _, itemRoute := range routes {
+		service.Send(Event{
+			Event: eventName,
+			Route: itemRoute.Value(),
+			Path:  service.basePath + itemRoute.Value(),
+		})
+	}
+}
+
+// Send queues the given event for delivery to all webhooks which have subscribed to it.
+// Events are dropped if the queue of a webhook is full.
+func (service *Service) Send(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	for _, subscription := range service.subscriptions {
+		if !subscription.webhook.ReceivesEvent(event.Event) {
+			continue
+		}
+
+		select {
+		case subscription.queue <- event:
+		default:
+			service.logger.Error("Dropping the %q event for the webhook %q because too many events are waiting for delivery.", event.Event, subscription.webhook.URL)
+		}
+	}
+}
+
+// deliver posts the queued events of the given subscription one after another.
+func (service *Service) deliver(subscription *subscription) {
+	for event := range subscription.queue {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			service.logger.Error("Unable to convert the %q event to json. Error: %s", event.Event, err)
+			continue
+		}
+
+		deliveryID := newDeliveryID()
+
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			err = service.post(subscription.webhook, event.Event, deliveryID, payload)
+			if err == nil {
+				service.logger.Debug("Delivered the %q event to the webhook %q.", event.Event, subscription.webhook.URL)
+				break
+			}
+
+			if attempt < maxAttempts {
+				// back off: 1s, 4s, ...
+				time.Sleep(time.Duration(attempt*attempt) * time.Second)
+			}
+		}
+
+		if err != nil {
+			service.logger.Error("Unable to deliver the %q event to the webhook %q after %d attempts. Error: %s", event.Event, subscription.webhook.URL, maxAttempts, err)
+		}
+	}
+}
+
+// post sends the given payload to the given webhook.
+func (service *Service) post(webhook config.Webhook, eventName, deliveryID string, payload []byte) error {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "allmark-webhooks")
+	request.Header.Set(EventHeader, eventName)
+	request.Header.Set(DeliveryHeader, deliveryID)
+
+	if webhook.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(webhook.Secret, payload))
+	}
+
+	response, err := service.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("The webhook responded with the status %q.", response.Status)
+	}
+
+	return nil
+}
+
+// Sign returns the signature of the given payload ("sha256=" followed by the hex-encoded HMAC-SHA256 of the payload).
+// Receivers verify the payloads by comparing the signature with the value of the signature header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID returns a random ID for a delivery.
+func newDeliveryID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,143 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhooks
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/dataaccess"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Sign_SecretAndPayload_HMACSHA256IsReturned(t *testing.T) {
+	// arrange
+	secret := "It's a Secret to Everybody"
+	payload := []byte("Hello, World!")
+
+	// act
+	result := Sign(secret, payload)
+
+	// assert
+	expected := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if result != expected {
+		t.Errorf("Sign should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_Send_SubscribedEvent_SignedPayloadIsPosted(t *testing.T) {
+	// arrange
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+
+	deliveries := make(chan delivery, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		deliveries <- delivery{r.Header, body}
+	}))
+	defer server.Close()
+
+	logger := console.New(loglevel.Off)
+	service := &Service{logger: logger, basePath: "/", client: server.Client()}
+
+	webhook := config.Webhook{URL: server.URL, Secret: "secret", Events: []string{config.WebhookEventItemCreated}}
+	subscription := &subscription{webhook: webhook, queue: make(chan Event, 1)}
+	service.subscriptions = append(service.subscriptions, subscription)
+	go service.deliver(subscription)
+
+	// act
+	service.Send(Event{Event: config.WebhookEventItemDeleted, Route: "documents/old"})
+	service.Send(Event{Event: config.WebhookEventItemCreated, Route: "documents/new"})
+
+	// assert
+	select {
+	case result := <-deliveries:
+		var event Event
+		if err := json.Unmarshal(result.body, &event); err != nil || event.Route != "documents/new" {
+			t.Fatalf("The webhook should receive the event of the new item but received %q.", result.body)
+		}
+
+		if signature := result.header.Get(SignatureHeader); signature != Sign("secret", result.body) {
+			t.Errorf("The payload should be signed but the signature is %q.", signature)
+		}
+
+		if eventName := result.header.Get(EventHeader); eventName != config.WebhookEventItemCreated {
+			t.Errorf("The event header should be %q but is %q.", config.WebhookEventItemCreated, eventName)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The event was not delivered.")
+	}
+}
+
+// getTestRoutes returns the given number of item routes.
+func getTestRoutes(numberOfRoutes int) []route.Route {
+	routes := make([]route.Route, 0, numberOfRoutes)
+	for index := 0; index < numberOfRoutes; index++ {
+		routes = append(routes, route.NewFromRequest(fmt.Sprintf("documents/%d", index)))
+	}
+
+	return routes
+}
+
+func Test_sendUpdateEvents_IndexRunWithManyChanges_ItemEventsAreSkipped(t *testing.T) {
+	// arrange
+	service := &Service{logger: console.New(loglevel.Off), basePath: "/"}
+	subscription := &subscription{webhook: config.Webhook{URL: "http://localhost"}, queue: make(chan Event, queueSize)}
+	service.subscriptions = append(service.subscriptions, subscription)
+
+	update := dataaccess.NewIndexUpdate(getTestRoutes(queueSize+10), nil, nil)
+
+	// act
+	service.sendUpdateEvents(update)
+
+	// assert
+	if len(subscription.queue) != 0 {
+		t.Errorf("No item events should be queued for an index run with %d changes but %d events were queued.", queueSize+10, len(subscription.queue))
+	}
+}
+
+func Test_sendUpdateEvents_IndexRunWithFewChanges_ItemEventsAreQueued(t *testing.T) {
+	// arrange
+	service := &Service{logger: console.New(loglevel.Off), basePath: "/"}
+	subscription := &subscription{webhook: config.Webhook{URL: "http://localhost"}, queue: make(chan Event, queueSize)}
+	service.subscriptions = append(service.subscriptions, subscription)
+
+	update := dataaccess.NewIndexUpdate(getTestRoutes(2), getTestRoutes(1), nil)
+
+	// act
+	service.sendUpdateEvents(update)
+
+	// assert
+	if len(subscription.queue) != 3 {
+		t.Errorf("3 item events should be queued but %d events were queued.", len(subscription.queue))
+	}
+}
+
+func Test_sendUpdateEvents_ManyChangesOutsideOfAnIndexRun_ItemEventsAreQueued(t *testing.T) {
+	// arrange
+	service := &Service{logger: console.New(loglevel.Off), basePath: "/"}
+	subscription := &subscription{webhook: config.Webhook{URL: "http://localhost"}, queue: make(chan Event, queueSize)}
+	service.subscriptions = append(service.subscriptions, subscription)
+
+	update := dataaccess.NewUpdate(nil, nil, getTestRoutes(maxItemEventsPerIndexRun+1))
+
+	// act
+	service.sendUpdateEvents(update)
+
+	// assert
+	if len(subscription.queue) != maxItemEventsPerIndexRun+1 {
+		t.Errorf("%d item events should be queued but %d events were queued.", maxItemEventsPerIndexRun+1, len(subscription.queue))
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package graphql executes GraphQL queries (see https://spec.graphql.org) against objects whose fields are
+// resolved by Go functions. It supports the query language subset that is needed to select fields:
+// named and anonymous queries, variables, arguments, aliases and nested selections.
+// Fragments, directives, mutations, subscriptions and introspection are not supported.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// typeNameField is the meta field which returns the name of the object type.
+const typeNameField = "__typename"
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is the result of a GraphQL request. The data is not set if the request could not be executed.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Error describes why a request or a field failed. The path contains the response keys
+// and list indices of the field that failed (e.g. ["item", "children", 2, "title"]).
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Execute executes the given request. The fields of the given root object are the entry points of the queries.
+// Selections which are nested deeper than the given maximum depth are rejected.
+func Execute(root Object, request Request, maxDepth int) Response {
+	doc, err := parse(request.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	operation, err := getOperation(doc, request.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	variables, err := getVariables(operation, request.Variables)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	if depth := getDepth(operation.selections); depth > maxDepth {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("The query is nested %d levels deep but at most %d levels are allowed.", depth, maxDepth)}}}
+	}
+
+	executor := &executor{variables: variables}
+	data := executor.executeSelections(root, operation.selections, []interface{}{})
+
+	return Response{
+		Data:   data,
+		Errors: executor.errors,
+	}
+}
+
+// getOperation returns the operation with the given name or the only operation of the given document.
+func getOperation(doc *document, operationName string) (*operation, error) {
+	if operationName == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("The query contains %d operations; the operation name must be specified.", len(doc.operations))
+		}
+
+		return doc.operations[0], nil
+	}
+
+	for _, operation := range doc.operations {
+		if operation.name == operationName {
+			return operation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("The query does not contain an operation named %q.", operationName)
+}
+
+// getVariables returns the values of the variables of the given operation.
+func getVariables(operation *operation, values map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+
+	for _, definition := range operation.variables {
+		value, isSet := values[definition.name]
+
+		switch {
+		case isSet && value != nil:
+			variables[definition.name] = value
+
+		case definition.hasDefault:
+			variables[definition.name] = definition.defaultValue
+
+		case definition.isRequired:
+			return nil, fmt.Errorf("The variable $%s is required.", definition.name)
+
+		default:
+			variables[definition.name] = nil
+		}
+	}
+
+	return variables, nil
+}
+
+// getDepth returns how deep the given selections are nested (e.g. 2 for "{ item { title } }").
+func getDepth(selections []*field) int {
+	if len(selections) == 0 {
+		return 0
+	}
+
+	maxChildDepth := 0
+	for _, field := range selections {
+		if childDepth := getDepth(field.selections); childDepth > maxChildDepth {
+			maxChildDepth = childDepth
+		}
+	}
+
+	return maxChildDepth + 1
+}
+
+type executor struct {
+	variables map[string]interface{}
+	errors    []Error
+}
+
+// fail records an error for the field with the given path.
+func (executor *executor) fail(path []interface{}, format string, arguments ...interface{}) {
+	executor.errors = append(executor.errors, Error{
+		Message: fmt.Sprintf(format, arguments...),
+		Path:    path,
+	})
+}
+
+// executeSelections resolves the given fields of the given object.
+func (executor *executor) executeSelections(object Object, selections []*field, path []interface{}) *result {
+	result := &result{}
+
+	for _, field := range selections {
+		key := field.responseKey()
+
+		// fields which are selected more than once are only resolved once
+		if result.has(key) {
+			continue
+		}
+
+		fieldPath := append(append([]interface{}{}, path...), key)
+		result.add(key, executor.executeField(object, field, fieldPath))
+	}
+
+	return result
+}
+
+// executeField resolves the given field of the given object. Failed fields are null.
+func (executor *executor) executeField(object Object, field *field, path []interface{}) interface{} {
+	if field.name == typeNameField {
+		return object.Name
+	}
+
+	definition, exists := object.Fields[field.name]
+	if !exists {
+		executor.fail(path, "The type %q has no field %q.", object.Name, field.name)
+		return nil
+	}
+
+	arguments, err := executor.getArguments(definition, field)
+	if err != nil {
+		executor.fail(path, "%s", err)
+		return nil
+	}
+
+	value, err := definition.Resolve(arguments)
+	if err != nil {
+		executor.fail(path, "%s", err)
+		return nil
+	}
+
+	return executor.completeValue(field, value, path)
+}
+
+// getArguments returns the argument values of the given field; variables are replaced by their values.
+func (executor *executor) getArguments(definition Field, field *field) (Arguments, error) {
+	arguments := make(Arguments)
+
+	for name, value := range field.arguments {
+		isDeclared := false
+		for _, argumentName := range definition.Arguments {
+			if argumentName == name {
+				isDeclared = true
+				break
+			}
+		}
+
+		if !isDeclared {
+			return nil, fmt.Errorf("The field %q has no argument %q.", field.name, name)
+		}
+
+		resolvedValue, err := executor.resolveVariables(value)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments[name] = resolvedValue
+	}
+
+	return arguments, nil
+}
+
+// resolveVariables replaces the variables in the given argument value.
+func (executor *executor) resolveVariables(value interface{}) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case variable:
+		variableValue, isDeclared := executor.variables[string(typedValue)]
+		if !isDeclared {
+			return nil, fmt.Errorf("The variable $%s is not declared.", typedValue)
+		}
+
+		return variableValue, nil
+
+	case []interface{}:
+		list := make([]interface{}, 0, len(typedValue))
+		for _, entry := range typedValue {
+			resolvedEntry, err := executor.resolveVariables(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, resolvedEntry)
+		}
+
+		return list, nil
+
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(typedValue))
+		for name, entry := range typedValue {
+			resolvedEntry, err := executor.resolveVariables(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			object[name] = resolvedEntry
+		}
+
+		return object, nil
+	}
+
+	return value, nil
+}
+
+// completeValue converts the resolved value of the given field into the response value.
+func (executor *executor) completeValue(field *field, value interface{}, path []interface{}) interface{} {
+	if object, isObject := value.(Object); isObject {
+		if len(field.selections) == 0 {
+			executor.fail(path, "The field %q of type %q must have a selection of subfields.", field.name, object.Name)
+			return nil
+		}
+
+		return executor.executeSelections(object, field.selections, path)
+	}
+
+	reflectValue := reflect.ValueOf(value)
+
+	switch reflectValue.Kind() {
+	case reflect.Invalid:
+		return nil
+
+	case reflect.Ptr, reflect.Interface:
+		if reflectValue.IsNil() {
+			return nil
+		}
+
+		return executor.completeValue(field, reflectValue.Elem().Interface(), path)
+
+	case reflect.Slice, reflect.Array:
+		if reflectValue.Kind() == reflect.Slice && reflectValue.IsNil() {
+			return nil
+		}
+
+		list := make([]interface{}, 0, reflectValue.Len())
+		for index := 0; index < reflectValue.Len(); index++ {
+			entryPath := append(append([]interface{}{}, path...), index)
+			list = append(list, executor.completeValue(field, reflectValue.Index(index).Interface(), entryPath))
+		}
+
+		return list
+
+	case reflect.Struct:
+		if _, isTime := value.(time.Time); !isTime {
+			return executor.completeValue(field, ObjectOf(reflectValue.Type().Name(), value), path)
+		}
+	}
+
+	// scalars
+	if len(field.selections) > 0 {
+		executor.fail(path, "The field %q is a scalar and cannot have a selection of subfields.", field.name)
+		return nil
+	}
+
+	return value
+}
+
+// result contains the values of the selected fields in the order of the selection.
+type result struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (result *result) has(key string) bool {
+	_, exists := result.values[key]
+	return exists
+}
+
+func (result *result) add(key string, value interface{}) {
+	if result.values == nil {
+		result.values = make(map[string]interface{})
+	}
+
+	result.keys = append(result.keys, key)
+	result.values[key] = value
+}
+
+// MarshalJSON encodes the result as a JSON object whose properties are in the order of the selection.
+func (result *result) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+
+	for index, key := range result.keys {
+		if index > 0 {
+			buffer.WriteByte(',')
+		}
+
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		valueBytes, err := json.Marshal(result.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(keyBytes)
+		buffer.WriteByte(':')
+		buffer.Write(valueBytes)
+	}
+
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type testFile struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+	internal string
+}
+
+// testRoot returns a root object with an item field; the children of the items are nested items.
+func testRoot() Object {
+	var item func(route string) Object
+	item = func(route string) Object {
+		return NewObject("Item", map[string]Field{
+			"route": Value(route),
+			"files": Value([]testFile{{Name: "image.png", MimeType: "image/png"}}),
+			"children": {
+				Arguments: []string{"limit"},
+				Resolve: func(arguments Arguments) (interface{}, error) {
+					limit, err := arguments.Int("limit", 2)
+					if err != nil {
+						return nil, err
+					}
+
+					children := make([]Object, 0)
+					for index := 1; index <= limit; index++ {
+						children = append(children, item(fmt.Sprintf("%s/%d", route, index)))
+					}
+
+					return children, nil
+				},
+			},
+			"parent": Value(nil),
+			"broken": {
+				Resolve: func(arguments Arguments) (interface{}, error) {
+					return nil, fmt.Errorf("The field is broken.")
+				},
+			},
+		})
+	}
+
+	return NewObject("Query", map[string]Field{
+		"item": {
+			Arguments: []string{"route"},
+			Resolve: func(arguments Arguments) (interface{}, error) {
+				route, err := arguments.String("route", "")
+				return item(route), err
+			},
+		},
+	})
+}
+
+func executeToJSON(t *testing.T, request Request, maxDepth int) string {
+	jsonBytes, err := json.Marshal(Execute(testRoot(), request, maxDepth))
+	if err != nil {
+		t.Fatalf("The response could not be encoded: %s", err)
+	}
+
+	return string(jsonBytes)
+}
+
+func Test_Execute_NestedQuery_FieldsAreReturnedInTheOrderOfTheSelection(t *testing.T) {
+	// arrange
+	request := Request{
+		Query: `{ item(route: "docs") { route __typename children(limit: 1) { route } files { name } parent { route } } }`,
+	}
+
+	// act
+	result := executeToJSON(t, request, 10)
+
+	// assert
+	expected := `{"data":{"item":{"route":"docs","__typename":"Item","children":[{"route":"docs/1"}],"files":[{"name":"image.png"}],"parent":null}}}`
+	if result != expected {
+		t.Errorf("Execute should return\n%s\nbut returned\n%s", expected, result)
+	}
+}
+
+func Test_Execute_VariablesAndAliases_VariablesAreUsed(t *testing.T) {
+	// arrange
+	request := Request{
+		Query:         `query First { a: item { route } } query Second($route: String!, $limit: Int = 1) { b: item(route: $route) { children(limit: $limit) { route } } }`,
+		OperationName: "Second",
+		Variables:     map[string]interface{}{"route": "blog"},
+	}
+
+	// act
+	result := executeToJSON(t, request, 10)
+
+	// assert
+	expected := `{"data":{"b":{"children":[{"route":"blog/1"}]}}}`
+	if result != expected {
+		t.Errorf("Execute should return\n%s\nbut returned\n%s", expected, result)
+	}
+}
+
+func Test_Execute_FieldErrors_FieldIsNullAndErrorContainsThePath(t *testing.T) {
+	// arrange
+	request := Request{
+		Query: `{ item { route broken unknown children(limit: 1) { other } } }`,
+	}
+
+	// act
+	result := executeToJSON(t, request, 10)
+
+	// assert
+	expected := `{"data":{"item":{"route":"","broken":null,"unknown":null,"children":[{"other":null}]}},"errors":[` +
+		`{"message":"The field is broken.","path":["item","broken"]},` +
+		`{"message":"The type \"Item\" has no field \"unknown\".","path":["item","unknown"]},` +
+		`{"message":"The type \"Item\" has no field \"other\".","path":["item","children",0,"other"]}]}`
+
+	if result != expected {
+		t.Errorf("Execute should return\n%s\nbut returned\n%s", expected, result)
+	}
+}
+
+func Test_Execute_InvalidRequests_NoDataIsReturned(t *testing.T) {
+	// arrange
+	requests := []Request{
+		{Query: `{ item { route `},
+		{Query: `query($route: String!) { item(route: $route) { route } }`},
+		{Query: `query A { item { route } } query B { item { route } }`},
+		{Query: `{ item { children { children { route } } } }`},
+	}
+
+	for _, request := range requests {
+
+		// act
+		response := Execute(testRoot(), request, 3)
+
+		// assert
+		if response.Data != nil || len(response.Errors) != 1 {
+			t.Errorf("Execute(%q) should return one error and no data but returned %#v.", request.Query, response)
+		}
+	}
+}
+
+func Test_Execute_MissingOrInvalidSelections_FieldsFail(t *testing.T) {
+	// arrange
+	request := Request{
+		Query: `{ item }  `,
+	}
+
+	// act
+	response := Execute(testRoot(), request, 10)
+
+	// assert
+	if len(response.Errors) != 1 {
+		t.Errorf("Execute should fail for objects without a selection but returned %#v.", response)
+	}
+
+	// act
+	response = Execute(testRoot(), Request{Query: `{ item { route { length } children(page: 1) { route } } }`}, 10)
+
+	// assert
+	if len(response.Errors) != 2 {
+		t.Errorf("Execute should fail for scalars with a selection and for unknown arguments but returned %#v.", response)
+	}
+}
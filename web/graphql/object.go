@@ -0,0 +1,156 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Object is a GraphQL object type with resolvers for its fields.
+type Object struct {
+	Name   string
+	Fields map[string]Field
+}
+
+// Field is a field of an object. It declares the names of its arguments and resolves its value.
+// The value can be an Object, a struct (whose exported fields are selected by their JSON names),
+// a slice of these, a scalar or nil.
+type Field struct {
+	Arguments []string
+	Resolve   func(arguments Arguments) (interface{}, error)
+}
+
+// NewObject creates a new object with the given name and fields.
+func NewObject(name string, fields map[string]Field) Object {
+	return Object{
+		Name:   name,
+		Fields: fields,
+	}
+}
+
+// ObjectOf creates an object with the given name from the exported fields of the given struct.
+// The fields are named like their JSON properties and the fields of embedded structs are added to the object itself.
+// Fields can be added to or replaced in the returned object.
+func ObjectOf(name string, model interface{}) Object {
+	object := NewObject(name, make(map[string]Field))
+
+	value := reflect.Indirect(reflect.ValueOf(model))
+	if value.Kind() == reflect.Struct {
+		addStructFields(object, value)
+	}
+
+	return object
+}
+
+// Value returns a field without arguments which resolves to the given value.
+func Value(value interface{}) Field {
+	return Field{
+		Resolve: func(arguments Arguments) (interface{}, error) {
+			return value, nil
+		},
+	}
+}
+
+// addStructFields adds the given struct's exported fields to the given object.
+func addStructFields(object Object, value reflect.Value) {
+	structType := value.Type()
+
+	for index := 0; index < structType.NumField(); index++ {
+		structField := structType.Field(index)
+
+		tag := structField.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct && tag == "" {
+			addStructFields(object, value.Field(index))
+			continue
+		}
+
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = structField.Name
+		}
+
+		object.Fields[name] = Value(value.Field(index).Interface())
+	}
+}
+
+// Arguments contains the argument values of a field. Variables are already replaced by their values.
+type Arguments map[string]interface{}
+
+// String returns the string argument with the given name or the given default value if the argument is not set.
+func (arguments Arguments) String(name, defaultValue string) (string, error) {
+	value, isSet := arguments[name]
+	if !isSet || value == nil {
+		return defaultValue, nil
+	}
+
+	switch stringValue := value.(type) {
+	case string:
+		return stringValue, nil
+	case enumValue:
+		return string(stringValue), nil
+	}
+
+	return "", fmt.Errorf("The argument %q must be a string.", name)
+}
+
+// Int returns the integer argument with the given name or the given default value if the argument is not set.
+func (arguments Arguments) Int(name string, defaultValue int) (int, error) {
+	value, isSet := arguments[name]
+	if !isSet || value == nil {
+		return defaultValue, nil
+	}
+
+	switch number := value.(type) {
+	case int:
+		return number, nil
+
+	case float64:
+		// JSON encoded variables only contain floats
+		if number == float64(int(number)) {
+			return int(number), nil
+		}
+	}
+
+	return 0, fmt.Errorf("The argument %q must be an integer.", name)
+}
+
+// Strings returns the list of strings with the given name. A single string is treated as a list with one entry.
+func (arguments Arguments) Strings(name string) ([]string, error) {
+	value, isSet := arguments[name]
+	if !isSet || value == nil {
+		return []string{}, nil
+	}
+
+	if _, isString := value.(string); isString {
+		value = []interface{}{value}
+	}
+
+	list, isList := value.([]interface{})
+	if !isList {
+		return nil, fmt.Errorf("The argument %q must be a list of strings.", name)
+	}
+
+	values := make([]string, 0, len(list))
+	for _, entry := range list {
+		stringValue, isString := entry.(string)
+		if !isString {
+			return nil, fmt.Errorf("The argument %q must be a list of strings.", name)
+		}
+
+		values = append(values, stringValue)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,634 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed GraphQL query document.
+type document struct {
+	operations []*operation
+}
+
+// operation is a query (other operation types are not supported).
+type operation struct {
+	name       string
+	variables  []variableDefinition
+	selections []*field
+}
+
+// variableDefinition is a variable of an operation (e.g. `$route: String = "documents"`).
+type variableDefinition struct {
+	name         string
+	isRequired   bool
+	defaultValue interface{}
+	hasDefault   bool
+}
+
+// field is a selected field (e.g. "parent: item(route: $route) { title }").
+type field struct {
+	alias      string
+	name       string
+	arguments  map[string]interface{}
+	selections []*field
+}
+
+// responseKey returns the alias of the field or its name if it has no alias.
+func (field *field) responseKey() string {
+	if field.alias != "" {
+		return field.alias
+	}
+
+	return field.name
+}
+
+// variable is a reference to a variable in an argument value (e.g. "$route").
+type variable string
+
+// enumValue is an unquoted name in an argument value (e.g. "DOCUMENT").
+type enumValue string
+
+// parse parses the given GraphQL query document.
+// Fragments, directives, mutations and subscriptions are not supported.
+func parse(query string) (*document, error) {
+	parser := &parser{lexer: &lexer{source: strings.TrimPrefix(query, "\uFEFF")}}
+	if err := parser.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{}
+	for parser.token.kind != tokenEOF {
+		operation, err := parser.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+
+		doc.operations = append(doc.operations, operation)
+	}
+
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("The query does not contain an operation.")
+	}
+
+	return doc, nil
+}
+
+type parser struct {
+	lexer *lexer
+	token token
+}
+
+// next reads the next token.
+func (parser *parser) next() error {
+	token, err := parser.lexer.next()
+	if err != nil {
+		return err
+	}
+
+	parser.token = token
+	return nil
+}
+
+// is returns true if the current token is the given punctuator or name.
+func (parser *parser) is(kind tokenKind, value string) bool {
+	return parser.token.kind == kind && parser.token.value == value
+}
+
+// expect reads the given punctuator or fails.
+func (parser *parser) expect(value string) error {
+	if !parser.is(tokenPunctuator, value) {
+		return parser.unexpected(fmt.Sprintf("%q", value))
+	}
+
+	return parser.next()
+}
+
+// expectName reads a name or fails.
+func (parser *parser) expectName() (string, error) {
+	if parser.token.kind != tokenName {
+		return "", parser.unexpected("a name")
+	}
+
+	name := parser.token.value
+	return name, parser.next()
+}
+
+// unexpected returns an error for the current token.
+func (parser *parser) unexpected(expected string) error {
+	if parser.token.kind == tokenEOF {
+		return fmt.Errorf("Syntax error at position %d: expected %s but the query ended.", parser.token.position, expected)
+	}
+
+	return fmt.Errorf("Syntax error at position %d: expected %s but found %q.", parser.token.position, expected, parser.token.value)
+}
+
+func (parser *parser) parseOperation() (*operation, error) {
+	operation := &operation{}
+
+	// query shorthand ("{ ... }")
+	if parser.is(tokenPunctuator, "{") {
+		selections, err := parser.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+
+		operation.selections = selections
+		return operation, nil
+	}
+
+	switch {
+	case parser.is(tokenName, "query"):
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+
+	case parser.is(tokenName, "mutation"), parser.is(tokenName, "subscription"):
+		return nil, fmt.Errorf("The operation type %q is not supported.", parser.token.value)
+
+	case parser.is(tokenName, "fragment"):
+		return nil, fmt.Errorf("Fragments are not supported.")
+
+	default:
+		return nil, parser.unexpected("an operation")
+	}
+
+	if parser.token.kind == tokenName {
+		operation.name = parser.token.value
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if parser.is(tokenPunctuator, "(") {
+		variables, err := parser.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+
+		operation.variables = variables
+	}
+
+	if parser.is(tokenPunctuator, "@") {
+		return nil, fmt.Errorf("Directives are not supported.")
+	}
+
+	selections, err := parser.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	operation.selections = selections
+	return operation, nil
+}
+
+func (parser *parser) parseVariableDefinitions() ([]variableDefinition, error) {
+	if err := parser.expect("("); err != nil {
+		return nil, err
+	}
+
+	variables := make([]variableDefinition, 0)
+	for !parser.is(tokenPunctuator, ")") {
+		if err := parser.expect("$"); err != nil {
+			return nil, err
+		}
+
+		name, err := parser.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := parser.expect(":"); err != nil {
+			return nil, err
+		}
+
+		isRequired, err := parser.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		definition := variableDefinition{name: name, isRequired: isRequired}
+
+		if parser.is(tokenPunctuator, "=") {
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+
+			defaultValue, err := parser.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+
+			definition.defaultValue = defaultValue
+			definition.hasDefault = true
+		}
+
+		variables = append(variables, definition)
+	}
+
+	return variables, parser.expect(")")
+}
+
+// parseType reads a type reference (e.g. "[String!]!") and returns whether it is non-null.
+// The types themselves are not checked; the resolvers validate their arguments.
+func (parser *parser) parseType() (isRequired bool, err error) {
+	if parser.is(tokenPunctuator, "[") {
+		if err := parser.next(); err != nil {
+			return false, err
+		}
+
+		if _, err := parser.parseType(); err != nil {
+			return false, err
+		}
+
+		if err := parser.expect("]"); err != nil {
+			return false, err
+		}
+
+	} else if _, err := parser.expectName(); err != nil {
+		return false, err
+	}
+
+	if parser.is(tokenPunctuator, "!") {
+		return true, parser.next()
+	}
+
+	return false, nil
+}
+
+func (parser *parser) parseSelectionSet() ([]*field, error) {
+	if err := parser.expect("{"); err != nil {
+		return nil, err
+	}
+
+	selections := make([]*field, 0)
+	for !parser.is(tokenPunctuator, "}") {
+		if parser.is(tokenPunctuator, "...") {
+			return nil, fmt.Errorf("Fragments are not supported.")
+		}
+
+		field, err := parser.parseField()
+		if err != nil {
+			return nil, err
+		}
+
+		selections = append(selections, field)
+	}
+
+	if len(selections) == 0 {
+		return nil, parser.unexpected("a field")
+	}
+
+	return selections, parser.expect("}")
+}
+
+func (parser *parser) parseField() (*field, error) {
+	name, err := parser.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &field{name: name}
+
+	if parser.is(tokenPunctuator, ":") {
+		if err := parser.next(); err != nil {
+			return nil, err
+		}
+
+		field.alias = name
+		if field.name, err = parser.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if parser.is(tokenPunctuator, "(") {
+		if field.arguments, err = parser.parseArguments(); err != nil {
+			return nil, err
+		}
+	}
+
+	if parser.is(tokenPunctuator, "@") {
+		return nil, fmt.Errorf("Directives are not supported.")
+	}
+
+	if parser.is(tokenPunctuator, "{") {
+		if field.selections, err = parser.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (parser *parser) parseArguments() (map[string]interface{}, error) {
+	if err := parser.expect("("); err != nil {
+		return nil, err
+	}
+
+	arguments := make(map[string]interface{})
+	for !parser.is(tokenPunctuator, ")") {
+		name, err := parser.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := parser.expect(":"); err != nil {
+			return nil, err
+		}
+
+		value, err := parser.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+
+		arguments[name] = value
+	}
+
+	return arguments, parser.expect(")")
+}
+
+// parseValue reads an argument value. Default values of variables (isConstant) must not contain variables.
+func (parser *parser) parseValue(isConstant bool) (interface{}, error) {
+	token := parser.token
+
+	switch token.kind {
+	case tokenPunctuator:
+		switch token.value {
+		case "$":
+			if isConstant {
+				return nil, parser.unexpected("a constant value")
+			}
+
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+
+			name, err := parser.expectName()
+			return variable(name), err
+
+		case "[":
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+
+			list := make([]interface{}, 0)
+			for !parser.is(tokenPunctuator, "]") {
+				value, err := parser.parseValue(isConstant)
+				if err != nil {
+					return nil, err
+				}
+
+				list = append(list, value)
+			}
+
+			return list, parser.next()
+
+		case "{":
+			if err := parser.next(); err != nil {
+				return nil, err
+			}
+
+			object := make(map[string]interface{})
+			for !parser.is(tokenPunctuator, "}") {
+				name, err := parser.expectName()
+				if err != nil {
+					return nil, err
+				}
+
+				if err := parser.expect(":"); err != nil {
+					return nil, err
+				}
+
+				if object[name], err = parser.parseValue(isConstant); err != nil {
+					return nil, err
+				}
+			}
+
+			return object, parser.next()
+		}
+
+	case tokenInt:
+		value, err := strconv.Atoi(token.value)
+		if err != nil {
+			return nil, fmt.Errorf("Syntax error at position %d: %q is not a valid integer.", token.position, token.value)
+		}
+
+		return value, parser.next()
+
+	case tokenFloat:
+		value, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Syntax error at position %d: %q is not a valid number.", token.position, token.value)
+		}
+
+		return value, parser.next()
+
+	case tokenString:
+		return token.value, parser.next()
+
+	case tokenName:
+		switch token.value {
+		case "true":
+			return true, parser.next()
+
+		case "false":
+			return false, parser.next()
+
+		case "null":
+			return nil, parser.next()
+		}
+
+		return enumValue(token.value), parser.next()
+	}
+
+	return nil, parser.unexpected("a value")
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind     tokenKind
+	value    string
+	position int
+}
+
+// lexer splits a GraphQL document into tokens. Whitespace, commas and comments are skipped.
+type lexer struct {
+	source   string
+	position int
+}
+
+func (lexer *lexer) next() (token, error) {
+
+	// skip ignored characters
+	for lexer.position < len(lexer.source) {
+		character := lexer.source[lexer.position]
+
+		if character == '#' {
+			for lexer.position < len(lexer.source) && lexer.source[lexer.position] != '\n' {
+				lexer.position++
+			}
+
+			continue
+		}
+
+		if character != ' ' && character != '\t' && character != '\n' && character != '\r' && character != ',' {
+			break
+		}
+
+		lexer.position++
+	}
+
+	start := lexer.position
+	if start >= len(lexer.source) {
+		return token{kind: tokenEOF, position: start}, nil
+	}
+
+	character := lexer.source[start]
+	switch {
+	case strings.HasPrefix(lexer.source[start:], "..."):
+		lexer.position += 3
+		return token{kind: tokenPunctuator, value: "...", position: start}, nil
+
+	case strings.IndexByte("!$()[]{}:=@|&", character) >= 0:
+		lexer.position++
+		return token{kind: tokenPunctuator, value: string(character), position: start}, nil
+
+	case character == '_' || isLetter(character):
+		for lexer.position < len(lexer.source) && (lexer.source[lexer.position] == '_' || isLetter(lexer.source[lexer.position]) || isDigit(lexer.source[lexer.position])) {
+			lexer.position++
+		}
+
+		return token{kind: tokenName, value: lexer.source[start:lexer.position], position: start}, nil
+
+	case character == '-' || isDigit(character):
+		return lexer.readNumber()
+
+	case character == '"':
+		return lexer.readString()
+	}
+
+	_, size := utf8.DecodeRuneInString(lexer.source[start:])
+	return token{}, fmt.Errorf("Syntax error at position %d: unexpected character %q.", start, lexer.source[start:start+size])
+}
+
+func (lexer *lexer) readNumber() (token, error) {
+	start := lexer.position
+	kind := tokenInt
+
+	if lexer.source[lexer.position] == '-' {
+		lexer.position++
+	}
+
+	lexer.skipDigits()
+
+	if lexer.position < len(lexer.source) && lexer.source[lexer.position] == '.' {
+		kind = tokenFloat
+		lexer.position++
+		lexer.skipDigits()
+	}
+
+	if lexer.position < len(lexer.source) && (lexer.source[lexer.position] == 'e' || lexer.source[lexer.position] == 'E') {
+		kind = tokenFloat
+		lexer.position++
+
+		if lexer.position < len(lexer.source) && (lexer.source[lexer.position] == '+' || lexer.source[lexer.position] == '-') {
+			lexer.position++
+		}
+
+		lexer.skipDigits()
+	}
+
+	return token{kind: kind, value: lexer.source[start:lexer.position], position: start}, nil
+}
+
+func (lexer *lexer) skipDigits() {
+	for lexer.position < len(lexer.source) && isDigit(lexer.source[lexer.position]) {
+		lexer.position++
+	}
+}
+
+// readString reads a quoted string. Block strings ("""...""") are not supported.
+func (lexer *lexer) readString() (token, error) {
+	start := lexer.position
+	lexer.position++
+
+	var value strings.Builder
+	for lexer.position < len(lexer.source) {
+		character := lexer.source[lexer.position]
+
+		switch character {
+		case '"':
+			lexer.position++
+			return token{kind: tokenString, value: value.String(), position: start}, nil
+
+		case '\n', '\r':
+			return token{}, fmt.Errorf("Syntax error at position %d: the string is not terminated.", start)
+
+		case '\\':
+			if lexer.position+1 >= len(lexer.source) {
+				return token{}, fmt.Errorf("Syntax error at position %d: the string is not terminated.", start)
+			}
+
+			escapedCharacter := lexer.source[lexer.position+1]
+			lexer.position += 2
+
+			switch escapedCharacter {
+			case '"', '\\', '/':
+				value.WriteByte(escapedCharacter)
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case 'u':
+				if lexer.position+4 > len(lexer.source) {
+					return token{}, fmt.Errorf("Syntax error at position %d: invalid unicode escape sequence.", lexer.position)
+				}
+
+				codePoint, err := strconv.ParseUint(lexer.source[lexer.position:lexer.position+4], 16, 32)
+				if err != nil {
+					return token{}, fmt.Errorf("Syntax error at position %d: invalid unicode escape sequence.", lexer.position)
+				}
+
+				value.WriteRune(rune(codePoint))
+				lexer.position += 4
+			default:
+				return token{}, fmt.Errorf("Syntax error at position %d: invalid escape sequence %q.", lexer.position-2, lexer.source[lexer.position-2:lexer.position])
+			}
+
+		default:
+			value.WriteByte(character)
+			lexer.position++
+		}
+	}
+
+	return token{}, fmt.Errorf("Syntax error at position %d: the string is not terminated.", start)
+}
+
+func isLetter(character byte) bool {
+	return (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z')
+}
+
+func isDigit(character byte) bool {
+	return character >= '0' && character <= '9'
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"testing"
+)
+
+func Test_parse_QueryShorthand_SelectionsAreParsed(t *testing.T) {
+	// arrange
+	query := `{ item(route: "documents") { title children { route } } }`
+
+	// act
+	result, err := parse(query)
+
+	// assert
+	if err != nil {
+		t.Fatalf("parse(%q) should not fail but returned: %s", query, err)
+	}
+
+	selections := result.operations[0].selections
+	if len(selections) != 1 || selections[0].name != "item" || selections[0].arguments["route"] != "documents" {
+		t.Fatalf("parse(%q) should return the item field with the route argument but returned %#v.", query, selections)
+	}
+
+	if children := selections[0].selections; len(children) != 2 || children[1].name != "children" || children[1].selections[0].name != "route" {
+		t.Errorf("parse(%q) should return the nested selections but returned %#v.", query, children)
+	}
+}
+
+func Test_parse_NamedQueryWithVariablesAndAliases_OperationIsParsed(t *testing.T) {
+	// arrange
+	query := `
+		# the first page
+		query FirstPage($pageSize: Int = 10, $tags: [String!]!) {
+			first: items(page: 1, pageSize: $pageSize, tags: $tags, ratio: -1.5e2) { totalItems }
+		}`
+
+	// act
+	result, err := parse(query)
+
+	// assert
+	if err != nil {
+		t.Fatalf("parse should not fail but returned: %s", err)
+	}
+
+	operation := result.operations[0]
+	if operation.name != "FirstPage" || len(operation.variables) != 2 {
+		t.Fatalf("parse should return the operation FirstPage with two variables but returned %#v.", operation)
+	}
+
+	if pageSize := operation.variables[0]; !pageSize.hasDefault || pageSize.defaultValue != 10 || pageSize.isRequired {
+		t.Errorf("The variable $pageSize should be optional with the default value 10 but is %#v.", pageSize)
+	}
+
+	if tags := operation.variables[1]; !tags.isRequired {
+		t.Errorf("The variable $tags should be required.")
+	}
+
+	field := operation.selections[0]
+	if field.alias != "first" || field.name != "items" || field.responseKey() != "first" {
+		t.Errorf("The field should be items with the alias first but is %#v.", field)
+	}
+
+	if field.arguments["page"] != 1 || field.arguments["pageSize"] != variable("pageSize") || field.arguments["ratio"] != -150.0 {
+		t.Errorf("The arguments of the field are not parsed correctly: %#v", field.arguments)
+	}
+}
+
+func Test_parse_StringWithEscapeSequences_StringIsUnescaped(t *testing.T) {
+	// arrange
+	query := `{ search(query: "\"go\"\tweb é") { totalHits } }`
+
+	// act
+	result, err := parse(query)
+
+	// assert
+	if err != nil {
+		t.Fatalf("parse should not fail but returned: %s", err)
+	}
+
+	expected := "\"go\"\tweb é"
+	if value := result.operations[0].selections[0].arguments["query"]; value != expected {
+		t.Errorf("The query argument should be %q but is %q.", expected, value)
+	}
+}
+
+func Test_parse_UnsupportedOrInvalidQueries_ErrorIsReturned(t *testing.T) {
+	// arrange
+	queries := []string{
+		``,
+		`{ }`,
+		`{ item { title }`,
+		`{ item(route: ) { title } }`,
+		`{ item(route: "documents) { title } }`,
+		`mutation { deleteItem(route: "documents") }`,
+		`{ item { ...ItemFields } }`,
+		`{ item @include(if: true) { title } }`,
+	}
+
+	for _, query := range queries {
+
+		// act
+		_, err := parse(query)
+
+		// assert
+		if err == nil {
+			t.Errorf("parse(%q) should fail.", query)
+		}
+	}
+}
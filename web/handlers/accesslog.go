@@ -0,0 +1,72 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/accesslog"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AccessLog writes access log entries in a given format (e.g. "combined", "json") to a writer.
+type AccessLog struct {
+	sync.Mutex
+
+	writer io.Writer
+	format string
+}
+
+// NewAccessLog creates a new access log which writes entries in the given format to the given writer.
+func NewAccessLog(writer io.Writer, format string) *AccessLog {
+	return &AccessLog{
+		writer: writer,
+		format: format,
+	}
+}
+
+// Write adds the given entry to the access log.
+func (accessLog *AccessLog) Write(entry accesslog.Entry) {
+	line := accesslog.Format(accessLog.format, entry)
+
+	accessLog.Lock()
+	defer accessLog.Unlock()
+	fmt.Fprintln(accessLog.writer, line)
+}
+
+// LogRequests writes an entry to the given access log for every request to the given handler.
+// If the access log is nil the handler is returned unchanged.
+func LogRequests(accessLog *AccessLog, baseHandler http.Handler) http.Handler {
+	if accessLog == nil {
+		return baseHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		startTime := time.Now()
+		statusWriter := newStatusRecordingResponseWriter(w)
+
+		baseHandler.ServeHTTP(statusWriter, r)
+
+		username, _, _ := r.BasicAuth()
+
+		accessLog.Write(accesslog.Entry{
+			Time:       startTime,
+			RemoteAddr: getClientAddress(r),
+			User:       username,
+			Method:     r.Method,
+			URI:        r.RequestURI,
+			Protocol:   r.Proto,
+			Status:     statusWriter.statusCode,
+			Bytes:      statusWriter.bytesWritten,
+			Latency:    time.Since(startTime),
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+			RequestID:  GetRequestID(r),
+		})
+	})
+}
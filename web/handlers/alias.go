@@ -64,8 +64,8 @@ func AliasIndex(
 		}
 
 		// assemble the base view model
-		title := "Shortlinks"
-		description := "A list of all short links to different items in this repository."
+		title := templateProvider.Label("", "aliasindex.title")
+		description := templateProvider.Label("", "aliasindex.description")
 		viewModel := viewmodel.Model{}
 
 		viewModel.Type = "aliasindex"
@@ -78,7 +78,7 @@ func AliasIndex(
 		// assemble the specialized alias index viewmodel
 		aliasIndexViewModel := viewmodel.AliasIndex{}
 		aliasIndexViewModel.Model = viewModel
-		aliasIndexViewModel.Aliases = aliasIndexOrchestrator.GetIndexEntries(hostname, "!")
+		aliasIndexViewModel.Aliases = aliasIndexOrchestrator.GetIndexEntries(hostname, "!", aliasIndexOrchestrator.GetVisibility(getUsername(r)))
 
 		renderTemplate(aliasIndexTemplate, aliasIndexViewModel, w)
 
@@ -0,0 +1,247 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// apiDefaultPageSize is the number of items per page if the client doesn't specify a page size.
+	apiDefaultPageSize = 20
+
+	// apiMaxPageSize is the maximum number of items per page.
+	apiMaxPageSize = 100
+)
+
+// APIItems returns a handler which lists all items page by page (e.g. "/api/v1/items?page=2&pageSize=50").
+func APIItems(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		page, pageSize, err := getAPIPaging(r)
+		if err != nil {
+			writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeAPIResponse(logger, headerWriter, w, r, apiOrchestrator.GetItems(pageSize, page, apiOrchestrator.GetVisibility(getUsername(r))))
+	})
+}
+
+// APIItem returns a handler which returns a single item including its content, children and files
+// (e.g. "/api/v1/items/documents/Sample-Document"; "/api/v1/items/" returns the repository root).
+func APIItem(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		// get the item route
+		path := strings.TrimPrefix(r.URL.Path, APIItemsRoutePrefix)
+		requestRoute := route.NewFromRequest(path)
+
+		itemModel, found := apiOrchestrator.GetItem(requestRoute, apiOrchestrator.GetVisibility(getUsername(r)))
+		if !found {
+			writeAPIError(logger, headerWriter, w, r, http.StatusNotFound, fmt.Sprintf("The item %q was not found.", requestRoute.Value()))
+			return
+		}
+
+		writeAPIResponse(logger, headerWriter, w, r, itemModel)
+	})
+}
+
+// APISearch returns a handler which returns the items matching a search query page by page
+// (e.g. "/api/v1/search?q=allmark&tag=Documentation&type=document&page=2").
+// The "tag" and "type" parameters can be repeated: the items must have all of the given tags and one of the given types.
+// Instead of a page number the position of the first hit can be given as an "offset" or as the "cursor" of the previous page.
+func APISearch(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, "The search query (q) must not be empty.")
+			return
+		}
+
+		page, pageSize, err := getAPIPaging(r)
+		if err != nil {
+			writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		filter := orchestrator.APISearchFilter{
+			Tags:  r.URL.Query()["tag"],
+			Types: r.URL.Query()["type"],
+		}
+
+		offset := pageSize * (page - 1)
+		if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+			if offset, err = strconv.Atoi(offsetParam); err != nil || offset < 0 {
+				writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, "The offset must be a number which is not negative.")
+				return
+			}
+		}
+
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			if offset, err = apiOrchestrator.ParseSearchCursor(cursor, query, filter, pageSize); err != nil {
+				writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		writeAPIResponse(logger, headerWriter, w, r, apiOrchestrator.Search(query, filter, offset, pageSize, apiOrchestrator.GetVisibility(getUsername(r))))
+	})
+}
+
+// APITags returns a handler which lists all tags with the number of items that are tagged with them.
+func APITags(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		writeAPIResponse(logger, headerWriter, w, r, apiOrchestrator.GetTags(apiOrchestrator.GetVisibility(getUsername(r))))
+	})
+}
+
+// APITag returns a handler which returns a tag with all items that are tagged with it (e.g. "/api/v1/tags/Documentation").
+func APITag(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		tag := strings.TrimPrefix(r.URL.Path, APITagsRoutePrefix)
+
+		tagModel, found := apiOrchestrator.GetTag(tag, apiOrchestrator.GetVisibility(getUsername(r)))
+		if !found {
+			writeAPIError(logger, headerWriter, w, r, http.StatusNotFound, fmt.Sprintf("The tag %q was not found.", tag))
+			return
+		}
+
+		writeAPIResponse(logger, headerWriter, w, r, tagModel)
+	})
+}
+
+// APITree returns a handler which returns the hierarchy of all items.
+func APITree(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		writeAPIResponse(logger, headerWriter, w, r, apiOrchestrator.GetTree(apiOrchestrator.GetVisibility(getUsername(r))))
+	})
+}
+
+// APIGraph returns a handler which returns all items and the links between them.
+func APIGraph(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		writeAPIResponse(logger, headerWriter, w, r, apiOrchestrator.GetGraph(apiOrchestrator.GetVisibility(getUsername(r))))
+	})
+}
+
+// getAPIPaging returns the page and the page size requested with the "page" and "pageSize" parameters of the given request.
+func getAPIPaging(r *http.Request) (page, pageSize int, err error) {
+	page = 1
+	if pageParam, isAvailable := getPageParameterFromURL(*r.URL); isAvailable {
+		if pageParam < 1 {
+			return 0, 0, fmt.Errorf("The page must be a positive number.")
+		}
+
+		page = pageParam
+	}
+
+	pageSize = apiDefaultPageSize
+	if pageSizeParam := r.URL.Query().Get("pageSize"); pageSizeParam != "" {
+		parsedPageSize, err := strconv.Atoi(pageSizeParam)
+		if err != nil || parsedPageSize < 1 || parsedPageSize > apiMaxPageSize {
+			return 0, 0, fmt.Errorf("The page size must be a number between 1 and %d.", apiMaxPageSize)
+		}
+
+		pageSize = parsedPageSize
+	}
+
+	return page, pageSize, nil
+}
+
+// writeAPIResponse writes the given model as JSON with an entity tag.
+func writeAPIResponse(logger logger.Logger, headerWriter header.HeaderWriter, w http.ResponseWriter, r *http.Request, model interface{}) {
+	jsonBytes, err := json.MarshalIndent(model, "", "\t")
+	if err != nil {
+		logger.Error("Unable to convert the API model to json. Error: %s", err)
+		writeAPIError(logger, headerWriter, w, r, http.StatusInternalServerError, "The response could not be created.")
+		return
+	}
+
+	headerWriter.Write(w, header.CONTENTTYPE_JSON)
+
+	// etag cache validator
+	if etag := hashutil.FromBytes(jsonBytes); etag != "" {
+		header.ETag(w, etag)
+	}
+
+	// the client already has the current version
+	if header.NotModified(w, r) {
+		return
+	}
+
+	w.Write(jsonBytes)
+}
+
+// writeAPIError writes a JSON error response with the given status code and message.
+func writeAPIError(logger logger.Logger, headerWriter header.HeaderWriter, w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	jsonBytes, err := json.MarshalIndent(viewmodel.APIError{
+		Status: statusCode,
+		Error:  message,
+	}, "", "\t")
+
+	if err != nil {
+		logger.Error("Unable to convert the API error to json. Error: %s", err)
+		http.Error(w, message, statusCode)
+		return
+	}
+
+	// errors must not be cached
+	headerWriter.Write(w, header.CONTENTTYPE_JSON)
+	header.NoCache(w)
+
+	w.WriteHeader(statusCode)
+	w.Write(jsonBytes)
+}
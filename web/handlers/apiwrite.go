@@ -0,0 +1,232 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/dataaccess"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// contentTypeMarkdown is the content type of the markdown documents which are sent to the write API.
+// POST requests must use it, so that browsers can't send them from other sites without a CORS preflight.
+const contentTypeMarkdown = "text/markdown"
+
+// DispatchWriteRequests passes PUT, POST and DELETE requests to the given write handler
+// and all other requests to the given read handler.
+func DispatchWriteRequests(readHandler, writeHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut, http.MethodPost, http.MethodDelete:
+			writeHandler.ServeHTTP(w, r)
+
+		default:
+			readHandler.ServeHTTP(w, r)
+		}
+	})
+}
+
+// APIWrite returns a handler which creates, updates and deletes items and their files:
+//
+//   - PUT "/api/v1/items/{route}" creates or updates the markdown document of an item
+//   - POST "/api/v1/items/{route}" creates a new item
+//   - DELETE "/api/v1/items/{route}" deletes an item which has no children
+//   - PUT "/api/v1/items/{route}/files/{path}" creates or updates a file of an item
+//   - DELETE "/api/v1/items/{route}/files/{path}" deletes a file of an item
+//
+// The If-Match header (the hash of the current version) and the If-None-Match header ("*") can be used
+// to make sure that other changes are not overwritten. Items which the user cannot see are not found.
+func APIWrite(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator, maxUploadSize int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		// limit the size of the request body
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+		itemRoute, filePath, err := getAPIWriteTarget(strings.TrimPrefix(r.URL.Path, APIItemsRoutePrefix))
+		if err != nil {
+			writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// the items which the user cannot see can neither be modified nor read back
+		isVisible := func() bool {
+			return apiOrchestrator.GetVisibility(getUsername(r)).IsVisible(itemRoute)
+		}
+
+		if !isVisible() {
+			writeAPIError(logger, headerWriter, w, r, http.StatusNotFound, fmt.Sprintf("The item %q was not found.", itemRoute.Value()))
+			return
+		}
+
+		precondition := dataaccess.Precondition{
+			MatchingHashes: header.EntityTags(r.Header.Get("If-Match")),
+			MustNotExist:   strings.TrimSpace(r.Header.Get("If-None-Match")) == "*",
+		}
+
+		// files
+		if filePath != "" {
+			switch r.Method {
+			case http.MethodPut:
+				apiFile, created, err := apiOrchestrator.SaveFile(itemRoute, filePath, r.Body, precondition)
+				if err != nil {
+					writeAPIWriteError(logger, headerWriter, w, r, err)
+					return
+				}
+
+				logger.Info("Saved file %q of item %q.", filePath, itemRoute.Value())
+				if !isVisible() {
+					writeAPIError(logger, headerWriter, w, r, http.StatusNotFound, fmt.Sprintf("The item %q was not found.", itemRoute.Value()))
+					return
+				}
+
+				writeAPIWriteResponse(logger, headerWriter, w, r, created, apiFile)
+
+			case http.MethodDelete:
+				if err := apiOrchestrator.DeleteFile(itemRoute, filePath, precondition); err != nil {
+					writeAPIWriteError(logger, headerWriter, w, r, err)
+					return
+				}
+
+				logger.Info("Deleted file %q of item %q.", filePath, itemRoute.Value())
+				w.WriteHeader(http.StatusNoContent)
+
+			default:
+				w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+				writeAPIError(logger, headerWriter, w, r, http.StatusMethodNotAllowed, fmt.Sprintf("Files cannot be modified with %s requests.", r.Method))
+			}
+
+			return
+		}
+
+		// items
+		switch r.Method {
+		case http.MethodPut, http.MethodPost:
+			if r.Method == http.MethodPost {
+				if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != contentTypeMarkdown {
+					writeAPIError(logger, headerWriter, w, r, http.StatusUnsupportedMediaType, fmt.Sprintf("New items must be sent with the content type %q.", contentTypeMarkdown))
+					return
+				}
+
+				// POST only creates new items
+				precondition.MustNotExist = true
+			}
+
+			markdown, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				writeAPIWriteError(logger, headerWriter, w, r, err)
+				return
+			}
+
+			apiItem, created, err := apiOrchestrator.SaveItem(itemRoute, markdown, precondition)
+			if err != nil {
+				if r.Method == http.MethodPost && errors.Is(err, dataaccess.ErrPreconditionFailed) && len(precondition.MatchingHashes) == 0 {
+					err = fmt.Errorf("%w: The item %q already exists.", dataaccess.ErrConflict, itemRoute.Value())
+				}
+
+				writeAPIWriteError(logger, headerWriter, w, r, err)
+				return
+			}
+
+			logger.Info("Saved item %q.", itemRoute.Value())
+			if !isVisible() {
+				writeAPIError(logger, headerWriter, w, r, http.StatusNotFound, fmt.Sprintf("The item %q was not found.", itemRoute.Value()))
+				return
+			}
+
+			writeAPIWriteResponse(logger, headerWriter, w, r, created, apiItem)
+
+		case http.MethodDelete:
+			if err := apiOrchestrator.DeleteItem(itemRoute, precondition); err != nil {
+				writeAPIWriteError(logger, headerWriter, w, r, err)
+				return
+			}
+
+			logger.Info("Deleted item %q.", itemRoute.Value())
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// getAPIWriteTarget returns the item route and the (slash-separated) file path that are addressed by the given
+// path of a write API request (e.g. "documents/Sample-Document/files/image.png" → "documents/Sample-Document", "image.png").
+// The file path is empty if the path addresses an item.
+func getAPIWriteTarget(path string) (itemRoute route.Route, filePath string, err error) {
+	components := strings.Split(strings.Trim(path, "/"), "/")
+
+	for index, component := range components {
+		if !strings.EqualFold(component, config.FilesDirectoryName) {
+			continue
+		}
+
+		filePath = strings.Join(components[index+1:], "/")
+		if filePath == "" {
+			return itemRoute, "", fmt.Errorf("No file name was specified.")
+		}
+
+		return route.NewFromRequest(strings.Join(components[:index], "/")), filePath, nil
+	}
+
+	return route.NewFromRequest(path), "", nil
+}
+
+// writeAPIWriteResponse writes the given model of a created or updated item or file as JSON.
+func writeAPIWriteResponse(logger logger.Logger, headerWriter header.HeaderWriter, w http.ResponseWriter, r *http.Request, created bool, model interface{}) {
+	jsonBytes, err := json.MarshalIndent(model, "", "\t")
+	if err != nil {
+		logger.Error("Unable to convert the API model to json. Error: %s", err)
+		writeAPIError(logger, headerWriter, w, r, http.StatusInternalServerError, "The response could not be created.")
+		return
+	}
+
+	headerWriter.Write(w, header.CONTENTTYPE_JSON)
+	header.NoCache(w)
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	w.Write(jsonBytes)
+}
+
+// writeAPIWriteError writes the JSON error response for the given error of a write operation.
+func writeAPIWriteError(logger logger.Logger, headerWriter header.HeaderWriter, w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, dataaccess.ErrNotFound):
+		writeAPIError(logger, headerWriter, w, r, http.StatusNotFound, err.Error())
+
+	case errors.Is(err, dataaccess.ErrConflict):
+		writeAPIError(logger, headerWriter, w, r, http.StatusConflict, err.Error())
+
+	case errors.Is(err, dataaccess.ErrPreconditionFailed):
+		writeAPIError(logger, headerWriter, w, r, http.StatusPreconditionFailed, err.Error())
+
+	case errors.Is(err, dataaccess.ErrInvalidPath):
+		writeAPIError(logger, headerWriter, w, r, http.StatusBadRequest, err.Error())
+
+	case errors.As(err, &maxBytesError):
+		writeAPIError(logger, headerWriter, w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("The request body must not be larger than %d bytes.", maxBytesError.Limit))
+
+	default:
+		logger.Error("Unable to modify %q. Error: %s", r.URL.Path, err)
+		writeAPIError(logger, headerWriter, w, r, http.StatusInternalServerError, "The change could not be saved.")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// archivePathPattern matches the paths of the archive pages ("/archive/", "/archive/2015/", "/archive/2015/08/").
+var archivePathPattern = regexp.MustCompile(`^/archive(?:/([0-9]{4})(?:/([0-9]{2}))?)?/?$`)
+
+// Archive creates a http handler which displays the years and months with items ("/archive/"), the months of a
+// year ("/archive/2015/") or the items of a month with a calendar ("/archive/2015/08/").
+func Archive(headerWriter header.HeaderWriter,
+	navigationOrchestrator *orchestrator.NavigationOrchestrator,
+	archiveOrchestrator *orchestrator.ArchiveOrchestrator,
+	templateProvider templates.Provider,
+	error404Handler http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		year, month, isValid := getArchivePeriod(r.URL.Path)
+		if !isValid {
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		var archiveModel viewmodel.Archive
+		headline := templateProvider.Label("", "archive.title")
+
+		switch {
+		case year == 0:
+			archiveModel.Years = archiveOrchestrator.GetYears(archiveOrchestrator.GetVisibility(getUsername(r)))
+
+		default:
+			var found bool
+			archiveModel, found = archiveOrchestrator.GetArchive(year, month, archiveOrchestrator.GetVisibility(getUsername(r)))
+			if !found {
+				error404Handler.ServeHTTP(w, r)
+				return
+			}
+
+			headline = fmt.Sprintf(templateProvider.Label("", "archive.title.year"), year)
+			if month > 0 {
+				monthName := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format("January 2006")
+				headline = fmt.Sprintf(templateProvider.Label("", "archive.title.month"), monthName)
+			}
+		}
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_HTML)
+
+		hostname := getBaseURLFromRequest(r)
+
+		archiveTemplate, err := templateProvider.GetArchiveTemplate(hostname)
+		if err != nil {
+			fmt.Fprintf(w, "Template not found. Error: %s", err)
+			return
+		}
+
+		archiveModel.Type = "archive"
+		archiveModel.Title = headline
+		archiveModel.Description = templateProvider.Label("", "archive.description")
+		archiveModel.PageTitle = archiveOrchestrator.GetPageTitle(headline)
+		archiveModel.ToplevelNavigation = navigationOrchestrator.GetToplevelNavigation()
+		archiveModel.BreadcrumbNavigation = navigationOrchestrator.GetBreadcrumbNavigation(route.New())
+		archiveModel.Weekdays = strings.Split(templateProvider.Label("", "archive.weekdays"), ",")
+
+		renderTemplate(archiveTemplate, archiveModel, w)
+	})
+}
+
+// getArchivePeriod returns the year and month of the given archive path; both are zero for the archive overview
+// and the month is zero for the year pages. It returns false if the path or the month are invalid.
+func getArchivePeriod(requestPath string) (year, month int, isValid bool) {
+	matches := archivePathPattern.FindStringSubmatch(requestPath)
+	if matches == nil {
+		return 0, 0, false
+	}
+
+	year, _ = strconv.Atoi(matches[1])
+	month, _ = strconv.Atoi(matches[2])
+	if month > 12 || (matches[2] != "" && month < 1) {
+		return 0, 0, false
+	}
+
+	return year, month, true
+}
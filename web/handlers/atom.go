@@ -0,0 +1,62 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// atomFeedName is the name of the Atom feed of an item (e.g. "/feed.atom", "/projects/feed.atom").
+const atomFeedName = "feed.atom"
+
+// Atom creates a new Atom-Feed handler for the whole repository ("/feed.atom")
+// or for the subtree of an item (e.g. "/projects/feed.atom"). The "tag" url-parameter limits the feed to the items with the given tag.
+func Atom(headerWriter header.HeaderWriter,
+	feedOrchestrator *orchestrator.FeedOrchestrator,
+	templateProvider templates.Provider,
+	error404Handler http.Handler,
+	itemsPerPage int) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// get the current baseURL
+		baseURL := getBaseURLFromRequest(r)
+
+		// get the item route and the tag of the feed
+		itemRoute, feedRoute := getFeedRoutes(r.URL.Path, atomFeedName)
+		tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+
+		// read the page url-parameter
+		page, pageParameterIsAvailable := getPageParameterFromURL(*r.URL)
+		if !pageParameterIsAvailable || page == 0 {
+			page = 1
+		}
+
+		feedModel, err := feedOrchestrator.GetAtomFeed(baseURL, itemRoute, feedRoute, tag, itemsPerPage, page, feedOrchestrator.GetVisibility(getUsername(r)))
+
+		// display error 404 non-existing page has been requested
+		if err != nil {
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		// get the Atom template
+		feedTemplate, err := templateProvider.GetAtomTemplate(baseURL)
+		if err != nil {
+			fmt.Fprintf(w, "Template not found. Error: %s", err)
+			return
+		}
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_ATOM)
+
+		renderTemplate(feedTemplate, feedModel, w)
+	})
+}
@@ -1,24 +1,88 @@
 package handlers
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/abbot/go-http-auth"
+	"context"
 	"net/http"
 )
 
-// RequireDigestAuthentication forces digest access authentication for the given handler.
-func RequireDigestAuthentication(logger logger.Logger, baseHandler http.Handler, secretProvider auth.SecretProvider) http.Handler {
+// usernameContextKey is the key of the name of the authenticated user in the context of a request.
+type usernameContextKey struct{}
+
+// getUsername returns the name of the user who sent the given request
+// or an empty string if the request was not authenticated.
+func getUsername(r *http.Request) string {
+	username, _ := r.Context().Value(usernameContextKey{}).(string)
+	return username
+}
+
+// withUsername returns the given request with the given username in its context.
+func withUsername(r *http.Request, username string) *http.Request {
+	if username == "" {
+		return r
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), usernameContextKey{}, username))
+}
+
+// RequireDigestAuthentication forces digest access authentication for the given handler
+// for all routes which require authentication according to the given authentication rules.
+// The name of the authenticated user is passed on to the handler so that it can hide the items
+// the user is not allowed to see (also on public routes if the client sent credentials).
+func RequireDigestAuthentication(logger logger.Logger, baseHandler http.Handler, secretProvider auth.SecretProvider, authentication config.Authentication) http.Handler {
+	return requireDigestAuthentication(logger, baseHandler, secretProvider, func() config.Authentication {
+		return authentication
+	})
+}
+
+// RequireDigestAuthenticationWithRules forces digest access authentication for the given handler for all routes
+// which require authentication according to the given authentication rules and the rules returned by the given
+// function (e.g. the rules of the content folders) which can change while requests are served.
+func RequireDigestAuthenticationWithRules(logger logger.Logger, baseHandler http.Handler, secretProvider auth.SecretProvider, authentication config.Authentication, additionalRules func() []config.AuthenticationRule) http.Handler {
+	return requireDigestAuthentication(logger, baseHandler, secretProvider, func() config.Authentication {
+		return authentication.WithRules(additionalRules())
+	})
+}
+
+func requireDigestAuthentication(logger logger.Logger, baseHandler http.Handler, secretProvider auth.SecretProvider, getAuthentication func() config.Authentication) http.Handler {
+
+	authenticator := auth.NewBasicAuthenticator("", secretProvider)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		authenticator := auth.NewBasicAuthenticator("", secretProvider)
+		logger := requestLogger(logger, r)
+
+		// check if the requested route requires authentication
+		authentication := getAuthentication()
+		rule, requiresAuthentication := authentication.GetRule(r.URL.Path)
+		if !requiresAuthentication {
+
+			// identify the user (if credentials were sent) without asking for them
+			if r.Header.Get("Authorization") != "" {
+				r = withUsername(r, authenticator.CheckAuth(r))
+			}
+
+			baseHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// authenticate
+		username := authenticator.CheckAuth(r)
+		if username == "" {
+			authenticator.RequireAuth(w, r)
+			return
+		}
 
-		baseHandlerWithAuthentication := func(w http.ResponseWriter, r *auth.AuthenticatedRequest) {
-			baseHandler.ServeHTTP(w, &r.Request)
+		// authorize
+		if !authentication.IsAuthorized(rule, username) {
+			logger.Warn("The user %q is not authorized to access %q.", username, r.URL.Path)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
 		}
 
-		authHandler := authenticator.Wrap(baseHandlerWithAuthentication)
-		authHandler.ServeHTTP(w, r)
+		baseHandler.ServeHTTP(w, withUsername(r, username))
 	})
 
 }
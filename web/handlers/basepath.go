@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StripBasePath removes the given base path (e.g. "/wiki/") from the request
+// path before passing the request to the given handler. Requests which don't
+// start with the base path (e.g. because a reverse proxy already removed it)
+// are passed through unchanged.
+func StripBasePath(basePath string, baseHandler http.Handler) http.Handler {
+	if basePath == "" || basePath == "/" {
+		return baseHandler
+	}
+
+	prefix := strings.TrimSuffix(basePath, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// redirect "/wiki" to "/wiki/"
+		if r.URL.Path == prefix {
+			http.Redirect(w, r, basePath, http.StatusMovedPermanently)
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, basePath) {
+			r.URL.Path = strings.TrimPrefix(r.URL.Path, prefix)
+			r.URL.RawPath = ""
+		}
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+)
+
+// BrandingIcon creates a http handler which serves the favicons and touch icons that are generated
+// from the configured icon source (e.g. "/-/icons/apple-touch-icon.png").
+func BrandingIcon(headerWriter header.HeaderWriter,
+	brandingOrchestrator *orchestrator.BrandingOrchestrator,
+	error404Handler http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		data, mimeType, found := brandingOrchestrator.GetIcon(path.Base(r.URL.Path))
+		if !found {
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		// set headers
+		headerWriter.Write(w, mimeType)
+
+		// etag cache validator
+		if etag := hashutil.FromBytes(data); etag != "" {
+			header.ETag(w, etag)
+		}
+
+		// the client already has the current version
+		if header.NotModified(w, r) {
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+	})
+}
@@ -0,0 +1,140 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/ical"
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// calendarName is the name of the iCalendar feed of an item (e.g. "/calendar.ics", "/events/calendar.ics").
+const calendarName = "calendar.ics"
+
+// Calendar creates a new iCalendar handler (see https://tools.ietf.org/html/rfc5545) which lists the items
+// with event meta data of the repository or of the subtree of an item.
+func Calendar(logger logger.Logger,
+	headerWriter header.HeaderWriter,
+	calendarOrchestrator *orchestrator.CalendarOrchestrator,
+	error404Handler http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// get the current baseURL
+		baseURL := getBaseURLFromRequest(r)
+
+		itemRoute, _ := getFeedRoutes(r.URL.Path, calendarName)
+		calendarModel, err := calendarOrchestrator.GetCalendar(baseURL, itemRoute, calendarOrchestrator.GetVisibility(getUsername(r)))
+
+		// display error 404 if the item does not exist
+		if err != nil {
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		buffer := new(bytes.Buffer)
+		if err := writeCalendar(ical.NewWriter(buffer), calendarModel); err != nil {
+			logger.Error("Unable to create the calendar for %q. Error: %s", itemRoute.String(), err)
+			http.Error(w, "The calendar could not be created.", http.StatusInternalServerError)
+			return
+		}
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_CALENDAR)
+
+		// etag cache validator
+		if etag := hashutil.FromBytes(buffer.Bytes()); etag != "" {
+			header.ETag(w, etag)
+		}
+
+		// the client already has the current version
+		if header.NotModified(w, r) {
+			return
+		}
+
+		w.Write(buffer.Bytes())
+	})
+}
+
+// writeCalendar writes the given calendar as an iCalendar object.
+func writeCalendar(writer *ical.Writer, calendar viewmodel.Calendar) error {
+
+	writer.Begin("VCALENDAR")
+	writer.Property("VERSION", "2.0")
+	writer.Property("PRODID", "-//allmark//allmark//EN")
+	writer.Property("CALSCALE", "GREGORIAN")
+	writer.Property("METHOD", "PUBLISH")
+	writer.Text("X-WR-CALNAME", calendar.Name)
+	writer.Text("X-WR-CALDESC", calendar.Description)
+
+	for _, event := range calendar.Events {
+		writer.Begin("VEVENT")
+		writer.Property("UID", event.UID)
+
+		// the time stamp must not change with every request
+		stamp := event.LastModified
+		if stamp.IsZero() {
+			stamp = event.Start
+		}
+
+		writer.Timestamp("DTSTAMP", stamp)
+
+		if event.AllDay {
+			writer.Date("DTSTART", event.Start)
+
+			// the end date of all-day events is exclusive
+			if !event.End.IsZero() {
+				writer.Date("DTEND", event.End.AddDate(0, 0, 1))
+			}
+		} else {
+			writer.DateTime("DTSTART", event.Start)
+
+			if !event.End.IsZero() {
+				writer.DateTime("DTEND", event.End)
+			}
+		}
+
+		writer.Text("SUMMARY", event.Summary)
+		writer.Text("DESCRIPTION", event.Description)
+		writer.Text("LOCATION", event.Location)
+
+		if event.Latitude != "" && event.Longitude != "" {
+			writer.Property("GEO", event.Latitude+";"+event.Longitude)
+		}
+
+		if len(event.Categories) > 0 {
+			categories := make([]string, 0, len(event.Categories))
+			for _, category := range event.Categories {
+				categories = append(categories, ical.Escape(category))
+			}
+
+			writer.Property("CATEGORIES", strings.Join(categories, ","))
+		}
+
+		writer.Property("URL", event.URL)
+
+		if !event.Created.IsZero() {
+			writer.Timestamp("CREATED", event.Created)
+		}
+
+		if !event.LastModified.IsZero() {
+			writer.Timestamp("LAST-MODIFIED", event.LastModified)
+		}
+
+		writer.End("VEVENT")
+	}
+
+	writer.End("VCALENDAR")
+
+	return writer.Err()
+}
@@ -1,10 +1,296 @@
 package handlers
 
 import (
-	gorillahandlers "github.com/gorilla/handlers"
+	"compress/gzip"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+
+	// compressionMinSize is the minimum response size in bytes for which compression is worthwhile.
+	compressionMinSize = 1024
+
+	// brotliQuality is the brotli compression level (0-11) used for dynamic responses.
+	brotliQuality = 5
 )
 
+// compressibleContentTypes contains the content types (or content type prefixes) that will be compressed.
+var compressibleContentTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/x-javascript",
+	"application/xml",
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/opensearchdescription+xml",
+	"image/svg+xml",
+	"image/x-icon",
+}
+
+// CompressResponses compresses the responses of the given handler with Brotli or gzip
+// depending on the encodings accepted by the client.
 func CompressResponses(baseHandler http.Handler) http.Handler {
-	return gorillahandlers.CompressHandler(baseHandler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// always vary by encoding to prevent intermediate caches from serving the wrong representation
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		// don't interfere with websocket connections
+		if r.Header.Get("Upgrade") != "" {
+			baseHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// partial content must be served unmodified
+		encoding := getPreferredEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			baseHandler.ServeHTTP(w, r)
+			return
+		}
+
+		compressingWriter := &compressingResponseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			statusCode:     http.StatusOK,
+		}
+		defer compressingWriter.Close()
+
+		baseHandler.ServeHTTP(compressingWriter, r)
+	})
+}
+
+// getPreferredEncoding returns the preferred supported encoding ("br", "gzip")
+// from the given Accept-Encoding header value; or an empty string if none is accepted.
+func getPreferredEncoding(acceptEncoding string) string {
+	encodings := getAcceptedEncodings(acceptEncoding)
+	if len(encodings) == 0 {
+		return ""
+	}
+
+	return encodings[0]
+}
+
+// getAcceptedEncodings returns the supported encodings ("br", "gzip") which are accepted
+// according to the given Accept-Encoding header value in the order of preference.
+func getAcceptedEncodings(acceptEncoding string) []string {
+	accepted := make(map[string]bool)
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, parameter := range fields[1:] {
+			parameter = strings.TrimSpace(parameter)
+			if strings.HasPrefix(parameter, "q=") {
+				if value, err := strconv.ParseFloat(strings.TrimPrefix(parameter, "q="), 64); err == nil {
+					quality = value
+				}
+			}
+		}
+
+		accepted[name] = quality > 0
+	}
+
+	var encodings []string
+	for _, encoding := range []string{encodingBrotli, encodingGzip} {
+		if isAccepted, isListed := accepted[encoding]; isListed {
+			if isAccepted {
+				encodings = append(encodings, encoding)
+			}
+
+			continue
+		}
+
+		if accepted["*"] {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return encodings
+}
+
+// isCompressibleContentType checks if responses with the given content type should be compressed.
+func isCompressibleContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+
+	// server-sent events must be delivered immediately
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return false
+	}
+
+	for _, compressibleContentType := range compressibleContentTypes {
+		if strings.HasPrefix(contentType, compressibleContentType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compressingResponseWriter compresses the response body if the content type is compressible
+// and the response is large enough.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+
+	encoding   string
+	statusCode int
+
+	decided     bool
+	passThrough bool
+	headerSent  bool
+
+	buffer     []byte
+	compressor io.WriteCloser
+}
+
+func (writer *compressingResponseWriter) WriteHeader(statusCode int) {
+	if writer.decided || writer.headerSent {
+		return
+	}
+
+	writer.statusCode = statusCode
+}
+
+func (writer *compressingResponseWriter) Write(data []byte) (int, error) {
+	if !writer.decided {
+		writer.decide(data)
+	}
+
+	if writer.passThrough {
+		return writer.ResponseWriter.Write(data)
+	}
+
+	if writer.compressor != nil {
+		return writer.compressor.Write(data)
+	}
+
+	// collect the data until it is clear that compression is worthwhile
+	writer.buffer = append(writer.buffer, data...)
+	if len(writer.buffer) >= compressionMinSize {
+		if err := writer.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+// Flush sends all buffered data to the client.
+func (writer *compressingResponseWriter) Flush() {
+	if !writer.decided {
+		// nothing has been written yet; the response can no longer be compressed
+		writer.decided = true
+		writer.passThrough = true
+	} else if !writer.passThrough && writer.compressor == nil {
+		writer.startCompression()
+	}
+
+	if flusher, ok := writer.compressor.(interface {
+		Flush() error
+	}); ok {
+		flusher.Flush()
+	}
+
+	writer.sendHeader()
+
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close finishes the compression or writes the buffered (uncompressed) data.
+func (writer *compressingResponseWriter) Close() error {
+	if writer.compressor != nil {
+		return writer.compressor.Close()
+	}
+
+	// the response was too small to be compressed
+	writer.sendHeader()
+
+	if len(writer.buffer) > 0 {
+		_, err := writer.ResponseWriter.Write(writer.buffer)
+		writer.buffer = nil
+		return err
+	}
+
+	return nil
+}
+
+// decide determines whether the response will be compressed or not.
+func (writer *compressingResponseWriter) decide(data []byte) {
+	writer.decided = true
+
+	header := writer.Header()
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+		header.Set("Content-Type", contentType)
+	}
+
+	switch {
+	case writer.statusCode == http.StatusNoContent,
+		writer.statusCode == http.StatusNotModified,
+		writer.statusCode == http.StatusPartialContent,
+		header.Get("Content-Encoding") != "",
+		header.Get("Content-Range") != "",
+		!isCompressibleContentType(contentType):
+		writer.passThrough = true
+
+	default:
+		if contentLength, err := strconv.Atoi(header.Get("Content-Length")); err == nil && contentLength < compressionMinSize {
+			writer.passThrough = true
+		}
+	}
+
+	if writer.passThrough {
+		writer.sendHeader()
+	}
+}
+
+// startCompression sends the headers for the compressed response and writes the buffered data to the compressor.
+func (writer *compressingResponseWriter) startCompression() error {
+	header.EncodeETag(writer, writer.encoding)
+
+	header := writer.Header()
+	header.Set("Content-Encoding", writer.encoding)
+	header.Del("Content-Length")
+	writer.sendHeader()
+
+	switch writer.encoding {
+	case encodingBrotli:
+		writer.compressor = brotli.NewWriterLevel(writer.ResponseWriter, brotliQuality)
+
+	default:
+		writer.compressor = gzip.NewWriter(writer.ResponseWriter)
+	}
+
+	buffer := writer.buffer
+	writer.buffer = nil
+
+	_, err := writer.compressor.Write(buffer)
+	return err
+}
+
+// sendHeader writes the status code to the underlying response writer if it has not been sent yet.
+func (writer *compressingResponseWriter) sendHeader() {
+	if writer.headerSent {
+		return
+	}
+
+	writer.headerSent = true
+	writer.ResponseWriter.WriteHeader(writer.statusCode)
 }
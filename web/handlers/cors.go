@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	gorillahandlers "github.com/gorilla/handlers"
+	"net/http"
+)
+
+// AllowCrossOriginRequests adds cross-origin resource sharing (CORS) headers to
+// the responses of the given handler if CORS is enabled.
+func AllowCrossOriginRequests(corsConfig config.CORS, baseHandler http.Handler) http.Handler {
+	if !corsConfig.Enabled {
+		return baseHandler
+	}
+
+	options := []gorillahandlers.CORSOption{
+		gorillahandlers.AllowedOrigins(corsConfig.AllowedOrigins),
+		gorillahandlers.MaxAge(corsConfig.MaxAgeInSeconds),
+	}
+
+	if len(corsConfig.AllowedMethods) > 0 {
+		options = append(options, gorillahandlers.AllowedMethods(corsConfig.AllowedMethods))
+	}
+
+	if len(corsConfig.AllowedHeaders) > 0 {
+		options = append(options, gorillahandlers.AllowedHeaders(corsConfig.AllowedHeaders))
+	}
+
+	return gorillahandlers.CORS(options...)(baseHandler)
+}
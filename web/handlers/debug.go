@@ -0,0 +1,85 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// DebugProfiles returns a handler which exposes the pprof profiles of the server
+// (e.g. "/-/debug/pprof/heap", "/-/debug/pprof/profile?seconds=30").
+func DebugProfiles() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		profileName := strings.TrimPrefix(r.URL.Path, DebugPProfRoutePrefix)
+
+		switch profileName {
+		case "":
+			pprof.Index(w, r)
+		case "cmdline":
+			pprof.Cmdline(w, r)
+		case "profile":
+			pprof.Profile(w, r)
+		case "symbol":
+			pprof.Symbol(w, r)
+		case "trace":
+			pprof.Trace(w, r)
+		default:
+			pprof.Handler(profileName).ServeHTTP(w, r)
+		}
+	})
+}
+
+// DebugVars returns a handler which responds with a JSON snapshot of the runtime statistics of the server.
+func DebugVars(headerWriter header.HeaderWriter, statusOrchestrator *orchestrator.StatusOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerWriter.Write(w, header.CONTENTTYPE_JSON)
+
+		bytes, err := json.MarshalIndent(statusOrchestrator.GetDebugVars(), "", "\t")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Write(bytes)
+	})
+}
+
+// RestrictToLocalhost answers all requests which don't come from the loopback interface with "403 Forbidden".
+// Requests over Unix domain sockets are only considered local if unixSocketIsLocal is set.
+func RestrictToLocalhost(unixSocketIsLocal bool, baseHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if !isLocalClient(r, unixSocketIsLocal) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
+
+// isLocalClient returns true if the client address of the given request is a loopback address. Requests with
+// an X-Forwarded-For header are only local if their client address was resolved through trusted proxies (a local
+// reverse proxy forwards the requests of remote clients). Client addresses which are not IP addresses at all
+// (e.g. the remote address of a Unix domain socket connection) are local if unixSocketIsLocal is set.
+func isLocalClient(r *http.Request, unixSocketIsLocal bool) bool {
+	if len(r.Header["X-Forwarded-For"]) > 0 && !isForwardedByTrustedProxy(r) {
+		return false
+	}
+
+	ip := net.ParseIP(getClientAddress(r))
+	if ip == nil {
+		return unixSocketIsLocal
+	}
+
+	return ip.IsLoopback()
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// getLocalhostRestrictionStatus returns the status code of the given request to a handler which is restricted to localhost
+// and whose client addresses are resolved with the given trusted proxies.
+func getLocalhostRestrictionStatus(t *testing.T, trustedProxies []string, unixSocketIsLocal bool, r *http.Request) int {
+	networkPolicy, err := config.NewNetworkPolicy(config.Network{TrustedProxies: trustedProxies})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := ResolveClientAddress(networkPolicy, RestrictToLocalhost(unixSocketIsLocal, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, r)
+
+	return response.Code
+}
+
+func Test_RestrictToLocalhost_LoopbackClient_RequestIsServed(t *testing.T) {
+	// arrange
+	r := httptest.NewRequest(http.MethodGet, "/-/debug/vars", nil)
+	r.RemoteAddr = "127.0.0.1:50000"
+
+	// act
+	status := getLocalhostRestrictionStatus(t, nil, false, r)
+
+	// assert
+	if status != http.StatusOK {
+		t.Errorf("The request from the loopback interface should be served but the status was %d.", status)
+	}
+}
+
+func Test_RestrictToLocalhost_ForwardedByUntrustedLocalProxy_RequestIsForbidden(t *testing.T) {
+	// arrange
+	r := httptest.NewRequest(http.MethodGet, "/-/debug/vars", nil)
+	r.RemoteAddr = "127.0.0.1:50000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	// act
+	status := getLocalhostRestrictionStatus(t, nil, false, r)
+
+	// assert
+	if status != http.StatusForbidden {
+		t.Errorf("The request which a local proxy forwarded for a remote client should be forbidden but the status was %d.", status)
+	}
+}
+
+func Test_RestrictToLocalhost_ForwardedByTrustedProxy_ResolvedClientAddressIsUsed(t *testing.T) {
+	// arrange
+	remoteRequest := httptest.NewRequest(http.MethodGet, "/-/debug/vars", nil)
+	remoteRequest.RemoteAddr = "127.0.0.1:50000"
+	remoteRequest.Header.Set("X-Forwarded-For", "127.0.0.1, 203.0.113.7")
+
+	localRequest := httptest.NewRequest(http.MethodGet, "/-/debug/vars", nil)
+	localRequest.RemoteAddr = "10.0.0.2:50000"
+	localRequest.Header.Set("X-Forwarded-For", "::1")
+
+	// act
+	remoteStatus := getLocalhostRestrictionStatus(t, []string{"127.0.0.1", "10.0.0.0/8"}, false, remoteRequest)
+	localStatus := getLocalhostRestrictionStatus(t, []string{"127.0.0.1", "10.0.0.0/8"}, false, localRequest)
+
+	// assert
+	if remoteStatus != http.StatusForbidden {
+		t.Errorf("The request of the remote client should be forbidden but the status was %d.", remoteStatus)
+	}
+
+	if localStatus != http.StatusOK {
+		t.Errorf("The request of the local client should be served but the status was %d.", localStatus)
+	}
+}
+
+func Test_RestrictToLocalhost_UnixSocket_RequestIsOnlyServedIfConfigured(t *testing.T) {
+	// arrange
+	r := httptest.NewRequest(http.MethodGet, "/-/debug/vars", nil)
+	r.RemoteAddr = "@"
+
+	// act
+	defaultStatus := getLocalhostRestrictionStatus(t, nil, false, r)
+	configuredStatus := getLocalhostRestrictionStatus(t, nil, true, r)
+
+	// assert
+	if defaultStatus != http.StatusForbidden {
+		t.Errorf("The request over the Unix domain socket should be forbidden by default but the status was %d.", defaultStatus)
+	}
+
+	if configuredStatus != http.StatusOK {
+		t.Errorf("The request over the Unix domain socket should be served if configured but the status was %d.", configuredStatus)
+	}
+}
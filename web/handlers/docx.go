@@ -5,62 +5,132 @@
 package handlers
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/services/richtext"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
 	"github.com/andreaskoch/allmark/web/view/templates"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// DOCX returns a handler which converts the requested item (and all of its descendants if the subtree
+// parameter is set) to a Word document. Requests for paths which are not items (e.g. attached DOCX files)
+// are passed to the fallback handler.
 func DOCX(logger logger.Logger,
-	conversionToolPath string,
+	conversionConfig config.DOCXConversion,
+	converter *richtext.Converter,
 	conversionEndpointHostname string,
 	headerWriter header.HeaderWriter,
 	converterModelOrchestrator *orchestrator.ConversionModelOrchestrator,
 	templateProvider templates.Provider,
-	error404Handler http.Handler) http.Handler {
+	limitConversions ConcurrencyLimiter,
+	fallbackHandler http.Handler) http.Handler {
 
-	convertToHtml := func(baseURL string, viewModel viewmodel.ConversionModel) string {
+	return richText(logger, richtext.FormatDOCX, header.CONTENTTYPE_DOCX, conversionConfig, converter, conversionEndpointHostname, headerWriter, converterModelOrchestrator, templateProvider, limitConversions, fallbackHandler)
+}
+
+// ODT returns a handler which converts the requested item (and all of its descendants if the subtree
+// parameter is set) to an OpenDocument text document. Requests for paths which are not items
+// (e.g. attached ODT files) are passed to the fallback handler.
+func ODT(logger logger.Logger,
+	conversionConfig config.DOCXConversion,
+	converter *richtext.Converter,
+	conversionEndpointHostname string,
+	headerWriter header.HeaderWriter,
+	converterModelOrchestrator *orchestrator.ConversionModelOrchestrator,
+	templateProvider templates.Provider,
+	limitConversions ConcurrencyLimiter,
+	fallbackHandler http.Handler) http.Handler {
+
+	return richText(logger, richtext.FormatODT, header.CONTENTTYPE_ODT, conversionConfig, converter, conversionEndpointHostname, headerWriter, converterModelOrchestrator, templateProvider, limitConversions, fallbackHandler)
+}
+
+// richText returns a handler which converts the print view of the requested item to a rich-text document in the
+// given format with the given converter (pandoc). The converted documents are cached by the converter, so only
+// the first request for every version of an item is restricted by the given concurrency limiter.
+func richText(logger logger.Logger,
+	format string,
+	contentType string,
+	conversionConfig config.DOCXConversion,
+	converter *richtext.Converter,
+	conversionEndpointHostname string,
+	headerWriter header.HeaderWriter,
+	converterModelOrchestrator *orchestrator.ConversionModelOrchestrator,
+	templateProvider templates.Provider,
+	limitConversions ConcurrencyLimiter,
+	fallbackHandler http.Handler) http.Handler {
+
+	convertToHtml := func(baseURL string, viewModel viewmodel.ConversionModel) (string, error) {
 
 		// get a template
 		template, err := templateProvider.GetConversionTemplate(baseURL)
 		if err != nil {
-			logger.Error("No template for item of type %q.", viewModel.Type)
-			return ""
+			return "", fmt.Errorf("No template for item of type %q.", viewModel.Type)
 		}
 
 		// render template
 		buffer := new(bytes.Buffer)
-		writer := bufio.NewWriter(buffer)
-		if err := renderTemplate(template, viewModel, writer); err != nil {
+		if err := renderTemplate(template, viewModel, buffer); err != nil {
+			return "", err
+		}
+
+		return buffer.String(), nil
+	}
+
+	convert := func(r *http.Request, w http.ResponseWriter, baseURL, name string, model viewmodel.ConversionModel) {
+
+		logger := requestLogger(logger, r)
+
+		html, err := convertToHtml(baseURL, model)
+		if err != nil {
 			logger.Error("%s", err)
-			return ""
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
 		}
 
-		writer.Flush()
+		targetFilePath, err := converter.Convert(name, getRichTextVersion(baseURL, html, model), html, format)
+		if err != nil {
+			logger.Error("Could not convert %q to %s. Error: %s", model.Route, format, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
 
-		return buffer.String()
+		targetFile, err := fsutil.OpenFile(targetFilePath)
+		if err != nil {
+			logger.Error("Cannot open target file. Error: %s", err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		defer targetFile.Close()
+
+		// set headers
+		headerWriter.Write(w, contentType)
+		w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, getTargetFilename(model, format)))
+
+		io.Copy(w, targetFile)
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		// set headers
-		headerWriter.Write(w, header.CONTENTTYPE_DOCX)
+		if !conversionConfig.IsEnabled() {
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
 
-		// strip the "docx" or ".docx" suffix from the path
+		// strip the format suffix (e.g. "docx" or ".docx") from the path
 		path := r.URL.Path
-		path = strings.TrimSuffix(path, "docx")
+		path = strings.TrimSuffix(path, format)
 		path = strings.TrimSuffix(path, ".")
 
 		// get the request route
@@ -69,94 +139,50 @@ func DOCX(logger logger.Logger,
 		// make sure the request body is closed
 		defer r.Body.Close()
 
-		// the temporary working directory
-		targetDirectory := fsutil.GetTempDirectory()
-
-		// get the conversion model
-		baseURL := getBaseURLFromRequest(r)
-
 		// make the baseURL HTTP because pandoc has problems with HTTPS
+		// and make sure pandoc only performs local requests
+		baseURL := getBaseURLFromRequest(r)
 		baseURL = strings.Replace(baseURL, "https://", "http://", 1)
-
-		// make sure pandoc only performs local requests
 		baseURL = strings.Replace(baseURL, r.Host, conversionEndpointHostname, 1)
 
-		model, found := converterModelOrchestrator.GetConversionModel(baseURL, requestRoute)
-		if !found {
-
-			// display a 404 error page
-			error404Handler.ServeHTTP(w, r)
-			return
-		}
-
-		html := convertToHtml(baseURL, model)
-
-		// write the html to a temp file (Note: the file extension .html is important for pandoc)
-		htmlFilePath := filepath.Join(targetDirectory, "source.html")
-		htmlFile, err := os.Create(htmlFilePath)
-		if err != nil {
-			logger.Error("Cannot open HTML file for writing. Error: %s", err.Error())
-			return
+		var model viewmodel.ConversionModel
+		var found bool
+		name := requestRoute.Value()
+		if includeSubtree, _ := strconv.ParseBool(r.URL.Query().Get(printSubtreeParameter)); includeSubtree {
+			model, found = converterModelOrchestrator.GetSubtreeConversionModel(baseURL, requestRoute, converterModelOrchestrator.GetVisibility(getUsername(r)))
+			name += "?" + printSubtreeParameter
+		} else {
+			model, found = converterModelOrchestrator.GetConversionModel(baseURL, requestRoute)
 		}
 
-		htmlFile.WriteString(html)
-		htmlFile.Sync()
-
-		// close and delete the file at the end of the function
-		htmlFile.Close()
-
-		// get a target file path (Note: the file extension .docx is important for pandoc)
-		targetFilePath := filepath.Join(targetDirectory, "target.docx")
-
-		// call pandoc
-		args := []string{
-			"-s",
-			fmt.Sprintf(`%s`, htmlFilePath),
-			"-o",
-			fmt.Sprintf(`%s`, targetFilePath),
-		}
-
-		cmd := exec.Command(conversionToolPath, args...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Dir = targetDirectory
-
-		if err := cmd.Run(); err != nil {
-			logger.Error("Could not run pandoc: %v", err)
-			return
-		}
-
-		logger.Debug("Saving conversion files to directory: %q", targetDirectory)
+		if !found {
 
-		// docx file
-		docxFile, err := fsutil.OpenFile(targetFilePath)
-		if err != nil {
-			logger.Error("Cannot open target file. Error: %s", err.Error())
+			// not an item (e.g. an attached file)
+			fallbackHandler.ServeHTTP(w, r)
 			return
 		}
 
-		// close and delete the file at the end of the function
-		defer docxFile.Close()
-
-		// remove the temp directory at the end
-		defer func() {
-			logger.Debug("Deleting conversion file directory: %q", targetDirectory)
-			if err := deleteFile(targetDirectory); err != nil {
-				logger.Error("Could not delete the temporary working directory (%q) that has been created during the conversion. Error: %s", targetDirectory, err.Error())
-			}
-		}()
-
-		w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, getTargetFilename(model)))
+		limitConversions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			convert(r, w, baseURL, name, model)
+		})).ServeHTTP(w, r)
+	})
 
-		io.Copy(w, docxFile)
+}
 
-		return
-	})
+// getRichTextVersion returns the version of the given HTML document of the given conversion model by which the
+// converted documents are cached: the document without the given base URL (the conversion endpoint changes with
+// every start) and the hashes of the files of the item, whose images are embedded into the converted documents.
+func getRichTextVersion(baseURL, html string, model viewmodel.ConversionModel) string {
+	version := strings.Replace(html, baseURL, "", -1)
+	for _, file := range model.Files {
+		version += "\n" + file.Route + ":" + file.Hash
+	}
 
+	return version
 }
 
-// getTargetFilename returns a filename from the given conversion model.
-func getTargetFilename(model viewmodel.ConversionModel) string {
+// getTargetFilename returns a filename with the given extension (e.g. "docx") from the given conversion model.
+func getTargetFilename(model viewmodel.ConversionModel, extension string) string {
 	originalRoute := route.NewFromRequest(model.Route)
 	fileNameRoute := route.NewFromRequest(originalRoute.LastComponentName())
 
@@ -164,7 +190,7 @@ func getTargetFilename(model viewmodel.ConversionModel) string {
 		fileNameRoute = route.NewFromRequest(model.Title)
 	}
 
-	return fmt.Sprintf("%s.docx", fileNameRoute.Value())
+	return fmt.Sprintf("%s.%s", fileNameRoute.Value(), extension)
 }
 
 // deleteFile removes the file with the specified path.
@@ -0,0 +1,213 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/services/epub"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// EPUB returns a handler which creates an e-book of the requested item and all of its descendants: every item
+// becomes a chapter and the images of the items are embedded, so the book can be read offline. The primary
+// image of the item is the cover. Requests for paths which are not items (e.g. attached EPUB files) are passed
+// to the fallback handler; only the conversions are restricted by the given concurrency limiter.
+func EPUB(logger logger.Logger,
+	enabled bool,
+	basePath string,
+	headerWriter header.HeaderWriter,
+	converterModelOrchestrator *orchestrator.ConversionModelOrchestrator,
+	fileOrchestrator *orchestrator.FileOrchestrator,
+	limitConversions ConcurrencyLimiter,
+	fallbackHandler http.Handler) http.Handler {
+
+	// getImage returns the image file with the given route
+	getImage := func(fileRoute route.Route, imagePath string) (*epub.Image, error) {
+		contentProvider := fileOrchestrator.GetFileContentProvider(fileRoute)
+		if contentProvider == nil {
+			return nil, fmt.Errorf("The image %q was not found.", fileRoute)
+		}
+
+		mimeType, err := contentProvider.MimeType()
+		if err != nil {
+			return nil, err
+		}
+
+		buffer := new(bytes.Buffer)
+		if err := contentProvider.Data(func(content io.ReadSeeker) error {
+			_, err := io.Copy(buffer, content)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+
+		return &epub.Image{
+			Path:      imagePath + strings.ToLower(path.Ext(fileRoute.Value())),
+			MediaType: mimeType,
+			Data:      buffer.Bytes(),
+		}, nil
+	}
+
+	convert := func(r *http.Request, w http.ResponseWriter, baseURL string, model viewmodel.ConversionModel) {
+
+		logger := requestLogger(logger, r)
+
+		book := &epub.Book{
+			Identifier:  baseURL + basePath + model.Route,
+			Title:       model.Title,
+			Description: model.Description,
+			Author:      model.Author.Name,
+			Language:    model.LanguageTag,
+			Modified:    getBookModificationDate(model.LastModifiedDate),
+		}
+
+		// cover
+		if model.CoverImage != "" {
+			cover, err := getImage(route.NewFromRequest(model.CoverImage), "images/cover")
+			if err != nil {
+				logger.Warn("Unable to add the cover to the e-book of %q. Error: %s", model.Route, err)
+			} else {
+				book.Cover = cover
+			}
+		}
+
+		// the links to the sections point to the chapters
+		chapterPaths := make(map[string]string, len(model.Sections))
+		for index, section := range model.Sections {
+			chapterPaths["#"+section.Anchor] = epub.ChapterPath(index + 1)
+		}
+
+		// the images of the repository are embedded
+		siteURL := baseURL + basePath
+		imagePaths := make(map[string]string)
+		if book.Cover != nil {
+			imagePaths[siteURL+model.CoverImage] = book.Cover.Path
+		}
+
+		rewriteURL := func(element, url string) string {
+			if element == "a" {
+				if chapterPath, isChapter := chapterPaths[url]; isChapter {
+					return chapterPath
+				}
+
+				return url
+			}
+
+			if imagePath, exists := imagePaths[url]; exists {
+				return imagePath
+			}
+
+			if !strings.HasPrefix(url, siteURL) {
+				return url
+			}
+
+			image, err := getImage(route.NewFromRequest(strings.TrimPrefix(url, siteURL)), fmt.Sprintf("images/%03d", len(book.Images)+1))
+			if err != nil {
+				logger.Warn("Unable to add the image %q to the e-book of %q. Error: %s", url, model.Route, err)
+				return url
+			}
+
+			book.Images = append(book.Images, *image)
+			imagePaths[url] = image.Path
+			return image.Path
+		}
+
+		addChapter := func(title, description, content string, depth int) error {
+			if description != "" {
+				content = fmt.Sprintf("<p class=\"description\">%s</p>\n%s", html.EscapeString(description), content)
+			}
+
+			xhtml, err := epub.ToXHTML(content, rewriteURL)
+			if err != nil {
+				return err
+			}
+
+			book.Chapters = append(book.Chapters, epub.Chapter{Title: title, Depth: depth, Content: xhtml})
+			return nil
+		}
+
+		if err := addChapter(model.Title, model.Description, model.Content, 0); err != nil {
+			logger.Error("Unable to convert %q to an e-book. Error: %s", model.Route, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		for _, section := range model.Sections {
+			if err := addChapter(section.Title, section.Description, section.Content, section.Depth); err != nil {
+				logger.Error("Unable to convert %q to an e-book. Error: %s", section.Route, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		buffer := new(bytes.Buffer)
+		if err := book.Write(buffer); err != nil {
+			logger.Error("Unable to write the e-book of %q. Error: %s", model.Route, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_EPUB)
+		w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, getTargetFilename(model, "epub")))
+
+		io.Copy(w, buffer)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if !enabled {
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// strip the "epub" or ".epub" suffix from the path
+		path := r.URL.Path
+		path = strings.TrimSuffix(path, "epub")
+		path = strings.TrimSuffix(path, ".")
+
+		// get the request route
+		requestRoute := route.NewFromRequest(path)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		baseURL := getBaseURLFromRequest(r)
+		model, found := converterModelOrchestrator.GetSubtreeConversionModel(baseURL, requestRoute, converterModelOrchestrator.GetVisibility(getUsername(r)))
+		if !found {
+
+			// not an item (e.g. an attached EPUB file)
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
+
+		limitConversions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			convert(r, w, baseURL, model)
+		})).ServeHTTP(w, r)
+	})
+
+}
+
+// getBookModificationDate returns the given (formatted) modification date of an item or the current time if it cannot be parsed.
+func getBookModificationDate(date string) time.Time {
+	for _, dateLayout := range []string{"2006-01-02", time.RFC3339} {
+		if parsedDate, err := time.Parse(dateLayout, date); err == nil {
+			return parsedDate
+		}
+	}
+
+	return time.Now()
+}
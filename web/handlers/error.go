@@ -5,6 +5,8 @@
 package handlers
 
 import (
+	"bytes"
+	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
@@ -12,17 +14,110 @@ import (
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
-func Error(headerWriter header.HeaderWriter, templateProvider templates.Provider, navigationOrchestrator *orchestrator.NavigationOrchestrator) http.Handler {
+// Error returns a handler which displays a "404 Not Found" error page. If a custom error page
+// route is given and the repository contains an item for it, the item is displayed instead of
+// the built-in error page.
+func Error(headerWriter header.HeaderWriter,
+	templateProvider templates.Provider,
+	navigationOrchestrator *orchestrator.NavigationOrchestrator,
+	viewModelOrchestrator *orchestrator.ViewModelOrchestrator,
+	customErrorPageRoute string) http.Handler {
+
+	return errorPage(headerWriter,
+		templateProvider,
+		navigationOrchestrator,
+		viewModelOrchestrator,
+		customErrorPageRoute,
+		http.StatusNotFound,
+		"error.notfound.title",
+		"error.notfound.description")
+}
+
+// InternalServerError returns a handler which displays a "500 Internal Server Error" error page.
+// If a custom error page route is given and the repository contains an item for it, the item is
+// displayed instead of the built-in error page.
+func InternalServerError(headerWriter header.HeaderWriter,
+	templateProvider templates.Provider,
+	navigationOrchestrator *orchestrator.NavigationOrchestrator,
+	viewModelOrchestrator *orchestrator.ViewModelOrchestrator,
+	customErrorPageRoute string) http.Handler {
+
+	return errorPage(headerWriter,
+		templateProvider,
+		navigationOrchestrator,
+		viewModelOrchestrator,
+		customErrorPageRoute,
+		http.StatusInternalServerError,
+		"error.internal.title",
+		"error.internal.description")
+}
+
+// RecoverFromPanics recovers from panics in the given handler, logs them and displays the given error page.
+func RecoverFromPanics(logger logger.Logger, errorHandler http.Handler, baseHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := requestLogger(logger, r)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+
+				// let the http server abort the response
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				logger.Error("Request %q failed: %v", r.URL.Path, recovered)
+				errorHandler.ServeHTTP(w, r)
+			}
+		}()
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
+
+func errorPage(headerWriter header.HeaderWriter,
+	templateProvider templates.Provider,
+	navigationOrchestrator *orchestrator.NavigationOrchestrator,
+	viewModelOrchestrator *orchestrator.ViewModelOrchestrator,
+	customErrorPageRoute string,
+	statusCode int,
+	titleKey, descriptionKey string) http.Handler {
+
+	customErrorPageRoute = strings.TrimSpace(customErrorPageRoute)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
+		hostname := getBaseURLFromRequest(r)
+
+		// the custom error page
+		if customErrorPageRoute != "" {
+			if model, found := viewModelOrchestrator.GetFullViewModel(route.NewFromRequest(customErrorPageRoute), viewModelOrchestrator.GetVisibility(getUsername(r))); found {
+				itemTemplate, err := getItemTemplate(templateProvider, model, hostname)
+				if err != nil {
+					itemTemplate, err = templateProvider.GetItemTemplate(model.Type, hostname)
+				}
+
+				if err == nil {
+
+					buffer := new(bytes.Buffer)
+					if err := renderTemplate(itemTemplate, model, buffer); err == nil {
+						headerWriter.Write(w, header.CONTENTTYPE_HTML)
+						w.WriteHeader(statusCode)
+						w.Write(buffer.Bytes())
+						return
+					}
+
+				}
+			}
+		}
+
 		// set headers
 		headerWriter.Write(w, header.CONTENTTYPE_HTML)
-		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(statusCode)
 
 		// get the error template
-		hostname := getBaseURLFromRequest(r)
 		errorTemplate, err := templateProvider.GetErrorTemplate(hostname)
 		if err != nil {
 			fmt.Fprintf(w, "Template not found. Error: %s", err)
@@ -33,8 +128,8 @@ func Error(headerWriter header.HeaderWriter, templateProvider templates.Provider
 		errorModel := viewmodel.Model{}
 
 		errorModel.Type = "error"
-		errorModel.Title = "Not found"
-		errorModel.Description = "The requested resource was not found."
+		errorModel.Title = templateProvider.Label("", titleKey)
+		errorModel.Description = templateProvider.Label("", descriptionKey)
 		errorModel.ToplevelNavigation = navigationOrchestrator.GetToplevelNavigation()
 		errorModel.BreadcrumbNavigation = navigationOrchestrator.GetBreadcrumbNavigation(route.New())
 
@@ -8,9 +8,12 @@ import (
 	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/services/richtext"
+	"github.com/andreaskoch/allmark/services/thumbnail"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
 	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/themes"
 	"fmt"
 	"net/http"
 )
@@ -41,6 +44,9 @@ var (
 	// PrintHandlerRoute defines the route for print-handler requests.
 	PrintHandlerRoute = `/{path:.+\.print$|print$}`
 
+	// ReaderHandlerRoute defines the route for reader-handler requests.
+	ReaderHandlerRoute = `/{path:.+\.plain$|plain$}`
+
 	// JSONHandlerRoute defines the route for JSON-handler requests.
 	JSONHandlerRoute = `/{path:.+\.json$|json$}`
 
@@ -53,20 +59,56 @@ var (
 	// DOCXHandlerRoute defines the route for rich-text-handler requests.
 	DOCXHandlerRoute = `/{path:.+\.docx$|docx$}`
 
+	// ODTHandlerRoute defines the route for OpenDocument-handler requests.
+	ODTHandlerRoute = `/{path:.+\.odt$|odt$}`
+
+	// PDFHandlerRoute defines the route for PDF-handler requests.
+	PDFHandlerRoute = `/{path:.+\.pdf$|pdf$}`
+
+	// EPUBHandlerRoute defines the route for e-book-handler requests.
+	EPUBHandlerRoute = `/{path:.+\.epub$|epub$}`
+
+	// ZIPHandlerRoute defines the route for ZIP-download requests.
+	ZIPHandlerRoute = `/{path:.+\.zip$|zip$}`
+
 	// UpdateHandlerRoute defines the route for update-handler requests.
 	UpdateHandlerRoute = `/{path:.+\.ws$|ws$}`
 
+	// UpdateEventsHandlerRoute defines the route for the server-sent update events.
+	UpdateEventsHandlerRoute = `/{path:.+\.events$|events$}`
+
 	// ItemHandlerRoute defines the route for item-handler requests.
 	ItemHandlerRoute = "/{path:.*$}"
 
 	// SitemapHandlerRoute defines the route for sitemap-handler requests.
 	SitemapHandlerRoute = "/sitemap.html"
 
+	// RecentChangesHandlerRoute defines the route for the page of the most recently modified items.
+	RecentChangesHandlerRoute = "/recent"
+
+	// RecentChangesFeedHandlerRoute defines the route for the RSS feed of the most recently modified items.
+	RecentChangesFeedHandlerRoute = "/recent.rss"
+
+	// ArchiveHandlerRoute defines the route for the archive overview and the year and month pages (e.g. "/archive/2015/08/").
+	ArchiveHandlerRoute = `/{path:archive(?:/[0-9]{4}(?:/[0-9]{2})?)?/?$}`
+
 	// XMLSitemapHandlerRoute defines the route for xml-sitemap-handler requests.
 	XMLSitemapHandlerRoute = "/sitemap.xml"
 
-	// RSSHandlerRoute defines the route for RSS-feed-handler requests.
-	RSSHandlerRoute = "/feed.rss"
+	// RSSHandlerRoute defines the route for RSS-feed-handler requests (the whole repository or the subtree of an item).
+	RSSHandlerRoute = `/{path:(?:.+/)?feed\.rss$}`
+
+	// AtomHandlerRoute defines the route for Atom-feed-handler requests (the whole repository or the subtree of an item).
+	AtomHandlerRoute = `/{path:(?:.+/)?feed\.atom$}`
+
+	// JSONFeedHandlerRoute defines the route for JSON-feed-handler requests.
+	JSONFeedHandlerRoute = "/feed.json"
+
+	// CalendarHandlerRoute defines the route for iCalendar-handler requests (the whole repository or the subtree of an item).
+	CalendarHandlerRoute = `/{path:(?:.+/)?calendar\.ics$}`
+
+	// OPMLHandlerRoute defines the route for OPML-handler requests.
+	OPMLHandlerRoute = "/opml"
 
 	// RobotsTxtHandlerRoute defines the route for robotstxt-handler requests.
 	RobotsTxtHandlerRoute = "/robots.txt"
@@ -80,6 +122,9 @@ var (
 	// TypeAheadSearchHandlerRoute defines the route for typeahead-search-handler requests.
 	TypeAheadSearchHandlerRoute = "/search.json"
 
+	// SuggestHandlerRoute defines the route for the search-as-you-type suggestions.
+	SuggestHandlerRoute = "/search/suggest"
+
 	// TypeAheadTitlesHandlerRoute defines the route for typeahead-titles-handler requests.
 	TypeAheadTitlesHandlerRoute = "/titles.json"
 
@@ -91,8 +136,146 @@ var (
 
 	// AliasIndexHandlerRoute defines the route for alias-lookup-handler requests.
 	AliasIndexHandlerRoute = "/!"
+
+	// HealthHandlerRoute defines the route for health-check requests.
+	HealthHandlerRoute = "/-/healthz"
+
+	// ReadinessHandlerRoute defines the route for readiness-check requests.
+	ReadinessHandlerRoute = "/-/readyz"
+
+	// StatsHandlerRoute defines the route for the statistics page of the repository.
+	StatsHandlerRoute = "/-/stats"
+
+	// StatsJSONHandlerRoute defines the route for the statistics of the repository in JSON format.
+	StatsJSONHandlerRoute = "/-/stats.json"
+
+	// BrandingIconHandlerRoute defines the route for the favicons and touch icons of the site.
+	BrandingIconHandlerRoute = "/" + orchestrator.BrandingIconsPath + "{name:[^/]+$}"
+
+	// WebAppManifestHandlerRoute defines the route for the web app manifest.
+	WebAppManifestHandlerRoute = "/manifest.webmanifest"
+
+	// ServiceWorkerHandlerRoute defines the route for the service worker (its scope is the base path).
+	ServiceWorkerHandlerRoute = "/service-worker.js"
+
+	// MetricsHandlerRoute defines the route for metrics requests.
+	MetricsHandlerRoute = "/metrics"
+
+	// DebugPProfRoutePrefix defines the route-prefix for the pprof profiles.
+	DebugPProfRoutePrefix = "/-/debug/pprof/"
+
+	// DebugPProfHandlerRoute defines the route for the pprof profiles.
+	DebugPProfHandlerRoute = DebugPProfRoutePrefix + "{profile:.*$}"
+
+	// DebugVarsHandlerRoute defines the route for the snapshot of the runtime statistics.
+	DebugVarsHandlerRoute = "/-/debug/vars"
+
+	// APIItemsRoutePrefix defines the route-prefix for the items of the REST API.
+	APIItemsRoutePrefix = "/api/v1/items/"
+
+	// APIItemsHandlerRoute defines the route for the (paginated) list of all items of the REST API.
+	APIItemsHandlerRoute = "/api/v1/items"
+
+	// APIItemHandlerRoute defines the route for single items of the REST API.
+	APIItemHandlerRoute = APIItemsRoutePrefix + "{path:.*$}"
+
+	// APISearchHandlerRoute defines the route for the search of the REST API.
+	APISearchHandlerRoute = "/api/v1/search"
+
+	// APITagsRoutePrefix defines the route-prefix for the tags of the REST API.
+	APITagsRoutePrefix = "/api/v1/tags/"
+
+	// APITagsHandlerRoute defines the route for the list of all tags of the REST API.
+	APITagsHandlerRoute = "/api/v1/tags"
+
+	// APITagHandlerRoute defines the route for the items of a tag of the REST API.
+	APITagHandlerRoute = APITagsRoutePrefix + "{tag:.+$}"
+
+	// APITreeHandlerRoute defines the route for the item hierarchy of the REST API.
+	APITreeHandlerRoute = "/api/v1/tree"
+
+	// APIGraphHandlerRoute defines the route for the link graph of the REST API.
+	APIGraphHandlerRoute = "/api/v1/graph"
+
+	// OpenAPIHandlerRoute defines the route for the OpenAPI document of the REST API.
+	OpenAPIHandlerRoute = "/api/v1/openapi.json"
+
+	// GraphQLHandlerRoute defines the route for GraphQL queries.
+	GraphQLHandlerRoute = "/graphql"
 )
 
+// handlerNames contains short names for the handler routes (e.g. for metrics).
+var handlerNames = map[string]string{
+	TagmapHandlerRoute:                "tags",
+	ThemeHandlerRoute:                 "theme",
+	ThumbnailHandlerRoute:             "thumbnails",
+	PrintHandlerRoute:                 "print",
+	ReaderHandlerRoute:                "reader",
+	JSONHandlerRoute:                  "json",
+	MarkdownHandlerRoute:              "markdown",
+	LatestHandlerRoute:                "latest",
+	DOCXHandlerRoute:                  "docx",
+	ODTHandlerRoute:                   "odt",
+	PDFHandlerRoute:                   "pdf",
+	EPUBHandlerRoute:                  "epub",
+	ZIPHandlerRoute:                   "zip",
+	UpdateHandlerRoute:                "update",
+	UpdateEventsHandlerRoute:          "updateevents",
+	ItemHandlerRoute:                  "item",
+	SitemapHandlerRoute:               "sitemap",
+	RecentChangesHandlerRoute:         "recent",
+	RecentChangesFeedHandlerRoute:     "recentrss",
+	ArchiveHandlerRoute:               "archive",
+	XMLSitemapHandlerRoute:            "xmlsitemap",
+	RSSHandlerRoute:                   "rss",
+	JSONFeedHandlerRoute:              "jsonfeed",
+	AtomHandlerRoute:                  "atom",
+	OPMLHandlerRoute:                  "opml",
+	CalendarHandlerRoute:              "calendar",
+	RobotsTxtHandlerRoute:             "robotstxt",
+	SearchHandlerRoute:                "search",
+	OpenSearchDescriptionHandlerRoute: "opensearch",
+	TypeAheadSearchHandlerRoute:       "typeahead",
+	TypeAheadTitlesHandlerRoute:       "titles",
+	SuggestHandlerRoute:               "suggest",
+	AliasLookupHandlerRoute:           "alias",
+	AliasIndexHandlerRoute:            "aliasindex",
+	HealthHandlerRoute:                "healthz",
+	ReadinessHandlerRoute:             "readyz",
+	StatsHandlerRoute:                 "stats",
+	StatsJSONHandlerRoute:             "statsjson",
+	BrandingIconHandlerRoute:          "brandingicon",
+	WebAppManifestHandlerRoute:        "webappmanifest",
+	ServiceWorkerHandlerRoute:         "serviceworker",
+	MetricsHandlerRoute:               "metrics",
+	DebugPProfHandlerRoute:            "pprof",
+	DebugVarsHandlerRoute:             "debugvars",
+	APIItemsHandlerRoute:              "apiitems",
+	APIItemHandlerRoute:               "apiitem",
+	APISearchHandlerRoute:             "apisearch",
+	APITagsHandlerRoute:               "apitags",
+	APITagHandlerRoute:                "apitag",
+	APITreeHandlerRoute:               "apitree",
+	APIGraphHandlerRoute:              "apigraph",
+	OpenAPIHandlerRoute:               "openapi",
+	GraphQLHandlerRoute:               "graphql",
+}
+
+// GetHandlerName returns a short name for the handler with the given route (e.g. "item", "search").
+func GetHandlerName(route string) string {
+	if name, exists := handlerNames[route]; exists {
+		return name
+	}
+
+	return route
+}
+
+// IsInfrastructureRoute returns a flag indicating whether the given route belongs to a handler for
+// monitoring systems and load balancers (health checks, metrics) which must not require authentication.
+func IsInfrastructureRoute(route string) bool {
+	return route == HealthHandlerRoute || route == ReadinessHandlerRoute || route == MetricsHandlerRoute
+}
+
 // RouteAndHandler combines routes and http-handlers.
 type RouteAndHandler struct {
 	Route   string
@@ -115,7 +298,9 @@ func GetRedirectHandlers(logger logger.Logger, baseURITarget string, baseHandler
 }
 
 // GetBaseHandlers returns a full-list of all http-handlers in this package.
-func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvider templates.Provider, orchestratorFactory orchestrator.Factory, headerWriterFactory header.WriterFactory) HandlerList {
+// The thumbnail conversion service is optional and only used for status reports. The theme fingerprints
+// must be the ones the template provider uses to reference the theme files.
+func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvider templates.Provider, orchestratorFactory orchestrator.Factory, headerWriterFactory header.WriterFactory, thumbnailIndex *thumbnail.Index, thumbnailConversion *thumbnail.ConversionService, themeFingerprints *themes.Fingerprints) HandlerList {
 	handlers := make(HandlerList, 0)
 
 	// orchestrators
@@ -123,36 +308,78 @@ func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvide
 	viewModelOrchestrator := orchestratorFactory.NewViewModelOrchestrator()
 	fileOrchestrator := orchestratorFactory.NewFileOrchestrator()
 
+	// search and conversion requests share a common concurrency limit
+	limitExpensiveRequests := NewConcurrencyLimiter(0)
+	if config.Server.RateLimiting.Enabled {
+		limitExpensiveRequests = NewConcurrencyLimiter(config.Server.RateLimiting.MaxConcurrentExpensiveRequests)
+	}
+
 	// global handlers
-	errorHandler := Error(headerWriterFactory.Static(), templateProvider, navigationOrchestrator)
+	errorHandler := Error(headerWriterFactory.Static(),
+		templateProvider,
+		navigationOrchestrator,
+		viewModelOrchestrator,
+		config.Web.ErrorPages.NotFound)
+
+	internalServerErrorHandler := InternalServerError(headerWriterFactory.Static(),
+		templateProvider,
+		navigationOrchestrator,
+		viewModelOrchestrator,
+		config.Web.ErrorPages.InternalServerError)
 
 	itemHandler := Item(
 		logger,
-		headerWriterFactory.Dynamic(),
+		headerWriterFactory.Items(),
+		headerWriterFactory.Files(),
 		fileOrchestrator,
 		viewModelOrchestrator,
-		templateProvider, errorHandler)
+		templateProvider, errorHandler,
+		config.Web.Pagination.PageSize())
+
+	// theme: the files of the theme folders take precedence over the files of the built-in theme
+	themeFolders := config.ThemeAssetFolders()
+	newThemeHandler := func(headerWriter header.HeaderWriter) http.Handler {
+		themeHandler := InMemoryTheme(
+			"/"+config.Server.ThemeFolderName+"/",
+			headerWriter,
+			errorHandler)
+
+		for index := len(themeFolders) - 1; index >= 0; index-- {
+			if fsutil.DirectoryExists(themeFolders[index]) {
+				themeHandler = ThemeFolder(themeFolders[index], headerWriter, themeHandler)
+			}
+		}
+
+		return themeHandler
+	}
 
-	// theme
-	if themeFolder := config.ThemeFolder(); fsutil.DirectoryExists(themeFolder) {
-		requestPrefixToStripFromRequestURI := "/" + config.Server.ThemeFolderName
+	handlers.Add(ThemeHandlerRoute, FingerprintedTheme(
+		themeFingerprints,
+		newThemeHandler(headerWriterFactory.Immutable()),
+		newThemeHandler(headerWriterFactory.Theme())))
 
-		handlers.Add(
-			ThemeHandlerRoute,
-			AddETAgToStaticFileHandler(
-				Static(
-					themeFolder,
-					ThemeRoutePrefix),
-				headerWriterFactory.Static(), themeFolder, requestPrefixToStripFromRequestURI))
+	// favicons and touch icons
+	handlers.Add(
+		BrandingIconHandlerRoute,
+		BrandingIcon(headerWriterFactory.Theme(),
+			orchestratorFactory.NewBrandingOrchestrator(),
+			errorHandler))
 
-	} else {
+	// web app manifest and service worker
+	if config.Web.WebApp.Enabled {
+		webAppOrchestrator := orchestratorFactory.NewWebAppOrchestrator()
 
 		handlers.Add(
-			ThemeHandlerRoute,
-			InMemoryTheme(
-				"/"+config.Server.ThemeFolderName+"/",
-				headerWriterFactory.Static(),
-				errorHandler))
+			WebAppManifestHandlerRoute,
+			WebAppManifest(headerWriterFactory.Static(), webAppOrchestrator))
+
+		handlers.Add(
+			ServiceWorkerHandlerRoute,
+			ServiceWorker(headerWriterFactory.NoCache(),
+				templateProvider,
+				webAppOrchestrator,
+				config.BasePath(),
+				themeFingerprints))
 	}
 
 	// alias lookup
@@ -166,7 +393,7 @@ func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvide
 	handlers.Add(
 		AliasIndexHandlerRoute,
 		AliasIndex(
-			headerWriterFactory.Dynamic(),
+			headerWriterFactory.Items(),
 			navigationOrchestrator,
 			orchestratorFactory.NewAliasIndexOrchestrator(),
 			templateProvider))
@@ -179,46 +406,138 @@ func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvide
 			ThumbnailHandlerRoute,
 			AddETAgToStaticFileHandler(Static(thumbnailsFolder,
 				ThumbnailRoutePrefix),
-				headerWriterFactory.Static(),
+				headerWriterFactory.Thumbnails(),
 				thumbnailsFolder,
 				requestPrefixToStripFromRequestURI))
 	}
 
+	// health and readiness checks
+	statusOrchestrator := orchestratorFactory.NewStatusOrchestrator(thumbnailConversion)
+	handlers.Add(HealthHandlerRoute, Health(headerWriterFactory.NoCache(), statusOrchestrator))
+	handlers.Add(ReadinessHandlerRoute, Readiness(headerWriterFactory.NoCache(), statusOrchestrator))
+
+	// metrics
+	if config.Server.Metrics.Enabled {
+		handlers.Add(MetricsHandlerRoute, Metrics(headerWriterFactory.NoCache(), statusOrchestrator))
+	}
+
+	// profiling and diagnostics (restricted to localhost or, otherwise, always protected by the authentication)
+	if config.Server.Debug.Enabled {
+		debugProfilesHandler := DebugProfiles()
+		debugVarsHandler := DebugVars(headerWriterFactory.NoCache(), statusOrchestrator)
+
+		debugIsEnabled := true
+		if config.Server.Debug.LocalhostOnly {
+			debugProfilesHandler = RestrictToLocalhost(config.Server.Debug.UnixSocketIsLocal, debugProfilesHandler)
+			debugVarsHandler = RestrictToLocalhost(config.Server.Debug.UnixSocketIsLocal, debugVarsHandler)
+
+		} else if config.AuthenticationIsEnabled() {
+
+			// the debug endpoints always require authentication regardless of the authentication rules
+			debugProfilesHandler = RequireDigestAuthentication(logger,
+				debugProfilesHandler,
+				config.GetAuthenticationUserStore(),
+				config.DebugAuthentication())
+
+			debugVarsHandler = RequireDigestAuthentication(logger,
+				debugVarsHandler,
+				config.GetAuthenticationUserStore(),
+				config.DebugAuthentication())
+
+		} else {
+			logger.Error("The debug endpoints are not available because they are not restricted to localhost and authentication is disabled.")
+			debugIsEnabled = false
+		}
+
+		if debugIsEnabled {
+			handlers.Add(DebugPProfHandlerRoute, debugProfilesHandler)
+			handlers.Add(DebugVarsHandlerRoute, debugVarsHandler)
+		}
+	}
+
 	// robots.txt
-	handlers.Add(RobotsTxtHandlerRoute, RobotsTxt(headerWriterFactory.Static(), templateProvider))
+	handlers.Add(RobotsTxtHandlerRoute, RobotsTxt(headerWriterFactory.Static(), templateProvider, config.BasePath(), config.Web.RobotsTxt))
 
 	// sitemap.html
 	handlers.Add(
 		SitemapHandlerRoute,
-		Sitemap(headerWriterFactory.Dynamic(),
+		Sitemap(headerWriterFactory.Items(),
 			navigationOrchestrator,
 			orchestratorFactory.NewSitemapOrchestrator(),
 			templateProvider))
 
+	// recent changes
+	recentChangesLimit := config.Web.RecentChanges.Limit()
+
+	handlers.Add(
+		RecentChangesHandlerRoute,
+		RecentChanges(headerWriterFactory.Dynamic(),
+			navigationOrchestrator,
+			orchestratorFactory.NewRecentChangesOrchestrator(),
+			templateProvider,
+			recentChangesLimit))
+
+	handlers.Add(
+		RecentChangesFeedHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			RecentChangesFeed(headerWriterFactory.RSS(),
+				orchestratorFactory.NewFeedOrchestrator(),
+				templateProvider,
+				errorHandler,
+				recentChangesLimit)))
+
+	// archive
+	handlers.Add(
+		ArchiveHandlerRoute,
+		Archive(headerWriterFactory.Items(),
+			navigationOrchestrator,
+			orchestratorFactory.NewArchiveOrchestrator(),
+			templateProvider,
+			errorHandler))
+
+	// statistics
+	statsOrchestrator := orchestratorFactory.NewStatsOrchestrator()
+
+	handlers.Add(
+		StatsHandlerRoute,
+		Stats(headerWriterFactory.Dynamic(),
+			navigationOrchestrator,
+			statsOrchestrator,
+			templateProvider))
+
+	handlers.Add(
+		StatsJSONHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			StatsJSON(headerWriterFactory.JSON(), statsOrchestrator)))
+
 	// tags.html
 	handlers.Add(
 		TagmapHandlerRoute,
-		Tags(headerWriterFactory.Dynamic(),
+		Tags(headerWriterFactory.Items(),
 			navigationOrchestrator,
 			orchestratorFactory.NewTagsOrchestrator(),
-			templateProvider))
+			templateProvider,
+			errorHandler,
+			config.BasePath(),
+			config.Web.Pagination.PageSize()))
 
 	// search
 	handlers.Add(
 		SearchHandlerRoute,
-		Search(
+		limitExpensiveRequests(Search(
 			headerWriterFactory.Dynamic(),
 			navigationOrchestrator,
 			orchestratorFactory.NewSearchOrchestrator(),
 			templateProvider,
-			errorHandler))
+			errorHandler)))
 
 	// sitemap.xml
 	handlers.Add(
 		XMLSitemapHandlerRoute,
 		XMLSitemap(headerWriterFactory.Dynamic(),
-			orchestratorFactory.NewXMLSitemapOrchestrator(),
-			templateProvider))
+			orchestratorFactory.NewXMLSitemapOrchestrator(thumbnailIndex),
+			templateProvider,
+			errorHandler))
 
 	// opensearch.xml
 	handlers.Add(
@@ -230,37 +549,154 @@ func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvide
 	// titles.json
 	handlers.Add(
 		TypeAheadTitlesHandlerRoute,
-		Titles(headerWriterFactory.Dynamic(),
-			orchestratorFactory.NewTitlesOrchestrator()))
+		AllowCrossOriginRequests(config.Server.CORS,
+			Titles(headerWriterFactory.JSON(),
+				orchestratorFactory.NewTitlesOrchestrator())))
 
 	// search.json
 	handlers.Add(
 		TypeAheadSearchHandlerRoute,
-		TypeAhead(headerWriterFactory.Dynamic(),
-			orchestratorFactory.NewTypeAheadOrchestrator()))
+		AllowCrossOriginRequests(config.Server.CORS,
+			limitExpensiveRequests(TypeAhead(headerWriterFactory.JSON(),
+				orchestratorFactory.NewTypeAheadOrchestrator()))))
+
+	// search/suggest
+	handlers.Add(
+		SuggestHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			limitExpensiveRequests(Suggest(headerWriterFactory.JSON(),
+				orchestratorFactory.NewTypeAheadOrchestrator()))))
 
 	// latest.json
-	handlers.Add(LatestHandlerRoute, Latest(logger, headerWriterFactory.Dynamic(), viewModelOrchestrator, itemHandler))
+	handlers.Add(
+		LatestHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			Latest(logger, headerWriterFactory.JSON(), viewModelOrchestrator, itemHandler)))
 
 	// rss
 	handlers.Add(
 		RSSHandlerRoute,
-		RSS(headerWriterFactory.Dynamic(),
-			orchestratorFactory.NewFeedOrchestrator(),
-			templateProvider,
-			errorHandler))
+		AllowCrossOriginRequests(config.Server.CORS,
+			RSS(headerWriterFactory.RSS(),
+				orchestratorFactory.NewFeedOrchestrator(),
+				templateProvider,
+				errorHandler,
+				config.Web.Feeds.PageSize())))
+
+	// atom
+	handlers.Add(
+		AtomHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			Atom(headerWriterFactory.RSS(),
+				orchestratorFactory.NewFeedOrchestrator(),
+				templateProvider,
+				errorHandler,
+				config.Web.Feeds.PageSize())))
+
+	// json feed
+	handlers.Add(
+		JSONFeedHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			JSONFeed(logger,
+				headerWriterFactory.RSS(),
+				orchestratorFactory.NewFeedOrchestrator(),
+				errorHandler,
+				config.Web.Feeds.PageSize())))
+
+	// calendar
+	handlers.Add(
+		CalendarHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			Calendar(logger,
+				headerWriterFactory.RSS(),
+				orchestratorFactory.NewCalendarOrchestrator(),
+				errorHandler)))
+
+	// opml
+	handlers.Add(
+		OPMLHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			OPML(headerWriterFactory.RSS(),
+				orchestratorFactory.NewOPMLOrchestrator(),
+				templateProvider)))
+
+	// REST API
+	apiOrchestrator := orchestratorFactory.NewAPIOrchestrator()
+
+	handlers.Add(APIItemsHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			APIItems(logger, headerWriterFactory.JSON(), apiOrchestrator)))
+
+	apiItemHandler := APIItem(logger, headerWriterFactory.JSON(), apiOrchestrator)
+
+	writeAPIIsEnabled := false
+	if config.Server.WriteAPI.Enabled {
+		if config.AuthenticationIsEnabled() && apiOrchestrator.IsWritable() {
+			logger.Info("Write API: On")
+			writeAPIIsEnabled = true
+
+			// modifications always require authentication regardless of the authentication rules
+			apiItemHandler = DispatchWriteRequests(apiItemHandler,
+				RequireDigestAuthentication(logger,
+					APIWrite(logger, headerWriterFactory.NoCache(), apiOrchestrator, config.WriteAPIMaxUploadSize()),
+					config.GetAuthenticationUserStore(),
+					config.WriteAPIAuthentication()))
+
+		} else {
+			logger.Error("The write API is not available because authentication is disabled or the repository cannot be modified.")
+		}
+	}
+
+	handlers.Add(APIItemHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			apiItemHandler))
+
+	handlers.Add(APISearchHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			limitExpensiveRequests(APISearch(logger, headerWriterFactory.JSON(), apiOrchestrator))))
+
+	handlers.Add(APITagsHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			APITags(logger, headerWriterFactory.JSON(), apiOrchestrator)))
 
-	// json
+	handlers.Add(APITagHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			APITag(logger, headerWriterFactory.JSON(), apiOrchestrator)))
+
+	handlers.Add(APITreeHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			APITree(logger, headerWriterFactory.JSON(), apiOrchestrator)))
+
+	handlers.Add(APIGraphHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			APIGraph(logger, headerWriterFactory.JSON(), apiOrchestrator)))
+
+	handlers.Add(OpenAPIHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			OpenAPI(logger, headerWriterFactory.JSON(), config.BasePath(), writeAPIIsEnabled)))
+
+	// GraphQL
+	if config.Server.GraphQL.Enabled {
+		logger.Info("GraphQL: On")
+
+		handlers.Add(GraphQLHandlerRoute,
+			AllowCrossOriginRequests(config.Server.CORS,
+				limitExpensiveRequests(GraphQL(logger, headerWriterFactory.JSON(), apiOrchestrator, config.GraphQLMaxDepth()))))
+	}
+
+	// json (after the REST API, which has routes ending with ".json")
 	handlers.Add(JSONHandlerRoute,
-		JSON(headerWriterFactory.Dynamic(),
-			viewModelOrchestrator,
-			itemHandler))
+		AllowCrossOriginRequests(config.Server.CORS,
+			JSON(headerWriterFactory.JSON(),
+				viewModelOrchestrator,
+				itemHandler)))
 
 	// markdown
 	handlers.Add(MarkdownHandlerRoute,
-		Markdown(headerWriterFactory.Dynamic(),
-			viewModelOrchestrator,
-			itemHandler))
+		AllowCrossOriginRequests(config.Server.CORS,
+			Markdown(headerWriterFactory.Dynamic(),
+				viewModelOrchestrator,
+				itemHandler)))
 
 	// conversion
 	conversionModelOrchestrator := orchestratorFactory.NewConversionModelOrchestrator()
@@ -268,38 +704,122 @@ func GetBaseHandlers(logger logger.Logger, config config.Config, templateProvide
 	// print
 	handlers.Add(
 		PrintHandlerRoute,
-		Print(logger,
-			headerWriterFactory.Dynamic(),
+		limitExpensiveRequests(Print(logger,
+			headerWriterFactory.Items(),
 			conversionModelOrchestrator,
 			templateProvider,
-			errorHandler))
+			errorHandler)))
 
-	// docx
+	// reader
+	handlers.Add(
+		ReaderHandlerRoute,
+		AllowCrossOriginRequests(config.Server.CORS,
+			limitExpensiveRequests(Reader(headerWriterFactory.Items(),
+				conversionModelOrchestrator,
+				errorHandler))))
+
+	// docx and odt (attached DOCX and ODT files are passed to the item handler)
 	conversionEndpointTCPAddress := config.Conversion.EndpointBinding().GetTCPAddress()
 	conversionEndpointAddress := conversionEndpointTCPAddress.String()
+	richTextConverter := richtext.NewConverter(logger, config.Conversion.DOCX.Tool(), config.ConversionFolder())
 	handlers.Add(
 		DOCXHandlerRoute,
 		DOCX(logger,
-			config.Conversion.DOCX.Tool(),
+			config.Conversion.DOCX,
+			richTextConverter,
 			conversionEndpointAddress,
 			headerWriterFactory.Dynamic(),
 			conversionModelOrchestrator,
 			templateProvider,
-			errorHandler))
+			limitExpensiveRequests,
+			itemHandler))
 
-	// update
 	handlers.Add(
-		UpdateHandlerRoute,
-		Update(
-			logger,
+		ODTHandlerRoute,
+		ODT(logger,
+			config.Conversion.DOCX,
+			richTextConverter,
+			conversionEndpointAddress,
 			headerWriterFactory.Dynamic(),
+			conversionModelOrchestrator,
 			templateProvider,
-			orchestratorFactory.NewUpdateOrchestrator()))
+			limitExpensiveRequests,
+			itemHandler))
+
+	// pdf (attached PDF files are passed to the item handler)
+	handlers.Add(
+		PDFHandlerRoute,
+		PDF(logger,
+			config.Conversion.PDF,
+			conversionEndpointAddress,
+			headerWriterFactory.Dynamic(),
+			conversionModelOrchestrator,
+			templateProvider,
+			limitExpensiveRequests,
+			itemHandler))
+
+	// epub (attached EPUB files are passed to the item handler)
+	handlers.Add(
+		EPUBHandlerRoute,
+		EPUB(logger,
+			config.Conversion.EPUB.Enabled,
+			config.BasePath(),
+			headerWriterFactory.Dynamic(),
+			conversionModelOrchestrator,
+			fileOrchestrator,
+			limitExpensiveRequests,
+			itemHandler))
+
+	// zip (attached ZIP files are passed to the item handler)
+	if config.Server.ZIPDownload.Enabled {
+		if config.AuthenticationIsEnabled() {
+			logger.Info("ZIP Downloads: On")
+
+			// downloads always require authentication regardless of the authentication rules
+			requireAuthentication := func(handler http.Handler) http.Handler {
+				return RequireDigestAuthentication(logger,
+					handler,
+					config.GetAuthenticationUserStore(),
+					config.ZIPDownloadAuthentication())
+			}
+
+			handlers.Add(
+				ZIPHandlerRoute,
+				ZIP(logger,
+					headerWriterFactory.NoCache(),
+					orchestratorFactory.NewDownloadOrchestrator(),
+					conversionModelOrchestrator,
+					templateProvider,
+					requireAuthentication,
+					limitExpensiveRequests,
+					itemHandler))
+
+		} else {
+			logger.Error("The ZIP downloads are not available because authentication is disabled.")
+		}
+	}
+
+	// update (websocket and server-sent events)
+	updateOrchestrator := orchestratorFactory.NewUpdateOrchestrator()
+	updateHub := newUpdateHub(logger, config.LiveReload, templateProvider, updateOrchestrator)
+
+	handlers.Add(
+		UpdateHandlerRoute,
+		Update(logger, updateHub, updateOrchestrator))
+
+	handlers.Add(
+		UpdateEventsHandlerRoute,
+		UpdateEvents(logger, headerWriterFactory.NoCache(), updateHub, updateOrchestrator))
 
 	// items
 	handlers.Add(
 		ItemHandlerRoute,
 		itemHandler)
 
+	// display the error page if a handler fails
+	for index, routeAndHandler := range handlers {
+		handlers[index].Handler = RecoverFromPanics(logger, internalServerErrorHandler, routeAndHandler.Handler)
+	}
+
 	return handlers
 }
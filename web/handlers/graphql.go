@@ -0,0 +1,342 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/web/graphql"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+const (
+	// contentTypeGraphQL is the content type of requests whose body is a GraphQL query.
+	contentTypeGraphQL = "application/graphql"
+
+	// graphQLMaxRequestSize is the maximum size of a GraphQL request body in bytes.
+	graphQLMaxRequestSize = 1 << 20
+)
+
+// GraphQL returns a handler which executes GraphQL queries over the items, files and tags of the repository.
+// Queries can be sent as GET requests ("?query=...&variables=...&operationName=...") or as POST requests
+// with a JSON body ({"query": "...", "variables": {...}, "operationName": "..."}) or a GraphQL body.
+// The queries only resolve the items the user of the request is allowed to access.
+func GraphQL(logger logger.Logger, headerWriter header.HeaderWriter, apiOrchestrator *orchestrator.APIOrchestrator, maxDepth int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		request, err := getGraphQLRequest(w, r)
+		if err != nil {
+			writeGraphQLResponse(logger, headerWriter, w, r, graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}})
+			return
+		}
+
+		root := getGraphQLRoot(apiOrchestrator, apiOrchestrator.GetVisibility(getUsername(r)))
+		writeGraphQLResponse(logger, headerWriter, w, r, graphql.Execute(root, request, maxDepth))
+	})
+}
+
+// getGraphQLRequest reads the GraphQL request from the URL parameters (GET) or the body (POST) of the given request.
+func getGraphQLRequest(w http.ResponseWriter, r *http.Request) (graphql.Request, error) {
+	var request graphql.Request
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		parameters := r.URL.Query()
+		request.Query = parameters.Get("query")
+		request.OperationName = parameters.Get("operationName")
+
+		if variables := parameters.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return request, fmt.Errorf("The variables must be a JSON object.")
+			}
+		}
+
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, graphQLMaxRequestSize))
+		if err != nil {
+			return request, fmt.Errorf("The request body could not be read or is larger than %d bytes.", graphQLMaxRequestSize)
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/json":
+			if err := json.Unmarshal(body, &request); err != nil {
+				return request, fmt.Errorf("The request body is not a valid GraphQL request: %s", err)
+			}
+
+		case contentTypeGraphQL:
+			request.Query = string(body)
+
+		default:
+			return request, fmt.Errorf("The content type of the request must be %q or %q.", "application/json", contentTypeGraphQL)
+		}
+
+	default:
+		return request, fmt.Errorf("GraphQL queries must be sent with GET or POST requests.")
+	}
+
+	if request.Query == "" {
+		return request, fmt.Errorf("The query must not be empty.")
+	}
+
+	return request, nil
+}
+
+// writeGraphQLResponse writes the given GraphQL response as JSON. Requests that could not be executed
+// (the response has no data) are answered with the status code 400.
+func writeGraphQLResponse(logger logger.Logger, headerWriter header.HeaderWriter, w http.ResponseWriter, r *http.Request, response graphql.Response) {
+	if response.Data != nil {
+		writeAPIResponse(logger, headerWriter, w, r, response)
+		return
+	}
+
+	jsonBytes, err := json.MarshalIndent(response, "", "\t")
+	if err != nil {
+		logger.Error("Unable to convert the GraphQL response to json. Error: %s", err)
+		http.Error(w, "The response could not be created.", http.StatusInternalServerError)
+		return
+	}
+
+	headerWriter.Write(w, header.CONTENTTYPE_JSON)
+	header.NoCache(w)
+
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(jsonBytes)
+}
+
+// getGraphQLRoot returns the entry points of the GraphQL queries which resolve the items that can be shown with the given visibility.
+func getGraphQLRoot(apiOrchestrator *orchestrator.APIOrchestrator, visibility orchestrator.Visibility) graphql.Object {
+	return graphql.NewObject("Query", map[string]graphql.Field{
+
+		// item(route: String = ""): Item
+		"item": {
+			Arguments: []string{"route"},
+			Resolve: func(arguments graphql.Arguments) (interface{}, error) {
+				itemRoute, err := arguments.String("route", "")
+				if err != nil {
+					return nil, err
+				}
+
+				itemDetails, found := apiOrchestrator.GetItem(route.NewFromRequest(itemRoute), visibility)
+				if !found {
+					return nil, nil
+				}
+
+				return getGraphQLItem(apiOrchestrator, itemDetails.APIItem, visibility), nil
+			},
+		},
+
+		// items(page: Int = 1, pageSize: Int = 20): ItemList
+		"items": {
+			Arguments: []string{"page", "pageSize"},
+			Resolve: func(arguments graphql.Arguments) (interface{}, error) {
+				page, pageSize, err := getGraphQLPaging(arguments)
+				if err != nil {
+					return nil, err
+				}
+
+				itemList := apiOrchestrator.GetItems(pageSize, page, visibility)
+
+				object := graphql.ObjectOf("ItemList", itemList)
+				object.Fields["items"] = graphql.Value(getGraphQLItems(apiOrchestrator, itemList.Items, visibility))
+				return object, nil
+			},
+		},
+
+		// search(query: String!, tags: [String], types: [String], page: Int = 1, pageSize: Int = 20, cursor: String): SearchResults
+		"search": {
+			Arguments: []string{"query", "tags", "types", "page", "pageSize", "cursor"},
+			Resolve: func(arguments graphql.Arguments) (interface{}, error) {
+				query, err := arguments.String("query", "")
+				if err != nil {
+					return nil, err
+				}
+
+				if query == "" {
+					return nil, fmt.Errorf("The search query must not be empty.")
+				}
+
+				var filter orchestrator.APISearchFilter
+				if filter.Tags, err = arguments.Strings("tags"); err != nil {
+					return nil, err
+				}
+
+				if filter.Types, err = arguments.Strings("types"); err != nil {
+					return nil, err
+				}
+
+				page, pageSize, err := getGraphQLPaging(arguments)
+				if err != nil {
+					return nil, err
+				}
+
+				offset := pageSize * (page - 1)
+				cursor, err := arguments.String("cursor", "")
+				if err != nil {
+					return nil, err
+				}
+
+				if cursor != "" {
+					if offset, err = apiOrchestrator.ParseSearchCursor(cursor, query, filter, pageSize); err != nil {
+						return nil, err
+					}
+				}
+
+				results := apiOrchestrator.Search(query, filter, offset, pageSize, visibility)
+
+				hits := make([]graphql.Object, 0, len(results.Hits))
+				for _, hit := range results.Hits {
+					hits = append(hits, graphql.NewObject("SearchHit", map[string]graphql.Field{
+						"score":              graphql.Value(hit.Score),
+						"snippet":            graphql.Value(hit.Snippet),
+						"highlightedSnippet": graphql.Value(hit.HighlightedSnippet),
+						"item":               graphql.Value(getGraphQLItem(apiOrchestrator, hit.APIItem, visibility)),
+						"file":               graphql.Value(hit.File),
+					}))
+				}
+
+				object := graphql.ObjectOf("SearchResults", results)
+				object.Fields["hits"] = graphql.Value(hits)
+				return object, nil
+			},
+		},
+
+		// tags: [Tag]
+		"tags": {
+			Resolve: func(arguments graphql.Arguments) (interface{}, error) {
+				tags := make([]graphql.Object, 0)
+				for _, tag := range apiOrchestrator.GetTags(visibility) {
+					tags = append(tags, getGraphQLTag(apiOrchestrator, tag, visibility))
+				}
+
+				return tags, nil
+			},
+		},
+
+		// tag(name: String!): Tag
+		"tag": {
+			Arguments: []string{"name"},
+			Resolve: func(arguments graphql.Arguments) (interface{}, error) {
+				name, err := arguments.String("name", "")
+				if err != nil {
+					return nil, err
+				}
+
+				tagDetails, found := apiOrchestrator.GetTag(name, visibility)
+				if !found {
+					return nil, nil
+				}
+
+				return getGraphQLTag(apiOrchestrator, tagDetails.APITag, visibility), nil
+			},
+		},
+	})
+}
+
+// getGraphQLItem returns the GraphQL object of the given item. The content, the files, the children
+// and the parent of the item are only loaded if they are selected (and can be shown with the given visibility).
+func getGraphQLItem(apiOrchestrator *orchestrator.APIOrchestrator, apiItem viewmodel.APIItem, visibility orchestrator.Visibility) graphql.Object {
+	object := graphql.ObjectOf("Item", apiItem)
+	itemRoute := route.NewFromRequest(apiItem.Route)
+
+	var itemDetails *viewmodel.APIItemDetails
+	getDetails := func() viewmodel.APIItemDetails {
+		if itemDetails == nil {
+			details, _ := apiOrchestrator.GetItem(itemRoute, visibility)
+			itemDetails = &details
+		}
+
+		return *itemDetails
+	}
+
+	object.Fields["content"] = getGraphQLField(func() interface{} { return getDetails().Content })
+	object.Fields["markdown"] = getGraphQLField(func() interface{} { return getDetails().Markdown })
+	object.Fields["files"] = getGraphQLField(func() interface{} { return getDetails().Files })
+	object.Fields["location"] = getGraphQLField(func() interface{} { return getDetails().Location })
+
+	object.Fields["children"] = getGraphQLField(func() interface{} {
+		return getGraphQLItems(apiOrchestrator, getDetails().Children, visibility)
+	})
+
+	object.Fields["parent"] = getGraphQLField(func() interface{} {
+		parentRoute, exists := itemRoute.Parent()
+		if !exists || apiItem.Route == "" {
+			return nil
+		}
+
+		parent, found := apiOrchestrator.GetItem(parentRoute, visibility)
+		if !found {
+			return nil
+		}
+
+		return getGraphQLItem(apiOrchestrator, parent.APIItem, visibility)
+	})
+
+	return object
+}
+
+// getGraphQLItems returns the GraphQL objects of the given items.
+func getGraphQLItems(apiOrchestrator *orchestrator.APIOrchestrator, apiItems []viewmodel.APIItem, visibility orchestrator.Visibility) []graphql.Object {
+	items := make([]graphql.Object, 0, len(apiItems))
+	for _, apiItem := range apiItems {
+		items = append(items, getGraphQLItem(apiOrchestrator, apiItem, visibility))
+	}
+
+	return items
+}
+
+// getGraphQLTag returns the GraphQL object of the given tag. The items are only loaded if they are selected.
+func getGraphQLTag(apiOrchestrator *orchestrator.APIOrchestrator, apiTag viewmodel.APITag, visibility orchestrator.Visibility) graphql.Object {
+	object := graphql.ObjectOf("Tag", apiTag)
+
+	object.Fields["items"] = getGraphQLField(func() interface{} {
+		tagDetails, _ := apiOrchestrator.GetTag(apiTag.Name, visibility)
+		return getGraphQLItems(apiOrchestrator, tagDetails.Items, visibility)
+	})
+
+	return object
+}
+
+// getGraphQLField returns a field without arguments whose value is resolved by the given function.
+func getGraphQLField(resolve func() interface{}) graphql.Field {
+	return graphql.Field{
+		Resolve: func(arguments graphql.Arguments) (interface{}, error) {
+			return resolve(), nil
+		},
+	}
+}
+
+// getGraphQLPaging returns the values of the "page" and "pageSize" arguments.
+func getGraphQLPaging(arguments graphql.Arguments) (page, pageSize int, err error) {
+	if page, err = arguments.Int("page", 1); err != nil {
+		return 0, 0, err
+	}
+
+	if page < 1 {
+		return 0, 0, fmt.Errorf("The page must be a positive number.")
+	}
+
+	if pageSize, err = arguments.Int("pageSize", apiDefaultPageSize); err != nil {
+		return 0, 0, err
+	}
+
+	if pageSize < 1 || pageSize > apiMaxPageSize {
+		return 0, 0, fmt.Errorf("The page size must be a number between 1 and %d.", apiMaxPageSize)
+	}
+
+	return page, pageSize, nil
+}
@@ -5,29 +5,40 @@
 package handlers
 
 import (
+	"bytes"
 	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/util/hashutil"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
 	"github.com/andreaskoch/allmark/web/view/templates"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"io"
 	"net/http"
+	"text/template"
 )
 
 func Item(logger logger.Logger,
 	headerWriter header.HeaderWriter,
+	fileHeaderWriter header.HeaderWriter,
 	fileOrchestrator *orchestrator.FileOrchestrator,
 	viewModelOrchestrator *orchestrator.ViewModelOrchestrator,
 	templateProvider templates.Provider,
-	error404Handler http.Handler) http.Handler {
+	error404Handler http.Handler,
+	itemsPerPage int) http.Handler {
 
-	render := func(writer io.Writer, baseURL string, viewModel viewmodel.Model) {
+	render := func(r *http.Request, writer io.Writer, baseURL string, viewModel viewmodel.Model) {
+
+		logger := requestLogger(logger, r)
 
 		// get a template
-		templateName := viewModel.Type
-		template, err := templateProvider.GetItemTemplate(templateName, baseURL)
+		template, err := getItemTemplate(templateProvider, viewModel, baseURL)
+		if err != nil && viewModel.Template != "" {
+			logger.Warn("Unable to use the template %q for the item %q. Error: %s", viewModel.Template, viewModel.Route, err)
+			template, err = templateProvider.GetItemTemplate(viewModel.Type, baseURL)
+		}
+
 		if err != nil {
-			logger.Error("No template for item of type %q.", templateName)
+			logger.Error("No template for item of type %q.", viewModel.Type)
 			return
 		}
 
@@ -41,6 +52,8 @@ func Item(logger logger.Logger,
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
+		logger := requestLogger(logger, r)
+
 		baseURL := getBaseURLFromRequest(r)
 
 		// get the request route
@@ -52,15 +65,36 @@ func Item(logger logger.Logger,
 		logger.Debug("Requesting %q", requestRoute)
 
 		// stage 1: check if there is a item for the request
-		if model, found := viewModelOrchestrator.GetFullViewModel(requestRoute); found {
+		if model, found := viewModelOrchestrator.GetFullViewModel(requestRoute, viewModelOrchestrator.GetVisibility(getUsername(r))); found {
 
 			logger.Debug("Returning item %q", requestRoute)
 
-			// set headers
+			// display only the requested page of the children
+			page := 1
+			if pageParam, isAvailable := getPageParameterFromURL(*r.URL); isAvailable {
+				page = pageParam
+			}
+
+			if model, found = viewModelOrchestrator.PageChildren(model, itemsPerPage, page); !found {
+				error404Handler.ServeHTTP(w, r)
+				return
+			}
+
+			// render the page
+			buffer := new(bytes.Buffer)
+			render(r, buffer, baseURL, model)
+
+			// set headers (the entity tag covers the rendered page including navigation and children)
 			headerWriter.Write(w, header.CONTENTTYPE_HTML)
-			header.ETag(w, model.Hash)
+			header.ETag(w, hashutil.FromBytes(buffer.Bytes()))
+			header.LastModified(w, viewModelOrchestrator.LastUpdate())
+
+			// the client already has the current version
+			if header.NotModified(w, r) {
+				return
+			}
 
-			render(w, baseURL, model)
+			w.Write(buffer.Bytes())
 			return
 		}
 
@@ -70,22 +104,28 @@ func Item(logger logger.Logger,
 			logger.Debug("Returning file %q", requestRoute)
 
 			// set  headers
-			headerWriter.Write(w, file.MimeType)
+			fileHeaderWriter.Write(w, file.MimeType)
 			header.ETag(w, file.Hash)
+			header.LastModified(w, file.LastModified)
 
 			// get the content provider
 			contentProvider := fileOrchestrator.GetFileContentProvider(requestRoute)
 			if contentProvider == nil {
 				logger.Error("There is no content provider for file %q", requestRoute)
+				error404Handler.ServeHTTP(w, r)
+				return
 			}
 
 			filename := file.Name
 			lastModifiedTime := file.LastModified
 
-			contentProvider.Data(func(content io.ReadSeeker) error {
+			// serve the file with support for range requests (e.g. for seeking in audio and video files)
+			if err := contentProvider.Data(func(content io.ReadSeeker) error {
 				http.ServeContent(w, r, filename, lastModifiedTime, content)
 				return nil
-			})
+			}); err != nil {
+				logger.Error("Unable to read file %q. Error: %s", requestRoute, err)
+			}
 
 			return
 		}
@@ -96,3 +136,13 @@ func Item(logger logger.Logger,
 		error404Handler.ServeHTTP(w, r)
 	})
 }
+
+// getItemTemplate returns the template the given view model selects (see viewmodel.Base.Template)
+// or, if it does not select one, the template for its item type.
+func getItemTemplate(templateProvider templates.Provider, viewModel viewmodel.Model, hostname string) (*template.Template, error) {
+	if viewModel.Template == "" {
+		return templateProvider.GetItemTemplate(viewModel.Type, hostname)
+	}
+
+	return templateProvider.GetCustomItemTemplate(viewModel.Template, hostname)
+}
@@ -31,7 +31,7 @@ func JSON(headerWriter header.HeaderWriter, viewModelOrchestrator *orchestrator.
 		defer r.Body.Close()
 
 		// stage 1: check if there is a item for the request
-		if viewModel, found := viewModelOrchestrator.GetFullViewModel(requestRoute); found {
+		if viewModel, found := viewModelOrchestrator.GetFullViewModel(requestRoute, viewModelOrchestrator.GetVisibility(getUsername(r))); found {
 			renderViewModelAsJSON(viewModel, w)
 			return
 		}
@@ -0,0 +1,71 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// JSONFeed creates a new JSON Feed handler (see https://www.jsonfeed.org/version/1.1/).
+func JSONFeed(logger logger.Logger,
+	headerWriter header.HeaderWriter,
+	feedOrchestrator *orchestrator.FeedOrchestrator,
+	error404Handler http.Handler,
+	itemsPerPage int) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// get the current baseURL
+		baseURL := getBaseURLFromRequest(r)
+
+		// read the page url-parameter
+		page, pageParameterIsAvailable := getPageParameterFromURL(*r.URL)
+		if !pageParameterIsAvailable || page == 0 {
+			page = 1
+		}
+
+		// the "tag" url-parameter limits the feed to the items with the given tag
+		tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+
+		feedRoute := strings.TrimPrefix(JSONFeedHandlerRoute, "/")
+		feedModel, err := feedOrchestrator.GetJSONFeed(baseURL, feedRoute, tag, itemsPerPage, page, feedOrchestrator.GetVisibility(getUsername(r)))
+
+		// display error 404 non-existing page has been requested
+		if err != nil {
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		jsonBytes, err := json.MarshalIndent(feedModel, "", "\t")
+		if err != nil {
+			logger.Error("Unable to convert the feed model to json. Error: %s", err)
+			http.Error(w, "The feed could not be created.", http.StatusInternalServerError)
+			return
+		}
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_JSONFEED)
+
+		// etag cache validator
+		if etag := hashutil.FromBytes(jsonBytes); etag != "" {
+			header.ETag(w, etag)
+		}
+
+		// the client already has the current version
+		if header.NotModified(w, r) {
+			return
+		}
+
+		w.Write(jsonBytes)
+	})
+}
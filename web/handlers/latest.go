@@ -18,6 +18,8 @@ import (
 func Latest(logger logger.Logger, headerWriter header.HeaderWriter, viewModelOrchestrator *orchestrator.ViewModelOrchestrator, fallbackHandler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
+		logger := requestLogger(logger, r)
+
 		// set headers
 		headerWriter.Write(w, header.CONTENTTYPE_JSON)
 
@@ -33,7 +35,7 @@ func Latest(logger logger.Logger, headerWriter header.HeaderWriter, viewModelOrc
 		defer r.Body.Close()
 
 		// stage 1: check if there is a item for the request
-		if latestModels, found := viewModelOrchestrator.GetLatest(requestRoute, 3, 1); found {
+		if latestModels, found := viewModelOrchestrator.GetLatest(requestRoute, 3, 1, viewModelOrchestrator.GetVisibility(getUsername(r))); found {
 
 			// convert the viewmodel to json
 			jsonBytes, err := json.MarshalIndent(latestModels, "", "\t")
@@ -33,7 +33,7 @@ func Markdown(headerWriter header.HeaderWriter, viewModelOrchestrator *orchestra
 		defer r.Body.Close()
 
 		// stage 1: check if there is a item for the request
-		if viewModel, found := viewModelOrchestrator.GetFullViewModel(requestRoute); found {
+		if viewModel, found := viewModelOrchestrator.GetFullViewModel(requestRoute, viewModelOrchestrator.GetVisibility(getUsername(r))); found {
 			fmt.Fprintf(w, "%s", viewModel.Markdown)
 			return
 		}
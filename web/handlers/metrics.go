@@ -0,0 +1,60 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/metrics"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const contentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	requestCount = metrics.NewCounter(
+		"allmark_http_requests_total",
+		"Number of HTTP requests by handler and status code.",
+		"handler", "code")
+
+	requestDuration = metrics.NewHistogram(
+		"allmark_http_request_duration_seconds",
+		"Duration of HTTP requests by handler in seconds.",
+		metrics.DefaultDurationBuckets,
+		"handler")
+)
+
+// Metrics returns a handler which exposes all metrics in the Prometheus text format.
+func Metrics(headerWriter header.HeaderWriter, statusOrchestrator *orchestrator.StatusOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statusOrchestrator.UpdateMetrics()
+
+		headerWriter.Write(w, contentTypePrometheus)
+		metrics.Default.Write(w)
+	})
+}
+
+// CollectMetrics counts the requests to the given handler and measures their duration.
+func CollectMetrics(handlerName string, baseHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// websocket connections need the original response writer
+		if r.Header.Get("Upgrade") != "" {
+			requestCount.Inc(handlerName, strconv.Itoa(http.StatusSwitchingProtocols))
+			baseHandler.ServeHTTP(w, r)
+			return
+		}
+
+		startTime := time.Now()
+		statusWriter := newStatusRecordingResponseWriter(w)
+
+		baseHandler.ServeHTTP(statusWriter, r)
+
+		requestCount.Inc(handlerName, strconv.Itoa(statusWriter.statusCode))
+		requestDuration.Observe(time.Since(startTime).Seconds(), handlerName)
+	})
+}
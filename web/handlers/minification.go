@@ -0,0 +1,97 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/util/htmlutil"
+)
+
+// MinifyResponses minifies all HTML responses of the given handler.
+// All other responses are passed through unchanged.
+func MinifyResponses(baseHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// don't interfere with websocket connections
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			baseHandler.ServeHTTP(w, r)
+			return
+		}
+
+		minifyingWriter := &minifyingResponseWriter{ResponseWriter: w}
+		baseHandler.ServeHTTP(minifyingWriter, r)
+		minifyingWriter.Flush()
+	})
+}
+
+// minifyingResponseWriter buffers HTML responses so they can be minified as a whole.
+type minifyingResponseWriter struct {
+	http.ResponseWriter
+
+	decided    bool
+	isHTML     bool
+	statusCode int
+	buffer     bytes.Buffer
+}
+
+func (writer *minifyingResponseWriter) WriteHeader(statusCode int) {
+	if writer.decided {
+		return
+	}
+
+	writer.decide()
+	writer.statusCode = statusCode
+
+	if !writer.isHTML {
+		writer.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (writer *minifyingResponseWriter) Write(data []byte) (int, error) {
+	if !writer.decided {
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	if !writer.isHTML {
+		return writer.ResponseWriter.Write(data)
+	}
+
+	return writer.buffer.Write(data)
+}
+
+// Flush writes the minified response to the underlying writer.
+// Responses which are not minified (e.g. event streams) are flushed immediately.
+func (writer *minifyingResponseWriter) Flush() {
+	if !writer.isHTML {
+		if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return
+	}
+
+	writer.isHTML = false
+
+	minified := htmlutil.Minify(writer.buffer.String())
+	writer.buffer.Reset()
+
+	writer.ResponseWriter.WriteHeader(writer.statusCode)
+	writer.ResponseWriter.Write([]byte(minified))
+}
+
+// decide determines whether the response will be minified based on its content type.
+func (writer *minifyingResponseWriter) decide() {
+	writer.decided = true
+
+	contentType := writer.Header().Get("Content-Type")
+	writer.isHTML = strings.HasPrefix(contentType, "text/html")
+
+	if writer.isHTML {
+		writer.Header().Del("Content-Length")
+	}
+}
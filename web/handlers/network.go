@@ -0,0 +1,68 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"context"
+	"net"
+	"net/http"
+)
+
+// forwardedByTrustedProxyContextKey is the context key of the flag which indicates whether a request
+// with an X-Forwarded-For header was sent by a trusted proxy.
+type forwardedByTrustedProxyContextKey struct{}
+
+// isForwardedByTrustedProxy returns true if the given request was forwarded by a trusted proxy, so its client
+// address has been resolved from the X-Forwarded-For header (see ResolveClientAddress).
+func isForwardedByTrustedProxy(r *http.Request) bool {
+	isTrusted, _ := r.Context().Value(forwardedByTrustedProxyContextKey{}).(bool)
+	return isTrusted
+}
+
+// ResolveClientAddress replaces the remote address of requests from trusted proxies
+// with the client address from the X-Forwarded-For header.
+func ResolveClientAddress(networkPolicy *config.NetworkPolicy, baseHandler http.Handler) http.Handler {
+	if !networkPolicy.HasTrustedProxies() {
+		return baseHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		remoteIP, port, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			remoteIP, port = r.RemoteAddr, "0"
+		}
+
+		forwardedFor := r.Header["X-Forwarded-For"]
+		if len(forwardedFor) > 0 && networkPolicy.IsTrustedProxy(remoteIP) {
+			r = r.WithContext(context.WithValue(r.Context(), forwardedByTrustedProxyContextKey{}, true))
+		}
+
+		if clientIP := networkPolicy.ClientIP(remoteIP, forwardedFor); clientIP != remoteIP {
+			r.RemoteAddr = net.JoinHostPort(clientIP, port)
+		}
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
+
+// RestrictNetworkAccess answers requests from clients which are not allowed to access
+// the requested route according to the given network policy with "403 Forbidden".
+func RestrictNetworkAccess(networkPolicy *config.NetworkPolicy, baseHandler http.Handler) http.Handler {
+	if !networkPolicy.HasAccessRules() {
+		return baseHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if !networkPolicy.IsAllowed(r.URL.Path, getClientAddress(r)) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
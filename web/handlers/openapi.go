@@ -0,0 +1,293 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/openapi"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// openAPIVersion is the version of the REST API that is described by the OpenAPI document.
+	openAPIVersion = "v1"
+
+	// openAPIDigestAuthentication is the name of the security scheme of the write API.
+	openAPIDigestAuthentication = "digestAuthentication"
+)
+
+// OpenAPI returns a handler which returns the OpenAPI document that describes the REST API.
+// The operations of the write API are only described if the write API is enabled.
+func OpenAPI(logger logger.Logger, headerWriter header.HeaderWriter, basePath string, writeAPIIsEnabled bool) http.Handler {
+
+	// the document doesn't change while the server is running
+	document := getOpenAPIDocument(basePath, writeAPIIsEnabled)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		writeAPIResponse(logger, headerWriter, w, r, document)
+	})
+}
+
+// getOpenAPIDocument creates the OpenAPI document of the REST API which is served with the given base path.
+func getOpenAPIDocument(basePath string, writeAPIIsEnabled bool) *openapi.Document {
+	serverURL := strings.TrimSuffix(basePath, "/")
+	if serverURL == "" {
+		serverURL = "/"
+	}
+
+	document := openapi.New("allmark REST API", openAPIVersion, serverURL)
+	document.Info.Description = "Read the items, files and tags of the repository and search its content."
+
+	errorResponse := func(description string) openapi.Response {
+		return openAPIJSONResponse(description, document.SchemaOf(viewmodel.APIError{}))
+	}
+
+	pagingParameters := []openapi.Parameter{
+		{Name: "page", In: "query", Description: "The page number (default: 1).", Schema: &openapi.Schema{Type: "integer", Format: "int32"}},
+		{Name: "pageSize", In: "query", Description: fmt.Sprintf("The number of entries per page (default: %d, maximum: %d).", apiDefaultPageSize, apiMaxPageSize), Schema: &openapi.Schema{Type: "integer", Format: "int32"}},
+	}
+
+	routeParameter := openapi.Parameter{
+		Name:        "route",
+		In:          "path",
+		Description: `The route of the item (e.g. "documents/Sample-Document"). Unlike most path parameters it can contain slashes.`,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+
+	itemsPath := APIItemsHandlerRoute
+	itemPath := APIItemsRoutePrefix + "{route}"
+	filePath := itemPath + "/files/{path}"
+
+	// items
+	document.AddOperation(itemsPath, "get", &openapi.Operation{
+		OperationID: "listItems",
+		Summary:     "List all items",
+		Tags:        []string{"items"},
+		Parameters:  pagingParameters,
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("A page of items.", document.SchemaOf(viewmodel.APIItemList{})),
+			"400": errorResponse("The paging parameters are invalid."),
+		},
+	})
+
+	document.AddOperation(itemPath, "get", &openapi.Operation{
+		OperationID: "getItem",
+		Summary:     "Get an item with its content, children and files",
+		Description: fmt.Sprintf("%q returns the repository root.", APIItemsRoutePrefix),
+		Tags:        []string{"items"},
+		Parameters:  []openapi.Parameter{routeParameter},
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("The item.", document.SchemaOf(viewmodel.APIItemDetails{})),
+			"404": errorResponse("The item was not found."),
+		},
+	})
+
+	// search
+	document.AddOperation(APISearchHandlerRoute, "get", &openapi.Operation{
+		OperationID: "search",
+		Summary:     "Search the items",
+		Tags:        []string{"search"},
+		Parameters: append([]openapi.Parameter{
+			{Name: "q", In: "query", Description: "The search query.", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			{Name: "tag", In: "query", Description: "Only return items with this tag. Can be repeated; the items must have all of the given tags.", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+			{Name: "type", In: "query", Description: `Only return items with this type (e.g. "document"). Can be repeated; the items must have one of the given types.`, Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+		}, append(pagingParameters,
+			openapi.Parameter{Name: "offset", In: "query", Description: "The zero-based position of the first hit; overrides the page.", Schema: &openapi.Schema{Type: "integer", Format: "int32"}},
+			openapi.Parameter{Name: "cursor", In: "query", Description: "The nextCursor of the previous page; overrides the page and the offset. It is only valid with the same query, filters and page size.", Schema: &openapi.Schema{Type: "string"}},
+		)...),
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("A page of search hits, best matches first.", document.SchemaOf(viewmodel.APISearchResults{})),
+			"400": errorResponse("The query is empty or the paging parameters or the cursor are invalid."),
+		},
+	})
+
+	// tags
+	document.AddOperation(APITagsHandlerRoute, "get", &openapi.Operation{
+		OperationID: "listTags",
+		Summary:     "List all tags",
+		Tags:        []string{"tags"},
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("All tags, sorted by name.", &openapi.Schema{Type: "array", Items: document.SchemaOf(viewmodel.APITag{})}),
+		},
+	})
+
+	document.AddOperation(APITagsRoutePrefix+"{tag}", "get", &openapi.Operation{
+		OperationID: "getTag",
+		Summary:     "Get a tag with the items that are tagged with it",
+		Tags:        []string{"tags"},
+		Parameters: []openapi.Parameter{
+			{Name: "tag", In: "path", Description: "The name of the tag.", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("The tag with its items, newest first.", document.SchemaOf(viewmodel.APITagDetails{})),
+			"404": errorResponse("The tag was not found."),
+		},
+	})
+
+	// structure
+	document.AddOperation(APITreeHandlerRoute, "get", &openapi.Operation{
+		OperationID: "getTree",
+		Summary:     "Get the hierarchy of all items",
+		Tags:        []string{"structure"},
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("The repository root with all of its descendants.", document.SchemaOf(viewmodel.APITreeNode{})),
+		},
+	})
+
+	document.AddOperation(APIGraphHandlerRoute, "get", &openapi.Operation{
+		OperationID: "getGraph",
+		Summary:     "Get the links between the items",
+		Tags:        []string{"structure"},
+		Responses: map[string]openapi.Response{
+			"200": openAPIJSONResponse("All items and the links between them.", document.SchemaOf(viewmodel.APIGraph{})),
+		},
+	})
+
+	if !writeAPIIsEnabled {
+		return document
+	}
+
+	// write API
+	document.Info.Description = "Read, create, update and delete the items and files of the repository and search its content."
+	document.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		openAPIDigestAuthentication: {Type: "http", Scheme: "digest", Description: "All modifications require HTTP digest authentication."},
+	}
+
+	security := []map[string][]string{{openAPIDigestAuthentication: {}}}
+
+	preconditionParameters := []openapi.Parameter{
+		{Name: "If-Match", In: "header", Description: "Only apply the change if the target has one of the given hashes.", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "If-None-Match", In: "header", Description: `"*": only apply the change if the target doesn't exist yet.`, Schema: &openapi.Schema{Type: "string"}},
+	}
+
+	writeErrorResponses := map[string]openapi.Response{
+		"400": errorResponse("The route or the file path is invalid."),
+		"401": {Description: "Authentication is required."},
+		"403": {Description: "The user is not allowed to modify the repository."},
+		"404": errorResponse("The item or file was not found."),
+		"409": errorResponse("The change conflicts with the current state of the repository."),
+		"412": errorResponse("The precondition of the If-Match or If-None-Match header was not met."),
+	}
+
+	markdownBody := &openapi.RequestBody{
+		Description: "The markdown document of the item.",
+		Required:    true,
+		Content:     map[string]openapi.MediaType{contentTypeMarkdown: {Schema: &openapi.Schema{Type: "string"}}},
+	}
+
+	itemResponses := withOpenAPIResponses(writeErrorResponses, map[string]openapi.Response{
+		"200": openAPIJSONResponse("The item was updated.", document.SchemaOf(viewmodel.APIItem{})),
+		"201": openAPIJSONResponse("The item was created.", document.SchemaOf(viewmodel.APIItem{})),
+		"413": errorResponse("The markdown document is too large."),
+	})
+
+	document.AddOperation(itemPath, "put", &openapi.Operation{
+		OperationID: "saveItem",
+		Summary:     "Create or update the markdown document of an item",
+		Tags:        []string{"items"},
+		Parameters:  append([]openapi.Parameter{routeParameter}, preconditionParameters...),
+		RequestBody: markdownBody,
+		Responses:   itemResponses,
+		Security:    security,
+	})
+
+	document.AddOperation(itemPath, "post", &openapi.Operation{
+		OperationID: "createItem",
+		Summary:     "Create a new item",
+		Tags:        []string{"items"},
+		Parameters:  []openapi.Parameter{routeParameter},
+		RequestBody: markdownBody,
+		Responses: withOpenAPIResponses(writeErrorResponses, map[string]openapi.Response{
+			"201": itemResponses["201"],
+			"409": errorResponse("The item already exists or its parent does not exist."),
+			"413": itemResponses["413"],
+			"415": errorResponse(fmt.Sprintf("The content type is not %q.", contentTypeMarkdown)),
+		}),
+		Security: security,
+	})
+
+	document.AddOperation(itemPath, "delete", &openapi.Operation{
+		OperationID: "deleteItem",
+		Summary:     "Delete an item which has no children",
+		Tags:        []string{"items"},
+		Parameters:  append([]openapi.Parameter{routeParameter}, preconditionParameters...),
+		Responses: withOpenAPIResponses(writeErrorResponses, map[string]openapi.Response{
+			"204": {Description: "The item was deleted."},
+		}),
+		Security: security,
+	})
+
+	filePathParameter := openapi.Parameter{
+		Name:        "path",
+		In:          "path",
+		Description: `The path of the file in the files directory of the item (e.g. "images/diagram.png").`,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+
+	document.AddOperation(filePath, "put", &openapi.Operation{
+		OperationID: "saveFile",
+		Summary:     "Create or update a file of an item",
+		Tags:        []string{"files"},
+		Parameters:  append([]openapi.Parameter{routeParameter, filePathParameter}, preconditionParameters...),
+		RequestBody: &openapi.RequestBody{
+			Description: "The content of the file.",
+			Required:    true,
+			Content:     map[string]openapi.MediaType{"application/octet-stream": {Schema: &openapi.Schema{Type: "string", Format: "binary"}}},
+		},
+		Responses: withOpenAPIResponses(writeErrorResponses, map[string]openapi.Response{
+			"200": openAPIJSONResponse("The file was updated.", document.SchemaOf(viewmodel.APIFile{})),
+			"201": openAPIJSONResponse("The file was created.", document.SchemaOf(viewmodel.APIFile{})),
+			"413": errorResponse("The file is too large."),
+		}),
+		Security: security,
+	})
+
+	document.AddOperation(filePath, "delete", &openapi.Operation{
+		OperationID: "deleteFile",
+		Summary:     "Delete a file of an item",
+		Tags:        []string{"files"},
+		Parameters:  append([]openapi.Parameter{routeParameter, filePathParameter}, preconditionParameters...),
+		Responses: withOpenAPIResponses(writeErrorResponses, map[string]openapi.Response{
+			"204": {Description: "The file was deleted."},
+		}),
+		Security: security,
+	})
+
+	return document
+}
+
+// openAPIJSONResponse returns a response with a JSON body with the given schema.
+func openAPIJSONResponse(description string, schema *openapi.Schema) openapi.Response {
+	return openapi.Response{
+		Description: description,
+		Content:     map[string]openapi.MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// withOpenAPIResponses returns a copy of the given responses with the given additional (or replaced) responses.
+func withOpenAPIResponses(responses, additionalResponses map[string]openapi.Response) map[string]openapi.Response {
+	combinedResponses := make(map[string]openapi.Response, len(responses)+len(additionalResponses))
+	for statusCode, response := range responses {
+		combinedResponses[statusCode] = response
+	}
+
+	for statusCode, response := range additionalResponses {
+		combinedResponses[statusCode] = response
+	}
+
+	return combinedResponses
+}
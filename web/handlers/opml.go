@@ -0,0 +1,41 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// opmlTypeFeeds is the value of the "type" url-parameter which selects the list of feeds instead of the item hierarchy.
+const opmlTypeFeeds = "feeds"
+
+// OPML creates a http handler which exports the item hierarchy of the repository as an OPML document.
+// With the url-parameter "type=feeds" the handler returns the list of the RSS feeds of the repository and its subtrees
+// which can be imported into feed readers.
+func OPML(headerWriter header.HeaderWriter, opmlOrchestrator *orchestrator.OPMLOrchestrator, templateProvider templates.Provider) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// get the current baseURL
+		baseURL := getBaseURLFromRequest(r)
+
+		opmlTemplate, err := templateProvider.GetOPMLTemplate(baseURL)
+		if err != nil {
+			fmt.Fprintf(w, "Template not found. Error: %s", err)
+			return
+		}
+
+		feedsOnly := strings.EqualFold(r.URL.Query().Get("type"), opmlTypeFeeds)
+		opmlModel := opmlOrchestrator.GetOPML(baseURL, rssFeedName, feedsOnly, opmlOrchestrator.GetVisibility(getUsername(r)))
+
+		headerWriter.Write(w, header.CONTENTTYPE_OPML)
+		renderTemplate(opmlTemplate, opmlModel, w)
+	})
+}
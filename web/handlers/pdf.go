@@ -0,0 +1,183 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PDF returns a handler which converts the print view of the requested item (and of all of its descendants
+// if the subtree parameter is set) to a PDF document with the configured conversion tool (wkhtmltopdf).
+// Requests for paths which are not items (e.g. attached PDF files) are passed to the fallback handler;
+// only the conversions are restricted by the given concurrency limiter.
+func PDF(logger logger.Logger,
+	conversionConfig config.PDFConversion,
+	conversionEndpointHostname string,
+	headerWriter header.HeaderWriter,
+	converterModelOrchestrator *orchestrator.ConversionModelOrchestrator,
+	templateProvider templates.Provider,
+	limitConversions ConcurrencyLimiter,
+	fallbackHandler http.Handler) http.Handler {
+
+	convertToHtml := func(baseURL string, viewModel viewmodel.ConversionModel) (string, error) {
+
+		// get a template
+		template, err := templateProvider.GetConversionTemplate(baseURL)
+		if err != nil {
+			return "", fmt.Errorf("No template for item of type %q.", viewModel.Type)
+		}
+
+		// render template
+		buffer := new(bytes.Buffer)
+		if err := renderTemplate(template, viewModel, buffer); err != nil {
+			return "", err
+		}
+
+		return buffer.String(), nil
+	}
+
+	convert := func(r *http.Request, w http.ResponseWriter, baseURL string, model viewmodel.ConversionModel) {
+
+		logger := requestLogger(logger, r)
+
+		html, err := convertToHtml(baseURL, model)
+		if err != nil {
+			logger.Error("%s", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		// the temporary working directory
+		targetDirectory := fsutil.GetTempDirectory()
+
+		// remove the temp directory at the end
+		defer func() {
+			logger.Debug("Deleting conversion file directory: %q", targetDirectory)
+			if err := deleteFile(targetDirectory); err != nil {
+				logger.Error("Could not delete the temporary working directory (%q) that has been created during the conversion. Error: %s", targetDirectory, err.Error())
+			}
+		}()
+
+		// write the html to a temp file (Note: the file extension .html is important for wkhtmltopdf)
+		htmlFilePath := filepath.Join(targetDirectory, "source.html")
+		if err := os.WriteFile(htmlFilePath, []byte(html), 0644); err != nil {
+			logger.Error("Cannot write the HTML file. Error: %s", err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		targetFilePath := filepath.Join(targetDirectory, "target.pdf")
+
+		cmd := exec.Command(conversionConfig.Tool(), getPDFConversionArguments(conversionConfig, model.Title, htmlFilePath, targetFilePath)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Dir = targetDirectory
+
+		if err := cmd.Run(); err != nil {
+			logger.Error("Could not run %q: %v", conversionConfig.Tool(), err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		pdfFile, err := fsutil.OpenFile(targetFilePath)
+		if err != nil {
+			logger.Error("Cannot open target file. Error: %s", err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		defer pdfFile.Close()
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_PDF)
+		w.Header().Add("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, getTargetFilename(model, "pdf")))
+
+		io.Copy(w, pdfFile)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if !conversionConfig.IsEnabled() {
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// strip the "pdf" or ".pdf" suffix from the path
+		path := r.URL.Path
+		path = strings.TrimSuffix(path, "pdf")
+		path = strings.TrimSuffix(path, ".")
+
+		// get the request route
+		requestRoute := route.NewFromRequest(path)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		// make sure the conversion tool only performs local requests
+		baseURL := getBaseURLFromRequest(r)
+		baseURL = strings.Replace(baseURL, "https://", "http://", 1)
+		baseURL = strings.Replace(baseURL, r.Host, conversionEndpointHostname, 1)
+
+		var model viewmodel.ConversionModel
+		var found bool
+		if includeSubtree, _ := strconv.ParseBool(r.URL.Query().Get(printSubtreeParameter)); includeSubtree {
+			model, found = converterModelOrchestrator.GetSubtreeConversionModel(baseURL, requestRoute, converterModelOrchestrator.GetVisibility(getUsername(r)))
+		} else {
+			model, found = converterModelOrchestrator.GetConversionModel(baseURL, requestRoute)
+		}
+
+		if !found {
+
+			// not an item (e.g. an attached PDF file)
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
+
+		limitConversions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			convert(r, w, baseURL, model)
+		})).ServeHTTP(w, r)
+	})
+
+}
+
+// getPDFConversionArguments returns the arguments of the PDF conversion tool (wkhtmltopdf) for the conversion
+// of the given HTML file with the given title to the given PDF file. The links between the sections of the
+// document are kept as internal links and the headings are used for the outline of the document.
+func getPDFConversionArguments(conversionConfig config.PDFConversion, title, htmlFilePath, targetFilePath string) []string {
+	args := []string{
+		"--quiet",
+		"--page-size", conversionConfig.GetPageSize(),
+		"--title", title,
+		"--outline",
+		"--encoding", "utf-8",
+		"--enable-internal-links",
+	}
+
+	if header := strings.TrimSpace(conversionConfig.Header); header != "" {
+		args = append(args, "--header-center", header, "--header-font-size", "8", "--header-spacing", "5")
+	}
+
+	if footer := strings.TrimSpace(conversionConfig.Footer); footer != "" {
+		args = append(args, "--footer-center", footer, "--footer-font-size", "8", "--footer-spacing", "5")
+	}
+
+	return append(args, htmlFilePath, targetFilePath)
+}
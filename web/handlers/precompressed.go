@@ -0,0 +1,171 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andybalholm/brotli"
+)
+
+// precompressedFileExtensions contains the file extensions of the pre-compressed siblings of static files by encoding.
+var precompressedFileExtensions = map[string]string{
+	encodingBrotli: ".br",
+	encodingGzip:   ".gz",
+}
+
+// precompressedContent contains the compressed versions of a static asset by encoding.
+type precompressedContent map[string][]byte
+
+// newPrecompressedContent compresses the given data with all supported encodings at the highest
+// compression level. Encodings which don't reduce the size of the data are omitted.
+func newPrecompressedContent(data []byte) precompressedContent {
+	content := make(precompressedContent)
+
+	brotliBuffer := new(bytes.Buffer)
+	brotliWriter := brotli.NewWriterLevel(brotliBuffer, brotli.BestCompression)
+	if _, err := brotliWriter.Write(data); err == nil && brotliWriter.Close() == nil && brotliBuffer.Len() < len(data) {
+		content[encodingBrotli] = brotliBuffer.Bytes()
+	}
+
+	gzipBuffer := new(bytes.Buffer)
+	gzipWriter, _ := gzip.NewWriterLevel(gzipBuffer, gzip.BestCompression)
+	if _, err := gzipWriter.Write(data); err == nil && gzipWriter.Close() == nil && gzipBuffer.Len() < len(data) {
+		content[encodingGzip] = gzipBuffer.Bytes()
+	}
+
+	return content
+}
+
+// Select returns the preferred compressed version of the content which is accepted by the
+// client of the given request; or an empty encoding if the uncompressed version must be used.
+func (content precompressedContent) Select(r *http.Request) (encoding string, data []byte) {
+	if r.Header.Get("Range") != "" {
+		return "", nil
+	}
+
+	for _, acceptedEncoding := range getAcceptedEncodings(r.Header.Get("Accept-Encoding")) {
+		if data, exists := content[acceptedEncoding]; exists {
+			return acceptedEncoding, data
+		}
+	}
+
+	return "", nil
+}
+
+// precompressedCache compresses static assets on first use and keeps the compressed versions
+// in memory, so that frequently requested assets don't need to be compressed for every request.
+type precompressedCache struct {
+	lock    sync.RWMutex
+	entries map[string]precompressedContent
+}
+
+func newPrecompressedCache() *precompressedCache {
+	return &precompressedCache{
+		entries: make(map[string]precompressedContent),
+	}
+}
+
+// Get returns the compressed versions of the given data which is stored under the given key (e.g. the path of the asset).
+// Data of content types which are not compressible or which is too small to be compressed is not cached.
+func (cache *precompressedCache) Get(key, contentType string, data []byte) precompressedContent {
+	if len(data) < compressionMinSize || !isCompressibleContentType(contentType) {
+		return precompressedContent{}
+	}
+
+	cache.lock.RLock()
+	content, exists := cache.entries[key]
+	cache.lock.RUnlock()
+
+	if exists {
+		return content
+	}
+
+	content = newPrecompressedContent(data)
+
+	cache.lock.Lock()
+	cache.entries[key] = content
+	cache.lock.Unlock()
+
+	return content
+}
+
+// ServePrecompressedFiles wraps the given static file handler and serves the pre-compressed siblings
+// of the requested files (e.g. "screen.css.br" or "screen.css.gz" for "screen.css") if they exist,
+// are up-to-date and the client accepts their encoding.
+func ServePrecompressedFiles(staticFileHandler http.Handler, baseFolder, requestPrefixToStripFromRequestURI string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// partial content must be served from the uncompressed file
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Range") != "" {
+			staticFileHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// prepare the request uri
+		requestURI := r.URL.Path
+		if requestPrefixToStripFromRequestURI != "" {
+			requestURI = stripPathFromRequest(r, requestPrefixToStripFromRequestURI)
+		}
+
+		// assemble the filepath on disc
+		filePath := filepath.Join(baseFolder, filepath.FromSlash(requestURI))
+
+		fileInfo, err := os.Stat(filePath)
+		if err != nil || !fileInfo.Mode().IsRegular() {
+			staticFileHandler.ServeHTTP(w, r)
+			return
+		}
+
+		for _, encoding := range getAcceptedEncodings(r.Header.Get("Accept-Encoding")) {
+
+			// ignore siblings that are older than the file itself
+			siblingPath := filePath + precompressedFileExtensions[encoding]
+			siblingInfo, err := os.Stat(siblingPath)
+			if err != nil || !siblingInfo.Mode().IsRegular() || siblingInfo.ModTime().Before(fileInfo.ModTime()) {
+				continue
+			}
+
+			sibling, err := os.Open(siblingPath)
+			if err != nil {
+				continue
+			}
+
+			defer sibling.Close()
+
+			header.EncodeETag(w, encoding)
+
+			header := w.Header()
+			header.Set("Content-Type", getMimeType(filePath, nil))
+			header.Set("Content-Encoding", encoding)
+			addVaryHeader(header, "Accept-Encoding")
+
+			http.ServeContent(w, r, fileInfo.Name(), fileInfo.ModTime(), sibling)
+			return
+		}
+
+		staticFileHandler.ServeHTTP(w, r)
+	})
+}
+
+// addVaryHeader adds the given request header name to the Vary header unless it is already listed.
+func addVaryHeader(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, listedName := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listedName), name) {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", name)
+}
@@ -13,16 +13,25 @@ import (
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
+// printSubtreeParameter is the name of the query parameter which requests a print view of an item
+// which includes all of its descendants (e.g. "/documentation.print?subtree=true").
+const printSubtreeParameter = "subtree"
+
+// Print returns a handler which renders a print view of the requested item
+// (and of all of its descendants if the subtree parameter is set).
 func Print(logger logger.Logger,
 	headerWriter header.HeaderWriter,
 	conversionModelOrchestrator *orchestrator.ConversionModelOrchestrator,
 	templateProvider templates.Provider,
 	error404Handler http.Handler) http.Handler {
 
-	render := func(writer io.Writer, baseURL string, viewModel viewmodel.ConversionModel) {
+	render := func(r *http.Request, writer io.Writer, baseURL string, viewModel viewmodel.ConversionModel) {
+
+		logger := requestLogger(logger, r)
 
 		// get a template
 		template, err := templateProvider.GetConversionTemplate(baseURL)
@@ -57,7 +66,14 @@ func Print(logger logger.Logger,
 
 		// check if there is a item for the request
 		baseURL := getBaseURLFromRequest(r)
-		viewModel, found := conversionModelOrchestrator.GetConversionModel(baseURL, requestRoute)
+		var viewModel viewmodel.ConversionModel
+		var found bool
+		if includeSubtree, _ := strconv.ParseBool(r.URL.Query().Get(printSubtreeParameter)); includeSubtree {
+			viewModel, found = conversionModelOrchestrator.GetSubtreeConversionModel(baseURL, requestRoute, conversionModelOrchestrator.GetVisibility(getUsername(r)))
+		} else {
+			viewModel, found = conversionModelOrchestrator.GetConversionModel(baseURL, requestRoute)
+		}
+
 		if !found {
 
 			// display a 404 error page
@@ -66,6 +82,6 @@ func Print(logger logger.Logger,
 		}
 
 		// render the view model
-		render(w, baseURL, viewModel)
+		render(r, w, baseURL, viewModel)
 	})
 }
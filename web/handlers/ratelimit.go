@@ -0,0 +1,168 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// concurrencyLimitTimeout is the maximum time a request waits for a free slot before it is rejected.
+	concurrencyLimitTimeout = 5 * time.Second
+
+	// rateLimiterCleanupInterval defines how often idle clients are removed from the rate limiter.
+	rateLimiterCleanupInterval = time.Minute
+)
+
+// LimitRequestRate limits the number of requests per second each client (IP address) can make.
+// Requests exceeding the limit are answered with "429 Too Many Requests".
+func LimitRequestRate(rateLimitingConfig config.RateLimiting, baseHandler http.Handler) http.Handler {
+	if !rateLimitingConfig.Enabled || rateLimitingConfig.RequestsPerSecond <= 0 {
+		return baseHandler
+	}
+
+	limiter := newClientRateLimiter(rateLimitingConfig.RequestsPerSecond, rateLimitingConfig.Burst)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if allowed, retryAfter := limiter.Allow(getClientAddress(r)); !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
+
+// ConcurrencyLimiter restricts the number of requests that are processed at the same time.
+type ConcurrencyLimiter func(baseHandler http.Handler) http.Handler
+
+// NewConcurrencyLimiter creates a new ConcurrencyLimiter which allows at most the given number
+// of concurrent requests across all handlers it wraps. Requests which don't get a free slot
+// within a few seconds are answered with "503 Service Unavailable".
+// A limit of zero or less disables the limiter.
+func NewConcurrencyLimiter(limit int) ConcurrencyLimiter {
+	if limit <= 0 {
+		return func(baseHandler http.Handler) http.Handler {
+			return baseHandler
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+
+	return func(baseHandler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			// long-lived websocket and event stream connections would block a slot forever
+			if isLongLivedRequest(r) {
+				baseHandler.ServeHTTP(w, r)
+				return
+			}
+
+			timeout := time.NewTimer(concurrencyLimitTimeout)
+			defer timeout.Stop()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				baseHandler.ServeHTTP(w, r)
+
+			case <-timeout.C:
+				w.Header().Set("Retry-After", strconv.Itoa(int(concurrencyLimitTimeout.Seconds())))
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+			case <-r.Context().Done():
+			}
+		})
+	}
+}
+
+// getClientAddress returns the IP address of the client that sent the given request.
+func getClientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// clientRateLimiter is a token bucket rate limiter with one bucket per client.
+type clientRateLimiter struct {
+	sync.Mutex
+
+	rate        float64
+	burst       float64
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+func newClientRateLimiter(requestsPerSecond float64, burst int) *clientRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &clientRateLimiter{
+		rate:        requestsPerSecond,
+		burst:       float64(burst),
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of the given client. If the bucket is empty
+// it returns false and the duration after which the next token will be available.
+func (limiter *clientRateLimiter) Allow(client string) (allowed bool, retryAfter time.Duration) {
+	limiter.Lock()
+	defer limiter.Unlock()
+
+	now := time.Now()
+
+	// remove the idle clients while the limiter is in use, so a replaced limiter (e.g. after a reload) does not leave a task behind
+	if now.Sub(limiter.lastCleanup) >= rateLimiterCleanupInterval {
+		limiter.removeIdleClients(now)
+	}
+
+	bucket, exists := limiter.buckets[client]
+	if !exists {
+		bucket = &tokenBucket{tokens: limiter.burst, lastUpdate: now}
+		limiter.buckets[client] = bucket
+	}
+
+	// refill
+	bucket.tokens = math.Min(limiter.burst, bucket.tokens+now.Sub(bucket.lastUpdate).Seconds()*limiter.rate)
+	bucket.lastUpdate = now
+
+	if bucket.tokens < 1 {
+		missingTokens := 1 - bucket.tokens
+		return false, time.Duration(missingTokens / limiter.rate * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// removeIdleClients removes the buckets of all clients which would be completely refilled at the given time.
+// The caller must hold the lock of the limiter.
+func (limiter *clientRateLimiter) removeIdleClients(now time.Time) {
+	limiter.lastCleanup = now
+	for client, bucket := range limiter.buckets {
+		if bucket.tokens+now.Sub(bucket.lastUpdate).Seconds()*limiter.rate >= limiter.burst {
+			delete(limiter.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+)
+
+// Reader returns a http handler which returns the converted HTML content of the
+// requested item without any theme elements (e.g. for embedding the item into other pages).
+func Reader(headerWriter header.HeaderWriter,
+	conversionModelOrchestrator *orchestrator.ConversionModelOrchestrator,
+	error404Handler http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// strip the "plain" or ".plain" suffix from the path
+		path := r.URL.Path
+		path = strings.TrimSuffix(path, "plain")
+		path = strings.TrimSuffix(path, ".")
+
+		// get the request route
+		requestRoute := route.NewFromRequest(path)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		// check if there is a item for the request
+		baseURL := getBaseURLFromRequest(r)
+		viewModel, found := conversionModelOrchestrator.GetConversionModel(baseURL, requestRoute)
+		if !found {
+
+			// display a 404 error page
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_HTML)
+
+		fmt.Fprintf(w, "%s", viewModel.Content)
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// RecentChanges creates a http handler which displays the most recently modified items grouped by day.
+func RecentChanges(headerWriter header.HeaderWriter,
+	navigationOrchestrator *orchestrator.NavigationOrchestrator,
+	recentChangesOrchestrator *orchestrator.RecentChangesOrchestrator,
+	templateProvider templates.Provider,
+	limit int) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_HTML)
+
+		hostname := getBaseURLFromRequest(r)
+
+		recentChangesTemplate, err := templateProvider.GetRecentChangesTemplate(hostname)
+		if err != nil {
+			fmt.Fprintf(w, "Template not found. Error: %s", err)
+			return
+		}
+
+		// Page parameters
+		headline := templateProvider.Label("", "recent.title")
+
+		pageModel := viewmodel.Model{}
+		pageModel.Type = "recentchanges"
+		pageModel.Title = headline
+		pageModel.Description = templateProvider.Label("", "recent.description")
+		pageModel.PageTitle = recentChangesOrchestrator.GetPageTitle(headline)
+		pageModel.ToplevelNavigation = navigationOrchestrator.GetToplevelNavigation()
+		pageModel.BreadcrumbNavigation = navigationOrchestrator.GetBreadcrumbNavigation(route.New())
+
+		recentChangesModel := viewmodel.RecentChanges{}
+		recentChangesModel.Model = pageModel
+		recentChangesModel.FeedURL = strings.TrimPrefix(RecentChangesFeedHandlerRoute, "/")
+		recentChangesModel.Days = recentChangesOrchestrator.GetRecentChanges(limit, recentChangesOrchestrator.GetVisibility(getUsername(r)))
+
+		renderTemplate(recentChangesTemplate, recentChangesModel, w)
+	})
+}
+
+// RecentChangesFeed creates a http handler which returns an RSS feed of the most recently modified items.
+func RecentChangesFeed(headerWriter header.HeaderWriter,
+	feedOrchestrator *orchestrator.FeedOrchestrator,
+	templateProvider templates.Provider,
+	error404Handler http.Handler,
+	limit int) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		baseURL := getBaseURLFromRequest(r)
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_XML)
+
+		feedTemplate, err := templateProvider.GetRSSTemplate(baseURL)
+		if err != nil {
+			fmt.Fprintf(w, "Template not found. Error: %s", err)
+			return
+		}
+
+		title := feedOrchestrator.GetPageTitle(templateProvider.Label("", "recent.title"))
+		feedModel, err := feedOrchestrator.GetRecentChangesFeed(baseURL, title, limit, feedOrchestrator.GetVisibility(getUsername(r)))
+		if err != nil {
+			error404Handler.ServeHTTP(w, r)
+			return
+		}
+
+		renderTemplate(feedTemplate, feedModel, w)
+	})
+}
@@ -7,14 +7,18 @@ package handlers
 import (
 	"github.com/andreaskoch/allmark/common/logger"
 	"net/http"
+	"strings"
 )
 
 func Redirect(logger logger.Logger, baseURITarget string) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		requestPath := r.URL.Path
-		redirectURL := baseURITarget + "/" + requestPath
+		requestPath := strings.TrimPrefix(r.URL.Path, "/")
+		redirectURL := strings.TrimSuffix(baseURITarget, "/") + "/" + requestPath
+		if r.URL.RawQuery != "" {
+			redirectURL += "?" + r.URL.RawQuery
+		}
 
 		http.Redirect(w, r, redirectURL, http.StatusMovedPermanently)
 	})
@@ -0,0 +1,41 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"net/http"
+	"strings"
+)
+
+// ApplyRedirects redirects all requests which match one of the given redirects to the
+// respective target. Targets which start with a slash are relative to the given base path.
+func ApplyRedirects(basePath string, redirects []config.Redirect, baseHandler http.Handler) http.Handler {
+	if len(redirects) == 0 {
+		return baseHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		target, statusCode, found := config.FindRedirect(redirects, r.URL.Path)
+		if !found {
+			baseHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// routes are relative to the base path; collapse leading (back)slashes so that
+		// the target cannot be mistaken for a protocol-relative URL ("//host")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimSuffix(basePath, "/") + "/" + strings.TrimLeft(target, "/\\")
+		}
+
+		// keep the query string
+		if r.URL.RawQuery != "" && !strings.Contains(target, "?") {
+			target += "?" + r.URL.RawQuery
+		}
+
+		http.Redirect(w, r, target, statusCode)
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_ApplyRedirects_WildcardWithDoubleSlash_LocationStaysOnTheServer(t *testing.T) {
+	// arrange
+	redirects := []config.Redirect{
+		{Source: "/old/*", Target: "/*", StatusCode: http.StatusMovedPermanently},
+	}
+
+	handler := ApplyRedirects("/", redirects, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	inputs := map[string]string{
+		"/old//evil.com":   "/evil.com",
+		"/old/%5Cevil.com": "/evil.com",
+		"/old/a/b":         "/a/b",
+	}
+
+	for path, expected := range inputs {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		response := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(response, r)
+
+		// assert
+		if location := response.Header().Get("Location"); location != expected {
+			t.Errorf("The request for %q was redirected to %q; expected %q.", path, location, expected)
+		}
+	}
+}
+
+func Test_ApplyRedirects_TargetWithLeadingSlashes_TargetIsRelativeToTheBasePath(t *testing.T) {
+	// arrange
+	redirects := []config.Redirect{
+		{Source: "/old-page", Target: "//evil.com/", StatusCode: http.StatusMovedPermanently},
+	}
+
+	handler := ApplyRedirects("/docs/", redirects, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodGet, "/old-page", nil)
+	response := httptest.NewRecorder()
+
+	// act
+	handler.ServeHTTP(response, r)
+
+	// assert
+	if location := response.Header().Get("Location"); location != "/docs/evil.com/" {
+		t.Errorf("The request was redirected to %q; expected %q.", location, "/docs/evil.com/")
+	}
+}
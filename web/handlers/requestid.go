@@ -0,0 +1,78 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the name of the request and response header which contains the ID of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximum length of the request IDs which are taken over from the clients.
+const maxRequestIDLength = 64
+
+// requestIDContextKey is the key of the request ID in the context of a request.
+type requestIDContextKey struct{}
+
+// AssignRequestIDs assigns an ID to every request and returns it in the X-Request-ID response header.
+// The ID of a request which already has a valid X-Request-ID header (e.g. from a reverse proxy) is kept.
+func AssignRequestIDs(baseHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		baseHandler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, requestID)))
+	})
+}
+
+// GetRequestID returns the ID of the given request or an empty string if the request has no ID.
+func GetRequestID(r *http.Request) string {
+	requestID, _ := r.Context().Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// requestLogger returns a logger which adds the ID of the given request to its messages.
+func requestLogger(baseLogger logger.Logger, r *http.Request) logger.Logger {
+	requestID := GetRequestID(r)
+	if requestID == "" {
+		return baseLogger
+	}
+
+	return baseLogger.WithFields(map[string]interface{}{"requestId": requestID})
+}
+
+// newRequestID returns a random request ID (16 hexadecimal characters).
+func newRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// isValidRequestID returns a flag indicating whether the given request ID of a client can be used:
+// it must not be longer than 64 characters and must only contain letters, digits, dashes, dots and underscores.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, character := range requestID {
+		isLetterOrDigit := (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9')
+		if !isLetterOrDigit && character != '-' && character != '.' && character != '_' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// statusRecordingResponseWriter remembers the status code and the size of a response.
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode   int
+	bytesWritten int64
+	wroteHeader  bool
+}
+
+func newStatusRecordingResponseWriter(w http.ResponseWriter) *statusRecordingResponseWriter {
+	return &statusRecordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+}
+
+func (writer *statusRecordingResponseWriter) WriteHeader(statusCode int) {
+	if !writer.wroteHeader {
+		writer.statusCode = statusCode
+		writer.wroteHeader = true
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *statusRecordingResponseWriter) Write(data []byte) (int, error) {
+	writer.wroteHeader = true
+	bytesWritten, err := writer.ResponseWriter.Write(data)
+	writer.bytesWritten += int64(bytesWritten)
+	return bytesWritten, err
+}
+
+func (writer *statusRecordingResponseWriter) Flush() {
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection (e.g. for websockets).
+func (writer *statusRecordingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := writer.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("The response writer does not support hijacking")
+	}
+
+	writer.statusCode = http.StatusSwitchingProtocols
+	writer.wroteHeader = true
+	return hijacker.Hijack()
+}
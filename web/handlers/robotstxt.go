@@ -5,15 +5,38 @@
 package handlers
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/view/templates"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
+// defaultRobotsTxtDisallowPaths contains the paths (relative to the base path) that are not crawled
+// if no custom rules are configured.
+var defaultRobotsTxtDisallowPaths = []string{
+	"thumbnails",
+	"docx$",
+	"odt$",
+	"json$",
+	"markdown$",
+	"print$",
+	"ws$",
+	"*.docx$",
+	"*.odt$",
+	"*.json$",
+	"*.markdown$",
+	"*.print$",
+	"*.ws$",
+}
+
 // RobotsTxt creates a http handler for serving the robots.txt.
-func RobotsTxt(headerWriter header.HeaderWriter, templateProvider templates.Provider) http.Handler {
+// The configured rules replace the built-in rules which exclude the thumbnails and the alternative views of the items.
+func RobotsTxt(headerWriter header.HeaderWriter, templateProvider templates.Provider, basePath string, robotsTxtConfig config.RobotsTxt) http.Handler {
+
+	groups := getRobotsTxtGroups(basePath, robotsTxtConfig)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -26,28 +49,12 @@ func RobotsTxt(headerWriter header.HeaderWriter, templateProvider templates.Prov
 		}
 
 		// view model
-		disallowPaths := []string{
-			"/thumbnails",
-			"/docx$",
-			"/json$",
-			"/markdown$",
-			"/print$",
-			"/ws$",
-			"/*.docx$",
-			"/*.json$",
-			"/*.markdown$",
-			"/*.print$",
-			"/*.ws$",
-		}
-		disallow := viewmodel.RobotsTxtDisallow{
-			UserAgent: "*",
-			Paths:     disallowPaths,
+		model := viewmodel.RobotsTxt{
+			Groups: groups,
 		}
 
-		sitemapURL := fmt.Sprintf("%s%s", baseURL, XMLSitemapHandlerRoute)
-		model := viewmodel.RobotsTxt{
-			Disallows:  []viewmodel.RobotsTxtDisallow{disallow},
-			SitemapURL: sitemapURL,
+		if !robotsTxtConfig.DisableSitemap {
+			model.SitemapURL = baseURL + basePath + strings.TrimPrefix(XMLSitemapHandlerRoute, "/")
 		}
 
 		// write
@@ -56,3 +63,39 @@ func RobotsTxt(headerWriter header.HeaderWriter, templateProvider templates.Prov
 	})
 
 }
+
+// getRobotsTxtGroups returns the user-agent sections of the robots.txt for the given configuration.
+func getRobotsTxtGroups(basePath string, robotsTxtConfig config.RobotsTxt) []viewmodel.RobotsTxtGroup {
+
+	if !robotsTxtConfig.HasRules() {
+		disallowPaths := make([]string, 0, len(defaultRobotsTxtDisallowPaths))
+		for _, path := range defaultRobotsTxtDisallowPaths {
+			disallowPaths = append(disallowPaths, basePath+path)
+		}
+
+		return []viewmodel.RobotsTxtGroup{
+			{
+				UserAgent: "*",
+				Disallows: disallowPaths,
+			},
+		}
+	}
+
+	groups := make([]viewmodel.RobotsTxtGroup, 0, len(robotsTxtConfig.Rules))
+	for _, rule := range robotsTxtConfig.Rules {
+		group := viewmodel.RobotsTxtGroup{
+			UserAgent: rule.Agent(),
+			Allows:    rule.Allow,
+			Disallows: rule.Disallow,
+		}
+
+		// a group without any paths allows everything
+		if len(group.Allows) == 0 && len(group.Disallows) == 0 {
+			group.Disallows = []string{""}
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
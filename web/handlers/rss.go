@@ -5,26 +5,35 @@
 package handlers
 
 import (
+	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
 	"github.com/andreaskoch/allmark/web/view/templates"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
-var itemsPerPage = 5
+// rssFeedName is the name of the RSS feed of an item (e.g. "/feed.rss", "/projects/feed.rss").
+const rssFeedName = "feed.rss"
 
-// RSS cretes a new RSS-Feed handler.
+// RSS cretes a new RSS-Feed handler for the whole repository ("/feed.rss") or for the subtree
+// of an item (e.g. "/projects/feed.rss"). The "tag" url-parameter limits the feed to the items with the given tag.
 func RSS(headerWriter header.HeaderWriter,
 	feedOrchestrator *orchestrator.FeedOrchestrator,
 	templateProvider templates.Provider,
-	error404Handler http.Handler) http.Handler {
+	error404Handler http.Handler,
+	itemsPerPage int) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		// get the current baseURL
 		baseURL := getBaseURLFromRequest(r)
 
+		// get the item route and the tag of the feed
+		itemRoute, _ := getFeedRoutes(r.URL.Path, rssFeedName)
+		tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+
 		// set headers
 		headerWriter.Write(w, header.CONTENTTYPE_XML)
 
@@ -41,7 +50,7 @@ func RSS(headerWriter header.HeaderWriter,
 			return
 		}
 
-		feedModel, err := feedOrchestrator.GetFeed(baseURL, itemsPerPage, page)
+		feedModel, err := feedOrchestrator.GetFeed(baseURL, itemRoute, tag, itemsPerPage, page, feedOrchestrator.GetVisibility(getUsername(r)))
 
 		// display error 404 non-existing page has been requested
 		if err != nil {
@@ -52,3 +61,11 @@ func RSS(headerWriter header.HeaderWriter,
 		renderTemplate(feedTemplate, feedModel, w)
 	})
 }
+
+// getFeedRoutes returns the route of the item whose subtree is covered by the feed with the given request path
+// and the route of the feed itself (e.g. "projects" and "projects/feed.rss" for "/projects/feed.rss").
+func getFeedRoutes(requestPath, feedName string) (itemRoute route.Route, feedRoute string) {
+	itemRoute = route.NewFromRequest(strings.TrimSuffix(requestPath, feedName))
+	feedRoute = strings.TrimPrefix(itemRoute.Value()+"/"+feedName, "/")
+	return itemRoute, feedRoute
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	html "html/template"
 	"net/http"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -41,6 +42,9 @@ func Search(headerWriter header.HeaderWriter,
 			page = 1
 		}
 
+		// read the page size url-parameter (invalid values are replaced by the default page size)
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
 		// get the search template
 		searchTemplate, err := templateProvider.GetSearchTemplate(hostname)
 		if err != nil {
@@ -50,9 +54,9 @@ func Search(headerWriter header.HeaderWriter,
 
 		// Page parameters
 		pageType := "search"
-		headline := getPageTitle(query)
+		headline := getPageTitle(templateProvider, query)
 		pageTitle := searchOrchestrator.GetPageTitle(headline)
-		description := getDescription(query)
+		description := getDescription(templateProvider, query)
 
 		// Page model
 		pageModel := viewmodel.Model{}
@@ -64,7 +68,7 @@ func Search(headerWriter header.HeaderWriter,
 		pageModel.BreadcrumbNavigation = navigationOrchestrator.GetBreadcrumbNavigation(route.New())
 
 		// get the search results
-		searchResultsModel := searchOrchestrator.GetSearchResults(query, page)
+		searchResultsModel := searchOrchestrator.GetSearchResults(query, page, pageSize, searchOrchestrator.GetVisibility(getUsername(r)))
 
 		// display error 404 non-existing page has been requested
 		if searchResultsModel.ResultCount == 0 && page > 1 {
@@ -82,20 +86,20 @@ func Search(headerWriter header.HeaderWriter,
 
 }
 
-func getPageTitle(query string) string {
+func getPageTitle(templateProvider templates.Provider, query string) string {
 	if strings.TrimSpace(query) == "" {
-		return "Search"
+		return templateProvider.Label("", "search.title")
 	}
 
-	return fmt.Sprintf("%s | Search", html.HTMLEscapeString(query))
+	return fmt.Sprintf(templateProvider.Label("", "search.title.query"), html.HTMLEscapeString(query))
 }
 
-func getDescription(query string) string {
+func getDescription(templateProvider templates.Provider, query string) string {
 	if strings.TrimSpace(query) == "" {
-		return "Search this repository."
+		return templateProvider.Label("", "search.description")
 	}
 
-	return fmt.Sprintf("Search results for %q.", html.HTMLEscapeString(query))
+	return fmt.Sprintf(templateProvider.Label("", "search.description.query"), html.HTMLEscapeString(query))
 }
 
 func renderSearchResultModel(templ *template.Template, searchModel viewmodel.Search) string {
@@ -0,0 +1,51 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"net/http"
+)
+
+// AddSecurityHeaders adds the configured security headers (HSTS, Content-Security-Policy,
+// X-Frame-Options, X-Content-Type-Options and Referrer-Policy) to all responses of the given handler.
+// The Strict-Transport-Security header is only sent for HTTPS requests.
+func AddSecurityHeaders(securityHeaders config.SecurityHeaders, baseHandler http.Handler) http.Handler {
+	if !securityHeaders.Enabled {
+		return baseHandler
+	}
+
+	// the header values don't change at runtime
+	strictTransportSecurity := securityHeaders.StrictTransportSecurityHeader()
+	contentSecurityPolicy := securityHeaders.ContentSecurityPolicyHeader()
+	frameOptions := securityHeaders.FrameOptionsHeader()
+	referrerPolicy := securityHeaders.ReferrerPolicy
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+
+		if strictTransportSecurity != "" && r.TLS != nil {
+			header.Set("Strict-Transport-Security", strictTransportSecurity)
+		}
+
+		if contentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", contentSecurityPolicy)
+		}
+
+		if frameOptions != "" {
+			header.Set("X-Frame-Options", frameOptions)
+		}
+
+		if securityHeaders.ContentTypeNoSniff {
+			header.Set("X-Content-Type-Options", "nosniff")
+		}
+
+		if referrerPolicy != "" {
+			header.Set("Referrer-Policy", referrerPolicy)
+		}
+
+		baseHandler.ServeHTTP(w, r)
+	})
+}
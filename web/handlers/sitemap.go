@@ -45,9 +45,9 @@ func Sitemap(headerWriter header.HeaderWriter,
 		}
 
 		// Page parameters
-		pageTitle := "Sitemap"
+		pageTitle := templateProvider.Label("", "sitemap.title")
 		pageType := "sitemap"
-		descriptionText := "A list of all items in this repository."
+		descriptionText := templateProvider.Label("", "sitemap.description")
 
 		// Page model
 		viewModel := viewmodel.Model{}
@@ -60,7 +60,7 @@ func Sitemap(headerWriter header.HeaderWriter,
 
 		sitemapPageModel := viewmodel.Sitemap{}
 		sitemapPageModel.Model = viewModel
-		sitemapPageModel.Tree = renderSitemapEntryTemplate(sitemapEntryTemplate, sitemapOrchestrator.GetSitemap(), childPlaceholder)
+		sitemapPageModel.Tree = renderSitemapEntryTemplate(sitemapEntryTemplate, sitemapOrchestrator.GetSitemap(sitemapOrchestrator.GetVisibility(getUsername(r))), childPlaceholder)
 
 		renderTemplate(sitemapTemplate, sitemapPageModel, w)
 	})
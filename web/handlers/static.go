@@ -25,7 +25,7 @@ func AddETAgToStaticFileHandler(staticFileHandler http.Handler, headerWriter hea
 		etag := ""
 
 		// prepare the request uri
-		requestURI := r.RequestURI
+		requestURI := r.URL.Path
 		if requestPrefixToStripFromRequestURI != "" {
 			requestURI = stripPathFromRequest(r, requestPrefixToStripFromRequestURI)
 		}
@@ -49,5 +49,5 @@ func AddETAgToStaticFileHandler(staticFileHandler http.Handler, headerWriter hea
 }
 
 func stripPathFromRequest(request *http.Request, path string) string {
-	return strings.TrimPrefix(request.RequestURI, path)
+	return strings.TrimPrefix(request.URL.Path, path)
 }
@@ -0,0 +1,76 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// Stats creates a http handler which displays the statistics of the repository.
+func Stats(headerWriter header.HeaderWriter,
+	navigationOrchestrator *orchestrator.NavigationOrchestrator,
+	statsOrchestrator *orchestrator.StatsOrchestrator,
+	templateProvider templates.Provider) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_HTML)
+
+		hostname := getBaseURLFromRequest(r)
+
+		statsTemplate, err := templateProvider.GetStatsTemplate(hostname)
+		if err != nil {
+			fmt.Fprintf(w, "Template not found. Error: %s", err)
+			return
+		}
+
+		// Page parameters
+		headline := templateProvider.Label("", "stats.title")
+
+		pageModel := viewmodel.Model{}
+		pageModel.Type = "stats"
+		pageModel.Title = headline
+		pageModel.Description = templateProvider.Label("", "stats.description")
+		pageModel.PageTitle = statsOrchestrator.GetPageTitle(headline)
+		pageModel.ToplevelNavigation = navigationOrchestrator.GetToplevelNavigation()
+		pageModel.BreadcrumbNavigation = navigationOrchestrator.GetBreadcrumbNavigation(route.New())
+
+		statsModel := viewmodel.StatsPage{}
+		statsModel.Model = pageModel
+		statsModel.JSONURL = strings.TrimPrefix(StatsJSONHandlerRoute, "/")
+		statsModel.Stats = statsOrchestrator.GetStats()
+
+		renderTemplate(statsTemplate, statsModel, w)
+	})
+}
+
+// StatsJSON creates a http handler which returns the statistics of the repository in JSON format.
+func StatsJSON(headerWriter header.HeaderWriter, statsOrchestrator *orchestrator.StatsOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerWriter.Write(w, header.CONTENTTYPE_JSON)
+		writeStats(w, statsOrchestrator.GetStats())
+	})
+}
+
+func writeStats(writer io.Writer, stats viewmodel.Stats) error {
+	bytes, err := json.MarshalIndent(stats, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	writer.Write(bytes)
+	return nil
+}
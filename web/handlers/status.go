@@ -0,0 +1,47 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Health returns a handler which reports whether the server is alive.
+func Health(headerWriter header.HeaderWriter, statusOrchestrator *orchestrator.StatusOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerWriter.Write(w, header.CONTENTTYPE_JSON)
+		writeStatus(w, statusOrchestrator.GetHealth())
+	})
+}
+
+// Readiness returns a handler which reports whether the server is ready to serve requests.
+// It responds with "503 Service Unavailable" until the indizes have been created.
+func Readiness(headerWriter header.HeaderWriter, statusOrchestrator *orchestrator.StatusOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerWriter.Write(w, header.CONTENTTYPE_JSON)
+
+		status, isReady := statusOrchestrator.GetReadiness()
+		if !isReady {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		writeStatus(w, status)
+	})
+}
+
+func writeStatus(writer io.Writer, status viewmodel.Status) error {
+	bytes, err := json.MarshalIndent(status, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	writer.Write(bytes)
+	return nil
+}
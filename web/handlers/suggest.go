@@ -0,0 +1,53 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// suggestDefaultLimit is the number of suggestions that are returned if the "limit" parameter is not set.
+	suggestDefaultLimit = 10
+
+	// suggestMaxLimit is the maximum number of suggestions a client can request.
+	suggestMaxLimit = 25
+)
+
+// Suggest returns a handler which returns the titles, aliases and tags that start with the given text
+// ("?q=...&limit=10") as JSON for the search-as-you-type box.
+func Suggest(headerWriter header.HeaderWriter, typeAheadOrchestrator *orchestrator.TypeAheadOrchestrator) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		limit := suggestDefaultLimit
+		if limitParameter := r.URL.Query().Get("limit"); limitParameter != "" {
+			parsedLimit, err := strconv.Atoi(limitParameter)
+			if err != nil || parsedLimit < 1 || parsedLimit > suggestMaxLimit {
+				http.Error(w, "The limit must be a number between 1 and "+strconv.Itoa(suggestMaxLimit)+".", http.StatusBadRequest)
+				return
+			}
+
+			limit = parsedLimit
+		}
+
+		query, _ := getQueryParameterFromURL(*r.URL)
+		suggestions := typeAheadOrchestrator.GetCompletions(query, limit, typeAheadOrchestrator.GetVisibility(getUsername(r)))
+
+		bytes, err := json.MarshalIndent(suggestions, "", "\t")
+		if err != nil {
+			http.Error(w, "The suggestions could not be created.", http.StatusInternalServerError)
+			return
+		}
+
+		headerWriter.Write(w, header.CONTENTTYPE_JSON)
+		w.Write(bytes)
+	})
+
+}
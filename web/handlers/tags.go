@@ -7,6 +7,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/web/header"
@@ -18,7 +19,12 @@ import (
 func Tags(headerWriter header.HeaderWriter,
 	navigationOrchestrator *orchestrator.NavigationOrchestrator,
 	tagsOrchestrator *orchestrator.TagsOrchestrator,
-	templateProvider templates.Provider) http.Handler {
+	templateProvider templates.Provider,
+	error404Handler http.Handler,
+	basePath string,
+	itemsPerPage int) http.Handler {
+
+	tagsPageURL := basePath + strings.TrimPrefix(TagmapHandlerRoute, "/")
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -26,6 +32,7 @@ func Tags(headerWriter header.HeaderWriter,
 		headerWriter.Write(w, header.CONTENTTYPE_HTML)
 
 		hostname := getBaseURLFromRequest(r)
+		visibility := tagsOrchestrator.GetVisibility(getUsername(r))
 
 		tagmapTemplate, err := templateProvider.GetTagMapTemplate(hostname)
 		if err != nil {
@@ -35,7 +42,7 @@ func Tags(headerWriter header.HeaderWriter,
 
 		// Page parameters
 		pageType := "tagmap"
-		headline := "Tags"
+		headline := templateProvider.Label("", "tagmap.title")
 		pageTitle := tagsOrchestrator.GetPageTitle(headline)
 
 		pageModel := viewmodel.Model{}
@@ -44,11 +51,29 @@ func Tags(headerWriter header.HeaderWriter,
 		pageModel.PageTitle = pageTitle
 		pageModel.ToplevelNavigation = navigationOrchestrator.GetToplevelNavigation()
 		pageModel.BreadcrumbNavigation = navigationOrchestrator.GetBreadcrumbNavigation(route.New())
-		pageModel.TagCloud = tagsOrchestrator.GetTagCloud()
+		pageModel.TagCloud = tagsOrchestrator.GetTagCloud(visibility)
 
 		tagsPageModel := viewmodel.Tags{}
 		tagsPageModel.Model = pageModel
-		tagsPageModel.Tags = tagsOrchestrator.GetTags()
+
+		// a single tag with all of its items (e.g. "tags.html?tag=Go&page=2") or all tags with the first items
+		if tagName := strings.TrimSpace(r.URL.Query().Get("tag")); tagName != "" {
+			page := 1
+			if pageParam, isAvailable := getPageParameterFromURL(*r.URL); isAvailable {
+				page = pageParam
+			}
+
+			tag, pager, found := tagsOrchestrator.GetTagPage(tagName, tagsPageURL, itemsPerPage, page, visibility)
+			if !found {
+				error404Handler.ServeHTTP(w, r)
+				return
+			}
+
+			tagsPageModel.Tags = []viewmodel.Tag{tag}
+			tagsPageModel.Pager = pager
+		} else {
+			tagsPageModel.Tags = tagsOrchestrator.GetTruncatedTagTree(tagsPageURL, itemsPerPage, visibility)
+		}
 
 		renderTemplate(tagmapTemplate, tagsPageModel, w)
 	})
@@ -8,17 +8,27 @@ import (
 	"github.com/andreaskoch/allmark/common/util/hashutil"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/view/themes"
-	"fmt"
 	"mime"
 	"net/http"
+	"net/url"
+	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // InMemoryTheme creates a theme-handler that serves the theme-files from memory.
+// The compressed versions of the theme-files are created on first use and served directly
+// to clients that accept them.
 func InMemoryTheme(themeFolderPath string, headerWriter header.HeaderWriter, error404Handler http.Handler) http.Handler {
 
 	defaultTheme := themes.GetTheme()
+	compressedThemeFiles := newPrecompressedCache()
+
+	// the in-memory theme only changes with the executable
+	lastModified := getExecutableModificationTime()
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -47,7 +57,95 @@ func InMemoryTheme(themeFolderPath string, headerWriter header.HeaderWriter, err
 			header.ETag(w, etag)
 		}
 
-		fmt.Fprintf(w, `%s`, data)
+		header.LastModified(w, lastModified)
+
+		// the client already has the current version
+		if header.NotModified(w, r) {
+			return
+		}
+
+		// serve the pre-compressed version
+		addVaryHeader(w.Header(), "Accept-Encoding")
+		if encoding, compressedData := compressedThemeFiles.Get(path, mimeType, data).Select(r); encoding != "" {
+			w.Header().Set("Content-Encoding", encoding)
+			header.EncodeETag(w, encoding)
+			data = compressedData
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+	})
+}
+
+// getExecutableModificationTime returns the modification time of the running executable;
+// or the current time if it cannot be determined.
+func getExecutableModificationTime() time.Time {
+	executablePath, err := os.Executable()
+	if err != nil {
+		return time.Now()
+	}
+
+	fileInfo, err := os.Stat(executablePath)
+	if err != nil {
+		return time.Now()
+	}
+
+	return fileInfo.ModTime()
+}
+
+// ThemeFolder creates a theme-handler that serves the theme-files from the given folder on disc.
+// Requests for files which the folder does not contain are passed to the given fallback handler
+// (e.g. the handler of another theme folder or the in-memory theme).
+func ThemeFolder(themeFolder string, headerWriter header.HeaderWriter, fallbackHandler http.Handler) http.Handler {
+
+	themeFolderHandler := AddETAgToStaticFileHandler(
+		ServePrecompressedFiles(
+			Static(
+				themeFolder,
+				ThemeRoutePrefix),
+			themeFolder, ThemeRoutePrefix),
+		headerWriter, themeFolder, ThemeRoutePrefix)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		filePath := filepath.Join(themeFolder, filepath.FromSlash(path.Clean("/"+stripPathFromRequest(r, ThemeRoutePrefix))))
+		if fileInfo, err := os.Stat(filePath); err != nil || !fileInfo.Mode().IsRegular() {
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
+
+		themeFolderHandler.ServeHTTP(w, r)
+	})
+}
+
+// FingerprintedTheme creates a theme-handler for the theme files which are requested by their fingerprinted paths
+// (e.g. "/theme/screen.0a1b2c3d4e.css"). Files whose fingerprint matches their current content are served by the
+// given immutable handler, all other requests (outdated fingerprints or paths without a fingerprint) by the given
+// theme handler.
+func FingerprintedTheme(fingerprints *themes.Fingerprints, immutableThemeHandler, themeHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requestedPath := stripPathFromRequest(r, ThemeRoutePrefix+"/")
+		themeFilePath, isCurrent := fingerprints.Resolve(requestedPath)
+		if themeFilePath == requestedPath {
+			themeHandler.ServeHTTP(w, r)
+			return
+		}
+
+		// serve the file under its actual path
+		request := new(http.Request)
+		*request = *r
+		request.URL = new(url.URL)
+		*request.URL = *r.URL
+		request.URL.Path = ThemeRoutePrefix + "/" + themeFilePath
+		request.URL.RawPath = ""
+
+		if isCurrent {
+			immutableThemeHandler.ServeHTTP(w, request)
+			return
+		}
+
+		themeHandler.ServeHTTP(w, request)
 	})
 }
 
@@ -0,0 +1,98 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/view/themes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_InMemoryTheme_IfModifiedSince_NotModifiedIsReturned(t *testing.T) {
+	// arrange
+	headerWriterFactory := header.NewHeaderWriterFactory(0, config.CacheControl{})
+	handler := InMemoryTheme("/theme/", headerWriterFactory.Theme(), http.NotFoundHandler())
+	themeFilePath := "/theme/" + themes.GetTheme().Files[0].Path()
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, themeFilePath, nil))
+
+	lastModified := response.Header().Get("Last-Modified")
+	if response.Code != http.StatusOK || lastModified == "" {
+		t.Fatalf("The theme file should be returned with a Last-Modified header but the status was %d and the header %q.", response.Code, lastModified)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, themeFilePath, nil)
+	request.Header.Set("If-Modified-Since", lastModified)
+
+	// act
+	conditionalResponse := httptest.NewRecorder()
+	handler.ServeHTTP(conditionalResponse, request)
+
+	// assert
+	if conditionalResponse.Code != http.StatusNotModified {
+		t.Errorf("The handler should return %d but returned %d.", http.StatusNotModified, conditionalResponse.Code)
+	}
+
+	if conditionalResponse.Body.Len() != 0 {
+		t.Errorf("The not modified response should have no body but had %d bytes.", conditionalResponse.Body.Len())
+	}
+}
+
+func Test_InMemoryTheme_IfNoneMatch_NotModifiedIsReturned(t *testing.T) {
+	// arrange
+	headerWriterFactory := header.NewHeaderWriterFactory(0, config.CacheControl{})
+	handler := InMemoryTheme("/theme/", headerWriterFactory.Theme(), http.NotFoundHandler())
+	themeFilePath := "/theme/" + themes.GetTheme().Files[0].Path()
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, themeFilePath, nil))
+
+	request := httptest.NewRequest(http.MethodGet, themeFilePath, nil)
+	request.Header.Set("If-None-Match", response.Header().Get("ETag"))
+
+	// act
+	conditionalResponse := httptest.NewRecorder()
+	handler.ServeHTTP(conditionalResponse, request)
+
+	// assert
+	if conditionalResponse.Code != http.StatusNotModified {
+		t.Errorf("The handler should return %d but returned %d.", http.StatusNotModified, conditionalResponse.Code)
+	}
+}
+
+func Test_InMemoryTheme_CompressedResponse_ETagContainsTheEncoding(t *testing.T) {
+	// arrange
+	headerWriterFactory := header.NewHeaderWriterFactory(0, config.CacheControl{})
+	handler := InMemoryTheme("/theme/", headerWriterFactory.Theme(), http.NotFoundHandler())
+
+	var themeFilePath string
+	for _, themeFile := range themes.GetTheme().Files {
+		if strings.HasSuffix(themeFile.Path(), ".css") {
+			themeFilePath = "/theme/" + themeFile.Path()
+			break
+		}
+	}
+
+	uncompressedResponse := httptest.NewRecorder()
+	handler.ServeHTTP(uncompressedResponse, httptest.NewRequest(http.MethodGet, themeFilePath, nil))
+
+	request := httptest.NewRequest(http.MethodGet, themeFilePath, nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	// act
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	// assert
+	etag, uncompressedETag := response.Header().Get("ETag"), uncompressedResponse.Header().Get("ETag")
+	if response.Header().Get("Content-Encoding") != "gzip" || etag != header.EncodedETag(uncompressedETag, "gzip") {
+		t.Errorf("The compressed response should have the entity tag %q but has %q.", header.EncodedETag(uncompressedETag, "gzip"), etag)
+	}
+}
@@ -21,7 +21,7 @@ func Titles(headerWriter header.HeaderWriter, titlesOrchestrator *orchestrator.T
 		headerWriter.Write(w, header.CONTENTTYPE_JSON)
 
 		// get the suggestions
-		titles := titlesOrchestrator.GetTitles()
+		titles := titlesOrchestrator.GetTitles(titlesOrchestrator.GetVisibility(getUsername(r)))
 		writeTitles(w, titles)
 	})
 
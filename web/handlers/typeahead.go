@@ -22,7 +22,7 @@ func TypeAhead(headerWriter header.HeaderWriter, typeAheadOrchestrator *orchestr
 
 		// get the suggestions
 		query, _ := getQueryParameterFromURL(*r.URL)
-		searchResults := typeAheadOrchestrator.GetSuggestions(query)
+		searchResults := typeAheadOrchestrator.GetSuggestions(query, typeAheadOrchestrator.GetVisibility(getUsername(r)))
 
 		// convert to json
 		writeSearchResults(w, searchResults)
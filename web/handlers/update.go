@@ -5,75 +5,111 @@
 package handlers
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/web/handlers/update"
-	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
 	"github.com/andreaskoch/allmark/web/view/templates"
 	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"golang.org/x/net/websocket"
+	"net/http"
+	"sort"
 	"strings"
+	"time"
 )
 
-func Update(logger logger.Logger,
-	headerWriter header.HeaderWriter,
+// newUpdateHub returns a hub for the live-reload connections. Changes are collected until no
+// further changes arrive for the configured debounce period; then the clients viewing one of the
+// changed items (or the parent of a changed item) receive an update for their page.
+func newUpdateHub(logger logger.Logger,
+	liveReload config.LiveReload,
 	templateProvider templates.Provider,
-	updateOrchestrator *orchestrator.UpdateOrchestrator) websocket.Handler {
+	updateOrchestrator *orchestrator.UpdateOrchestrator) *update.Hub {
 
 	hub := update.NewHub(logger, updateOrchestrator)
 
 	updateChannel := make(chan orchestrator.Update, 1)
 	updateOrchestrator.Subscribe(updateChannel)
 
-	go func() {
-		for update := range updateChannel {
+	// sendUpdate sends the latest version of the item with the given route to the clients viewing it
+	sendUpdate := func(itemRoute route.Route) {
 
-			logger.Info("Received an update for route %q: %s", update.Route(), update.String())
+		// reload the page
+		if liveReload.ReloadPages() {
+			hub.Send(update.NewReloadMessage(itemRoute))
+			return
+		}
 
-			// handle new or modified items
-			if update.Type() == orchestrator.UpdateTypeNew || update.Type() == orchestrator.UpdateTypeModified {
+		// send the latest viewmodel to the client (the updates are sent to all clients, so they only list the public items)
+		viewModel, found := updateOrchestrator.GetUpdatedModel(itemRoute, updateOrchestrator.GetVisibility(""))
+		if !found {
+			logger.Warn("The item for route %q was no longer found.", itemRoute)
+			hub.Send(update.NewReloadMessage(itemRoute))
+			return
+		}
 
-				// send the latest viewmodel to the client
-				viewModel, found := updateOrchestrator.GetUpdatedModel(update.Route())
-				if !found {
-					logger.Warn("The item for route %q was no longer found.", update.Route())
-					return
-				}
+		var updateModel viewmodel.Update
+		updateModel.Model = viewModel
 
-				var updateModel viewmodel.Update
-				updateModel.Model = viewModel
+		snippets := make(map[string]string)
+		snippets["aliases"] = renderSnippet(templateProvider, templatenames.Aliases, viewModel)
+		snippets["tags"] = renderSnippet(templateProvider, templatenames.Tags, viewModel)
+		snippets["publisher"] = renderSnippet(templateProvider, templatenames.Publisher, viewModel)
+		snippets["toplevelnavigation"] = renderSnippet(templateProvider, templatenames.ToplevelNavigation, viewModel)
+		snippets["breadcrumbnavigation"] = renderSnippet(templateProvider, templatenames.BreadcrumbNavigation, viewModel)
+		snippets["itemnavigation"] = renderSnippet(templateProvider, templatenames.ItemNavigation, viewModel)
+		snippets["children"] = renderSnippet(templateProvider, templatenames.Children, viewModel)
+		snippets["tagcloud"] = renderSnippet(templateProvider, templatenames.TagCloud, viewModel)
 
-				snippets := make(map[string]string)
-				snippets["aliases"] = renderSnippet(templateProvider, templatenames.Aliases, viewModel)
-				snippets["tags"] = renderSnippet(templateProvider, templatenames.Tags, viewModel)
-				snippets["publisher"] = renderSnippet(templateProvider, templatenames.Publisher, viewModel)
-				snippets["toplevelnavigation"] = renderSnippet(templateProvider, templatenames.ToplevelNavigation, viewModel)
-				snippets["breadcrumbnavigation"] = renderSnippet(templateProvider, templatenames.BreadcrumbNavigation, viewModel)
-				snippets["itemnavigation"] = renderSnippet(templateProvider, templatenames.ItemNavigation, viewModel)
-				snippets["children"] = renderSnippet(templateProvider, templatenames.Children, viewModel)
-				snippets["tagcloud"] = renderSnippet(templateProvider, templatenames.TagCloud, viewModel)
+		updateModel.Snippets = snippets
 
-				updateModel.Snippets = snippets
+		hub.Message(updateModel)
+	}
 
-				hub.Message(updateModel)
+	go func() {
 
-			} else if update.Type() == orchestrator.UpdateTypeNew {
+		// the changed routes and their latest update type (by route value)
+		pendingUpdates := make(map[string]orchestrator.Update)
+		var debounceTimer <-chan time.Time
 
-				// send an empty update to the client
-				hub.Message(viewmodel.Update{})
+		for {
+			select {
+			case receivedUpdate := <-updateChannel:
+				logger.Info("Received an update for route %q: %s", receivedUpdate.Route(), receivedUpdate.String())
 
-			} else {
+				pendingUpdates[receivedUpdate.Route().Value()] = receivedUpdate
 
-				logger.Debug("No action for update %s", update.String())
+				// wait for the next quiet period
+				debounceTimer = time.After(liveReload.Debounce())
 
-			}
+			case <-debounceTimer:
+				debounceTimer = nil
 
+				for _, affectedRoute := range getAffectedRoutes(pendingUpdates) {
+
+					// deleted items: let the browser display the error page
+					if pendingUpdate, changed := pendingUpdates[affectedRoute.Value()]; changed && pendingUpdate.Type() == orchestrator.UpdateTypeDeleted {
+						hub.Send(update.NewReloadMessage(affectedRoute))
+						continue
+					}
+
+					sendUpdate(affectedRoute)
+				}
+
+				pendingUpdates = make(map[string]orchestrator.Update)
+			}
 		}
 	}()
 
-	return websocket.Handler(func(ws *websocket.Conn) {
+	return hub
+}
+
+// Update returns a handler for the live-reload websocket connections.
+func Update(logger logger.Logger, hub *update.Hub, updateOrchestrator *orchestrator.UpdateOrchestrator) http.Handler {
+
+	websocketHandler := websocket.Handler(func(ws *websocket.Conn) {
 
 		// strip the "ws" or ".ws" suffix from the path
 		path := ws.Request().URL.Path
@@ -105,6 +141,18 @@ func Update(logger logger.Logger,
 		c.Reader()
 	})
 
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// websocket connections can only be established via a HTTP/1.1 connection upgrade
+		// (e.g. not for requests that were received over HTTP/2)
+		if r.ProtoMajor != 1 || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			http.Error(w, "Websocket connections require a HTTP/1.1 connection upgrade.", http.StatusBadRequest)
+			return
+		}
+
+		websocketHandler.ServeHTTP(w, r)
+	})
+
 }
 
 func renderSnippet(templateProvider templates.Provider, templateName string, viewmodel interface{}) string {
@@ -123,3 +171,32 @@ func renderSnippet(templateProvider templates.Provider, templateName string, vie
 
 	return code
 }
+
+// getAffectedRoutes returns the routes of the changed items and their parents (which display
+// the changed items in their list of children) in a stable order.
+func getAffectedRoutes(updates map[string]orchestrator.Update) []route.Route {
+	routesByValue := make(map[string]route.Route)
+
+	for _, changedItem := range updates {
+		changedRoute := changedItem.Route()
+		routesByValue[changedRoute.Value()] = changedRoute
+
+		if parentRoute, exists := changedRoute.Parent(); exists {
+			routesByValue[parentRoute.Value()] = parentRoute
+		}
+	}
+
+	values := make([]string, 0, len(routesByValue))
+	for value := range routesByValue {
+		values = append(values, value)
+	}
+
+	sort.Strings(values)
+
+	routes := make([]route.Route, 0, len(values))
+	for _, value := range values {
+		routes = append(routes, routesByValue[value])
+	}
+
+	return routes
+}
@@ -8,15 +8,31 @@ import (
 	"github.com/andreaskoch/allmark/common/route"
 	"fmt"
 	"golang.org/x/net/websocket"
+	"sync"
 )
 
 func NewConnection(hub *Hub, ws *websocket.Conn, route route.Route) *connection {
 	return &connection{
 		Route: route,
 
-		hub:  hub,
-		send: make(chan Message, 10),
-		ws:   ws,
+		hub:           hub,
+		send:          make(chan Message, 10),
+		ws:            ws,
+		remoteAddress: ws.Request().RemoteAddr,
+		closed:        make(chan struct{}),
+	}
+}
+
+// NewEventStreamConnection creates a connection for a client that receives the messages
+// as Server-Sent Events instead of via a websocket.
+func NewEventStreamConnection(hub *Hub, remoteAddress string, route route.Route) *connection {
+	return &connection{
+		Route: route,
+
+		hub:           hub,
+		send:          make(chan Message, 10),
+		remoteAddress: remoteAddress,
+		closed:        make(chan struct{}),
 	}
 }
 
@@ -27,21 +43,49 @@ type connection struct {
 	// the hub
 	hub *Hub
 
-	// The websocket connection.
+	// The websocket connection (nil for event stream connections).
 	ws *websocket.Conn
 
+	// The address of the client.
+	remoteAddress string
+
 	// Buffered channel of outbound messages.
 	send chan Message
+
+	// closed is closed when the connection is closed.
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
 func (c *connection) String() string {
-	return fmt.Sprintf("Connection (Route: %s, IP: %s)", c.Route.String(), c.ws.Request().RemoteAddr)
+	return fmt.Sprintf("Connection (Route: %s, IP: %s)", c.Route.String(), c.remoteAddress)
 }
 
 func (c *connection) Send(msg Message) {
 	c.send <- msg
 }
 
+// Messages returns the channel of the outbound messages of the connection.
+func (c *connection) Messages() <-chan Message {
+	return c.send
+}
+
+// Closed returns a channel which is closed when the connection has been closed by the hub.
+func (c *connection) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// Close closes the connection.
+func (c *connection) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+
+		if c.ws != nil {
+			c.ws.Close()
+		}
+	})
+}
+
 func (c *connection) Reader() {
 	for {
 		var message Message
@@ -53,7 +97,7 @@ func (c *connection) Reader() {
 		c.hub.broadcast <- message
 	}
 
-	c.ws.Close()
+	c.Close()
 	c.hub.Unsubscribe(c)
 }
 
@@ -65,6 +109,6 @@ func (c *connection) Writer() {
 		}
 	}
 
-	c.ws.Close()
+	c.Close()
 	c.hub.Unsubscribe(c)
 }
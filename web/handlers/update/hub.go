@@ -6,11 +6,14 @@ package update
 
 import (
 	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/shutdown"
 	"github.com/andreaskoch/allmark/web/orchestrator"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 )
 
 func NewHub(logger logger.Logger, updateOrchestrator *orchestrator.UpdateOrchestrator) *Hub {
+	logger = logger.Module("livereload")
+
 	hub := &Hub{
 		logger: logger,
 
@@ -19,12 +22,20 @@ func NewHub(logger logger.Logger, updateOrchestrator *orchestrator.UpdateOrchest
 		broadcast:   make(chan Message, 1),
 		subscribe:   make(chan *connection, 1),
 		unsubscribe: make(chan *connection, 1),
+		closeAll:    make(chan chan bool),
 		connections: make(map[*connection]bool),
 	}
 
 	// start the hub
 	go hub.run()
 
+	// close all live-reload connections on shutdown
+	shutdown.Register(func() error {
+		logger.Info("Closing all live-reload connections")
+		hub.Close()
+		return nil
+	})
+
 	return hub
 }
 
@@ -44,12 +55,27 @@ type Hub struct {
 
 	// Unsubscribe requests from connections.
 	unsubscribe chan *connection
+
+	// Requests to close all connections.
+	closeAll chan chan bool
+}
+
+// Close closes all connections of the hub.
+func (hub *Hub) Close() {
+	done := make(chan bool)
+	hub.closeAll <- done
+	<-done
 }
 
 func (hub *Hub) Message(updateModel viewmodel.Update) {
+	hub.Send(NewMessage(updateModel))
+}
+
+// Send broadcasts the given message to all connections for the route of the message.
+func (hub *Hub) Send(message Message) {
 	go func() {
-		hub.logger.Debug("Broadcasting message for route %s", updateModel.Route)
-		hub.broadcast <- NewMessage(updateModel)
+		hub.logger.Debug("Broadcasting %q message for route %s", message.Name, message.Route)
+		hub.broadcast <- message
 	}()
 }
 
@@ -115,6 +141,19 @@ func (hub *Hub) run() {
 				hub.logger.Debug("Number of Connections - After: %v", len(hub.connections))
 			}
 
+		// close all connections
+		case done := <-hub.closeAll:
+			{
+				hub.logger.Debug("Closing %v connection(s)", len(hub.connections))
+
+				for connection := range hub.connections {
+					delete(hub.connections, connection)
+					connection.Close()
+				}
+
+				done <- true
+			}
+
 		// handle broadcasts
 		case broadcastMsg := <-hub.broadcast:
 			{
@@ -138,7 +177,7 @@ func (hub *Hub) run() {
 							// todo: find out when this is happening
 							hub.logger.Debug("Revieved a non-send message for %s", connection.String())
 							delete(hub.connections, connection)
-							go connection.ws.Close()
+							go connection.Close()
 							hub.logger.Debug("Number of Connections: %v", len(hub.connections))
 						}
 					}
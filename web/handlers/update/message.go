@@ -9,6 +9,15 @@ import (
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 )
 
+// The message names.
+const (
+	// MessageNameUpdate instructs the client to update the open page with the model of the message.
+	MessageNameUpdate = "update"
+
+	// MessageNameReload instructs the client to reload the open page.
+	MessageNameReload = "reload"
+)
+
 type Message struct {
 	Route       string           `json:"route"`
 	Name        string           `json:"name"`
@@ -20,7 +29,15 @@ func NewMessage(updateModel viewmodel.Update) Message {
 
 	return Message{
 		Route:       route.Value(),
-		Name:        "update",
+		Name:        MessageNameUpdate,
 		UpdateModel: updateModel,
 	}
 }
+
+// NewReloadMessage creates a message which instructs the clients viewing the given route to reload the page.
+func NewReloadMessage(route route.Route) Message {
+	return Message{
+		Route: route.Value(),
+		Name:  MessageNameReload,
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/common/shutdown"
+	"github.com/andreaskoch/allmark/web/handlers/update"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// contentTypeEventStream is the content type of server-sent events.
+	contentTypeEventStream = "text/event-stream; charset=utf-8"
+
+	// eventStreamKeepAliveInterval is the interval in which comments are sent to idle event streams
+	// so that proxies don't close the connection.
+	eventStreamKeepAliveInterval = 30 * time.Second
+
+	// eventStreamRetryInMilliseconds is the reconnection delay for the clients.
+	eventStreamRetryInMilliseconds = 3000
+)
+
+// UpdateEvents returns a handler which sends the live-reload messages for an item as Server-Sent Events
+// (e.g. "documents/Sample-Document.events"). It is an alternative to the websocket which works through
+// proxies that don't support connection upgrades and which can easily be consumed by scripts.
+// Each message is sent as an event with the name of the message ("update" or "reload") and the
+// JSON-encoded message as data.
+func UpdateEvents(logger logger.Logger, headerWriter header.HeaderWriter, hub *update.Hub, updateOrchestrator *orchestrator.UpdateOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		logger := requestLogger(logger, r)
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming is not supported.", http.StatusInternalServerError)
+			return
+		}
+
+		// strip the "events" or ".events" suffix from the path
+		path := r.URL.Path
+		path = strings.TrimSuffix(path, "events")
+		path = strings.TrimSuffix(path, ".")
+
+		// get the request route
+		requestRoute := route.NewFromRequest(path)
+
+		// check if there is a item for the request
+		if exists := updateOrchestrator.ItemExists(requestRoute); !exists {
+			logger.Debug("Route %q was not found.", requestRoute)
+			http.NotFound(w, r)
+			return
+		}
+
+		headerWriter.Write(w, contentTypeEventStream)
+
+		// prevent reverse proxies (e.g. nginx) from buffering the stream
+		w.Header().Set("X-Accel-Buffering", "no")
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", eventStreamRetryInMilliseconds)
+		flusher.Flush()
+
+		// create a new connection
+		c := update.NewEventStreamConnection(hub, r.RemoteAddr, requestRoute)
+
+		logger.Debug("Establishing an event stream for %q", requestRoute.String())
+		hub.Subscribe(c)
+
+		defer func() {
+			c.Close()
+			hub.Unsubscribe(c)
+		}()
+
+		keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case message := <-c.Messages():
+				if err := writeEvent(w, message); err != nil {
+					logger.Debug("Unable to send an event to %s. Error: %s", c.String(), err)
+					return
+				}
+
+				flusher.Flush()
+
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+
+				flusher.Flush()
+
+			case <-c.Closed():
+				return
+
+			// end the stream so the shutdown doesn't have to wait for it;
+			// the client reconnects (e.g. to the restarted server)
+			case <-shutdown.Started():
+				return
+
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+}
+
+// writeEvent writes the given message as a server-sent event to the given writer.
+func writeEvent(w http.ResponseWriter, message update.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", message.Name, data)
+	return err
+}
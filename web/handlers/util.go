@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"text/template"
 )
 
@@ -21,6 +22,12 @@ func getRouteFromRequest(r *http.Request) route.Route {
 	return route.NewFromRequest(r.URL.Path)
 }
 
+// isLongLivedRequest returns a flag indicating whether the given request opens a long-lived
+// connection (a websocket or an event stream).
+func isLongLivedRequest(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != "" || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
 func getBaseURLFromRequest(r *http.Request) string {
 	scheme := "http"
 	if r.TLS != nil {
@@ -0,0 +1,86 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/themes"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WebAppManifest returns a handler which serves the web app manifest of the site.
+func WebAppManifest(headerWriter header.HeaderWriter, webAppOrchestrator *orchestrator.WebAppOrchestrator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(webAppOrchestrator.GetManifest(), "", "\t")
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		headerWriter.Write(w, header.CONTENTTYPE_WEBAPPMANIFEST)
+		w.Write(bytes)
+	})
+}
+
+// ServiceWorker returns a handler which serves the service worker of the site. The service worker caches the
+// start page, the (fingerprinted) theme files and the configured precache route on installation and every item
+// the user visits, so the site remains readable offline.
+func ServiceWorker(headerWriter header.HeaderWriter,
+	templateProvider templates.Provider,
+	webAppOrchestrator *orchestrator.WebAppOrchestrator,
+	basePath string,
+	themeFingerprints *themes.Fingerprints) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		hostname := getBaseURLFromRequest(r)
+		serviceWorkerTemplate, err := templateProvider.GetServiceWorkerTemplate(hostname)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Template not found. Error: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		model := getServiceWorkerModel(basePath, webAppOrchestrator.GetPrecacheURLs(webAppOrchestrator.GetVisibility(getUsername(r))), getThemeFileURLs(basePath, themeFingerprints))
+
+		headerWriter.Write(w, header.CONTENTTYPE_JAVASCRIPT)
+		renderTemplate(serviceWorkerTemplate, model, w)
+	})
+}
+
+// getServiceWorkerModel returns the model of the service worker which caches the given page and theme URLs.
+// The name of the cache contains a hash of the URLs, so the cache is replaced whenever an URL changes
+// (e.g. the fingerprint of a theme file).
+func getServiceWorkerModel(basePath string, pageURLs, themeURLs []string) viewmodel.ServiceWorker {
+	precacheURLs := append(append([]string{}, pageURLs...), themeURLs...)
+
+	return viewmodel.ServiceWorker{
+		CacheName:    "allmark-" + hashutil.FromString(strings.Join(precacheURLs, "\n")),
+		StartURL:     basePath,
+		PrecacheURLs: precacheURLs,
+	}
+}
+
+// getThemeFileURLs returns the paths under which the pages reference the files of the built-in theme.
+func getThemeFileURLs(basePath string, themeFingerprints *themes.Fingerprints) []string {
+	themeFiles := themes.GetTheme().Files
+	urls := make([]string, 0, len(themeFiles))
+	for _, themeFile := range themeFiles {
+		themeFilePath := themeFile.Path()
+		if themeFingerprints != nil {
+			themeFilePath = themeFingerprints.Path(themeFilePath)
+		}
+
+		urls = append(urls, basePath+strings.TrimPrefix(ThemeRoutePrefix, "/")+"/"+themeFilePath)
+	}
+
+	return urls
+}
@@ -12,21 +12,53 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"strings"
 	"text/template"
 )
 
+// XMLSitemap creates a new XML sitemap handler. Sitemaps with more than orchestrator.MaxXMLSitemapEntries URLs
+// are split: the handler returns a sitemap index and the sitemaps are available via the "page" url-parameter.
 func XMLSitemap(headerWriter header.HeaderWriter,
 	xmlSitemapOrchestrator *orchestrator.XmlSitemapOrchestrator,
-	templateProvider templates.Provider) http.Handler {
+	templateProvider templates.Provider,
+	error404Handler http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		// set headers
-		headerWriter.Write(w, header.CONTENTTYPE_XML)
-
 		// get the current hostname
 		hostname := getBaseURLFromRequest(r)
 
+		entries := xmlSitemapOrchestrator.GetSitemapEntires(hostname, xmlSitemapOrchestrator.GetVisibility(getUsername(r)))
+
+		// read the page url-parameter
+		page, pageParameterIsAvailable := getPageParameterFromURL(*r.URL)
+
+		// sitemap index
+		if !pageParameterIsAvailable && len(entries) > orchestrator.MaxXMLSitemapEntries {
+
+			xmlSitemapIndexTemplate, err := templateProvider.GetXMLSitemapIndexTemplate(hostname)
+			if err != nil {
+				fmt.Fprintf(w, "Template not found. Error: %s", err)
+				return
+			}
+
+			sitemapRoute := strings.TrimPrefix(XMLSitemapHandlerRoute, "/")
+			headerWriter.Write(w, header.CONTENTTYPE_XML)
+			renderTemplate(xmlSitemapIndexTemplate, xmlSitemapOrchestrator.GetSitemapIndex(hostname, sitemapRoute, entries), w)
+			return
+		}
+
+		// a single sitemap of the index
+		if pageParameterIsAvailable {
+			pageEntries, found := orchestrator.PagedXMLSitemapEntries(entries, page)
+			if !found {
+				error404Handler.ServeHTTP(w, r)
+				return
+			}
+
+			entries = pageEntries
+		}
+
 		// get the sitemap template
 		xmlSitemapTemplate, err := templateProvider.GetXMLSitemapTemplate(hostname)
 		if err != nil {
@@ -35,9 +67,12 @@ func XMLSitemap(headerWriter header.HeaderWriter,
 		}
 
 		xmlSitemapViewModel := viewmodel.XMLSitemap{
-			Entries: xmlSitemapOrchestrator.GetSitemapEntires(hostname),
+			Entries: entries,
 		}
 
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_XML)
+
 		renderTemplate(xmlSitemapTemplate, xmlSitemapViewModel, w)
 
 	})
@@ -0,0 +1,183 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/web/header"
+	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/view/templates"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// zipFormatParameter is the name of the query parameter which selects the contents of a ZIP download.
+const zipFormatParameter = "format"
+
+const (
+	// zipFormatSource selects the markdown documents and the attached files of the items (the default).
+	zipFormatSource = "source"
+
+	// zipFormatHTML selects the rendered (print) pages of the items and the attached files.
+	zipFormatHTML = "html"
+)
+
+// ZIP returns a handler which streams a ZIP archive of the requested item and all of its descendants: either
+// the markdown documents and the attached files ("?format=source", the default) or the rendered pages and the
+// attached files with relative links between them ("?format=html"). Requests for paths which are not items
+// (e.g. attached ZIP files) are passed to the fallback handler; only the downloads require the given
+// authentication and are restricted by the given concurrency limiter.
+func ZIP(logger logger.Logger,
+	headerWriter header.HeaderWriter,
+	downloadOrchestrator *orchestrator.DownloadOrchestrator,
+	converterModelOrchestrator *orchestrator.ConversionModelOrchestrator,
+	templateProvider templates.Provider,
+	requireAuthentication func(http.Handler) http.Handler,
+	limitDownloads ConcurrencyLimiter,
+	fallbackHandler http.Handler) http.Handler {
+
+	// renderPage returns the rendered page of the given item with relative links to the other items and files of the archive
+	renderPage := func(baseURL string, items []orchestrator.DownloadItem, item orchestrator.DownloadItem) ([]byte, error) {
+		model, found := converterModelOrchestrator.GetConversionModel(baseURL, item.Route)
+		if !found {
+			return nil, fmt.Errorf("The item %q was not found.", item.Route)
+		}
+
+		template, err := templateProvider.GetConversionTemplate(baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("No template for item of type %q.", model.Type)
+		}
+
+		buffer := new(bytes.Buffer)
+		if err := renderTemplate(template, model, buffer); err != nil {
+			return nil, err
+		}
+
+		return []byte(downloadOrchestrator.GetRelativeDownloadPage(baseURL, items, item.Folder, buffer.String())), nil
+	}
+
+	download := func(r *http.Request, w http.ResponseWriter, baseURL, format string, items []orchestrator.DownloadItem) {
+
+		logger := requestLogger(logger, r)
+
+		// set headers
+		headerWriter.Write(w, header.CONTENTTYPE_ZIP)
+		w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, getDownloadFilename(items[0])))
+
+		archive := zip.NewWriter(w)
+		defer archive.Close()
+
+		for _, item := range items {
+
+			switch format {
+			case zipFormatHTML:
+				page, err := renderPage(baseURL, items, item)
+				if err != nil {
+					logger.Error("Unable to render %q for the ZIP download. Error: %s", item.Route, err)
+					return
+				}
+
+				if err := writeZIPEntry(archive, path.Join(item.Folder, orchestrator.DownloadPageFileName), item.LastModified, bytes.NewReader(page)); err != nil {
+					logger.Error("Unable to write the ZIP download of %q. Error: %s", items[0].Route, err)
+					return
+				}
+
+			default:
+				if item.Markdown == "" {
+					break
+				}
+
+				if err := writeZIPEntry(archive, path.Join(item.Folder, orchestrator.DownloadMarkdownFileName), item.LastModified, strings.NewReader(item.Markdown)); err != nil {
+					logger.Error("Unable to write the ZIP download of %q. Error: %s", items[0].Route, err)
+					return
+				}
+			}
+
+			for _, file := range item.Files {
+				lastModified, _ := file.LastModified()
+				if err := file.Data(func(content io.ReadSeeker) error {
+					return writeZIPEntry(archive, file.Path, lastModified, content)
+				}); err != nil {
+					logger.Error("Unable to add the file %q to the ZIP download of %q. Error: %s", file.Path, items[0].Route, err)
+					return
+				}
+			}
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// strip the "zip" or ".zip" suffix from the path
+		path := r.URL.Path
+		path = strings.TrimSuffix(path, "zip")
+		path = strings.TrimSuffix(path, ".")
+
+		// get the request route
+		requestRoute := route.NewFromRequest(path)
+
+		// make sure the request body is closed
+		defer r.Body.Close()
+
+		items, found := downloadOrchestrator.GetDownloadItems(requestRoute, downloadOrchestrator.GetVisibility(getUsername(r)))
+		if !found {
+
+			// not an item (e.g. an attached ZIP file)
+			fallbackHandler.ServeHTTP(w, r)
+			return
+		}
+
+		requireAuthentication(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get(zipFormatParameter)))
+			if format == "" {
+				format = zipFormatSource
+			}
+
+			if format != zipFormatSource && format != zipFormatHTML {
+				http.Error(w, fmt.Sprintf("The format %q is not supported. Use %q or %q.", format, zipFormatSource, zipFormatHTML), http.StatusBadRequest)
+				return
+			}
+
+			baseURL := getBaseURLFromRequest(r)
+			limitDownloads(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				download(r, w, baseURL, format, items)
+			})).ServeHTTP(w, r)
+		})).ServeHTTP(w, r)
+	})
+
+}
+
+// writeZIPEntry adds a file with the given path, modification date and content to the given archive.
+func writeZIPEntry(archive *zip.Writer, filePath string, lastModified time.Time, content io.Reader) error {
+	fileHeader := &zip.FileHeader{Name: filePath, Method: zip.Deflate}
+	if !lastModified.IsZero() {
+		fileHeader.Modified = lastModified
+	}
+
+	fileWriter, err := archive.CreateHeader(fileHeader)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fileWriter, content)
+	return err
+}
+
+// getDownloadFilename returns the name of the ZIP download of the given item (e.g. "Sample-Document.zip").
+func getDownloadFilename(item orchestrator.DownloadItem) string {
+	fileNameRoute := route.NewFromRequest(item.Route.LastComponentName())
+	if item.Route.IsEmpty() {
+		fileNameRoute = route.NewFromRequest(item.Title)
+	}
+
+	return fmt.Sprintf("%s.zip", fileNameRoute.Value())
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package header
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LastModified sets the Last-Modified header to the given date.
+func LastModified(w http.ResponseWriter, lastModified time.Time) {
+	if lastModified.IsZero() {
+		return
+	}
+
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+}
+
+// NotModified checks the conditional headers (If-None-Match, If-Modified-Since) of the given
+// request against the ETag and Last-Modified headers of the response. If the client already has
+// the current version it writes a "304 Not Modified" response and returns true.
+func NotModified(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if !isNotModified(r, w.Header().Get("ETag"), w.Header().Get("Last-Modified")) {
+		return false
+	}
+
+	// remove the entity headers
+	w.Header().Del("Content-Type")
+	w.Header().Del("Content-Length")
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// isNotModified checks if the given request refers to the resource with the given ETag and modification date.
+// The entity tags of the compressed representations (see EncodedETag) match the entity tag of the resource.
+func isNotModified(r *http.Request, etag, lastModified string) bool {
+
+	// If-None-Match takes precedence over If-Modified-Since
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etag == "" {
+			return false
+		}
+
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || getUnencodedETag(candidate) == getUnencodedETag(etag) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+
+	modifiedSince, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !modified.After(modifiedSince)
+}
+
+// EntityTags returns the entity tags of the given If-Match or If-None-Match header value
+// without quotes and weakness indicators (e.g. `"123-ABC", W/"456"` → ["123-ABC", "456"]).
+func EntityTags(value string) []string {
+	entityTags := make([]string, 0)
+
+	for _, candidate := range strings.Split(value, ",") {
+		candidate = strings.TrimSpace(candidate)
+		candidate = strings.TrimPrefix(candidate, "W/")
+		candidate = strings.Trim(candidate, `"`)
+
+		if candidate == "" {
+			continue
+		}
+
+		entityTags = append(entityTags, candidate)
+	}
+
+	return entityTags
+}
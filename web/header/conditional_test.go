@@ -0,0 +1,105 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package header
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_isNotModified_MatchingETag_ResultIsTrue(t *testing.T) {
+	// arrange
+	request, _ := http.NewRequest("GET", "/", nil)
+	request.Header.Set("If-None-Match", `"123-ABC", "8-18889A25"`)
+
+	// act
+	result := isNotModified(request, `"8-18889A25"`, "")
+
+	// assert
+	if !result {
+		t.Errorf("The resource should not be modified if one of the If-None-Match entity tags matches the ETag.")
+	}
+}
+
+func Test_isNotModified_ETagOfCompressedRepresentation_ResultIsTrue(t *testing.T) {
+	// arrange
+	request, _ := http.NewRequest("GET", "/", nil)
+	request.Header.Set("If-None-Match", `W/"8-18889A25-br"`)
+
+	// act
+	result := isNotModified(request, `"8-18889A25"`, "")
+
+	// assert
+	if !result {
+		t.Errorf("The resource should not be modified if the If-None-Match entity tag is the one of its compressed representation.")
+	}
+}
+
+func Test_EncodedETag_StrongETag_EncodingIsAppended(t *testing.T) {
+	// act
+	result := EncodedETag(`"8-18889A25"`, "gzip")
+
+	// assert
+	if result != `"8-18889A25-gzip"` {
+		t.Errorf("The entity tag of the compressed representation should be %q but is %q.", `"8-18889A25-gzip"`, result)
+	}
+}
+
+func Test_isNotModified_DifferentETag_ResultIsFalse(t *testing.T) {
+	// arrange
+	request, _ := http.NewRequest("GET", "/", nil)
+	request.Header.Set("If-None-Match", `"123-ABC"`)
+	request.Header.Set("If-Modified-Since", "Wed, 21 Oct 2015 07:28:00 GMT")
+
+	// act
+	result := isNotModified(request, `"8-18889A25"`, "Wed, 21 Oct 2015 07:28:00 GMT")
+
+	// assert
+	if result {
+		t.Errorf("The resource should be modified if the ETag does not match (If-Modified-Since must be ignored).")
+	}
+}
+
+func Test_isNotModified_IfModifiedSince_ResultDependsOnDate(t *testing.T) {
+	// arrange
+	request, _ := http.NewRequest("GET", "/", nil)
+	request.Header.Set("If-Modified-Since", "Wed, 21 Oct 2015 07:28:00 GMT")
+
+	// act
+	olderResult := isNotModified(request, "", "Tue, 20 Oct 2015 07:28:00 GMT")
+	newerResult := isNotModified(request, "", "Thu, 22 Oct 2015 07:28:00 GMT")
+
+	// assert
+	if !olderResult {
+		t.Errorf("The resource should not be modified if the last modified date is before the If-Modified-Since date.")
+	}
+
+	if newerResult {
+		t.Errorf("The resource should be modified if the last modified date is after the If-Modified-Since date.")
+	}
+}
+
+func Test_EntityTags_QuotedAndWeakTags_TagsAreReturnedWithoutDecoration(t *testing.T) {
+	// arrange
+	value := `"123-ABC", W/"456", *`
+
+	// act
+	result := EntityTags(value)
+
+	// assert
+	if len(result) != 3 || result[0] != "123-ABC" || result[1] != "456" || result[2] != "*" {
+		t.Errorf("EntityTags(%q) should return [123-ABC 456 *] but returned %v.", value, result)
+	}
+}
+
+func Test_EntityTags_EmptyValue_NoTagsAreReturned(t *testing.T) {
+	// act
+	result := EntityTags("")
+
+	// assert
+	if len(result) != 0 {
+		t.Errorf("EntityTags should not return any tags for an empty value but returned %v.", result)
+	}
+}
@@ -5,32 +5,89 @@
 package header
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 const (
-	CONTENTTYPE_HTML = "text/html; charset=utf-8"
-	CONTENTTYPE_TEXT = "text/plain; charset=utf-8"
-	CONTENTTYPE_XML  = "text/xml; charset=utf-8"
-	CONTENTTYPE_JSON = "application/json; charset=utf-8"
-	CONTENTTYPE_DOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document; charset=utf-8"
+	CONTENTTYPE_HTML     = "text/html; charset=utf-8"
+	CONTENTTYPE_TEXT     = "text/plain; charset=utf-8"
+	CONTENTTYPE_XML      = "text/xml; charset=utf-8"
+	CONTENTTYPE_JSON     = "application/json; charset=utf-8"
+	CONTENTTYPE_JSONFEED = "application/feed+json; charset=utf-8"
+	CONTENTTYPE_ATOM     = "application/atom+xml; charset=utf-8"
+	CONTENTTYPE_OPML     = "text/x-opml; charset=utf-8"
+	CONTENTTYPE_CALENDAR = "text/calendar; charset=utf-8"
+	CONTENTTYPE_DOCX     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document; charset=utf-8"
+	CONTENTTYPE_ODT      = "application/vnd.oasis.opendocument.text"
+	CONTENTTYPE_PDF      = "application/pdf"
+	CONTENTTYPE_EPUB     = "application/epub+zip"
+	CONTENTTYPE_ZIP      = "application/zip"
+
+	CONTENTTYPE_JAVASCRIPT     = "text/javascript; charset=utf-8"
+	CONTENTTYPE_WEBAPPMANIFEST = "application/manifest+json; charset=utf-8"
 )
 
 func Cache(w http.ResponseWriter, seconds int) {
-	w.Header().Add("Cache-Control", fmt.Sprintf("public, max-age=%d", seconds))
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", seconds))
 }
 
+// ETag sets a strong entity tag for the given hash.
 func ETag(w http.ResponseWriter, hash string) {
 	if hash == "" {
 		return
 	}
 
-	w.Header().Add("ETag", hash)
+	if !strings.HasPrefix(hash, `"`) {
+		hash = `"` + hash + `"`
+	}
+
+	w.Header().Set("ETag", hash)
+}
+
+// contentEncodings are the content encodings whose name is appended to the entity tags of compressed responses.
+var contentEncodings = []string{"br", "gzip"}
+
+// EncodedETag returns the entity tag of the representation of a resource with the given entity tag which
+// is compressed with the given content encoding (e.g. `"123"` and "br" → `"123-br"`), so that caches don't
+// mix up the compressed and the uncompressed representation.
+func EncodedETag(etag, encoding string) string {
+	if etag == "" || encoding == "" {
+		return etag
+	}
+
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// EncodeETag replaces the entity tag of the given response with the entity tag of its representation
+// which is compressed with the given content encoding (see EncodedETag).
+func EncodeETag(w http.ResponseWriter, encoding string) {
+	if etag := w.Header().Get("ETag"); etag != "" {
+		w.Header().Set("ETag", EncodedETag(etag, encoding))
+	}
+}
+
+// getUnencodedETag returns the given entity tag without the weakness indicator and the content encoding (see EncodedETag).
+func getUnencodedETag(etag string) string {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, encoding := range contentEncodings {
+		if suffix := "-" + encoding + `"`; strings.HasSuffix(etag, suffix) {
+			return strings.TrimSuffix(etag, suffix) + `"`
+		}
+	}
+
+	return etag
+}
+
+// Immutable allows clients to cache the response forever without revalidating it (e.g. for fingerprinted theme files).
+func Immutable(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
 }
 
 func NoCache(w http.ResponseWriter) {
-	w.Header().Add("Cache-Control", "no-cache")
+	w.Header().Set("Cache-Control", "no-cache")
 }
 
 func ContentType(w http.ResponseWriter, contentType string) {
@@ -66,12 +123,43 @@ func (headerWriter noCacheHeaderWriter) Write(w http.ResponseWriter, contentType
 	VaryAcceptEncoding(w)
 }
 
+// immutable header writer
+type immutableHeaderWriter struct {
+}
+
+func (headerWriter immutableHeaderWriter) Write(w http.ResponseWriter, contentType string) {
+	Immutable(w)
+	ContentType(w, contentType)
+	VaryAcceptEncoding(w)
+}
+
+// cache-policy header writer
+type cachePolicyHeaderWriter struct {
+	cacheControl string
+}
+
+func (headerWriter cachePolicyHeaderWriter) Write(w http.ResponseWriter, contentType string) {
+	w.Header().Set("Cache-Control", headerWriter.cacheControl)
+	ContentType(w, contentType)
+	VaryAcceptEncoding(w)
+}
+
+// withPolicy returns a header writer for the given cache policy or
+// the given default header writer if the policy is not configured.
+func withPolicy(policy config.CachePolicy, defaultHeaderWriter HeaderWriter) HeaderWriter {
+	if !policy.IsConfigured() {
+		return defaultHeaderWriter
+	}
+
+	return cachePolicyHeaderWriter{policy.Header()}
+}
+
 type HeaderWriter interface {
 	Write(w http.ResponseWriter, contentType string)
 }
 
 // HeaderWriter factory
-func NewHeaderWriterFactory(reindexIntervalInSeconds int) WriterFactory {
+func NewHeaderWriterFactory(reindexIntervalInSeconds int, cacheControl config.CacheControl) WriterFactory {
 
 	// default cache durations
 	cacheDurationDynamic := 86400   // 1 day
@@ -93,9 +181,18 @@ func NewHeaderWriterFactory(reindexIntervalInSeconds int) WriterFactory {
 
 	// create the factory with the given parameters
 	return WriterFactory{
-		static,
-		dynamic,
-		noCache,
+		static:  static,
+		dynamic: dynamic,
+		noCache: noCache,
+
+		immutable: immutableHeaderWriter{},
+
+		items:      withPolicy(cacheControl.Items, dynamic),
+		json:       withPolicy(cacheControl.JSON, dynamic),
+		rss:        withPolicy(cacheControl.RSS, dynamic),
+		thumbnails: withPolicy(cacheControl.Thumbnails, static),
+		files:      withPolicy(cacheControl.Files, dynamic),
+		theme:      withPolicy(cacheControl.Theme, static),
 	}
 
 }
@@ -104,6 +201,16 @@ type WriterFactory struct {
 	static  HeaderWriter
 	dynamic HeaderWriter
 	noCache HeaderWriter
+
+	immutable HeaderWriter
+
+	// per-content-type header writers
+	items      HeaderWriter
+	json       HeaderWriter
+	rss        HeaderWriter
+	thumbnails HeaderWriter
+	files      HeaderWriter
+	theme      HeaderWriter
 }
 
 func (writerFactory *WriterFactory) Static() HeaderWriter {
@@ -117,3 +224,38 @@ func (writerFactory *WriterFactory) Dynamic() HeaderWriter {
 func (writerFactory *WriterFactory) NoCache() HeaderWriter {
 	return writerFactory.noCache
 }
+
+// Immutable returns the header writer for responses which never change (e.g. fingerprinted theme files).
+func (writerFactory *WriterFactory) Immutable() HeaderWriter {
+	return writerFactory.immutable
+}
+
+// Items returns the header writer for the HTML pages of the items.
+func (writerFactory *WriterFactory) Items() HeaderWriter {
+	return writerFactory.items
+}
+
+// JSON returns the header writer for the JSON representations.
+func (writerFactory *WriterFactory) JSON() HeaderWriter {
+	return writerFactory.json
+}
+
+// RSS returns the header writer for the RSS feeds.
+func (writerFactory *WriterFactory) RSS() HeaderWriter {
+	return writerFactory.rss
+}
+
+// Thumbnails returns the header writer for the thumbnail images.
+func (writerFactory *WriterFactory) Thumbnails() HeaderWriter {
+	return writerFactory.thumbnails
+}
+
+// Files returns the header writer for the files attached to the items.
+func (writerFactory *WriterFactory) Files() HeaderWriter {
+	return writerFactory.files
+}
+
+// Theme returns the header writer for the theme assets.
+func (writerFactory *WriterFactory) Theme() HeaderWriter {
+	return writerFactory.theme
+}
@@ -0,0 +1,43 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package header
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_NewHeaderWriterFactory_ThemePolicy_ConfiguredCacheControlIsUsed(t *testing.T) {
+	// arrange
+	cacheControl := config.CacheControl{
+		Theme: config.CachePolicy{MaxAgeInSeconds: 31536000, Immutable: true},
+	}
+
+	factory := NewHeaderWriterFactory(0, cacheControl)
+	response := httptest.NewRecorder()
+
+	// act
+	factory.Theme().Write(response, "")
+
+	// assert
+	if result := response.Header().Get("Cache-Control"); result != "public, max-age=31536000, immutable" {
+		t.Errorf("The theme header writer should use the configured policy but wrote %q.", result)
+	}
+}
+
+func Test_NewHeaderWriterFactory_NoItemPolicy_DynamicDefaultIsUsed(t *testing.T) {
+	// arrange
+	factory := NewHeaderWriterFactory(60, config.CacheControl{})
+	response := httptest.NewRecorder()
+
+	// act
+	factory.Items().Write(response, CONTENTTYPE_HTML)
+
+	// assert
+	if result := response.Header().Get("Cache-Control"); result != "public, max-age=30" {
+		t.Errorf("The item header writer should use the dynamic default (half of the reindex interval) but wrote %q.", result)
+	}
+}
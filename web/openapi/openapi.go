@@ -0,0 +1,127 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openapi contains the model of OpenAPI 3 documents (see https://spec.openapis.org/oas/v3.0.3)
+// which describe JSON APIs, and creates their schemas from the Go types of the API models.
+package openapi
+
+// Version is the version of the OpenAPI specification the documents are based on.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// New creates a new OpenAPI document with the given title, (API) version and server URL.
+func New(title, version, serverURL string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   title,
+			Version: version,
+		},
+		Servers: []Server{{URL: serverURL}},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+}
+
+// AddOperation adds the given operation for the given HTTP method (e.g. "get") to the path with the given template.
+func (document *Document) AddOperation(pathTemplate, method string, operation *Operation) {
+	pathItem, exists := document.Paths[pathTemplate]
+	if !exists {
+		pathItem = make(PathItem)
+		document.Paths[pathTemplate] = pathItem
+	}
+
+	pathItem[method] = operation
+}
+
+// Info contains the metadata of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is the (absolute or relative) URL of the API.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem contains the operations of a path by lower-case HTTP method (e.g. "get", "put").
+type PathItem map[string]*Operation
+
+// Operation describes an API operation.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the request body of an operation.
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType contains the schema of a request or response body with a specific content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components contains the schemas and security schemes which are referenced by the operations.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate (e.g. type "http" with the scheme "digest").
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema describes a JSON value.
+type Schema struct {
+	Ref string `json:"$ref,omitempty"`
+
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
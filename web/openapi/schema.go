@@ -0,0 +1,104 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf returns the schema of the given model. Structs are added to the component schemas of the
+// document (by their type name) and referenced, so that every model is only described once.
+func (document *Document) SchemaOf(model interface{}) *Schema {
+	return document.getSchema(reflect.TypeOf(model))
+}
+
+func (document *Document) getSchema(modelType reflect.Type) *Schema {
+	switch modelType.Kind() {
+	case reflect.Ptr:
+		return document.getSchema(modelType.Elem())
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: document.getSchema(modelType.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: document.getSchema(modelType.Elem())}
+
+	case reflect.Struct:
+		if modelType == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+
+		name := modelType.Name()
+		if _, exists := document.Components.Schemas[name]; !exists {
+
+			// add a placeholder first so recursive types (e.g. trees) reference the schema instead of describing it again
+			schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			document.Components.Schemas[name] = schema
+			document.addProperties(schema, modelType)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	// values which can't be described (e.g. interfaces) can have any type
+	return &Schema{}
+}
+
+// addProperties adds the exported fields of the given struct type to the properties of the given schema.
+// The fields of embedded structs are added to the schema itself, just like JSON encoding does.
+func (document *Document) addProperties(schema *Schema, structType reflect.Type) {
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+
+		name, options := field.Name, ""
+		if tag, hasTag := field.Tag.Lookup("json"); hasTag {
+			if tag == "-" {
+				continue
+			}
+
+			name, options, _ = strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			document.addProperties(schema, field.Type)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		schema.Properties[name] = document.getSchema(field.Type)
+
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	Route    string     `json:"route"`
+	Created  *time.Time `json:"created,omitempty"`
+	Tags     []string   `json:"tags"`
+	Children []testItem `json:"children"`
+	Ignored  string     `json:"-"`
+}
+
+type testItemDetails struct {
+	testItem
+
+	Size int64 `json:"size"`
+}
+
+func Test_SchemaOf_Struct_SchemaIsReferenced(t *testing.T) {
+	// arrange
+	document := New("Test", "v1", "/")
+
+	// act
+	result := document.SchemaOf(testItem{})
+
+	// assert
+	if result.Ref != "#/components/schemas/testItem" {
+		t.Errorf("SchemaOf should return a reference to the testItem schema but returned %#v.", result)
+	}
+
+	if _, exists := document.Components.Schemas["testItem"]; !exists {
+		t.Errorf("SchemaOf should add the testItem schema to the components.")
+	}
+}
+
+func Test_SchemaOf_Struct_PropertiesAreDescribedWithTheirJSONNames(t *testing.T) {
+	// arrange
+	document := New("Test", "v1", "/")
+
+	// act
+	document.SchemaOf(testItem{})
+
+	// assert
+	schema := document.Components.Schemas["testItem"]
+
+	if len(schema.Properties) != 4 {
+		t.Errorf("The testItem schema should have 4 properties but has %d.", len(schema.Properties))
+	}
+
+	if created := schema.Properties["created"]; created == nil || created.Type != "string" || created.Format != "date-time" {
+		t.Errorf("The created property should be a date-time string but is %#v.", created)
+	}
+
+	if tags := schema.Properties["tags"]; tags == nil || tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("The tags property should be an array of strings but is %#v.", tags)
+	}
+
+	if children := schema.Properties["children"]; children == nil || children.Items.Ref != "#/components/schemas/testItem" {
+		t.Errorf("The children property should reference the testItem schema but is %#v.", children)
+	}
+}
+
+func Test_SchemaOf_OmitEmptyFields_FieldsAreNotRequired(t *testing.T) {
+	// arrange
+	document := New("Test", "v1", "/")
+
+	// act
+	document.SchemaOf(testItem{})
+
+	// assert
+	required := document.Components.Schemas["testItem"].Required
+	expected := []string{"route", "tags", "children"}
+
+	if len(required) != len(expected) {
+		t.Fatalf("The required properties should be %v but are %v.", expected, required)
+	}
+
+	for index := range expected {
+		if required[index] != expected[index] {
+			t.Errorf("The required properties should be %v but are %v.", expected, required)
+		}
+	}
+}
+
+func Test_SchemaOf_EmbeddedStruct_PropertiesAreInlined(t *testing.T) {
+	// arrange
+	document := New("Test", "v1", "/")
+
+	// act
+	document.SchemaOf(testItemDetails{})
+
+	// assert
+	schema := document.Components.Schemas["testItemDetails"]
+
+	if schema.Properties["route"] == nil || schema.Properties["size"] == nil {
+		t.Errorf("The testItemDetails schema should contain the route and the size but contains %v.", schema.Properties)
+	}
+
+	if size := schema.Properties["size"]; size.Type != "integer" || size.Format != "int64" {
+		t.Errorf("The size property should be an int64 integer but is %#v.", size)
+	}
+}
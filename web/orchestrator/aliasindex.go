@@ -14,17 +14,20 @@ type AliasIndexOrchestrator struct {
 	*Orchestrator
 }
 
-// GetIndexEntries returns a list of all alias index entry models.
-func (orchestrator *AliasIndexOrchestrator) GetIndexEntries(hostname, prefix string) []viewmodel.Alias {
+// GetIndexEntries returns a list of the alias index entry models of all items which can be shown with the given visibility.
+func (orchestrator *AliasIndexOrchestrator) GetIndexEntries(hostname, prefix string, visibility Visibility) []viewmodel.Alias {
 
-	itemPathProvider := orchestrator.absolutePather("/")
-	aliasPathProvider := orchestrator.absolutePather("/" + prefix)
+	itemPathProvider := orchestrator.absolutePather(orchestrator.basePath())
+	aliasPathProvider := orchestrator.absolutePather(orchestrator.basePath() + prefix)
 
 	var aliasIndexEntries []viewmodel.Alias
 
 	for entry := range orchestrator.getAliasMap().Iter() {
 		alias := entry.Key
 		item := entry.Val
+		if !visibility.IsVisible(item.Route()) {
+			continue
+		}
 
 		aliasIndexEntries = append(aliasIndexEntries, viewmodel.Alias{
 			Name:        fmt.Sprintf("%s%s", prefix, alias),
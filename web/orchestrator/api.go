@@ -0,0 +1,493 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/dataaccess"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/orchestrator/search"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// apiSearchSnippetLength is the maximum number of characters of the snippets of search hits.
+const apiSearchSnippetLength = 200
+
+// APISearchFilter restricts the search hits of the REST API.
+type APISearchFilter struct {
+	// Tags, if set, requires that the items have all of the given tags.
+	Tags []string
+
+	// Types, if set, requires that the items have one of the given types (e.g. "document", "presentation").
+	Types []string
+}
+
+// Matches returns a flag indicating whether the given item passes the filter.
+func (filter APISearchFilter) Matches(item *model.Item) bool {
+	for _, tag := range filter.Tags {
+		if !containsIgnoringCase(item.MetaData.Tags, tag) {
+			return false
+		}
+	}
+
+	if len(filter.Types) > 0 && !containsIgnoringCase(filter.Types, item.Type.String()) {
+		return false
+	}
+
+	return true
+}
+
+// APIOrchestrator creates the view models of the REST API.
+type APIOrchestrator struct {
+	*Orchestrator
+
+	viewModelOrchestrator *ViewModelOrchestrator
+}
+
+// GetItems returns the given page of all items which can be shown with the given visibility (sorted by creation date, newest first).
+func (orchestrator *APIOrchestrator) GetItems(pageSize, page int, visibility Visibility) viewmodel.APIItemList {
+	allItems := visibleItems(orchestrator.getAllItems(), visibility)
+
+	totalPages := (len(allItems) + pageSize - 1) / pageSize
+
+	items := make([]viewmodel.APIItem, 0)
+	pageItems, _ := pagedItems(allItems, pageSize, page)
+	for _, item := range pageItems {
+		items = append(items, orchestrator.getAPIItem(item))
+	}
+
+	return viewmodel.APIItemList{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: len(allItems),
+		TotalPages: totalPages,
+		Items:      items,
+	}
+}
+
+// GetItem returns the item with given route including its content, children and files.
+// Items which cannot be shown with the given visibility are not found and are not listed as children or related items.
+func (orchestrator *APIOrchestrator) GetItem(itemRoute route.Route, visibility Visibility) (itemDetails viewmodel.APIItemDetails, found bool) {
+	item := orchestrator.getItem(itemRoute)
+	if item == nil || !visibility.IsVisible(item.Route()) {
+		return itemDetails, false
+	}
+
+	children := make([]viewmodel.APIItem, 0)
+	for _, child := range visibleItems(orchestrator.getChildren(itemRoute), visibility) {
+		children = append(children, orchestrator.getAPIItem(child))
+	}
+
+	relatedItems := make([]viewmodel.APIItem, 0)
+	for _, relatedItem := range visibleItems(orchestrator.getRelatedItems(itemRoute), visibility) {
+		relatedItems = append(relatedItems, orchestrator.getAPIItem(relatedItem))
+	}
+
+	itemDetails = viewmodel.APIItemDetails{
+		APIItem:  orchestrator.getAPIItem(item),
+		Content:  orchestrator.viewModelOrchestrator.getHTMLFromItem(orchestrator.itemPather(), item),
+		Markdown: item.Markdown,
+		Children: children,
+		Files:    orchestrator.getAPIFiles(item),
+		Related:  relatedItems,
+		Location: getAPILocation(item),
+	}
+
+	return itemDetails, true
+}
+
+// Search returns at most pageSize of the items which match the given query and filter and can be shown with
+// the given visibility (sorted by score), starting at the given offset. If there are more hits the results
+// contain a cursor for the next page.
+func (orchestrator *APIOrchestrator) Search(query string, filter APISearchFilter, offset, pageSize int, visibility Visibility) viewmodel.APISearchResults {
+	type match struct {
+		item   *model.Item
+		result search.Result
+	}
+
+	// filter all results but only render the requested ones (the snippets of hidden items are never created)
+	matches := make([]match, 0)
+	searchIndex := orchestrator.searchIndex()
+	for _, result := range visibleSearchResults(searchIndex.Search(query, searchIndex.Size()), visibility) {
+		item := orchestrator.getItem(result.Route)
+		if item == nil || !filter.Matches(item) {
+			continue
+		}
+
+		matches = append(matches, match{item, result})
+	}
+
+	totalHits := len(matches)
+	totalPages := (totalHits + pageSize - 1) / pageSize
+
+	start := offset
+	if start > totalHits {
+		start = totalHits
+	}
+
+	end := start + pageSize
+	if end > totalHits {
+		end = totalHits
+	}
+
+	hits := make([]viewmodel.APISearchHit, 0, end-start)
+	for _, match := range matches[start:end] {
+		hits = append(hits, orchestrator.getAPISearchHit(match.item, match.result))
+	}
+
+	results := viewmodel.APISearchResults{
+		Query:      query,
+		Page:       offset/pageSize + 1,
+		PageSize:   pageSize,
+		Offset:     offset,
+		TotalHits:  totalHits,
+		TotalPages: totalPages,
+		Hits:       hits,
+	}
+
+	if end < totalHits {
+		results.NextCursor = search.NewCursor(end, getSearchCursorParameters(query, filter, pageSize)...)
+	}
+
+	return results
+}
+
+// ParseSearchCursor returns the offset encoded in the given continuation token of a search with the given parameters.
+func (orchestrator *APIOrchestrator) ParseSearchCursor(cursor, query string, filter APISearchFilter, pageSize int) (int, error) {
+	return search.ParseCursor(cursor, getSearchCursorParameters(query, filter, pageSize)...)
+}
+
+// getSearchCursorParameters returns the parameters of a search which a cursor is bound to.
+func getSearchCursorParameters(query string, filter APISearchFilter, pageSize int) []string {
+	return []string{query, strings.Join(filter.Tags, ","), strings.Join(filter.Types, ","), fmt.Sprintf("%d", pageSize)}
+}
+
+// getAPISearchHit returns the search hit model for the given item and search result.
+func (orchestrator *APIOrchestrator) getAPISearchHit(item *model.Item, result search.Result) viewmodel.APISearchHit {
+	searchIndex := orchestrator.searchIndex()
+
+	var apiFile *viewmodel.APIFile
+	if file := getMatchedFile(item, result); file != nil {
+		if fileModel, err := orchestrator.getAPIFile(file); err == nil {
+			apiFile = &fileModel
+		}
+	}
+
+	var snippet search.Snippet
+	if apiFile != nil {
+		snippet = searchIndex.GetFileSnippet(result.File, result.Terms, apiSearchSnippetLength)
+	} else {
+		snippet = searchIndex.GetItemSnippet(item, result.Terms, apiSearchSnippetLength)
+	}
+
+	return viewmodel.APISearchHit{
+		APIItem:            orchestrator.getAPIItem(item),
+		Score:              result.Score,
+		Snippet:            snippet.Text,
+		HighlightedSnippet: snippet.HTML(),
+		File:               apiFile,
+	}
+}
+
+// GetTags returns the tags of all items which can be shown with the given visibility (sorted by name)
+// with the number of items that are tagged with them.
+func (orchestrator *APIOrchestrator) GetTags(visibility Visibility) []viewmodel.APITag {
+	tags := make([]viewmodel.APITag, 0)
+	for tag, items := range orchestrator.getItemsByTag(visibility) {
+		tags = append(tags, orchestrator.getAPITag(tag, len(items)))
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags
+}
+
+// GetTag returns the tag with the given name and all items which are tagged with it and can be shown with the
+// given visibility (sorted by creation date, newest first).
+func (orchestrator *APIOrchestrator) GetTag(tag string, visibility Visibility) (tagDetails viewmodel.APITagDetails, found bool) {
+	items, found := orchestrator.getItemsByTag(visibility)[tag]
+	if !found {
+		return tagDetails, false
+	}
+
+	apiItems := make([]viewmodel.APIItem, 0)
+	for _, item := range items {
+		apiItems = append(apiItems, orchestrator.getAPIItem(item))
+	}
+
+	return viewmodel.APITagDetails{
+		APITag: orchestrator.getAPITag(tag, len(items)),
+		Items:  apiItems,
+	}, true
+}
+
+// getItemsByTag returns all items which can be shown with the given visibility (sorted by creation date, newest first)
+// grouped by their tags.
+func (orchestrator *APIOrchestrator) getItemsByTag(visibility Visibility) map[string][]*model.Item {
+	itemsByTag := make(map[string][]*model.Item)
+	for _, item := range visibleItems(orchestrator.getAllItems(), visibility) {
+		for _, tag := range item.MetaData.Tags {
+			itemsByTag[tag] = append(itemsByTag[tag], item)
+		}
+	}
+
+	return itemsByTag
+}
+
+func (orchestrator *APIOrchestrator) getAPITag(tag string, numberOfItems int) viewmodel.APITag {
+	return viewmodel.APITag{
+		Name:          tag,
+		URL:           orchestrator.tagPather().Path(url.QueryEscape(tag)),
+		NumberOfItems: numberOfItems,
+	}
+}
+
+// GetTree returns the hierarchy of all items which can be shown with the given visibility starting with the repository root.
+func (orchestrator *APIOrchestrator) GetTree(visibility Visibility) viewmodel.APITreeNode {
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	return orchestrator.getTreeNode(rootItem, visibility)
+}
+
+// GetGraph returns all items which can be shown with the given visibility and the references and links between them.
+func (orchestrator *APIOrchestrator) GetGraph(visibility Visibility) viewmodel.APIGraph {
+	graph := orchestrator.links()
+
+	apiGraph := viewmodel.APIGraph{
+		Nodes: make([]viewmodel.APIGraphNode, 0),
+		Links: make([]viewmodel.APIGraphLink, 0),
+	}
+
+	for _, itemRoute := range graph.Routes() {
+		item := orchestrator.getItem(itemRoute)
+		if item == nil || !visibility.IsVisible(itemRoute) {
+			continue
+		}
+
+		links := getVisibleRoutes(graph.Links(itemRoute), visibility)
+
+		apiGraph.Nodes = append(apiGraph.Nodes, viewmodel.APIGraphNode{
+			Route:         itemRoute.Value(),
+			URL:           orchestrator.itemPather().Path(itemRoute.Value()),
+			Type:          item.Type.String(),
+			Title:         item.Title,
+			OutgoingLinks: len(links),
+			Backlinks:     len(getVisibleRoutes(graph.Backlinks(itemRoute), visibility)),
+		})
+
+		for _, linkedRoute := range links {
+			apiGraph.Links = append(apiGraph.Links, viewmodel.APIGraphLink{
+				Source: itemRoute.Value(),
+				Target: linkedRoute.Value(),
+			})
+		}
+	}
+
+	return apiGraph
+}
+
+// IsWritable returns a flag indicating whether the items and files of the repository can be modified.
+func (orchestrator *APIOrchestrator) IsWritable() bool {
+	_, isWriter := orchestrator.repository.(dataaccess.Writer)
+	return isWriter
+}
+
+// SaveItem creates or updates the markdown document of the item with the given route.
+func (orchestrator *APIOrchestrator) SaveItem(itemRoute route.Route, markdown []byte, precondition dataaccess.Precondition) (apiItem viewmodel.APIItem, created bool, err error) {
+	writer, err := orchestrator.writer()
+	if err != nil {
+		return apiItem, false, err
+	}
+
+	repositoryItem, created, err := writer.SaveItem(itemRoute, markdown, precondition)
+	if err != nil {
+		return apiItem, false, err
+	}
+
+	item := orchestrator.parseItem(repositoryItem)
+	if item == nil {
+		return apiItem, created, fmt.Errorf("The item %q was saved but could not be parsed.", itemRoute.Value())
+	}
+
+	return orchestrator.getAPIItem(item), created, nil
+}
+
+// DeleteItem deletes the item with the given route.
+func (orchestrator *APIOrchestrator) DeleteItem(itemRoute route.Route, precondition dataaccess.Precondition) error {
+	writer, err := orchestrator.writer()
+	if err != nil {
+		return err
+	}
+
+	return writer.DeleteItem(itemRoute, precondition)
+}
+
+// SaveFile creates or updates the file with the given path which is attached to the item with the given route.
+func (orchestrator *APIOrchestrator) SaveFile(itemRoute route.Route, filePath string, data io.Reader, precondition dataaccess.Precondition) (apiFile viewmodel.APIFile, created bool, err error) {
+	writer, err := orchestrator.writer()
+	if err != nil {
+		return apiFile, false, err
+	}
+
+	repositoryFile, created, err := writer.SaveFile(itemRoute, filePath, data, precondition)
+	if err != nil {
+		return apiFile, false, err
+	}
+
+	file := orchestrator.parseFile(repositoryFile)
+	if file == nil {
+		return apiFile, created, fmt.Errorf("The file %q of item %q was saved but could not be parsed.", filePath, itemRoute.Value())
+	}
+
+	apiFile, err = orchestrator.getAPIFile(file)
+	return apiFile, created, err
+}
+
+// DeleteFile deletes the file with the given path which is attached to the item with the given route.
+func (orchestrator *APIOrchestrator) DeleteFile(itemRoute route.Route, filePath string, precondition dataaccess.Precondition) error {
+	writer, err := orchestrator.writer()
+	if err != nil {
+		return err
+	}
+
+	return writer.DeleteFile(itemRoute, filePath, precondition)
+}
+
+// writer returns the writer of the repository.
+func (orchestrator *APIOrchestrator) writer() (dataaccess.Writer, error) {
+	writer, isWriter := orchestrator.repository.(dataaccess.Writer)
+	if !isWriter {
+		return nil, fmt.Errorf("%w: The repository cannot be modified.", dataaccess.ErrConflict)
+	}
+
+	return writer, nil
+}
+
+func (orchestrator *APIOrchestrator) getTreeNode(item *model.Item, visibility Visibility) viewmodel.APITreeNode {
+	children := make([]viewmodel.APITreeNode, 0)
+	for _, child := range visibleItems(orchestrator.getChildren(item.Route()), visibility) {
+		children = append(children, orchestrator.getTreeNode(child, visibility))
+	}
+
+	return viewmodel.APITreeNode{
+		Route:    item.Route().Value(),
+		URL:      orchestrator.itemPather().Path(item.Route().Value()),
+		Type:     item.Type.String(),
+		Title:    item.Title,
+		Children: children,
+	}
+}
+
+func (orchestrator *APIOrchestrator) getAPIItem(item *model.Item) viewmodel.APIItem {
+	apiItem := viewmodel.APIItem{
+		Route: item.Route().Value(),
+		URL:   orchestrator.itemPather().Path(item.Route().Value()),
+
+		Type:        item.Type.String(),
+		Title:       item.Title,
+		Description: item.Description,
+
+		Language:  getLanguageCode(item.MetaData.Language),
+		Direction: getDirectionCode(item.MetaData.Direction),
+
+		Author:  item.MetaData.Author,
+		Tags:    make([]string, 0),
+		Aliases: make([]string, 0),
+
+		Created:      getAPITime(item.MetaData.CreationDate),
+		LastModified: getAPITime(item.MetaData.LastModifiedDate),
+
+		Hash: item.Hash,
+	}
+
+	if parentRoute, exists := item.Route().Parent(); exists && item.Route().Level() > 0 {
+		apiItem.ParentRoute = parentRoute.Value()
+	}
+
+	apiItem.Tags = append(apiItem.Tags, item.MetaData.Tags...)
+	apiItem.Aliases = append(apiItem.Aliases, item.MetaData.Aliases...)
+
+	return apiItem
+}
+
+func (orchestrator *APIOrchestrator) getAPIFiles(item *model.Item) []viewmodel.APIFile {
+	files := make([]viewmodel.APIFile, 0)
+
+	for _, file := range item.Files() {
+		apiFile, err := orchestrator.getAPIFile(file)
+		if err != nil {
+			orchestrator.logger.Warn(err.Error())
+			continue
+		}
+
+		files = append(files, apiFile)
+	}
+
+	return files
+}
+
+func (orchestrator *APIOrchestrator) getAPIFile(file *model.File) (viewmodel.APIFile, error) {
+	fileModel, err := toViewModel(orchestrator.itemPather(), file)
+	if err != nil {
+		return viewmodel.APIFile{}, err
+	}
+
+	return viewmodel.APIFile{
+		Route:        file.Route().Value(),
+		URL:          orchestrator.itemPather().Path(file.Route().Value()),
+		Name:         fileModel.Name,
+		MimeType:     fileModel.MimeType,
+		Hash:         fileModel.Hash,
+		LastModified: fileModel.LastModified,
+	}, nil
+}
+
+// getAPILocation returns the geo location of the given item; or nil if the item has no location.
+func getAPILocation(item *model.Item) *viewmodel.APILocation {
+	if item.MetaData.GeoInformation == (model.GeoInformation{}) {
+		return nil
+	}
+
+	return &viewmodel.APILocation{
+		PlaceName: getPlaceName(item),
+		Street:    item.MetaData.GeoInformation.Street,
+		City:      item.MetaData.GeoInformation.City,
+		Postcode:  item.MetaData.GeoInformation.Postcode,
+		Country:   item.MetaData.GeoInformation.Country,
+		Latitude:  item.MetaData.GeoInformation.Latitude,
+		Longitude: item.MetaData.GeoInformation.Longitude,
+	}
+}
+
+// containsIgnoringCase returns a flag indicating whether the given list contains the given value (ignoring the case).
+func containsIgnoringCase(list []string, value string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(strings.TrimSpace(entry), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getAPITime returns a pointer to the given time; or nil if the time is not initialized.
+func getAPITime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+
+	return &value
+}
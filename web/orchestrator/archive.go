@@ -0,0 +1,180 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// ArchiveRoutePrefix is the route of the archive overview; the year and month pages are below it (e.g. "archive/2015/08").
+const ArchiveRoutePrefix = "archive"
+
+// ArchiveOrchestrator provides the items by the year and month of their creation.
+type ArchiveOrchestrator struct {
+	*Orchestrator
+}
+
+// GetYears returns all years and months which contain items that can be shown with the given visibility (the most recent first).
+func (orchestrator *ArchiveOrchestrator) GetYears(visibility Visibility) []viewmodel.ArchiveYear {
+	return orchestrator.getArchiveYears(getArchiveItems(visibleItems(orchestrator.getAllItems(), visibility)))
+}
+
+// GetArchive returns the archive page for the given year and month (or the whole year if the month is zero).
+// It returns false if there are no items in the given period which can be shown with the given visibility.
+func (orchestrator *ArchiveOrchestrator) GetArchive(year, month int, visibility Visibility) (archive viewmodel.Archive, found bool) {
+	items := getArchiveItems(visibleItems(orchestrator.getAllItems(), visibility))
+	years := orchestrator.getArchiveYears(items)
+
+	periodItems := make([]*model.Item, 0)
+	for _, item := range items {
+		created := item.MetaData.CreationDate
+		if created.Year() == year && (month == 0 || int(created.Month()) == month) {
+			periodItems = append(periodItems, item)
+		}
+	}
+
+	if len(periodItems) == 0 {
+		return archive, false
+	}
+
+	archive.Year = year
+	archive.Month = month
+
+	for _, archiveYear := range years {
+		if archiveYear.Year == year {
+			archive.Years = []viewmodel.ArchiveYear{archiveYear}
+		}
+	}
+
+	if month == 0 {
+		return archive, true
+	}
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	// items by day (the most recent day first)
+	itemsByDay := make(map[int]int)
+	for _, item := range periodItems {
+		baseModel := getBaseModel(rootItem, item, orchestrator.config)
+		baseModel.Route = orchestrator.itemPather().Path(item.Route().Value())
+
+		date := item.MetaData.CreationDate.Format("2006-01-02")
+		if len(archive.Days) == 0 || archive.Days[len(archive.Days)-1].Date != date {
+			archive.Days = append(archive.Days, viewmodel.ArchiveDay{Date: date})
+		}
+
+		archive.Days[len(archive.Days)-1].Items = append(archive.Days[len(archive.Days)-1].Items, baseModel)
+		itemsByDay[item.MetaData.CreationDate.Day()]++
+	}
+
+	archive.Calendar = getArchiveCalendar(year, time.Month(month), itemsByDay)
+
+	// the adjacent months with items
+	months := make([]viewmodel.ArchiveMonth, 0)
+	for _, archiveYear := range years {
+		months = append(months, archiveYear.Months...)
+	}
+
+	for index, archiveMonth := range months {
+		if archiveMonth.Year != year || archiveMonth.Month != month {
+			continue
+		}
+
+		if index > 0 {
+			archive.Next = &months[index-1]
+		}
+
+		if index < len(months)-1 {
+			archive.Previous = &months[index+1]
+		}
+	}
+
+	return archive, true
+}
+
+// getArchiveYears returns the years and months of the given items (sorted by their creation date, the most recent first).
+func (orchestrator *ArchiveOrchestrator) getArchiveYears(items []*model.Item) []viewmodel.ArchiveYear {
+	pather := orchestrator.itemPather()
+
+	years := make([]viewmodel.ArchiveYear, 0)
+	for _, item := range items {
+		created := item.MetaData.CreationDate
+		if len(years) == 0 || years[len(years)-1].Year != created.Year() {
+			years = append(years, viewmodel.ArchiveYear{
+				Year:  created.Year(),
+				Route: pather.Path(fmt.Sprintf("%s/%04d/", ArchiveRoutePrefix, created.Year())),
+			})
+		}
+
+		archiveYear := &years[len(years)-1]
+		archiveYear.NumberOfItems++
+
+		if len(archiveYear.Months) == 0 || archiveYear.Months[len(archiveYear.Months)-1].Month != int(created.Month()) {
+			archiveYear.Months = append(archiveYear.Months, viewmodel.ArchiveMonth{
+				Year:  created.Year(),
+				Month: int(created.Month()),
+				Date:  fmt.Sprintf("%04d-%02d-01", created.Year(), created.Month()),
+				Route: pather.Path(fmt.Sprintf("%s/%04d/%02d/", ArchiveRoutePrefix, created.Year(), created.Month())),
+			})
+		}
+
+		archiveYear.Months[len(archiveYear.Months)-1].NumberOfItems++
+	}
+
+	return years
+}
+
+// getArchiveItems returns the given items which have a creation date sorted by their creation date (the most recent
+// first). Items with the noindex flag are skipped.
+func getArchiveItems(items []*model.Item) []*model.Item {
+	archiveItems := make([]*model.Item, 0, len(items))
+	for _, item := range items {
+		if item.MetaData.NoIndex || item.MetaData.CreationDate.IsZero() {
+			continue
+		}
+
+		archiveItems = append(archiveItems, item)
+	}
+
+	model.SortItemsBy(func(item1, item2 *model.Item) bool {
+		return item1.MetaData.CreationDate.After(item2.MetaData.CreationDate)
+	}).Sort(archiveItems)
+
+	return archiveItems
+}
+
+// getArchiveCalendar returns the weeks (Monday to Sunday) of the given month with the given number of items per day.
+func getArchiveCalendar(year int, month time.Month, itemsByDay map[int]int) []viewmodel.ArchiveWeek {
+	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	numberOfDays := firstDay.AddDate(0, 1, -1).Day()
+
+	// the number of days of the first week which belong to the previous month
+	offset := (int(firstDay.Weekday()) + 6) % 7
+
+	weeks := make([]viewmodel.ArchiveWeek, 0, 6)
+	for day := 1 - offset; day <= numberOfDays; day += 7 {
+		week := make(viewmodel.ArchiveWeek, 7)
+		for weekday := range week {
+			if dayOfMonth := day + weekday; dayOfMonth >= 1 && dayOfMonth <= numberOfDays {
+				week[weekday] = viewmodel.ArchiveCalendarDay{
+					Day:           dayOfMonth,
+					Date:          fmt.Sprintf("%04d-%02d-%02d", year, month, dayOfMonth),
+					NumberOfItems: itemsByDay[dayOfMonth],
+				}
+			}
+		}
+
+		weeks = append(weeks, week)
+	}
+
+	return weeks
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/model"
+)
+
+func Test_getArchiveCalendar_MonthStartingOnSaturday_WeeksStartOnMonday(t *testing.T) {
+	// arrange
+	itemsByDay := map[int]int{3: 2}
+
+	// act
+	weeks := getArchiveCalendar(2015, time.August, itemsByDay)
+
+	// assert
+	if len(weeks) != 6 {
+		t.Fatalf("August 2015 should span 6 weeks but the calendar has %d weeks.", len(weeks))
+	}
+
+	if weeks[0][4].Day != 0 || weeks[0][5].Day != 1 || weeks[0][5].Date != "2015-08-01" {
+		t.Errorf("The first day of August 2015 should be the Saturday of the first week but the first week is %v.", weeks[0])
+	}
+
+	if weeks[1][0].Day != 3 || weeks[1][0].NumberOfItems != 2 {
+		t.Errorf("The Monday of the second week should be the 3rd with 2 items but is %v.", weeks[1][0])
+	}
+
+	if weeks[5][0].Day != 31 || weeks[5][1].Day != 0 {
+		t.Errorf("The last week should only contain the 31st but is %v.", weeks[5])
+	}
+}
+
+func Test_getArchiveItems_ItemsWithAndWithoutDates_DatedItemsAreSortedByCreationDate(t *testing.T) {
+	// arrange
+	day := func(number int) time.Time { return time.Date(2015, 8, number, 12, 0, 0, 0, time.UTC) }
+	items := []*model.Item{
+		newTestItem("older", day(1), day(9)),
+		newTestItem("undated", time.Time{}, day(9)),
+		newTestItem("newer", day(5), time.Time{}),
+	}
+
+	// act
+	result := getArchiveItems(items)
+
+	// assert
+	if len(result) != 2 || result[0].Route().Value() != "newer" || result[1].Route().Value() != "older" {
+		t.Errorf("getArchiveItems should return the items [newer older] but returned %v.", result)
+	}
+}
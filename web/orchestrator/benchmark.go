@@ -0,0 +1,75 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/benchmark"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/orchestrator/index"
+	"github.com/andreaskoch/allmark/web/orchestrator/search"
+)
+
+// invalidItemType is the item type under which the items which cannot be parsed are measured.
+const invalidItemType = "invalid"
+
+// BenchmarkOrchestrator measures the parsing, the conversion and the search indexing of the items.
+type BenchmarkOrchestrator struct {
+	*Orchestrator
+}
+
+// Run parses, converts and indexes all items of the repository one after another (without the HTML cache)
+// and returns the duration and the memory allocations of every phase by item type.
+func (orchestrator *BenchmarkOrchestrator) Run() []*benchmark.Phase {
+
+	// parsing
+	parsing := benchmark.NewPhase("Parsing")
+	parsedItems := make([]*model.Item, 0)
+	for _, repositoryItem := range orchestrator.repository.Items() {
+		parsing.Measure(repositoryItem.Route().Value(), func() string {
+			parsedItem := orchestrator.parseItem(repositoryItem)
+			if parsedItem == nil {
+				return invalidItemType
+			}
+
+			parsedItems = append(parsedItems, parsedItem)
+			return parsedItem.Type.String()
+		})
+	}
+
+	// the conversion looks up the items which are referenced by alias in the index
+	orchestrator.repositoryIndex = index.New(orchestrator.logger)
+	for _, parsedItem := range parsedItems {
+		orchestrator.repositoryIndex.Add(parsedItem)
+	}
+
+	orchestrator.getItemByAlias("")
+
+	// conversion
+	conversion := benchmark.NewPhase("Conversion")
+	for _, parsedItem := range parsedItems {
+		conversion.Measure(parsedItem.Route().Value(), func() string {
+			pathProvider := orchestrator.relativePather(parsedItem.Route())
+			if _, err := orchestrator.converter.Convert(orchestrator.getItemByAlias, pathProvider, parsedItem); err != nil {
+				orchestrator.logger.Warn("Cannot convert content for route %q. Error: %s.", parsedItem.Route(), err.Error())
+			}
+
+			return parsedItem.Type.String()
+		})
+	}
+
+	// search indexing (in memory, so that all items are indexed again)
+	searchIndexing := benchmark.NewPhase("Search indexing")
+	fulltextIndex := search.NewItemSearch(orchestrator.logger, "", orchestrator.config.SearchLanguage(), getSearchRanking(orchestrator.config.Search.Ranking), orchestrator.getSearchSynonyms(), orchestrator.getTextExtractor(), nil)
+	defer fulltextIndex.Close()
+
+	for _, parsedItem := range parsedItems {
+		searchIndexing.Measure(parsedItem.Route().Value(), func() string {
+			fulltextIndex.Update(parsedItem)
+			return parsedItem.Type.String()
+		})
+	}
+
+	return []*benchmark.Phase{parsing, conversion, searchIndexing}
+}
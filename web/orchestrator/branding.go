@@ -0,0 +1,241 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/services/imageconversion"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// BrandingIconsPath is the path (relative to the base path) under which the generated icons are served.
+const BrandingIconsPath = "-/icons/"
+
+// brandingIcon is one of the favicons and touch icons which are generated from the icon source of the site.
+type brandingIcon struct {
+	Name  string // the file name (e.g. "favicon-32x32.png")
+	Rel   string // the link relation (e.g. "apple-touch-icon")
+	Sizes []uint // the edge lengths of the images of the icon in pixels
+}
+
+// brandingIcons contains all icons which are generated from the icon source of the site.
+var brandingIcons = []brandingIcon{
+	{Name: "favicon.ico", Rel: "icon", Sizes: []uint{16, 32, 48}},
+	{Name: "favicon-16x16.png", Rel: "icon", Sizes: []uint{16}},
+	{Name: "favicon-32x32.png", Rel: "icon", Sizes: []uint{32}},
+	{Name: "apple-touch-icon.png", Rel: "apple-touch-icon", Sizes: []uint{180}},
+	{Name: "icon-192x192.png", Rel: "icon", Sizes: []uint{192}},
+	{Name: "icon-512x512.png", Rel: "icon", Sizes: []uint{512}},
+}
+
+// MimeType returns the mime type of the icon ("image/x-icon" or "image/png").
+func (icon brandingIcon) MimeType() string {
+	if path.Ext(icon.Name) == ".ico" {
+		return "image/x-icon"
+	}
+
+	return "image/png"
+}
+
+// SizesAttribute returns the sizes of the icon in the format of the sizes attribute of link tags (e.g. "16x16 32x32").
+func (icon brandingIcon) SizesAttribute() string {
+	sizes := make([]string, 0, len(icon.Sizes))
+	for _, size := range icon.Sizes {
+		sizes = append(sizes, fmt.Sprintf("%dx%d", size, size))
+	}
+
+	return strings.Join(sizes, " ")
+}
+
+// BrandingOrchestrator provides the logo of the site and the favicons and touch icons which are generated
+// from the configured icon source.
+type BrandingOrchestrator struct {
+	*Orchestrator
+
+	// caches
+	branding *viewmodel.Branding
+
+	iconsLock sync.Mutex
+	icons     map[string][]byte // the generated icons by name
+}
+
+// GetBranding returns the logo and the icon links of the site. The logo and the icons are only returned if the
+// configured files exist; icons are only available for PNG and JPEG images.
+func (orchestrator *BrandingOrchestrator) GetBranding() viewmodel.Branding {
+
+	if orchestrator.branding == nil {
+
+		// updateBranding resolves the branding files and resets the generated icons.
+		updateBranding := func(route route.Route) {
+			branding := orchestrator.getBranding()
+			orchestrator.branding = &branding
+
+			orchestrator.iconsLock.Lock()
+			orchestrator.icons = make(map[string][]byte)
+			orchestrator.iconsLock.Unlock()
+		}
+
+		// register update callbacks
+		orchestrator.registerUpdateCallback("update branding", UpdateTypeNew, updateBranding)
+		orchestrator.registerUpdateCallback("update branding", UpdateTypeModified, updateBranding)
+		orchestrator.registerUpdateCallback("update branding", UpdateTypeDeleted, updateBranding)
+
+		// build the cache
+		updateBranding(route.New())
+	}
+
+	return *orchestrator.branding
+}
+
+// GetIcon returns the icon with the given name (e.g. "favicon-32x32.png") and its mime type. The icons are
+// generated from the icon source on first use.
+func (orchestrator *BrandingOrchestrator) GetIcon(name string) (data []byte, mimeType string, found bool) {
+
+	if len(orchestrator.GetBranding().Icons) == 0 {
+		return nil, "", false
+	}
+
+	icon, exists := getBrandingIcon(name)
+	if !exists {
+		return nil, "", false
+	}
+
+	orchestrator.iconsLock.Lock()
+	defer orchestrator.iconsLock.Unlock()
+
+	if data, exists := orchestrator.icons[icon.Name]; exists {
+		return data, icon.MimeType(), true
+	}
+
+	source := orchestrator.getIconSource()
+	if source == nil {
+		return nil, "", false
+	}
+
+	mimeType, err := model.GetMimeType(source)
+	if err != nil {
+		orchestrator.logger.Warn("Cannot determine the mime type of the icon source %q. Error: %s", source.Route(), err.Error())
+		return nil, "", false
+	}
+
+	err = source.Data(func(content io.ReadSeeker) error {
+		var iconErr error
+		data, iconErr = createBrandingIcon(icon, content, mimeType)
+		return iconErr
+	})
+
+	if err != nil {
+		orchestrator.logger.Warn("Cannot create the icon %q from %q. Error: %s", icon.Name, source.Route(), err.Error())
+		return nil, "", false
+	}
+
+	orchestrator.icons[icon.Name] = data
+	return data, icon.MimeType(), true
+}
+
+// getBranding returns the logo and the icon links of the site.
+func (orchestrator *BrandingOrchestrator) getBranding() viewmodel.Branding {
+	branding := viewmodel.Branding{}
+	brandingConfig := orchestrator.config.Web.Branding
+
+	// logo
+	if logo := strings.TrimSpace(brandingConfig.Logo); isExternalURL(logo) {
+		branding.Logo = logo
+	} else if logo != "" {
+		if file := orchestrator.getFile(route.NewFromRequest(logo)); file != nil {
+			branding.Logo = orchestrator.itemPather().Path(file.Route().Value())
+		} else {
+			orchestrator.logger.Warn("The logo %q was not found.", logo)
+		}
+	}
+
+	// icons
+	if source := orchestrator.getIconSource(); source != nil {
+		if mimeType, _ := model.GetMimeType(source); imageconversion.MimeTypeIsSupported(mimeType) {
+			branding.Icons = getBrandingIconLinks(orchestrator.basePath() + BrandingIconsPath)
+		} else {
+			orchestrator.logger.Warn("Cannot create icons from %q because it is no PNG or JPEG image.", source.Route())
+		}
+	}
+
+	return branding
+}
+
+// getIconSource returns the image from which the icons are generated or nil if none is configured.
+func (orchestrator *BrandingOrchestrator) getIconSource() *model.File {
+	source := orchestrator.config.Web.Branding.IconSource()
+	if source == "" || isExternalURL(source) {
+		return nil
+	}
+
+	file := orchestrator.getFile(route.NewFromRequest(source))
+	if file == nil {
+		orchestrator.logger.Warn("The icon source %q was not found.", source)
+	}
+
+	return file
+}
+
+// getBrandingIcon returns the icon with the given name.
+func getBrandingIcon(name string) (brandingIcon, bool) {
+	for _, icon := range brandingIcons {
+		if icon.Name == name {
+			return icon, true
+		}
+	}
+
+	return brandingIcon{}, false
+}
+
+// getBrandingIconLinks returns the links to all icons which are served under the given path (e.g. "/-/icons/").
+func getBrandingIconLinks(iconsPath string) []viewmodel.BrandingIcon {
+	links := make([]viewmodel.BrandingIcon, 0, len(brandingIcons))
+	for _, icon := range brandingIcons {
+		links = append(links, viewmodel.BrandingIcon{
+			Rel:   icon.Rel,
+			Type:  icon.MimeType(),
+			Sizes: icon.SizesAttribute(),
+			Path:  iconsPath + icon.Name,
+		})
+	}
+
+	return links
+}
+
+// createBrandingIcon creates the given icon from the source image with the given mime type.
+func createBrandingIcon(icon brandingIcon, source io.ReadSeeker, mimeType string) ([]byte, error) {
+	images := make([]imageconversion.IconImage, 0, len(icon.Sizes))
+	for _, size := range icon.Sizes {
+		if _, err := source.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		image := new(bytes.Buffer)
+		if err := imageconversion.ResizeToSquare(source, mimeType, size, image); err != nil {
+			return nil, err
+		}
+
+		images = append(images, imageconversion.IconImage{Size: size, Data: image.Bytes()})
+	}
+
+	if icon.MimeType() != "image/x-icon" {
+		return images[0].Data, nil
+	}
+
+	iconFile := new(bytes.Buffer)
+	if err := imageconversion.WriteIcon(iconFile, images); err != nil {
+		return nil, err
+	}
+
+	return iconFile.Bytes(), nil
+}
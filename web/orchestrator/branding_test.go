@@ -0,0 +1,83 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func newTestImage(t *testing.T, width, height int) *bytes.Reader {
+	buffer := new(bytes.Buffer)
+	if err := png.Encode(buffer, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("The test image could not be created. Error: %s", err)
+	}
+
+	return bytes.NewReader(buffer.Bytes())
+}
+
+func Test_createBrandingIcon_TouchIcon_SquarePNGIsCreated(t *testing.T) {
+	// arrange
+	icon, _ := getBrandingIcon("apple-touch-icon.png")
+	source := newTestImage(t, 100, 50)
+
+	// act
+	data, err := createBrandingIcon(icon, source, "image/png")
+
+	// assert
+	if err != nil {
+		t.Fatalf("createBrandingIcon returned an error: %s", err)
+	}
+
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("The icon is not a PNG image. Error: %s", err)
+	}
+
+	if config.Width != 180 || config.Height != 180 {
+		t.Errorf("The touch icon should be 180x180 pixels but was %dx%d.", config.Width, config.Height)
+	}
+}
+
+func Test_createBrandingIcon_Favicon_IconFileWithAllSizesIsCreated(t *testing.T) {
+	// arrange
+	icon, _ := getBrandingIcon("favicon.ico")
+	source := newTestImage(t, 64, 64)
+
+	// act
+	data, err := createBrandingIcon(icon, source, "image/png")
+
+	// assert
+	if err != nil {
+		t.Fatalf("createBrandingIcon returned an error: %s", err)
+	}
+
+	var header struct{ Reserved, Type, Count uint16 }
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		t.Fatalf("The icon file header could not be read. Error: %s", err)
+	}
+
+	if header.Type != 1 || int(header.Count) != len(icon.Sizes) {
+		t.Errorf("The icon file should contain %d images but the header was %+v.", len(icon.Sizes), header)
+	}
+}
+
+func Test_getBrandingIconLinks_IconsPath_LinksContainTheSizesAndTypes(t *testing.T) {
+	// act
+	links := getBrandingIconLinks("/wiki/-/icons/")
+
+	// assert
+	if len(links) != len(brandingIcons) {
+		t.Fatalf("There should be %d icon links but there were %d.", len(brandingIcons), len(links))
+	}
+
+	first := links[0]
+	if first.Path != "/wiki/-/icons/favicon.ico" || first.Type != "image/x-icon" || first.Sizes != "16x16 32x32 48x48" {
+		t.Errorf("The favicon link is not correct: %+v", first)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/paths"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type CalendarOrchestrator struct {
+	*Orchestrator
+}
+
+// GetCalendar returns the events of the item with the given route and of all items below it.
+// Items which must not be listed and items which cannot be shown with the given visibility are skipped.
+func (orchestrator *CalendarOrchestrator) GetCalendar(baseURL string, itemRoute route.Route, visibility Visibility) (viewmodel.Calendar, error) {
+
+	item := orchestrator.getItem(itemRoute)
+	if item == nil {
+		return viewmodel.Calendar{}, fmt.Errorf("No item found for route %q.", itemRoute.String())
+	}
+
+	pathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
+
+	candidates := append([]*model.Item{item}, orchestrator.index().GetAllChildren(item.Route(), func(child *model.Item) bool {
+		return !child.MetaData.NoIndex && visibility.IsVisible(child.Route())
+	})...)
+
+	events := make([]viewmodel.CalendarEvent, 0)
+	for _, candidate := range candidates {
+		if !candidate.MetaData.Event.IsSet() || candidate.MetaData.NoIndex {
+			continue
+		}
+
+		events = append(events, orchestrator.getCalendarEvent(pathProvider, candidate))
+	}
+
+	// the events are listed chronologically
+	sortCalendarEvents(events)
+
+	return viewmodel.Calendar{
+		Name:        item.Title,
+		Description: item.Description,
+		URL:         pathProvider.Path(item.Route().Value()),
+		Events:      events,
+	}, nil
+}
+
+// getCalendarEvent returns the calendar event for the given item. The location of the event defaults to
+// the address of the item.
+func (orchestrator *CalendarOrchestrator) getCalendarEvent(pathProvider paths.Pather, item *model.Item) viewmodel.CalendarEvent {
+
+	event := item.MetaData.Event
+	geoInformation := item.MetaData.GeoInformation
+
+	location := event.Location
+	if location == "" {
+		addressComponents := make([]string, 0)
+		for _, component := range []string{geoInformation.Street, geoInformation.City, geoInformation.Country} {
+			if component != "" {
+				addressComponents = append(addressComponents, component)
+			}
+		}
+
+		location = strings.Join(addressComponents, ", ")
+	}
+
+	uid := strings.TrimPrefix(getNameBasedUUID(fmt.Sprintf("event:%s/%s", orchestrator.config.Server.DomainName, item.Route().Value())), "urn:uuid:")
+
+	return viewmodel.CalendarEvent{
+		UID:         uid,
+		Summary:     item.Title,
+		Description: item.Description,
+		URL:         pathProvider.Path(item.Route().Value()),
+		Categories:  item.MetaData.Tags,
+
+		Start:  event.Start,
+		End:    event.End,
+		AllDay: event.AllDay,
+
+		Location:  location,
+		Latitude:  geoInformation.Latitude,
+		Longitude: geoInformation.Longitude,
+
+		Created:      item.MetaData.CreationDate,
+		LastModified: getFeedUpdateTime(item),
+	}
+}
+
+// sortCalendarEvents sorts the given events by their start (earliest first).
+func sortCalendarEvents(events []viewmodel.CalendarEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+}
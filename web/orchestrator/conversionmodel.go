@@ -6,10 +6,17 @@ package orchestrator
 
 import (
 	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 )
 
+// conversionLinkPattern matches the link targets in the converted content of an item.
+var conversionLinkPattern = regexp.MustCompile(`(\shref=")([^"]*)(")`)
+
 type ConversionModelOrchestrator struct {
 	*Orchestrator
 
@@ -32,10 +39,10 @@ func (orchestrator *ConversionModelOrchestrator) GetConversionModel(baseURL stri
 	}
 
 	// create the path provider
-	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s/", baseURL))
+	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
 
 	// convert content
-	convertedContent, err := orchestrator.converter.Convert(orchestrator.getItemByAlias, rootPathProvider, item)
+	convertedContent, err := orchestrator.convert(rootPathProvider, item)
 	if err != nil {
 		return model, false
 	}
@@ -43,11 +50,134 @@ func (orchestrator *ConversionModelOrchestrator) GetConversionModel(baseURL stri
 	// create a view model
 	model = viewmodel.ConversionModel{
 		Base:    getBaseModel(root, item, orchestrator.config),
-		Content: convertedContent,
+		Content: resolveConversionLinks(convertedContent, orchestrator.getItemURL(baseURL, route), nil),
 
 		// files
 		Files: orchestrator.fileOrchestrator.GetFiles(route),
+
+		Author: orchestrator.getAuthorInformation(item.MetaData.Author),
+	}
+
+	if coverImage := getPreviewImage(item); coverImage != nil {
+		model.CoverImage = coverImage.Route().Value()
 	}
 
 	return model, true
 }
+
+// GetSubtreeConversionModel returns the conversion model of the item with the given route which contains
+// all descendants of the item which can be shown with the given visibility as sections
+// (e.g. for printing a whole manual as one document).
+func (orchestrator *ConversionModelOrchestrator) GetSubtreeConversionModel(baseURL string, route route.Route, visibility Visibility) (model viewmodel.ConversionModel, found bool) {
+
+	model, found = orchestrator.GetConversionModel(baseURL, route)
+	if !found {
+		return model, false
+	}
+
+	root := orchestrator.rootItem()
+	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
+
+	// links to the descendants point to their sections
+	descendants := getDescendants(route, orchestrator.visibleChildren(visibility))
+	anchorsByURL := make(map[string]string, len(descendants))
+	for _, descendant := range descendants {
+		anchorsByURL[strings.TrimSuffix(orchestrator.getItemURL(baseURL, descendant.item.Route()), "/")] = getSectionAnchor(descendant.item.Route())
+	}
+
+	model.Content = resolveConversionLinks(model.Content, orchestrator.getItemURL(baseURL, route), anchorsByURL)
+
+	model.Sections = make([]viewmodel.ConversionSection, 0)
+	for _, descendant := range descendants {
+
+		convertedContent, err := orchestrator.convert(rootPathProvider, descendant.item)
+		if err != nil {
+			orchestrator.logger.Warn("Unable to convert item %q. Error: %s", descendant.item.String(), err.Error())
+			continue
+		}
+
+		model.Sections = append(model.Sections, viewmodel.ConversionSection{
+			Base:    getBaseModel(root, descendant.item, orchestrator.config),
+			Content: resolveConversionLinks(convertedContent, orchestrator.getItemURL(baseURL, descendant.item.Route()), anchorsByURL),
+			Depth:   descendant.depth,
+			Anchor:  getSectionAnchor(descendant.item.Route()),
+		})
+	}
+
+	return model, true
+}
+
+// descendant is an item below another item.
+type descendant struct {
+	item  *model.Item
+	depth int
+}
+
+// getDescendants returns all items below the item with the given route (depth-first, every item before its
+// children) using the given function to get the children of an item.
+func getDescendants(parentRoute route.Route, getChildren func(parentRoute route.Route) []*model.Item) []descendant {
+	descendants := make([]descendant, 0)
+
+	var addChildren func(parentRoute route.Route, depth int)
+	addChildren = func(parentRoute route.Route, depth int) {
+		for _, child := range getChildren(parentRoute) {
+			descendants = append(descendants, descendant{child, depth})
+			addChildren(child.Route(), depth+1)
+		}
+	}
+
+	addChildren(parentRoute, 1)
+	return descendants
+}
+
+// getSectionAnchor returns the id of the section of the item with the given route (e.g. "section-docs-sample").
+func getSectionAnchor(itemRoute route.Route) string {
+	return "section-" + strings.Replace(itemRoute.Value(), "/", "-", -1)
+}
+
+// getItemURL returns the absolute URL the links of the item with the given route are relative to (e.g. "http://example.com/docs/sample/").
+func (orchestrator *ConversionModelOrchestrator) getItemURL(baseURL string, itemRoute route.Route) string {
+	return baseURL + GetBaseURL(orchestrator.basePath(), itemRoute)
+}
+
+// resolveConversionLinks returns the given content with its relative links resolved against the given item URL,
+// because the converted content is not displayed under the URL of the item. Links to the items with the
+// given section anchors (by their URL) point to the sections instead (e.g. "#section-docs-sample").
+func resolveConversionLinks(content, itemURL string, anchorsByURL map[string]string) string {
+	baseURL, err := url.Parse(itemURL)
+	if err != nil {
+		return content
+	}
+
+	return conversionLinkPattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := conversionLinkPattern.FindStringSubmatch(match)
+		link := parts[2]
+		if link == "" || strings.HasPrefix(link, "#") {
+			return match
+		}
+
+		linkURL, err := url.Parse(link)
+		if err != nil {
+			return match
+		}
+
+		resolvedURL := baseURL.ResolveReference(linkURL)
+		if resolvedURL.Scheme != "http" && resolvedURL.Scheme != "https" {
+			return match
+		}
+
+		if resolvedURL.RawQuery == "" {
+			targetURL := *resolvedURL
+			targetURL.Fragment = ""
+			if anchor, isSection := anchorsByURL[strings.TrimSuffix(targetURL.String(), "/")]; isSection {
+				if resolvedURL.Fragment != "" {
+					anchor = resolvedURL.Fragment
+				}
+
+				return parts[1] + "#" + anchor + parts[3]
+			}
+		}
+
+		return parts[1] + resolvedURL.String() + parts[3]
+	})
+}
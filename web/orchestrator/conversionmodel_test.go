@@ -0,0 +1,98 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+)
+
+func Test_getDescendants_NestedItems_ItemsAreReturnedDepthFirst(t *testing.T) {
+	// arrange
+	children := map[string][]*model.Item{
+		"manual": {
+			newTestItem("manual/intro", time.Time{}, time.Time{}),
+			newTestItem("manual/setup", time.Time{}, time.Time{}),
+		},
+		"manual/intro": {
+			newTestItem("manual/intro/history", time.Time{}, time.Time{}),
+		},
+	}
+
+	getChildren := func(parentRoute route.Route) []*model.Item {
+		return children[parentRoute.Value()]
+	}
+
+	// act
+	descendants := getDescendants(route.NewFromRequest("manual"), getChildren)
+
+	// assert
+	expected := []struct {
+		route string
+		depth int
+	}{
+		{"manual/intro", 1},
+		{"manual/intro/history", 2},
+		{"manual/setup", 1},
+	}
+
+	if len(descendants) != len(expected) {
+		t.Fatalf("getDescendants should return %d items but returned %d.", len(expected), len(descendants))
+	}
+
+	for index, descendant := range descendants {
+		if descendant.item.Route().Value() != expected[index].route || descendant.depth != expected[index].depth {
+			t.Errorf("Descendant %d should be %q at depth %d but was %q at depth %d.", index, expected[index].route, expected[index].depth, descendant.item.Route().Value(), descendant.depth)
+		}
+	}
+}
+
+func Test_getSectionAnchor_NestedRoute_SlashesAreReplaced(t *testing.T) {
+	// arrange
+	itemRoute := route.NewFromRequest("manual/intro/history")
+
+	// act
+	anchor := getSectionAnchor(itemRoute)
+
+	// assert
+	if anchor != "section-manual-intro-history" {
+		t.Errorf("getSectionAnchor should return %q but returned %q.", "section-manual-intro-history", anchor)
+	}
+}
+
+func Test_resolveConversionLinks_RelativeLinks_LinksAreResolvedAgainstTheItem(t *testing.T) {
+	// arrange
+	content := `<a href="../other">Other</a> <a href="files/manual.pdf">Manual</a> <a href="#usage">Usage</a> <a href="mailto:info@example.com">Mail</a>`
+
+	// act
+	result := resolveConversionLinks(content, "http://example.com/docs/sample/", nil)
+
+	// assert
+	expected := `<a href="http://example.com/docs/other">Other</a> <a href="http://example.com/docs/sample/files/manual.pdf">Manual</a> <a href="#usage">Usage</a> <a href="mailto:info@example.com">Mail</a>`
+	if result != expected {
+		t.Errorf("resolveConversionLinks should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_resolveConversionLinks_LinksToSections_LinksPointToTheSections(t *testing.T) {
+	// arrange
+	content := `<a href="intro">Intro</a> <a href="/manual/setup/#proxy">Proxy</a> <a href="setup?page=2">Page 2</a>`
+	anchorsByURL := map[string]string{
+		"http://example.com/manual/intro": "section-manual-intro",
+		"http://example.com/manual/setup": "section-manual-setup",
+	}
+
+	// act
+	result := resolveConversionLinks(content, "http://example.com/manual/", anchorsByURL)
+
+	// assert
+	expected := `<a href="#section-manual-intro">Intro</a> <a href="#proxy">Proxy</a> <a href="http://example.com/manual/setup?page=2">Page 2</a>`
+	if result != expected {
+		t.Errorf("resolveConversionLinks should return %q but returned %q.", expected, result)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/route"
+)
+
+const (
+	// CustomStylesheetName is the name of the attachment which contains the custom styles of an item and its descendants.
+	CustomStylesheetName = "custom.css"
+
+	// CustomScriptName is the name of the attachment which contains the custom scripts of an item and its descendants.
+	CustomScriptName = "custom.js"
+)
+
+// getCustomAssets returns the URLs of the custom stylesheets and scripts which are attached to the item with the
+// given route or to one of its ancestors. The assets of the ancestors come first so the assets of the item can
+// override them.
+func (orchestrator *Orchestrator) getCustomAssets(itemRoute route.Route) (stylesheets, scripts []string) {
+
+	// collect the item and its ancestors
+	routes := []route.Route{itemRoute}
+	for parentRoute, exists := itemRoute.Parent(); exists; parentRoute, exists = parentRoute.Parent() {
+		routes = append([]route.Route{parentRoute}, routes...)
+	}
+
+	for _, ancestorRoute := range routes {
+		item := orchestrator.getItem(ancestorRoute)
+		if item == nil {
+			continue
+		}
+
+		for _, file := range item.Files() {
+			switch strings.ToLower(file.Route().LastComponentName()) {
+			case CustomStylesheetName:
+				stylesheets = append(stylesheets, orchestrator.itemPather().Path(file.Route().Value()))
+
+			case CustomScriptName:
+				scripts = append(scripts, orchestrator.itemPather().Path(file.Route().Value()))
+			}
+		}
+	}
+
+	return stylesheets, scripts
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/budget"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"runtime"
+	"time"
+)
+
+// GetDebugVars returns a snapshot of the runtime statistics (goroutines, heap), the durations of the
+// last index runs and the usage of the background task budget and the HTML cache.
+func (orchestrator *StatusOrchestrator) GetDebugVars() viewmodel.DebugVars {
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	budgetUsage := budget.Default.Usage()
+
+	debugVars := viewmodel.DebugVars{
+		Uptime:     time.Since(orchestrator.startTime).Truncate(time.Second).String(),
+		GoVersion:  runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		CPUs:       runtime.NumCPU(),
+
+		Memory: viewmodel.DebugMemory{
+			HeapAllocBytes:             memStats.HeapAlloc,
+			HeapInUseBytes:             memStats.HeapInuse,
+			HeapObjects:                memStats.HeapObjects,
+			SystemBytes:                memStats.Sys,
+			TotalAllocBytes:            memStats.TotalAlloc,
+			NumberOfGCs:                memStats.NumGC,
+			GCPauseTotalInMicroseconds: memStats.PauseTotalNs / uint64(time.Microsecond),
+		},
+
+		RepositoryIndex: getIndexRunViewModel(&orchestrator.lastIndexRun),
+		SearchIndex:     getIndexRunViewModel(&orchestrator.lastSearchIndexRun),
+
+		Budget: viewmodel.DebugBudget{
+			MemoryBytes:  budgetUsage.Memory,
+			Tasks:        budgetUsage.Tasks,
+			WaitingTasks: budgetUsage.Waiting,
+		},
+	}
+
+	if orchestrator.htmlCache != nil {
+		cacheStatistics := orchestrator.htmlCache.Statistics()
+		debugVars.HTMLCache = &viewmodel.DebugHTMLCache{
+			Entries:   cacheStatistics.Entries,
+			SizeBytes: cacheStatistics.Size,
+			Hits:      cacheStatistics.Hits,
+			Misses:    cacheStatistics.Misses,
+		}
+	}
+
+	return debugVars
+}
+
+// getIndexRunViewModel returns the duration and the date of the given index run.
+func getIndexRunViewModel(run *indexRun) viewmodel.StatsIndex {
+	duration, date := run.Get()
+
+	indexRunViewModel := viewmodel.StatsIndex{
+		DurationInMilliseconds: duration.Nanoseconds() / int64(time.Millisecond),
+	}
+
+	if !date.IsZero() {
+		indexRunViewModel.Date = date.Format(time.RFC3339)
+	}
+
+	return indexRunViewModel
+}
@@ -0,0 +1,153 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/content"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DownloadMarkdownFileName is the name of the markdown documents of the items in the ZIP downloads
+// (the name the repository uses for new items), so an extracted download is a repository of its own.
+const DownloadMarkdownFileName = "document.md"
+
+// DownloadPageFileName is the name of the rendered pages of the items in the ZIP downloads.
+const DownloadPageFileName = "index.html"
+
+// downloadLinkPattern matches the link targets and image sources of the rendered pages of a download.
+var downloadLinkPattern = regexp.MustCompile(`(\s(?:href|src)=")([^"]*)(")`)
+
+// DownloadOrchestrator provides the contents of the ZIP archives of items and their descendants.
+type DownloadOrchestrator struct {
+	*Orchestrator
+}
+
+// A DownloadItem is an item of a ZIP download.
+type DownloadItem struct {
+	Route        route.Route
+	Title        string
+	LastModified time.Time
+
+	// Folder is the slash-separated path of the folder of the item in the archive ("" for the requested item).
+	Folder string
+
+	// Markdown is the markdown document of the item; virtual items and file collections have none.
+	Markdown string
+
+	Files []DownloadFile
+}
+
+// A DownloadFile is a file which is attached to an item of a ZIP download.
+type DownloadFile struct {
+	content.ContentProviderInterface
+
+	Route route.Route
+
+	// Path is the slash-separated path of the file in the archive (e.g. "sample/files/image.png").
+	Path string
+}
+
+// GetDownloadItems returns the item with the given route and all of its descendants which can be shown with the
+// given visibility (every item before its children).
+func (orchestrator *DownloadOrchestrator) GetDownloadItems(itemRoute route.Route, visibility Visibility) (items []DownloadItem, found bool) {
+	item := orchestrator.getItem(itemRoute)
+	if item == nil {
+		orchestrator.logger.Info("There was no item for route %q.", itemRoute)
+		return nil, false
+	}
+
+	items = []DownloadItem{getDownloadItem(itemRoute, item)}
+	for _, descendant := range getDescendants(itemRoute, orchestrator.visibleChildren(visibility)) {
+		items = append(items, getDownloadItem(itemRoute, descendant.item))
+	}
+
+	return items, true
+}
+
+// GetRelativeDownloadPage returns the given rendered page of the download item in the given folder with the
+// links to the items and files of the download replaced by relative links to the pages and files in the archive.
+// Links to all other pages remain absolute URLs below the given base URL.
+func (orchestrator *DownloadOrchestrator) GetRelativeDownloadPage(baseURL string, items []DownloadItem, folder, page string) string {
+	pathsByURL := make(map[string]string)
+	for _, item := range items {
+		itemURL := baseURL + GetBaseURL(orchestrator.basePath(), item.Route)
+		pathsByURL[strings.TrimSuffix(itemURL, "/")] = path.Join(item.Folder, DownloadPageFileName)
+
+		for _, file := range item.Files {
+			pathsByURL[baseURL+orchestrator.basePath()+file.Route.Value()] = file.Path
+		}
+	}
+
+	return rewriteDownloadLinks(page, folder, pathsByURL)
+}
+
+// getDownloadItem returns the download item of the given item below the item with the given root route.
+func getDownloadItem(rootRoute route.Route, item *model.Item) DownloadItem {
+	downloadItem := DownloadItem{
+		Route:        item.Route(),
+		Title:        item.Title,
+		LastModified: item.MetaData.LastModifiedDate,
+		Folder:       getDownloadPath(rootRoute, item.Route()),
+		Files:        make([]DownloadFile, 0, len(item.Files())),
+	}
+
+	if item.IsPhysical() {
+		downloadItem.Markdown = item.Markdown
+	}
+
+	for _, file := range item.Files() {
+		downloadItem.Files = append(downloadItem.Files, DownloadFile{file, file.Route(), getDownloadPath(rootRoute, file.Route())})
+	}
+
+	return downloadItem
+}
+
+// getDownloadPath returns the path of the item or file with the given route in the archive of the item with the given root route.
+func getDownloadPath(rootRoute, itemRoute route.Route) string {
+	return strings.Trim(strings.TrimPrefix(itemRoute.OriginalValue(), rootRoute.OriginalValue()), "/")
+}
+
+// rewriteDownloadLinks replaces the links of the given page in the given folder which point to the given URLs
+// with relative links to the respective paths in the archive.
+func rewriteDownloadLinks(page, folder string, pathsByURL map[string]string) string {
+	return downloadLinkPattern.ReplaceAllStringFunc(page, func(match string) string {
+		parts := downloadLinkPattern.FindStringSubmatch(match)
+
+		linkURL, err := url.Parse(parts[2])
+		if err != nil || !linkURL.IsAbs() || linkURL.RawQuery != "" {
+			return match
+		}
+
+		fragment := linkURL.Fragment
+		linkURL.Fragment = ""
+
+		targetPath, isDownloaded := pathsByURL[strings.TrimSuffix(linkURL.String(), "/")]
+		if !isDownloaded {
+			return match
+		}
+
+		link := getRelativeDownloadLink(folder, targetPath)
+		if fragment != "" {
+			link += "#" + fragment
+		}
+
+		return parts[1] + link + parts[3]
+	})
+}
+
+// getRelativeDownloadLink returns the relative link from a page in the given folder to the file with the given path.
+func getRelativeDownloadLink(fromFolder, toPath string) string {
+	if fromFolder == "" {
+		return toPath
+	}
+
+	return strings.Repeat("../", strings.Count(fromFolder, "/")+1) + toPath
+}
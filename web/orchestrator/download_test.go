@@ -0,0 +1,87 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"testing"
+)
+
+func Test_getDownloadPath_Descendant_PathIsRelativeToTheRoot(t *testing.T) {
+	// arrange
+	rootRoute := route.NewFromRequest("docs")
+	fileRoute := route.NewFromRequest("docs/sample/files/image.png")
+
+	// act
+	result := getDownloadPath(rootRoute, fileRoute)
+
+	// assert
+	expected := "sample/files/image.png"
+	if result != expected {
+		t.Errorf("The download path should be %q but was %q.", expected, result)
+	}
+}
+
+func Test_getDownloadPath_RootItem_PathIsEmpty(t *testing.T) {
+	// arrange
+	rootRoute := route.NewFromRequest("docs")
+
+	// act
+	result := getDownloadPath(rootRoute, rootRoute)
+
+	// assert
+	if result != "" {
+		t.Errorf("The download path of the root item should be empty but was %q.", result)
+	}
+}
+
+func Test_rewriteDownloadLinks_LinksToDownloadedItemsAndFiles_LinksAreRelative(t *testing.T) {
+	// arrange
+	page := `<a href="http://example.com/docs/">Docs</a> <a href="http://example.com/docs/other/#intro">Other</a> <img src="http://example.com/docs/sample/files/image.png">`
+	pathsByURL := map[string]string{
+		"http://example.com/docs":                        "index.html",
+		"http://example.com/docs/other":                  "other/index.html",
+		"http://example.com/docs/sample/files/image.png": "sample/files/image.png",
+	}
+
+	// act
+	result := rewriteDownloadLinks(page, "sample", pathsByURL)
+
+	// assert
+	expected := `<a href="../index.html">Docs</a> <a href="../other/index.html#intro">Other</a> <img src="../sample/files/image.png">`
+	if result != expected {
+		t.Errorf("The result should be %q but was %q.", expected, result)
+	}
+}
+
+func Test_rewriteDownloadLinks_LinksToOtherPages_LinksAreNotChanged(t *testing.T) {
+	// arrange
+	page := `<a href="http://example.com/blog/">Blog</a> <a href="http://example.com/docs/?subtree=true">Docs</a> <a href="#top">Top</a>`
+	pathsByURL := map[string]string{
+		"http://example.com/docs": "index.html",
+	}
+
+	// act
+	result := rewriteDownloadLinks(page, "", pathsByURL)
+
+	// assert
+	if result != page {
+		t.Errorf("The links to pages which are not downloaded should not be changed but the result was %q.", result)
+	}
+}
+
+func Test_getRelativeDownloadLink_NestedFolder_LinkLeadsToTheRoot(t *testing.T) {
+	// arrange
+	fromFolder := "sample/nested"
+
+	// act
+	result := getRelativeDownloadLink(fromFolder, "index.html")
+
+	// assert
+	expected := "../../index.html"
+	if result != expected {
+		t.Errorf("The relative link should be %q but was %q.", expected, result)
+	}
+}
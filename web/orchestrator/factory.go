@@ -10,12 +10,15 @@ import (
 	"github.com/andreaskoch/allmark/dataaccess"
 	"github.com/andreaskoch/allmark/services/converter"
 	"github.com/andreaskoch/allmark/services/parser"
+	"github.com/andreaskoch/allmark/services/thumbnail"
+	"github.com/andreaskoch/allmark/web/orchestrator/htmlcache"
 	"github.com/andreaskoch/allmark/web/webpaths"
 )
 
-func NewFactory(logger logger.Logger, config config.Config, repository dataaccess.Repository, parser parser.Parser, converter converter.Converter, webPathProvider webpaths.WebPathProvider) *Factory {
+// NewFactory creates a new orchestrator factory. The thumbnail index and the HTML cache are optional.
+func NewFactory(logger logger.Logger, config config.Config, repository dataaccess.Repository, parser parser.Parser, converter converter.Converter, webPathProvider webpaths.WebPathProvider, thumbnailIndex *thumbnail.Index, htmlCache *htmlcache.Cache) *Factory {
 
-	baseOrchestrator := newBaseOrchestrator(logger, config, repository, parser, converter, webPathProvider)
+	baseOrchestrator := newBaseOrchestrator(logger, config, repository, parser, converter, webPathProvider, thumbnailIndex, htmlCache)
 
 	// listen for updates
 	repositoryUpdates := make(chan dataaccess.Update, 1)
@@ -40,19 +43,69 @@ type Factory struct {
 
 	baseOrchestrator *Orchestrator
 
+	apiOrchestrator                   *APIOrchestrator
+	benchmarkOrchestrator             *BenchmarkOrchestrator
+	brandingOrchestrator              *BrandingOrchestrator
+	calendarOrchestrator              *CalendarOrchestrator
 	viewModelOrchestrator             *ViewModelOrchestrator
 	conversionModelOrchestrator       *ConversionModelOrchestrator
+	downloadOrchestrator              *DownloadOrchestrator
 	feedOrchestrator                  *FeedOrchestrator
 	fileOrchestrator                  *FileOrchestrator
 	navigationOrchestrator            *NavigationOrchestrator
 	openSearchDescriptionOrchestrator *OpenSearchDescriptionOrchestrator
+	opmlOrchestrator                  *OPMLOrchestrator
 	searchOrchestrator                *SearchOrchestrator
+	statusOrchestrator                *StatusOrchestrator
+	statsOrchestrator                 *StatsOrchestrator
 	sitemapOrchestrator               *SitemapOrchestrator
 	tagsOrchestrator                  *TagsOrchestrator
 	xmlSitemapOrchestrator            *XmlSitemapOrchestrator
 	typeAheadOrchestrator             *TypeAheadOrchestrator
 	titlesOrchestrator                *TitlesOrchestrator
+	searchIndexOrchestrator           *SearchIndexOrchestrator
 	updateOrchestrator                *UpdateOrchestrator
+	webAppOrchestrator                *WebAppOrchestrator
+}
+
+func (factory *Factory) NewAPIOrchestrator() *APIOrchestrator {
+
+	if factory.apiOrchestrator != nil {
+		return factory.apiOrchestrator
+	}
+
+	factory.apiOrchestrator = &APIOrchestrator{
+		Orchestrator:          factory.baseOrchestrator,
+		viewModelOrchestrator: factory.NewViewModelOrchestrator(),
+	}
+
+	return factory.apiOrchestrator
+}
+
+func (factory *Factory) NewBrandingOrchestrator() *BrandingOrchestrator {
+
+	if factory.brandingOrchestrator != nil {
+		return factory.brandingOrchestrator
+	}
+
+	factory.brandingOrchestrator = &BrandingOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.brandingOrchestrator
+}
+
+func (factory *Factory) NewCalendarOrchestrator() *CalendarOrchestrator {
+
+	if factory.calendarOrchestrator != nil {
+		return factory.calendarOrchestrator
+	}
+
+	factory.calendarOrchestrator = &CalendarOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.calendarOrchestrator
 }
 
 func (factory *Factory) NewConversionModelOrchestrator() *ConversionModelOrchestrator {
@@ -69,6 +122,19 @@ func (factory *Factory) NewConversionModelOrchestrator() *ConversionModelOrchest
 	return factory.conversionModelOrchestrator
 }
 
+func (factory *Factory) NewDownloadOrchestrator() *DownloadOrchestrator {
+
+	if factory.downloadOrchestrator != nil {
+		return factory.downloadOrchestrator
+	}
+
+	factory.downloadOrchestrator = &DownloadOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.downloadOrchestrator
+}
+
 func (factory *Factory) NewFeedOrchestrator() *FeedOrchestrator {
 	if factory.feedOrchestrator != nil {
 		return factory.feedOrchestrator
@@ -120,6 +186,19 @@ func (factory *Factory) NewOpenSearchDescriptionOrchestrator() *OpenSearchDescri
 	return factory.openSearchDescriptionOrchestrator
 }
 
+func (factory *Factory) NewOPMLOrchestrator() *OPMLOrchestrator {
+
+	if factory.opmlOrchestrator != nil {
+		return factory.opmlOrchestrator
+	}
+
+	factory.opmlOrchestrator = &OPMLOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.opmlOrchestrator
+}
+
 func (factory *Factory) NewSearchOrchestrator() *SearchOrchestrator {
 	if factory.searchOrchestrator != nil {
 		return factory.searchOrchestrator
@@ -179,14 +258,16 @@ func (factory *Factory) NewViewModelOrchestrator() *ViewModelOrchestrator {
 	return factory.viewModelOrchestrator
 }
 
-func (factory *Factory) NewXMLSitemapOrchestrator() *XmlSitemapOrchestrator {
+// NewXMLSitemapOrchestrator returns the XML sitemap orchestrator. The thumbnail index is optional.
+func (factory *Factory) NewXMLSitemapOrchestrator(thumbnailIndex *thumbnail.Index) *XmlSitemapOrchestrator {
 
 	if factory.xmlSitemapOrchestrator != nil {
 		return factory.xmlSitemapOrchestrator
 	}
 
 	factory.xmlSitemapOrchestrator = &XmlSitemapOrchestrator{
-		Orchestrator: factory.baseOrchestrator,
+		Orchestrator:   factory.baseOrchestrator,
+		thumbnailIndex: thumbnailIndex,
 	}
 
 	return factory.xmlSitemapOrchestrator
@@ -205,6 +286,18 @@ func (factory *Factory) NewTypeAheadOrchestrator() *TypeAheadOrchestrator {
 	return factory.typeAheadOrchestrator
 }
 
+// NewStatusOrchestrator returns the status orchestrator. The thumbnail conversion service is optional.
+func (factory *Factory) NewStatusOrchestrator(thumbnailConversion *thumbnail.ConversionService) *StatusOrchestrator {
+
+	if factory.statusOrchestrator != nil {
+		return factory.statusOrchestrator
+	}
+
+	factory.statusOrchestrator = newStatusOrchestrator(factory.baseOrchestrator, thumbnailConversion)
+
+	return factory.statusOrchestrator
+}
+
 func (factory *Factory) NewTitlesOrchestrator() *TitlesOrchestrator {
 
 	if factory.titlesOrchestrator != nil {
@@ -218,6 +311,19 @@ func (factory *Factory) NewTitlesOrchestrator() *TitlesOrchestrator {
 	return factory.titlesOrchestrator
 }
 
+func (factory *Factory) NewSearchIndexOrchestrator() *SearchIndexOrchestrator {
+
+	if factory.searchIndexOrchestrator != nil {
+		return factory.searchIndexOrchestrator
+	}
+
+	factory.searchIndexOrchestrator = &SearchIndexOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.searchIndexOrchestrator
+}
+
 func (factory *Factory) NewUpdateOrchestrator() *UpdateOrchestrator {
 	if factory.updateOrchestrator != nil {
 		return factory.updateOrchestrator
@@ -231,9 +337,65 @@ func (factory *Factory) NewUpdateOrchestrator() *UpdateOrchestrator {
 	return factory.updateOrchestrator
 }
 
+// NewRecentChangesOrchestrator creates a new recent-changes orchestrator.
+func (factory *Factory) NewRecentChangesOrchestrator() *RecentChangesOrchestrator {
+	return &RecentChangesOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+}
+
+// NewArchiveOrchestrator creates a new archive orchestrator.
+func (factory *Factory) NewArchiveOrchestrator() *ArchiveOrchestrator {
+	return &ArchiveOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+}
+
+// NewBenchmarkOrchestrator creates a new benchmark orchestrator.
+func (factory *Factory) NewBenchmarkOrchestrator() *BenchmarkOrchestrator {
+
+	if factory.benchmarkOrchestrator != nil {
+		return factory.benchmarkOrchestrator
+	}
+
+	factory.benchmarkOrchestrator = &BenchmarkOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.benchmarkOrchestrator
+}
+
+// NewStatsOrchestrator creates a new stats orchestrator.
+func (factory *Factory) NewStatsOrchestrator() *StatsOrchestrator {
+
+	if factory.statsOrchestrator != nil {
+		return factory.statsOrchestrator
+	}
+
+	factory.statsOrchestrator = &StatsOrchestrator{
+		Orchestrator: factory.baseOrchestrator,
+	}
+
+	return factory.statsOrchestrator
+}
+
 // NewAliasIndexOrchestrator creates a new alias-index orchestrator.
 func (factory *Factory) NewAliasIndexOrchestrator() *AliasIndexOrchestrator {
 	return &AliasIndexOrchestrator{
 		Orchestrator: factory.baseOrchestrator,
 	}
 }
+
+func (factory *Factory) NewWebAppOrchestrator() *WebAppOrchestrator {
+
+	if factory.webAppOrchestrator != nil {
+		return factory.webAppOrchestrator
+	}
+
+	factory.webAppOrchestrator = &WebAppOrchestrator{
+		Orchestrator:         factory.baseOrchestrator,
+		brandingOrchestrator: factory.NewBrandingOrchestrator(),
+	}
+
+	return factory.webAppOrchestrator
+}
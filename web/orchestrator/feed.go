@@ -5,80 +5,221 @@
 package orchestrator
 
 import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/paths"
+	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/model"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"github.com/andreaskoch/allmark/web/webpaths"
+	"crypto/sha1"
 	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
+// feedLinkPattern matches the src and href attributes of HTML elements.
+var feedLinkPattern = regexp.MustCompile(`(src|href)="([^"]*)"`)
+
 // A FeedOrchestrator provides feed models.
 type FeedOrchestrator struct {
 	*Orchestrator
 }
 
-// GetFeed returns a feed model for the given base URL, items per page and page.
-func (orchestrator *FeedOrchestrator) GetFeed(baseURL string, itemsPerPage, page int) (viewmodel.Feed, error) {
-	root, err := orchestrator.getRootEntry(baseURL)
-	if err != nil {
-		return viewmodel.Feed{}, err
+// GetFeed returns a feed model for the latest items below the item with the given route which can be shown
+// with the given visibility. If a tag is given only the items with this tag are included.
+func (orchestrator *FeedOrchestrator) GetFeed(baseURL string, itemRoute route.Route, tag string, itemsPerPage, page int, visibility Visibility) (viewmodel.Feed, error) {
+
+	// validate page number
+	if page < 1 {
+		return viewmodel.Feed{}, fmt.Errorf("Invalid page number: %v.", page)
 	}
 
-	items, err := orchestrator.getItems(baseURL, itemsPerPage, page)
+	item, latestItems, err := orchestrator.getFeedItems(itemRoute, tag)
 	if err != nil {
 		return viewmodel.Feed{}, err
 	}
 
+	latestItems = visibleItems(latestItems, visibility)
+
+	pageItems, found := pagedItems(latestItems, itemsPerPage, page)
+	if !found {
+		return viewmodel.Feed{}, fmt.Errorf("No items found (Items per page: %v, Page: %v)", itemsPerPage, page)
+	}
+
+	var feedEntries []viewmodel.FeedEntry
+	for _, pageItem := range pageItems {
+		feedEntries = append(feedEntries, orchestrator.createFeedEntryModel(baseURL, pageItem))
+	}
+
 	feedModel := viewmodel.Feed{}
-	feedModel.FeedEntry = root
-	feedModel.Items = items
+	feedModel.FeedEntry = orchestrator.createFeedEntryModel(baseURL, item)
+	feedModel.Title = getFeedTitle(item, tag)
+	feedModel.Items = feedEntries
 
 	return feedModel, nil
 }
 
-func (orchestrator *FeedOrchestrator) getRootEntry(baseURL string) (viewmodel.FeedEntry, error) {
-
+// GetRecentChangesFeed returns a feed model with the given title for at most limit of the most recently modified items
+// of the repository which can be shown with the given visibility. The publication dates of the entries are the modification dates of the items.
+func (orchestrator *FeedOrchestrator) GetRecentChangesFeed(baseURL, title string, limit int, visibility Visibility) (viewmodel.Feed, error) {
 	rootItem := orchestrator.rootItem()
 	if rootItem == nil {
-		return viewmodel.FeedEntry{}, fmt.Errorf("No root item found.")
+		return viewmodel.Feed{}, fmt.Errorf("No root item found.")
+	}
+
+	var feedEntries []viewmodel.FeedEntry
+	for _, item := range getRecentlyModifiedItems(visibleItems(orchestrator.getAllItems(), visibility), limit) {
+		feedEntry := orchestrator.createFeedEntryModel(baseURL, item)
+		feedEntry.PubDate = getFeedUpdateTime(item).Format("2006-01-02")
+		feedEntries = append(feedEntries, feedEntry)
 	}
 
-	return orchestrator.createFeedEntryModel(baseURL, rootItem), nil
+	feedModel := viewmodel.Feed{}
+	feedModel.FeedEntry = orchestrator.createFeedEntryModel(baseURL, rootItem)
+	feedModel.Title = title
+	feedModel.Items = feedEntries
+
+	return feedModel, nil
 }
 
-func (orchestrator *FeedOrchestrator) getItems(baseURL string, itemsPerPage, page int) ([]viewmodel.FeedEntry, error) {
+// getFeedItems returns the item with the given route and the latest items below it.
+// Items with the noindex flag are skipped and if a tag is given only the items with this tag are returned.
+func (orchestrator *FeedOrchestrator) getFeedItems(itemRoute route.Route, tag string) (*model.Item, []*model.Item, error) {
+	item := orchestrator.getItem(itemRoute)
+	if item == nil {
+		return nil, nil, fmt.Errorf("No item found for route %q.", itemRoute.String())
+	}
 
-	// validate page number
-	if page < 1 {
-		return []viewmodel.FeedEntry{}, fmt.Errorf("Invalid page number: %v.", page)
+	latestItems := orchestrator.getLatestItems(item.Route())
+	sortFeedItems(latestItems, orchestrator.config.Web.Feeds.SortOrder())
+
+	feedItems := make([]*model.Item, 0, len(latestItems))
+	for _, latestItem := range latestItems {
+
+		// skip items which must not be listed
+		if latestItem.MetaData.NoIndex {
+			continue
+		}
+
+		if tag != "" && !containsIgnoringCase(latestItem.MetaData.Tags, tag) {
+			continue
+		}
+
+		feedItems = append(feedItems, latestItem)
 	}
 
-	rootItem := orchestrator.rootItem()
-	if rootItem == nil {
-		return []viewmodel.FeedEntry{}, fmt.Errorf("No root item found.")
+	return item, feedItems, nil
+}
+
+// sortFeedItems sorts the given items in the given sort order (see config.Feeds.SortOrder).
+func sortFeedItems(items []*model.Item, sortOrder string) {
+	switch sortOrder {
+	case config.FeedSortByModified:
+		model.SortItemsBy(func(item1, item2 *model.Item) bool {
+			return getFeedUpdateTime(item1).After(getFeedUpdateTime(item2))
+		}).Sort(items)
+
+	case config.FeedSortByTitle:
+		model.SortItemsBy(func(item1, item2 *model.Item) bool {
+			return strings.ToLower(item1.Title) < strings.ToLower(item2.Title)
+		}).Sort(items)
+
+	default:
+		model.SortItemsBy(sortItemsByDate).Sort(items)
 	}
+}
 
-	var feedEntries []viewmodel.FeedEntry
+// getFeedTitle returns the title of the feed for the given item and tag.
+func getFeedTitle(item *model.Item, tag string) string {
+	if tag == "" {
+		return item.Title
+	}
 
-	latestItems, found := pagedItems(orchestrator.getLatestItems(rootItem.Route()), itemsPerPage, page)
-	if !found {
-		return []viewmodel.FeedEntry{}, fmt.Errorf("No items found (Items per page: %v, Page: %v)", itemsPerPage, page)
+	return fmt.Sprintf("%s: %s", item.Title, tag)
+}
+
+// getFeedPageURL returns the URL of the given page of the feed with the given URL and tag.
+func getFeedPageURL(feedURL, tag string, page int) string {
+	parameters := url.Values{}
+	if tag != "" {
+		parameters.Set("tag", tag)
 	}
 
-	for _, item := range latestItems {
-		feedEntries = append(feedEntries, orchestrator.createFeedEntryModel(baseURL, item))
+	if page > 1 {
+		parameters.Set("page", fmt.Sprintf("%d", page))
 	}
 
-	return feedEntries, nil
+	if len(parameters) == 0 {
+		return feedURL
+	}
+
+	return feedURL + "?" + parameters.Encode()
 }
 
 func (orchestrator *FeedOrchestrator) createFeedEntryModel(baseURL string, item *model.Item) viewmodel.FeedEntry {
 
-	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s/", baseURL))
+	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
 
 	// item location
 	location := rootPathProvider.Path(item.Route().Value())
 
 	// content
-	content, err := orchestrator.converter.Convert(orchestrator.getItemByAlias, rootPathProvider, item)
+	content := item.Description
+	if orchestrator.config.Web.Feeds.RSS.FullContent() {
+		content = orchestrator.getFeedContent(rootPathProvider, item)
+	}
+
+	// creation date
+	creationDate := item.MetaData.CreationDate.Format("2006-01-02")
+
+	feedEntry := viewmodel.FeedEntry{
+		Title:       item.Title,
+		Description: content,
+		Link:        location,
+		PubDate:     creationDate,
+	}
+
+	if orchestrator.config.Web.Feeds.RSS.Enclosures {
+		feedEntry.Enclosure = orchestrator.getFeedEnclosure(rootPathProvider, item)
+	}
+
+	return feedEntry
+}
+
+// getFeedEnclosure returns the first audio file of the given item; or the first video or image file if the item
+// has no audio files. If the item has none of these files nil is returned.
+func (orchestrator *FeedOrchestrator) getFeedEnclosure(rootPathProvider paths.Pather, item *model.Item) *viewmodel.FeedEnclosure {
+
+	for _, isEnclosure := range []func(file *model.File) bool{model.IsAudioFile, model.IsVideoFile, model.IsImageFile} {
+		for _, file := range item.Files() {
+			if !isEnclosure(file) {
+				continue
+			}
+
+			attachment, err := getJSONFeedAttachment(rootPathProvider, file)
+			if err != nil {
+				orchestrator.logger.Warn(err.Error())
+				continue
+			}
+
+			return &viewmodel.FeedEnclosure{
+				URL:    attachment.URL,
+				Length: attachment.SizeInBytes,
+				Type:   attachment.MimeType,
+			}
+		}
+	}
+
+	return nil
+}
+
+// getFeedContent returns the HTML of the given item with the description as the first paragraph.
+// All links of the HTML are absolute so feed readers can resolve them.
+func (orchestrator *FeedOrchestrator) getFeedContent(rootPathProvider paths.Pather, item *model.Item) string {
+	content, err := orchestrator.convert(rootPathProvider, item)
 	if err != nil {
 		content = err.Error()
 	}
@@ -88,13 +229,281 @@ func (orchestrator *FeedOrchestrator) createFeedEntryModel(baseURL string, item
 		content = fmt.Sprintf("<p>%s</p>\n\n%s", item.Description, content)
 	}
 
-	// creation date
-	creationDate := item.MetaData.CreationDate.Format("2006-01-02")
+	return getAbsoluteLinks(content, rootPathProvider.Path(item.Route().Value()))
+}
 
-	return viewmodel.FeedEntry{
-		Title:       item.Title,
-		Description: content,
-		Link:        location,
-		PubDate:     creationDate,
+// getAbsoluteLinks resolves the relative src and href attributes of the given HTML against the given item URL.
+func getAbsoluteLinks(html, itemURL string) string {
+	baseURL, err := url.Parse(itemURL)
+	if err != nil {
+		return html
+	}
+
+	return feedLinkPattern.ReplaceAllStringFunc(html, func(attribute string) string {
+		matches := feedLinkPattern.FindStringSubmatch(attribute)
+		link := strings.TrimSpace(matches[2])
+
+		if webpaths.IsAbsoluteURI(link) {
+			return attribute
+		}
+
+		linkURL, err := url.Parse(link)
+		if err != nil {
+			return attribute
+		}
+
+		return fmt.Sprintf(`%s="%s"`, matches[1], baseURL.ResolveReference(linkURL).String())
+	})
+}
+
+// GetJSONFeed returns a JSON Feed model for the given base URL, items per page and page with the items which can be
+// shown with the given visibility. If a tag is given only the items with this tag are included.
+func (orchestrator *FeedOrchestrator) GetJSONFeed(baseURL, feedRoute, tag string, itemsPerPage, page int, visibility Visibility) (viewmodel.JSONFeed, error) {
+
+	// validate page number
+	if page < 1 {
+		return viewmodel.JSONFeed{}, fmt.Errorf("Invalid page number: %v.", page)
+	}
+
+	rootItem, latestItems, err := orchestrator.getFeedItems(route.New(), tag)
+	if err != nil {
+		return viewmodel.JSONFeed{}, err
+	}
+
+	latestItems = visibleItems(latestItems, visibility)
+
+	pageItems, found := pagedItems(latestItems, itemsPerPage, page)
+	if !found {
+		return viewmodel.JSONFeed{}, fmt.Errorf("No items found (Items per page: %v, Page: %v)", itemsPerPage, page)
 	}
+
+	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
+	feedURL := rootPathProvider.Path(feedRoute)
+
+	feed := viewmodel.JSONFeed{
+		Version:     viewmodel.JSONFeedVersion,
+		Title:       getFeedTitle(rootItem, tag),
+		HomePageURL: rootPathProvider.Path(rootItem.Route().Value()),
+		FeedURL:     getFeedPageURL(feedURL, tag, 1),
+		Description: rootItem.Description,
+		Favicon:     rootPathProvider.Path("theme/favicon.ico"),
+		Language:    getLanguageCode(rootItem.MetaData.Language),
+		Authors:     getJSONFeedAuthors(rootItem),
+		Items:       make([]viewmodel.JSONFeedItem, 0, len(pageItems)),
+	}
+
+	// link the next page
+	if _, hasNextPage := pagedItems(latestItems, itemsPerPage, page+1); hasNextPage {
+		feed.NextURL = getFeedPageURL(feedURL, tag, page+1)
+	}
+
+	for _, item := range pageItems {
+		feed.Items = append(feed.Items, orchestrator.createJSONFeedItem(rootPathProvider, item))
+	}
+
+	return feed, nil
+}
+
+func (orchestrator *FeedOrchestrator) createJSONFeedItem(rootPathProvider paths.Pather, item *model.Item) viewmodel.JSONFeedItem {
+
+	// the absolute URL of an item is its permanent identifier
+	location := rootPathProvider.Path(item.Route().Value())
+
+	feedItem := viewmodel.JSONFeedItem{
+		ID:            location,
+		URL:           location,
+		Title:         item.Title,
+		ContentHTML:   orchestrator.getFeedContent(rootPathProvider, item),
+		Summary:       item.Description,
+		DatePublished: getAPITime(item.MetaData.CreationDate),
+		DateModified:  getAPITime(item.MetaData.LastModifiedDate),
+		Authors:       getJSONFeedAuthors(item),
+		Tags:          item.MetaData.Tags,
+		Language:      getLanguageCode(item.MetaData.Language),
+	}
+
+	for _, file := range item.Files() {
+		attachment, err := getJSONFeedAttachment(rootPathProvider, file)
+		if err != nil {
+			orchestrator.logger.Warn(err.Error())
+			continue
+		}
+
+		feedItem.Attachments = append(feedItem.Attachments, attachment)
+	}
+
+	return feedItem
+}
+
+// getJSONFeedAuthors returns the author of the given item; or nil if the item has no author.
+func getJSONFeedAuthors(item *model.Item) []viewmodel.JSONFeedAuthor {
+	if item.MetaData.Author == "" {
+		return nil
+	}
+
+	return []viewmodel.JSONFeedAuthor{{Name: item.MetaData.Author}}
+}
+
+// getJSONFeedAttachment returns the attachment model for the given file.
+func getJSONFeedAttachment(rootPathProvider paths.Pather, file *model.File) (viewmodel.JSONFeedAttachment, error) {
+	fileModel, err := toViewModel(rootPathProvider, file)
+	if err != nil {
+		return viewmodel.JSONFeedAttachment{}, err
+	}
+
+	// determine the file size
+	var size int64
+	err = file.Data(func(content io.ReadSeeker) error {
+		var seekErr error
+		size, seekErr = content.Seek(0, io.SeekEnd)
+		return seekErr
+	})
+
+	if err != nil {
+		return viewmodel.JSONFeedAttachment{}, fmt.Errorf("Unable to determine the size of file %q. Error: %s", file, err.Error())
+	}
+
+	return viewmodel.JSONFeedAttachment{
+		URL:         fileModel.Route,
+		MimeType:    fileModel.MimeType,
+		Title:       fileModel.Name,
+		SizeInBytes: size,
+	}, nil
+}
+
+// GetAtomFeed returns an Atom feed model for the latest items below the item with the given route which can be shown
+// with the given visibility. If a tag is given only the items with this tag are included.
+func (orchestrator *FeedOrchestrator) GetAtomFeed(baseURL string, itemRoute route.Route, feedRoute, tag string, itemsPerPage, page int, visibility Visibility) (viewmodel.AtomFeed, error) {
+
+	// validate page number
+	if page < 1 {
+		return viewmodel.AtomFeed{}, fmt.Errorf("Invalid page number: %v.", page)
+	}
+
+	item, latestItems, err := orchestrator.getFeedItems(itemRoute, tag)
+	if err != nil {
+		return viewmodel.AtomFeed{}, err
+	}
+
+	latestItems = visibleItems(latestItems, visibility)
+
+	pageItems, found := pagedItems(latestItems, itemsPerPage, page)
+	if !found {
+		return viewmodel.AtomFeed{}, fmt.Errorf("No items found (Items per page: %v, Page: %v)", itemsPerPage, page)
+	}
+
+	rootPathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
+	feedURL := rootPathProvider.Path(feedRoute)
+
+	feed := viewmodel.AtomFeed{
+		ID:       orchestrator.getAtomID("feed", item, tag),
+		Title:    getFeedTitle(item, tag),
+		Subtitle: item.Description,
+		Link:     rootPathProvider.Path(item.Route().Value()),
+		SelfLink: getFeedPageURL(feedURL, tag, page),
+		Author:   orchestrator.getAtomAuthor(item),
+		Entries:  make([]viewmodel.AtomEntry, 0, len(pageItems)),
+	}
+
+	// link the next page
+	if _, hasNextPage := pagedItems(latestItems, itemsPerPage, page+1); hasNextPage {
+		feed.NextLink = getFeedPageURL(feedURL, tag, page+1)
+	}
+
+	// the feed has been updated when the most recent of its entries has been updated
+	var feedUpdated time.Time
+	for _, entryItem := range pageItems {
+		if updated := getFeedUpdateTime(entryItem); updated.After(feedUpdated) {
+			feedUpdated = updated
+		}
+
+		feed.Entries = append(feed.Entries, orchestrator.createAtomEntry(rootPathProvider, entryItem))
+	}
+
+	feed.Updated = getAtomTimestamp(feedUpdated)
+
+	return feed, nil
+}
+
+func (orchestrator *FeedOrchestrator) createAtomEntry(rootPathProvider paths.Pather, item *model.Item) viewmodel.AtomEntry {
+
+	entry := viewmodel.AtomEntry{
+		ID:         orchestrator.getAtomID("item", item, ""),
+		Title:      item.Title,
+		Link:       rootPathProvider.Path(item.Route().Value()),
+		Summary:    item.Description,
+		Content:    orchestrator.getFeedContent(rootPathProvider, item),
+		Updated:    getAtomTimestamp(getFeedUpdateTime(item)),
+		Author:     item.MetaData.Author,
+		Categories: item.MetaData.Tags,
+	}
+
+	if !item.MetaData.CreationDate.IsZero() {
+		entry.Published = getAtomTimestamp(item.MetaData.CreationDate)
+	}
+
+	return entry
+}
+
+// getAtomID returns a permanent identifier for the feed or entry of the given item. The identifier is a
+// name-based UUID URN derived from the domain name, the item route and the tag of the feed, so it does not
+// change when the repository is served under another URL or when the item is modified.
+func (orchestrator *FeedOrchestrator) getAtomID(kind string, item *model.Item, tag string) string {
+	name := fmt.Sprintf("%s:%s/%s", kind, orchestrator.config.Server.DomainName, item.Route().Value())
+	if tag != "" {
+		name += "?tag=" + strings.ToLower(tag)
+	}
+
+	return getNameBasedUUID(name)
+}
+
+// getAtomAuthor returns the author of the given item; or the author or title of the root item if the item has no author.
+func (orchestrator *FeedOrchestrator) getAtomAuthor(item *model.Item) string {
+	if item.MetaData.Author != "" {
+		return item.MetaData.Author
+	}
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		return item.Title
+	}
+
+	if rootItem.MetaData.Author != "" {
+		return rootItem.MetaData.Author
+	}
+
+	return rootItem.Title
+}
+
+// getFeedUpdateTime returns the last modification date of the given item; or the creation date if the item has no modification date.
+func getFeedUpdateTime(item *model.Item) time.Time {
+	if !item.MetaData.LastModifiedDate.IsZero() {
+		return item.MetaData.LastModifiedDate
+	}
+
+	return item.MetaData.CreationDate
+}
+
+// getAtomTimestamp formats the given time according to RFC 3339. Uninitialized times are formatted as the Unix epoch.
+func getAtomTimestamp(value time.Time) string {
+	if value.IsZero() {
+		value = time.Unix(0, 0)
+	}
+
+	return value.UTC().Format(time.RFC3339)
+}
+
+// getNameBasedUUID returns a version 5 UUID URN (RFC 4122) for the given name in the URL namespace.
+func getNameBasedUUID(name string) string {
+	namespace := []byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+	hash := sha1.New()
+	hash.Write(namespace)
+	hash.Write([]byte(name))
+	uuid := hash.Sum(nil)[:16]
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x50 // version 5
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
 }
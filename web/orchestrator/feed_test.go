@@ -0,0 +1,98 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_getNameBasedUUID_Name_VersionFiveUUIDInTheURLNamespaceIsReturned(t *testing.T) {
+	// arrange
+	name := "https://example.com/docs"
+
+	// act
+	result := getNameBasedUUID(name)
+
+	// assert
+	expected := "urn:uuid:135db5b6-4ec5-5ed7-ad6e-a868e6324b64"
+	if result != expected {
+		t.Errorf("getNameBasedUUID(%q) should return %q but returned %q.", name, expected, result)
+	}
+}
+
+func Test_getNameBasedUUID_SameName_SameUUIDIsReturned(t *testing.T) {
+	// act
+	first := getNameBasedUUID("item:localhost/docs/sample")
+	second := getNameBasedUUID("item:localhost/docs/sample")
+	other := getNameBasedUUID("item:localhost/docs/other")
+
+	// assert
+	if first != second {
+		t.Errorf("getNameBasedUUID should return the same UUID for the same name but returned %q and %q.", first, second)
+	}
+
+	if first == other {
+		t.Errorf("getNameBasedUUID should return different UUIDs for different names but returned %q for both.", first)
+	}
+}
+
+func Test_getAtomTimestamp_TimeWithZone_TimeIsFormattedInUTC(t *testing.T) {
+	// arrange
+	value := time.Date(2015, 8, 3, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	// act
+	result := getAtomTimestamp(value)
+
+	// assert
+	if result != "2015-08-03T12:30:00Z" {
+		t.Errorf("getAtomTimestamp should return %q but returned %q.", "2015-08-03T12:30:00Z", result)
+	}
+}
+
+func Test_getAtomTimestamp_ZeroTime_UnixEpochIsReturned(t *testing.T) {
+	// act
+	result := getAtomTimestamp(time.Time{})
+
+	// assert
+	if result != "1970-01-01T00:00:00Z" {
+		t.Errorf("getAtomTimestamp should return the Unix epoch for uninitialized times but returned %q.", result)
+	}
+}
+
+func Test_getFeedPageURL_TagAndPage_ParametersAreAppended(t *testing.T) {
+	// act
+	result := getFeedPageURL("http://example.com/projects/feed.rss", "go lang", 2)
+
+	// assert
+	expected := "http://example.com/projects/feed.rss?page=2&tag=go+lang"
+	if result != expected {
+		t.Errorf("getFeedPageURL should return %q but returned %q.", expected, result)
+	}
+}
+
+func Test_getFeedPageURL_FirstPageWithoutTag_FeedURLIsReturned(t *testing.T) {
+	// act
+	result := getFeedPageURL("http://example.com/feed.rss", "", 1)
+
+	// assert
+	if result != "http://example.com/feed.rss" {
+		t.Errorf("getFeedPageURL should return the feed URL without parameters but returned %q.", result)
+	}
+}
+
+func Test_getAbsoluteLinks_RelativeLinks_LinksAreResolvedAgainstTheItemURL(t *testing.T) {
+	// arrange
+	html := `<a href="other">Other</a> <a href="/wiki/">Home</a> <img src="files/image.png"> <a href="https://example.com/x">External</a> <a href="mailto:info@example.com">Mail</a>`
+
+	// act
+	result := getAbsoluteLinks(html, "http://example.com/wiki/docs/sample")
+
+	// assert
+	expected := `<a href="http://example.com/wiki/docs/other">Other</a> <a href="http://example.com/wiki/">Home</a> <img src="http://example.com/wiki/docs/files/image.png"> <a href="https://example.com/x">External</a> <a href="mailto:info@example.com">Mail</a>`
+	if result != expected {
+		t.Errorf("getAbsoluteLinks should return\n%s\nbut returned\n%s", expected, result)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package htmlcache provides a cache for the converted HTML of the items which is kept in memory
+// and optionally on disc, so unchanged items don't have to be converted again.
+package htmlcache
+
+import (
+	"github.com/andreaskoch/allmark/common/logger"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Entry is the converted HTML of an item.
+type Entry struct {
+	// Route is the route of the converted item.
+	Route string `json:"route"`
+
+	// HTML is the converted HTML of the item.
+	HTML string `json:"html"`
+
+	// References contains the fingerprints of the items which were referenced by their alias
+	// when the item was converted (an empty fingerprint if no item had the alias).
+	References map[string]string `json:"references,omitempty"`
+}
+
+// size returns the approximate number of bytes the entry occupies in memory.
+func (entry Entry) size() int64 {
+	size := len(entry.Route) + len(entry.HTML)
+	for alias, fingerprint := range entry.References {
+		size += len(alias) + len(fingerprint)
+	}
+
+	return int64(size)
+}
+
+// Statistics contains the number of cached entries and the cache hits and misses.
+type Statistics struct {
+	Entries int
+	Size    int64
+	Hits    uint64
+	Misses  uint64
+}
+
+// New creates a new cache for converted HTML. The version (e.g. of the theme) is part of every key, so entries
+// of another version are never returned. Entries are removed from memory (least recently used first) when
+// the maximum size in bytes is exceeded; 0 means unlimited. If a folder is given the entries are also stored
+// on disc and are available after a restart.
+func New(logger logger.Logger, version string, maxSize int64, folder string) *Cache {
+	return &Cache{
+		logger:  logger,
+		version: version,
+		maxSize: maxSize,
+		folder:  folder,
+
+		entries: make(map[string]*list.Element),
+		routes:  make(map[string]map[string]bool),
+		lru:     list.New(),
+	}
+}
+
+// Cache is a cache for converted HTML.
+type Cache struct {
+	logger  logger.Logger
+	version string
+	maxSize int64
+	folder  string
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	routes  map[string]map[string]bool
+	lru     *list.List
+	size    int64
+	hits    uint64
+	misses  uint64
+}
+
+// element is an entry of the cache together with its key.
+type element struct {
+	key   string
+	entry Entry
+}
+
+// Key returns the cache key for the given attributes (e.g. the route and the content hash of an item).
+func (cache *Cache) Key(attributes ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(cache.version))
+	for _, attribute := range attributes {
+		hash.Write([]byte{0})
+		hash.Write([]byte(attribute))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the entry with the given key of the item with the given route.
+// Entries which are not in memory are read from disc.
+func (cache *Cache) Get(route, key string) (Entry, bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if cached, exists := cache.entries[key]; exists {
+		cache.lru.MoveToFront(cached)
+		cache.hits++
+		return cached.Value.(*element).entry, true
+	}
+
+	entry, exists := cache.read(route, key)
+	if !exists {
+		cache.misses++
+		return Entry{}, false
+	}
+
+	cache.add(key, entry)
+	cache.hits++
+	return entry, true
+}
+
+// Set stores the given entry under the given key.
+func (cache *Cache) Set(key string, entry Entry) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.remove(key)
+	cache.add(key, entry)
+
+	if err := cache.write(key, entry); err != nil {
+		cache.logger.Warn("Unable to store the HTML of %q on disc. Error: %s", entry.Route, err)
+	}
+}
+
+// Remove removes the entry with the given key of the item with the given route
+// (e.g. because an item it references has changed).
+func (cache *Cache) Remove(route, key string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.remove(key)
+	if cache.folder != "" {
+		os.Remove(cache.entryPath(route, key))
+	}
+}
+
+// Invalidate removes all entries of the item with the given route (e.g. because it was modified or deleted).
+func (cache *Cache) Invalidate(route string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	for key := range cache.routes[route] {
+		cache.remove(key)
+	}
+
+	if cache.folder != "" {
+		os.RemoveAll(cache.routeFolder(route))
+	}
+}
+
+// Statistics returns the number of cached entries and the cache hits and misses.
+func (cache *Cache) Statistics() Statistics {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	return Statistics{
+		Entries: cache.lru.Len(),
+		Size:    cache.size,
+		Hits:    cache.hits,
+		Misses:  cache.misses,
+	}
+}
+
+// add adds the given entry to the memory and removes the least recently used entries if the maximum size is exceeded.
+func (cache *Cache) add(key string, entry Entry) {
+	cache.entries[key] = cache.lru.PushFront(&element{key, entry})
+	cache.size += entry.size()
+
+	if cache.routes[entry.Route] == nil {
+		cache.routes[entry.Route] = make(map[string]bool)
+	}
+
+	cache.routes[entry.Route][key] = true
+
+	for cache.maxSize > 0 && cache.size > cache.maxSize && cache.lru.Len() > 1 {
+		cache.remove(cache.lru.Back().Value.(*element).key)
+	}
+}
+
+// remove removes the entry with the given key from the memory.
+func (cache *Cache) remove(key string) {
+	cached, exists := cache.entries[key]
+	if !exists {
+		return
+	}
+
+	entry := cached.Value.(*element).entry
+	cache.lru.Remove(cached)
+	cache.size -= entry.size()
+	delete(cache.entries, key)
+
+	delete(cache.routes[entry.Route], key)
+	if len(cache.routes[entry.Route]) == 0 {
+		delete(cache.routes, entry.Route)
+	}
+}
+
+// read reads the entry with the given key of the item with the given route from disc.
+func (cache *Cache) read(route, key string) (Entry, bool) {
+	if cache.folder == "" {
+		return Entry{}, false
+	}
+
+	data, err := os.ReadFile(cache.entryPath(route, key))
+	if err != nil {
+		return Entry{}, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Route != route {
+		return Entry{}, false
+	}
+
+	return entry, true
+}
+
+// write stores the given entry on disc.
+func (cache *Cache) write(key string, entry Entry) error {
+	if cache.folder == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(cache.routeFolder(entry.Route), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(cache.entryPath(entry.Route, key), data, 0600)
+}
+
+// entryPath returns the path of the file of the entry with the given key of the item with the given route.
+func (cache *Cache) entryPath(route, key string) string {
+	return filepath.Join(cache.routeFolder(route), key+".json")
+}
+
+// routeFolder returns the folder in which the entries of the item with the given route are stored.
+func (cache *Cache) routeFolder(route string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(route)))
+	return filepath.Join(cache.folder, hex.EncodeToString(hash[:])[:16])
+}
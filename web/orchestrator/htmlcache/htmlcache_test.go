@@ -0,0 +1,96 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package htmlcache
+
+import (
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"strings"
+	"testing"
+)
+
+func Test_Get_EntryWasSet_EntryIsReturned(t *testing.T) {
+	// arrange
+	cache := New(console.New(loglevel.Off), "v1", 0, "")
+	key := cache.Key("documents/sample", "content-hash")
+	cache.Set(key, Entry{Route: "documents/sample", HTML: "<p>Sample</p>"})
+
+	// act
+	entry, exists := cache.Get("documents/sample", key)
+
+	// assert
+	if !exists || entry.HTML != "<p>Sample</p>" {
+		t.Errorf("The cached HTML should have been returned but Get returned %q (exists: %t).", entry.HTML, exists)
+	}
+}
+
+func Test_Key_DifferentVersions_KeysAreDifferent(t *testing.T) {
+	// arrange
+	cache1 := New(console.New(loglevel.Off), "v1", 0, "")
+	cache2 := New(console.New(loglevel.Off), "v2", 0, "")
+
+	// act
+	key1 := cache1.Key("documents/sample", "content-hash")
+	key2 := cache2.Key("documents/sample", "content-hash")
+
+	// assert
+	if key1 == key2 {
+		t.Errorf("The keys of different versions should be different but both were %q.", key1)
+	}
+}
+
+func Test_Set_MaxSizeExceeded_LeastRecentlyUsedEntryIsRemoved(t *testing.T) {
+	// arrange
+	cache := New(console.New(loglevel.Off), "v1", 30, "")
+	cache.Set("a", Entry{Route: "a", HTML: strings.Repeat("a", 10)})
+	cache.Set("b", Entry{Route: "b", HTML: strings.Repeat("b", 10)})
+	cache.Get("a", "a")
+
+	// act
+	cache.Set("c", Entry{Route: "c", HTML: strings.Repeat("c", 10)})
+
+	// assert
+	if _, exists := cache.Get("b", "b"); exists {
+		t.Errorf("The least recently used entry should have been removed.")
+	}
+
+	if _, exists := cache.Get("a", "a"); !exists {
+		t.Errorf("The recently used entry should have been kept.")
+	}
+}
+
+func Test_Invalidate_EntriesOfOtherItemsAreKept(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	cache := New(console.New(loglevel.Off), "v1", 0, folder)
+	cache.Set("a1", Entry{Route: "a", HTML: "<p>A</p>"})
+	cache.Set("b1", Entry{Route: "b", HTML: "<p>B</p>"})
+
+	// act
+	cache.Invalidate("a")
+
+	// assert
+	if _, exists := cache.Get("a", "a1"); exists {
+		t.Errorf("The entries of the invalidated item should have been removed from memory and disc.")
+	}
+
+	if _, exists := cache.Get("b", "b1"); !exists {
+		t.Errorf("The entries of the other items should have been kept.")
+	}
+}
+
+func Test_Get_PersistentCache_EntryIsReadFromDisc(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	New(console.New(loglevel.Off), "v1", 0, folder).Set("a1", Entry{Route: "a", HTML: "<p>A</p>", References: map[string]string{"other": ""}})
+
+	// act
+	entry, exists := New(console.New(loglevel.Off), "v1", 0, folder).Get("a", "a1")
+
+	// assert
+	if !exists || entry.HTML != "<p>A</p>" || len(entry.References) != 1 {
+		t.Errorf("The entry should have been read from disc but Get returned %#v (exists: %t).", entry, exists)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// [reference:*alias-of-referenced-item*]
+	linkReferencePattern = regexp.MustCompile(`\[reference:([^\]]+)\]`)
+
+	// [*text*](*target*), ![*text*](*target*) or *extension*: [*text*](*target*)
+	markdownLinkPattern = regexp.MustCompile(`(\w+:[ \t]*)?(!)?\[[^\]]*\]\(([^)\s]+)[^)]*\)`)
+
+	// <a href="*target*">
+	htmlLinkPattern = regexp.MustCompile(`<a\s[^>]*href="([^"]+)"`)
+)
+
+// linkGraph contains the links between the items of the repository.
+type linkGraph struct {
+	routes    []route.Route
+	outgoing  map[string][]route.Route
+	backlinks map[string][]route.Route
+}
+
+// Links returns the routes of the items the item with the given route links to.
+func (graph *linkGraph) Links(itemRoute route.Route) []route.Route {
+	return graph.outgoing[route.ToKey(itemRoute)]
+}
+
+// Backlinks returns the routes of the items which link to the item with the given route.
+func (graph *linkGraph) Backlinks(itemRoute route.Route) []route.Route {
+	return graph.backlinks[route.ToKey(itemRoute)]
+}
+
+// Routes returns the routes of all items of the graph.
+func (graph *linkGraph) Routes() []route.Route {
+	return graph.routes
+}
+
+// links returns the link graph of all items. The graph is created on first use and rebuilt on every update.
+func (orchestrator *Orchestrator) links() *linkGraph {
+
+	if orchestrator.itemLinks != nil {
+		return orchestrator.itemLinks
+	}
+
+	// updateLinkGraph creates a new link graph and replaces the existing one.
+	updateLinkGraph := func(r route.Route) {
+		orchestrator.itemLinks = orchestrator.newLinkGraph(orchestrator.getAllItems())
+	}
+
+	// initialize
+	updateLinkGraph(route.New())
+
+	// register update callbacks
+	orchestrator.registerUpdateCallback("update link graph", UpdateTypeNew, updateLinkGraph)
+	orchestrator.registerUpdateCallback("update link graph", UpdateTypeModified, updateLinkGraph)
+	orchestrator.registerUpdateCallback("update link graph", UpdateTypeDeleted, updateLinkGraph)
+
+	return orchestrator.itemLinks
+}
+
+// newLinkGraph creates a link graph from the references and links in the markdown of the given items.
+func (orchestrator *Orchestrator) newLinkGraph(items []*model.Item) *linkGraph {
+	graph := &linkGraph{
+		routes:    make([]route.Route, 0, len(items)),
+		outgoing:  make(map[string][]route.Route),
+		backlinks: make(map[string][]route.Route),
+	}
+
+	for _, item := range items {
+		graph.routes = append(graph.routes, item.Route())
+
+		linkedItems := make(map[string]bool)
+		for _, linkedItem := range orchestrator.getLinkedItems(item) {
+			key := route.ToKey(linkedItem.Route())
+			if linkedItems[key] || linkedItem.Route().Equals(item.Route()) {
+				continue
+			}
+
+			linkedItems[key] = true
+			graph.outgoing[route.ToKey(item.Route())] = append(graph.outgoing[route.ToKey(item.Route())], linkedItem.Route())
+			graph.backlinks[key] = append(graph.backlinks[key], item.Route())
+		}
+	}
+
+	return graph
+}
+
+// getLinkedItems returns the items which are referenced or linked in the markdown of the given item.
+func (orchestrator *Orchestrator) getLinkedItems(item *model.Item) []*model.Item {
+	aliases, targets := getLinkTargets(item.Markdown)
+
+	linkedItems := make([]*model.Item, 0)
+	for _, alias := range aliases {
+		if linkedItem := orchestrator.getItemByAlias(alias); linkedItem != nil {
+			linkedItems = append(linkedItems, linkedItem)
+		}
+	}
+
+	for _, target := range targets {
+		targetPath, isInternal := resolveLinkTarget(orchestrator.basePath(), item.Route(), target)
+		if !isInternal {
+			continue
+		}
+
+		// the target can be an alternative representation of the item (e.g. "document.json")
+		linkedItem := orchestrator.getItem(route.NewFromRequest(targetPath))
+		if linkedItem == nil {
+			linkedItem = orchestrator.getItem(route.NewFromRequest(strings.TrimSuffix(targetPath, path.Ext(targetPath))))
+		}
+
+		if linkedItem != nil {
+			linkedItems = append(linkedItems, linkedItem)
+		}
+	}
+
+	return linkedItems
+}
+
+// getLinkTargets returns the referenced aliases and the link targets in the given markdown.
+// Images and the links of markdown extensions (e.g. "files: [Attachments](files)") are ignored.
+func getLinkTargets(markdown string) (aliases []string, targets []string) {
+	for _, match := range linkReferencePattern.FindAllStringSubmatch(markdown, -1) {
+		aliases = append(aliases, strings.TrimSpace(match[1]))
+	}
+
+	for _, match := range markdownLinkPattern.FindAllStringSubmatch(markdown, -1) {
+		if match[1] != "" || match[2] != "" {
+			continue
+		}
+
+		targets = append(targets, match[3])
+	}
+
+	for _, match := range htmlLinkPattern.FindAllStringSubmatch(markdown, -1) {
+		targets = append(targets, match[1])
+	}
+
+	return aliases, targets
+}
+
+// resolveLinkTarget returns the repository path (e.g. "documents/Sample-Document") of the given link target
+// of the item with the given route. Absolute targets must start with the given base path; external links
+// (e.g. "https://example.com", "mailto:") are not resolved.
+func resolveLinkTarget(basePath string, itemRoute route.Route, target string) (targetPath string, isInternal bool) {
+	target = strings.TrimSpace(target)
+
+	// strip the fragment and the query
+	if position := strings.IndexAny(target, "#?"); position >= 0 {
+		target = target[:position]
+	}
+
+	if target == "" {
+		return "", false
+	}
+
+	parsedTarget, err := url.Parse(target)
+	if err != nil || parsedTarget.Scheme != "" || parsedTarget.Host != "" {
+		return "", false
+	}
+
+	targetPath = parsedTarget.Path
+
+	if strings.HasPrefix(targetPath, "/") {
+		if !strings.HasPrefix(targetPath+"/", basePath) {
+			return "", false
+		}
+
+		targetPath = path.Clean("/" + strings.TrimPrefix(targetPath+"/", basePath))
+	} else {
+		// item URLs don't end with a slash, so relative targets are resolved against the parent (like in the browser)
+		targetPath = path.Join(path.Dir("/"+itemRoute.OriginalValue()), targetPath)
+	}
+
+	return strings.Trim(targetPath, "/"), true
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"testing"
+)
+
+func Test_getLinkTargets_MarkdownWithLinksImagesAndExtensions_OnlyLinksAreReturned(t *testing.T) {
+	// arrange
+	markdown := `See [the sample](../sample "Sample") and [reference:go-intro].
+
+![Screenshot](files/screenshot.png)
+
+files: [Attachments](files)
+
+<a href="/docs/other">Other</a>`
+
+	// act
+	aliases, targets := getLinkTargets(markdown)
+
+	// assert
+	if len(aliases) != 1 || aliases[0] != "go-intro" {
+		t.Errorf("getLinkTargets should return the alias %q but returned %v.", "go-intro", aliases)
+	}
+
+	if len(targets) != 2 || targets[0] != "../sample" || targets[1] != "/docs/other" {
+		t.Errorf("getLinkTargets should return the targets [../sample /docs/other] but returned %v.", targets)
+	}
+}
+
+func Test_resolveLinkTarget_RelativeTarget_TargetIsResolvedAgainstTheParent(t *testing.T) {
+	// arrange
+	itemRoute := route.NewFromRequest("docs/sample")
+
+	// act
+	result, isInternal := resolveLinkTarget("/", itemRoute, "other#section")
+
+	// assert
+	if !isInternal || result != "docs/other" {
+		t.Errorf("resolveLinkTarget should return %q but returned %q (internal: %t).", "docs/other", result, isInternal)
+	}
+}
+
+func Test_resolveLinkTarget_AbsoluteTargetWithBasePath_BasePathIsRemoved(t *testing.T) {
+	// arrange
+	itemRoute := route.NewFromRequest("docs/sample")
+
+	// act
+	result, isInternal := resolveLinkTarget("/wiki/", itemRoute, "/wiki/docs/other")
+
+	// assert
+	if !isInternal || result != "docs/other" {
+		t.Errorf("resolveLinkTarget should return %q but returned %q (internal: %t).", "docs/other", result, isInternal)
+	}
+}
+
+func Test_resolveLinkTarget_ExternalTargets_TargetsAreNotInternal(t *testing.T) {
+	// arrange
+	itemRoute := route.NewFromRequest("docs/sample")
+
+	for _, target := range []string{"https://example.com/docs", "mailto:john@example.com", "/other/app", "#top"} {
+
+		// act
+		_, isInternal := resolveLinkTarget("/wiki/", itemRoute, target)
+
+		// assert
+		if isInternal {
+			t.Errorf("resolveLinkTarget should not resolve the external target %q.", target)
+		}
+	}
+}
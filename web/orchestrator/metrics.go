@@ -0,0 +1,55 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/metrics"
+)
+
+const (
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+)
+
+var (
+	cacheRequests = metrics.NewCounter(
+		"allmark_cache_requests_total",
+		"Number of cache lookups by cache and result (hit, miss).",
+		"cache", "result")
+
+	searchIndexDuration = metrics.NewHistogram(
+		"allmark_search_index_duration_seconds",
+		"Duration of the full-text index creation in seconds.",
+		[]float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60})
+
+	itemCount = metrics.NewGauge(
+		"allmark_items",
+		"Number of items in the repository index.")
+
+	searchIndexSize = metrics.NewGauge(
+		"allmark_search_index_items",
+		"Number of items in the full-text index.")
+
+	thumbnailQueueLength = metrics.NewGauge(
+		"allmark_thumbnail_queue_length",
+		"Number of items waiting for thumbnail creation.")
+)
+
+// UpdateMetrics updates the item, search index and thumbnail metrics.
+// Indizes which have not been created yet are reported as empty.
+func (orchestrator *StatusOrchestrator) UpdateMetrics() {
+
+	if orchestrator.repositoryIndex != nil {
+		itemCount.Set(float64(orchestrator.repositoryIndex.Size()))
+	}
+
+	if orchestrator.fulltextIndex != nil {
+		searchIndexSize.Set(float64(orchestrator.fulltextIndex.Size()))
+	}
+
+	if orchestrator.thumbnailConversion != nil {
+		thumbnailQueueLength.Set(float64(orchestrator.thumbnailConversion.Status().PendingItems))
+	}
+}
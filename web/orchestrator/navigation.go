@@ -5,7 +5,10 @@
 package orchestrator
 
 import (
+	"strings"
+
 	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 )
 
@@ -24,17 +27,24 @@ func (orchestrator *NavigationOrchestrator) GetToplevelNavigation() viewmodel.To
 	// updateToplevelNavigation creates a new toplevel navigation and stores it in the cache
 	updateToplevelNavigation := func(r route.Route) {
 		root := route.New()
-		toplevelEntries := make([]viewmodel.ToplevelEntry, 0)
+		toplevelItems := orchestrator.getChildren(root)
 
-		for _, child := range orchestrator.getChildren(root) {
+		// use the navigation definition of the root item if there is one
+		var definition []model.NavigationEntry
+		if rootItem := orchestrator.rootItem(); rootItem != nil {
+			definition = rootItem.MetaData.Navigation
+		}
 
-			toplevelEntries = append(toplevelEntries, viewmodel.ToplevelEntry{
-				Title: child.Title,
-				Path:  orchestrator.itemPather().Path(child.Route().Value()),
-			})
+		if len(definition) == 0 {
+			definition = []model.NavigationEntry{{Target: model.NavigationWildcard}}
+		}
 
+		getPath := func(path string) string {
+			return orchestrator.itemPather().Path(path)
 		}
 
+		toplevelEntries := getToplevelEntries(definition, toplevelItems, orchestrator.getItem, getPath)
+
 		orchestrator.toplevelNavigation = &viewmodel.ToplevelNavigation{
 			Entries: toplevelEntries,
 		}
@@ -51,6 +61,96 @@ func (orchestrator *NavigationOrchestrator) GetToplevelNavigation() viewmodel.To
 	return orchestrator.GetToplevelNavigation()
 }
 
+// getToplevelEntries returns the toplevel navigation entries for the given navigation definition. Entries which
+// link to an item get the title of the item unless they define their own; entries whose item does not exist are
+// skipped. The wildcard entry is replaced by the given toplevel items which are not listed themselves.
+func getToplevelEntries(definition []model.NavigationEntry, toplevelItems []*model.Item, getItem func(route.Route) *model.Item, getPath func(path string) string) []viewmodel.ToplevelEntry {
+
+	// collect the items which are listed explicitly
+	listedRoutes := make(map[string]bool)
+	for _, entry := range definition {
+		if entry.Target != model.NavigationWildcard && !isExternalURL(entry.Target) {
+			listedRoutes[route.NewFromRequest(entry.Target).Value()] = true
+		}
+	}
+
+	entries := make([]viewmodel.ToplevelEntry, 0, len(toplevelItems))
+	for _, entry := range definition {
+
+		switch {
+		case entry.Target == model.NavigationWildcard:
+			for _, item := range toplevelItems {
+				if listedRoutes[item.Route().Value()] {
+					continue
+				}
+
+				entries = append(entries, viewmodel.ToplevelEntry{
+					Title: item.Title,
+					Path:  getPath(item.Route().Value()),
+				})
+			}
+
+		case isExternalURL(entry.Target):
+			toplevelEntry := viewmodel.ToplevelEntry{
+				Title:      entry.Title,
+				Path:       entry.Target,
+				IsExternal: true,
+			}
+
+			if toplevelEntry.Title == "" {
+				toplevelEntry.Title = entry.Target
+			}
+
+			setToplevelEntryIcon(&toplevelEntry, entry.Icon, getPath)
+			entries = append(entries, toplevelEntry)
+
+		default:
+			item := getItem(route.NewFromRequest(entry.Target))
+			if item == nil {
+				continue
+			}
+
+			toplevelEntry := viewmodel.ToplevelEntry{
+				Title: entry.Title,
+				Path:  getPath(item.Route().Value()),
+			}
+
+			if toplevelEntry.Title == "" {
+				toplevelEntry.Title = item.Title
+			}
+
+			setToplevelEntryIcon(&toplevelEntry, entry.Icon, getPath)
+			entries = append(entries, toplevelEntry)
+		}
+	}
+
+	return entries
+}
+
+// setToplevelEntryIcon assigns the given icon to the given entry; icons which contain a dot or a slash
+// (e.g. "files/home.svg") are the paths or urls of images, all other icons are symbols (e.g. "★").
+func setToplevelEntryIcon(entry *viewmodel.ToplevelEntry, icon string, getPath func(path string) string) {
+	switch {
+	case icon == "":
+		return
+
+	case isExternalURL(icon):
+		entry.IconURL = icon
+
+	case strings.ContainsAny(icon, "./"):
+		entry.IconURL = getPath(route.NewFromRequest(icon).Value())
+
+	default:
+		entry.Icon = icon
+	}
+}
+
+// isExternalURL returns true if the given navigation target or icon is an url of another site
+// (e.g. "https://github.com" or "mailto:info@example.com").
+func isExternalURL(target string) bool {
+	return strings.Contains(target, "://") || strings.HasPrefix(target, "//") || strings.HasPrefix(strings.ToLower(target), "mailto:")
+}
+
 func (orchestrator *NavigationOrchestrator) GetBreadcrumbNavigation(route route.Route) viewmodel.BreadcrumbNavigation {
 
 	// create a new bread crumb navigation
@@ -0,0 +1,53 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+func Test_getToplevelEntries_NavigationDefinition_EntriesAreResolvedInTheirOrder(t *testing.T) {
+	// arrange
+	items := map[string]*model.Item{
+		"documentation":       newTestChild("documentation", "Documentation", 0, time.Time{}),
+		"documentation/setup": newTestChild("documentation/setup", "Setup", 0, time.Time{}),
+		"blog":                newTestChild("blog", "Blog", 0, time.Time{}),
+		"about":               newTestChild("about", "About", 0, time.Time{}),
+	}
+
+	toplevelItems := []*model.Item{items["about"], items["blog"], items["documentation"]}
+	getItem := func(itemRoute route.Route) *model.Item { return items[itemRoute.Value()] }
+	getPath := func(path string) string { return "/wiki/" + path }
+
+	definition := []model.NavigationEntry{
+		{Target: "/documentation/setup", Icon: "★"},
+		{Title: "Docs", Target: "/documentation", Icon: "files/docs.svg"},
+		{Target: model.NavigationWildcard},
+		{Target: "/missing"},
+		{Title: "Source Code", Target: "https://github.com/andreaskoch/allmark"},
+	}
+
+	// act
+	entries := getToplevelEntries(definition, toplevelItems, getItem, getPath)
+
+	// assert
+	expected := []viewmodel.ToplevelEntry{
+		{Title: "Setup", Path: "/wiki/documentation/setup", Icon: "★"},
+		{Title: "Docs", Path: "/wiki/documentation", IconURL: "/wiki/files/docs.svg"},
+		{Title: "About", Path: "/wiki/about"},
+		{Title: "Blog", Path: "/wiki/blog"},
+		{Title: "Source Code", Path: "https://github.com/andreaskoch/allmark", IsExternal: true},
+	}
+
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("The toplevel entries should be %#v but were %#v.", expected, entries)
+	}
+}
@@ -21,7 +21,7 @@ func (orchestrator *OpenSearchDescriptionOrchestrator) GetDescriptionModel(hostn
 		orchestrator.logger.Fatal("No root item found")
 	}
 
-	addressPrefix := fmt.Sprintf("%s/", hostname)
+	addressPrefix := fmt.Sprintf("%s%s", hostname, orchestrator.basePath())
 	pathProvider := orchestrator.absolutePather(addressPrefix)
 
 	descriptionModel := viewmodel.OpenSearchDescription{
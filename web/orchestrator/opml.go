@@ -0,0 +1,147 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/paths"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// OPMLOutlineTypeRSS is the outline type of feeds.
+	OPMLOutlineTypeRSS = "rss"
+
+	// OPMLOutlineTypeLink is the outline type of web pages.
+	OPMLOutlineTypeLink = "link"
+)
+
+type OPMLOrchestrator struct {
+	*Orchestrator
+}
+
+// GetOPML returns an OPML document with the item hierarchy of the repository. Items with children are
+// outlines of the type "rss" which reference the feed of their subtree (e.g. "docs/feed.rss" for the feed name "feed.rss");
+// all other items are outlines of the type "link".
+// If feedsOnly is set the OPML document contains a flat list of the feeds of the repository and of all subtrees.
+// Only the items which can be shown with the given visibility are included.
+func (orchestrator *OPMLOrchestrator) GetOPML(baseURL, feedName string, feedsOnly bool, visibility Visibility) viewmodel.OPML {
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	pathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", baseURL, orchestrator.basePath()))
+
+	var outlines []viewmodel.OPMLOutline
+	if feedsOnly {
+		outlines = orchestrator.getOPMLFeedOutlines(pathProvider, feedName, rootItem, visibility)
+	} else {
+		outlines = orchestrator.getOPMLOutlines(pathProvider, feedName, rootItem.Route(), visibility)
+	}
+
+	// the document has been modified when the most recent item has been modified
+	var lastModified time.Time
+	for _, item := range visibleItems(orchestrator.getAllItems(), visibility) {
+		if updateTime := getFeedUpdateTime(item); !item.MetaData.NoIndex && updateTime.After(lastModified) {
+			lastModified = updateTime
+		}
+	}
+
+	opml := viewmodel.OPML{
+		Title:     rootItem.Title,
+		OwnerName: orchestrator.config.Web.Publisher.Name,
+		Outlines:  outlines,
+	}
+
+	if !lastModified.IsZero() {
+		opml.DateModified = lastModified.UTC().Format(time.RFC1123Z)
+	}
+
+	return opml
+}
+
+// getOPMLOutlines returns the outlines for the children of the item with the given route.
+func (orchestrator *OPMLOrchestrator) getOPMLOutlines(pathProvider paths.Pather, feedName string, parentRoute route.Route, visibility Visibility) []viewmodel.OPMLOutline {
+
+	outlines := make([]viewmodel.OPMLOutline, 0)
+	for _, child := range orchestrator.getListedChildren(parentRoute, visibility) {
+
+		children := orchestrator.getOPMLOutlines(pathProvider, feedName, child.Route(), visibility)
+
+		outline := getOPMLOutline(pathProvider, feedName, child, len(children) > 0)
+		outline.Children = children
+
+		outlines = append(outlines, outline)
+	}
+
+	return outlines
+}
+
+// getOPMLFeedOutlines returns the feed outlines for the given item and for all of its descendants which have children.
+func (orchestrator *OPMLOrchestrator) getOPMLFeedOutlines(pathProvider paths.Pather, feedName string, item *model.Item, visibility Visibility) []viewmodel.OPMLOutline {
+
+	outlines := []viewmodel.OPMLOutline{
+		getOPMLOutline(pathProvider, feedName, item, true),
+	}
+
+	for _, child := range orchestrator.getListedChildren(item.Route(), visibility) {
+
+		if len(orchestrator.getListedChildren(child.Route(), visibility)) == 0 {
+			continue
+		}
+
+		outlines = append(outlines, orchestrator.getOPMLFeedOutlines(pathProvider, feedName, child, visibility)...)
+	}
+
+	return outlines
+}
+
+// getListedChildren returns the children of the item with the given route without the items which must not be listed
+// or cannot be shown with the given visibility. The children of unlisted items are not listed either.
+func (orchestrator *OPMLOrchestrator) getListedChildren(parentRoute route.Route, visibility Visibility) []*model.Item {
+
+	children := make([]*model.Item, 0)
+	for _, child := range visibleItems(orchestrator.getChildren(parentRoute), visibility) {
+		if !child.MetaData.NoIndex {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
+
+// getOPMLOutline returns the outline for the given item; a feed outline if isFeed is set and a link outline otherwise.
+func getOPMLOutline(pathProvider paths.Pather, feedName string, item *model.Item, isFeed bool) viewmodel.OPMLOutline {
+
+	location := pathProvider.Path(item.Route().Value())
+
+	outline := viewmodel.OPMLOutline{
+		Text:        item.Title,
+		Description: item.Description,
+	}
+
+	if !item.MetaData.CreationDate.IsZero() {
+		outline.Created = item.MetaData.CreationDate.UTC().Format(time.RFC1123Z)
+	}
+
+	if !isFeed {
+		outline.Type = OPMLOutlineTypeLink
+		outline.URL = location
+		return outline
+	}
+
+	feedRoute := strings.TrimPrefix(item.Route().Value()+"/"+feedName, "/")
+
+	outline.Type = OPMLOutlineTypeRSS
+	outline.HTMLURL = location
+	outline.XMLURL = pathProvider.Path(feedRoute)
+	return outline
+}
@@ -6,16 +6,24 @@ package orchestrator
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
 	"github.com/andreaskoch/allmark/common/paths"
+	"github.com/andreaskoch/allmark/common/progress"
 	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/common/shutdown"
+	"github.com/andreaskoch/allmark/common/util/parallelutil"
 	"github.com/andreaskoch/allmark/dataaccess"
 	"github.com/andreaskoch/allmark/model"
 	"github.com/andreaskoch/allmark/services/converter"
 	"github.com/andreaskoch/allmark/services/parser"
+	"github.com/andreaskoch/allmark/services/textextraction"
+	"github.com/andreaskoch/allmark/services/thumbnail"
+	"github.com/andreaskoch/allmark/web/orchestrator/htmlcache"
 	"github.com/andreaskoch/allmark/web/orchestrator/index"
 	"github.com/andreaskoch/allmark/web/orchestrator/search"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
@@ -113,7 +121,7 @@ func (updateCallback *CacheUpdateCallback) Execute(route route.Route) (err error
 	return err
 }
 
-func newBaseOrchestrator(logger logger.Logger, config config.Config, repository dataaccess.Repository, parser parser.Parser, converter converter.Converter, webPathProvider webpaths.WebPathProvider) *Orchestrator {
+func newBaseOrchestrator(logger logger.Logger, config config.Config, repository dataaccess.Repository, parser parser.Parser, converter converter.Converter, webPathProvider webpaths.WebPathProvider, thumbnailIndex *thumbnail.Index, htmlCache *htmlcache.Cache) *Orchestrator {
 
 	orchestrator := &Orchestrator{
 		logger: logger,
@@ -124,9 +132,31 @@ func newBaseOrchestrator(logger logger.Logger, config config.Config, repository
 		converter:  converter,
 
 		webPathProvider: webPathProvider,
+		thumbnailIndex:  thumbnailIndex,
+		htmlCache:       htmlCache,
 
 		updateSubscribers: make([]chan Update, 0),
 		updateCallbacks:   make(map[UpdateType][]CacheUpdateCallback),
+
+		lastUpdate: time.Now().UnixNano(),
+	}
+
+	// remove the cached HTML of modified and deleted items
+	if htmlCache != nil {
+		orchestrator.registerUpdateCallback("invalidate html", UpdateTypeModified, orchestrator.invalidateHTML)
+		orchestrator.registerUpdateCallback("invalidate html", UpdateTypeDeleted, orchestrator.invalidateHTML)
+	}
+
+	// record the duration of the re-indexing runs of the repository
+	if indexNotifier, isIndexNotifier := repository.(dataaccess.IndexNotifier); isIndexNotifier {
+		indexingResults := make(chan dataaccess.IndexingResult, 1)
+		indexNotifier.SubscribeToIndexing(indexingResults)
+
+		go func() {
+			for result := range indexingResults {
+				orchestrator.lastIndexRun.Set(result.Duration, time.Now())
+			}
+		}()
 	}
 
 	return orchestrator
@@ -142,14 +172,70 @@ type Orchestrator struct {
 
 	webPathProvider webpaths.WebPathProvider
 
+	// thumbnailIndex is used to reference the thumbnails of images (optional)
+	thumbnailIndex *thumbnail.Index
+
+	// htmlCache contains the converted HTML of the items (optional)
+	htmlCache *htmlcache.Cache
+
 	// caches and indizes (do not initialize!)
+	repositoryIndexInitialization sync.Once
+	fulltextIndexInitialization   sync.Once
+	indexesInitialization         sync.Once
+	indexesInitialized            int32
+	repositoryIndexCreated        int32
+	fulltextIndexCreated          int32
+
 	fulltextIndex   *search.ItemSearch
+	suggestionIndex *search.SuggestionIndex
 	repositoryIndex *index.Index
 	itemsByAlias    ItemCache
+	itemLinks       *linkGraph
+	relatedIndex    *relatedItemIndex
+
+	// statistics
+	lastIndexRun       indexRun
+	lastSearchIndexRun indexRun
 
 	// update handling
-	updateCallbacks   map[UpdateType][]CacheUpdateCallback
-	updateSubscribers []chan Update
+	updateCallbacks      map[UpdateType][]CacheUpdateCallback
+	updateCallbacksMutex sync.RWMutex
+	updateSubscribers    []chan Update
+
+	// lastUpdate is the time (in nanoseconds since the epoch) of the most recent update of the repository
+	lastUpdate int64
+}
+
+// LastUpdate returns the time of the most recent change of the repository or, if it has not changed since,
+// the time when the orchestrator was created. The pages of the items contain the navigation, the children
+// and the backlinks, so they can change whenever any item changes.
+func (orchestrator *Orchestrator) LastUpdate() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&orchestrator.lastUpdate))
+}
+
+// InitializeIndexes creates the repository index and the search index if they don't exist yet.
+// Only the first call creates the indizes; all other calls wait until they have been created.
+func (orchestrator *Orchestrator) InitializeIndexes() {
+	orchestrator.indexesInitialization.Do(func() {
+		orchestrator.index()
+		orchestrator.searchIndex()
+		atomic.StoreInt32(&orchestrator.indexesInitialized, 1)
+	})
+}
+
+// indexesAreInitialized returns a flag indicating whether the repository index and the search index have been created.
+func (orchestrator *Orchestrator) indexesAreInitialized() bool {
+	return atomic.LoadInt32(&orchestrator.indexesInitialized) == 1
+}
+
+// repositoryIndexIsCreated returns a flag indicating whether the repository index has been created.
+func (orchestrator *Orchestrator) repositoryIndexIsCreated() bool {
+	return atomic.LoadInt32(&orchestrator.repositoryIndexCreated) == 1
+}
+
+// searchIndexIsCreated returns a flag indicating whether the search index has been created.
+func (orchestrator *Orchestrator) searchIndexIsCreated() bool {
+	return atomic.LoadInt32(&orchestrator.fulltextIndexCreated) == 1
 }
 
 // Get the full-page title for a given headline.
@@ -166,6 +252,7 @@ func (orchestrator *Orchestrator) Subscribe(update chan Update) {
 func (orchestrator *Orchestrator) UpdateCache(dataaccessLayerUpdate dataaccess.Update) {
 
 	orchestrator.logger.Info("Received an update. Updating caches: %s", dataaccessLayerUpdate.String())
+	atomic.StoreInt64(&orchestrator.lastUpdate, time.Now().UnixNano())
 
 	// inform subscribers ...
 	// ... about new items
@@ -174,7 +261,7 @@ func (orchestrator *Orchestrator) UpdateCache(dataaccessLayerUpdate dataaccess.U
 		orchestrator.logger.Info("Updating cache for route %q", newItemRoute.String())
 
 		// execute cache update callbacks
-		for _, callbackDefinition := range orchestrator.updateCallbacks[UpdateTypeNew] {
+		for _, callbackDefinition := range orchestrator.getUpdateCallbacks(UpdateTypeNew) {
 			orchestrator.logger.Debug("Executing cache update callback: %q", callbackDefinition.String())
 			if err := callbackDefinition.Execute(newItemRoute); err != nil {
 				orchestrator.logger.Error("%s", err.Error())
@@ -193,7 +280,7 @@ func (orchestrator *Orchestrator) UpdateCache(dataaccessLayerUpdate dataaccess.U
 		orchestrator.logger.Info("Updating cache for route %q", modifiedItemRoute.String())
 
 		// execute cache update callbacks
-		for _, callbackDefinition := range orchestrator.updateCallbacks[UpdateTypeModified] {
+		for _, callbackDefinition := range orchestrator.getUpdateCallbacks(UpdateTypeModified) {
 			orchestrator.logger.Debug("Executing cache update callback: %q", callbackDefinition.String())
 			if err := callbackDefinition.Execute(modifiedItemRoute); err != nil {
 				orchestrator.logger.Error("%s", err.Error())
@@ -212,7 +299,7 @@ func (orchestrator *Orchestrator) UpdateCache(dataaccessLayerUpdate dataaccess.U
 		orchestrator.logger.Info("Removing cache for route %q", deletedItemRoute.String())
 
 		// execute cache update callbacks
-		for _, callbackDefinition := range orchestrator.updateCallbacks[UpdateTypeDeleted] {
+		for _, callbackDefinition := range orchestrator.getUpdateCallbacks(UpdateTypeDeleted) {
 			orchestrator.logger.Debug("Executing cache update callback: %q", callbackDefinition.String())
 			if err := callbackDefinition.Execute(deletedItemRoute); err != nil {
 				orchestrator.logger.Error("%s", err.Error())
@@ -237,6 +324,8 @@ func (orchestrator *Orchestrator) UpdateCache(dataaccessLayerUpdate dataaccess.U
 
 // registerUpdateCallback registers callbacks for new, modified and deleted items.
 func (orchestrator *Orchestrator) registerUpdateCallback(name string, updateType UpdateType, callback func(updatedRoute route.Route)) {
+	orchestrator.updateCallbacksMutex.Lock()
+	defer orchestrator.updateCallbacksMutex.Unlock()
 
 	if orchestrator.updateCallbacks[updateType] == nil {
 		orchestrator.updateCallbacks[updateType] = make([]CacheUpdateCallback, 0)
@@ -245,11 +334,25 @@ func (orchestrator *Orchestrator) registerUpdateCallback(name string, updateType
 	orchestrator.updateCallbacks[updateType] = append(orchestrator.updateCallbacks[updateType], cacheUpdate(name, updateType, callback))
 }
 
+// getUpdateCallbacks returns a copy of the callbacks for the given update type. The callbacks can
+// register further callbacks (e.g. by creating an index on first use) while they are executed.
+func (orchestrator *Orchestrator) getUpdateCallbacks(updateType UpdateType) []CacheUpdateCallback {
+	orchestrator.updateCallbacksMutex.RLock()
+	defer orchestrator.updateCallbacksMutex.RUnlock()
+
+	return append([]CacheUpdateCallback(nil), orchestrator.updateCallbacks[updateType]...)
+}
+
 func (orchestrator *Orchestrator) ItemExists(route route.Route) bool {
 	_, exists := orchestrator.index().IsMatch(route)
 	return exists
 }
 
+// basePath returns the path prefix under which the repository is served (e.g. "/", "/wiki/").
+func (orchestrator *Orchestrator) basePath() string {
+	return orchestrator.config.BasePath()
+}
+
 func (orchestrator *Orchestrator) absolutePather(prefix string) paths.Pather {
 	return orchestrator.webPathProvider.AbsolutePather(prefix)
 }
@@ -309,11 +412,16 @@ func (orchestrator *Orchestrator) getLatestItems(parentRoute route.Route) []*mod
 	return leafes
 }
 
+// index returns the index of all items. The index is created on first use; concurrent callers
+// wait until it has been created.
 func (orchestrator *Orchestrator) index() *index.Index {
+	orchestrator.repositoryIndexInitialization.Do(orchestrator.createIndex)
+	return orchestrator.repositoryIndex
+}
 
-	if orchestrator.repositoryIndex != nil {
-		return orchestrator.repositoryIndex
-	}
+// createIndex parses all items of the repository, adds them to a new index and registers
+// the callbacks which keep the index up to date.
+func (orchestrator *Orchestrator) createIndex() {
 
 	// newItem fetches the item with the given route and adds it to the index.
 	updateItem := func(updatedRoute route.Route) {
@@ -347,76 +455,187 @@ func (orchestrator *Orchestrator) index() *index.Index {
 	}
 
 	// create a new index
+	startTime := time.Now()
 	orchestrator.repositoryIndex = index.New(orchestrator.logger)
 
-	// parse all items
+	// parse all items concurrently and add them to the index in the order of the repository
 	repositoryItems := orchestrator.repository.Items()
-	for _, repositoryItem := range repositoryItems {
-		parsedItem := orchestrator.parseItem(repositoryItem)
+	parsedItems := make([]*model.Item, len(repositoryItems))
+	parseProgress := progress.Start(orchestrator.logger, "Item parsing", "items", len(repositoryItems))
+	parallelutil.ForEach(len(repositoryItems), orchestrator.config.Indexing.NumberOfWorkers(), func(index int) {
+		parsedItems[index] = orchestrator.parseItem(repositoryItems[index])
+		parseProgress.Increment()
+	})
+
+	parseProgress.Finish()
+
+	for index, parsedItem := range parsedItems {
 		if parsedItem == nil {
-			orchestrator.logger.Warn("Unable to parse item %q", repositoryItem.String())
+			orchestrator.logger.Warn("Unable to parse item %q", repositoryItems[index].String())
 			continue
 		}
 
 		orchestrator.repositoryIndex.Add(parsedItem)
 	}
 
+	orchestrator.lastIndexRun.Set(time.Since(startTime), time.Now())
+
 	// register update callbacks
 	orchestrator.registerUpdateCallback("update index", UpdateTypeNew, updateItem)
 	orchestrator.registerUpdateCallback("update index", UpdateTypeModified, updateItem)
 	orchestrator.registerUpdateCallback("update index", UpdateTypeDeleted, deleteItem)
 
-	return orchestrator.repositoryIndex
+	atomic.StoreInt32(&orchestrator.repositoryIndexCreated, 1)
 }
 
-func (orchestrator *Orchestrator) search(keywords string, maxiumNumberOfResults int) []search.Result {
+// search returns at most the given number of search results for the given keywords
+// without the results for items which cannot be shown with the given visibility.
+func (orchestrator *Orchestrator) search(keywords string, maxiumNumberOfResults int, visibility Visibility) []search.Result {
+	if visibility.ShowsAllItems() {
+		return orchestrator.searchIndex().Search(keywords, maxiumNumberOfResults)
+	}
 
-	if orchestrator.fulltextIndex != nil {
-		return orchestrator.fulltextIndex.Search(keywords, maxiumNumberOfResults)
+	results := visibleSearchResults(orchestrator.searchIndex().Search(keywords, orchestrator.searchIndex().Size()), visibility)
+	if len(results) > maxiumNumberOfResults {
+		results = results[:maxiumNumberOfResults]
 	}
 
-	// updateFulltextIndex creates a new full-text index and replaces the existing one.
+	return results
+}
+
+// searchIndex returns the full-text index of all items. The index is created (or loaded from the
+// search index file) on first use and updated whenever items are created, modified or deleted.
+// Concurrent callers wait until the index has been created.
+func (orchestrator *Orchestrator) searchIndex() *search.ItemSearch {
+	orchestrator.fulltextIndexInitialization.Do(orchestrator.createSearchIndex)
+	return orchestrator.fulltextIndex
+}
+
+// createSearchIndex creates the full-text index and registers the callbacks which keep it up to date.
+func (orchestrator *Orchestrator) createSearchIndex() {
+
+	// initialize
+	startTime := time.Now()
+	orchestrator.fulltextIndex = search.NewItemSearch(orchestrator.logger, orchestrator.config.SearchIndexFilePath(), orchestrator.config.SearchLanguage(), getSearchRanking(orchestrator.config.Search.Ranking), orchestrator.getSearchSynonyms(), orchestrator.getTextExtractor(), orchestrator.getAllItems())
+	searchIndexDuration.Observe(time.Since(startTime).Seconds())
+	orchestrator.lastSearchIndexRun.Set(time.Since(startTime), time.Now())
+
+	// close the index on shutdown so that the pending changes are written to disk
+	shutdown.Register(orchestrator.fulltextIndex.Close)
+
+	// updateFulltextIndex indexes the item with the given route.
 	updateFulltextIndex := func(r route.Route) {
-		newFullTextIndex := search.NewItemSearch(orchestrator.logger, orchestrator.getAllItems())
-		orchestrator.fulltextIndex = newFullTextIndex
+		startTime := time.Now()
+
+		item := orchestrator.getItem(r)
+		if item == nil {
+			orchestrator.fulltextIndex.Remove(r)
+			return
+		}
+
+		orchestrator.fulltextIndex.Update(item)
+		searchIndexDuration.Observe(time.Since(startTime).Seconds())
 	}
 
-	// initialize
-	updateFulltextIndex(route.New())
+	// removeFromFulltextIndex removes the item with the given route from the index.
+	removeFromFulltextIndex := func(r route.Route) {
+		orchestrator.fulltextIndex.Remove(r)
+	}
 
 	// register update callbacks
 	orchestrator.registerUpdateCallback("update fulltext index", UpdateTypeNew, updateFulltextIndex)
 	orchestrator.registerUpdateCallback("update fulltext index", UpdateTypeModified, updateFulltextIndex)
-	orchestrator.registerUpdateCallback("update fulltext index", UpdateTypeDeleted, updateFulltextIndex)
+	orchestrator.registerUpdateCallback("update fulltext index", UpdateTypeDeleted, removeFromFulltextIndex)
 
-	return orchestrator.fulltextIndex.Search(keywords, maxiumNumberOfResults)
+	atomic.StoreInt32(&orchestrator.fulltextIndexCreated, 1)
 }
 
-func (orchestrator *Orchestrator) getAllItems() []*model.Item {
+// getSearchSynonyms returns the synonyms from the synonyms file of the repository (if there is one).
+func (orchestrator *Orchestrator) getSearchSynonyms() *search.Synonyms {
+	groups, err := orchestrator.config.Synonyms()
+	if err != nil {
+		orchestrator.logger.Warn("Unable to read the synonyms file %q. Error: %s", orchestrator.config.SynonymsFilePath(), err)
+		return nil
+	}
 
-	allItems := orchestrator.index().GetAllItems()
-	model.SortItemsBy(sortItemsByDate).Sort(allItems)
-	return allItems
+	if len(groups) == 0 {
+		return nil
+	}
+
+	synonymGroups := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		synonymGroups = append(synonymGroups, group)
+	}
+
+	orchestrator.logger.Info("Loaded %d synonym group(s) from %q", len(synonymGroups), orchestrator.config.SynonymsFilePath())
+	return search.NewSynonyms(synonymGroups)
+}
+
+// getTextExtractor returns the text extractor for the attachments of the items; or nil if the
+// attachments are not indexed.
+func (orchestrator *Orchestrator) getTextExtractor() *textextraction.Extractor {
+	attachments := orchestrator.config.Search.Attachments
+	if !attachments.Enabled {
+		return nil
+	}
 
+	return textextraction.New(attachments.MaxFileSize(), attachments.PDFToTextPath)
 }
 
-func (orchestrator *Orchestrator) getItems(pageSize, page int) []*model.Item {
+// getSearchRanking returns the search ranking for the given ranking settings.
+func getSearchRanking(settings config.SearchRanking) search.Ranking {
+	return search.Ranking{
+		TitleBoost:       settings.TitleBoost,
+		DescriptionBoost: settings.DescriptionBoost,
+		TagsBoost:        settings.TagsBoost,
+		AliasesBoost:     settings.AliasesBoost,
+		RouteBoost:       settings.RouteBoost,
+		ContentBoost:     settings.ContentBoost,
+		RecencyBoost:     settings.RecencyBoost,
+		RecencyHalfLife:  time.Duration(settings.RecencyHalfLifeInDays) * 24 * time.Hour,
+	}
+}
 
-	allItems := orchestrator.getAllItems()
+// suggestions returns the prefix index over the titles, aliases and tags of all items. The index is created
+// on first use and updated whenever items are created, modified or deleted.
+func (orchestrator *Orchestrator) suggestions() *search.SuggestionIndex {
 
-	// determine the start index
-	startIndex := pageSize * (page - 1)
-	if startIndex >= len(allItems) {
-		return []*model.Item{}
+	if orchestrator.suggestionIndex != nil {
+		return orchestrator.suggestionIndex
 	}
 
-	// determine the end index
-	endIndex := startIndex + pageSize
-	if endIndex > len(allItems) {
-		endIndex = len(allItems)
+	orchestrator.suggestionIndex = search.NewSuggestionIndex(orchestrator.getAllItems())
+
+	// updateSuggestionIndex updates the suggestions of the item with the given route.
+	updateSuggestionIndex := func(r route.Route) {
+		item := orchestrator.getItem(r)
+		if item == nil {
+			orchestrator.suggestionIndex.Remove(r)
+			return
+		}
+
+		orchestrator.suggestionIndex.Update(item)
+	}
+
+	// removeFromSuggestionIndex removes the suggestions of the item with the given route.
+	removeFromSuggestionIndex := func(r route.Route) {
+		orchestrator.suggestionIndex.Remove(r)
 	}
 
-	return allItems[startIndex:endIndex]
+	// register update callbacks
+	orchestrator.registerUpdateCallback("update suggestion index", UpdateTypeNew, updateSuggestionIndex)
+	orchestrator.registerUpdateCallback("update suggestion index", UpdateTypeModified, updateSuggestionIndex)
+	orchestrator.registerUpdateCallback("update suggestion index", UpdateTypeDeleted, removeFromSuggestionIndex)
+
+	return orchestrator.suggestionIndex
+}
+
+func (orchestrator *Orchestrator) getAllItems() []*model.Item {
+
+	allItems := orchestrator.index().GetAllItems()
+	model.SortItemsBy(sortItemsByDate).Sort(allItems)
+	return allItems
+
 }
 
 func (orchestrator *Orchestrator) getCreationDate(itemRoute route.Route) (creationDate time.Time, found bool) {
@@ -512,8 +731,13 @@ func (orchestrator *Orchestrator) getChildren(route route.Route) []*model.Item {
 	// get all children
 	children := orchestrator.index().GetDirectChildren(route)
 
-	// sort the children by date
-	model.SortItemsBy(sortItemsByDate).Sort(children)
+	// sort the children in the order of the parent or in the configured order
+	order := orchestrator.config.Web.Children.Order()
+	if parent := orchestrator.getItem(route); parent != nil && config.IsChildSortOrder(parent.MetaData.ChildSortOrder) {
+		order = parent.MetaData.ChildSortOrder
+	}
+
+	sortChildren(children, order)
 
 	return children
 }
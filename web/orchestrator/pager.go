@@ -0,0 +1,121 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// PageChildren returns the given view model with only the children on the given page (starting with 1).
+// It returns false if the page does not exist.
+func (orchestrator *ViewModelOrchestrator) PageChildren(viewModel viewmodel.Model, itemsPerPage, page int) (viewmodel.Model, bool) {
+	pageURL := func(page int) string {
+		return fmt.Sprintf("%s?page=%d", orchestrator.itemPather().Path(viewModel.Route), page)
+	}
+
+	startIndex, endIndex, pager, found := getPager(len(viewModel.Children), itemsPerPage, page, pageURL)
+	if !found {
+		return viewModel, false
+	}
+
+	viewModel.Children = viewModel.Children[startIndex:endIndex]
+	viewModel.ChildrenPager = pager
+	return viewModel, true
+}
+
+// GetTagPage returns the tag with the given name with only the items on the given page (starting with 1)
+// which can be shown with the given visibility. It returns false if the tag or the page does not exist.
+func (orchestrator *TagsOrchestrator) GetTagPage(name string, tagsPageURL string, itemsPerPage, page int, visibility Visibility) (viewmodel.Tag, viewmodel.Pager, bool) {
+	for _, tag := range orchestrator.GetTags(visibility) {
+		if tag.Name != name {
+			continue
+		}
+
+		pageURL := func(page int) string {
+			return fmt.Sprintf("%s?tag=%s&page=%d", tagsPageURL, url.QueryEscape(name), page)
+		}
+
+		startIndex, endIndex, pager, found := getPager(len(tag.Children), itemsPerPage, page, pageURL)
+		if !found {
+			return viewmodel.Tag{}, viewmodel.Pager{}, false
+		}
+
+		tag.Children = tag.Children[startIndex:endIndex]
+		return tag, pager, true
+	}
+
+	return viewmodel.Tag{}, viewmodel.Pager{}, false
+}
+
+// GetTruncatedTagTree returns the tag tree with at most itemsPerPage items per tag. Tags with more
+// items link to the first page of their items. Only the items which can be shown with the given visibility are included.
+func (orchestrator *TagsOrchestrator) GetTruncatedTagTree(tagsPageURL string, itemsPerPage int, visibility Visibility) []viewmodel.Tag {
+	return truncateTags(orchestrator.GetTagTree(visibility), itemsPerPage, func(tag viewmodel.Tag) string {
+		return fmt.Sprintf("%s?tag=%s", tagsPageURL, url.QueryEscape(tag.Name))
+	})
+}
+
+// truncateTags returns copies of the given tags and their sub tags with at most itemsPerPage items each.
+// The MoreURL of the truncated tags is set to the URL returned by the given function.
+func truncateTags(tags []viewmodel.Tag, itemsPerPage int, moreURL func(tag viewmodel.Tag) string) []viewmodel.Tag {
+	truncatedTags := make([]viewmodel.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if len(tag.Children) > itemsPerPage {
+			tag.Children = tag.Children[:itemsPerPage]
+			tag.MoreURL = moreURL(tag)
+		}
+
+		tag.SubTags = truncateTags(tag.SubTags, itemsPerPage, moreURL)
+		truncatedTags = append(truncatedTags, tag)
+	}
+
+	return truncatedTags
+}
+
+// getPager returns the start and end index of the given page (starting with 1) of a listing of the given number
+// of items and the pager for the page, whose links are created with the given function. An empty listing has one
+// (empty) page. It returns false if the page does not exist.
+func getPager(numberOfItems, itemsPerPage, page int, pageURL func(page int) string) (startIndex, endIndex int, pager viewmodel.Pager, found bool) {
+	if itemsPerPage < 1 {
+		itemsPerPage = numberOfItems
+	}
+
+	totalPages := 1
+	if itemsPerPage > 0 {
+		totalPages = (numberOfItems + itemsPerPage - 1) / itemsPerPage
+	}
+
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	if page < 1 || page > totalPages {
+		return 0, 0, pager, false
+	}
+
+	startIndex = itemsPerPage * (page - 1)
+	endIndex = startIndex + itemsPerPage
+	if endIndex > numberOfItems {
+		endIndex = numberOfItems
+	}
+
+	pager = viewmodel.Pager{
+		Page:       page,
+		TotalPages: totalPages,
+	}
+
+	if page > 1 {
+		pager.PreviousURL = pageURL(page - 1)
+	}
+
+	if page < totalPages {
+		pager.NextURL = pageURL(page + 1)
+	}
+
+	return startIndex, endIndex, pager, true
+}
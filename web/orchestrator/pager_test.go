@@ -0,0 +1,93 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+func testPageURL(page int) string {
+	return fmt.Sprintf("?page=%d", page)
+}
+
+func Test_getPager_MiddlePage_IndexesAndAdjacentPagesAreReturned(t *testing.T) {
+	// act
+	startIndex, endIndex, pager, found := getPager(25, 10, 2, testPageURL)
+
+	// assert
+	if !found || startIndex != 10 || endIndex != 20 {
+		t.Errorf("getPager should return the items 10 to 20 but returned %d to %d (found: %t).", startIndex, endIndex, found)
+	}
+
+	expected := viewmodel.Pager{Page: 2, TotalPages: 3, PreviousURL: "?page=1", NextURL: "?page=3"}
+	if pager != expected {
+		t.Errorf("getPager should return the pager %v but returned %v.", expected, pager)
+	}
+}
+
+func Test_getPager_LastPage_RemainingItemsAndNoNextPageAreReturned(t *testing.T) {
+	// act
+	startIndex, endIndex, pager, found := getPager(25, 10, 3, testPageURL)
+
+	// assert
+	if !found || startIndex != 20 || endIndex != 25 || pager.NextURL != "" {
+		t.Errorf("getPager should return the items 20 to 25 without a next page but returned %d to %d and %v.", startIndex, endIndex, pager)
+	}
+}
+
+func Test_getPager_EmptyListing_FirstPageExists(t *testing.T) {
+	// act
+	startIndex, endIndex, pager, found := getPager(0, 10, 1, testPageURL)
+
+	// assert
+	if !found || startIndex != 0 || endIndex != 0 || pager.TotalPages != 1 {
+		t.Errorf("getPager should return an empty first page but returned %d to %d and %v (found: %t).", startIndex, endIndex, pager, found)
+	}
+}
+
+func Test_getPager_PageOutOfRange_NotFound(t *testing.T) {
+	for _, page := range []int{0, -1, 4} {
+
+		// act
+		_, _, _, found := getPager(25, 10, page, testPageURL)
+
+		// assert
+		if found {
+			t.Errorf("getPager should not find page %d of 3.", page)
+		}
+	}
+}
+
+func Test_truncateTags_TagsWithManyItems_ItemsAreTruncatedAndMoreURLIsSet(t *testing.T) {
+	// arrange
+	tags := []viewmodel.Tag{
+		{
+			Name:     "project",
+			Children: make([]viewmodel.Model, 3),
+			SubTags: []viewmodel.Tag{
+				{Name: "project/alpha", Children: make([]viewmodel.Model, 1)},
+			},
+		},
+	}
+
+	// act
+	result := truncateTags(tags, 2, func(tag viewmodel.Tag) string { return "?tag=" + tag.Name })
+
+	// assert
+	if len(result[0].Children) != 2 || result[0].MoreURL != "?tag=project" {
+		t.Errorf("truncateTags should truncate the items of %q and set its MoreURL but returned %v.", "project", result[0])
+	}
+
+	if len(result[0].SubTags[0].Children) != 1 || result[0].SubTags[0].MoreURL != "" {
+		t.Errorf("truncateTags should not truncate the items of %q but returned %v.", "project/alpha", result[0].SubTags[0])
+	}
+
+	if len(tags[0].Children) != 3 {
+		t.Errorf("truncateTags must not modify the given tags.")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/paths"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/services/thumbnail"
+)
+
+// previewImageFieldName is the meta data field which selects the preview image of an item (e.g. "image: files/cover.jpg").
+const previewImageFieldName = "image"
+
+// getPreviewImagePath returns the path of the image which is displayed in the previews of shared links to the
+// given item (e.g. "/thumbnails/105-D6134C1B-1024-768.png"): the largest thumbnail of its preview image or the
+// image itself if there are no thumbnails. It returns an empty string if the item has no images.
+func (orchestrator *Orchestrator) getPreviewImagePath(item *model.Item) string {
+	image := getPreviewImage(item)
+	if image == nil {
+		return ""
+	}
+
+	pathProvider := orchestrator.absolutePather(orchestrator.basePath())
+	if thumbnailPath, exists := getLargestThumbnailPath(orchestrator.thumbnailIndex, pathProvider, image); exists {
+		return thumbnailPath
+	}
+
+	return orchestrator.itemPather().Path(image.Route().Value())
+}
+
+// getPreviewImage returns the image file which is selected by the "image" meta data field of the given item
+// (e.g. "files/cover.jpg") or its first image file if it selects none; nil if the item has no image files.
+func getPreviewImage(item *model.Item) *model.File {
+	var firstImage *model.File
+	selectedImage := strings.Trim(strings.ToLower(item.MetaData.Fields[previewImageFieldName]), "/ ")
+
+	for _, file := range item.Files() {
+		if !model.IsImageFile(file) {
+			continue
+		}
+
+		if fileRoute := strings.ToLower(file.Route().Value()); selectedImage != "" && (fileRoute == selectedImage || strings.HasSuffix(fileRoute, "/"+selectedImage)) {
+			return file
+		}
+
+		if firstImage == nil {
+			firstImage = file
+		}
+	}
+
+	return firstImage
+}
+
+// getLargestThumbnailPath returns the path of the largest thumbnail of the given image file.
+func getLargestThumbnailPath(thumbnailIndex *thumbnail.Index, pathProvider paths.Pather, file *model.File) (thumbnailPath string, exists bool) {
+	if thumbnailIndex == nil {
+		return "", false
+	}
+
+	thumbs, exists := thumbnailIndex.GetThumbs(file.Route().Value())
+	if !exists {
+		return "", false
+	}
+
+	for _, dimensions := range []thumbnail.ThumbDimension{thumbnail.SizeLarge, thumbnail.SizeMedium, thumbnail.SizeSmall} {
+		if thumb, exists := thumbs.GetThumbBySize(dimensions); exists {
+			return pathProvider.Path(thumb.ThumbRoute().Value()), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+)
+
+// testFile is a file without content with the given route and mime type.
+type testFile struct {
+	route    route.Route
+	mimeType string
+}
+
+func (file testFile) Data(contentReader func(content io.ReadSeeker) error) error { return nil }
+func (file testFile) Hash() (string, error)                                      { return "", nil }
+func (file testFile) LastModified() (time.Time, error)                           { return time.Time{}, nil }
+func (file testFile) MimeType() (string, error)                                  { return file.mimeType, nil }
+func (file testFile) String() string                                             { return file.route.String() }
+func (file testFile) Id() string                                                 { return file.route.Value() }
+func (file testFile) Name() string                                               { return file.route.LastComponentName() }
+func (file testFile) Route() route.Route                                         { return file.route }
+func (file testFile) Parent() route.Route {
+	parent, _ := file.route.Parent()
+	return parent
+}
+
+func newTestFile(path, mimeType string) *model.File {
+	return &model.File{File: testFile{route: route.NewFromRequest(path), mimeType: mimeType}}
+}
+
+func Test_getPreviewImage_NoImageSelected_FirstImageIsReturned(t *testing.T) {
+	// arrange
+	item := model.NewItem(route.NewFromRequest("document"), []*model.File{
+		newTestFile("document/files/notes.txt", "text/plain"),
+		newTestFile("document/files/first.png", "image/png"),
+		newTestFile("document/files/second.jpg", "image/jpeg"),
+	}, 0)
+
+	// act
+	image := getPreviewImage(item)
+
+	// assert
+	if image == nil || image.Route().Value() != "document/files/first.png" {
+		t.Errorf("The first image should be the preview image but the result was %v.", image)
+	}
+}
+
+func Test_getPreviewImage_ImageSelectedByMetaData_SelectedImageIsReturned(t *testing.T) {
+	// arrange
+	item := model.NewItem(route.NewFromRequest("document"), []*model.File{
+		newTestFile("document/files/first.png", "image/png"),
+		newTestFile("document/files/cover.jpg", "image/jpeg"),
+		newTestFile("document/files/discover.jpg", "image/jpeg"),
+	}, 0)
+	item.MetaData.Fields = map[string]string{"image": "files/Cover.jpg"}
+
+	// act
+	image := getPreviewImage(item)
+
+	// assert
+	if image == nil || image.Route().Value() != "document/files/cover.jpg" {
+		t.Errorf("The selected image should be the preview image but the result was %v.", image)
+	}
+}
+
+func Test_getPreviewImage_NoImages_NilIsReturned(t *testing.T) {
+	// arrange
+	item := model.NewItem(route.NewFromRequest("document"), []*model.File{
+		newTestFile("document/files/notes.txt", "text/plain"),
+	}, 0)
+
+	// act
+	image := getPreviewImage(item)
+
+	// assert
+	if image != nil {
+		t.Errorf("An item without images should not have a preview image but the result was %v.", image)
+	}
+}
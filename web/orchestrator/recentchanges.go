@@ -0,0 +1,63 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// RecentChangesOrchestrator provides the most recently modified items.
+type RecentChangesOrchestrator struct {
+	*Orchestrator
+}
+
+// GetRecentChanges returns at most limit of the most recently modified items grouped by the day of their
+// modification (the most recent day first). Items which cannot be shown with the given visibility are skipped.
+func (orchestrator *RecentChangesOrchestrator) GetRecentChanges(limit int, visibility Visibility) []viewmodel.RecentChangesDay {
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	days := make([]viewmodel.RecentChangesDay, 0)
+	for _, item := range getRecentlyModifiedItems(visibleItems(orchestrator.getAllItems(), visibility), limit) {
+		baseModel := getBaseModel(rootItem, item, orchestrator.config)
+		baseModel.Route = orchestrator.itemPather().Path(item.Route().Value())
+
+		date := getFeedUpdateTime(item).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, viewmodel.RecentChangesDay{Date: date})
+		}
+
+		days[len(days)-1].Items = append(days[len(days)-1].Items, baseModel)
+	}
+
+	return days
+}
+
+// getRecentlyModifiedItems returns at most limit of the given items ordered by their modification date (the most
+// recently modified first). Items without a date and items with the noindex flag are skipped.
+func getRecentlyModifiedItems(items []*model.Item, limit int) []*model.Item {
+	modifiedItems := make([]*model.Item, 0, len(items))
+	for _, item := range items {
+		if item.MetaData.NoIndex || getFeedUpdateTime(item).IsZero() {
+			continue
+		}
+
+		modifiedItems = append(modifiedItems, item)
+	}
+
+	model.SortItemsBy(func(item1, item2 *model.Item) bool {
+		return getFeedUpdateTime(item1).After(getFeedUpdateTime(item2))
+	}).Sort(modifiedItems)
+
+	if len(modifiedItems) > limit {
+		modifiedItems = modifiedItems[:limit]
+	}
+
+	return modifiedItems
+}
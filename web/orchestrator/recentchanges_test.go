@@ -0,0 +1,58 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+)
+
+func newTestItem(path string, created, modified time.Time) *model.Item {
+	item := model.NewItem(route.NewFromRequest(path), nil, 0)
+	item.MetaData.CreationDate = created
+	item.MetaData.LastModifiedDate = modified
+	return item
+}
+
+func Test_getRecentlyModifiedItems_ItemsWithDifferentDates_MostRecentlyModifiedItemsAreReturnedFirst(t *testing.T) {
+	// arrange
+	day := func(number int) time.Time { return time.Date(2015, 8, number, 12, 0, 0, 0, time.UTC) }
+	items := []*model.Item{
+		newTestItem("old", day(1), day(2)),
+		newTestItem("new", day(1), day(9)),
+		newTestItem("created", day(5), time.Time{}),
+		newTestItem("undated", time.Time{}, time.Time{}),
+	}
+
+	// act
+	result := getRecentlyModifiedItems(items, 10)
+
+	// assert
+	if len(result) != 3 || result[0].Route().Value() != "new" || result[1].Route().Value() != "created" || result[2].Route().Value() != "old" {
+		t.Errorf("getRecentlyModifiedItems should return the items [new created old] but returned %v.", result)
+	}
+}
+
+func Test_getRecentlyModifiedItems_MoreItemsThanTheLimit_OnlyTheLimitIsReturned(t *testing.T) {
+	// arrange
+	modified := time.Date(2015, 8, 3, 12, 0, 0, 0, time.UTC)
+	items := []*model.Item{
+		newTestItem("a", modified, modified),
+		newTestItem("b", modified, modified.Add(time.Hour)),
+		newTestItem("c", modified, modified.Add(2*time.Hour)),
+	}
+	items[2].MetaData.NoIndex = true
+
+	// act
+	result := getRecentlyModifiedItems(items, 1)
+
+	// assert
+	if len(result) != 1 || result[0].Route().Value() != "b" {
+		t.Errorf("getRecentlyModifiedItems should only return the item %q but returned %v.", "b", result)
+	}
+}
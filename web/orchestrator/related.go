@@ -0,0 +1,101 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/services/related"
+)
+
+// relatedItemIndex finds the related items of the items of the repository.
+type relatedItemIndex struct {
+	index  *related.Index
+	routes map[string]route.Route
+}
+
+// Related returns the routes of at most limit items which are related to the item with the given route,
+// the most closely related first.
+func (relatedItems *relatedItemIndex) Related(itemRoute route.Route, limit int) []route.Route {
+	relatedRoutes := make([]route.Route, 0, limit)
+	for _, match := range relatedItems.index.Related(route.ToKey(itemRoute), limit) {
+		if relatedRoute, exists := relatedItems.routes[match.Key]; exists {
+			relatedRoutes = append(relatedRoutes, relatedRoute)
+		}
+	}
+
+	return relatedRoutes
+}
+
+// relatedItems returns the index of the related items. The index is created on first use and rebuilt on every update.
+func (orchestrator *Orchestrator) relatedItems() *relatedItemIndex {
+
+	if orchestrator.relatedIndex != nil {
+		return orchestrator.relatedIndex
+	}
+
+	// the related items depend on the link graph, so its update callbacks must be registered first
+	orchestrator.links()
+
+	// updateRelatedIndex creates a new index of the related items and replaces the existing one.
+	updateRelatedIndex := func(r route.Route) {
+		orchestrator.relatedIndex = orchestrator.newRelatedIndex(orchestrator.getAllItems())
+	}
+
+	// initialize
+	updateRelatedIndex(route.New())
+
+	// register update callbacks
+	orchestrator.registerUpdateCallback("update related items", UpdateTypeNew, updateRelatedIndex)
+	orchestrator.registerUpdateCallback("update related items", UpdateTypeModified, updateRelatedIndex)
+	orchestrator.registerUpdateCallback("update related items", UpdateTypeDeleted, updateRelatedIndex)
+
+	return orchestrator.relatedIndex
+}
+
+// newRelatedIndex creates an index of the related items from the tags and the link graph of the given items.
+func (orchestrator *Orchestrator) newRelatedIndex(items []*model.Item) *relatedItemIndex {
+	graph := orchestrator.links()
+
+	routes := make(map[string]route.Route, len(items))
+	relatedItems := make([]related.Item, 0, len(items))
+	for _, item := range items {
+		routes[route.ToKey(item.Route())] = item.Route()
+
+		links := make([]string, 0)
+		for _, linkedRoute := range graph.Links(item.Route()) {
+			links = append(links, route.ToKey(linkedRoute))
+		}
+
+		relatedItems = append(relatedItems, related.Item{
+			Key:   route.ToKey(item.Route()),
+			Tags:  item.MetaData.Tags,
+			Links: links,
+		})
+	}
+
+	return &relatedItemIndex{
+		index:  related.NewIndex(relatedItems),
+		routes: routes,
+	}
+}
+
+// getRelatedItems returns the items which are most closely related to the item with the given route;
+// it returns nothing if the related items are disabled.
+func (orchestrator *Orchestrator) getRelatedItems(itemRoute route.Route) []*model.Item {
+	limit := orchestrator.config.Web.RelatedItems.Limit()
+	if limit == 0 {
+		return nil
+	}
+
+	relatedItems := make([]*model.Item, 0, limit)
+	for _, relatedRoute := range orchestrator.relatedItems().Related(itemRoute, limit) {
+		if relatedItem := orchestrator.getItem(relatedRoute); relatedItem != nil {
+			relatedItems = append(relatedItems, relatedItem)
+		}
+	}
+
+	return relatedItems
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/budget"
+	"github.com/andreaskoch/allmark/common/paths"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/orchestrator/htmlcache"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// conversionMemoryFactor is the estimated number of bytes which are needed to convert a byte of markdown.
+const conversionMemoryFactor = 8
+
+// convert returns the HTML of the given item with all paths provided by the given path provider.
+// If the HTML cache is enabled the HTML is only converted again if the content or the files of the
+// item or one of the items it references by alias have changed.
+func (orchestrator *Orchestrator) convert(pathProvider paths.Pather, item *model.Item) (string, error) {
+	if orchestrator.htmlCache == nil {
+		return orchestrator.convertWithinBudget(orchestrator.getItemByAlias, pathProvider, item)
+	}
+
+	itemRoute := route.ToKey(item.Route())
+	key := orchestrator.htmlCache.Key(
+		itemRoute,
+		pathProvider.Path(item.Route().Value()),
+		getTextHash(item.Content),
+		orchestrator.getFilesFingerprint(item))
+
+	if entry, exists := orchestrator.htmlCache.Get(itemRoute, key); exists {
+		if orchestrator.referencesAreUnchanged(entry.References) {
+			cacheRequests.Inc("html", cacheHit)
+			return entry.HTML, nil
+		}
+
+		orchestrator.htmlCache.Remove(itemRoute, key)
+	}
+
+	cacheRequests.Inc("html", cacheMiss)
+
+	// record the items which are referenced by alias
+	references := make(map[string]string)
+	aliasResolver := func(alias string) *model.Item {
+		referencedItem := orchestrator.getItemByAlias(alias)
+		references[alias] = getReferenceFingerprint(referencedItem)
+		return referencedItem
+	}
+
+	html, err := orchestrator.convertWithinBudget(aliasResolver, pathProvider, item)
+	if err != nil {
+		return "", err
+	}
+
+	orchestrator.htmlCache.Set(key, htmlcache.Entry{
+		Route:      itemRoute,
+		HTML:       html,
+		References: references,
+	})
+
+	return html, nil
+}
+
+// convertWithinBudget converts the given item as soon as the memory budget of the background tasks permits it.
+func (orchestrator *Orchestrator) convertWithinBudget(aliasResolver func(alias string) *model.Item, pathProvider paths.Pather, item *model.Item) (string, error) {
+	release := budget.Acquire(budget.TaskConversion, int64(len(item.Content))*conversionMemoryFactor)
+	defer release()
+
+	return orchestrator.converter.Convert(aliasResolver, pathProvider, item)
+}
+
+// invalidateHTML removes the cached HTML of the item with the given route.
+func (orchestrator *Orchestrator) invalidateHTML(updatedRoute route.Route) {
+	orchestrator.htmlCache.Invalidate(route.ToKey(updatedRoute))
+}
+
+// referencesAreUnchanged returns true if the items with the given aliases still have the given fingerprints.
+func (orchestrator *Orchestrator) referencesAreUnchanged(references map[string]string) bool {
+	for alias, fingerprint := range references {
+		if getReferenceFingerprint(orchestrator.getItemByAlias(alias)) != fingerprint {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getFilesFingerprint returns a fingerprint of the files of the given item and their thumbnails
+// (the image code of the HTML changes as soon as the thumbnails of an image are available).
+func (orchestrator *Orchestrator) getFilesFingerprint(item *model.Item) string {
+	hash := sha256.New()
+	for _, file := range item.Files() {
+		lastModified, _ := file.LastModified()
+		fmt.Fprintf(hash, "%s\n%s\n", file.Route().Value(), lastModified.Format(time.RFC3339Nano))
+
+		if orchestrator.thumbnailIndex != nil {
+			thumbs, _ := orchestrator.thumbnailIndex.GetThumbs(file.Route().Value())
+			fmt.Fprintf(hash, "%d\n", len(thumbs))
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// getReferenceFingerprint returns the attributes of the given item which are used when it is referenced
+// by its alias (an empty string if there is no item).
+func getReferenceFingerprint(item *model.Item) string {
+	if item == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s\n%s", item.Route().Value(), item.Title)
+}
+
+// getTextHash returns the SHA-256 hash of the given text.
+func getTextHash(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(hash[:])
+}
@@ -5,42 +5,54 @@
 package orchestrator
 
 import (
+	"github.com/andreaskoch/allmark/model"
 	"github.com/andreaskoch/allmark/web/orchestrator/search"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"strings"
 )
 
 var (
-	itemsPerPage = 50
+	// itemsPerPage is the default number of search results per page; maximumItemsPerPage the largest page size that can be requested.
+	itemsPerPage        = 50
+	maximumItemsPerPage = 100
+
+	// searchSnippetLength is the maximum number of characters of the snippets of the search results.
+	searchSnippetLength = 240
 )
 
 type SearchOrchestrator struct {
 	*Orchestrator
 }
 
-func (orchestrator *SearchOrchestrator) GetSearchResults(keywords string, page int) viewmodel.SearchResults {
+// GetSearchResults returns the given page of the results for the given keywords. Page sizes which
+// are not positive or larger than the maximum page size are replaced by the default page size.
+// Items which cannot be shown with the given visibility are not included.
+func (orchestrator *SearchOrchestrator) GetSearchResults(keywords string, page, pageSize int, visibility Visibility) viewmodel.SearchResults {
 
 	// validate page number
 	if page < 1 {
 		orchestrator.logger.Fatal("Invalid page number (%v).", page)
 	}
 
+	if pageSize < 1 || pageSize > maximumItemsPerPage {
+		pageSize = itemsPerPage
+	}
+
 	// determine start item
-	startItemNumber := itemsPerPage * (page - 1)
+	startItemNumber := pageSize * (page - 1)
 
 	// determine end item
-	endItemNumber := itemsPerPage * page
+	endItemNumber := pageSize * page
 
 	// collect the search results
 	searchResultModels := make([]viewmodel.SearchResult, 0)
 
-	maximumNumberOfResults := 100
 	totalResultCount := 0
 
 	if strings.TrimSpace(keywords) != "" {
 
 		// execute the search
-		searchResults := orchestrator.search(keywords, maximumNumberOfResults)
+		searchResults := orchestrator.search(keywords, orchestrator.searchIndex().Size(), visibility)
 
 		// count the number of search results
 		totalResultCount = len(searchResults)
@@ -58,14 +70,29 @@ func (orchestrator *SearchOrchestrator) GetSearchResults(keywords string, page i
 
 	}
 
+	totalPages := (totalResultCount + pageSize - 1) / pageSize
+
+	previousPage := 0
+	if page > 1 && page <= totalPages {
+		previousPage = page - 1
+	}
+
+	nextPage := 0
+	if page < totalPages {
+		nextPage = page + 1
+	}
+
 	return viewmodel.SearchResults{
 		Query:   keywords,
 		Results: searchResultModels,
 
 		Page:         page,
-		ItemsPerPage: itemsPerPage,
+		ItemsPerPage: pageSize,
+		TotalPages:   totalPages,
+		PreviousPage: previousPage,
+		NextPage:     nextPage,
 
-		StartIndex:       getStartIndex(itemsPerPage, page),
+		StartIndex:       getStartIndex(pageSize, page),
 		ResultCount:      len(searchResultModels),
 		TotalResultCount: totalResultCount,
 	}
@@ -81,7 +108,7 @@ func (orchestrator *SearchOrchestrator) createSearchResultModel(searchResult sea
 	// item location
 	location := orchestrator.itemPather().Path(item.Route().Value())
 
-	return viewmodel.SearchResult{
+	resultModel := viewmodel.SearchResult{
 		Index: searchResult.Number,
 
 		Title:       item.Title,
@@ -89,6 +116,27 @@ func (orchestrator *SearchOrchestrator) createSearchResultModel(searchResult sea
 		Route:       location,
 		Path:        item.Route().OriginalValue(),
 	}
+
+	// matches in attached files link to the file
+	if file := getMatchedFile(item, searchResult); file != nil {
+		resultModel.File = file.Name()
+		resultModel.FileRoute = orchestrator.itemPather().Path(file.Route().Value())
+		resultModel.Snippet = orchestrator.searchIndex().GetFileSnippet(searchResult.File, searchResult.Terms, searchSnippetLength).HTML()
+	} else {
+		resultModel.Snippet = orchestrator.searchIndex().GetItemSnippet(item, searchResult.Terms, searchSnippetLength).HTML()
+	}
+
+	return resultModel
+}
+
+// getMatchedFile returns the attached file of the given item whose text matched the query of the given search result;
+// or nil if the item itself matched.
+func getMatchedFile(item *model.Item, searchResult search.Result) *model.File {
+	if searchResult.File.Value() == "" {
+		return nil
+	}
+
+	return item.GetFile(searchResult.File)
 }
 
 func getStartIndex(itemsPerPage, pageNumber int) int {
@@ -0,0 +1,199 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	unicodeTokenizer "github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/registry"
+	"strings"
+	"unicode"
+
+	// the language analyzers of bleve (stop words and stemmers)
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/ar"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/ckb"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/da"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/de"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/en"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/es"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/fa"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/fi"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/fr"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/hi"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/hr"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/hu"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/it"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/nl"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/no"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/pt"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/ro"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/ru"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/sv"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/tr"
+)
+
+// analyzerType is the bleve analyzer type which analyzes the texts of a language (see newLanguageAnalyzer).
+const analyzerType = "allmark"
+
+// cjkLanguage is the analyzer language of Chinese, Japanese and Korean texts.
+const cjkLanguage = "cjk"
+
+// supportedLanguages contains the languages which have a bleve analyzer with stop words and a stemmer.
+var supportedLanguages = []string{"ar", "ckb", "da", "de", "en", "es", "fa", "fi", "fr", "hi", "hr", "hu", "it", "nl", "no", "pt", "ro", "ru", "sv", "tr", cjkLanguage}
+
+// cjkLanguages contains the languages whose texts are split into bigrams because their words are not separated by spaces.
+var cjkLanguages = map[string]bool{"zh": true, "ja": true, "ko": true}
+
+func init() {
+	registry.RegisterAnalyzer(analyzerType, newLanguageAnalyzer)
+}
+
+// getAnalyzerLanguage returns the analyzer language for the given language code (e.g. "de" or "de-CH").
+// It returns an empty string for languages without stemmer and stop words whose texts are only split into lower-case terms.
+func getAnalyzerLanguage(language string) string {
+	primaryLanguage, _, _ := strings.Cut(strings.ToLower(language), "-")
+	if cjkLanguages[primaryLanguage] {
+		return cjkLanguage
+	}
+
+	for _, supportedLanguage := range supportedLanguages {
+		if supportedLanguage == primaryLanguage {
+			return primaryLanguage
+		}
+	}
+
+	return ""
+}
+
+// getAnalyzerName returns the name under which the analyzer of the given analyzer language is defined in the index mapping.
+func getAnalyzerName(language string) string {
+	if language == "" {
+		return analyzerType + "-plain"
+	}
+
+	return analyzerType + "-" + language
+}
+
+// languageAnalyzer analyzes a text with the bleve analyzer of a language and applies the given filters to the terms.
+type languageAnalyzer struct {
+	analyzer analysis.Analyzer
+	filters  []analysis.TokenFilter
+}
+
+func (languageAnalyzer *languageAnalyzer) Analyze(input []byte) analysis.TokenStream {
+	tokens := languageAnalyzer.analyzer.Analyze(input)
+	for _, filter := range languageAnalyzer.filters {
+		tokens = filter.Filter(tokens)
+	}
+
+	return tokens
+}
+
+// newLanguageAnalyzer creates the analyzer for the "language" of the given config. Chinese, Japanese and Korean
+// characters are split into bigrams in the texts of all languages and the synonym groups of the "synonyms"
+// entry of the config (if any) are indexed along with the terms they are synonyms of.
+func newLanguageAnalyzer(config map[string]interface{}, cache *registry.Cache) (analysis.Analyzer, error) {
+	language, _ := config["language"].(string)
+
+	analyzer, err := getBaseAnalyzer(language, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	synonymGroups := getSynonymGroups(config["synonyms"])
+	if len(synonymGroups) == 0 {
+		return analyzer, nil
+	}
+
+	return &languageAnalyzer{
+		analyzer: analyzer,
+		filters:  []analysis.TokenFilter{newSynonymFilter(synonymGroups, analyzer)},
+	}, nil
+}
+
+// getBaseAnalyzer returns the analyzer for the given language without synonyms.
+func getBaseAnalyzer(language string, cache *registry.Cache) (analysis.Analyzer, error) {
+	if language == cjkLanguage {
+		return cache.AnalyzerNamed(cjk.AnalyzerName)
+	}
+
+	bigramFilter, err := cache.TokenFilterNamed(cjk.BigramName)
+	if err != nil {
+		return nil, err
+	}
+
+	if language != "" {
+		analyzer, err := cache.AnalyzerNamed(language)
+		if err != nil {
+			return nil, err
+		}
+
+		return &languageAnalyzer{analyzer, []analysis.TokenFilter{bigramFilter}}, nil
+	}
+
+	tokenizer, err := cache.TokenizerNamed(unicodeTokenizer.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	lowercaseFilter, err := cache.TokenFilterNamed(lowercase.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &analysis.DefaultAnalyzer{
+		Tokenizer:    tokenizer,
+		TokenFilters: []analysis.TokenFilter{lowercaseFilter, bigramFilter},
+	}, nil
+}
+
+// plainAnalyzer splits texts into lower-case terms without stemming them (e.g. for the snippets of GetSnippet).
+var plainAnalyzer = mustGetBaseAnalyzer("")
+
+// mustGetBaseAnalyzer returns the analyzer for the given language without synonyms and panics if it cannot be created.
+func mustGetBaseAnalyzer(language string) analysis.Analyzer {
+	analyzer, err := getBaseAnalyzer(language, registry.NewCache())
+	if err != nil {
+		panic(err)
+	}
+
+	return analyzer
+}
+
+// analyze returns the terms of the given text. Terms which are indexed at the same position as a preceding
+// term (e.g. synonyms) are omitted.
+func analyze(analyzer analysis.Analyzer, text string) []string {
+	terms := make([]string, 0)
+
+	previousPosition := 0
+	for _, token := range analyzer.Analyze([]byte(text)) {
+		if token.Position == previousPosition {
+			continue
+		}
+
+		terms = append(terms, string(token.Term))
+		previousPosition = token.Position
+	}
+
+	return terms
+}
+
+// tokenize splits the given text into lower-case words. Words are sequences of letters and digits;
+// all other characters separate the words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+// isSeparator returns true if the given character is not part of a word.
+func isSeparator(character rune) bool {
+	return !unicode.IsLetter(character) && !unicode.IsDigit(character) && !unicode.IsMark(character)
+}
+
+// isCJK returns true if the given character belongs to a script whose words are not separated by spaces.
+func isCJK(character rune) bool {
+	return unicode.In(character, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
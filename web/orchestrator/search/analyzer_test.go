@@ -0,0 +1,96 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_tokenize_LatinText_TextIsSplitIntoLowerCaseWords(t *testing.T) {
+	// act
+	words := tokenize("Hello, World! It's 2015.")
+
+	// assert
+	expected := []string{"hello", "world", "it", "s", "2015"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("The words should be %v but are %v.", expected, words)
+	}
+}
+
+func Test_analyze_CJKText_TextIsSplitIntoBigrams(t *testing.T) {
+	// act
+	terms := analyze(plainAnalyzer, "東京都に住む Go言語")
+
+	// assert
+	expected := []string{"東京", "京都", "都に", "に住", "住む", "go", "言語"}
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("The terms should be %v but are %v.", expected, terms)
+	}
+}
+
+func Test_analyze_SingleCJKCharacter_CharacterIsATerm(t *testing.T) {
+	// act
+	terms := analyze(plainAnalyzer, "猫 cat")
+
+	// assert
+	expected := []string{"猫", "cat"}
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("The terms should be %v but are %v.", expected, terms)
+	}
+}
+
+func Test_analyze_LanguageAnalyzers_InflectedFormsHaveTheSameStem(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		language string
+		words    []string
+	}{
+		{"en", []string{"connect", "connected", "connecting", "connects"}},
+		{"de", []string{"Häuser", "Hauses", "Haus"}},
+		{"fr", []string{"chevaux", "cheval"}},
+		{"es", []string{"libros", "libro"}},
+	}
+
+	for _, input := range inputs {
+		analyzer := mustGetBaseAnalyzer(input.language)
+
+		// act
+		stems := make(map[string]bool)
+		for _, word := range input.words {
+			for _, stem := range analyze(analyzer, word) {
+				stems[stem] = true
+			}
+		}
+
+		// assert
+		if len(stems) != 1 {
+			t.Errorf("The %s words %v should have one stem but have %v.", input.language, input.words, stems)
+		}
+	}
+}
+
+func Test_getAnalyzerLanguage(t *testing.T) {
+	// arrange
+	inputs := map[string]string{
+		"en":    "en",
+		"de-CH": "de",
+		"FR":    "fr",
+		"ja":    cjkLanguage,
+		"zh-TW": cjkLanguage,
+		"xx":    "",
+		"":      "",
+	}
+
+	for language, expected := range inputs {
+		// act
+		result := getAnalyzerLanguage(language)
+
+		// assert
+		if result != expected {
+			t.Errorf("The analyzer language of %q should be %q but is %q.", language, expected, result)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewCursor returns an opaque continuation token for the search results that start at the given offset.
+// The token is only valid for a search with the same parameters (e.g. the query and the filters).
+func NewCursor(offset int, parameters ...string) string {
+	token := fmt.Sprintf("%d:%s", offset, getCursorKey(parameters))
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// ParseCursor returns the offset of the given continuation token (see NewCursor). It returns an error
+// if the token is malformed or has been created for a search with other parameters.
+func ParseCursor(cursor string, parameters ...string) (int, error) {
+	token, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("The cursor is invalid.")
+	}
+
+	offsetText, key, isValid := strings.Cut(string(token), ":")
+	offset, err := strconv.Atoi(offsetText)
+	if !isValid || err != nil || offset < 0 {
+		return 0, fmt.Errorf("The cursor is invalid.")
+	}
+
+	if key != getCursorKey(parameters) {
+		return 0, fmt.Errorf("The cursor belongs to another search.")
+	}
+
+	return offset, nil
+}
+
+// getCursorKey returns a short hash of the given search parameters.
+func getCursorKey(parameters []string) string {
+	hash := sha1.New()
+	for _, parameter := range parameters {
+		hash.Write([]byte(parameter))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
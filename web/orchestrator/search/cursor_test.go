@@ -0,0 +1,55 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"testing"
+)
+
+func Test_ParseCursor_CursorOfTheSameSearch_OffsetIsReturned(t *testing.T) {
+	// arrange
+	cursor := NewCursor(40, "markdown", "tag:go")
+
+	// act
+	offset, err := ParseCursor(cursor, "markdown", "tag:go")
+
+	// assert
+	if err != nil {
+		t.Fatalf("ParseCursor returned an error: %s", err)
+	}
+
+	if offset != 40 {
+		t.Errorf("ParseCursor should return the offset 40 but returned %d.", offset)
+	}
+}
+
+func Test_ParseCursor_CursorOfAnotherSearch_ErrorIsReturned(t *testing.T) {
+	// arrange
+	cursor := NewCursor(40, "markdown")
+
+	// act
+	_, err := ParseCursor(cursor, "allmark")
+
+	// assert
+	if err == nil {
+		t.Errorf("ParseCursor should return an error for the cursor of another search.")
+	}
+}
+
+func Test_ParseCursor_MalformedCursor_ErrorIsReturned(t *testing.T) {
+	// arrange
+	inputs := []string{"", "not a cursor!", "MTA", "LTE6YWJj"}
+
+	for _, input := range inputs {
+
+		// act
+		_, err := ParseCursor(input, "markdown")
+
+		// assert
+		if err == nil {
+			t.Errorf("ParseCursor(%q) should return an error.", input)
+		}
+	}
+}
@@ -0,0 +1,837 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/index/scorch"
+	"github.com/blevesearch/bleve/v2/mapping"
+	bleveSearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	bleveIndex "github.com/blevesearch/bleve_index_api"
+	bolt "go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// indexFormatVersion changes whenever the index mapping, the document IDs or the analysis of the texts changes.
+	// Stored indizes with another version are rebuilt.
+	indexFormatVersion = 8
+
+	// settingsKey is the key under which the settings an index has been created with are stored in the index.
+	settingsKey = "allmark-settings"
+
+	// openTimeout is the time to wait for an index which is locked by another process.
+	openTimeout = "1s"
+)
+
+const (
+	// exactMatchWeight, prefixMatchWeight and fuzzyMatchWeight are the factors by which the scores
+	// of the index terms are multiplied depending on how they match a query term.
+	exactMatchWeight  = 1.0
+	prefixMatchWeight = 0.7
+	fuzzyMatchWeight  = 0.5
+
+	// minimumPrefixLength is the number of characters a query term must have to match longer index terms.
+	minimumPrefixLength = 2
+)
+
+// field identifies the part of an item in which a term occurs.
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldDescription
+	fieldTags
+	fieldAliases
+	fieldRoute
+	fieldContent
+
+	numberOfFields
+)
+
+// allFields contains all fields of a document.
+var allFields = []field{fieldTitle, fieldDescription, fieldTags, fieldAliases, fieldRoute, fieldContent}
+
+// fieldNames contains the names of the text fields in the index.
+var fieldNames = [numberOfFields]string{"title", "description", "tags", "aliases", "route", "content"}
+
+// The names of the attribute fields in the index.
+const (
+	itemFieldName        = "item"
+	textFieldName        = "text"
+	fingerprintFieldName = "fingerprint"
+	languageFieldName    = "language"
+	tagFieldName         = "tag"
+	typeFieldName        = "type"
+	dateFieldName        = "date"
+	modifiedFieldName    = "modified"
+)
+
+// Document is the indexed representation of a repository item or of an attached file.
+type Document struct {
+	// Route is the route of the item or file.
+	Route string
+
+	// Item is the route of the item a file is attached to ("" for items).
+	Item string
+
+	// Text is the text which has been extracted from an attached file ("" for items).
+	Text string
+
+	// Fingerprint identifies the indexed texts; items whose fingerprint did not change are not indexed again.
+	Fingerprint string
+
+	// Texts contains the indexed texts by field. It is not stored in the index.
+	Texts map[field]string
+
+	// Language is the analyzer language of the texts ("" if the texts are not stemmed; see getAnalyzerLanguage).
+	Language string
+
+	// Tags (lower-case), Type and Date are the attributes by which queries can filter the documents.
+	Tags []string
+	Type string
+	Date time.Time
+
+	// Modified is the last modification date by which recent documents are boosted.
+	Modified time.Time
+}
+
+// newDocument creates a new document for the item with the given route from the given field texts
+// which are analyzed in the given analyzer language.
+func newDocument(route, fingerprint, language string, texts map[field]string) *Document {
+	return &Document{
+		Route:       route,
+		Fingerprint: fingerprint,
+		Language:    language,
+		Texts:       texts,
+	}
+}
+
+// getFields returns the fields of the document in the form in which they are passed to bleve.
+func (document *Document) getFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"_type":              document.Language,
+		fingerprintFieldName: document.Fingerprint,
+		tagFieldName:         document.Tags,
+		typeFieldName:        document.Type,
+	}
+
+	for field, text := range document.Texts {
+		fields[fieldNames[field]] = text
+	}
+
+	if document.Item != "" {
+		fields[itemFieldName] = document.Item
+	}
+
+	if document.Text != "" {
+		fields[textFieldName] = document.Text
+	}
+
+	if document.Language != "" {
+		fields[languageFieldName] = document.Language
+	}
+
+	if !document.Date.IsZero() {
+		fields[dateFieldName] = float64(document.Date.Unix())
+	}
+
+	if !document.Modified.IsZero() {
+		fields[modifiedFieldName] = float64(document.Modified.Unix())
+	}
+
+	return fields
+}
+
+// hit is a document which matches a query.
+type hit struct {
+	route string
+	score float64
+
+	// item is the route of the item an attached file belongs to ("" for items)
+	item string
+
+	// terms are the (sorted) index terms of the document which matched the query
+	terms []string
+
+	date time.Time
+}
+
+// changes contains the documents which are added to and removed from the index at once (see Apply).
+type changes struct {
+	added   []*Document
+	removed []string
+
+	// size is the number of bytes of the texts of the added documents
+	size int64
+}
+
+// Add adds the given document to the changes.
+func (changes *changes) Add(document *Document) {
+	changes.added = append(changes.added, document)
+	changes.size += int64(len(document.Text))
+	for _, text := range document.Texts {
+		changes.size += int64(len(text))
+	}
+}
+
+// Remove adds the document with the given route to the documents which are removed.
+func (changes *changes) Remove(route string) {
+	changes.removed = append(changes.removed, route)
+}
+
+// IsEmpty returns true if there are no changes.
+func (changes *changes) IsEmpty() bool {
+	return len(changes.added) == 0 && len(changes.removed) == 0
+}
+
+// openIndex opens the bleve index in the given directory or creates it if it doesn't exist yet. Indizes which
+// have been created with another format or other synonyms are rebuilt. If no directory is given the index is only kept in memory.
+func openIndex(directory string, synonyms *Synonyms) (*index, error) {
+	settings := fmt.Sprintf("%d:%s", indexFormatVersion, synonyms.Fingerprint())
+	indexMapping := newIndexMapping(synonyms)
+
+	if directory == "" {
+		bleveIndex, err := bleve.NewMemOnly(indexMapping)
+		if err != nil {
+			return nil, err
+		}
+
+		return newIndex(bleveIndex), nil
+	}
+
+	// search indizes of previous versions have been stored in a single file
+	if info, err := os.Stat(directory); err == nil && !info.IsDir() {
+		if err := os.Remove(directory); err != nil {
+			return nil, err
+		}
+	}
+
+	runtimeConfig := map[string]interface{}{"bolt_timeout": openTimeout}
+
+	existingIndex, err := bleve.OpenUsing(directory, runtimeConfig)
+	switch {
+	case errors.Is(err, bolt.ErrTimeout):
+		return nil, fmt.Errorf("The index is used by another process.")
+
+	case err == nil:
+		storedSettings, err := existingIndex.GetInternal([]byte(settingsKey))
+		if err == nil && string(storedSettings) == settings {
+			return newIndex(existingIndex), nil
+		}
+
+		existingIndex.Close()
+		fallthrough
+
+	case !errors.Is(err, bleve.ErrorIndexPathDoesNotExist):
+		if err := os.RemoveAll(directory); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(directory), 0700); err != nil {
+		return nil, err
+	}
+
+	createdIndex, err := bleve.NewUsing(directory, indexMapping, scorch.Name, scorch.Name, runtimeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := createdIndex.SetInternal([]byte(settingsKey), []byte(settings)); err != nil {
+		createdIndex.Close()
+		return nil, err
+	}
+
+	return newIndex(createdIndex), nil
+}
+
+// newIndexMapping returns the bleve mapping of the documents. The text fields of the documents are analyzed
+// by the analyzer of their language which indexes the given synonyms along with the terms.
+func newIndexMapping(synonyms *Synonyms) *mapping.IndexMappingImpl {
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.StoreDynamic = false
+	indexMapping.IndexDynamic = false
+	indexMapping.DocValuesDynamic = false
+
+	for _, language := range append([]string{""}, supportedLanguages...) {
+		analyzerName := getAnalyzerName(language)
+		indexMapping.AddCustomAnalyzer(analyzerName, map[string]interface{}{
+			"type":     analyzerType,
+			"language": language,
+			"synonyms": synonyms.getConfig(),
+		})
+
+		if language == "" {
+			indexMapping.DefaultAnalyzer = analyzerName
+			indexMapping.DefaultMapping = newDocumentMapping(analyzerName)
+			continue
+		}
+
+		indexMapping.AddDocumentMapping(language, newDocumentMapping(analyzerName))
+	}
+
+	return indexMapping
+}
+
+// newDocumentMapping returns the mapping of the documents whose texts are analyzed by the given analyzer.
+func newDocumentMapping(analyzerName string) *mapping.DocumentMapping {
+	documentMapping := bleve.NewDocumentStaticMapping()
+	documentMapping.DefaultAnalyzer = analyzerName
+
+	for _, name := range fieldNames {
+		textMapping := bleve.NewTextFieldMapping()
+		textMapping.Analyzer = analyzerName
+		textMapping.IncludeInAll = false
+		documentMapping.AddFieldMappingsAt(name, textMapping)
+	}
+
+	// attributes
+	for _, name := range []string{itemFieldName, languageFieldName, tagFieldName, typeFieldName} {
+		keywordMapping := bleve.NewKeywordFieldMapping()
+		keywordMapping.Analyzer = keyword.Name
+		keywordMapping.Store = name == itemFieldName || name == languageFieldName
+		keywordMapping.IncludeInAll = false
+		keywordMapping.IncludeTermVectors = false
+		documentMapping.AddFieldMappingsAt(name, keywordMapping)
+	}
+
+	for _, name := range []string{dateFieldName, modifiedFieldName} {
+		dateMapping := bleve.NewNumericFieldMapping()
+		dateMapping.IncludeInAll = false
+		documentMapping.AddFieldMappingsAt(name, dateMapping)
+	}
+
+	// stored values
+	for _, name := range []string{fingerprintFieldName, textFieldName} {
+		storedMapping := bleve.NewTextFieldMapping()
+		storedMapping.Index = false
+		storedMapping.IncludeInAll = false
+		storedMapping.IncludeTermVectors = false
+		storedMapping.DocValues = false
+		documentMapping.AddFieldMappingsAt(name, storedMapping)
+	}
+
+	return documentMapping
+}
+
+// newIndex creates a new index for the given bleve index.
+func newIndex(bleveIndex bleve.Index) *index {
+	return &index{
+		bleveIndex: bleveIndex,
+		ranking:    DefaultRanking(),
+	}
+}
+
+// index is a bleve full-text index of documents. It is safe for concurrent use.
+type index struct {
+	bleveIndex bleve.Index
+
+	// ranking defines the weights of the fields and of the age of the documents
+	rankingMutex sync.RWMutex
+	ranking      Ranking
+
+	// languages contains the analyzer languages of the indexed documents; it is determined on demand after changes
+	languagesMutex sync.Mutex
+	languages      []string
+}
+
+// Size returns the number of documents in the index.
+func (index *index) Size() int {
+	size, err := index.bleveIndex.DocCount()
+	if err != nil {
+		return 0
+	}
+
+	return int(size)
+}
+
+// getDocumentID returns the ID of the document with the given route. bleve rejects empty IDs
+// and the route of the root item is empty, so all routes are prefixed with a slash.
+func getDocumentID(route string) string {
+	return "/" + route
+}
+
+// getDocumentRoute returns the route of the document with the given ID (see getDocumentID).
+func getDocumentRoute(documentID string) string {
+	return strings.TrimPrefix(documentID, "/")
+}
+
+// Close closes the index.
+func (index *index) Close() error {
+	return index.bleveIndex.Close()
+}
+
+// Fingerprint returns the fingerprint of the document with the given route; or false if there is no such document.
+func (index *index) Fingerprint(route string) (string, bool) {
+	document, exists := index.Document(route)
+	if !exists {
+		return "", false
+	}
+
+	return document.Fingerprint, true
+}
+
+// Document returns the stored attributes of the document with the given route; or false if there is no such document.
+// The texts of the fields are not stored in the index.
+func (index *index) Document(route string) (*Document, bool) {
+	storedDocument, err := index.bleveIndex.Document(getDocumentID(route))
+	if err != nil || storedDocument == nil {
+		return nil, false
+	}
+
+	document := &Document{Route: route}
+	storedDocument.VisitFields(func(storedField bleveIndex.Field) {
+		value := string(storedField.Value())
+		switch storedField.Name() {
+		case itemFieldName:
+			document.Item = value
+		case textFieldName:
+			document.Text = value
+		case fingerprintFieldName:
+			document.Fingerprint = value
+		case languageFieldName:
+			document.Language = value
+		}
+	})
+
+	return document, true
+}
+
+// Attachments returns the routes of the documents of the files which are attached to the item with the given route.
+func (index *index) Attachments(itemRoute string) []string {
+	attachmentQuery := bleve.NewTermQuery(itemRoute)
+	attachmentQuery.SetField(itemFieldName)
+
+	routes := make([]string, 0)
+	for _, documentHit := range index.find(attachmentQuery) {
+		routes = append(routes, getDocumentRoute(documentHit.ID))
+	}
+
+	return routes
+}
+
+// Items returns the routes of all documents with the route of the item they are attached to ("" for items).
+func (index *index) Items() map[string]string {
+	items := make(map[string]string)
+	for _, documentHit := range index.find(bleve.NewMatchAllQuery(), itemFieldName) {
+		itemRoute, _ := documentHit.Fields[itemFieldName].(string)
+		items[getDocumentRoute(documentHit.ID)] = itemRoute
+	}
+
+	return items
+}
+
+// find returns all documents which match the given query with the given stored fields.
+func (index *index) find(findQuery query.Query, fields ...string) []*bleveSearch.DocumentMatch {
+	request := bleve.NewSearchRequestOptions(findQuery, index.Size(), 0, false)
+	request.Fields = fields
+	request.SortBy([]string{"_id"})
+
+	result, err := index.bleveIndex.Search(request)
+	if err != nil {
+		return nil
+	}
+
+	return result.Hits
+}
+
+// SetRanking sets the weights by which the matches are ranked. Unset factors are replaced by the defaults.
+func (index *index) SetRanking(ranking Ranking) {
+	index.rankingMutex.Lock()
+	defer index.rankingMutex.Unlock()
+
+	index.ranking = ranking.withDefaults()
+}
+
+// getRanking returns the weights by which the matches are ranked.
+func (index *index) getRanking() Ranking {
+	index.rankingMutex.RLock()
+	defer index.rankingMutex.RUnlock()
+
+	return index.ranking
+}
+
+// Add adds the given document to the index. An existing document with the same route is replaced.
+func (index *index) Add(document *Document) error {
+	changes := &changes{}
+	changes.Add(document)
+	return index.Apply(changes)
+}
+
+// Remove removes the document with the given route from the index.
+func (index *index) Remove(route string) error {
+	changes := &changes{}
+	changes.Remove(route)
+	return index.Apply(changes)
+}
+
+// Apply adds and removes the documents of the given changes in a single batch.
+func (index *index) Apply(changes *changes) error {
+	if changes.IsEmpty() {
+		return nil
+	}
+
+	batch := index.bleveIndex.NewBatch()
+	for _, route := range changes.removed {
+		batch.Delete(getDocumentID(route))
+	}
+
+	for _, document := range changes.added {
+		if err := batch.Index(getDocumentID(document.Route), document.getFields()); err != nil {
+			return err
+		}
+	}
+
+	index.languagesMutex.Lock()
+	index.languages = nil
+	index.languagesMutex.Unlock()
+
+	return index.bleveIndex.Batch(batch)
+}
+
+// getLanguages returns the analyzer languages of the indexed documents and the plain language ("").
+func (index *index) getLanguages() ([]string, error) {
+	index.languagesMutex.Lock()
+	defer index.languagesMutex.Unlock()
+
+	if index.languages != nil {
+		return index.languages, nil
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), 0, 0, false)
+	request.AddFacet(languageFieldName, bleve.NewFacetRequest(languageFieldName, len(supportedLanguages)))
+
+	result, err := index.bleveIndex.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := []string{""}
+	if facet, exists := result.Facets[languageFieldName]; exists && facet.Terms != nil {
+		for _, term := range facet.Terms.Terms() {
+			languages = append(languages, term.Term)
+		}
+	}
+
+	index.languages = languages
+	return languages, nil
+}
+
+// SearchQuery returns the documents which match the given query in one of the given fields (best matches first).
+// The keywords of the query also match the index terms which start with them and the index terms which differ
+// from them by a few typos; these matches contribute less to the score. The scores of the fields are weighted
+// with the boosts of the ranking and recently modified documents are ranked higher. Queries that only consist of
+// filters return all documents that pass the filters (newest first).
+func (index *index) SearchQuery(searchQuery Query, fields []field) ([]hit, error) {
+	languages, err := index.getLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	ranking := index.getRanking()
+	boosts := ranking.getFieldBoosts()
+
+	booleanQuery := bleve.NewBooleanQuery()
+
+	// keywords
+	keywordQueries := make([]query.Query, 0)
+	for _, term := range searchQuery.Terms {
+		if termQuery := index.newTermQuery(term, languages, fields, boosts); termQuery != nil {
+			keywordQueries = append(keywordQueries, termQuery)
+		}
+	}
+
+	hasKeywords := len(keywordQueries) > 0
+	if hasKeywords {
+		booleanQuery.AddMust(bleve.NewDisjunctionQuery(keywordQueries...))
+	}
+
+	for _, phrase := range searchQuery.Phrases {
+		if phraseQuery := index.newPhraseQuery(phrase, languages, fields, boosts); phraseQuery != nil {
+			booleanQuery.AddMust(phraseQuery)
+			hasKeywords = true
+		}
+	}
+
+	if !hasKeywords && !searchQuery.hasFilters() {
+		return []hit{}, nil
+	}
+
+	// filters
+	for _, tag := range searchQuery.Tags {
+		booleanQuery.AddMust(newAttributeQuery(tagFieldName, tag))
+	}
+
+	for _, tag := range searchQuery.ExcludedTags {
+		booleanQuery.AddMustNot(newAttributeQuery(tagFieldName, tag))
+	}
+
+	if len(searchQuery.Types) > 0 {
+		typeQueries := make([]query.Query, 0, len(searchQuery.Types))
+		for _, itemType := range searchQuery.Types {
+			typeQueries = append(typeQueries, newAttributeQuery(typeFieldName, itemType))
+		}
+
+		booleanQuery.AddMust(bleve.NewDisjunctionQuery(typeQueries...))
+	}
+
+	for _, itemType := range searchQuery.ExcludedTypes {
+		booleanQuery.AddMustNot(newAttributeQuery(typeFieldName, itemType))
+	}
+
+	if !searchQuery.Before.IsZero() || !searchQuery.After.IsZero() {
+		booleanQuery.AddMust(newDateRangeQuery(searchQuery.After, searchQuery.Before))
+	}
+
+	for _, term := range searchQuery.ExcludedTerms {
+		if termQuery := index.newPhraseQuery([]string{term}, languages, fields, boosts); termQuery != nil {
+			booleanQuery.AddMustNot(termQuery)
+		}
+	}
+
+	for _, phrase := range searchQuery.ExcludedPhrases {
+		if phraseQuery := index.newPhraseQuery(phrase, languages, fields, boosts); phraseQuery != nil {
+			booleanQuery.AddMustNot(phraseQuery)
+		}
+	}
+
+	if !hasKeywords {
+		booleanQuery.AddMust(bleve.NewMatchAllQuery())
+	}
+
+	request := bleve.NewSearchRequestOptions(booleanQuery, index.Size(), 0, false)
+	request.Fields = []string{itemFieldName, dateFieldName, modifiedFieldName}
+	request.IncludeLocations = true
+
+	result, err := index.bleveIndex.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := getCurrentTime()
+	hits := make([]hit, 0, len(result.Hits))
+	for _, documentHit := range result.Hits {
+		documentRoute := getDocumentRoute(documentHit.ID)
+		if len(searchQuery.Paths) > 0 && !matchesAnyPath(searchQuery.Paths, documentRoute) {
+			continue
+		}
+
+		if matchesAnyPath(searchQuery.ExcludedPaths, documentRoute) {
+			continue
+		}
+
+		itemRoute, _ := documentHit.Fields[itemFieldName].(string)
+		score := 0.0
+		if hasKeywords {
+			// recently modified documents are ranked higher
+			score = documentHit.Score * ranking.getRecencyFactor(getStoredDate(documentHit.Fields[modifiedFieldName]), now)
+		}
+
+		hits = append(hits, hit{
+			route: documentRoute,
+			score: score,
+			item:  itemRoute,
+			terms: getMatchedTerms(documentHit.Locations, fields),
+			date:  getStoredDate(documentHit.Fields[dateFieldName]),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+
+		if !hits[i].date.Equal(hits[j].date) {
+			return hits[i].date.After(hits[j].date)
+		}
+
+		return hits[i].route < hits[j].route
+	})
+
+	return hits, nil
+}
+
+// newTermQuery returns a query which matches the documents that contain the given query term in one of the
+// given fields in one of the given analyzer languages; or nil if the term is a stop word in all languages.
+// The term also matches the index terms which start with it and the index terms which are only a few typos
+// away from it. Terms which are split into several index terms (e.g. Chinese words) must occur as a phrase.
+func (index *index) newTermQuery(term string, languages []string, fields []field, boosts [numberOfFields]float64) query.Query {
+	termQueries := make([]query.Query, 0)
+
+	stems := make(map[string]bool)
+	for _, language := range languages {
+		indexTerms := analyze(index.getAnalyzer(language), term)
+		if len(indexTerms) != 1 {
+			if phraseQuery := index.newPhraseQuery([]string{term}, []string{language}, fields, boosts); phraseQuery != nil {
+				termQueries = append(termQueries, phraseQuery)
+			}
+
+			continue
+		}
+
+		stem := indexTerms[0]
+		if stems[stem] {
+			continue
+		}
+
+		stems[stem] = true
+
+		length := utf8.RuneCountInString(stem)
+		maximumEdits := getMaximumEdits(length)
+
+		for _, field := range fields {
+			exactQuery := bleve.NewTermQuery(stem)
+			exactQuery.SetField(fieldNames[field])
+			exactQuery.SetBoost(boosts[field] * exactMatchWeight)
+			termQueries = append(termQueries, exactQuery)
+
+			// single CJK characters match the bigrams which start with them
+			if length >= minimumPrefixLength || (length == 1 && isCJK([]rune(stem)[0])) {
+				prefixQuery := bleve.NewPrefixQuery(stem)
+				prefixQuery.SetField(fieldNames[field])
+				prefixQuery.SetBoost(boosts[field] * prefixMatchWeight)
+				termQueries = append(termQueries, prefixQuery)
+			}
+
+			if maximumEdits > 0 {
+				fuzzyQuery := bleve.NewFuzzyQuery(stem)
+				fuzzyQuery.SetField(fieldNames[field])
+				fuzzyQuery.SetFuzziness(maximumEdits)
+				fuzzyQuery.SetBoost(boosts[field] * fuzzyMatchWeight)
+				termQueries = append(termQueries, fuzzyQuery)
+			}
+		}
+	}
+
+	if len(termQueries) == 0 {
+		return nil
+	}
+
+	return bleve.NewDisjunctionQuery(termQueries...)
+}
+
+// newPhraseQuery returns a query which matches the documents that contain the given words one after another
+// in one of the given fields in one of the given analyzer languages; or nil if the words are stop words in all languages.
+func (index *index) newPhraseQuery(words []string, languages []string, fields []field, boosts [numberOfFields]float64) query.Query {
+	phrase := strings.Join(words, " ")
+
+	phraseQueries := make([]query.Query, 0)
+	for _, language := range languages {
+		if len(analyze(index.getAnalyzer(language), phrase)) == 0 {
+			continue
+		}
+
+		for _, field := range fields {
+			phraseQuery := bleve.NewMatchPhraseQuery(phrase)
+			phraseQuery.SetField(fieldNames[field])
+			phraseQuery.Analyzer = getAnalyzerName(language)
+			phraseQuery.SetBoost(boosts[field])
+			phraseQueries = append(phraseQueries, phraseQuery)
+		}
+	}
+
+	if len(phraseQueries) == 0 {
+		return nil
+	}
+
+	return bleve.NewDisjunctionQuery(phraseQueries...)
+}
+
+// getAnalyzer returns the analyzer of the given analyzer language.
+func (index *index) getAnalyzer(language string) analysis.Analyzer {
+	return index.bleveIndex.Mapping().AnalyzerNamed(getAnalyzerName(language))
+}
+
+// newAttributeQuery returns a query which matches the documents which have the given (lower-case) value in the given attribute field.
+func newAttributeQuery(fieldName, value string) query.Query {
+	attributeQuery := bleve.NewTermQuery(value)
+	attributeQuery.SetField(fieldName)
+	return attributeQuery
+}
+
+// newDateRangeQuery returns a query which matches the documents whose date is between the given dates (exclusive).
+// Zero dates are not applied.
+func newDateRangeQuery(after, before time.Time) query.Query {
+	var minimum, maximum *float64
+	if !after.IsZero() {
+		value := float64(after.Unix())
+		minimum = &value
+	}
+
+	if !before.IsZero() {
+		value := float64(before.Unix())
+		maximum = &value
+	}
+
+	exclusive := false
+	dateQuery := bleve.NewNumericRangeInclusiveQuery(minimum, maximum, &exclusive, &exclusive)
+	dateQuery.SetField(dateFieldName)
+	return dateQuery
+}
+
+// getStoredDate returns the date of the given stored date field value.
+func getStoredDate(value interface{}) time.Time {
+	seconds, isNumber := value.(float64)
+	if !isNumber {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(seconds), 0)
+}
+
+// getMatchedTerms returns the (sorted) index terms which matched in the given fields.
+func getMatchedTerms(locations bleveSearch.FieldTermLocationMap, fields []field) []string {
+	terms := make([]string, 0)
+	for _, field := range fields {
+		for term := range locations[fieldNames[field]] {
+			terms = append(terms, term)
+		}
+	}
+
+	terms = uniqueTerms(terms)
+	sort.Strings(terms)
+	return terms
+}
+
+// getMaximumEdits returns the number of typos that are tolerated in a query term with the given number of characters:
+// none for terms with up to three characters, one for terms with up to seven characters and two for longer terms.
+func getMaximumEdits(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	}
+
+	return 2
+}
+
+// uniqueTerms returns the given terms without duplicates.
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term == "" || seen[term] {
+			continue
+		}
+
+		seen[term] = true
+		unique = append(unique, term)
+	}
+
+	return unique
+}
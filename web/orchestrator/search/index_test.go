@@ -0,0 +1,387 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestIndex creates an in-memory index with the given synonyms which contains the given documents.
+func newTestIndex(t *testing.T, synonyms *Synonyms, documents ...*Document) *index {
+	index, err := openIndex("", synonyms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { index.Close() })
+
+	for _, document := range documents {
+		if err := index.Add(document); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return index
+}
+
+// searchIndex returns the hits of the given query in the given fields of the given index.
+func searchIndex(t *testing.T, index *index, text string, fields []field) []hit {
+	hits, err := index.SearchQuery(ParseQuery(text), fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hits
+}
+
+func Test_indexSearch_TermOccursMoreOften_DocumentIsRankedHigher(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("a", "1", "", map[field]string{fieldContent: "markdown is a markup language for the web"}),
+		newDocument("b", "2", "", map[field]string{fieldContent: "markdown markdown markdown everywhere"}),
+		newDocument("c", "3", "", map[field]string{fieldContent: "something completely different"}))
+
+	// act
+	hits := searchIndex(t, index, "markdown", allFields)
+
+	// assert
+	if len(hits) != 2 {
+		t.Fatalf("The search should have returned 2 hits but returned %d.", len(hits))
+	}
+
+	if hits[0].route != "b" {
+		t.Errorf("The document with more occurrences of the term should be ranked first but %q was.", hits[0].route)
+	}
+}
+
+func Test_indexSearch_DocumentRemoved_DocumentIsNotFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil, newDocument("a", "1", "", map[field]string{fieldTitle: "Markdown"}))
+	index.Remove("a")
+
+	// act
+	hits := searchIndex(t, index, "markdown", allFields)
+
+	// assert
+	if len(hits) != 0 {
+		t.Errorf("The removed document should not be found but the search returned %d hits.", len(hits))
+	}
+
+	if index.Size() != 0 {
+		t.Errorf("The index should be empty but contains %d documents.", index.Size())
+	}
+}
+
+func Test_indexSearch_RootItem_DocumentIsFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("", "1", "", map[field]string{fieldTitle: "Markdown"}),
+		newDocument("documents", "2", "", map[field]string{fieldTitle: "Documents"}))
+
+	// act
+	hits := searchIndex(t, index, "markdown", allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "" {
+		t.Fatalf("The search should have returned the root item but returned %v.", hits)
+	}
+
+	if _, exists := index.Fingerprint(""); !exists {
+		t.Errorf("The fingerprint of the root item should be stored.")
+	}
+
+	if items := index.Items(); len(items) != 2 {
+		t.Errorf("The index should contain 2 documents but contains %v.", items)
+	}
+}
+
+func Test_indexSearch_TermOnlyInOtherField_DocumentIsNotFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil, newDocument("a", "1", "", map[field]string{fieldTitle: "projects", fieldRoute: "notes"}))
+
+	// act
+	hits := searchIndex(t, index, "projects", []field{fieldRoute})
+
+	// assert
+	if len(hits) != 0 {
+		t.Errorf("The search should only match the route field but returned %d hits.", len(hits))
+	}
+}
+
+func Test_openIndex_ExistingIndex_DocumentsAreRestored(t *testing.T) {
+	// arrange
+	directory := filepath.Join(t.TempDir(), "search.index")
+
+	storedIndex, err := openIndex(directory, nil)
+	if err != nil {
+		t.Fatalf("The index could not be created: %s", err)
+	}
+
+	storedIndex.Add(newDocument("docs/sample", "fingerprint", "en", map[field]string{fieldContent: "golang testing"}))
+	storedIndex.Close()
+
+	// act
+	restoredIndex, err := openIndex(directory, nil)
+
+	// assert
+	if err != nil {
+		t.Fatalf("The index could not be opened: %s", err)
+	}
+
+	defer restoredIndex.Close()
+
+	if fingerprint, _ := restoredIndex.Fingerprint("docs/sample"); fingerprint != "fingerprint" {
+		t.Errorf("The restored document should have the fingerprint %q but has %q.", "fingerprint", fingerprint)
+	}
+
+	if hits := searchIndex(t, restoredIndex, "golang", allFields); len(hits) != 1 {
+		t.Errorf("The restored index should return 1 hit but returned %d.", len(hits))
+	}
+}
+
+func Test_openIndex_OtherSynonyms_IndexIsRebuilt(t *testing.T) {
+	// arrange
+	directory := filepath.Join(t.TempDir(), "search.index")
+
+	storedIndex, err := openIndex(directory, nil)
+	if err != nil {
+		t.Fatalf("The index could not be created: %s", err)
+	}
+
+	storedIndex.Add(newDocument("a", "1", "en", map[field]string{fieldContent: "Setting up a Kubernetes cluster"}))
+	storedIndex.Close()
+
+	// act
+	rebuiltIndex, err := openIndex(directory, NewSynonyms([][]string{{"k8s", "kubernetes"}}))
+
+	// assert
+	if err != nil {
+		t.Fatalf("The index could not be opened: %s", err)
+	}
+
+	defer rebuiltIndex.Close()
+
+	if rebuiltIndex.Size() != 0 {
+		t.Errorf("The index should have been rebuilt for the new synonyms but contains %d documents.", rebuiltIndex.Size())
+	}
+}
+
+func Test_openIndex_IndexFileOfPreviousVersion_IndexIsCreated(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "search.index")
+	if err := os.WriteFile(path, []byte("gob"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	createdIndex, err := openIndex(path, nil)
+
+	// assert
+	if err != nil {
+		t.Fatalf("The index could not be created: %s", err)
+	}
+
+	defer createdIndex.Close()
+
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		t.Errorf("The index file should have been replaced by the index directory.")
+	}
+}
+
+func Test_indexSearch_TermWithTypo_DocumentIsFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil, newDocument("a", "1", "", map[field]string{fieldTitle: "Markdown"}))
+
+	// act
+	hits := searchIndex(t, index, "markdwon", allFields)
+
+	// assert
+	if len(hits) != 1 {
+		t.Fatalf("The search should have returned 1 hit but returned %d.", len(hits))
+	}
+
+	if len(hits[0].terms) != 1 || hits[0].terms[0] != "markdown" {
+		t.Errorf("The hit should contain the matched index term %q but contains %v.", "markdown", hits[0].terms)
+	}
+}
+
+func Test_indexSearch_PartialTerm_DocumentIsFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil, newDocument("a", "1", "", map[field]string{fieldTitle: "Documentation"}))
+
+	// act
+	hits := searchIndex(t, index, "doc", allFields)
+
+	// assert
+	if len(hits) != 1 {
+		t.Fatalf("The search should have returned 1 hit but returned %d.", len(hits))
+	}
+}
+
+func Test_indexSearch_ExactAndFuzzyMatches_ExactMatchIsRankedHigher(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("a", "1", "", map[field]string{fieldTitle: "shorts"}),
+		newDocument("b", "2", "", map[field]string{fieldTitle: "sports"}))
+
+	// act
+	hits := searchIndex(t, index, "sports", allFields)
+
+	// assert
+	if len(hits) != 2 {
+		t.Fatalf("The search should have returned 2 hits but returned %d.", len(hits))
+	}
+
+	if hits[0].route != "b" {
+		t.Errorf("The exact match should be ranked first but %q was.", hits[0].route)
+	}
+}
+
+func Test_indexSearchQuery_Phrase_OnlyDocumentsWithThePhraseAreFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("a", "1", "", map[field]string{fieldContent: "getting started with markdown"}),
+		newDocument("b", "2", "", map[field]string{fieldContent: "started getting markdown"}))
+
+	// act
+	hits := searchIndex(t, index, `"getting started"`, allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "a" {
+		t.Errorf("The search should have returned the document a but returned %v.", hits)
+	}
+}
+
+func Test_indexSearchQuery_FiltersAndNegation_OnlyMatchingDocumentsAreFound(t *testing.T) {
+	// arrange
+	documentA := newDocument("notes/a", "1", "", map[field]string{fieldContent: "markdown notes"})
+	documentA.Tags = []string{"project"}
+	documentA.Type = "document"
+	documentA.Date = time.Date(2019, 5, 1, 0, 0, 0, 0, time.Local)
+
+	documentB := newDocument("notes/b", "2", "", map[field]string{fieldContent: "markdown draft"})
+	documentB.Tags = []string{"project"}
+	documentB.Type = "document"
+	documentB.Date = time.Date(2019, 6, 1, 0, 0, 0, 0, time.Local)
+
+	documentC := newDocument("talks/c", "3", "", map[field]string{fieldContent: "markdown slides"})
+	documentC.Tags = []string{"project"}
+	documentC.Type = "presentation"
+	documentC.Date = time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+
+	index := newTestIndex(t, nil, documentA, documentB, documentC)
+
+	// act
+	hits := searchIndex(t, index, "markdown tag:project type:document before:2020-01-01 path:/notes/** -draft", allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "notes/a" {
+		t.Errorf("The search should have returned the document notes/a but returned %v.", hits)
+	}
+}
+
+func Test_indexSearchQuery_OnlyFilters_AllMatchingDocumentsAreFoundNewestFirst(t *testing.T) {
+	// arrange
+	documentA := newDocument("a", "1", "", map[field]string{fieldContent: "first"})
+	documentA.Tags = []string{"project"}
+	documentA.Date = time.Date(2019, 5, 1, 0, 0, 0, 0, time.Local)
+
+	documentB := newDocument("b", "2", "", map[field]string{fieldContent: "second"})
+	documentB.Tags = []string{"project"}
+	documentB.Date = time.Date(2020, 5, 1, 0, 0, 0, 0, time.Local)
+
+	index := newTestIndex(t, nil, documentA, documentB, newDocument("c", "3", "", map[field]string{fieldContent: "third"}))
+
+	// act
+	hits := searchIndex(t, index, "tag:project", allFields)
+
+	// assert
+	if len(hits) != 2 || hits[0].route != "b" || hits[1].route != "a" {
+		t.Errorf("The search should have returned the documents b and a but returned %v.", hits)
+	}
+}
+
+func Test_indexSearch_InflectedForm_StemmedDocumentIsFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil, newDocument("a", "1", "de", map[field]string{fieldContent: "Die Häuser der Stadt"}))
+
+	// act
+	hits := searchIndex(t, index, "hauses", allFields)
+
+	// assert
+	if len(hits) != 1 {
+		t.Fatalf("The search should have returned 1 hit but returned %d.", len(hits))
+	}
+}
+
+func Test_indexSearch_StopWords_StopWordsAreIgnored(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("a", "1", "en", map[field]string{fieldContent: "the guide"}),
+		newDocument("b", "2", "en", map[field]string{fieldContent: "the markdown reference"}))
+
+	// act
+	hits := searchIndex(t, index, "the markdown", allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "b" {
+		t.Errorf("The search should only have returned the document b but returned %v.", hits)
+	}
+}
+
+func Test_indexSearchQuery_CJKWord_OnlyDocumentsWithTheWordAreFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("tokyo", "1", "", map[field]string{fieldContent: "東京都庁は新宿にあります"}),
+		newDocument("kyoto", "2", "", map[field]string{fieldContent: "京都は古い都です"}))
+
+	// act
+	hits := searchIndex(t, index, "東京都", allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "tokyo" {
+		t.Errorf("The search should only have returned the document tokyo but returned %v.", hits)
+	}
+}
+
+func Test_indexSearch_SingleCJKCharacter_DocumentsWithBigramsAreFound(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil, newDocument("a", "1", "", map[field]string{fieldContent: "新宿駅"}))
+
+	// act
+	hits := searchIndex(t, index, "新", allFields)
+
+	// assert
+	if len(hits) != 1 {
+		t.Errorf("The search should have returned 1 hit but returned %d.", len(hits))
+	}
+}
+
+func Test_indexSearch_AttachedFile_HitReferencesTheItem(t *testing.T) {
+	// arrange
+	attachment := newDocument("notes/files/report.pdf", "1", "", map[field]string{fieldTitle: "report.pdf", fieldContent: "quarterly revenue"})
+	attachment.Item = "notes"
+	index := newTestIndex(t, nil, newDocument("notes", "2", "", map[field]string{fieldTitle: "Notes"}), attachment)
+
+	// act
+	hits := searchIndex(t, index, "revenue", allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "notes/files/report.pdf" || hits[0].item != "notes" {
+		t.Fatalf("The search should have returned the attached file of %q but returned %v.", "notes", hits)
+	}
+
+	if attachments := index.Attachments("notes"); len(attachments) != 1 || attachments[0] != "notes/files/report.pdf" {
+		t.Errorf("Attachments should return the attached file but returned %v.", attachments)
+	}
+
+	index.Remove("notes/files/report.pdf")
+	if attachments := index.Attachments("notes"); len(attachments) != 0 {
+		t.Errorf("Attachments should not return removed files but returned %v.", attachments)
+	}
+}
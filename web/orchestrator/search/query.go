@@ -0,0 +1,272 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"path"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// queryDateFormats are the formats of the values of the "before:" and "after:" operators.
+var queryDateFormats = []string{"2006-01-02", "2006-01", "2006"}
+
+// Query is a parsed search query. Besides the keywords a query can contain phrases in quotes and
+// the operators "tag:", "type:", "before:", "after:" and "path:". Keywords, phrases and operators
+// which are prefixed with a minus exclude the documents they match.
+//
+// Example: `"getting started" markdown tag:howto -type:presentation before:2020-01-01 path:/notes/**`
+type Query struct {
+	// Terms are the keywords; documents which contain one of them are ranked by relevance.
+	Terms []string
+
+	// Phrases are the quoted sequences of terms which a document must contain.
+	Phrases [][]string
+
+	// Tags are the tags a document must have; Types are the item types of which a document must have one.
+	Tags  []string
+	Types []string
+
+	// Before and After restrict the date of the documents (exclusive); zero values are not applied.
+	Before time.Time
+	After  time.Time
+
+	// Paths are the route patterns of which a document must match one. "*" matches a single route
+	// component, "**" any number of components and a pattern without wildcards matches the route and all routes below it.
+	Paths []string
+
+	// the negated terms, phrases, tags, types and paths
+	ExcludedTerms   []string
+	ExcludedPhrases [][]string
+	ExcludedTags    []string
+	ExcludedTypes   []string
+	ExcludedPaths   []string
+}
+
+// ParseQuery parses the given search query. Unknown operators and values that cannot be parsed are treated as keywords.
+func ParseQuery(text string) Query {
+	var query Query
+
+	for _, token := range splitQuery(text) {
+		if token.isPhrase {
+			phrase := tokenize(token.value)
+			if len(phrase) == 0 {
+				continue
+			}
+
+			if token.isNegated {
+				query.ExcludedPhrases = append(query.ExcludedPhrases, phrase)
+			} else {
+				query.Phrases = append(query.Phrases, phrase)
+			}
+
+			continue
+		}
+
+		if query.addOperator(token) {
+			continue
+		}
+
+		if token.isNegated {
+			query.ExcludedTerms = append(query.ExcludedTerms, tokenize(token.value)...)
+		} else {
+			query.Terms = append(query.Terms, tokenize(token.value)...)
+		}
+	}
+
+	return query
+}
+
+// IsEmpty returns true if the query neither contains keywords nor filters.
+func (query Query) IsEmpty() bool {
+	return len(query.Terms) == 0 && len(query.Phrases) == 0 && !query.hasFilters()
+}
+
+// hasFilters returns true if the query restricts the documents by other means than keywords.
+func (query Query) hasFilters() bool {
+	return len(query.Tags) > 0 || len(query.Types) > 0 || !query.Before.IsZero() || !query.After.IsZero() || len(query.Paths) > 0 ||
+		len(query.ExcludedTerms) > 0 || len(query.ExcludedPhrases) > 0 || len(query.ExcludedTags) > 0 || len(query.ExcludedTypes) > 0 || len(query.ExcludedPaths) > 0
+}
+
+// addOperator adds the given token to the query if it is a known operator with a valid value.
+func (query *Query) addOperator(token queryToken) bool {
+	name, value, isOperator := strings.Cut(token.value, ":")
+	if !isOperator || value == "" {
+		return false
+	}
+
+	value = strings.ToLower(value)
+
+	switch strings.ToLower(name) {
+	case "tag":
+		if token.isNegated {
+			query.ExcludedTags = append(query.ExcludedTags, value)
+		} else {
+			query.Tags = append(query.Tags, value)
+		}
+
+	case "type":
+		if token.isNegated {
+			query.ExcludedTypes = append(query.ExcludedTypes, value)
+		} else {
+			query.Types = append(query.Types, value)
+		}
+
+	case "path":
+		pattern := strings.Trim(value, "/")
+		if token.isNegated {
+			query.ExcludedPaths = append(query.ExcludedPaths, pattern)
+		} else {
+			query.Paths = append(query.Paths, pattern)
+		}
+
+	case "before", "after":
+		if token.isNegated {
+			return false
+		}
+
+		date, isDate := parseQueryDate(value)
+		if !isDate {
+			return false
+		}
+
+		if strings.ToLower(name) == "before" {
+			query.Before = date
+		} else {
+			query.After = date
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// queryToken is a word or a quoted phrase of a query.
+type queryToken struct {
+	value     string
+	isPhrase  bool
+	isNegated bool
+}
+
+// splitQuery splits the given query into words and quoted phrases. Operator values can be quoted as well (tag:"open source").
+func splitQuery(text string) []queryToken {
+	tokens := make([]queryToken, 0)
+	characters := []rune(text)
+
+	for position := 0; position < len(characters); {
+		if unicode.IsSpace(characters[position]) {
+			position++
+			continue
+		}
+
+		var token queryToken
+		if characters[position] == '-' && position+1 < len(characters) && !unicode.IsSpace(characters[position+1]) {
+			token.isNegated = true
+			position++
+		}
+
+		if characters[position] == '"' {
+			end := indexOfQuote(characters, position+1)
+			token.value = string(characters[position+1 : end])
+			token.isPhrase = true
+			tokens = append(tokens, token)
+			position = end + 1
+			continue
+		}
+
+		start := position
+		for position < len(characters) && !unicode.IsSpace(characters[position]) {
+			if characters[position] == '"' && position > start && characters[position-1] == ':' {
+				end := indexOfQuote(characters, position+1)
+				token.value = string(characters[start:position]) + string(characters[position+1:end])
+				position = end + 1
+				break
+			}
+
+			position++
+			token.value = string(characters[start:position])
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+// indexOfQuote returns the position of the next quote starting at the given position; or the length of the text if there is none.
+func indexOfQuote(characters []rune, start int) int {
+	for position := start; position < len(characters); position++ {
+		if characters[position] == '"' {
+			return position
+		}
+	}
+
+	return len(characters)
+}
+
+// parseQueryDate parses the value of a date operator.
+func parseQueryDate(value string) (time.Time, bool) {
+	for _, format := range queryDateFormats {
+		if date, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// matchesAnyPath returns true if the given route matches one of the given patterns.
+func matchesAnyPath(patterns []string, route string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "*") {
+			pattern = strings.TrimPrefix(pattern+"/**", "/")
+		}
+
+		if matchPath(strings.Split(pattern, "/"), splitRoute(strings.ToLower(route))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPath returns true if the given route components match the given pattern components.
+func matchPath(pattern, components []string) bool {
+	if len(pattern) == 0 {
+		return len(components) == 0
+	}
+
+	if pattern[0] == "**" {
+		for skipped := 0; skipped <= len(components); skipped++ {
+			if matchPath(pattern[1:], components[skipped:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(components) == 0 {
+		return false
+	}
+
+	if matches, err := path.Match(pattern[0], components[0]); err != nil || !matches {
+		return false
+	}
+
+	return matchPath(pattern[1:], components[1:])
+}
+
+// splitRoute returns the components of the given route.
+func splitRoute(route string) []string {
+	if route == "" {
+		return []string{}
+	}
+
+	return strings.Split(route, "/")
+}
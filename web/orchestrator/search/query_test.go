@@ -0,0 +1,108 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_ParseQuery_OperatorsPhrasesAndNegations_QueryIsParsed(t *testing.T) {
+	// arrange
+	text := `"Getting started" markdown -draft tag:"Open Source" -type:presentation before:2020-01-01 path:/notes/** -"old stuff"`
+
+	// act
+	query := ParseQuery(text)
+
+	// assert
+	if !reflect.DeepEqual(query.Terms, []string{"markdown"}) {
+		t.Errorf("The terms should be [markdown] but are %v.", query.Terms)
+	}
+
+	if !reflect.DeepEqual(query.Phrases, [][]string{{"getting", "started"}}) {
+		t.Errorf("The phrases should be [[getting started]] but are %v.", query.Phrases)
+	}
+
+	if !reflect.DeepEqual(query.ExcludedTerms, []string{"draft"}) {
+		t.Errorf("The excluded terms should be [draft] but are %v.", query.ExcludedTerms)
+	}
+
+	if !reflect.DeepEqual(query.Tags, []string{"open source"}) {
+		t.Errorf("The tags should be [open source] but are %v.", query.Tags)
+	}
+
+	if !reflect.DeepEqual(query.ExcludedTypes, []string{"presentation"}) {
+		t.Errorf("The excluded types should be [presentation] but are %v.", query.ExcludedTypes)
+	}
+
+	if expected := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local); !query.Before.Equal(expected) {
+		t.Errorf("The before date should be %s but is %s.", expected, query.Before)
+	}
+
+	if !reflect.DeepEqual(query.Paths, []string{"notes/**"}) {
+		t.Errorf("The paths should be [notes/**] but are %v.", query.Paths)
+	}
+
+	if !reflect.DeepEqual(query.ExcludedPhrases, [][]string{{"old", "stuff"}}) {
+		t.Errorf("The excluded phrases should be [[old stuff]] but are %v.", query.ExcludedPhrases)
+	}
+}
+
+func Test_ParseQuery_UnknownOperatorAndInvalidDate_ValuesAreKeywords(t *testing.T) {
+	// arrange
+	text := "author:andreas before:yesterday"
+
+	// act
+	query := ParseQuery(text)
+
+	// assert
+	expected := []string{"author", "andreas", "before", "yesterday"}
+	if !reflect.DeepEqual(query.Terms, expected) {
+		t.Errorf("The terms should be %v but are %v.", expected, query.Terms)
+	}
+
+	if !query.Before.IsZero() {
+		t.Errorf("The before date should not be set but is %s.", query.Before)
+	}
+}
+
+func Test_matchesAnyPath(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		pattern string
+		route   string
+		matches bool
+	}{
+		{"notes/**", "notes", true},
+		{"notes/**", "notes/2020/todo", true},
+		{"notes/*", "notes/2020/todo", false},
+		{"notes/*/todo", "notes/2020/todo", true},
+		{"notes", "notes/2020", true},
+		{"notes", "notebooks", false},
+		{"**/todo", "notes/2020/todo", true},
+		{"", "anything", true},
+	}
+
+	for _, input := range inputs {
+		// act
+		matches := matchesAnyPath([]string{input.pattern}, input.route)
+
+		// assert
+		if matches != input.matches {
+			t.Errorf("matchesAnyPath(%q, %q) returned %v but %v was expected.", input.pattern, input.route, matches, input.matches)
+		}
+	}
+}
+
+func Test_ParseQuery_CJKWord_WordIsATerm(t *testing.T) {
+	// act
+	query := ParseQuery("東京都 tokyo")
+
+	// assert
+	if !reflect.DeepEqual(query.Terms, []string{"東京都", "tokyo"}) {
+		t.Errorf("The terms should be [東京都 tokyo] but are %v.", query.Terms)
+	}
+}
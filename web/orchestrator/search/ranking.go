@@ -0,0 +1,116 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"math"
+	"time"
+)
+
+// The default ranking factors (see Ranking).
+const (
+	DefaultTitleBoost       = 3.0
+	DefaultDescriptionBoost = 1.5
+	DefaultTagsBoost        = 2.0
+	DefaultAliasesBoost     = 2.0
+	DefaultRouteBoost       = 1.0
+	DefaultContentBoost     = 1.0
+	DefaultRecencyBoost     = 0.0
+	DefaultRecencyHalfLife  = 30 * 24 * time.Hour
+)
+
+// getCurrentTime returns the time by which the age of the documents is measured.
+var getCurrentTime = time.Now
+
+// Ranking defines how much matches in the different fields of a document and the age of a document
+// contribute to its score.
+type Ranking struct {
+	// The factors by which the scores of matches in the fields are multiplied. Factors which are not
+	// positive are replaced by the default factors.
+	TitleBoost       float64
+	DescriptionBoost float64
+	TagsBoost        float64
+	AliasesBoost     float64
+	RouteBoost       float64
+	ContentBoost     float64
+
+	// RecencyBoost is the share by which the score of a document that has just been modified is increased
+	// (e.g. 0.5 for 50% more). The boost is halved with every RecencyHalfLife the document is older; 0 disables it.
+	RecencyBoost    float64
+	RecencyHalfLife time.Duration
+}
+
+// DefaultRanking returns a ranking which prefers matches in titles, tags and aliases over matches in the content
+// and ignores the age of the documents.
+func DefaultRanking() Ranking {
+	return Ranking{
+		TitleBoost:       DefaultTitleBoost,
+		DescriptionBoost: DefaultDescriptionBoost,
+		TagsBoost:        DefaultTagsBoost,
+		AliasesBoost:     DefaultAliasesBoost,
+		RouteBoost:       DefaultRouteBoost,
+		ContentBoost:     DefaultContentBoost,
+		RecencyBoost:     DefaultRecencyBoost,
+		RecencyHalfLife:  DefaultRecencyHalfLife,
+	}
+}
+
+// withDefaults returns the ranking in which the unset factors are replaced by the default factors.
+func (ranking Ranking) withDefaults() Ranking {
+	defaults := DefaultRanking()
+
+	for _, factor := range []struct {
+		value        *float64
+		defaultValue float64
+	}{
+		{&ranking.TitleBoost, defaults.TitleBoost},
+		{&ranking.DescriptionBoost, defaults.DescriptionBoost},
+		{&ranking.TagsBoost, defaults.TagsBoost},
+		{&ranking.AliasesBoost, defaults.AliasesBoost},
+		{&ranking.RouteBoost, defaults.RouteBoost},
+		{&ranking.ContentBoost, defaults.ContentBoost},
+	} {
+		if *factor.value <= 0 {
+			*factor.value = factor.defaultValue
+		}
+	}
+
+	if ranking.RecencyBoost < 0 {
+		ranking.RecencyBoost = 0
+	}
+
+	if ranking.RecencyHalfLife <= 0 {
+		ranking.RecencyHalfLife = defaults.RecencyHalfLife
+	}
+
+	return ranking
+}
+
+// getFieldBoosts returns the boost factors by field.
+func (ranking Ranking) getFieldBoosts() [numberOfFields]float64 {
+	var boosts [numberOfFields]float64
+	boosts[fieldTitle] = ranking.TitleBoost
+	boosts[fieldDescription] = ranking.DescriptionBoost
+	boosts[fieldTags] = ranking.TagsBoost
+	boosts[fieldAliases] = ranking.AliasesBoost
+	boosts[fieldRoute] = ranking.RouteBoost
+	boosts[fieldContent] = ranking.ContentBoost
+
+	return boosts
+}
+
+// getRecencyFactor returns the factor by which the score of a document that has been modified at the given time is multiplied.
+func (ranking Ranking) getRecencyFactor(modified, now time.Time) float64 {
+	if ranking.RecencyBoost == 0 || modified.IsZero() {
+		return 1
+	}
+
+	age := now.Sub(modified)
+	if age < 0 {
+		age = 0
+	}
+
+	return 1 + ranking.RecencyBoost*math.Pow(2, -float64(age)/float64(ranking.RecencyHalfLife))
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_indexSearch_TermInTitleAndInContent_TitleMatchIsRankedHigher(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("a", "1", "", map[field]string{fieldTitle: "Meeting notes", fieldContent: "kubernetes cluster"}),
+		newDocument("b", "2", "", map[field]string{fieldTitle: "Kubernetes notes", fieldContent: "meeting minutes"}))
+
+	// act
+	hits := searchIndex(t, index, "kubernetes", allFields)
+
+	// assert
+	if len(hits) != 2 || hits[0].route != "b" {
+		t.Errorf("The document with the term in its title should be ranked first but the hits were %v.", hits)
+	}
+}
+
+func Test_indexSearch_ContentBoostedAboveTitle_ContentMatchIsRankedHigher(t *testing.T) {
+	// arrange
+	index := newTestIndex(t, nil,
+		newDocument("a", "1", "", map[field]string{fieldTitle: "Meeting notes", fieldContent: "kubernetes cluster"}),
+		newDocument("b", "2", "", map[field]string{fieldTitle: "Kubernetes notes", fieldContent: "meeting minutes"}))
+	index.SetRanking(Ranking{TitleBoost: 1, ContentBoost: 10})
+
+	// act
+	hits := searchIndex(t, index, "kubernetes", allFields)
+
+	// assert
+	if len(hits) != 2 || hits[0].route != "a" {
+		t.Errorf("The document with the term in its content should be ranked first but the hits were %v.", hits)
+	}
+}
+
+func Test_indexSearch_RecencyBoost_RecentlyModifiedDocumentIsRankedHigher(t *testing.T) {
+	// arrange
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	getCurrentTime = func() time.Time { return now }
+	defer func() { getCurrentTime = time.Now }()
+
+	old := newDocument("a", "1", "", map[field]string{fieldContent: "release notes"})
+	old.Modified = now.AddDate(-1, 0, 0)
+	recent := newDocument("b", "2", "", map[field]string{fieldContent: "release notes"})
+	recent.Modified = now.Add(-time.Hour)
+
+	index := newTestIndex(t, nil, old, recent)
+	index.SetRanking(Ranking{RecencyBoost: 1, RecencyHalfLife: 24 * time.Hour})
+
+	// act
+	hits := searchIndex(t, index, "release", allFields)
+
+	// assert
+	if len(hits) != 2 || hits[0].route != "b" {
+		t.Fatalf("The recently modified document should be ranked first but the hits were %v.", hits)
+	}
+
+	if hits[0].score <= hits[1].score*1.9 {
+		t.Errorf("The score of a document that has just been modified should almost be doubled but was %f (old document: %f).", hits[0].score, hits[1].score)
+	}
+}
+
+func Test_withDefaults_UnsetFactors_DefaultFactorsAreUsed(t *testing.T) {
+	// arrange
+	ranking := Ranking{TitleBoost: 5}
+
+	// act
+	result := ranking.withDefaults()
+
+	// assert
+	expected := DefaultRanking()
+	expected.TitleBoost = 5
+	if result != expected {
+		t.Errorf("withDefaults should return %+v but returned %+v.", expected, result)
+	}
+}
@@ -5,84 +5,436 @@
 package search
 
 import (
+	"github.com/andreaskoch/allmark/common/budget"
 	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/progress"
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/services/textextraction"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
-// Result is the model returned by the fulltext index's Search function.
+const (
+	// maximumBatchSize is the number of bytes of text after which the documents which are indexed
+	// during the initial synchronization are written to the index.
+	maximumBatchSize = 16 << 20
+
+	// maximumBatchDocuments is the number of documents after which the documents which are indexed
+	// during the initial synchronization are written to the index.
+	maximumBatchDocuments = 256
+)
+
+// Result is the model returned by the ItemSearch's Search function.
 type Result struct {
 	Route route.Route
 
-	Number     int
-	Score      int64
-	StoreValue string
+	// File is the route of the attached file whose text matched the query; it is empty if the item itself matched.
+	File route.Route
+
+	Number int
+	Score  float64
+
+	// Terms are the indexed terms of the item which matched the query (e.g. for highlighting).
+	Terms []string
 }
 
-// NewItemSearch creates a new repository item searcher.
-func NewItemSearch(logger logger.Logger, items []*model.Item) *ItemSearch {
+// NewItemSearch creates a new repository item searcher for the given items. If an index file path is given
+// the bleve index is stored in this directory, only new and modified items are indexed and every change is
+// written to the index; otherwise the index is only kept in memory.
+// The texts of the items are stemmed according to their language or, if they have none, the given default language.
+// The given synonyms (if any) are indexed along with the terms they are synonyms of.
+// If a text extractor is given the text of the attached files is indexed as well. The matches are ranked with the given ranking.
+func NewItemSearch(logger logger.Logger, indexFilePath, defaultLanguage string, ranking Ranking, synonyms *Synonyms, extractor *textextraction.Extractor, items []*model.Item) *ItemSearch {
+	logger = logger.Module("search")
+
+	fulltextIndex, err := openIndex(indexFilePath, synonyms)
+	if err != nil {
+		logger.Warn("Unable to open the search index %q. The index is kept in memory. Error: %s", indexFilePath, err)
+		fulltextIndex, err = openIndex("", synonyms)
+	}
+
+	if err != nil {
+		panic(err)
+	}
 
-	return &ItemSearch{
-		logger: logger,
+	if size := fulltextIndex.Size(); size > 0 {
+		logger.Info("Loaded %d items from the search index %q.", size, indexFilePath)
+	}
 
-		routesFullTextIndex:      newIndex(logger, items, "route", itemRouteKeywordProvider),
-		itemContentFullTextIndex: newIndex(logger, items, "content", itemContentKeywordProvider),
+	itemSearch := &ItemSearch{
+		logger:          logger,
+		defaultLanguage: defaultLanguage,
+		extractor:       extractor,
+		index:           fulltextIndex,
 	}
+
+	itemSearch.index.SetRanking(ranking)
+	itemSearch.sync(items)
+
+	return itemSearch
+}
+
+// ItemSearch maintains a full-text index of repository items and provides
+// the ability to search over this index.
+type ItemSearch struct {
+	logger logger.Logger
+
+	defaultLanguage string
+	extractor       *textextraction.Extractor
+	index           *index
+}
+
+// Size returns the number of indexed items.
+func (itemSearch *ItemSearch) Size() int {
+	return itemSearch.index.Size()
 }
 
-// itemRouteKeywordProvider returns a list of keywords for the fulltext index
-// from the given items' route.
-func itemRouteKeywordProvider(item *model.Item) []string {
-	if item == nil {
-		return []string{}
+// Close closes the index. The item search must not be used afterwards.
+func (itemSearch *ItemSearch) Close() error {
+	return itemSearch.index.Close()
+}
+
+// Search returns a set of Result models that match specified keywords (best matches first).
+// Keywords starting with a slash only match the routes of the items and their files.
+// The keywords can contain phrases and operators (see Query).
+func (itemSearch *ItemSearch) Search(keywords string, maxiumNumberOfResults int) []Result {
+
+	fields := allFields
+	if isRouteSearch(keywords) {
+		fields = []field{fieldRoute}
 	}
 
-	// item route components
-	routeComponents := item.Route().Components()
+	hits, err := itemSearch.index.SearchQuery(ParseQuery(keywords), fields)
+	if err != nil {
+		itemSearch.logger.Warn("Unable to search for %q. Error: %s", keywords, err)
+		return []Result{}
+	}
+
+	if len(hits) > maxiumNumberOfResults {
+		hits = hits[:maxiumNumberOfResults]
+	}
+
+	results := make([]Result, 0, len(hits))
+	for number, hit := range hits {
+		result := Result{
+			Number: number + 1,
+			Score:  hit.score,
+			Route:  route.NewFromRequest(hit.route),
+			Terms:  hit.terms,
+		}
+
+		// attached files are listed with the item they belong to
+		if hit.item != "" {
+			result.Route = route.NewFromRequest(hit.item)
+			result.File = route.NewFromRequest(hit.route)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Update adds the given item to the index or updates its entry. Items which must not be listed are removed from the index.
+func (itemSearch *ItemSearch) Update(item *model.Item) {
+	changes := &changes{}
+	itemSearch.update(item, changes)
+	itemSearch.apply(changes)
+}
+
+// Remove removes the item with the given route and its attached files from the index.
+func (itemSearch *ItemSearch) Remove(itemRoute route.Route) {
+	changes := &changes{}
+	if _, exists := itemSearch.index.Fingerprint(itemRoute.Value()); exists {
+		changes.Remove(itemRoute.Value())
+	}
+
+	for _, attachment := range itemSearch.index.Attachments(itemRoute.Value()) {
+		changes.Remove(attachment)
+	}
+
+	itemSearch.apply(changes)
+}
+
+// apply writes the given changes to the index.
+func (itemSearch *ItemSearch) apply(changes *changes) {
+	if changes.IsEmpty() {
+		return
+	}
+
+	release := budget.Acquire(budget.TaskSearch, changes.size*indexingMemoryFactor)
+	defer release()
+
+	if err := itemSearch.index.Apply(changes); err != nil {
+		itemSearch.logger.Warn("Unable to update the search index. Error: %s", err)
+	}
+}
+
+// sync indexes the given items and removes all other items from the index.
+func (itemSearch *ItemSearch) sync(items []*model.Item) {
+
+	syncProgress := progress.Start(itemSearch.logger, "Search indexing", "items", len(items))
+
+	// the documents are written in batches
+	pendingChanges := &changes{}
+
+	routes := make(map[string]bool, len(items))
+	for _, item := range items {
+		routes[item.Route().Value()] = true
+
+		itemSearch.update(item, pendingChanges)
+		if pendingChanges.size >= maximumBatchSize || len(pendingChanges.added) >= maximumBatchDocuments {
+			itemSearch.apply(pendingChanges)
+			pendingChanges = &changes{}
+		}
+
+		syncProgress.Increment()
+	}
+
+	// attached files are removed with their items
+	for indexedRoute, itemRoute := range itemSearch.index.Items() {
+		if itemRoute == "" {
+			itemRoute = indexedRoute
+		}
+
+		if !routes[itemRoute] {
+			pendingChanges.Remove(indexedRoute)
+		}
+	}
+
+	itemSearch.apply(pendingChanges)
+	syncProgress.Finish()
+}
+
+// update adds the changes which are needed to index the given item and its attached files to the given changes
+// unless they are indexed already.
+func (itemSearch *ItemSearch) update(item *model.Item, changes *changes) {
+	itemSearch.updateItem(item, changes)
+	itemSearch.updateAttachments(item, changes)
+}
 
-	// file route components
+// updateItem adds the given item to the given changes unless it is indexed already.
+func (itemSearch *ItemSearch) updateItem(item *model.Item, changes *changes) {
+	itemRoute := item.Route().Value()
+	indexedFingerprint, isIndexed := itemSearch.index.Fingerprint(itemRoute)
+
+	// items which must not be listed are not searchable
+	if item.MetaData.NoIndex {
+		if isIndexed {
+			changes.Remove(itemRoute)
+		}
+
+		return
+	}
+
+	texts := getIndexedTexts(item)
+	language := itemSearch.getLanguage(item)
+	itemType, itemDate, itemModified := item.Type.String(), getItemDate(item), getItemModificationDate(item)
+	fingerprint := getFingerprint(texts, language, itemType, itemDate.Format(time.RFC3339), itemModified.Format(time.RFC3339))
+	if isIndexed && indexedFingerprint == fingerprint {
+		return
+	}
+
+	document := newDocument(itemRoute, fingerprint, language, texts)
+	document.Tags = getLowerCaseTags(item.MetaData.Tags)
+	document.Type = strings.ToLower(itemType)
+	document.Date = itemDate
+	document.Modified = itemModified
+
+	changes.Add(document)
+}
+
+// updateAttachments adds the text of the supported files of the given item to the given changes unless they are
+// indexed already and removes the files which no longer belong to the item.
+func (itemSearch *ItemSearch) updateAttachments(item *model.Item, changes *changes) {
+	itemRoute := item.Route().Value()
+
+	attachments := make(map[string]bool)
+	if itemSearch.extractor != nil && !item.MetaData.NoIndex {
+		for _, file := range item.Files() {
+			itemSearch.updateAttachment(item, file, changes)
+			attachments[file.Route().Value()] = true
+		}
+	}
+
+	for _, indexedRoute := range itemSearch.index.Attachments(itemRoute) {
+		if !attachments[indexedRoute] {
+			changes.Remove(indexedRoute)
+		}
+	}
+}
+
+// updateAttachment adds the text of the given file of the given item to the given changes unless it is indexed already
+// or the text cannot be extracted.
+func (itemSearch *ItemSearch) updateAttachment(item *model.Item, file *model.File, changes *changes) {
+	fileRoute := file.Route().Value()
+	indexedFingerprint, isIndexed := itemSearch.index.Fingerprint(fileRoute)
+
+	// the mime type is only determined for files without an extension because it can require reading the file
+	mimeType := ""
+	if filepath.Ext(file.Name()) == "" {
+		mimeType, _ = file.MimeType()
+	}
+
+	if !itemSearch.extractor.CanExtract(file.Name(), mimeType) {
+		if isIndexed {
+			changes.Remove(fileRoute)
+		}
+
+		return
+	}
+
+	hash, err := file.Hash()
+	if err != nil {
+		itemSearch.logger.Warn("Unable to determine the hash of the file %q. Error: %s", fileRoute, err)
+		return
+	}
+
+	texts := map[field]string{
+		fieldTitle: file.Name(),
+		fieldRoute: strings.Join(file.Route().Components(), " "),
+	}
+
+	language := itemSearch.getLanguage(item)
+	// files are boosted by their own modification date
+	itemType, itemDate := item.Type.String(), getItemDate(item)
+	fileModified, err := file.LastModified()
+	if err != nil {
+		fileModified = getItemModificationDate(item)
+	}
+
+	fingerprint := getFingerprint(texts, hash, itemSearch.extractor.Name(), language, itemType, itemDate.Format(time.RFC3339), fileModified.Format(time.RFC3339), strings.Join(getLowerCaseTags(item.MetaData.Tags), " "))
+	if isIndexed && indexedFingerprint == fingerprint {
+		return
+	}
+
+	var text string
+	err = file.Data(func(content io.ReadSeeker) error {
+		// the extracted text is assumed to be at most as large as the file
+		size, _ := content.Seek(0, io.SeekEnd)
+		content.Seek(0, io.SeekStart)
+
+		release := budget.Acquire(budget.TaskSearch, size*indexingMemoryFactor)
+		defer release()
+
+		var extractionErr error
+		text, extractionErr = itemSearch.extractor.Extract(file.Name(), mimeType, content)
+		return extractionErr
+	})
+
+	// files whose text cannot be extracted are still found by their name
+	if err != nil {
+		itemSearch.logger.Warn("Unable to extract the text of the file %q. Error: %s", fileRoute, err)
+	}
+
+	texts[fieldContent] = text
+
+	document := newDocument(fileRoute, fingerprint, language, texts)
+	document.Item = item.Route().Value()
+	document.Text = text
+	document.Tags = getLowerCaseTags(item.MetaData.Tags)
+	document.Type = strings.ToLower(itemType)
+	document.Date = itemDate
+	document.Modified = fileModified
+
+	changes.Add(document)
+}
+
+// GetFileSnippet returns an excerpt (of at most maxLength characters) of the text which has been extracted
+// from the attached file with the given route which contains as many of the given index terms as possible (see GetSnippet).
+func (itemSearch *ItemSearch) GetFileSnippet(fileRoute route.Route, terms []string, maxLength int) Snippet {
+	document, exists := itemSearch.index.Document(fileRoute.Value())
+	if !exists {
+		return Snippet{Highlights: make([]Highlight, 0)}
+	}
+
+	return getSnippet(document.Text, itemSearch.index.getAnalyzer(document.Language), terms, maxLength)
+}
+
+// GetItemSnippet returns an excerpt (of at most maxLength characters) of the indexed content of the given
+// item which contains as many of the given index terms as possible (see GetSnippet).
+func (itemSearch *ItemSearch) GetItemSnippet(item *model.Item, terms []string, maxLength int) Snippet {
+	return getSnippet(getContentFromItem(item), itemSearch.index.getAnalyzer(itemSearch.getLanguage(item)), terms, maxLength)
+}
+
+// getLanguage returns the analyzer language of the given item (see getAnalyzerLanguage).
+func (itemSearch *ItemSearch) getLanguage(item *model.Item) string {
+	if item.MetaData.Language != "" {
+		return getAnalyzerLanguage(item.MetaData.Language)
+	}
+
+	return getAnalyzerLanguage(itemSearch.defaultLanguage)
+}
+
+// getIndexedTexts returns the texts of the given item by field.
+func getIndexedTexts(item *model.Item) map[field]string {
+
+	// item and file route components
+	routeComponents := item.Route().Components()
 	for _, file := range item.Files() {
 		routeComponents = append(routeComponents, file.Route().Components()...)
 	}
 
-	return routeComponents
+	return map[field]string{
+		fieldTitle:       item.Title,
+		fieldDescription: item.Description,
+		fieldTags:        strings.Join(item.MetaData.Tags, " "),
+		fieldAliases:     strings.Join(item.MetaData.Aliases, " "),
+		fieldRoute:       strings.Join(routeComponents, " "),
+		fieldContent:     item.Content,
+	}
 }
 
-// itemContentKeywordProvider returns a list of keywords for the fulltext index
-// from the given items' content.
-func itemContentKeywordProvider(item *model.Item) []string {
-
-	if item == nil {
-		return []string{}
+// getItemDate returns the creation date of the given item or its last modification date if the creation date is not set.
+func getItemDate(item *model.Item) time.Time {
+	if item.MetaData.CreationDate.IsZero() {
+		return item.MetaData.LastModifiedDate
 	}
 
-	var keywords []string
-	keywords = append(keywords, getContentFromItem(item))
+	return item.MetaData.CreationDate
+}
+
+// getItemModificationDate returns the last modification date of the given item or its creation date if the modification date is not set.
+func getItemModificationDate(item *model.Item) time.Time {
+	if item.MetaData.LastModifiedDate.IsZero() {
+		return item.MetaData.CreationDate
+	}
 
-	return keywords
+	return item.MetaData.LastModifiedDate
 }
 
-// ItemSearch creates a fulltext index for given set of repository items and provides
-// the ability to search over this index.
-type ItemSearch struct {
-	logger logger.Logger
+// getLowerCaseTags returns the lower-case versions of the given tags.
+func getLowerCaseTags(tags []string) []string {
+	lowerCaseTags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		lowerCaseTags = append(lowerCaseTags, strings.ToLower(tag))
+	}
 
-	routesFullTextIndex      *FullTextIndex
-	itemContentFullTextIndex *FullTextIndex
+	return lowerCaseTags
 }
 
-// Search returns a set of Result models that match specified keywords.
-func (itemSearch *ItemSearch) Search(keywords string, maxiumNumberOfResults int) []Result {
+// indexingMemoryFactor is the estimated number of bytes which are needed to analyze and index a byte of text.
+const indexingMemoryFactor = 4
 
-	// routes
-	if isRouteSearch(keywords) {
-		routeComponents := strings.Replace(keywords, "/", " ", -1)
-		return itemSearch.routesFullTextIndex.Search(routeComponents, maxiumNumberOfResults)
+// getFingerprint returns a fingerprint of the given texts and attributes.
+func getFingerprint(texts map[field]string, attributes ...string) string {
+	hash := sha1.New()
+	for _, field := range allFields {
+		hash.Write([]byte(texts[field]))
+		hash.Write([]byte{0})
+	}
+
+	for _, attribute := range attributes {
+		hash.Write([]byte(attribute))
+		hash.Write([]byte{0})
 	}
 
-	// items
-	return itemSearch.itemContentFullTextIndex.Search(keywords, maxiumNumberOfResults)
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
 // getContentFromItem returns the content from the given repository item.
@@ -0,0 +1,173 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/blevesearch/bleve/v2/analysis"
+	"html"
+	"regexp"
+	"strings"
+)
+
+const (
+	// snippetEllipsis is appended or prepended to snippets which don't start or end with the text.
+	snippetEllipsis = "…"
+
+	// maximumSnippetMatches is the number of matches that are considered when choosing the part of a text a snippet shows.
+	maximumSnippetMatches = 256
+)
+
+var (
+	// markdownMarkupPattern matches the markdown characters that are removed from snippets.
+	markdownMarkupPattern = regexp.MustCompile("[#*_`>|~]+|!?\\[|\\]\\([^)]*\\)|\\]")
+
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Snippet is an excerpt of the text of an item with the positions of the search terms it contains.
+type Snippet struct {
+	Text       string
+	Highlights []Highlight
+}
+
+// Highlight is the position (in characters) of a matched term in the text of a snippet.
+type Highlight struct {
+	Start, End int
+}
+
+// HTML returns the HTML-escaped text of the snippet in which the matched terms are wrapped in <mark> elements.
+func (snippet Snippet) HTML() string {
+	characters := []rune(snippet.Text)
+
+	var builder strings.Builder
+	position := 0
+	for _, highlight := range snippet.Highlights {
+		builder.WriteString(html.EscapeString(string(characters[position:highlight.Start])))
+		builder.WriteString("<mark>")
+		builder.WriteString(html.EscapeString(string(characters[highlight.Start:highlight.End])))
+		builder.WriteString("</mark>")
+		position = highlight.End
+	}
+
+	builder.WriteString(html.EscapeString(string(characters[position:])))
+	return builder.String()
+}
+
+// GetSnippet returns an excerpt (of at most maxLength characters) of the given markdown text and highlights
+// the occurrences of the given (lower-case) terms. The excerpt is chosen so that it contains as many
+// occurrences of the terms as possible; if none of the terms occurs in the text the beginning of the text is returned.
+func GetSnippet(text string, terms []string, maxLength int) Snippet {
+	return getSnippet(text, plainAnalyzer, terms, maxLength)
+}
+
+// getSnippet returns an excerpt of the given markdown text (see GetSnippet) in which the words are highlighted
+// whose index terms (according to the given analyzer) are one of the given index terms.
+func getSnippet(text string, analyzer analysis.Analyzer, terms []string, maxLength int) Snippet {
+
+	// strip the markup
+	text = markdownMarkupPattern.ReplaceAllString(text, " ")
+	text = strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+	characters := []rune(text)
+
+	// find the matches
+	isSearchTerm := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		isSearchTerm[term] = true
+	}
+
+	// the analyzer returns byte offsets
+	characterPositions := make(map[int]int, len(text)+1)
+	characterPosition := 0
+	for bytePosition := range text {
+		characterPositions[bytePosition] = characterPosition
+		characterPosition++
+	}
+
+	characterPositions[len(text)] = characterPosition
+
+	matches := make([]Highlight, 0)
+	for _, token := range analyzer.Analyze([]byte(text)) {
+		if !isSearchTerm[string(token.Term)] {
+			continue
+		}
+
+		start, end := characterPositions[token.Start], characterPositions[token.End]
+
+		// merge overlapping matches (bigrams and synonyms)
+		if last := len(matches) - 1; last >= 0 && start < matches[last].End {
+			if end > matches[last].End {
+				matches[last].End = end
+			}
+
+			continue
+		}
+
+		matches = append(matches, Highlight{start, end})
+
+		if len(matches) == maximumSnippetMatches {
+			break
+		}
+	}
+
+	// choose the excerpt with the most matches; the first match is placed after the first third of the excerpt
+	start, end := 0, len(characters)
+	if len(characters) > maxLength {
+		bestNumberOfMatches := 0
+		for index, match := range matches {
+			numberOfMatches := 0
+			for _, otherMatch := range matches[index:] {
+				if otherMatch.End-match.Start > maxLength*2/3 {
+					break
+				}
+
+				numberOfMatches++
+			}
+
+			if numberOfMatches > bestNumberOfMatches {
+				bestNumberOfMatches = numberOfMatches
+				start = 0
+				if match.Start > maxLength/3 {
+					start = match.Start - maxLength/3
+				}
+			}
+		}
+
+		end = start + maxLength
+		if end > len(characters) {
+			end = len(characters)
+			start = end - maxLength
+		}
+	}
+
+	// trim whitespace at the edges of the excerpt
+	for start < end && characters[start] == ' ' {
+		start++
+	}
+
+	for end > start && characters[end-1] == ' ' {
+		end--
+	}
+
+	snippet := Snippet{Highlights: make([]Highlight, 0)}
+
+	prefix := 0
+	if start > 0 {
+		snippet.Text = snippetEllipsis
+		prefix = len([]rune(snippetEllipsis))
+	}
+
+	snippet.Text += string(characters[start:end])
+	if end < len(characters) {
+		snippet.Text += snippetEllipsis
+	}
+
+	for _, match := range matches {
+		if match.Start >= start && match.End <= end {
+			snippet.Highlights = append(snippet.Highlights, Highlight{match.Start - start + prefix, match.End - start + prefix})
+		}
+	}
+
+	return snippet
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_GetSnippet_ShortText_TermsAreHighlighted(t *testing.T) {
+	// arrange
+	text := "# Markdown\n\nWrite **markdown** & render it."
+
+	// act
+	snippet := GetSnippet(text, []string{"markdown"}, 200)
+
+	// assert
+	expected := "<mark>Markdown</mark> Write <mark>markdown</mark> &amp; render it."
+	if snippet.HTML() != expected {
+		t.Errorf("The snippet should be %q but is %q.", expected, snippet.HTML())
+	}
+}
+
+func Test_GetSnippet_LongText_ExcerptContainsTheMostMatches(t *testing.T) {
+	// arrange
+	text := "zebra " + strings.Repeat("filler ", 50) + "zebra crossing zebra " + strings.Repeat("filler ", 50)
+
+	// act
+	snippet := GetSnippet(text, []string{"zebra", "crossing"}, 60)
+
+	// assert
+	if len(snippet.Highlights) != 3 {
+		t.Fatalf("The snippet should contain 3 highlights but contains %d (%q).", len(snippet.Highlights), snippet.Text)
+	}
+
+	if !strings.HasPrefix(snippet.Text, snippetEllipsis) || !strings.HasSuffix(snippet.Text, snippetEllipsis) {
+		t.Errorf("The snippet should start and end with an ellipsis: %q", snippet.Text)
+	}
+
+	characters := []rune(snippet.Text)
+	for _, highlight := range snippet.Highlights {
+		if term := strings.ToLower(string(characters[highlight.Start:highlight.End])); term != "zebra" && term != "crossing" {
+			t.Errorf("The highlight %v should mark a search term but marks %q.", highlight, term)
+		}
+	}
+}
+
+func Test_GetSnippet_NoMatches_BeginningOfTheTextIsReturned(t *testing.T) {
+	// arrange
+	text := strings.Repeat("word ", 100)
+
+	// act
+	snippet := GetSnippet(text, []string{"missing"}, 20)
+
+	// assert
+	if !strings.HasPrefix(snippet.Text, "word word") || len(snippet.Highlights) != 0 {
+		t.Errorf("The snippet should be the beginning of the text without highlights but is %q (%v).", snippet.Text, snippet.Highlights)
+	}
+}
+
+func Test_GetSnippet_CJKText_OverlappingBigramsAreHighlightedOnce(t *testing.T) {
+	// act
+	snippet := GetSnippet("私は東京都に住む", []string{"東京", "京都"}, 200)
+
+	// assert
+	expected := "私は<mark>東京都</mark>に住む"
+	if snippet.HTML() != expected {
+		t.Errorf("The snippet should be %q but is %q.", expected, snippet.HTML())
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maximumScannedSuggestions is the number of prefix matches that are ranked for a single request.
+const maximumScannedSuggestions = 1000
+
+// The types of suggestions.
+const (
+	SuggestionTypeTitle = "title"
+	SuggestionTypeAlias = "alias"
+	SuggestionTypeTag   = "tag"
+)
+
+// suggestionTypeOrder defines the order in which suggestions of different types are listed.
+var suggestionTypeOrder = map[string]int{
+	SuggestionTypeTitle: 0,
+	SuggestionTypeAlias: 1,
+	SuggestionTypeTag:   2,
+}
+
+// Suggestion is a title, alias or tag which starts with the typed text. Route is the route of the item
+// the title or alias belongs to; it is empty for tags.
+type Suggestion struct {
+	Value string
+	Type  string
+	Route route.Route
+
+	// itemRoute is the route of the item the suggestion belongs to (for tags as well)
+	itemRoute route.Route
+}
+
+// suggestionKey is a lower-case suffix of a suggestion value which starts at the beginning of a word.
+type suggestionKey struct {
+	key         string
+	isFullValue bool
+	suggestion  *Suggestion
+}
+
+// NewSuggestionIndex creates a new prefix index over the titles, aliases and tags of the given items.
+func NewSuggestionIndex(items []*model.Item) *SuggestionIndex {
+	suggestionIndex := &SuggestionIndex{
+		suggestionsByRoute: make(map[string][]*Suggestion),
+	}
+
+	for _, item := range items {
+		suggestionIndex.Update(item)
+	}
+
+	return suggestionIndex
+}
+
+// SuggestionIndex answers search-as-you-type requests with the titles, aliases and tags
+// which start with the typed text (or contain a word which does). It is safe for concurrent use.
+type SuggestionIndex struct {
+	sync.Mutex
+
+	suggestionsByRoute map[string][]*Suggestion
+
+	// keys contains the sorted keys of all suggestions; it is rebuilt on demand after changes
+	keys []suggestionKey
+}
+
+// Update adds the title, aliases and tags of the given item to the index or replaces them.
+// Items which must not be listed are removed from the index.
+func (suggestionIndex *SuggestionIndex) Update(item *model.Item) {
+	suggestionIndex.Lock()
+	defer suggestionIndex.Unlock()
+
+	itemRoute := item.Route().Value()
+	suggestionIndex.keys = nil
+
+	if item.MetaData.NoIndex {
+		delete(suggestionIndex.suggestionsByRoute, itemRoute)
+		return
+	}
+
+	suggestions := make([]*Suggestion, 0)
+	if strings.TrimSpace(item.Title) != "" {
+		suggestions = append(suggestions, &Suggestion{Value: item.Title, Type: SuggestionTypeTitle, Route: item.Route(), itemRoute: item.Route()})
+	}
+
+	for _, alias := range item.MetaData.Aliases {
+		suggestions = append(suggestions, &Suggestion{Value: alias, Type: SuggestionTypeAlias, Route: item.Route(), itemRoute: item.Route()})
+	}
+
+	for _, tag := range item.MetaData.Tags {
+		suggestions = append(suggestions, &Suggestion{Value: tag, Type: SuggestionTypeTag, itemRoute: item.Route()})
+	}
+
+	suggestionIndex.suggestionsByRoute[itemRoute] = suggestions
+}
+
+// Remove removes the title, aliases and tags of the item with the given route from the index.
+func (suggestionIndex *SuggestionIndex) Remove(itemRoute route.Route) {
+	suggestionIndex.Lock()
+	defer suggestionIndex.Unlock()
+
+	delete(suggestionIndex.suggestionsByRoute, itemRoute.Value())
+	suggestionIndex.keys = nil
+}
+
+// Suggest returns up to the given number of suggestions for the given text. Values which start with
+// the text are listed before values which only contain a word that starts with it; titles are listed
+// before aliases and tags and shorter values before longer ones.
+// If a visibility function is given only the suggestions of the items it accepts are returned.
+func (suggestionIndex *SuggestionIndex) Suggest(text string, maximumNumberOfSuggestions int, isVisible func(itemRoute route.Route) bool) []Suggestion {
+	prefix := normalizeSuggestionText(text)
+	if prefix == "" {
+		return []Suggestion{}
+	}
+
+	suggestionIndex.Lock()
+	keys := suggestionIndex.getKeys()
+	suggestionIndex.Unlock()
+
+	// collect the matching keys
+	matches := make([]suggestionKey, 0)
+	for position := sort.Search(len(keys), func(i int) bool { return keys[i].key >= prefix }); position < len(keys); position++ {
+		if !strings.HasPrefix(keys[position].key, prefix) || len(matches) == maximumScannedSuggestions {
+			break
+		}
+
+		if isVisible != nil && !isVisible(keys[position].suggestion.itemRoute) {
+			continue
+		}
+
+		matches = append(matches, keys[position])
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.isFullValue != b.isFullValue {
+			return a.isFullValue
+		}
+
+		if a.suggestion.Type != b.suggestion.Type {
+			return suggestionTypeOrder[a.suggestion.Type] < suggestionTypeOrder[b.suggestion.Type]
+		}
+
+		if len(a.suggestion.Value) != len(b.suggestion.Value) {
+			return len(a.suggestion.Value) < len(b.suggestion.Value)
+		}
+
+		return a.suggestion.Value < b.suggestion.Value
+	})
+
+	// remove duplicates (e.g. tags that are used by many items)
+	suggestions := make([]Suggestion, 0)
+	seen := make(map[Suggestion]bool)
+	for _, match := range matches {
+		suggestion := *match.suggestion
+		suggestion.itemRoute = route.Route{}
+
+		key := suggestion
+		if key.Type == SuggestionTypeTag {
+			key.Value = strings.ToLower(key.Value)
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		suggestions = append(suggestions, suggestion)
+
+		if len(suggestions) == maximumNumberOfSuggestions {
+			break
+		}
+	}
+
+	return suggestions
+}
+
+// getKeys returns the sorted keys of all suggestions. The caller must hold the lock of the index.
+func (suggestionIndex *SuggestionIndex) getKeys() []suggestionKey {
+	if suggestionIndex.keys != nil {
+		return suggestionIndex.keys
+	}
+
+	keys := make([]suggestionKey, 0)
+	for _, suggestions := range suggestionIndex.suggestionsByRoute {
+		for _, suggestion := range suggestions {
+			value := normalizeSuggestionText(suggestion.Value)
+
+			words := strings.Split(value, " ")
+			for index := range words {
+				keys = append(keys, suggestionKey{
+					key:         strings.Join(words[index:], " "),
+					isFullValue: index == 0,
+					suggestion:  suggestion,
+				})
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].key < keys[j].key
+	})
+
+	suggestionIndex.keys = keys
+	return keys
+}
+
+// normalizeSuggestionText returns the lower-case words of the given text separated by single spaces.
+func normalizeSuggestionText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), isSeparator), " ")
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"testing"
+)
+
+func newSuggestionTestItem(path, title string, aliases, tags []string) *model.Item {
+	item := model.NewItem(route.NewFromRequest(path), nil, 0)
+	item.Title = title
+	item.MetaData.Aliases = aliases
+	item.MetaData.Tags = tags
+	return item
+}
+
+func Test_SuggestionIndex_Suggest_PrefixOfValueOrWord_SuggestionsAreRanked(t *testing.T) {
+	// arrange
+	suggestionIndex := NewSuggestionIndex([]*model.Item{
+		newSuggestionTestItem("events/meetup", "Go Meetup", []string{"meet"}, []string{"meetings"}),
+		newSuggestionTestItem("notes/meeting", "Meeting Notes", nil, []string{"Meetings"}),
+	})
+
+	// act
+	suggestions := suggestionIndex.Suggest("Mee", 10, nil)
+
+	// assert
+	expected := []string{"Meeting Notes", "meet", "Meetings", "Go Meetup"}
+	if len(suggestions) != len(expected) {
+		t.Fatalf("The index should have returned %v but returned %v.", expected, suggestions)
+	}
+
+	for index, value := range expected {
+		if suggestions[index].Value != value {
+			t.Errorf("Suggestion %d should be %q but is %q.", index, value, suggestions[index].Value)
+		}
+	}
+}
+
+func Test_SuggestionIndex_ItemRemoved_ItemIsNotSuggested(t *testing.T) {
+	// arrange
+	item := newSuggestionTestItem("events/meetup", "Go Meetup", nil, nil)
+	suggestionIndex := NewSuggestionIndex([]*model.Item{item})
+	suggestionIndex.Suggest("go", 10, nil)
+
+	// act
+	suggestionIndex.Remove(item.Route())
+	suggestions := suggestionIndex.Suggest("go", 10, nil)
+
+	// assert
+	if len(suggestions) != 0 {
+		t.Errorf("The removed item should not be suggested but the index returned %v.", suggestions)
+	}
+}
+
+func Test_SuggestionIndex_ItemNotVisible_TitleAndTagsAreNotSuggested(t *testing.T) {
+	// arrange
+	suggestionIndex := NewSuggestionIndex([]*model.Item{
+		newSuggestionTestItem("private/meetup", "Go Meetup", nil, []string{"gophers"}),
+		newSuggestionTestItem("public/golang", "Golang", nil, nil),
+	})
+
+	isVisible := func(itemRoute route.Route) bool {
+		return itemRoute.Value() != "private/meetup"
+	}
+
+	// act
+	suggestions := suggestionIndex.Suggest("go", 10, isVisible)
+
+	// assert
+	if len(suggestions) != 1 || suggestions[0].Value != "Golang" {
+		t.Errorf("Only the visible item should be suggested but the index returned %v.", suggestions)
+	}
+}
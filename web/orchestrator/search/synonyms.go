@@ -0,0 +1,222 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/blevesearch/bleve/v2/analysis"
+	"sort"
+	"strings"
+)
+
+// Synonyms contains groups of words or phrases which are treated as equivalent by the search.
+// The synonyms are applied when the documents are indexed: wherever an entry of a group occurs
+// in a text the other entries of the group are indexed at the same position, so that a query
+// for any entry (or a phrase containing it) matches the document.
+type Synonyms struct {
+	groups      [][]string
+	fingerprint string
+}
+
+// NewSynonyms creates a new synonym dictionary from the given groups (e.g. {"k8s", "kubernetes"}).
+// Groups with less than two entries are ignored.
+func NewSynonyms(groups [][]string) *Synonyms {
+	synonyms := &Synonyms{}
+
+	hash := sha1.New()
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		synonyms.groups = append(synonyms.groups, group)
+		hash.Write([]byte(strings.Join(group, "\x00")))
+		hash.Write([]byte{'\n'})
+	}
+
+	if len(synonyms.groups) > 0 {
+		synonyms.fingerprint = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return synonyms
+}
+
+// Size returns the number of synonym groups.
+func (synonyms *Synonyms) Size() int {
+	if synonyms == nil {
+		return 0
+	}
+
+	return len(synonyms.groups)
+}
+
+// Fingerprint identifies the synonym groups; it is empty if there are none.
+func (synonyms *Synonyms) Fingerprint() string {
+	if synonyms == nil {
+		return ""
+	}
+
+	return synonyms.fingerprint
+}
+
+// getConfig returns the synonym groups in the form in which they are stored in the analyzer config of the index mapping.
+func (synonyms *Synonyms) getConfig() []interface{} {
+	config := make([]interface{}, 0, synonyms.Size())
+	for _, group := range synonyms.getGroups() {
+		entries := make([]interface{}, 0, len(group))
+		for _, entry := range group {
+			entries = append(entries, entry)
+		}
+
+		config = append(config, entries)
+	}
+
+	return config
+}
+
+// getGroups returns the synonym groups.
+func (synonyms *Synonyms) getGroups() [][]string {
+	if synonyms == nil {
+		return nil
+	}
+
+	return synonyms.groups
+}
+
+// getSynonymGroups returns the synonym groups of the given analyzer config value (see getConfig).
+func getSynonymGroups(config interface{}) [][]string {
+	groups := make([][]string, 0)
+
+	configGroups, _ := config.([]interface{})
+	for _, configGroup := range configGroups {
+		configEntries, _ := configGroup.([]interface{})
+
+		group := make([]string, 0, len(configEntries))
+		for _, configEntry := range configEntries {
+			if entry, isString := configEntry.(string); isString {
+				group = append(group, entry)
+			}
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// synonymRule adds the alternative term sequences wherever the pattern occurs in a text.
+type synonymRule struct {
+	pattern      []string
+	alternatives [][]string
+}
+
+// synonymTable contains the synonym rules of a language by the first term of their pattern.
+type synonymTable map[string][]synonymRule
+
+// newSynonymTable analyzes the entries of the given synonym groups with the given analyzer
+// and creates a rule for every entry.
+func newSynonymTable(groups [][]string, analyzer analysis.Analyzer) synonymTable {
+	table := make(synonymTable)
+
+	for _, group := range groups {
+
+		// entries which are equal after the analysis (e.g. "PR" and "pr") are only used once
+		var entries [][]string
+		seen := make(map[string]bool)
+		for _, entry := range group {
+			terms := analyze(analyzer, entry)
+			key := strings.Join(terms, " ")
+			if len(terms) == 0 || seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			entries = append(entries, terms)
+		}
+
+		for index, pattern := range entries {
+			var alternatives [][]string
+			alternatives = append(alternatives, entries[:index]...)
+			alternatives = append(alternatives, entries[index+1:]...)
+			if len(alternatives) == 0 {
+				continue
+			}
+
+			table[pattern[0]] = append(table[pattern[0]], synonymRule{pattern, alternatives})
+		}
+	}
+
+	return table
+}
+
+// newSynonymFilter creates a token filter which adds the synonyms of the given groups to the terms
+// of the texts analyzed by the given analyzer.
+func newSynonymFilter(groups [][]string, analyzer analysis.Analyzer) *synonymFilter {
+	return &synonymFilter{newSynonymTable(groups, analyzer)}
+}
+
+// synonymFilter is a bleve token filter which adds the terms of the synonyms wherever the terms
+// of a synonym rule occur in a text. The terms of the alternatives start at the position of the matched
+// pattern and are highlighted at the location of the pattern.
+type synonymFilter struct {
+	table synonymTable
+}
+
+func (filter *synonymFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	if len(filter.table) == 0 {
+		return input
+	}
+
+	terms := make([]string, 0, len(input))
+	for _, token := range input {
+		terms = append(terms, string(token.Term))
+	}
+
+	output := make(analysis.TokenStream, 0, len(input))
+	for index, token := range input {
+		output = append(output, token)
+
+		for _, rule := range filter.table[terms[index]] {
+			if !hasTermsAt(terms, rule.pattern, index) {
+				continue
+			}
+
+			last := input[index+len(rule.pattern)-1]
+			for _, alternative := range rule.alternatives {
+				for offset, term := range alternative {
+					output = append(output, &analysis.Token{
+						Term:     []byte(term),
+						Start:    token.Start,
+						End:      last.End,
+						Position: token.Position + offset,
+						Type:     token.Type,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(output, func(i, j int) bool {
+		return output[i].Position < output[j].Position
+	})
+
+	return output
+}
+
+// hasTermsAt returns true if the given pattern occurs in the given terms at the given position.
+func hasTermsAt(terms, pattern []string, position int) bool {
+	if position+len(pattern) > len(terms) {
+		return false
+	}
+
+	for offset, term := range pattern {
+		if terms[position+offset] != term {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"testing"
+)
+
+func Test_indexSearch_AbbreviationWithSynonym_DocumentWithOfficialTermIsFound(t *testing.T) {
+	// arrange
+	synonyms := NewSynonyms([][]string{{"k8s", "kubernetes"}})
+	index := newTestIndex(t, synonyms, newDocument("a", "1", "en", map[field]string{fieldContent: "Setting up a Kubernetes cluster"}))
+
+	// act
+	hits := searchIndex(t, index, "k8s", allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "a" {
+		t.Errorf("The search for the synonym should have returned the document a but returned %v.", hits)
+	}
+}
+
+func Test_indexSearch_PhraseWithSynonym_DocumentWithAbbreviationIsFound(t *testing.T) {
+	// arrange
+	synonyms := NewSynonyms([][]string{{"PR", "pull request"}})
+	index := newTestIndex(t, synonyms,
+		newDocument("a", "1", "en", map[field]string{fieldContent: "Open a PR for every change"}),
+		newDocument("b", "2", "en", map[field]string{fieldContent: "A request to pull the changes"}))
+
+	// act
+	hits := searchIndex(t, index, `"pull request"`, allFields)
+
+	// assert
+	if len(hits) != 1 || hits[0].route != "a" {
+		t.Errorf("The phrase search should only have returned the document a but returned %v.", hits)
+	}
+}
+
+func Test_Fingerprint_NoSynonyms_FingerprintIsEmpty(t *testing.T) {
+	// arrange
+	var synonyms *Synonyms
+
+	// act
+	result := synonyms.Fingerprint()
+
+	// assert
+	if result != "" {
+		t.Errorf("The fingerprint of no synonyms should be empty but was %q.", result)
+	}
+}
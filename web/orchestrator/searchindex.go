@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+type SearchIndexOrchestrator struct {
+	*Orchestrator
+}
+
+// GetSearchIndex returns the searchable texts of all items which are not excluded from indexing.
+func (orchestrator *SearchIndexOrchestrator) GetSearchIndex() []viewmodel.SearchIndexEntry {
+
+	entries := make([]viewmodel.SearchIndexEntry, 0)
+	for _, item := range orchestrator.getAllItems() {
+		if item.MetaData.NoIndex {
+			continue
+		}
+
+		tags := make([]string, 0, len(item.MetaData.Tags))
+		tags = append(tags, item.MetaData.Tags...)
+
+		entries = append(entries, viewmodel.SearchIndexEntry{
+			Route:       orchestrator.itemPather().Path(item.Route().Value()),
+			Type:        item.Type.String(),
+			Title:       item.Title,
+			Description: item.Description,
+			Tags:        tags,
+			Content:     item.Content,
+		})
+	}
+
+	return entries
+}
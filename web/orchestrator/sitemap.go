@@ -16,7 +16,13 @@ type SitemapOrchestrator struct {
 	sitemap *viewmodel.SitemapEntry
 }
 
-func (orchestrator *SitemapOrchestrator) GetSitemap() viewmodel.SitemapEntry {
+// GetSitemap returns the sitemap of all items which can be shown with the given visibility.
+// The sitemap of all items is cached; the sitemaps for restricted visibilities are created on every request.
+func (orchestrator *SitemapOrchestrator) GetSitemap(visibility Visibility) viewmodel.SitemapEntry {
+
+	if !visibility.ShowsAllItems() {
+		return orchestrator.createSitemap(visibility)
+	}
 
 	if orchestrator.sitemap != nil {
 		return *orchestrator.sitemap
@@ -24,18 +30,7 @@ func (orchestrator *SitemapOrchestrator) GetSitemap() viewmodel.SitemapEntry {
 
 	// updateSitemap creates a new sitemap model and assigns it to the orchestrator cache.
 	updateSitemap := func(route route.Route) {
-		rootItem := orchestrator.rootItem()
-		if rootItem == nil {
-			orchestrator.logger.Fatal("No root item found")
-		}
-
-		sitemapModel := viewmodel.SitemapEntry{
-			Title:       rootItem.Title,
-			Description: rootItem.Description,
-			Children:      orchestrator.getSitemapEntries(rootItem.Route()),
-			Path:        "/",
-		}
-
+		sitemapModel := orchestrator.createSitemap(Visibility{})
 		orchestrator.sitemap = &sitemapModel
 	}
 
@@ -50,17 +45,32 @@ func (orchestrator *SitemapOrchestrator) GetSitemap() viewmodel.SitemapEntry {
 	return *orchestrator.sitemap
 }
 
-func (orchestrator *SitemapOrchestrator) getSitemapEntries(startRoute route.Route) []viewmodel.SitemapEntry {
+// createSitemap creates a sitemap model for all items which can be shown with the given visibility.
+func (orchestrator *SitemapOrchestrator) createSitemap(visibility Visibility) viewmodel.SitemapEntry {
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	return viewmodel.SitemapEntry{
+		Title:       rootItem.Title,
+		Description: rootItem.Description,
+		Children:      orchestrator.getSitemapEntries(rootItem.Route(), visibility),
+		Path:        orchestrator.basePath(),
+	}
+}
+
+func (orchestrator *SitemapOrchestrator) getSitemapEntries(startRoute route.Route, visibility Visibility) []viewmodel.SitemapEntry {
 
 	children := make([]viewmodel.SitemapEntry, 0)
-	for _, child := range orchestrator.getChildren(startRoute) {
+	for _, child := range visibleItems(orchestrator.getChildren(startRoute), visibility) {
 
 		childRoute := child.Route()
 
 		childModel := viewmodel.SitemapEntry{
 			Title:       child.Title,
 			Description: child.Description,
-			Children:      orchestrator.getSitemapEntries(childRoute),
+			Children:      orchestrator.getSitemapEntries(childRoute, visibility),
 			Path:        orchestrator.itemPather().Path(childRoute.Value()),
 		}
 
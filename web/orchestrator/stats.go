@@ -0,0 +1,194 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// indexRun records the duration of the last index run.
+type indexRun struct {
+	lock     sync.RWMutex
+	duration time.Duration
+	date     time.Time
+}
+
+// Set records an index run which took the given duration and finished at the given date.
+func (run *indexRun) Set(duration time.Duration, date time.Time) {
+	run.lock.Lock()
+	defer run.lock.Unlock()
+
+	run.duration = duration
+	run.date = date
+}
+
+// Get returns the duration and the date of the last index run.
+func (run *indexRun) Get() (time.Duration, time.Time) {
+	run.lock.RLock()
+	defer run.lock.RUnlock()
+
+	return run.duration, run.date
+}
+
+// StatsOrchestrator provides statistics about the items and files of the repository.
+type StatsOrchestrator struct {
+	*Orchestrator
+
+	// caches and indizes
+	stats *viewmodel.Stats
+}
+
+// GetStats returns the statistics of the repository. The statistics are created on first use and
+// updated whenever items are created, modified or deleted.
+func (orchestrator *StatsOrchestrator) GetStats() viewmodel.Stats {
+
+	if orchestrator.stats == nil {
+
+		// updateStats creates new statistics and assigns them to the orchestrator cache.
+		updateStats := func(route route.Route) {
+			stats := getStats(orchestrator.getAllItems())
+			orchestrator.stats = &stats
+		}
+
+		// register update callbacks
+		orchestrator.registerUpdateCallback("update stats", UpdateTypeNew, updateStats)
+		orchestrator.registerUpdateCallback("update stats", UpdateTypeModified, updateStats)
+		orchestrator.registerUpdateCallback("update stats", UpdateTypeDeleted, updateStats)
+
+		// build the cache
+		updateStats(route.New())
+	}
+
+	stats := *orchestrator.stats
+
+	stats.LastIndexRun = getIndexRunViewModel(&orchestrator.lastIndexRun)
+
+	return stats
+}
+
+// getStats returns the number of the given items by type, their total number of words, the number of items per
+// tag (the most frequent tag first) and the number and sizes of their files by mime type (the largest first).
+func getStats(items []*model.Item) viewmodel.Stats {
+	stats := viewmodel.Stats{
+		NumberOfItems: len(items),
+	}
+
+	itemsByType := make(map[string]int)
+	itemsByTag := make(map[string]int)
+	filesByMimeType := make(map[string]*viewmodel.StatsMimeType)
+
+	for _, item := range items {
+		itemsByType[item.Type.String()]++
+		stats.NumberOfWords += len(strings.Fields(item.Content))
+
+		for _, tag := range item.MetaData.Tags {
+			itemsByTag[tag]++
+		}
+
+		for _, file := range item.Files() {
+			mimeType, err := model.GetMimeType(file)
+			if err != nil || mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+
+			if filesByMimeType[mimeType] == nil {
+				filesByMimeType[mimeType] = &viewmodel.StatsMimeType{MimeType: mimeType}
+			}
+
+			size := getFileSize(file)
+			filesByMimeType[mimeType].NumberOfFiles++
+			filesByMimeType[mimeType].SizeInBytes += size
+
+			stats.Files.NumberOfFiles++
+			stats.Files.SizeInBytes += size
+		}
+	}
+
+	// item types
+	stats.ItemTypes = make([]viewmodel.StatsType, 0, len(itemsByType))
+	for name, numberOfItems := range itemsByType {
+		stats.ItemTypes = append(stats.ItemTypes, viewmodel.StatsType{Name: name, NumberOfItems: numberOfItems})
+	}
+
+	sort.Slice(stats.ItemTypes, func(i, j int) bool {
+		return stats.ItemTypes[i].Name < stats.ItemTypes[j].Name
+	})
+
+	// tag histogram
+	stats.Tags = make([]viewmodel.StatsTag, 0, len(itemsByTag))
+	for name, numberOfItems := range itemsByTag {
+		stats.Tags = append(stats.Tags, viewmodel.StatsTag{Name: name, NumberOfItems: numberOfItems})
+	}
+
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		if stats.Tags[i].NumberOfItems != stats.Tags[j].NumberOfItems {
+			return stats.Tags[i].NumberOfItems > stats.Tags[j].NumberOfItems
+		}
+
+		return stats.Tags[i].Name < stats.Tags[j].Name
+	})
+
+	// file sizes
+	stats.Files.Size = formatFileSize(stats.Files.SizeInBytes)
+	stats.Files.MimeTypes = make([]viewmodel.StatsMimeType, 0, len(filesByMimeType))
+	for _, mimeType := range filesByMimeType {
+		mimeType.Size = formatFileSize(mimeType.SizeInBytes)
+		stats.Files.MimeTypes = append(stats.Files.MimeTypes, *mimeType)
+	}
+
+	sort.Slice(stats.Files.MimeTypes, func(i, j int) bool {
+		if stats.Files.MimeTypes[i].SizeInBytes != stats.Files.MimeTypes[j].SizeInBytes {
+			return stats.Files.MimeTypes[i].SizeInBytes > stats.Files.MimeTypes[j].SizeInBytes
+		}
+
+		return stats.Files.MimeTypes[i].MimeType < stats.Files.MimeTypes[j].MimeType
+	})
+
+	return stats
+}
+
+// getFileSize returns the size of the given file in bytes; it returns zero if the size cannot be determined.
+func getFileSize(file *model.File) int64 {
+	var size int64
+	err := file.Data(func(content io.ReadSeeker) error {
+		var seekErr error
+		size, seekErr = content.Seek(0, io.SeekEnd)
+		return seekErr
+	})
+
+	if err != nil {
+		return 0
+	}
+
+	return size
+}
+
+// formatFileSize returns the given number of bytes in a human-readable form (e.g. "1.5 MB").
+func formatFileSize(sizeInBytes int64) string {
+	const unit = 1024
+	if sizeInBytes < unit {
+		return fmt.Sprintf("%d B", sizeInBytes)
+	}
+
+	size := float64(sizeInBytes) / unit
+	for _, prefix := range []string{"KB", "MB", "GB", "TB"} {
+		if size < unit || prefix == "TB" {
+			return fmt.Sprintf("%.1f %s", size, prefix)
+		}
+
+		size /= unit
+	}
+
+	return ""
+}
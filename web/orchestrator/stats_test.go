@@ -0,0 +1,67 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/model"
+)
+
+func Test_getStats_ItemsWithTags_ItemsAndWordsAreCountedAndTagsAreOrderedByFrequency(t *testing.T) {
+	// arrange
+	newStatsItem := func(path, content string, itemType model.ItemType, tags ...string) *model.Item {
+		item := newTestItem(path, time.Time{}, time.Time{})
+		item.Type = itemType
+		item.Content = content
+		item.MetaData.Tags = tags
+		return item
+	}
+
+	items := []*model.Item{
+		newStatsItem("a", "one two three", model.TypeDocument, "testing", "go"),
+		newStatsItem("b", "four\nfive", model.TypeDocument, "go"),
+		newStatsItem("c", "", model.TypePresentation, "alpha"),
+	}
+
+	// act
+	stats := getStats(items)
+
+	// assert
+	if stats.NumberOfItems != 3 || stats.NumberOfWords != 5 {
+		t.Errorf("getStats should count 3 items and 5 words but counted %d items and %d words.", stats.NumberOfItems, stats.NumberOfWords)
+	}
+
+	if len(stats.ItemTypes) != 2 || stats.ItemTypes[0].Name != "document" || stats.ItemTypes[0].NumberOfItems != 2 {
+		t.Errorf("getStats should count 2 documents and 1 presentation but counted %v.", stats.ItemTypes)
+	}
+
+	if len(stats.Tags) != 3 || stats.Tags[0].Name != "go" || stats.Tags[1].Name != "alpha" || stats.Tags[2].Name != "testing" {
+		t.Errorf("getStats should return the tags [go alpha testing] but returned %v.", stats.Tags)
+	}
+}
+
+func Test_formatFileSize_DifferentSizes_SizesAreFormattedWithTheirUnit(t *testing.T) {
+	// arrange
+	sizes := map[int64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1536:                   "1.5 KB",
+		5 * 1024 * 1024:        "5.0 MB",
+		3 * 1024 * 1024 * 1024: "3.0 GB",
+	}
+
+	for sizeInBytes, expected := range sizes {
+
+		// act
+		result := formatFileSize(sizeInBytes)
+
+		// assert
+		if result != expected {
+			t.Errorf("formatFileSize(%d) should return %q but returned %q.", sizeInBytes, expected, result)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/progress"
+	"github.com/andreaskoch/allmark/common/util/hashutil"
+	"github.com/andreaskoch/allmark/services/thumbnail"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusUnavailable  = "unavailable"
+	StatusReady        = "ready"
+	StatusInitializing = "initializing"
+	StatusDisabled     = "disabled"
+)
+
+type StatusOrchestrator struct {
+	*Orchestrator
+
+	thumbnailConversion *thumbnail.ConversionService
+
+	startTime  time.Time
+	configHash string
+}
+
+func newStatusOrchestrator(baseOrchestrator *Orchestrator, thumbnailConversion *thumbnail.ConversionService) *StatusOrchestrator {
+
+	configHash := ""
+	buffer := new(bytes.Buffer)
+	if err := config.NewJSONSerializer().SerializeConfig(buffer, &baseOrchestrator.config); err == nil {
+		configHash = hashutil.FromBytes(buffer.Bytes())
+	}
+
+	return &StatusOrchestrator{
+		Orchestrator:        baseOrchestrator,
+		thumbnailConversion: thumbnailConversion,
+		startTime:           time.Now(),
+		configHash:          configHash,
+	}
+}
+
+// GetHealth returns the status of the server. The server is considered healthy as long as it is able to respond.
+func (orchestrator *StatusOrchestrator) GetHealth() viewmodel.Status {
+	status := orchestrator.getStatus()
+	status.Status = StatusOK
+	return status
+}
+
+// GetReadiness returns the status of the server and a flag indicating whether the server is ready to
+// serve requests. The server is ready as soon as the repository and the search index have been created
+// (see InitializeIndexes).
+func (orchestrator *StatusOrchestrator) GetReadiness() (status viewmodel.Status, isReady bool) {
+
+	status = orchestrator.getStatus()
+	isReady = orchestrator.indexesAreInitialized()
+
+	status.Status = StatusOK
+	if !isReady {
+		status.Status = StatusUnavailable
+	}
+
+	return status, isReady
+}
+
+func (orchestrator *StatusOrchestrator) getStatus() viewmodel.Status {
+
+	numberOfItems := 0
+	if orchestrator.indexesAreInitialized() {
+		numberOfItems = orchestrator.index().Size()
+	}
+
+	return viewmodel.Status{
+		StartTime:  orchestrator.startTime.Format(time.RFC3339),
+		Uptime:     time.Since(orchestrator.startTime).Truncate(time.Second).String(),
+		ConfigHash: orchestrator.configHash,
+		Items:      numberOfItems,
+		Services: []viewmodel.ServiceStatus{
+			orchestrator.getRepositoryIndexStatus(),
+			orchestrator.getSearchIndexStatus(),
+			orchestrator.getThumbnailStatus(),
+		},
+		Progress: getProgressStatus(),
+	}
+}
+
+func (orchestrator *StatusOrchestrator) getRepositoryIndexStatus() viewmodel.ServiceStatus {
+	if !orchestrator.repositoryIndexIsCreated() {
+		return viewmodel.ServiceStatus{Name: "repository index", Status: StatusInitializing}
+	}
+
+	return viewmodel.ServiceStatus{
+		Name:    "repository index",
+		Status:  StatusReady,
+		Details: fmt.Sprintf("%d item(s)", orchestrator.index().Size()),
+	}
+}
+
+func (orchestrator *StatusOrchestrator) getSearchIndexStatus() viewmodel.ServiceStatus {
+	if !orchestrator.searchIndexIsCreated() {
+		return viewmodel.ServiceStatus{Name: "search index", Status: StatusInitializing}
+	}
+
+	return viewmodel.ServiceStatus{
+		Name:    "search index",
+		Status:  StatusReady,
+		Details: fmt.Sprintf("%d item(s)", orchestrator.searchIndex().Size()),
+	}
+}
+
+// getProgressStatus returns the progress of the last run of all long-running tasks.
+func getProgressStatus() []viewmodel.ProgressStatus {
+	var progressStatus []viewmodel.ProgressStatus
+	for _, task := range progress.Tasks() {
+		progressStatus = append(progressStatus, viewmodel.ProgressStatus{
+			Name:                   task.Name,
+			Unit:                   task.Unit,
+			Count:                  task.Count,
+			Total:                  task.Total,
+			RatePerSecond:          math.Round(task.RatePerSecond*10) / 10,
+			DurationInMilliseconds: task.Duration.Nanoseconds() / int64(time.Millisecond),
+			Finished:               task.Finished,
+		})
+	}
+
+	return progressStatus
+}
+
+func (orchestrator *StatusOrchestrator) getThumbnailStatus() viewmodel.ServiceStatus {
+	if orchestrator.thumbnailConversion == nil {
+		return viewmodel.ServiceStatus{Name: "thumbnails", Status: StatusDisabled}
+	}
+
+	conversionStatus := orchestrator.thumbnailConversion.Status()
+
+	status := StatusInitializing
+	if conversionStatus.InitialConversionCompleted {
+		status = StatusReady
+	}
+
+	return viewmodel.ServiceStatus{
+		Name:    "thumbnails",
+		Status:  status,
+		Details: fmt.Sprintf("%d item(s) pending", conversionStatus.PendingItems),
+	}
+}
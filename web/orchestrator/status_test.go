@@ -0,0 +1,171 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/logger/console"
+	"github.com/andreaskoch/allmark/common/logger/loglevel"
+	"github.com/andreaskoch/allmark/dataaccess/filesystem"
+	"github.com/andreaskoch/allmark/services/parser"
+	"github.com/andreaskoch/allmark/web/webpaths"
+)
+
+func newTestStatusOrchestrator(t *testing.T) *StatusOrchestrator {
+	directory := t.TempDir()
+	files := map[string]string{
+		"README.md":             "# Root\n\nThe root item.",
+		"documents/README.md":   "# Documents\n\nA list of documents.",
+		"documents/a/README.md": "# Document A\n\nThe first document.",
+	}
+
+	for path, content := range files {
+		filePath := filepath.Join(directory, path)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := console.New(loglevel.Off)
+	configuration := config.Default(directory)
+	configuration.Indexing.Enabled = false
+	configuration.LiveReload.Enabled = false
+
+	repository, err := filesystem.NewRepository(logger, directory, *configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	itemParser, err := parser.New(logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseOrchestrator := newBaseOrchestrator(logger, *configuration, repository, itemParser, nil, webpaths.WebPathProvider{}, nil, nil)
+	t.Cleanup(func() {
+		if baseOrchestrator.fulltextIndex != nil {
+			baseOrchestrator.fulltextIndex.Close()
+		}
+	})
+
+	return newStatusOrchestrator(baseOrchestrator, nil)
+}
+
+func Test_GetReadiness_IndexesNotInitialized_ServerIsNotReady(t *testing.T) {
+	// arrange
+	orchestrator := newTestStatusOrchestrator(t)
+
+	// act
+	status, isReady := orchestrator.GetReadiness()
+
+	// assert
+	if isReady || status.Status != StatusUnavailable {
+		t.Errorf("The server should not be ready before the indizes have been initialized but the status was %q.", status.Status)
+	}
+}
+
+func Test_GetReadiness_IndexesInitialized_ServerIsReady(t *testing.T) {
+	// arrange
+	orchestrator := newTestStatusOrchestrator(t)
+
+	// act
+	orchestrator.InitializeIndexes()
+	status, isReady := orchestrator.GetReadiness()
+
+	// assert
+	if !isReady || status.Status != StatusOK {
+		t.Errorf("The server should be ready after the indizes have been initialized but the status was %q.", status.Status)
+	}
+
+	if status.Items != 3 {
+		t.Errorf("The status should report %d items but reported %d.", 3, status.Items)
+	}
+}
+
+func Test_InitializeIndexes_ConcurrentRequests_IndexesAreCreatedOnce(t *testing.T) {
+	// arrange
+	orchestrator := newTestStatusOrchestrator(t)
+
+	// act
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		waitGroup.Add(4)
+		go func() { defer waitGroup.Done(); orchestrator.InitializeIndexes() }()
+		go func() { defer waitGroup.Done(); orchestrator.index() }()
+		go func() { defer waitGroup.Done(); orchestrator.searchIndex() }()
+		go func() { defer waitGroup.Done(); orchestrator.GetReadiness() }()
+	}
+
+	waitGroup.Wait()
+
+	// assert
+	if _, isReady := orchestrator.GetReadiness(); !isReady {
+		t.Errorf("The server should be ready after the indizes have been initialized.")
+	}
+
+	if callbacks := orchestrator.getUpdateCallbacks(UpdateTypeNew); len(callbacks) != 2 {
+		t.Errorf("The update callbacks of the repository index and the search index should be registered once but %d callbacks were registered.", len(callbacks))
+	}
+}
+
+func Test_GetReadiness_OnlyRepositoryIndexCreated_SearchIndexIsInitializing(t *testing.T) {
+	// arrange
+	orchestrator := newTestStatusOrchestrator(t)
+
+	// act
+	orchestrator.index()
+	status, isReady := orchestrator.GetReadiness()
+
+	// assert
+	if isReady {
+		t.Errorf("The server should not be ready before the search index has been created.")
+	}
+
+	services := make(map[string]string)
+	for _, service := range status.Services {
+		services[service.Name] = service.Status
+	}
+
+	if services["repository index"] != StatusReady {
+		t.Errorf("The repository index should be %q but was %q.", StatusReady, services["repository index"])
+	}
+
+	if services["search index"] != StatusInitializing {
+		t.Errorf("The search index should be %q but was %q.", StatusInitializing, services["search index"])
+	}
+}
+
+func Test_GetReadiness_IndexesInitialized_SearchIndexIsReady(t *testing.T) {
+	// arrange
+	orchestrator := newTestStatusOrchestrator(t)
+
+	// act
+	orchestrator.InitializeIndexes()
+	status, _ := orchestrator.GetReadiness()
+
+	// assert
+	for _, service := range status.Services {
+		if service.Name != "search index" {
+			continue
+		}
+
+		if service.Status != StatusReady || service.Details != "3 item(s)" {
+			t.Errorf("The search index should be %q with 3 items but was %q (%s).", StatusReady, service.Status, service.Details)
+		}
+
+		return
+	}
+
+	t.Errorf("The status should contain the search index.")
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"strings"
 
 	"github.com/andreaskoch/allmark/common/route"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
@@ -19,16 +20,25 @@ var (
 	tagCloudEntryLevels = 6
 )
 
+// TagHierarchySeparator separates the components of hierarchical tag names (e.g. "project/alpha").
+const TagHierarchySeparator = "/"
+
 type TagsOrchestrator struct {
 	*Orchestrator
 
 	// caches and indizes
 	tags     []viewmodel.Tag
+	tagTree  []viewmodel.Tag
 	tagCloud viewmodel.TagCloud
 }
 
-// GetTags returns a list of all known tag models.
-func (orchestrator *TagsOrchestrator) GetTags() []viewmodel.Tag {
+// GetTags returns a list of the tag models of all items which can be shown with the given visibility.
+// The tags of all items are cached; the tags for restricted visibilities are created on every request.
+func (orchestrator *TagsOrchestrator) GetTags(visibility Visibility) []viewmodel.Tag {
+
+	if !visibility.ShowsAllItems() {
+		return orchestrator.createTags(visibility)
+	}
 
 	if orchestrator.tags != nil {
 		return orchestrator.tags
@@ -36,69 +46,125 @@ func (orchestrator *TagsOrchestrator) GetTags() []viewmodel.Tag {
 
 	// updateTags creates a tags list and assigns it to the orchestrator cache.
 	updateTags := func(route route.Route) {
+		orchestrator.tags = orchestrator.createTags(Visibility{})
+	}
 
-		rootItem := orchestrator.rootItem()
-		if rootItem == nil {
-			orchestrator.logger.Fatal("No root item found")
-		}
+	asyncUpdate := func(route route.Route) {
+		go updateTags(route)
+	}
 
-		// items by tag
-		itemsByTag := make(map[string][]viewmodel.Model)
-		for _, item := range orchestrator.getAllItems() {
+	// register update callbacks
+	orchestrator.registerUpdateCallback("update tags", UpdateTypeNew, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tags", UpdateTypeModified, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tags", UpdateTypeDeleted, asyncUpdate)
 
-			itemViewModel := viewmodel.Model{
-				Base: getBaseModel(rootItem, item, orchestrator.config),
-			}
+	// build the cache
+	updateTags(route.New())
 
-			for _, tag := range item.MetaData.Tags {
-				if items, exists := itemsByTag[tag]; exists {
-					itemsByTag[tag] = append(items, itemViewModel)
-				} else {
-					itemsByTag[tag] = []viewmodel.Model{itemViewModel}
-				}
-			}
+	return orchestrator.tags
+}
 
-		}
+// createTags creates the tag models of all items which can be shown with the given visibility.
+func (orchestrator *TagsOrchestrator) createTags(visibility Visibility) []viewmodel.Tag {
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	// items by tag
+	itemsByTag := make(map[string][]viewmodel.Model)
+	for _, item := range visibleItems(orchestrator.getAllItems(), visibility) {
 
-		// create tag models
-		tags := make([]viewmodel.Tag, 0)
-		for tag, items := range itemsByTag {
+		itemViewModel := viewmodel.Model{
+			Base: getBaseModel(rootItem, item, orchestrator.config),
+		}
 
-			// create view model
-			tagModel := viewmodel.Tag{
-				Name:     tag,
-				Anchor:   url.QueryEscape(tag),
-				Route:    orchestrator.tagPather().Path(url.QueryEscape(tag)),
-				Children: items,
+		for _, tag := range item.MetaData.Tags {
+			if items, exists := itemsByTag[tag]; exists {
+				itemsByTag[tag] = append(items, itemViewModel)
+			} else {
+				itemsByTag[tag] = []viewmodel.Model{itemViewModel}
 			}
+		}
+
+	}
 
-			// append to list
-			tags = append(tags, tagModel)
+	// create tag models
+	tags := make([]viewmodel.Tag, 0)
+	for tag, items := range itemsByTag {
+
+		// create view model
+		tagModel := viewmodel.Tag{
+			Name:             tag,
+			Anchor:           url.QueryEscape(tag),
+			Route:            orchestrator.tagPather().Path(url.QueryEscape(tag)),
+			Children:         items,
+			Label:            getTagLabel(tag),
+			NumberOfChildren: len(items),
 		}
 
-		// sort the tags
-		viewmodel.SortTagBy(tagsByName).Sort(tags)
+		// append to list
+		tags = append(tags, tagModel)
+	}
+
+	// sort the tags
+	viewmodel.SortTagBy(tagsByName).Sort(tags)
+
+	return tags
+}
 
-		orchestrator.tags = tags
+// GetTagTree returns the top-level tags of the tag hierarchy of all items which can be shown with the given visibility.
+// Hierarchical tags (e.g. "project/alpha") are sub tags of their parent tags (e.g. "project"), even if no item is tagged
+// with the parent tag itself.
+func (orchestrator *TagsOrchestrator) GetTagTree(visibility Visibility) []viewmodel.Tag {
+
+	if !visibility.ShowsAllItems() {
+		return orchestrator.createTagTree(orchestrator.GetTags(visibility))
+	}
+
+	if orchestrator.tagTree != nil {
+		return orchestrator.tagTree
+	}
+
+	// updateTagTree creates a new tag tree and assigns it to the orchestrator cache.
+	updateTagTree := func(route route.Route) {
+		orchestrator.tagTree = orchestrator.createTagTree(orchestrator.GetTags(Visibility{}))
 	}
 
 	asyncUpdate := func(route route.Route) {
-		go updateTags(route)
+		go updateTagTree(route)
 	}
 
 	// register update callbacks
-	orchestrator.registerUpdateCallback("update tags", UpdateTypeNew, asyncUpdate)
-	orchestrator.registerUpdateCallback("update tags", UpdateTypeModified, asyncUpdate)
-	orchestrator.registerUpdateCallback("update tags", UpdateTypeDeleted, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tag tree", UpdateTypeNew, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tag tree", UpdateTypeModified, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tag tree", UpdateTypeDeleted, asyncUpdate)
 
 	// build the cache
-	updateTags(route.New())
+	updateTagTree(route.New())
 
-	return orchestrator.tags
+	return orchestrator.tagTree
+}
+
+// createTagTree arranges the given tags in a hierarchy and returns the top-level tags.
+func (orchestrator *TagsOrchestrator) createTagTree(tags []viewmodel.Tag) []viewmodel.Tag {
+	return getTagTree(tags, func(name string) viewmodel.Tag {
+		return viewmodel.Tag{
+			Name:   name,
+			Anchor: url.QueryEscape(name),
+			Route:  orchestrator.tagPather().Path(url.QueryEscape(name)),
+			Label:  getTagLabel(name),
+		}
+	})
 }
 
-// GetTagCloud returns the latest tag cloud viewmodel.
-func (orchestrator *TagsOrchestrator) GetTagCloud() viewmodel.TagCloud {
+// GetTagCloud returns the latest tag cloud viewmodel for all items which can be shown with the given visibility.
+func (orchestrator *TagsOrchestrator) GetTagCloud(visibility Visibility) viewmodel.TagCloud {
+
+	if !visibility.ShowsAllItems() {
+		return orchestrator.createTagCloud(orchestrator.GetTags(visibility))
+	}
 
 	if orchestrator.tagCloud != nil {
 		return orchestrator.tagCloud
@@ -106,63 +172,68 @@ func (orchestrator *TagsOrchestrator) GetTagCloud() viewmodel.TagCloud {
 
 	// updateTagCloud creates a new tag cloud and assigns it to the orchestrator cache.
 	updateTagCloud := func(route route.Route) {
-		cloud := make(viewmodel.TagCloud, 0)
+		orchestrator.tagCloud = orchestrator.createTagCloud(orchestrator.GetTags(Visibility{}))
+	}
 
-		minNumberOfItems := 1
-		maxNumberOfItems := 1
+	asyncUpdate := func(route route.Route) {
+		go updateTagCloud(route)
+	}
 
-		for _, tag := range orchestrator.GetTags() {
+	// register update callbacks
+	orchestrator.registerUpdateCallback("update tagcloud", UpdateTypeNew, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tagcloud", UpdateTypeModified, asyncUpdate)
+	orchestrator.registerUpdateCallback("update tagcloud", UpdateTypeDeleted, asyncUpdate)
 
-			// calculate the number of items per tag
-			numberItemsPerTag := len(tag.Children)
+	// build the cache
+	updateTagCloud(route.New())
 
-			// update the maximum number of items per tag
-			if numberItemsPerTag > maxNumberOfItems {
-				maxNumberOfItems = numberItemsPerTag
-			}
+	return orchestrator.tagCloud
+}
 
-			// update the minimum number of items per tag
-			if numberItemsPerTag < minNumberOfItems {
-				minNumberOfItems = numberItemsPerTag
-			}
+// createTagCloud creates a tag cloud for the given tags.
+func (orchestrator *TagsOrchestrator) createTagCloud(tags []viewmodel.Tag) viewmodel.TagCloud {
+	cloud := make(viewmodel.TagCloud, 0)
 
-			// create a new tag cloud entry
-			tagCloudEntry := viewmodel.TagCloudEntry{
-				Name:             tag.Name,
-				Anchor:           url.QueryEscape(tag.Name),
-				Route:            orchestrator.tagPather().Path(url.QueryEscape(tag.Name)),
-				NumberOfChildren: numberItemsPerTag,
-			}
+	minNumberOfItems := 1
+	maxNumberOfItems := 1
+
+	for _, tag := range tags {
+
+		// calculate the number of items per tag
+		numberItemsPerTag := len(tag.Children)
 
-			cloud = append(cloud, tagCloudEntry)
+		// update the maximum number of items per tag
+		if numberItemsPerTag > maxNumberOfItems {
+			maxNumberOfItems = numberItemsPerTag
 		}
 
-		// update the tag cloud entry levels according
-		// to the recorded min and max number of items
-		for index, entry := range cloud {
-			// calculate the entry level
-			cloud[index].Level = getTagCloudEntryLevel(entry.NumberOfChildren, minNumberOfItems, maxNumberOfItems, tagCloudEntryLevels)
+		// update the minimum number of items per tag
+		if numberItemsPerTag < minNumberOfItems {
+			minNumberOfItems = numberItemsPerTag
 		}
 
-		// sort tags by name
-		viewmodel.SortTagCloudBy(tagCloudEntriesByName).Sort(cloud)
+		// create a new tag cloud entry
+		tagCloudEntry := viewmodel.TagCloudEntry{
+			Name:             tag.Name,
+			Anchor:           url.QueryEscape(tag.Name),
+			Route:            orchestrator.tagPather().Path(url.QueryEscape(tag.Name)),
+			NumberOfChildren: numberItemsPerTag,
+		}
 
-		orchestrator.tagCloud = cloud
+		cloud = append(cloud, tagCloudEntry)
 	}
 
-	asyncUpdate := func(route route.Route) {
-		go updateTagCloud(route)
+	// update the tag cloud entry levels according
+	// to the recorded min and max number of items
+	for index, entry := range cloud {
+		// calculate the entry level
+		cloud[index].Level = getTagCloudEntryLevel(entry.NumberOfChildren, minNumberOfItems, maxNumberOfItems, tagCloudEntryLevels)
 	}
 
-	// register update callbacks
-	orchestrator.registerUpdateCallback("update tagcloud", UpdateTypeNew, asyncUpdate)
-	orchestrator.registerUpdateCallback("update tagcloud", UpdateTypeModified, asyncUpdate)
-	orchestrator.registerUpdateCallback("update tagcloud", UpdateTypeDeleted, asyncUpdate)
-
-	// build the cache
-	updateTagCloud(route.New())
+	// sort tags by name
+	viewmodel.SortTagCloudBy(tagCloudEntriesByName).Sort(cloud)
 
-	return orchestrator.tagCloud
+	return cloud
 }
 
 func (orchestrator *TagsOrchestrator) getItemTags(route route.Route) []viewmodel.Tag {
@@ -182,6 +253,7 @@ func (orchestrator *TagsOrchestrator) getItemTags(route route.Route) []viewmodel
 			Name:   tag,
 			Anchor: url.QueryEscape(tag),
 			Route:  orchestrator.tagPather().Path(url.QueryEscape(tag)),
+			Label:  getTagLabel(tag),
 		}
 
 		// append to list
@@ -191,6 +263,101 @@ func (orchestrator *TagsOrchestrator) getItemTags(route route.Route) []viewmodel
 	return tags
 }
 
+// getTagTree arranges the given tags in a hierarchy by the components of their names and returns the top-level tags.
+// The given function creates the parent tags no item is tagged with. The number of children of every tag includes the
+// items of its sub tags.
+func getTagTree(tags []viewmodel.Tag, newTag func(name string) viewmodel.Tag) []viewmodel.Tag {
+
+	type tagNode struct {
+		tag     viewmodel.Tag
+		subTags []string
+		items   map[string]bool
+	}
+
+	nodes := make(map[string]*tagNode)
+	topLevelTags := make([]string, 0)
+
+	// getNode returns the node of the tag with the given components and creates the nodes of its parents on the way
+	var getNode func(components []string) *tagNode
+	getNode = func(components []string) *tagNode {
+		name := strings.Join(components, TagHierarchySeparator)
+		if node, exists := nodes[name]; exists {
+			return node
+		}
+
+		node := &tagNode{tag: newTag(name), items: make(map[string]bool)}
+		nodes[name] = node
+
+		if len(components) == 1 {
+			topLevelTags = append(topLevelTags, name)
+		} else {
+			parent := getNode(components[:len(components)-1])
+			parent.subTags = append(parent.subTags, name)
+		}
+
+		return node
+	}
+
+	for _, tag := range tags {
+		components := getTagComponents(tag.Name)
+		if len(components) == 0 {
+			continue
+		}
+
+		node := getNode(components)
+		node.tag.Name = tag.Name
+		node.tag.Anchor = tag.Anchor
+		node.tag.Route = tag.Route
+		node.tag.Children = append(node.tag.Children, tag.Children...)
+
+		// count the items for the tag and all of its parents
+		for level := len(components); level > 0; level-- {
+			parent := nodes[strings.Join(components[:level], TagHierarchySeparator)]
+			for _, child := range tag.Children {
+				parent.items[child.Route] = true
+			}
+		}
+	}
+
+	var getTags func(names []string) []viewmodel.Tag
+	getTags = func(names []string) []viewmodel.Tag {
+		tags := make([]viewmodel.Tag, 0, len(names))
+		for _, name := range names {
+			node := nodes[name]
+			node.tag.NumberOfChildren = len(node.items)
+			node.tag.SubTags = getTags(node.subTags)
+			tags = append(tags, node.tag)
+		}
+
+		viewmodel.SortTagBy(tagsByName).Sort(tags)
+		return tags
+	}
+
+	return getTags(topLevelTags)
+}
+
+// getTagComponents returns the non-empty components of the given hierarchical tag name (e.g. ["project", "alpha"]).
+func getTagComponents(name string) []string {
+	components := make([]string, 0)
+	for _, component := range strings.Split(name, TagHierarchySeparator) {
+		if component = strings.TrimSpace(component); component != "" {
+			components = append(components, component)
+		}
+	}
+
+	return components
+}
+
+// getTagLabel returns the last component of the given hierarchical tag name (e.g. "alpha" for "project/alpha").
+func getTagLabel(name string) string {
+	components := getTagComponents(name)
+	if len(components) == 0 {
+		return name
+	}
+
+	return components[len(components)-1]
+}
+
 func getTagCloudEntryLevel(numberOfChildren, minNumberOfChildren, maxNumberOfChildren, levelCount int) int {
 
 	// check the number of children for negative numbers
@@ -0,0 +1,77 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+func newTestTag(name string, itemRoutes ...string) viewmodel.Tag {
+	children := make([]viewmodel.Model, 0, len(itemRoutes))
+	for _, itemRoute := range itemRoutes {
+		children = append(children, viewmodel.Model{Base: viewmodel.Base{Route: itemRoute}})
+	}
+
+	return viewmodel.Tag{Name: name, Label: getTagLabel(name), Children: children}
+}
+
+func Test_getTagTree_HierarchicalTags_SubTagsAreNestedBelowTheirParents(t *testing.T) {
+	// arrange
+	tags := []viewmodel.Tag{
+		newTestTag("go", "/a"),
+		newTestTag("project/beta", "/b"),
+		newTestTag("project/alpha", "/a", "/c"),
+	}
+
+	// act
+	tree := getTagTree(tags, func(name string) viewmodel.Tag { return viewmodel.Tag{Name: name, Label: getTagLabel(name)} })
+
+	// assert
+	if len(tree) != 2 || tree[0].Name != "go" || tree[1].Name != "project" {
+		t.Fatalf("getTagTree should return the top-level tags [go project] but returned %v.", tree)
+	}
+
+	project := tree[1]
+	if len(project.SubTags) != 2 || project.SubTags[0].Name != "project/alpha" || project.SubTags[1].Label != "beta" {
+		t.Errorf("The tag %q should have the sub tags [project/alpha project/beta] but has %v.", project.Name, project.SubTags)
+	}
+}
+
+func Test_getTagTree_ItemsWithSeveralSubTags_ParentCountsEveryItemOnce(t *testing.T) {
+	// arrange
+	tags := []viewmodel.Tag{
+		newTestTag("project", "/a"),
+		newTestTag("project/alpha", "/a", "/b"),
+		newTestTag("project/alpha/docs", "/b", "/c"),
+	}
+
+	// act
+	tree := getTagTree(tags, func(name string) viewmodel.Tag { return viewmodel.Tag{Name: name} })
+
+	// assert
+	if len(tree) != 1 || tree[0].NumberOfChildren != 3 || len(tree[0].Children) != 1 {
+		t.Fatalf("The tag %q should count 3 items and have 1 child of its own but the tree was %v.", "project", tree)
+	}
+
+	alpha := tree[0].SubTags[0]
+	if alpha.NumberOfChildren != 3 || len(alpha.SubTags) != 1 || alpha.SubTags[0].NumberOfChildren != 2 {
+		t.Errorf("The tag %q should count 3 items and its sub tag should count 2 items but the tags were %v.", alpha.Name, alpha)
+	}
+}
+
+func Test_getTagLabel_HierarchicalTagName_LastComponentIsReturned(t *testing.T) {
+	// arrange
+	name := "project / alpha"
+
+	// act
+	result := getTagLabel(name)
+
+	// assert
+	if result != "alpha" {
+		t.Errorf("getTagLabel(%q) should return %q but returned %q.", name, "alpha", result)
+	}
+}
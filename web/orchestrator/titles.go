@@ -13,7 +13,8 @@ type TitlesOrchestrator struct {
 	*Orchestrator
 }
 
-func (orchestrator *TitlesOrchestrator) GetTitles() []viewmodel.Title {
+// GetTitles returns the titles of all items which can be shown with the given visibility.
+func (orchestrator *TitlesOrchestrator) GetTitles(visibility Visibility) []viewmodel.Title {
 
 	rootItem := orchestrator.rootItem()
 	if rootItem == nil {
@@ -21,7 +22,7 @@ func (orchestrator *TitlesOrchestrator) GetTitles() []viewmodel.Title {
 	}
 
 	titleModels := make([]viewmodel.Title, 0)
-	for _, item := range orchestrator.getAllItems() {
+	for _, item := range visibleItems(orchestrator.getAllItems(), visibility) {
 
 		titleModels = append(titleModels, viewmodel.Title{
 			Value:  item.Title,
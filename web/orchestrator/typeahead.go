@@ -7,6 +7,7 @@ package orchestrator
 import (
 	"github.com/andreaskoch/allmark/web/orchestrator/search"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"net/url"
 	"strings"
 )
 
@@ -14,7 +15,8 @@ type TypeAheadOrchestrator struct {
 	*Orchestrator
 }
 
-func (orchestrator *TypeAheadOrchestrator) GetSuggestions(keywords string) []viewmodel.TypeAhead {
+// GetSuggestions returns the top search results for the given keywords which can be shown with the given visibility.
+func (orchestrator *TypeAheadOrchestrator) GetSuggestions(keywords string, visibility Visibility) []viewmodel.TypeAhead {
 
 	// collect the search results
 	typeAheadResults := make([]viewmodel.TypeAhead, 0)
@@ -24,7 +26,7 @@ func (orchestrator *TypeAheadOrchestrator) GetSuggestions(keywords string) []vie
 	if strings.TrimSpace(keywords) != "" {
 
 		// execute the search
-		searchResultItems := orchestrator.search(keywords, maximumNumberOfResults)
+		searchResultItems := orchestrator.search(keywords, maximumNumberOfResults, visibility)
 
 		// prepare the result models
 		for _, searchResult := range searchResultItems {
@@ -36,6 +38,34 @@ func (orchestrator *TypeAheadOrchestrator) GetSuggestions(keywords string) []vie
 	return typeAheadResults
 }
 
+// GetCompletions returns up to the given number of titles, aliases and tags which start with the given text
+// and belong to items which can be shown with the given visibility.
+func (orchestrator *TypeAheadOrchestrator) GetCompletions(text string, maximumNumberOfSuggestions int, visibility Visibility) []viewmodel.Suggestion {
+
+	suggestions := make([]viewmodel.Suggestion, 0)
+	for _, suggestion := range orchestrator.suggestions().Suggest(text, maximumNumberOfSuggestions, visibility.IsVisible) {
+
+		if suggestion.Type == search.SuggestionTypeTag {
+			suggestions = append(suggestions, viewmodel.Suggestion{
+				Value: suggestion.Value,
+				Type:  suggestion.Type,
+				Route: orchestrator.tagPather().Path(url.QueryEscape(suggestion.Value)),
+			})
+
+			continue
+		}
+
+		suggestions = append(suggestions, viewmodel.Suggestion{
+			Value: suggestion.Value,
+			Type:  suggestion.Type,
+			Route: orchestrator.itemPather().Path(suggestion.Route.Value()),
+			Path:  suggestion.Route.OriginalValue(),
+		})
+	}
+
+	return suggestions
+}
+
 func (orchestrator *TypeAheadOrchestrator) createTypeAheadResultModel(searchResult search.Result) viewmodel.TypeAhead {
 
 	item := orchestrator.getItem(searchResult.Route)
@@ -55,6 +85,6 @@ func (orchestrator *TypeAheadOrchestrator) createTypeAheadResultModel(searchResu
 		Path:        item.Route().OriginalValue(),
 
 		Value:  item.Title,
-		Tokens: strings.Split(searchResult.StoreValue, " "),
+		Tokens: append(strings.Fields(item.Title), item.MetaData.Tags...),
 	}
 }
@@ -23,8 +23,9 @@ func (orchestrator *UpdateOrchestrator) StopWatching(route route.Route) {
 	orchestrator.repository.StopWatching(route)
 }
 
-func (orchestrator *UpdateOrchestrator) GetUpdatedModel(itemRoute route.Route) (viewModel viewmodel.Model, found bool) {
-	model, found := orchestrator.viewModelOrchestrator.GetFullViewModel(itemRoute)
+// GetUpdatedModel returns the current view model of the item with the given route for the given visibility.
+func (orchestrator *UpdateOrchestrator) GetUpdatedModel(itemRoute route.Route, visibility Visibility) (viewModel viewmodel.Model, found bool) {
+	model, found := orchestrator.viewModelOrchestrator.GetFullViewModel(itemRoute, visibility)
 	if !found {
 		return viewmodel.Model{}, false
 	}
@@ -6,6 +6,7 @@ package orchestrator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,12 +25,12 @@ func getBaseModel(root, item *model.Item, config config.Config) viewmodel.Base {
 		Type:    item.Type.String(),
 		Route:   item.Route().Value(),
 		Level:   item.Route().Level(),
-		BaseURL: GetBaseURL(item.Route()),
+		BaseURL: GetBaseURL(config.BasePath(), item.Route()),
 		Aliases: getAliasViewModels(item),
 
-		PrintURL:    GetTypedItemURL(item.Route(), "print"),
-		JSONURL:     GetTypedItemURL(item.Route(), "json"),
-		MarkdownURL: GetTypedItemURL(item.Route(), "markdown"),
+		PrintURL:    GetTypedItemURL(config.BasePath(), item.Route(), "print"),
+		JSONURL:     GetTypedItemURL(config.BasePath(), item.Route(), "json"),
+		MarkdownURL: GetTypedItemURL(config.BasePath(), item.Route(), "markdown"),
 
 		PageTitle:   getPageTitleForItem(root, item),
 		Title:       item.Title,
@@ -39,8 +40,19 @@ func getBaseModel(root, item *model.Item, config config.Config) viewmodel.Base {
 		DirectionTag:     getDirectionCode(item.MetaData.Direction),
 		CreationDate:     getFormattedDate(item.MetaData.CreationDate),
 		LastModifiedDate: getFormattedDate(item.MetaData.LastModifiedDate),
+		Locale:           item.MetaData.Language,
+
+		NoIndex:  item.MetaData.NoIndex,
+		Template: getItemTemplateName(item, config),
+
+		HeadSnippet:   item.MetaData.HeadSnippet,
+		FooterSnippet: item.MetaData.FooterSnippet,
+
+		TagNames: item.MetaData.Tags,
+		MetaData: item.MetaData.Fields,
 
 		LiveReloadEnabled: config.LiveReload.Enabled,
+		WebAppEnabled:     config.Web.WebApp.Enabled,
 	}
 
 	if item.Route().Level() > 0 {
@@ -53,6 +65,16 @@ func getBaseModel(root, item *model.Item, config config.Config) viewmodel.Base {
 
 }
 
+// getItemTemplateName returns the name of the template the given item is rendered with: the template
+// from the meta data of the item or the template configured for its type ("" for the default template).
+func getItemTemplateName(item *model.Item, config config.Config) string {
+	if item.MetaData.Template != "" {
+		return item.MetaData.Template
+	}
+
+	return config.Theme.ItemTemplate(item.Type.String())
+}
+
 // Get the formatted date if the supplied date is initialized; otherwise return an empty string.
 func getFormattedDate(date time.Time) string {
 
@@ -87,17 +109,17 @@ func getPageTitleForItem(rootItem, item *model.Item) string {
 	return fmt.Sprintf("%s - %s", item.Title, rootItem.Title)
 }
 
-func GetBaseURL(route route.Route) string {
+func GetBaseURL(basePath string, route route.Route) string {
 	url := route.Value()
 	if url != "" {
-		return "/" + url + "/"
+		return basePath + url + "/"
 	}
 
-	return "/"
+	return basePath
 }
 
-func GetTypedItemURL(route route.Route, urlType string) string {
-	itemPath := GetBaseURL(route)
+func GetTypedItemURL(basePath string, route route.Route, urlType string) string {
+	itemPath := GetBaseURL(basePath, route)
 	itemPath = strings.TrimSuffix(itemPath, "/")
 
 	if len(itemPath) > 0 {
@@ -121,6 +143,39 @@ func sortItemsByDate(model1, model2 *model.Item) bool {
 
 }
 
+// sortChildren sorts the given items in the given child sort order (e.g. "title"). Items which are
+// equal in this order are sorted by their title and route.
+func sortChildren(items []*model.Item, order string) {
+	byTitle := func(item1, item2 *model.Item) bool {
+		title1, title2 := strings.ToLower(item1.Title), strings.ToLower(item2.Title)
+		if title1 != title2 {
+			return title1 < title2
+		}
+
+		return item1.Route().Value() < item2.Route().Value()
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		switch order {
+		case config.ChildSortOrderTitle:
+			return byTitle(items[i], items[j])
+
+		case config.ChildSortOrderWeight:
+			if items[i].MetaData.Weight != items[j].MetaData.Weight {
+				return items[i].MetaData.Weight < items[j].MetaData.Weight
+			}
+
+			return byTitle(items[i], items[j])
+		}
+
+		if !items[i].MetaData.CreationDate.Equal(items[j].MetaData.CreationDate) {
+			return sortItemsByDate(items[i], items[j])
+		}
+
+		return byTitle(items[i], items[j])
+	})
+}
+
 func pagedViewmodels(viewmodels []viewmodel.Model, pageSize, page int) (latest []viewmodel.Model, found bool) {
 
 	// determine the start index
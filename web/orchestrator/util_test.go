@@ -7,6 +7,9 @@ package orchestrator
 import (
 	"testing"
 	"time"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/model"
 )
 
 func Test_getFormattedDate_DateIsZero_ReturnsEmptyString(t *testing.T) {
@@ -22,3 +25,43 @@ func Test_getFormattedDate_DateIsZero_ReturnsEmptyString(t *testing.T) {
 		t.Errorf("The result of getFormattedDate(%q) should be %q but was %q.", inputDate, expected, result)
 	}
 }
+
+func newTestChild(path, title string, weight int, created time.Time) *model.Item {
+	item := newTestItem(path, created, time.Time{})
+	item.Title = title
+	item.MetaData.Weight = weight
+	return item
+}
+
+func Test_sortChildren_DifferentOrders_ChildrenAreSortedInTheGivenOrder(t *testing.T) {
+	// arrange
+	day := func(number int) time.Time { return time.Date(2015, 8, number, 12, 0, 0, 0, time.UTC) }
+	newChildren := func() []*model.Item {
+		return []*model.Item{
+			newTestChild("b", "Beta", 2, day(3)),
+			newTestChild("a", "alpha", 3, day(1)),
+			newTestChild("c", "Gamma", 1, day(2)),
+		}
+	}
+
+	expectedRoutes := map[string][]string{
+		config.ChildSortOrderDate:   {"b", "c", "a"},
+		config.ChildSortOrderTitle:  {"a", "b", "c"},
+		config.ChildSortOrderWeight: {"c", "b", "a"},
+	}
+
+	for order, expected := range expectedRoutes {
+		children := newChildren()
+
+		// act
+		sortChildren(children, order)
+
+		// assert
+		for index, child := range children {
+			if child.Route().Value() != expected[index] {
+				t.Errorf("sortChildren(%q) should return %v but position %d is %q.", order, expected, index, child.Route().Value())
+				break
+			}
+		}
+	}
+}
@@ -26,23 +26,48 @@ type ViewModelOrchestrator struct {
 	fullViewmodelsByRoute ViewModelCache
 }
 
-// GetFullViewModel returns a fully-initialized viewmodel for the given route.
-func (orchestrator *ViewModelOrchestrator) GetFullViewModel(itemRoute route.Route) (viewmodel.Model, bool) {
+// GetFullViewModel returns a fully-initialized viewmodel for the given route. The lists of other items
+// (children, backlinks and related items) only contain the items which can be shown with the given visibility.
+func (orchestrator *ViewModelOrchestrator) GetFullViewModel(itemRoute route.Route, visibility Visibility) (viewmodel.Model, bool) {
 
 	// return from cache
 	if orchestrator.fullViewmodelsByRoute != nil {
 
 		if viewModel, exists := orchestrator.fullViewmodelsByRoute.Get(itemRoute.String()); exists {
+			cacheRequests.Inc("viewmodel", cacheHit)
 
 			// append the content
 			viewModel.Content = orchestrator.getHTMLFromRoute(orchestrator.relativePather(itemRoute), itemRoute)
 
+			// the backlinks change with the other items, so they are not cached
+			viewModel.Backlinks = orchestrator.getBacklinkModels(itemRoute, visibility)
+			viewModel.Related = orchestrator.getRelatedModels(itemRoute, visibility)
+
+			// the cached children include all items
+			if !visibility.ShowsAllItems() {
+				viewModel.Children = orchestrator.getChildModels(itemRoute, visibility)
+				if viewModel.TagCloud != nil {
+					viewModel.TagCloud = orchestrator.tagOrchestrator.GetTagCloud(visibility)
+				}
+			}
+
+			// the same applies to the custom assets which can be attached to the ancestors
+			viewModel.Stylesheets, viewModel.Scripts = orchestrator.getCustomAssets(itemRoute)
+
+			// the thumbnails are created in the background
+			if item := orchestrator.getItem(itemRoute); item != nil {
+				viewModel.PreviewImage = orchestrator.getPreviewImagePath(item)
+			}
+
 			return viewModel, true
 		}
 
+		cacheRequests.Inc("viewmodel", cacheMiss)
 		return viewmodel.Model{}, false
 	}
 
+	cacheRequests.Inc("viewmodel", cacheMiss)
+
 	// initialize the cache
 	orchestrator.fullViewmodelsByRoute = newViewmodelCache()
 
@@ -67,7 +92,7 @@ func (orchestrator *ViewModelOrchestrator) GetFullViewModel(itemRoute route.Rout
 		viewModel.ItemNavigation = orchestrator.navigationOrchestrator.GetItemNavigation(route)
 
 		// children
-		viewModel.Children = orchestrator.getChildModels(route)
+		viewModel.Children = orchestrator.getChildModels(route, Visibility{})
 
 		// tags
 		viewModel.Tags = orchestrator.tagOrchestrator.getItemTags(route)
@@ -89,7 +114,7 @@ func (orchestrator *ViewModelOrchestrator) GetFullViewModel(itemRoute route.Rout
 			repositoryIsNotEmpty := orchestrator.index().Size() >= 5 // don't bother to create a tag cloud if there aren't enough documents
 			if repositoryIsNotEmpty {
 
-				tagCloud := orchestrator.tagOrchestrator.GetTagCloud()
+				tagCloud := orchestrator.tagOrchestrator.GetTagCloud(Visibility{})
 				viewModel.TagCloud = tagCloud
 
 			}
@@ -128,7 +153,7 @@ func (orchestrator *ViewModelOrchestrator) GetFullViewModel(itemRoute route.Rout
 	orchestrator.registerUpdateCallback("update full viewmodel", UpdateTypeModified, updateViewModel)
 	orchestrator.registerUpdateCallback("update full viewmodel", UpdateTypeDeleted, deleteRouteFromCache)
 
-	return orchestrator.GetFullViewModel(itemRoute)
+	return orchestrator.GetFullViewModel(itemRoute, visibility)
 }
 
 func (orchestrator *ViewModelOrchestrator) GetViewModel(itemRoute route.Route) (viewModel viewmodel.Model, found bool) {
@@ -163,46 +188,30 @@ func (orchestrator *ViewModelOrchestrator) GetViewModelByAlias(alias string) (vi
 	return vm, true
 }
 
-// GetLatest returns the latest items (sorted by creation date) for the given route.
-func (orchestrator *ViewModelOrchestrator) GetLatest(itemRoute route.Route, pageSize, page int) (latest []viewmodel.Model, found bool) {
+// GetLatest returns the latest items (sorted by creation date) for the given route which can be shown with the given visibility.
+// The latest items of all routes are cached; the latest items for restricted visibilities are determined on every request.
+func (orchestrator *ViewModelOrchestrator) GetLatest(itemRoute route.Route, pageSize, page int, visibility Visibility) (latest []viewmodel.Model, found bool) {
+
+	if !visibility.ShowsAllItems() {
+		latestItems := visibleItems(orchestrator.getLatestItems(itemRoute), visibility)
+		return orchestrator.getLatestPage(orchestrator.getLastesViewModelsFromItemList(latestItems), pageSize, page)
+	}
 
 	// return from cache if cache has been initialized
 	if orchestrator.latestByRoute != nil {
 
 		if models, exists := orchestrator.latestByRoute.Get(itemRoute.Value()); exists {
-
-			// get the paged view models
-			latest = make([]viewmodel.Model, 0)
-			latestModels, found := pagedViewmodels(models, pageSize, page)
-			if !found {
-				return []viewmodel.Model{}, false
-			}
-
-			// convert the content
-			absolutePather := orchestrator.absolutePather("/")
-			for _, model := range latestModels {
-				itemRoute := route.NewFromRequest(model.Route)
-
-				// convert to html
-				content := orchestrator.getHTMLFromRoute(absolutePather, itemRoute)
-
-				// lazy-load
-				content = lazyLoad(content)
-
-				// attach to model
-				model.Content = content
-
-				latest = append(latest, model)
-			}
-
-			return latest, true
-
+			cacheRequests.Inc("latest", cacheHit)
+			return orchestrator.getLatestPage(models, pageSize, page)
 		}
 
+		cacheRequests.Inc("latest", cacheMiss)
 		return []viewmodel.Model{}, false
 
 	}
 
+	cacheRequests.Inc("latest", cacheMiss)
+
 	// updateLatest updates the latest items for the given route.
 	updateLatest := func(route route.Route) {
 		startTime := time.Now()
@@ -233,7 +242,37 @@ func (orchestrator *ViewModelOrchestrator) GetLatest(itemRoute route.Route, page
 	orchestrator.registerUpdateCallback("update latest", UpdateTypeDeleted, asyncUpdateLatest)
 
 	// return the result
-	return orchestrator.GetLatest(itemRoute, pageSize, page)
+	return orchestrator.GetLatest(itemRoute, pageSize, page, visibility)
+}
+
+// getLatestPage returns the given page of the given view models with their converted content.
+func (orchestrator *ViewModelOrchestrator) getLatestPage(models []viewmodel.Model, pageSize, page int) (latest []viewmodel.Model, found bool) {
+
+	// get the paged view models
+	latest = make([]viewmodel.Model, 0)
+	latestModels, found := pagedViewmodels(models, pageSize, page)
+	if !found {
+		return []viewmodel.Model{}, false
+	}
+
+	// convert the content
+	absolutePather := orchestrator.absolutePather(orchestrator.basePath())
+	for _, model := range latestModels {
+		itemRoute := route.NewFromRequest(model.Route)
+
+		// convert to html
+		content := orchestrator.getHTMLFromRoute(absolutePather, itemRoute)
+
+		// lazy-load
+		content = lazyLoad(content)
+
+		// attach to model
+		model.Content = content
+
+		latest = append(latest, model)
+	}
+
+	return latest, true
 }
 
 // Converts a list of model.Item elements into a view models for the latest-items controller
@@ -250,7 +289,7 @@ func (orchestrator *ViewModelOrchestrator) getLastesViewModelsFromItemList(items
 		}
 
 		// make the routes absolute
-		absolutePathProvider := orchestrator.absolutePather("/")
+		absolutePathProvider := orchestrator.absolutePather(orchestrator.basePath())
 		viewModel.Route = absolutePathProvider.Path(viewModel.Route)
 		viewModel.ParentRoute = absolutePathProvider.Path(viewModel.ParentRoute)
 
@@ -294,9 +333,25 @@ func (orchestrator *ViewModelOrchestrator) getViewModel(itemRoute route.Route) (
 			IsRepositoryItem: true,
 		}
 
-		// add docx url if docx conversion is enabled
+		// add docx and odt urls if rich-text conversion is enabled
 		if orchestrator.config.Conversion.DOCX.IsEnabled() {
-			viewModel.DOCXURL = GetTypedItemURL(route, "docx")
+			viewModel.DOCXURL = GetTypedItemURL(orchestrator.basePath(), route, "docx")
+			viewModel.ODTURL = GetTypedItemURL(orchestrator.basePath(), route, "odt")
+		}
+
+		// add pdf url if pdf conversion is enabled
+		if orchestrator.config.Conversion.PDF.IsEnabled() {
+			viewModel.PDFURL = GetTypedItemURL(orchestrator.basePath(), route, "pdf")
+		}
+
+		// add epub url if epub conversion is enabled
+		if orchestrator.config.Conversion.EPUB.Enabled {
+			viewModel.EPUBURL = GetTypedItemURL(orchestrator.basePath(), route, "epub")
+		}
+
+		// add zip url if the (authenticated) zip downloads are enabled
+		if orchestrator.config.Server.ZIPDownload.Enabled && orchestrator.config.Server.Authentication.Enabled {
+			viewModel.ZIPURL = GetTypedItemURL(orchestrator.basePath(), route, "zip")
 		}
 
 		orchestrator.viewmodelsByRoute.Set(route.String(), viewModel)
@@ -325,7 +380,8 @@ func (orchestrator *ViewModelOrchestrator) getViewModel(itemRoute route.Route) (
 	return model, true
 }
 
-func (orchestrator *ViewModelOrchestrator) getChildModels(itemRoute route.Route) []viewmodel.Base {
+// getChildModels returns the view models of the children of the item with the given route which can be shown with the given visibility.
+func (orchestrator *ViewModelOrchestrator) getChildModels(itemRoute route.Route, visibility Visibility) []viewmodel.Base {
 
 	rootItem := orchestrator.rootItem()
 	if rootItem == nil {
@@ -333,17 +389,60 @@ func (orchestrator *ViewModelOrchestrator) getChildModels(itemRoute route.Route)
 	}
 
 	childModels := make([]viewmodel.Base, 0)
-	childItems := orchestrator.getChildren(itemRoute)
+	childItems := visibleItems(orchestrator.getChildren(itemRoute), visibility)
 	for _, childItem := range childItems {
 		baseModel := getBaseModel(rootItem, childItem, orchestrator.config)
 		baseModel.Route = orchestrator.relativePather(itemRoute).Path(baseModel.Route)
 		childModels = append(childModels, baseModel)
 	}
 
+	return childModels
+}
+
+// getBacklinkModels returns the view models of the items which link to or reference the item with the given route
+// and can be shown with the given visibility.
+func (orchestrator *ViewModelOrchestrator) getBacklinkModels(itemRoute route.Route, visibility Visibility) []viewmodel.Base {
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	backlinkModels := make([]viewmodel.Base, 0)
+	for _, backlinkRoute := range orchestrator.links().Backlinks(itemRoute) {
+		backlinkItem := orchestrator.getItem(backlinkRoute)
+		if backlinkItem == nil || !visibility.IsVisible(backlinkRoute) {
+			continue
+		}
+
+		baseModel := getBaseModel(rootItem, backlinkItem, orchestrator.config)
+		baseModel.Route = orchestrator.itemPather().Path(backlinkItem.Route().Value())
+		backlinkModels = append(backlinkModels, baseModel)
+	}
+
 	// sort the models
-	viewmodel.SortBaseModelBy(sortBaseModelsByDate).Sort(childModels)
+	viewmodel.SortBaseModelBy(sortBaseModelsByDate).Sort(backlinkModels)
 
-	return childModels
+	return backlinkModels
+}
+
+// getRelatedModels returns the view models of the items which are most closely related to the item with the given route
+// and can be shown with the given visibility (the most closely related first).
+func (orchestrator *ViewModelOrchestrator) getRelatedModels(itemRoute route.Route, visibility Visibility) []viewmodel.Base {
+
+	rootItem := orchestrator.rootItem()
+	if rootItem == nil {
+		orchestrator.logger.Fatal("No root item found")
+	}
+
+	relatedModels := make([]viewmodel.Base, 0)
+	for _, relatedItem := range visibleItems(orchestrator.getRelatedItems(itemRoute), visibility) {
+		baseModel := getBaseModel(rootItem, relatedItem, orchestrator.config)
+		baseModel.Route = orchestrator.itemPather().Path(relatedItem.Route().Value())
+		relatedModels = append(relatedModels, baseModel)
+	}
+
+	return relatedModels
 }
 
 // getHTMLFromRoute returns the converted HTML code for the item with the given route.
@@ -362,7 +461,7 @@ func (orchestrator *ViewModelOrchestrator) getHTMLFromItem(pathProvider paths.Pa
 		return ""
 	}
 
-	convertedContent, err := orchestrator.converter.Convert(orchestrator.getItemByAlias, pathProvider, item)
+	convertedContent, err := orchestrator.convert(pathProvider, item)
 	if err != nil {
 		orchestrator.logger.Warn("Cannot convert content for route %q. Error: %s.", item.Route(), err.Error())
 		return "<!-- Conversion Error -->"
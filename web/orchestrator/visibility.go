@@ -0,0 +1,116 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/dataaccess"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/orchestrator/search"
+)
+
+// Visibility decides which items can be shown to the user of a request according to the authentication
+// rules of the configuration and of the content folders. The zero value shows all items.
+type Visibility struct {
+	authentication *config.Authentication
+	username       string
+}
+
+// GetVisibility returns the visibility of the items for the user with the given name
+// (an empty name for anonymous users). If authentication is disabled all items are visible.
+func (orchestrator *Orchestrator) GetVisibility(username string) Visibility {
+	if !orchestrator.config.AuthenticationIsEnabled() {
+		return Visibility{}
+	}
+
+	authentication := orchestrator.config.Server.Authentication
+	if rulesProvider, isRulesProvider := orchestrator.repository.(dataaccess.AuthenticationRulesProvider); isRulesProvider {
+		authentication = authentication.WithRules(rulesProvider.AuthenticationRules())
+	}
+
+	return Visibility{
+		authentication: &authentication,
+		username:       username,
+	}
+}
+
+// IsVisible returns a flag indicating whether the item with the given route can be shown.
+func (visibility Visibility) IsVisible(itemRoute route.Route) bool {
+	if visibility.authentication == nil {
+		return true
+	}
+
+	rule, requiresAuthentication := visibility.authentication.GetRule("/" + itemRoute.Value())
+	if !requiresAuthentication {
+		return true
+	}
+
+	return visibility.authentication.IsAuthorized(rule, visibility.username)
+}
+
+// ShowsAllItems returns a flag indicating whether all items are visible (e.g. because authentication is disabled).
+func (visibility Visibility) ShowsAllItems() bool {
+	return visibility.authentication == nil
+}
+
+// visibleItems returns the given items without the items which cannot be shown.
+func visibleItems(items []*model.Item, visibility Visibility) []*model.Item {
+	if visibility.ShowsAllItems() {
+		return items
+	}
+
+	visible := make([]*model.Item, 0, len(items))
+	for _, item := range items {
+		if visibility.IsVisible(item.Route()) {
+			visible = append(visible, item)
+		}
+	}
+
+	return visible
+}
+
+// getVisibleRoutes returns the given routes without the routes of the items which cannot be shown.
+func getVisibleRoutes(routes []route.Route, visibility Visibility) []route.Route {
+	if visibility.ShowsAllItems() {
+		return routes
+	}
+
+	visible := make([]route.Route, 0, len(routes))
+	for _, itemRoute := range routes {
+		if visibility.IsVisible(itemRoute) {
+			visible = append(visible, itemRoute)
+		}
+	}
+
+	return visible
+}
+
+// visibleChildren returns a function which returns the children of an item which can be shown with the given visibility.
+func (orchestrator *Orchestrator) visibleChildren(visibility Visibility) func(parentRoute route.Route) []*model.Item {
+	return func(parentRoute route.Route) []*model.Item {
+		return visibleItems(orchestrator.getChildren(parentRoute), visibility)
+	}
+}
+
+// visibleSearchResults returns the given search results without the results for items which cannot be shown.
+// The remaining results are renumbered.
+func visibleSearchResults(results []search.Result, visibility Visibility) []search.Result {
+	if visibility.ShowsAllItems() {
+		return results
+	}
+
+	visible := make([]search.Result, 0, len(results))
+	for _, result := range results {
+		if !visibility.IsVisible(result.Route) {
+			continue
+		}
+
+		result.Number = len(visible) + 1
+		visible = append(visible, result)
+	}
+
+	return visible
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/orchestrator/search"
+)
+
+func newTestVisibility(username string) Visibility {
+	return Visibility{
+		authentication: &config.Authentication{
+			Rules: []config.AuthenticationRule{
+				{Route: "/", Public: true},
+				{Route: "/private/**", Users: []string{"alice"}},
+			},
+		},
+		username: username,
+	}
+}
+
+func Test_Visibility_AnonymousUser_PrivateItemsAreNotVisible(t *testing.T) {
+	// arrange
+	visibility := newTestVisibility("")
+
+	// act
+	publicIsVisible := visibility.IsVisible(route.NewFromRequest("docs/readme"))
+	privateIsVisible := visibility.IsVisible(route.NewFromRequest("private/secret"))
+
+	// assert
+	if !publicIsVisible {
+		t.Errorf("The public item should be visible for anonymous users.")
+	}
+
+	if privateIsVisible {
+		t.Errorf("The private item should not be visible for anonymous users.")
+	}
+}
+
+func Test_Visibility_AuthorizedUser_PrivateItemsAreVisible(t *testing.T) {
+	// arrange
+	visibility := newTestVisibility("alice")
+
+	// act
+	result := visibility.IsVisible(route.NewFromRequest("private/secret"))
+
+	// assert
+	if !result {
+		t.Errorf("The private item should be visible for the authorized user.")
+	}
+}
+
+func Test_Visibility_OtherUser_PrivateItemsAreNotVisible(t *testing.T) {
+	// arrange
+	visibility := newTestVisibility("bob")
+
+	// act
+	result := visibility.IsVisible(route.NewFromRequest("private/secret"))
+
+	// assert
+	if result {
+		t.Errorf("The private item should not be visible for a user who is not listed in the rule.")
+	}
+}
+
+func Test_Visibility_ZeroValue_AllItemsAreVisible(t *testing.T) {
+	// arrange
+	visibility := Visibility{}
+
+	// act
+	result := visibility.IsVisible(route.NewFromRequest("private/secret"))
+
+	// assert
+	if !result || !visibility.ShowsAllItems() {
+		t.Errorf("The zero value should show all items.")
+	}
+}
+
+func Test_visibleItems_PrivateItems_PrivateItemsAreRemoved(t *testing.T) {
+	// arrange
+	items := []*model.Item{
+		newTestItem("docs", time.Time{}, time.Time{}),
+		newTestItem("private", time.Time{}, time.Time{}),
+		newTestItem("private/secret", time.Time{}, time.Time{}),
+	}
+
+	// act
+	result := visibleItems(items, newTestVisibility(""))
+
+	// assert
+	if len(result) != 1 || result[0].Route().Value() != "docs" {
+		t.Errorf("visibleItems should only return the public item but returned %v.", result)
+	}
+}
+
+func Test_visibleSearchResults_PrivateResults_PrivateResultsAreRemovedAndTheOthersRenumbered(t *testing.T) {
+	// arrange
+	results := []search.Result{
+		{Route: route.NewFromRequest("private/secret"), Number: 1},
+		{Route: route.NewFromRequest("docs/readme"), Number: 2},
+	}
+
+	// act
+	result := visibleSearchResults(results, newTestVisibility(""))
+
+	// assert
+	if len(result) != 1 || result[0].Route.Value() != "docs/readme" || result[0].Number != 1 {
+		t.Errorf("visibleSearchResults should only return the public result as number 1 but returned %v.", result)
+	}
+}
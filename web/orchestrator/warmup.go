@@ -0,0 +1,70 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"sort"
+)
+
+// GetWarmUpRoutes returns the routes of the items which are pre-rendered after startup: the root item,
+// the items with the given routes and the given number of items with the most backlinks.
+// Routes without an item are skipped.
+func (orchestrator *ViewModelOrchestrator) GetWarmUpRoutes(routes []string, mostLinkedItems int) []route.Route {
+
+	warmUpRoutes := make([]route.Route, 0)
+	added := make(map[string]bool)
+	add := func(itemRoute route.Route) {
+		key := route.ToKey(itemRoute)
+		if added[key] || orchestrator.getItem(itemRoute) == nil {
+			return
+		}
+
+		added[key] = true
+		warmUpRoutes = append(warmUpRoutes, itemRoute)
+	}
+
+	if root := orchestrator.rootItem(); root != nil {
+		add(root.Route())
+	}
+
+	for _, requestRoute := range routes {
+		add(route.NewFromRequest(requestRoute))
+	}
+
+	if mostLinkedItems <= 0 {
+		return warmUpRoutes
+	}
+
+	// the items with the most backlinks (the same number of backlinks in the order of the routes)
+	links := orchestrator.links()
+	linkedRoutes := make([]route.Route, 0)
+	for _, itemRoute := range links.Routes() {
+		if len(links.Backlinks(itemRoute)) > 0 {
+			linkedRoutes = append(linkedRoutes, itemRoute)
+		}
+	}
+
+	sort.SliceStable(linkedRoutes, func(i, j int) bool {
+		return len(links.Backlinks(linkedRoutes[i])) > len(links.Backlinks(linkedRoutes[j]))
+	})
+
+	for index, itemRoute := range linkedRoutes {
+		if index >= mostLinkedItems {
+			break
+		}
+
+		add(itemRoute)
+	}
+
+	return warmUpRoutes
+}
+
+// WarmUp renders the view model and the HTML of the item with the given route so they are cached
+// when the item is requested for the first time. It returns false if there is no item with the route.
+func (orchestrator *ViewModelOrchestrator) WarmUp(itemRoute route.Route) bool {
+	_, found := orchestrator.GetFullViewModel(itemRoute, Visibility{})
+	return found
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"strings"
+)
+
+// webAppIconMinimumSize is the minimum edge length of the branding icons which are used as app icons.
+const webAppIconMinimumSize = 192
+
+// WebAppOrchestrator provides the web app manifest and the pages which the service worker of the site caches.
+type WebAppOrchestrator struct {
+	*Orchestrator
+
+	brandingOrchestrator *BrandingOrchestrator
+}
+
+// GetManifest returns the web app manifest of the site. The app icons are the large branding icons.
+func (orchestrator *WebAppOrchestrator) GetManifest() viewmodel.WebAppManifest {
+	webAppConfig := orchestrator.config.Web.WebApp
+
+	manifest := viewmodel.WebAppManifest{
+		Language:        getLanguageCode(orchestrator.config.Web.DefaultLanguage),
+		Direction:       getDirectionCode(orchestrator.config.Web.DefaultDirection),
+		StartURL:        orchestrator.basePath(),
+		Scope:           orchestrator.basePath(),
+		Display:         "standalone",
+		ThemeColor:      strings.TrimSpace(webAppConfig.ThemeColor),
+		BackgroundColor: strings.TrimSpace(webAppConfig.BackgroundColor),
+		Icons:           make([]viewmodel.WebAppIcon, 0),
+	}
+
+	if root := orchestrator.rootItem(); root != nil {
+		manifest.Name = root.Title
+		manifest.Description = root.Description
+	}
+
+	manifest.ShortName = strings.TrimSpace(webAppConfig.ShortName)
+	if manifest.ShortName == "" {
+		manifest.ShortName = manifest.Name
+	}
+
+	if len(orchestrator.brandingOrchestrator.GetBranding().Icons) > 0 {
+		iconsPath := orchestrator.basePath() + BrandingIconsPath
+		for _, icon := range brandingIcons {
+			if icon.MimeType() != "image/png" || icon.Sizes[0] < webAppIconMinimumSize {
+				continue
+			}
+
+			manifest.Icons = append(manifest.Icons, viewmodel.WebAppIcon{
+				Source: iconsPath + icon.Name,
+				Sizes:  icon.SizesAttribute(),
+				Type:   icon.MimeType(),
+			})
+		}
+	}
+
+	return manifest
+}
+
+// GetPrecacheURLs returns the paths of the start page and of the items (and their images) of the configured
+// precache route which the service worker caches when it is installed. Only the items which can be shown with the given visibility are included.
+func (orchestrator *WebAppOrchestrator) GetPrecacheURLs(visibility Visibility) []string {
+	urls := []string{orchestrator.basePath()}
+
+	precacheRoute := strings.TrimSpace(orchestrator.config.Web.WebApp.PrecacheRoute)
+	if precacheRoute == "" {
+		return urls
+	}
+
+	itemRoute := route.NewFromRequest(precacheRoute)
+	item := orchestrator.getItem(itemRoute)
+	if item == nil {
+		orchestrator.logger.Warn("The precache route %q of the web app was not found.", precacheRoute)
+		return urls
+	}
+
+	items := []*model.Item{item}
+	for _, descendant := range getDescendants(itemRoute, orchestrator.visibleChildren(visibility)) {
+		items = append(items, descendant.item)
+	}
+
+	return append(urls, getPrecachedItemURLs(items, orchestrator.basePath(), orchestrator.config.Web.WebApp.MaxItems())...)
+}
+
+// getPrecachedItemURLs returns the paths of the first items of the given list (up to the given maximum number)
+// and of their images below the given base path. The start page is not included.
+func getPrecachedItemURLs(items []*model.Item, basePath string, maxItems int) []string {
+	urls := make([]string, 0)
+	for index, item := range items {
+		if index >= maxItems {
+			break
+		}
+
+		if item.Route().IsEmpty() {
+			continue
+		}
+
+		urls = append(urls, GetBaseURL(basePath, item.Route()))
+		for _, file := range item.Files() {
+			if model.IsImageFile(file) {
+				urls = append(urls, basePath+file.Route().Value())
+			}
+		}
+	}
+
+	return urls
+}
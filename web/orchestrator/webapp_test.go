@@ -0,0 +1,68 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreaskoch/allmark/common/route"
+	"github.com/andreaskoch/allmark/model"
+)
+
+func Test_getPrecachedItemURLs_ItemsWithFiles_ItemsAndImagesAreReturned(t *testing.T) {
+	// arrange
+	items := []*model.Item{
+		model.NewItem(route.NewFromRequest("docs"), []*model.File{
+			newTestFile("docs/files/notes.txt", "text/plain"),
+			newTestFile("docs/files/image.png", "image/png"),
+		}, 0),
+		model.NewItem(route.NewFromRequest("docs/sample"), nil, 0),
+	}
+
+	// act
+	result := getPrecachedItemURLs(items, "/wiki/", 10)
+
+	// assert
+	expected := []string{"/wiki/docs/", "/wiki/docs/files/image.png", "/wiki/docs/sample/"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getPrecachedItemURLs should return %v but returned %v.", expected, result)
+	}
+}
+
+func Test_getPrecachedItemURLs_MoreItemsThanTheMaximum_OnlyTheFirstItemsAreReturned(t *testing.T) {
+	// arrange
+	items := []*model.Item{
+		model.NewItem(route.NewFromRequest("first"), nil, 0),
+		model.NewItem(route.NewFromRequest("second"), nil, 0),
+		model.NewItem(route.NewFromRequest("third"), nil, 0),
+	}
+
+	// act
+	result := getPrecachedItemURLs(items, "/", 2)
+
+	// assert
+	expected := []string{"/first/", "/second/"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getPrecachedItemURLs should return %v but returned %v.", expected, result)
+	}
+}
+
+func Test_getPrecachedItemURLs_RootItem_RootIsNotReturned(t *testing.T) {
+	// arrange
+	items := []*model.Item{
+		model.NewItem(route.New(), nil, 0),
+		model.NewItem(route.NewFromRequest("docs"), nil, 0),
+	}
+
+	// act
+	result := getPrecachedItemURLs(items, "/", 10)
+
+	// assert
+	expected := []string{"/docs/"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getPrecachedItemURLs should return %v but returned %v.", expected, result)
+	}
+}
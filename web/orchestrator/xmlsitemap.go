@@ -7,49 +7,55 @@ package orchestrator
 import (
 	"github.com/andreaskoch/allmark/common/paths"
 	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/services/thumbnail"
 	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"fmt"
 	"time"
 )
 
+// MaxXMLSitemapEntries is the maximum number of URLs of a single XML sitemap (see https://www.sitemaps.org/protocol.html).
+// Larger sitemaps are split into several sitemaps which are listed by a sitemap index.
+const MaxXMLSitemapEntries = 50000
+
 type XmlSitemapOrchestrator struct {
 	*Orchestrator
+
+	// thumbnailIndex is used to reference the thumbnails of images (optional)
+	thumbnailIndex *thumbnail.Index
 }
 
-func (orchestrator *XmlSitemapOrchestrator) GetSitemapEntires(hostname string) []viewmodel.XmlSitemapEntry {
+// GetSitemapEntires returns the sitemap entries for all items which can be shown with the given visibility.
+func (orchestrator *XmlSitemapOrchestrator) GetSitemapEntires(hostname string, visibility Visibility) []viewmodel.XmlSitemapEntry {
 
 	rootItem := orchestrator.rootItem()
 	if rootItem == nil {
 		orchestrator.logger.Fatal("No root item found")
 	}
 
-	zeroTime := time.Time{}
-
 	children := make([]viewmodel.XmlSitemapEntry, 0)
-	for _, item := range orchestrator.getAllItems() {
+	for _, item := range visibleItems(orchestrator.getAllItems(), visibility) {
 
 		// skip virtual items
 		if item.IsVirtual() {
 			continue
 		}
 
+		// skip items which must not be indexed
+		if item.MetaData.NoIndex {
+			continue
+		}
+
 		// item location
-		addressPrefix := fmt.Sprintf("%s/", hostname)
+		addressPrefix := fmt.Sprintf("%s%s", hostname, orchestrator.basePath())
 		pathProvider := orchestrator.absolutePather(addressPrefix)
 		location := pathProvider.Path(item.Route().Value())
 
-		// last modified date
-		lastModifiedDate := ""
-		if item.MetaData.LastModifiedDate != zeroTime {
-			lastModifiedDate = item.MetaData.LastModifiedDate.Format("2006-01-02")
-		}
-
 		// images
-		images := getImageModels(pathProvider, item)
+		images := orchestrator.getImageModels(pathProvider, item)
 
 		children = append(children, viewmodel.XmlSitemapEntry{
 			Loc:          location,
-			LastModified: lastModifiedDate,
+			LastModified: getSitemapDate(item),
 			Images:       images,
 		})
 	}
@@ -57,7 +63,71 @@ func (orchestrator *XmlSitemapOrchestrator) GetSitemapEntires(hostname string) [
 	return children
 }
 
-func getImageModels(pathProvider paths.Pather, item *model.Item) []viewmodel.XmlSitemapEntryImage {
+// GetSitemapIndex returns the sitemap index for the given number of sitemap entries. The sitemaps
+// of the index are referenced by their page number (e.g. "http://example.com/sitemap.xml?page=2").
+func (orchestrator *XmlSitemapOrchestrator) GetSitemapIndex(hostname, sitemapRoute string, entries []viewmodel.XmlSitemapEntry) viewmodel.XMLSitemapIndex {
+
+	pathProvider := orchestrator.absolutePather(fmt.Sprintf("%s%s", hostname, orchestrator.basePath()))
+	sitemapURL := pathProvider.Path(sitemapRoute)
+
+	index := viewmodel.XMLSitemapIndex{}
+	for page := 1; (page-1)*MaxXMLSitemapEntries < len(entries); page++ {
+		pageEntries, _ := PagedXMLSitemapEntries(entries, page)
+
+		// the sitemap has been modified when the most recent of its entries has been modified
+		lastModified := ""
+		for _, entry := range pageEntries {
+			if entry.LastModified > lastModified {
+				lastModified = entry.LastModified
+			}
+		}
+
+		index.Sitemaps = append(index.Sitemaps, viewmodel.XMLSitemapIndexEntry{
+			Loc:          fmt.Sprintf("%s?page=%d", sitemapURL, page),
+			LastModified: lastModified,
+		})
+	}
+
+	return index
+}
+
+// PagedXMLSitemapEntries returns the entries of the sitemap with the given page number.
+func PagedXMLSitemapEntries(entries []viewmodel.XmlSitemapEntry, page int) (pageEntries []viewmodel.XmlSitemapEntry, found bool) {
+
+	// determine the start index
+	startIndex := MaxXMLSitemapEntries * (page - 1)
+	if startIndex >= len(entries) || page < 1 {
+		return []viewmodel.XmlSitemapEntry{}, false
+	}
+
+	// determine the end index
+	endIndex := startIndex + MaxXMLSitemapEntries
+	if endIndex > len(entries) {
+		endIndex = len(entries)
+	}
+
+	return entries[startIndex:endIndex], true
+}
+
+// getSitemapDate returns the last modification date of the given item; or the creation
+// date if the item has no modification date. If the item has neither an empty string is returned.
+func getSitemapDate(item *model.Item) string {
+	zeroTime := time.Time{}
+
+	if item.MetaData.LastModifiedDate != zeroTime {
+		return item.MetaData.LastModifiedDate.Format("2006-01-02")
+	}
+
+	if item.MetaData.CreationDate != zeroTime {
+		return item.MetaData.CreationDate.Format("2006-01-02")
+	}
+
+	return ""
+}
+
+// getImageModels returns the image entries for the image files of the given item.
+// If there are thumbnails for an image the largest thumbnail is referenced instead of the original image.
+func (orchestrator *XmlSitemapOrchestrator) getImageModels(pathProvider paths.Pather, item *model.Item) []viewmodel.XmlSitemapEntryImage {
 	var imageModels []viewmodel.XmlSitemapEntryImage
 
 	for _, file := range item.Files() {
@@ -69,13 +139,22 @@ func getImageModels(pathProvider paths.Pather, item *model.Item) []viewmodel.Xml
 
 		// determine the file location
 		fileLocation := pathProvider.Path(file.Route().Value())
+		if thumbnailLocation, exists := orchestrator.getThumbnailLocation(pathProvider, file); exists {
+			fileLocation = thumbnailLocation
+		}
 
 		// append the image model
 		imageModels = append(imageModels, viewmodel.XmlSitemapEntryImage{
-			Loc: fileLocation,
+			Loc:   fileLocation,
+			Title: file.Route().LastComponentName(),
 		})
 
 	}
 
 	return imageModels
 }
+
+// getThumbnailLocation returns the location of the largest thumbnail of the given image file.
+func (orchestrator *XmlSitemapOrchestrator) getThumbnailLocation(pathProvider paths.Pather, file *model.File) (location string, exists bool) {
+	return getLargestThumbnailPath(orchestrator.thumbnailIndex, pathProvider, file)
+}
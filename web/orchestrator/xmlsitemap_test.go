@@ -0,0 +1,49 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orchestrator
+
+import (
+	"github.com/andreaskoch/allmark/model"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+	"testing"
+	"time"
+)
+
+func Test_PagedXMLSitemapEntries_MoreEntriesThanAllowed_SecondPageContainsTheRemainingEntries(t *testing.T) {
+	// arrange
+	entries := make([]viewmodel.XmlSitemapEntry, MaxXMLSitemapEntries+3)
+
+	// act
+	firstPage, firstPageFound := PagedXMLSitemapEntries(entries, 1)
+	secondPage, secondPageFound := PagedXMLSitemapEntries(entries, 2)
+	_, thirdPageFound := PagedXMLSitemapEntries(entries, 3)
+
+	// assert
+	if !firstPageFound || len(firstPage) != MaxXMLSitemapEntries {
+		t.Errorf("The first page should contain %d entries but contained %d.", MaxXMLSitemapEntries, len(firstPage))
+	}
+
+	if !secondPageFound || len(secondPage) != 3 {
+		t.Errorf("The second page should contain 3 entries but contained %d.", len(secondPage))
+	}
+
+	if thirdPageFound {
+		t.Errorf("There should be no third page.")
+	}
+}
+
+func Test_getSitemapDate_NoModificationDate_CreationDateIsReturned(t *testing.T) {
+	// arrange
+	item := &model.Item{}
+	item.MetaData.CreationDate = time.Date(2015, 8, 3, 12, 0, 0, 0, time.UTC)
+
+	// act
+	result := getSitemapDate(item)
+
+	// assert
+	if result != "2015-08-03" {
+		t.Errorf("getSitemapDate should return %q but returned %q.", "2015-08-03", result)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/web/handlers"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// newAccessLog creates the access log for the given configuration.
+// It returns nil if access logging is disabled.
+func newAccessLog(config config.Config) (*handlers.AccessLog, error) {
+	if !config.Server.AccessLog.Enabled {
+		return nil, nil
+	}
+
+	var writer io.Writer = os.Stdout
+	if accessLogFilePath := config.AccessLogFilePath(); accessLogFilePath != "" {
+
+		if err := os.MkdirAll(filepath.Dir(accessLogFilePath), 0700); err != nil {
+			return nil, err
+		}
+
+		file, err := os.OpenFile(accessLogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+
+		writer = file
+	}
+
+	return handlers.NewAccessLog(writer, config.Server.AccessLog.Format), nil
+}
@@ -0,0 +1,15 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"github.com/andreaskoch/allmark/common/benchmark"
+)
+
+// Benchmark parses, converts and indexes all items of the repository once and returns the duration and
+// the memory allocations of every phase by item type.
+func (server *Server) Benchmark() []*benchmark.Phase {
+	return server.orchestratorFactory.NewBenchmarkOrchestrator().Run()
+}
@@ -0,0 +1,538 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/util/fsutil"
+	"github.com/andreaskoch/allmark/web/handlers"
+	"github.com/andreaskoch/allmark/web/view/themes"
+)
+
+// SearchIndexFileName is the name of the file which contains the search index of an exported site.
+const SearchIndexFileName = "search-index.json"
+
+// exportSeeds contains the paths (relative to the base path) which are exported in addition to the linked pages and files.
+var exportSeeds = []string{
+	"",
+	"sitemap.xml",
+	"sitemap.html",
+	"tags.html",
+	"recent",
+	"recent.rss",
+	"archive",
+	"feed.rss",
+	"feed.atom",
+	"feed.json",
+	"opml",
+	"robots.txt",
+	"opensearch.xml",
+	"titles.json",
+}
+
+// exportExcludedPathPattern matches the paths (relative to the base path) which are not exported because they
+// only work with a running server (e.g. the search, the live-reload, the API routes and the ZIP downloads).
+var exportExcludedPathPattern = regexp.MustCompile(`^(search(/suggest)?|search\.json|graphql|metrics|-/.*|api/.*)$|(^|\.)(ws|events|latest|docx|odt|zip)$`)
+
+// exportAttachmentPathPattern matches the paths of the files attached to the items, which are always exported
+// (e.g. "docs/sample/files/archive.zip").
+var exportAttachmentPathPattern = regexp.MustCompile(`(^|/)files/`)
+
+var (
+	// exportLinkAttributePattern matches the HTML attributes which contain a single URL.
+	exportLinkAttributePattern = regexp.MustCompile(`(?i)(\s(?:href|src|action|poster|data-src|content)\s*=\s*)("[^"]*"|'[^']*')`)
+
+	// exportSourceSetAttributePattern matches the HTML attributes which contain a list of image candidates.
+	exportSourceSetAttributePattern = regexp.MustCompile(`(?i)(\s(?:srcset|data-srcset)\s*=\s*)("[^"]*"|'[^']*')`)
+
+	// exportStyleSheetURLPattern matches the URLs in style sheets.
+	exportStyleSheetURLPattern = regexp.MustCompile(`url\(\s*['"]?([^'")\s]+)['"]?\s*\)`)
+
+	// exportBaseElementPattern matches the base element of HTML pages.
+	exportBaseElementPattern = regexp.MustCompile(`(?i)<base\s+href\s*=\s*"([^"]*)"`)
+
+	// exportQueryPattern matches the characters of queries which are not used in the paths of exported files.
+	exportQueryPattern = regexp.MustCompile(`[^\pL\pN]+`)
+)
+
+// exportedFile is a page or a file of an exported site.
+type exportedFile struct {
+	// path is the path of the file in the export folder (e.g. "docs/sample/index.html").
+	path string
+
+	// isHTML indicates whether the file is an HTML page whose links are rewritten.
+	isHTML bool
+
+	// baseURL is the URL the relative links of an HTML page are resolved against.
+	baseURL *url.URL
+
+	// redirectKey is the key of the page a redirect forwards to.
+	redirectKey string
+
+	data []byte
+}
+
+// exporter renders the pages and files of a repository with the request router of a server.
+type exporter struct {
+	router   http.Handler
+	siteURL  *url.URL
+	relative bool
+
+	// absoluteURLPattern matches the absolute URLs of the site.
+	absoluteURLPattern *regexp.Regexp
+
+	files map[string]*exportedFile
+	queue []string
+}
+
+// Export renders the whole repository (items, tag pages, feeds, sitemaps, thumbnails and theme assets) to static
+// files in the given folder. If a base URL (e.g. "https://example.com/docs/") is given, the links of the pages point
+// to the exported files under that URL; otherwise all links are relative, so the site can be hosted under any path.
+// A search index for client-side searches is written to "search-index.json". Only the files which changed since
+// the previous export to the folder are written; the result lists them (see ExportManifestFileName).
+func (server *Server) Export(targetFolder, baseURL string) (ExportResult, error) {
+
+	siteURL, err := server.getSiteURL(baseURL)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	exporter := &exporter{
+		router:   server.getLocalRequestRouter(),
+		siteURL:  siteURL,
+		relative: baseURL == "",
+
+		absoluteURLPattern: regexp.MustCompile(regexp.QuoteMeta(siteURL.String()) + `[^\s"'<>()\\]*`),
+
+		files: make(map[string]*exportedFile),
+	}
+
+	// the PDF conversion tool loads the style sheets and images of the documents from the conversion endpoint
+	if server.config.Conversion.PDF.IsEnabled() {
+		stopConversionEndpoint, err := server.serveConversionEndpoint()
+		if err != nil {
+			return ExportResult{}, err
+		}
+
+		defer stopConversionEndpoint()
+	}
+
+	seeds := append([]string{}, exportSeeds...)
+	for _, themeFile := range themes.GetTheme().Files {
+		seeds = append(seeds, strings.TrimPrefix(handlers.ThemeRoutePrefix, "/")+"/"+themeFile.Path())
+	}
+
+	if err := exporter.crawl(seeds); err != nil {
+		return ExportResult{}, err
+	}
+
+	files := make(map[string][]byte, len(exporter.files)+1)
+	for _, file := range exporter.files {
+
+		// different keys can address the same page (e.g. "docs" and "docs/")
+		if _, exists := files[file.path]; exists {
+			continue
+		}
+
+		data := file.data
+		if file.redirectKey != "" {
+			data = []byte(exporter.getRedirectPage(file))
+		} else if file.isHTML {
+			data = []byte(exporter.rewriteLinks(file, string(data)))
+		}
+
+		files[file.path] = data
+	}
+
+	// search index
+	searchIndex := server.orchestratorFactory.NewSearchIndexOrchestrator().GetSearchIndex()
+	for index, entry := range searchIndex {
+		if key, fragment, isInternal := exporter.getKey(entry.Route, siteURL); isInternal {
+			if file, exists := exporter.files[key]; exists {
+				searchIndex[index].Route = exporter.getLink("", file.path, fragment)
+			}
+		}
+	}
+
+	searchIndexData, err := json.MarshalIndent(searchIndex, "", "\t")
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	files[SearchIndexFileName] = searchIndexData
+
+	result, err := writeExport(targetFolder, files)
+	if err != nil {
+		return result, err
+	}
+
+	server.logger.Info("Exported %d files to %q: %d added, %d modified, %d deleted, %d unchanged", len(files), targetFolder, len(result.Added), len(result.Modified), len(result.Deleted), result.Unchanged)
+	return result, nil
+}
+
+// getSiteURL returns the URL under which the pages are rendered: the given base URL or
+// the configured domain name and base path.
+func (server *Server) getSiteURL(baseURL string) (*url.URL, error) {
+	basePath := server.config.BasePath()
+	if baseURL == "" {
+		return &url.URL{Scheme: "http", Host: server.config.Server.DomainName, Path: basePath}, nil
+	}
+
+	siteURL, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || (siteURL.Scheme != "http" && siteURL.Scheme != "https") || siteURL.Host == "" {
+		return nil, fmt.Errorf("The base URL %q is not an absolute HTTP or HTTPS URL.", baseURL)
+	}
+
+	if siteURL.Path = "/" + strings.Trim(siteURL.Path, "/") + "/"; siteURL.Path == "//" {
+		siteURL.Path = "/"
+	}
+
+	if siteURL.Path != basePath {
+		return nil, fmt.Errorf("The path of the base URL %q does not match the base path %q.", baseURL, basePath)
+	}
+
+	return siteURL, nil
+}
+
+// serveConversionEndpoint serves the files of the repository on the conversion endpoint for the conversion tools
+// and returns a function which stops the endpoint.
+func (server *Server) serveConversionEndpoint() (func(), error) {
+	conversionEndpointBinding := server.config.Conversion.EndpointBinding()
+	conversionEndpointTCPAddress := conversionEndpointBinding.GetTCPAddress()
+	conversionEndpointAddress := conversionEndpointTCPAddress.String()
+
+	listener, err := net.Listen(conversionEndpointBinding.Network, conversionEndpointAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Conversion endpoint failed with error: %v", err)
+	}
+
+	conversionServer := &http.Server{Addr: conversionEndpointAddress, Handler: server.getLocalRequestRouter()}
+	go conversionServer.Serve(listener)
+
+	return func() {
+		conversionServer.Close()
+	}, nil
+}
+
+// crawl renders the given paths and all pages and files they link to.
+func (exporter *exporter) crawl(seeds []string) error {
+	exporter.queue = append(exporter.queue, seeds...)
+
+	queued := make(map[string]bool)
+	for _, seed := range seeds {
+		queued[seed] = true
+	}
+
+	for len(exporter.queue) > 0 {
+		key := exporter.queue[0]
+		exporter.queue = exporter.queue[1:]
+
+		file, links, err := exporter.render(key)
+		if err != nil {
+			if key == "" {
+				return err
+			}
+
+			continue
+		}
+
+		exporter.files[key] = file
+		for _, link := range links {
+			if !queued[link] {
+				queued[link] = true
+				exporter.queue = append(exporter.queue, link)
+			}
+		}
+	}
+
+	return nil
+}
+
+// render renders the page or file with the given key (its path relative to the base path and its query)
+// and returns the keys of the pages and files it links to.
+func (exporter *exporter) render(key string) (*exportedFile, []string, error) {
+	pageURL := exporter.getURL(key)
+
+	request := httptest.NewRequest(http.MethodGet, pageURL.String(), nil)
+	response := httptest.NewRecorder()
+	exporter.router.ServeHTTP(response, request)
+
+	// redirects (e.g. the aliases) become pages which forward to the target
+	if response.Code >= 300 && response.Code < 400 {
+		location := response.Header().Get("Location")
+		targetKey, _, isInternal := exporter.getKey(location, pageURL)
+		if !isInternal {
+			return nil, nil, fmt.Errorf("The redirect of %q to %q leaves the site.", key, location)
+		}
+
+		redirect := &exportedFile{
+			path:        getExportFilePath(key, true),
+			isHTML:      true,
+			baseURL:     pageURL,
+			redirectKey: targetKey,
+		}
+
+		return redirect, []string{targetKey}, nil
+	}
+
+	if response.Code != http.StatusOK {
+		return nil, nil, fmt.Errorf("The request for %q failed with status %d.", pageURL, response.Code)
+	}
+
+	data := response.Body.Bytes()
+	contentType := response.Header().Get("Content-Type")
+	isHTML := strings.HasPrefix(contentType, "text/html")
+
+	file := &exportedFile{
+		path:    getExportFilePath(key, isHTML),
+		isHTML:  isHTML,
+		baseURL: pageURL,
+		data:    data,
+	}
+
+	var links []string
+	switch {
+	case isHTML:
+		if baseElement := exportBaseElementPattern.FindStringSubmatch(string(data)); baseElement != nil {
+			if baseURL, err := pageURL.Parse(baseElement[1]); err == nil {
+				file.baseURL = baseURL
+			}
+		}
+
+		exporter.forEachLink(string(data), func(link string) string {
+			if linkKey, _, isInternal := exporter.getKey(link, file.baseURL); isInternal {
+				links = append(links, linkKey)
+			}
+
+			return link
+		})
+
+	case strings.HasPrefix(contentType, "text/css"):
+		for _, match := range exportStyleSheetURLPattern.FindAllStringSubmatch(string(data), -1) {
+			if linkKey, _, isInternal := exporter.getKey(match[1], pageURL); isInternal {
+				links = append(links, linkKey)
+			}
+		}
+	}
+
+	// absolute links in any text (e.g. the feeds, the sitemaps and the structured data)
+	if strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "xml") || strings.Contains(contentType, "json") {
+		for _, link := range exporter.absoluteURLPattern.FindAllString(string(data), -1) {
+			if linkKey, _, isInternal := exporter.getKey(link, pageURL); isInternal {
+				links = append(links, linkKey)
+			}
+		}
+	}
+
+	return file, links, nil
+}
+
+// rewriteLinks replaces the links of the given HTML page which point to exported pages or files with the links to the exported files.
+func (exporter *exporter) rewriteLinks(file *exportedFile, html string) string {
+	return exporter.forEachLink(html, func(link string) string {
+		key, fragment, isInternal := exporter.getKey(link, file.baseURL)
+		if !isInternal {
+			return link
+		}
+
+		target, exists := exporter.files[key]
+		if !exists {
+			return link
+		}
+
+		return exporter.getLink(file.path, target.path, fragment)
+	})
+}
+
+// getRedirectPage returns an HTML page which forwards to the target of the given redirect.
+func (exporter *exporter) getRedirectPage(redirect *exportedFile) string {
+	link := exporter.getURL(redirect.redirectKey).String()
+	if target, exists := exporter.files[redirect.redirectKey]; exists {
+		link = exporter.getLink(redirect.path, target.path, "")
+	}
+
+	link = html.EscapeString(link)
+	return fmt.Sprintf("<!DOCTYPE html>\n<html><head><meta http-equiv=\"refresh\" content=\"0; url=%s\"><link rel=\"canonical\" href=\"%s\"></head><body><a href=\"%s\">%s</a></body></html>\n", link, link, link, link)
+}
+
+// forEachLink replaces the links of the link attributes of the given HTML code with the results of the given function.
+func (exporter *exporter) forEachLink(html string, replace func(link string) string) string {
+
+	html = exportLinkAttributePattern.ReplaceAllStringFunc(html, func(attribute string) string {
+		parts := exportLinkAttributePattern.FindStringSubmatch(attribute)
+		quote, value := parts[2][:1], parts[2][1:len(parts[2])-1]
+
+		// meta data values are only links if they are absolute URLs
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(parts[1])), "content") && !strings.HasPrefix(value, exporter.siteURL.String()) {
+			return attribute
+		}
+
+		return parts[1] + quote + replace(value) + quote
+	})
+
+	return exportSourceSetAttributePattern.ReplaceAllStringFunc(html, func(attribute string) string {
+		parts := exportSourceSetAttributePattern.FindStringSubmatch(attribute)
+		quote, value := parts[2][:1], parts[2][1:len(parts[2])-1]
+
+		candidates := strings.Split(value, ",")
+		for index, candidate := range candidates {
+			fields := strings.Fields(candidate)
+			if len(fields) == 0 {
+				continue
+			}
+
+			fields[0] = replace(fields[0])
+			candidates[index] = strings.Join(fields, " ")
+		}
+
+		return parts[1] + quote + strings.Join(candidates, ", ") + quote
+	})
+}
+
+// getKey returns the key (the path relative to the base path and the query) and the fragment of the given link
+// which is resolved against the given URL. The result is false if the link does not point to a page or file of
+// the site which can be exported.
+func (exporter *exporter) getKey(link string, baseURL *url.URL) (key, fragment string, isInternal bool) {
+	link = strings.TrimSpace(link)
+	if link == "" || strings.HasPrefix(link, "#") {
+		return "", "", false
+	}
+
+	reference, err := url.Parse(link)
+	if err != nil {
+		return "", "", false
+	}
+
+	resolvedURL := baseURL.ResolveReference(reference)
+	if (resolvedURL.Scheme != "http" && resolvedURL.Scheme != "https") || resolvedURL.Host != exporter.siteURL.Host {
+		return "", "", false
+	}
+
+	basePath := exporter.siteURL.Path
+	if resolvedURL.Path+"/" == basePath {
+		resolvedURL.Path = basePath
+	}
+
+	if !strings.HasPrefix(resolvedURL.Path, basePath) {
+		return "", "", false
+	}
+
+	key = strings.TrimPrefix(resolvedURL.Path, basePath)
+	if exportExcludedPathPattern.MatchString(key) && !exportAttachmentPathPattern.MatchString(key) {
+		return "", "", false
+	}
+
+	if resolvedURL.RawQuery != "" {
+		key += "?" + resolvedURL.RawQuery
+	}
+
+	return key, resolvedURL.Fragment, true
+}
+
+// getURL returns the URL of the page or file with the given key.
+func (exporter *exporter) getURL(key string) *url.URL {
+	keyPath, query, _ := strings.Cut(key, "?")
+
+	pageURL := *exporter.siteURL
+	pageURL.Path = exporter.siteURL.Path + keyPath
+	pageURL.RawQuery = query
+	return &pageURL
+}
+
+// getLink returns the link from the exported file with the given path (or from the root of the site if the path is
+// empty) to the exported file with the given path: a relative link or an absolute link below the base URL.
+func (exporter *exporter) getLink(fromPath, toPath, fragment string) string {
+	var link string
+	if exporter.relative {
+		link = getRelativeExportLink(fromPath, toPath)
+	} else {
+		link = exporter.siteURL.String() + strings.TrimSuffix(toPath, "index.html")
+	}
+
+	if fragment != "" {
+		link += "#" + fragment
+	}
+
+	return link
+}
+
+// getExportFilePath returns the path of the exported file for the given key (e.g. "docs/sample/index.html"
+// for the page "docs/sample", "docs_page-2/index.html" for the page "docs?page=2" and "docs_subtree-true.pdf"
+// for the file "docs.pdf?subtree=true").
+func getExportFilePath(key string, isHTML bool) string {
+	name, query, _ := strings.Cut(key, "?")
+	name = strings.Trim(path.Clean("/"+name), "/")
+
+	if query != "" {
+		if isHTML {
+			name = strings.TrimSuffix(name, ".html") + "_" + sanitizeExportQuery(query)
+		} else {
+			extension := path.Ext(name)
+			name = strings.TrimSuffix(name, extension) + "_" + sanitizeExportQuery(query) + extension
+		}
+	}
+
+	if name == "" {
+		return "index.html"
+	}
+
+	extension := strings.ToLower(path.Ext(name))
+	if isHTML && extension != ".html" && extension != ".htm" {
+		return name + "/index.html"
+	}
+
+	return name
+}
+
+// sanitizeExportQuery returns the given query with all characters but letters and digits replaced by dashes (e.g. "tag-go-page-2").
+func sanitizeExportQuery(query string) string {
+	if decodedQuery, err := url.QueryUnescape(query); err == nil {
+		query = decodedQuery
+	}
+
+	return strings.Trim(exportQueryPattern.ReplaceAllString(query, "-"), "-")
+}
+
+// getRelativeExportLink returns the relative link from the exported file with the given path to the exported file
+// with the other path (e.g. "../other/index.html" from "docs/sample/index.html" to "docs/other/index.html").
+func getRelativeExportLink(fromPath, toPath string) string {
+	fromFolder := path.Dir("/" + fromPath)
+	if fromPath == "" {
+		fromFolder = "/"
+	}
+
+	link, err := filepath.Rel(filepath.FromSlash(fromFolder), filepath.FromSlash("/"+toPath))
+	if err != nil {
+		return toPath
+	}
+
+	return filepath.ToSlash(link)
+}
+
+// writeExportedFile writes the given data to the file with the given path in the given folder.
+func writeExportedFile(targetFolder, filePath string, data []byte) error {
+	targetFilePath := filepath.Join(targetFolder, filepath.FromSlash(filePath))
+	if !fsutil.CreateDirectory(filepath.Dir(targetFilePath)) {
+		return fmt.Errorf("Unable to create the folder for the exported file %q.", targetFilePath)
+	}
+
+	if err := os.WriteFile(targetFilePath, data, 0644); err != nil {
+		return fmt.Errorf("Unable to write the exported file %q. Error: %s", targetFilePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"net/url"
+	"testing"
+)
+
+func Test_getExportFilePath_Pages_PagesAreWrittenToIndexFiles(t *testing.T) {
+	// arrange
+	inputs := map[string]string{
+		"":                        "index.html",
+		"docs/sample":             "docs/sample/index.html",
+		"archive/2015/08/":        "archive/2015/08/index.html",
+		"tags.html":               "tags.html",
+		"docs?page=2":             "docs_page-2/index.html",
+		"tags.html?tag=go&page=2": "tags_tag-go-page-2/index.html",
+	}
+
+	for key, expectedPath := range inputs {
+
+		// act
+		result := getExportFilePath(key, true)
+
+		// assert
+		if result != expectedPath {
+			t.Errorf("The export path of the page %q should be %q but was %q.", key, expectedPath, result)
+		}
+	}
+}
+
+func Test_getExportFilePath_Files_PathIsNotChanged(t *testing.T) {
+	// arrange
+	key := "docs/sample/files/image.png"
+
+	// act
+	result := getExportFilePath(key, false)
+
+	// assert
+	if result != key {
+		t.Errorf("The export path of the file %q should not be changed but was %q.", key, result)
+	}
+}
+
+func Test_getRelativeExportLink_PagesInDifferentFolders_LinkIsRelative(t *testing.T) {
+	// arrange
+	inputs := []struct {
+		from, to, expectedLink string
+	}{
+		{"docs/sample/index.html", "docs/other/index.html", "../other/index.html"},
+		{"docs/sample/index.html", "theme/screen.css", "../../theme/screen.css"},
+		{"index.html", "docs/sample/index.html", "docs/sample/index.html"},
+		{"", "docs/sample/index.html", "docs/sample/index.html"},
+		{"docs/sample/index.html", "docs/sample/index.html", "index.html"},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		result := getRelativeExportLink(input.from, input.to)
+
+		// assert
+		if result != input.expectedLink {
+			t.Errorf("The link from %q to %q should be %q but was %q.", input.from, input.to, input.expectedLink, result)
+		}
+	}
+}
+
+func Test_getKey_Links_OnlyExportableLinksOfTheSiteHaveAKey(t *testing.T) {
+	// arrange
+	siteURL, _ := url.Parse("https://example.com/wiki/")
+	pageURL, _ := url.Parse("https://example.com/wiki/docs/sample/")
+	exporter := &exporter{siteURL: siteURL}
+
+	inputs := []struct {
+		link, expectedKey string
+		isInternal        bool
+	}{
+		{"files/image.png", "docs/sample/files/image.png", true},
+		{"/wiki/tags.html#go", "tags.html", true},
+		{"https://example.com/wiki", "", true},
+		{"/wiki/docs?page=2", "docs?page=2", true},
+		{"/wiki/search", "", false},
+		{"/wiki/api/v1/items", "", false},
+		{"/wiki/docs/sample.zip", "", false},
+		{"files/archive.zip", "docs/sample/files/archive.zip", true},
+		{"/other/page", "", false},
+		{"https://www.example.org/wiki/", "", false},
+		{"mailto:info@example.com", "", false},
+		{"#top", "", false},
+	}
+
+	for _, input := range inputs {
+
+		// act
+		key, _, isInternal := exporter.getKey(input.link, pageURL)
+
+		// assert
+		if key != input.expectedKey || isInternal != input.isInternal {
+			t.Errorf("The key of the link %q should be %q (%t) but was %q (%t).", input.link, input.expectedKey, input.isInternal, key, isInternal)
+		}
+	}
+}
+
+func Test_getExportFilePath_FileWithQuery_QueryIsAddedBeforeTheExtension(t *testing.T) {
+	// arrange
+	key := "docs.pdf?subtree=true"
+
+	// act
+	result := getExportFilePath(key, false)
+
+	// assert
+	if result != "docs_subtree-true.pdf" {
+		t.Errorf("The export path of the file %q should be %q but was %q.", key, "docs_subtree-true.pdf", result)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/util/fsutil"
+)
+
+const (
+	// ExportStateFileName is the name of the file in the export folder which contains the content hashes of the exported files.
+	ExportStateFileName = ".allmark-export.json"
+
+	// ExportManifestFileName is the name of the file in the export folder which lists the files that changed with the last export.
+	ExportManifestFileName = ".allmark-changes.json"
+)
+
+// ExportResult lists the files of an export (by their path in the export folder) which have been added, modified
+// or deleted since the previous export to the same folder.
+type ExportResult struct {
+	Added     []string `json:"added"`
+	Modified  []string `json:"modified"`
+	Deleted   []string `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// exportState contains the content hashes of the exported files by their path.
+type exportState struct {
+	Files map[string]string `json:"files"`
+}
+
+// writeExport writes the given files (by their path) to the given folder. Files whose content did not change since
+// the previous export are not written again and the files of the previous export which are no longer part of the
+// site are removed. The changes are written to the manifest file in the folder.
+func writeExport(targetFolder string, files map[string][]byte) (ExportResult, error) {
+	result := ExportResult{
+		Added:    []string{},
+		Modified: []string{},
+		Deleted:  []string{},
+	}
+
+	if !fsutil.CreateDirectory(targetFolder) {
+		return result, fmt.Errorf("Unable to create the export folder %q.", targetFolder)
+	}
+
+	previousState := readExportState(targetFolder)
+	state := exportState{Files: make(map[string]string, len(files))}
+
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+
+	sort.Strings(paths)
+
+	for _, filePath := range paths {
+		data := files[filePath]
+		hash := getExportHash(data)
+		state.Files[filePath] = hash
+
+		previousHash, existed := previousState.Files[filePath]
+		if existed && previousHash == hash && fsutil.FileExists(filepath.Join(targetFolder, filepath.FromSlash(filePath))) {
+			result.Unchanged++
+			continue
+		}
+
+		if err := writeExportedFile(targetFolder, filePath, data); err != nil {
+			return result, err
+		}
+
+		if existed {
+			result.Modified = append(result.Modified, filePath)
+		} else {
+			result.Added = append(result.Added, filePath)
+		}
+	}
+
+	// remove the files which are no longer exported
+	for filePath := range previousState.Files {
+		if _, exists := state.Files[filePath]; exists {
+			continue
+		}
+
+		if err := removeExportedFile(targetFolder, filePath); err != nil {
+			return result, err
+		}
+
+		result.Deleted = append(result.Deleted, filePath)
+	}
+
+	sort.Strings(result.Deleted)
+
+	if err := writeExportJSON(targetFolder, ExportStateFileName, state); err != nil {
+		return result, err
+	}
+
+	if err := writeExportJSON(targetFolder, ExportManifestFileName, result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// readExportState returns the state of the previous export to the given folder; the state is empty if the folder
+// has not been exported to before.
+func readExportState(targetFolder string) exportState {
+	state := exportState{Files: make(map[string]string)}
+
+	data, err := os.ReadFile(filepath.Join(targetFolder, ExportStateFileName))
+	if err != nil {
+		return state
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil || state.Files == nil {
+		return exportState{Files: make(map[string]string)}
+	}
+
+	return state
+}
+
+// writeExportJSON writes the given value as JSON to the file with the given name in the given folder.
+func writeExportJSON(targetFolder, fileName string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return writeExportedFile(targetFolder, fileName, data)
+}
+
+// removeExportedFile removes the file with the given path and the folders which are empty afterwards from the given folder.
+func removeExportedFile(targetFolder, filePath string) error {
+	targetFilePath := filepath.Join(targetFolder, filepath.FromSlash(filePath))
+
+	// never touch files outside of the export folder
+	if relativePath, err := filepath.Rel(targetFolder, targetFilePath); err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return nil
+	}
+
+	if err := os.Remove(targetFilePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Unable to remove the exported file %q. Error: %s", targetFilePath, err)
+	}
+
+	for folder := filepath.Dir(targetFilePath); folder != filepath.Clean(targetFolder); folder = filepath.Dir(folder) {
+		if os.Remove(folder) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+// getExportHash returns the SHA-256 hash of the given data.
+func getExportHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
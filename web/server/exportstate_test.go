@@ -0,0 +1,132 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_writeExport_FirstExport_AllFilesAreAdded(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	files := map[string][]byte{
+		"index.html":             []byte("home"),
+		"docs/sample/index.html": []byte("sample"),
+	}
+
+	// act
+	result, err := writeExport(folder, files)
+
+	// assert
+	if err != nil {
+		t.Fatalf("The export should not fail but returned %q.", err)
+	}
+
+	expectedAdded := []string{"docs/sample/index.html", "index.html"}
+	if !reflect.DeepEqual(result.Added, expectedAdded) {
+		t.Errorf("The added files should be %v but were %v.", expectedAdded, result.Added)
+	}
+
+	if len(result.Modified) != 0 || len(result.Deleted) != 0 || result.Unchanged != 0 {
+		t.Errorf("The first export should only add files but the result was %+v.", result)
+	}
+
+	if _, err := os.Stat(filepath.Join(folder, ExportManifestFileName)); err != nil {
+		t.Errorf("The manifest %q should have been written.", ExportManifestFileName)
+	}
+}
+
+func Test_writeExport_UnchangedFiles_FilesAreNotWrittenAgain(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	files := map[string][]byte{
+		"index.html": []byte("home"),
+	}
+
+	if _, err := writeExport(folder, files); err != nil {
+		t.Fatalf("The first export should not fail but returned %q.", err)
+	}
+
+	filePath := filepath.Join(folder, "index.html")
+	info, _ := os.Stat(filePath)
+	previousModTime := info.ModTime().Add(-1)
+	os.Chtimes(filePath, previousModTime, previousModTime)
+
+	// act
+	result, err := writeExport(folder, files)
+
+	// assert
+	if err != nil {
+		t.Fatalf("The second export should not fail but returned %q.", err)
+	}
+
+	if result.Unchanged != 1 || len(result.Added) != 0 || len(result.Modified) != 0 || len(result.Deleted) != 0 {
+		t.Errorf("The file should be unchanged but the result was %+v.", result)
+	}
+
+	if info, _ := os.Stat(filePath); !info.ModTime().Equal(previousModTime) {
+		t.Errorf("The unchanged file %q should not have been written again.", filePath)
+	}
+}
+
+func Test_writeExport_ChangedAndRemovedFiles_FilesAreModifiedAndDeleted(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	writeExport(folder, map[string][]byte{
+		"index.html":             []byte("home"),
+		"docs/sample/index.html": []byte("sample"),
+	})
+
+	// act
+	result, err := writeExport(folder, map[string][]byte{
+		"index.html": []byte("new home"),
+	})
+
+	// assert
+	if err != nil {
+		t.Fatalf("The export should not fail but returned %q.", err)
+	}
+
+	if !reflect.DeepEqual(result.Modified, []string{"index.html"}) {
+		t.Errorf("The modified files should be [index.html] but were %v.", result.Modified)
+	}
+
+	if !reflect.DeepEqual(result.Deleted, []string{"docs/sample/index.html"}) {
+		t.Errorf("The deleted files should be [docs/sample/index.html] but were %v.", result.Deleted)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(folder, "index.html")); string(data) != "new home" {
+		t.Errorf("The modified file should contain %q but contained %q.", "new home", string(data))
+	}
+
+	if _, err := os.Stat(filepath.Join(folder, "docs")); !os.IsNotExist(err) {
+		t.Errorf("The empty folders of the deleted file should have been removed.")
+	}
+}
+
+func Test_removeExportedFile_PathOutsideOfTheExportFolder_FileIsNotRemoved(t *testing.T) {
+	// arrange
+	parentFolder := t.TempDir()
+	folder := filepath.Join(parentFolder, "export")
+	os.Mkdir(folder, 0755)
+
+	outsideFile := filepath.Join(parentFolder, "outside.txt")
+	os.WriteFile(outsideFile, []byte("outside"), 0644)
+
+	// act
+	err := removeExportedFile(folder, "../outside.txt")
+
+	// assert
+	if err != nil {
+		t.Errorf("Removing a file outside of the export folder should be ignored but returned %q.", err)
+	}
+
+	if _, err := os.Stat(outsideFile); err != nil {
+		t.Errorf("The file %q outside of the export folder should not have been removed.", outsideFile)
+	}
+}
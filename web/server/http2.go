@@ -0,0 +1,69 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// newHTTPServer creates a plaintext HTTP server for the given address.
+// If h2c is enabled the server will accept HTTP/2 connections without TLS.
+func (server *Server) newHTTPServer(address string, handler http.Handler) *http.Server {
+	if server.config.Server.HTTP2.H2C {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	return &http.Server{
+		Addr:    address,
+		Handler: handler,
+	}
+}
+
+// newHTTPSServer creates a HTTP server for the given address that will
+// offer HTTP/2 over TLS if HTTP/2 is enabled.
+func (server *Server) newHTTPSServer(address string, handler http.Handler, tlsConfig *tls.Config) (*http.Server, error) {
+	httpsServer := &http.Server{
+		Addr:      address,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+
+	// disable HTTP/2 (and don't advertise it, e.g. in the TLS configuration of Let's Encrypt)
+	if !server.config.Server.HTTP2.Enabled {
+		httpsServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		httpsServer.TLSConfig = withoutHTTP2(tlsConfig)
+		return httpsServer, nil
+	}
+
+	if err := http2.ConfigureServer(httpsServer, &http2.Server{}); err != nil {
+		return nil, err
+	}
+
+	return httpsServer, nil
+}
+
+// withoutHTTP2 returns a copy of the given TLS configuration which doesn't offer HTTP/2 ("h2")
+// during the protocol negotiation. If the configuration is nil, nil is returned.
+func withoutHTTP2(tlsConfig *tls.Config) *tls.Config {
+	if tlsConfig == nil {
+		return nil
+	}
+
+	tlsConfig = tlsConfig.Clone()
+
+	nextProtos := make([]string, 0, len(tlsConfig.NextProtos))
+	for _, protocol := range tlsConfig.NextProtos {
+		if protocol != http2.NextProtoTLS {
+			nextProtos = append(nextProtos, protocol)
+		}
+	}
+
+	tlsConfig.NextProtos = nextProtos
+	return tlsConfig
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/tls"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/andreaskoch/allmark/common/config"
+)
+
+func Test_newHTTPSServer_HTTP2Disabled_H2IsNotAdvertised(t *testing.T) {
+	// arrange
+	server := &Server{config: *config.Default("/tmp")}
+	server.config.Server.HTTP2.Enabled = false
+	tlsConfig := &tls.Config{NextProtos: []string{"h2", "http/1.1", "acme-tls/1"}}
+
+	// act
+	httpsServer, err := server.newHTTPSServer(":443", http.NotFoundHandler(), tlsConfig)
+
+	// assert
+	if err != nil {
+		t.Fatalf("newHTTPSServer returned an error: %s", err)
+	}
+
+	expected := []string{"http/1.1", "acme-tls/1"}
+	if !reflect.DeepEqual(httpsServer.TLSConfig.NextProtos, expected) {
+		t.Errorf("The TLS configuration should advertise %v but advertised %v.", expected, httpsServer.TLSConfig.NextProtos)
+	}
+
+	if len(tlsConfig.NextProtos) != 3 {
+		t.Errorf("The given TLS configuration should not be modified but its protocols are %v.", tlsConfig.NextProtos)
+	}
+}
+
+func Test_newHTTPSServer_HTTP2Enabled_H2IsAdvertised(t *testing.T) {
+	// arrange
+	server := &Server{config: *config.Default("/tmp")}
+	server.config.Server.HTTP2.Enabled = true
+	tlsConfig := &tls.Config{NextProtos: []string{"h2", "http/1.1", "acme-tls/1"}}
+
+	// act
+	httpsServer, err := server.newHTTPSServer(":443", http.NotFoundHandler(), tlsConfig)
+
+	// assert
+	if err != nil {
+		t.Fatalf("newHTTPSServer returned an error: %s", err)
+	}
+
+	if httpsServer.TLSConfig.NextProtos[0] != "h2" {
+		t.Errorf("The TLS configuration should advertise HTTP/2 first but advertised %v.", httpsServer.TLSConfig.NextProtos)
+	}
+}
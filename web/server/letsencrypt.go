@@ -0,0 +1,30 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// letsEncryptIsEnabled returns a flag indicating whether the SSL certificates shall be obtained from Let's Encrypt.
+func (server *Server) letsEncryptIsEnabled() bool {
+	return server.config.Server.HTTPS.Enabled && server.config.Server.HTTPS.LetsEncrypt.Enabled
+}
+
+// getCertificateManager returns a certificate manager which obtains and renews
+// the SSL certificates for the configured hostnames from Let's Encrypt.
+func (server *Server) getCertificateManager() *autocert.Manager {
+	hostnames := server.config.LetsEncryptHostnames()
+	cacheDirectory := server.config.LetsEncryptCacheDirectory()
+
+	server.logger.Info("Obtaining certificates for %v from Let's Encrypt (Cache: %s)", hostnames, cacheDirectory)
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hostnames...),
+		Cache:      autocert.DirCache(cacheDirectory),
+		Email:      server.config.Server.HTTPS.LetsEncrypt.Email,
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// inheritedListenersVariable contains the comma-separated names of the listeners which have been
+	// passed on by the previous instance of the server during a restart (starting with file descriptor 3).
+	inheritedListenersVariable = "ALLMARK_LISTENERS"
+
+	// readyFileDescriptorVariable contains the number of the file descriptor to which the new
+	// instance of the server writes as soon as it is ready to serve requests.
+	readyFileDescriptorVariable = "ALLMARK_READY_FD"
+
+	// The first file descriptor of the inherited listeners.
+	inheritedListenersFDsStart = 3
+)
+
+// listenerRegistry keeps track of all listeners of a server so that they can be
+// handed over to a new instance of the server without dropping any connections.
+type listenerRegistry struct {
+	mutex sync.Mutex
+
+	// the listeners passed on by the previous instance of the server
+	inherited map[string]net.Listener
+
+	// the listeners which are in use
+	active map[string]net.Listener
+
+	// the names of the active listeners in the order in which they have been added
+	names []string
+
+	// a flag indicating whether the server has been started by a previous instance
+	isRestart bool
+}
+
+// newListenerRegistry creates a new listener registry which contains the
+// listeners that have been passed on by a previous instance of the server.
+func newListenerRegistry() (*listenerRegistry, error) {
+
+	registry := &listenerRegistry{
+		inherited: make(map[string]net.Listener),
+		active:    make(map[string]net.Listener),
+	}
+
+	inheritedListeners := os.Getenv(inheritedListenersVariable)
+	os.Unsetenv(inheritedListenersVariable)
+	if inheritedListeners == "" {
+		return registry, nil
+	}
+
+	for index, name := range strings.Split(inheritedListeners, ",") {
+		file := os.NewFile(uintptr(inheritedListenersFDsStart+index), name)
+		listener, err := net.FileListener(file)
+		file.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("Unable to use the inherited listener %q. Error: %s", name, err)
+		}
+
+		registry.inherited[name] = listener
+	}
+
+	registry.isRestart = true
+	return registry, nil
+}
+
+// IsRestart returns a flag indicating whether the server has been started by a previous instance.
+func (registry *listenerRegistry) IsRestart() bool {
+	return registry.isRestart
+}
+
+// Listen returns the inherited listener with the given name or creates a new listener for the given address.
+func (registry *listenerRegistry) Listen(name, network, address string) (net.Listener, error) {
+	if listener, found := registry.Inherited(name); found {
+		return listener, nil
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.Add(name, listener)
+	return listener, nil
+}
+
+// Inherited returns the inherited listener with the given name and marks it as active.
+func (registry *listenerRegistry) Inherited(name string) (net.Listener, bool) {
+	registry.mutex.Lock()
+	listener, found := registry.inherited[name]
+	delete(registry.inherited, name)
+	registry.mutex.Unlock()
+
+	if found {
+		registry.Add(name, listener)
+	}
+
+	return listener, found
+}
+
+// Add registers the given listener under the given name.
+func (registry *listenerRegistry) Add(name string, listener net.Listener) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if _, exists := registry.active[name]; !exists {
+		registry.names = append(registry.names, name)
+	}
+
+	registry.active[name] = listener
+}
+
+// Ready closes all inherited listeners which are no longer used (e.g. because
+// the configuration has changed) and notifies the previous instance of the server
+// that the current instance is ready to serve requests.
+func (registry *listenerRegistry) Ready() {
+	registry.mutex.Lock()
+	for name, listener := range registry.inherited {
+		listener.Close()
+		delete(registry.inherited, name)
+	}
+	registry.mutex.Unlock()
+
+	readyFileDescriptor, err := strconv.Atoi(os.Getenv(readyFileDescriptorVariable))
+	os.Unsetenv(readyFileDescriptorVariable)
+	if err != nil {
+		return
+	}
+
+	readyFile := os.NewFile(uintptr(readyFileDescriptor), "ready")
+	readyFile.Write([]byte{1})
+	readyFile.Close()
+}
+
+// Files returns the names and duplicated file descriptors of all active listeners.
+func (registry *listenerRegistry) Files() ([]string, []*os.File, error) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	var names []string
+	var files []*os.File
+
+	for _, name := range registry.names {
+		listener, ok := registry.active[name].(interface {
+			File() (*os.File, error)
+		})
+
+		if !ok {
+			continue
+		}
+
+		file, err := listener.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("Unable to hand over the listener %q. Error: %s", name, err)
+		}
+
+		names = append(names, name)
+		files = append(files, file)
+	}
+
+	return names, files, nil
+}
+
+// KeepSocketFiles prevents the socket files of the Unix domain socket listeners from
+// being removed when the listeners are closed, because they are still used by the new
+// instance of the server.
+func (registry *listenerRegistry) KeepSocketFiles() {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, listener := range registry.active {
+		if unixListener, ok := listener.(*net.UnixListener); ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"github.com/andreaskoch/allmark/common/config"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Reload applies the settings of the given configuration which can be changed while the server is running
+// (see config.RequiresRestart): the redirects file and the network policy are read again and the standard
+// request router is replaced. It returns the paths of the changed settings which
+// only take effect after a restart. If the configuration cannot be applied the current one stays in use.
+func (server *Server) Reload(updated config.Config) (restartRequired []string, err error) {
+
+	server.configMutex.Lock()
+	defer server.configMutex.Unlock()
+
+	reloaded, restartRequired := config.Reload(server.config, updated)
+
+	// redirects
+	redirects, err := reloaded.Redirects()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the redirects file %q. Error: %s", reloaded.RedirectsFilePath(), err)
+	}
+
+	// network access
+	networkPolicy, err := reloaded.NetworkPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	server.config = reloaded
+	server.redirects = redirects
+	server.networkPolicy = networkPolicy
+	server.standardRequestRouter.Set(server.getStandardRequestRouter())
+
+	server.logger.Info("Reloaded the configuration (%d redirect(s))", len(redirects))
+
+	return restartRequired, nil
+}
+
+// reloadableHandler passes all requests on to a handler which can be replaced while requests are served.
+type reloadableHandler struct {
+	handler atomic.Value
+}
+
+// handlerValue wraps the handlers of a reloadableHandler, so they are all stored with the same type.
+type handlerValue struct {
+	http.Handler
+}
+
+// Set replaces the handler which serves the requests.
+func (reloadable *reloadableHandler) Set(handler http.Handler) {
+	reloadable.handler.Store(handlerValue{handler})
+}
+
+func (reloadable *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, _ := reloadable.handler.Load().(handlerValue)
+	if handler.Handler == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
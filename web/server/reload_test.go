@@ -0,0 +1,44 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_reloadableHandler_HandlerIsReplaced_RequestsAreServedByTheNewHandler(t *testing.T) {
+	// arrange
+	reloadable := &reloadableHandler{}
+	reloadable.Set(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	// act
+	reloadable.Set(http.RedirectHandler("/wiki/", http.StatusMovedPermanently))
+
+	response := httptest.NewRecorder()
+	reloadable.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	// assert
+	if response.Code != http.StatusMovedPermanently {
+		t.Errorf("The request should have been served by the new handler (status %d) but the status was %d.", http.StatusMovedPermanently, response.Code)
+	}
+}
+
+func Test_reloadableHandler_NoHandler_ServiceIsUnavailable(t *testing.T) {
+	// arrange
+	reloadable := &reloadableHandler{}
+
+	// act
+	response := httptest.NewRecorder()
+	reloadable.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	// assert
+	if response.Code != http.StatusServiceUnavailable {
+		t.Errorf("The status should be %d but was %d.", http.StatusServiceUnavailable, response.Code)
+	}
+}
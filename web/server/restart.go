@@ -0,0 +1,94 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Restart starts a new instance of the current executable with the same arguments and
+// hands over all listeners to it. It returns as soon as the new instance is ready to
+// serve requests; the current instance should then be shut down. Because the listening
+// sockets are shared, no connections are dropped during the restart.
+func (server *Server) Restart() error {
+
+	names, files, err := server.listeners.Files()
+	if err != nil {
+		return err
+	}
+
+	defer closeFiles(files)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Unable to locate the executable. Error: %s", err)
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer readyReader.Close()
+
+	// pass on the listeners (starting with file descriptor 3) and the ready pipe
+	command := exec.Command(executable, os.Args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.ExtraFiles = append(files, readyWriter)
+	command.Env = append(getEnvironmentWithout(inheritedListenersVariable, readyFileDescriptorVariable),
+		fmt.Sprintf("%s=%s", inheritedListenersVariable, strings.Join(names, ",")),
+		fmt.Sprintf("%s=%d", readyFileDescriptorVariable, inheritedListenersFDsStart+len(files)))
+
+	server.logger.Info("Starting a new instance of %q", executable)
+	if err := command.Start(); err != nil {
+		readyWriter.Close()
+		return fmt.Errorf("Unable to start a new instance. Error: %s", err)
+	}
+
+	// only the new instance may hold the write end of the pipe,
+	// otherwise the read below would never fail
+	readyWriter.Close()
+
+	go command.Wait()
+
+	// wait until the new instance is ready (or has exited)
+	buffer := make([]byte, 1)
+	if _, err := readyReader.Read(buffer); err != nil {
+		return fmt.Errorf("The new instance (pid: %d) exited before it was ready to serve requests", command.Process.Pid)
+	}
+
+	server.logger.Info("The new instance (pid: %d) is ready", command.Process.Pid)
+	server.listeners.KeepSocketFiles()
+
+	return nil
+}
+
+// getEnvironmentWithout returns the environment of the current process without the given variables.
+func getEnvironmentWithout(variableNames ...string) []string {
+	var environment []string
+
+	for _, variable := range os.Environ() {
+		excluded := false
+		for _, variableName := range variableNames {
+			if strings.HasPrefix(variable, variableName+"=") {
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			environment = append(environment, variable)
+		}
+	}
+
+	return environment
+}
@@ -0,0 +1,14 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"fmt"
+)
+
+// Restart is not supported on Windows because listening sockets cannot be passed on to child processes.
+func (server *Server) Restart() error {
+	return fmt.Errorf("Restarting the server without downtime is not supported on Windows")
+}
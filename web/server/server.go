@@ -9,6 +9,8 @@ package server
 import (
 	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/common/logger"
+	"github.com/andreaskoch/allmark/common/shutdown"
+	"github.com/andreaskoch/allmark/common/util/fsutil"
 	"github.com/andreaskoch/allmark/dataaccess"
 	"github.com/andreaskoch/allmark/services/converter/markdowntohtml"
 	"github.com/andreaskoch/allmark/services/converter/markdowntohtml/imageprovider"
@@ -17,111 +19,249 @@ import (
 	"github.com/andreaskoch/allmark/web/handlers"
 	"github.com/andreaskoch/allmark/web/header"
 	"github.com/andreaskoch/allmark/web/orchestrator"
+	"github.com/andreaskoch/allmark/web/orchestrator/htmlcache"
 	"github.com/andreaskoch/allmark/web/view/templates"
+	"github.com/andreaskoch/allmark/web/view/themes"
 	"github.com/andreaskoch/allmark/web/webpaths"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/skratchdot/open-golang/open"
+	"golang.org/x/crypto/acme/autocert"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 )
 
 // New creates a new Server instance for the given repository.
-func New(logger logger.Logger, config config.Config, repository dataaccess.Repository, parser parser.Parser, thumbnailIndex *thumbnail.Index) (*Server, error) {
+// The thumbnail conversion service is optional (nil if thumbnail creation is disabled).
+func New(logger logger.Logger, config config.Config, repository dataaccess.Repository, parser parser.Parser, thumbnailIndex *thumbnail.Index, thumbnailConversion *thumbnail.ConversionService) (*Server, error) {
+	logger = logger.Module("server")
 
 	patherFactory := webpaths.NewFactory(logger, repository)
-	webPathProvider := webpaths.NewWebPathProvider(patherFactory, handlers.BasePath, handlers.TagPathPrefix)
+	basePath := config.BasePath()
+	tagPathPrefix := basePath + strings.TrimPrefix(handlers.TagPathPrefix, "/")
+	webPathProvider := webpaths.NewWebPathProvider(patherFactory, basePath, tagPathPrefix)
 
 	// image provider
-	imageProvider := imageprovider.NewImageProvider(webPathProvider.AbsolutePather("/"), thumbnailIndex)
+	imageProvider := imageprovider.NewImageProvider(webPathProvider.AbsolutePather(basePath), thumbnailIndex)
 
 	// converter
 	converter := markdowntohtml.New(logger, imageProvider)
 
-	orchestratorFactory := orchestrator.NewFactory(logger, config, repository, parser, converter, webPathProvider)
+	// the converted HTML of the items (the theme version is part of the cache keys)
+	themeFingerprints := themes.NewFingerprints(config.ThemeAssetFolders())
+	var htmlCache *htmlcache.Cache
+	if config.Caching.HTML.Enabled {
+		htmlCache = htmlcache.New(logger, themeFingerprints.Version(), int64(config.Caching.HTML.MaxSizeInMegabytes)*1024*1024, config.HTMLCacheFolder())
+	}
+
+	orchestratorFactory := orchestrator.NewFactory(logger, config, repository, parser, converter, webPathProvider, thumbnailIndex, htmlCache)
 	reindexInterval := config.Indexing.IntervalInSeconds
-	headerWriterFactory := header.NewHeaderWriterFactory(reindexInterval)
-	templateProvider := templates.NewProvider(config.TemplatesFolder())
-	requestHandlers := handlers.GetBaseHandlers(logger, config, templateProvider, *orchestratorFactory, headerWriterFactory)
+	headerWriterFactory := header.NewHeaderWriterFactory(reindexInterval, config.Server.CacheControl)
+
+	// locales
+	locales, err := config.Locales()
+	if err != nil {
+		return nil, err
+	}
+
+	for locale, labels := range locales {
+		logger.Info("Loaded %d label(s) for the locale %q", len(labels), locale)
+	}
+
+	templateProvider := templates.NewProvider(config.TemplateFolders(), config.BasePath(), config.Web.Locale, locales, config.Web.Snippets, orchestratorFactory.NewBrandingOrchestrator().GetBranding, themeFingerprints)
+	requestHandlers := handlers.GetBaseHandlers(logger, config, templateProvider, *orchestratorFactory, headerWriterFactory, thumbnailIndex, thumbnailConversion, themeFingerprints)
+
+	// theme folders
+	for _, themeFolder := range config.ThemeFolders() {
+		if !fsutil.DirectoryExists(themeFolder) {
+			logger.Warn("The theme folder %q does not exist", themeFolder)
+			continue
+		}
+
+		logger.Info("Using the theme folder %q", themeFolder)
+	}
+
+	// redirects
+	redirects, err := config.Redirects()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the redirects file %q. Error: %s", config.RedirectsFilePath(), err)
+	}
+
+	if len(redirects) > 0 {
+		logger.Info("Loaded %d redirect(s) from %q", len(redirects), config.RedirectsFilePath())
+	}
+
+	// network access
+	networkPolicy, err := config.NetworkPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	// access log
+	accessLog, err := newAccessLog(config)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open the access log %q. Error: %s", config.AccessLogFilePath(), err)
+	}
+
+	// listeners (including the ones passed on by a previous instance)
+	listeners, err := newListenerRegistry()
+	if err != nil {
+		return nil, err
+	}
 
 	return &Server{
 		logger: logger,
 		config: config,
 
+		folderAuthenticationRules: getFolderAuthenticationRules(repository),
+
 		headerWriterFactory: headerWriterFactory,
+		orchestratorFactory: orchestratorFactory,
+		thumbnailConversion: thumbnailConversion,
 		requestHandlers:     requestHandlers,
+		redirects:           redirects,
+		accessLog:           accessLog,
+		networkPolicy:       networkPolicy,
+		listeners:           listeners,
+
+		standardRequestRouter: &reloadableHandler{},
 	}, nil
 
 }
 
+// getFolderAuthenticationRules returns a function which returns the authentication rules
+// of the content folders of the given repository.
+func getFolderAuthenticationRules(repository dataaccess.Repository) func() []config.AuthenticationRule {
+	if rulesProvider, isRulesProvider := repository.(dataaccess.AuthenticationRulesProvider); isRulesProvider {
+		return rulesProvider.AuthenticationRules
+	}
+
+	return func() []config.AuthenticationRule {
+		return nil
+	}
+}
+
 // Server represents a web server instance for a given repository.
 type Server struct {
-	logger logger.Logger
-	config config.Config
+	logger      logger.Logger
+	config      config.Config
+	configMutex sync.Mutex
 
 	headerWriterFactory header.WriterFactory
+	orchestratorFactory *orchestrator.Factory
+	thumbnailConversion *thumbnail.ConversionService
 
 	requestHandlers handlers.HandlerList
+	redirects       []config.Redirect
+	accessLog       *handlers.AccessLog
+	networkPolicy   *config.NetworkPolicy
+
+	// folderAuthenticationRules returns the authentication rules defined by the folder settings of the repository
+	folderAuthenticationRules func() []config.AuthenticationRule
+
+	// standardRequestRouter is replaced when the configuration is reloaded
+	standardRequestRouter *reloadableHandler
+
+	listeners        *listenerRegistry
+	httpServers      []*http.Server
+	httpServersMutex sync.Mutex
 }
 
 // Start starts the current web server.
 func (server *Server) Start() chan error {
 
-	result := make(chan error)
+	result := make(chan error, 1)
+
+	server.configMutex.Lock()
+	server.standardRequestRouter.Set(server.getStandardRequestRouter())
+	server.configMutex.Unlock()
 
-	standardRequestRouter := server.getStandardRequestRouter()
+	var standardRequestRouter http.Handler = server.standardRequestRouter
 
 	// bindings
 	httpEndpoint, httpEnabled := server.httpEndpoint()
 	httpsEndpoint, httpsEnabled := server.httpsEndpoint()
 
-	// abort if no tcp bindings are configured
-	if len(httpEndpoint.Bindings()) == 0 && len(httpsEndpoint.Bindings()) == 0 {
-		result <- fmt.Errorf("No TCP bindings configured")
+	// socket bindings
+	socketListeners, err := server.getSocketListeners()
+	if err != nil {
+		result <- err
+		return result
+	}
+
+	// abort if no bindings are configured
+	if len(httpEndpoint.Bindings()) == 0 && len(httpsEndpoint.Bindings()) == 0 && len(server.config.Server.Listeners) == 0 && len(socketListeners) == 0 {
+		result <- fmt.Errorf("No TCP or socket bindings configured")
 		return result
 	}
 
+	// stop accepting new requests and complete the in-flight requests on shutdown
+	shutdown.Register(server.Shutdown)
+
+	// create the indizes in the background; the server is ready as soon as they exist
+	go server.orchestratorFactory.NewStatusOrchestrator(server.thumbnailConversion).InitializeIndexes()
+
+	// pre-render the most important items
+	if server.config.Caching.WarmUp.Enabled {
+		go server.warmUp()
+	}
+
 	uniqueURLs := make(map[string]string)
 
+	// Let's Encrypt
+	var certificateManager *autocert.Manager
+	if server.letsEncryptIsEnabled() {
+		certificateManager = server.getCertificateManager()
+	}
+
 	// http
 	if httpEnabled {
 
-		for _, tcpBinding := range httpEndpoint.Bindings() {
+		for index, tcpBinding := range httpEndpoint.Bindings() {
 
 			tcpBinding.AssignFreePort()
 
 			tcpAddr := tcpBinding.GetTCPAddress()
 			address := tcpAddr.String()
 
-			// start listening
-			go func() {
-				server.logger.Info("HTTP Endpoint: %s", address)
-
-				if httpEndpoint.ForceHTTPS() {
+			listener, err := server.listeners.Listen(fmt.Sprintf("http:%d", index), tcpBinding.Network, address)
+			if err != nil {
+				result <- fmt.Errorf("Server failed with error: %v", err)
+				return result
+			}
 
-					// Redirect HTTP → HTTPS
-					redirectTarget := httpsEndpoint.DefaultURL()
-					httpsRedirectRouter := server.getRedirectRouter(redirectTarget, standardRequestRouter)
+			// an inherited listener might use a different port than a newly assigned free port
+			if listenerAddress, ok := listener.Addr().(*net.TCPAddr); ok {
+				tcpBinding.Port = listenerAddress.Port
+			}
 
-					if err := http.ListenAndServe(address, httpsRedirectRouter); err != nil {
-						result <- fmt.Errorf("Server failed with error: %v", err)
-					} else {
-						result <- nil
-					}
+			server.logger.Info("HTTP Endpoint: %s", listener.Addr())
 
-				} else {
+			if httpEndpoint.ForceHTTPS() {
 
-					// Standard HTTP Request Router
-					if err := http.ListenAndServe(address, standardRequestRouter); err != nil {
-						result <- fmt.Errorf("Server failed with error: %v", err)
-					} else {
-						result <- nil
-					}
+				// Redirect HTTP → HTTPS
+				redirectTarget := httpsEndpoint.DefaultURL()
+				var httpsRedirectRouter http.Handler = server.getRedirectRouter(redirectTarget, standardRequestRouter)
 
+				// answer ACME challenges
+				if certificateManager != nil {
+					httpsRedirectRouter = certificateManager.HTTPHandler(httpsRedirectRouter)
 				}
 
-			}()
+				httpServer := server.newHTTPServer(address, httpsRedirectRouter)
+				server.serve(httpServer, listener, httpServer.Serve, result)
+
+			} else {
+
+				// Standard HTTP Request Router
+				httpServer := server.newHTTPServer(address, standardRequestRouter)
+				server.serve(httpServer, listener, httpServer.Serve, result)
+
+			}
 
 			// store the URL for later opening
 			if httpsEnabled == false {
@@ -135,25 +275,42 @@ func (server *Server) Start() chan error {
 	// https
 	if httpsEnabled {
 
-		for _, tcpBinding := range httpsEndpoint.Bindings() {
+		for index, tcpBinding := range httpsEndpoint.Bindings() {
 
 			tcpBinding.AssignFreePort()
 
 			tcpAddr := tcpBinding.GetTCPAddress()
 			address := tcpAddr.String()
 
-			// start listening
-			go func() {
-				server.logger.Info("HTTPS Endpoint: %s", address)
+			listener, err := server.listeners.Listen(fmt.Sprintf("https:%d", index), tcpBinding.Network, address)
+			if err != nil {
+				result <- fmt.Errorf("Server failed with error: %v", err)
+				return result
+			}
 
-				// Standard HTTPS Request Router
-				if err := http.ListenAndServeTLS(address, httpsEndpoint.CertFilePath(), httpsEndpoint.KeyFilePath(), standardRequestRouter); err != nil {
-					result <- fmt.Errorf("Server failed with error: %v", err)
-				} else {
-					result <- nil
-				}
+			// an inherited listener might use a different port than a newly assigned free port
+			if listenerAddress, ok := listener.Addr().(*net.TCPAddr); ok {
+				tcpBinding.Port = listenerAddress.Port
+			}
 
-			}()
+			server.logger.Info("HTTPS Endpoint: %s", listener.Addr())
+
+			// Let's Encrypt certificates
+			var tlsConfig *tls.Config
+			if certificateManager != nil {
+				tlsConfig = certificateManager.TLSConfig()
+			}
+
+			httpsServer, err := server.newHTTPSServer(address, standardRequestRouter, tlsConfig)
+			if err != nil {
+				result <- fmt.Errorf("Server failed with error: %v", err)
+				return result
+			}
+
+			// Standard HTTPS Request Router
+			server.serve(httpsServer, listener, func(listener net.Listener) error {
+				return httpsServer.ServeTLS(listener, httpsEndpoint.CertFilePath(), httpsEndpoint.KeyFilePath())
+			}, result)
 
 			// store the URL for later opening
 			endpointURL := httpsEndpoint.DefaultURL()
@@ -162,36 +319,235 @@ func (server *Server) Start() chan error {
 
 	}
 
-	// docx conversion endpoint (unencrypted, no authentication)
-	if server.config.Conversion.DOCX.IsEnabled() {
+	// additional listeners
+	for index, listenerConfig := range server.config.Server.Listeners {
+		if err := server.startListener(index, listenerConfig, standardRequestRouter, certificateManager, result); err != nil {
+			result <- fmt.Errorf("Listener %q failed with error: %v", listenerConfig.String(), err)
+			return result
+		}
+	}
 
-		// start listening
-		go func() {
-			conversionEndpointTCPAddress := server.config.Conversion.EndpointBinding().GetTCPAddress()
-			conversionEndpointAddress := conversionEndpointTCPAddress.String()
+	// unix domain sockets and systemd sockets (unencrypted, e.g. for a reverse proxy)
+	for _, listener := range socketListeners {
+		server.logger.Info("HTTP Socket: %s", listener.Addr())
 
-			server.logger.Info("Docx Conversion Endpoint: %s", conversionEndpointAddress)
+		httpServer := server.newHTTPServer("", standardRequestRouter)
+		server.serve(httpServer, listener, httpServer.Serve, result)
+	}
 
-			// Standard HTTPS Request Router
-			if err := http.ListenAndServe(conversionEndpointAddress, server.getLocalRequestRouter()); err != nil {
-				result <- fmt.Errorf("Docx Conversion endpoint failed with error: %v", err)
-			} else {
-				result <- nil
-			}
+	// conversion endpoint for the docx, odt and pdf conversion tools (unencrypted, no authentication)
+	if server.config.Conversion.DOCX.IsEnabled() || server.config.Conversion.PDF.IsEnabled() {
+
+		conversionEndpointBinding := server.config.Conversion.EndpointBinding()
+		conversionEndpointTCPAddress := conversionEndpointBinding.GetTCPAddress()
+		conversionEndpointAddress := conversionEndpointTCPAddress.String()
+
+		listener, err := server.listeners.Listen("conversion", conversionEndpointBinding.Network, conversionEndpointAddress)
+		if err != nil {
+			result <- fmt.Errorf("Conversion endpoint failed with error: %v", err)
+			return result
+		}
+
+		server.logger.Info("Conversion Endpoint: %s", listener.Addr())
 
-		}()
+		conversionServer := &http.Server{Addr: conversionEndpointAddress, Handler: server.getLocalRequestRouter()}
+		server.serve(conversionServer, listener, conversionServer.Serve, result)
 
 	}
 
-	// open HTTP URL(s) in a browser
-	for _, url := range uniqueURLs {
-		server.logger.Info("Open URL: %s", url)
-		go open.Run(url)
+	// notify the previous instance (if any) that this instance is ready
+	server.listeners.Ready()
+
+	// open HTTP URL(s) in a browser (but not after a restart)
+	if !server.listeners.IsRestart() {
+		for _, url := range uniqueURLs {
+			server.logger.Info("Open URL: %s", url)
+			go open.Run(url)
+		}
 	}
 
 	return result
 }
 
+// startListener starts serving requests on the given additional listener. Depending on the listener
+// configuration the requests are redirected, served without authentication or served by the standard router.
+func (server *Server) startListener(index int, listenerConfig config.Listener, standardRequestRouter http.Handler, certificateManager *autocert.Manager, result chan error) error {
+
+	// internal listeners serve requests without authentication and network access rules
+	if listenerConfig.Internal && !listenerConfig.Binding.IsLoopback() {
+		return fmt.Errorf("Internal listeners must be bound to a loopback address.")
+	}
+
+	// the credentials must not be sent unencrypted
+	if server.config.Server.Authentication.Enabled && listenerConfig.ServesUnencryptedContent() {
+		return fmt.Errorf("Authentication is only available over HTTPS. Please add a certificate, redirect the requests or make the listener internal.")
+	}
+
+	tcpBinding := listenerConfig.Binding
+	tcpBinding.AssignFreePort()
+
+	tcpAddr := tcpBinding.GetTCPAddress()
+	address := tcpAddr.String()
+
+	certFilePath, keyFilePath, err := server.config.ListenerCertificateFilePaths(listenerConfig)
+	if err != nil {
+		return err
+	}
+
+	listener, err := server.listeners.Listen(fmt.Sprintf("listener:%d", index), tcpBinding.Network, address)
+	if err != nil {
+		return err
+	}
+
+	var handler http.Handler
+	switch {
+	case listenerConfig.RedirectTo != "":
+		server.logger.Info("Listener %q: %s (redirecting to %s)", listenerConfig.String(), listener.Addr(), listenerConfig.RedirectTo)
+		handler = server.getRedirectRouter(strings.TrimSuffix(listenerConfig.RedirectTo, "/"), standardRequestRouter)
+
+		// answer ACME challenges
+		if certificateManager != nil && !listenerConfig.IsSecure() {
+			handler = certificateManager.HTTPHandler(handler)
+		}
+
+	case listenerConfig.Internal:
+		server.logger.Info("Listener %q: %s (internal)", listenerConfig.String(), listener.Addr())
+		handler = server.getLocalRequestRouter()
+
+	default:
+		server.logger.Info("Listener %q: %s", listenerConfig.String(), listener.Addr())
+		handler = standardRequestRouter
+	}
+
+	if !listenerConfig.IsSecure() {
+		httpServer := server.newHTTPServer(address, handler)
+		server.serve(httpServer, listener, httpServer.Serve, result)
+		return nil
+	}
+
+	httpsServer, err := server.newHTTPSServer(address, handler, nil)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	server.serve(httpsServer, listener, func(listener net.Listener) error {
+		return httpsServer.ServeTLS(listener, certFilePath, keyFilePath)
+	}, result)
+
+	return nil
+}
+
+// serve starts serving requests with the given HTTP server on the given listener
+// in the background. The result is written to the given channel if the server fails;
+// a graceful shutdown is not reported because it is completed by the shutdown handlers.
+func (server *Server) serve(httpServer *http.Server, listener net.Listener, serveFunc func(listener net.Listener) error, result chan error) {
+
+	server.httpServersMutex.Lock()
+	server.httpServers = append(server.httpServers, httpServer)
+	server.httpServersMutex.Unlock()
+
+	go func() {
+		err := serveFunc(listener)
+		if err == http.ErrServerClosed {
+			return
+		}
+
+		if err != nil {
+			result <- fmt.Errorf("Server failed with error: %v", err)
+		} else {
+			result <- nil
+		}
+	}()
+}
+
+// Shutdown gracefully stops all endpoints of the current web server: the listeners are closed
+// and the in-flight requests are completed within the configured shutdown timeout.
+func (server *Server) Shutdown() error {
+
+	server.httpServersMutex.Lock()
+	httpServers := server.httpServers
+	server.httpServersMutex.Unlock()
+
+	server.logger.Info("Waiting for %d endpoint(s) to complete the in-flight requests", len(httpServers))
+
+	server.configMutex.Lock()
+	shutdownTimeout := server.config.ShutdownTimeout()
+	server.configMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	errors := make(chan error, len(httpServers))
+	for _, httpServer := range httpServers {
+		go func(httpServer *http.Server) {
+			errors <- httpServer.Shutdown(ctx)
+		}(httpServer)
+	}
+
+	var shutdownError error
+	for range httpServers {
+		if err := <-errors; err != nil {
+			shutdownError = fmt.Errorf("Unable to complete all in-flight requests. Error: %s", err)
+		}
+	}
+
+	return shutdownError
+}
+
+// getSocketListeners returns the listeners for the configured Unix domain socket
+// and for the sockets passed in by systemd (if socket activation is enabled).
+func (server *Server) getSocketListeners() ([]net.Listener, error) {
+	var listeners []net.Listener
+
+	if server.config.Server.UnixSocket.SystemdActivation {
+
+		// use the systemd sockets passed on by the previous instance after a restart
+		for index := 0; ; index++ {
+			listener, found := server.listeners.Inherited(fmt.Sprintf("systemd:%d", index))
+			if !found {
+				break
+			}
+
+			listeners = append(listeners, listener)
+		}
+
+		if len(listeners) == 0 {
+			systemdListeners, err := getSystemdListeners()
+			if err != nil {
+				return nil, err
+			}
+
+			if len(systemdListeners) == 0 {
+				server.logger.Warn("Systemd socket activation is enabled but no sockets have been passed in.")
+			}
+
+			for index, listener := range systemdListeners {
+				server.listeners.Add(fmt.Sprintf("systemd:%d", index), listener)
+				listeners = append(listeners, listener)
+			}
+		}
+	}
+
+	if socketPath := server.config.UnixSocketFilePath(); socketPath != "" {
+		listenerName := "unix:" + socketPath
+		listener, found := server.listeners.Inherited(listenerName)
+		if !found {
+			var err error
+			listener, err = server.newUnixSocketListener(socketPath)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to listen on the Unix socket %q. Error: %s", socketPath, err)
+			}
+
+			server.listeners.Add(listenerName, listener)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
 // getRedirectRouter returns a router which redirects all requests to the url with the given base.
 func (server *Server) getRedirectRouter(baseURITarget string, baseHandler http.Handler) *mux.Router {
 	redirectRouter := mux.NewRouter()
@@ -207,7 +563,7 @@ func (server *Server) getRedirectRouter(baseURITarget string, baseHandler http.H
 }
 
 // Get an instance of the standard request router for all repository related routes.
-func (server *Server) getStandardRequestRouter() *mux.Router {
+func (server *Server) getStandardRequestRouter() http.Handler {
 
 	// register requst routers
 	requestRouter := mux.NewRouter()
@@ -216,30 +572,63 @@ func (server *Server) getStandardRequestRouter() *mux.Router {
 		requestRoute := requestHandler.Route
 		requestHandler := requestHandler.Handler
 
-		// add logging
-		requestHandler = handlers.LogRequests(requestHandler)
+		// add minification
+		if server.config.Server.Minification.Enabled {
+			requestHandler = handlers.MinifyResponses(requestHandler)
+		}
 
 		// add compression
 		requestHandler = handlers.CompressResponses(requestHandler)
 
-		// add authentication
-		if _, httpsEnabled := server.httpsEndpoint(); httpsEnabled && server.config.AuthenticationIsEnabled() {
+		// add metrics
+		if server.config.Server.Metrics.Enabled {
+			requestHandler = handlers.CollectMetrics(handlers.GetHandlerName(requestRoute), requestHandler)
+		}
+
+		// add authentication (health checks and metrics are always public so that load balancers and monitoring systems can use them)
+		if server.config.AuthenticationIsEnabled() && !handlers.IsInfrastructureRoute(requestRoute) {
 			secretProvider := server.config.GetAuthenticationUserStore()
 			if secretProvider == nil {
 				panic("Authentication is enabled but the supplied secret provider is nil.")
 			}
 
-			requestHandler = handlers.RequireDigestAuthentication(server.logger, requestHandler, secretProvider)
+			requestHandler = handlers.RequireDigestAuthenticationWithRules(server.logger, requestHandler, secretProvider, server.config.Server.Authentication, server.folderAuthenticationRules)
 		}
 
+		// add network access restrictions
+		requestHandler = handlers.RestrictNetworkAccess(server.networkPolicy, requestHandler)
+
+		// add logging
+		requestHandler = handlers.LogRequests(server.accessLog, requestHandler)
+
 		requestRouter.Handle(requestRoute, requestHandler)
 	}
 
-	return requestRouter
+	// add redirects
+	redirectingRouter := handlers.ApplyRedirects(server.config.BasePath(), server.redirects, requestRouter)
+
+	var router http.Handler = handlers.StripBasePath(server.config.BasePath(), redirectingRouter)
+
+	// add security headers (for all responses including redirects and errors)
+	router = handlers.AddSecurityHeaders(server.config.Server.SecurityHeaders, router)
+
+	// add rate and concurrency limits
+	if rateLimiting := server.config.Server.RateLimiting; rateLimiting.Enabled {
+		router = handlers.NewConcurrencyLimiter(rateLimiting.MaxConcurrentRequests)(router)
+		router = handlers.LimitRequestRate(rateLimiting, router)
+	}
+
+	// assign an ID to every request (for the response header and the log messages)
+	router = handlers.AssignRequestIDs(router)
+
+	// determine the client address (must come first)
+	router = handlers.ResolveClientAddress(server.networkPolicy, router)
+
+	return router
 }
 
 // getLocalRequestRouter returns a local request router without compression and without authentication.
-func (server *Server) getLocalRequestRouter() *mux.Router {
+func (server *Server) getLocalRequestRouter() http.Handler {
 
 	// register requst routers
 	requestRouter := mux.NewRouter()
@@ -249,12 +638,12 @@ func (server *Server) getLocalRequestRouter() *mux.Router {
 		requestHandler := requestHandler.Handler
 
 		// add logging
-		requestHandler = handlers.LogRequests(requestHandler)
+		requestHandler = handlers.LogRequests(server.accessLog, requestHandler)
 
 		requestRouter.Handle(requestRoute, requestHandler)
 	}
 
-	return requestRouter
+	return handlers.AssignRequestIDs(handlers.StripBasePath(server.config.BasePath(), requestRouter))
 }
 
 // Get the http binding if it is enabled.
@@ -285,6 +674,11 @@ func (server *Server) httpsEndpoint() (httpsEndpoint HTTPSEndpoint, enabled bool
 		tcpBindings: server.config.Server.HTTPS.Bindings,
 	}
 
+	// the certificates are managed by Let's Encrypt
+	if server.letsEncryptIsEnabled() {
+		return HTTPSEndpoint{HTTPEndpoint: httpEndpoint}, true
+	}
+
 	certFilePath, keyFilePath, _ := server.config.CertificateFilePaths()
 
 	httpsEndpoint = HTTPSEndpoint{
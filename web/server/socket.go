@@ -0,0 +1,99 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The first file descriptor passed in by systemd (see: sd_listen_fds(3)).
+const systemdListenFDsStart = 3
+
+// newUnixSocketListener creates a listener for the Unix domain socket with the given path.
+// A stale socket file from a previous run is removed before the socket is created.
+func (server *Server) newUnixSocketListener(socketPath string) (net.Listener, error) {
+
+	fileMode, err := server.config.Server.UnixSocket.FileMode()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return nil, err
+	}
+
+	// remove stale sockets but don't touch any other files
+	if fileInfo, err := os.Lstat(socketPath); err == nil {
+		if fileInfo.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%q exists and is not a socket", socketPath)
+		}
+
+		if err := os.Remove(socketPath); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(socketPath, fileMode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// getSystemdListeners returns listeners for all sockets that have been passed to
+// the current process by systemd. If the process was not started via socket activation
+// an empty list is returned.
+func getSystemdListeners() ([]net.Listener, error) {
+
+	// the sockets are only meant for the process with the given pid
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	numberOfFDs, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || numberOfFDs < 1 {
+		return nil, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	// don't pass the sockets on to child processes
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	var listeners []net.Listener
+	for index := 0; index < numberOfFDs; index++ {
+
+		name := fmt.Sprintf("LISTEN_FD_%d", systemdListenFDsStart+index)
+		if index < len(names) && names[index] != "" {
+			name = names[index]
+		}
+
+		file := os.NewFile(uintptr(systemdListenFDsStart+index), name)
+		listener, err := net.FileListener(file)
+		file.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("Unable to use the socket %q passed in by systemd. Error: %s", name, err)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"time"
+)
+
+// warmUp renders the root item, the configured items and the items with the most backlinks
+// (including the thumbnails of their images) so they are cached before they are requested.
+func (server *Server) warmUp() {
+	startTime := time.Now()
+	settings := server.config.Caching.WarmUp
+
+	viewModelOrchestrator := server.orchestratorFactory.NewViewModelOrchestrator()
+	routes := viewModelOrchestrator.GetWarmUpRoutes(settings.Routes, settings.MostLinkedItems)
+
+	server.logger.Info("Warming up the caches for %d item(s)", len(routes))
+
+	for _, itemRoute := range routes {
+
+		// the thumbnails first, so the cached HTML already references them
+		if server.thumbnailConversion != nil {
+			server.thumbnailConversion.CreateThumbnails(itemRoute)
+		}
+
+		if !viewModelOrchestrator.WarmUp(itemRoute) {
+			server.logger.Warn("Unable to warm up the caches for %q", itemRoute.String())
+			continue
+		}
+
+		server.logger.Debug("Warmed up the caches for %q", itemRoute.String())
+	}
+
+	server.logger.Info("Warmed up the caches for %d item(s) in %s", len(routes), time.Since(startTime))
+}
@@ -28,7 +28,7 @@ const aliasIndexTemplate = `
 <ol class="shortlinks">
 
 {{ if eq (len .Aliases) 0 }}
--- There are currently not items with aliases in this repository --
+{{ label "aliasindex.empty" .Locale }}
 {{ else }}
 {{ range .Aliases }}
 <li class="shortlink">
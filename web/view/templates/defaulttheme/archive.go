@@ -0,0 +1,90 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+import (
+	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+)
+
+func init() {
+	templates[templatenames.Archive] = archiveTemplate
+}
+
+const archiveTemplate = `
+<header>
+<h1 class="title">
+{{.Title}}
+</h1>
+</header>
+
+<section class="description">
+{{.Description}}
+</section>
+
+<section class="content">
+{{ if .Calendar }}
+<table class="calendar">
+	<thead>
+		<tr>
+		{{ range .Weekdays }}
+			<th>{{.}}</th>
+		{{ end }}
+		</tr>
+	</thead>
+	<tbody>
+	{{ range .Calendar }}
+		<tr>
+		{{ range . }}
+			{{ if .NumberOfItems }}
+			<td class="has-items"><a href="#{{.Date}}" title="{{ printf (label "tagcloud.count" $.Locale) .NumberOfItems }}">{{.Day}}</a></td>
+			{{ else }}
+			<td>{{ if .Day }}{{.Day}}{{ end }}</td>
+			{{ end }}
+		{{ end }}
+		</tr>
+	{{ end }}
+	</tbody>
+</table>
+
+<nav class="pager">
+	{{ if .Previous }}<a class="previous" href="{{.Previous.Route}}">{{ label "navigation.previous" .Locale }}: {{ formatdate "January 2006" .Previous.Date }}</a>{{ end }}
+	{{ if .Next }}<a class="next" href="{{.Next.Route}}">{{ label "navigation.next" .Locale }}: {{ formatdate "January 2006" .Next.Date }}</a>{{ end }}
+</nav>
+
+{{ range .Days }}
+<section class="day" id="{{.Date}}">
+	<h2><time datetime="{{.Date}}">{{ formatdate "January 2, 2006" .Date }}</time></h2>
+
+	<ol class="children">
+		{{range .Items}}
+		<li class="child">
+			<a href="{{.Route}}" class="child-title child-link">{{.Title}}</a>
+			<p class="child-description">{{.Description}}</p>
+		</li>
+		{{end}}
+	</ol>
+</section>
+{{ end }}
+
+{{ else if .Years }}
+{{ range .Years }}
+<section class="year">
+	<h2><a href="{{.Route}}">{{.Year}}</a> <span class="count">{{.NumberOfItems}}</span></h2>
+
+	<ul class="months">
+		{{ range .Months }}
+		<li class="month">
+			<a href="{{.Route}}">{{ formatdate "January" .Date }}</a> <span class="count">{{.NumberOfItems}}</span>
+		</li>
+		{{ end }}
+	</ul>
+</section>
+{{ end }}
+
+{{ else }}
+{{ label "archive.empty" .Locale }}
+{{ end }}
+</section>
+`
@@ -0,0 +1,42 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+import (
+	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+)
+
+func init() {
+	templates[templatenames.AtomFeed] = atomFeedTemplate
+}
+
+var atomFeedTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+
+<id>{{.ID}}</id>
+<title type="text">{{.Title | html}}</title>
+{{if .Subtitle}}<subtitle type="text">{{.Subtitle | html}}</subtitle>{{end}}
+<updated>{{.Updated}}</updated>
+<link rel="self" type="application/atom+xml" href="{{.SelfLink | html}}"/>
+<link rel="alternate" type="text/html" href="{{.Link | html}}"/>
+{{if .NextLink}}<link rel="next" type="application/atom+xml" href="{{.NextLink | html}}"/>{{end}}
+<author><name>{{.Author | html}}</name></author>
+<generator uri="https://github.com/andreaskoch/allmark">allmark</generator>
+
+{{ range .Entries }}
+<entry>
+	<id>{{.ID}}</id>
+	<title type="text">{{.Title | html}}</title>
+	<link rel="alternate" type="text/html" href="{{.Link | html}}"/>
+	{{if .Published}}<published>{{.Published}}</published>{{end}}
+	<updated>{{.Updated}}</updated>
+	{{if .Author}}<author><name>{{.Author | html}}</name></author>{{end}}
+	{{range .Categories}}<category term="{{. | html}}"/>{{end}}
+	{{if .Summary}}<summary type="text">{{.Summary | html}}</summary>{{end}}
+	<content type="html">{{.Content | html}}</content>
+</entry>
+{{ end}}
+
+</feed>`
@@ -18,7 +18,8 @@ const converterTemplate = `
 	<meta charset="utf-8">
 	<meta name="robots" content="noindex,nofollow">
 	<link rel="canonical" href="{{ .Route | absolute }}">
-	<link rel="stylesheet" href="/theme/print.css">
+	<link rel="stylesheet" href="{{ theme "print.css" | absolute }}">
+	<script>try { if (localStorage.getItem("allmark-color-scheme")) { document.documentElement.setAttribute("data-color-scheme", localStorage.getItem("allmark-color-scheme")); } } catch (e) {}</script>
 </head>
 <body>
 <h1>
@@ -29,7 +30,32 @@ const converterTemplate = `
 {{.Description}}
 </p>
 
+{{ if .Sections }}
+<nav class="toc">
+<h2>{{ label "print.toc" .Locale }}</h2>
+<ol>
+	{{ range .Sections }}
+	<li class="level-{{.Depth}}"><a href="#{{.Anchor}}">{{.Title}}</a></li>
+	{{ end }}
+</ol>
+</nav>
+{{ end }}
+
+{{.Content}}
+
+{{ range .Sections }}
+<section class="subtree-section level-{{.Depth}}" id="{{.Anchor}}">
+<h1>
+{{.Title}}
+</h1>
+
+<p>
+{{.Description}}
+</p>
+
 {{.Content}}
+</section>
+{{ end }}
 </body>
 </html>
 `
@@ -0,0 +1,178 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+// DefaultLocale is the locale whose labels are used for the labels other locales do not translate.
+const DefaultLocale = "en"
+
+// Labels returns the built-in user interface labels by their locale (e.g. "en") and key (e.g. "search.placeholder").
+func Labels() map[string]map[string]string {
+	return map[string]map[string]string{
+		DefaultLocale: englishLabels,
+		"fa":          persianLabels,
+	}
+}
+
+var englishLabels = map[string]string{
+	"search.placeholder":         "search",
+	"search.submit":              "Search",
+	"search.title":               "Search",
+	"search.title.query":         "%s | Search",
+	"search.description":         "Search this repository.",
+	"search.description.query":   "Search results for %q.",
+	"search.results":             "Displaying %d of %d search results for %q:",
+	"search.noresults":           "No results found for %q.",
+	"pager.position":             "Page %d of %d",
+	"pager.more":                 "Show all documents",
+	"navigation.parent":          "Parent",
+	"navigation.previous":        "Previous",
+	"navigation.next":            "Next",
+	"children.title":             "Child Documents",
+	"backlinks.title":            "Referenced by",
+	"related.title":              "Related Documents",
+	"tagcloud.title":             "Tag Cloud",
+	"tagcloud.count":             "%d documents",
+	"tags.title":                 "Tags:",
+	"publisher.by":               "by",
+	"publisher.createdby":        "created by",
+	"publisher.created":          "created",
+	"publisher.on":               "on",
+	"aliases.shortlink":          "Shortlink:",
+	"aliases.shortlink.title":    "A direct link to this document",
+	"aliases.shortlinks":         "Shortlinks:",
+	"aliases.shortlinks.title":   "Direct links to this document",
+	"aliases.redirect":           "Redirects to %s",
+	"export.print":               "Print",
+	"export.print.subtree":       "Print with child documents",
+	"export.pdf.subtree":         "PDF with child documents",
+	"print.toc":                  "Contents",
+	"feed.rss":                   "RSS",
+	"feed.json":                  "JSON Feed",
+	"feed.atom":                  "Atom",
+	"footer.search":              "Search",
+	"footer.tags":                "Tags",
+	"footer.sitemap":             "Sitemap",
+	"footer.recent":              "Recent Changes",
+	"footer.archive":             "Archive",
+	"footer.stats":               "Statistics",
+	"footer.feed":                "RSS Feed",
+	"footer.shortlinks":          "Shortlinks",
+	"footer.colorscheme":         "Light/Dark",
+	"footer.colorscheme.title":   "Switch between light and dark colors",
+	"footer.poweredby":           "powered by",
+	"presentation.goto":          "Go to slide:",
+	"presentation.go":            "Go",
+	"tagmap.title":               "Tags",
+	"tagmap.empty":               "-- There are currently not tagged items --",
+	"recent.title":               "Recent Changes",
+	"recent.description":         "The most recently modified documents of this repository.",
+	"recent.empty":               "-- There are currently no modified documents --",
+	"archive.title":              "Archive",
+	"archive.title.year":         "Archive %d",
+	"archive.title.month":        "Archive: %s",
+	"archive.description":        "All documents by the month of their creation.",
+	"archive.empty":              "-- There are currently no dated documents --",
+	"archive.weekdays":           "Mo,Tu,We,Th,Fr,Sa,Su",
+	"stats.title":                "Statistics",
+	"stats.description":          "Statistics about the documents and files of this repository.",
+	"stats.items":                "Documents",
+	"stats.words":                "Words",
+	"stats.files":                "Files",
+	"stats.indexing":             "Last index run",
+	"stats.duration":             "%d ms",
+	"stats.types":                "Documents by type",
+	"stats.mimetypes":            "Files by type",
+	"stats.tags":                 "Documents by tag",
+	"sitemap.title":              "Sitemap",
+	"sitemap.description":        "A list of all items in this repository.",
+	"aliasindex.title":           "Shortlinks",
+	"aliasindex.description":     "A list of all short links to different items in this repository.",
+	"aliasindex.empty":           "-- There are currently not items with aliases in this repository --",
+	"error.notfound.title":       "Not found",
+	"error.notfound.description": "The requested resource was not found.",
+	"error.internal.title":       "Internal server error",
+	"error.internal.description": "The server was unable to process your request.",
+}
+
+var persianLabels = map[string]string{
+	"search.placeholder":         "جستجو",
+	"search.submit":              "جستجو",
+	"search.title":               "جستجو",
+	"search.title.query":         "%s | جستجو",
+	"search.description":         "در این مخزن جستجو کنید.",
+	"search.description.query":   "نتایج جستجو برای %q.",
+	"search.results":             "نمایش %d از %d نتیجه جستجو برای %q:",
+	"search.noresults":           "هیچ نتیجه‌ای برای %q یافت نشد.",
+	"pager.position":             "صفحه %d از %d",
+	"pager.more":                 "نمایش همه اسناد",
+	"navigation.parent":          "بالاتر",
+	"navigation.previous":        "قبلی",
+	"navigation.next":            "بعدی",
+	"children.title":             "اسناد زیرمجموعه",
+	"backlinks.title":            "ارجاع شده در",
+	"related.title":              "اسناد مرتبط",
+	"tagcloud.title":             "ابر برچسب‌ها",
+	"tagcloud.count":             "%d سند",
+	"tags.title":                 "برچسب‌ها:",
+	"publisher.by":               "توسط",
+	"publisher.createdby":        "ایجاد شده توسط",
+	"publisher.created":          "ایجاد شده",
+	"publisher.on":               "در",
+	"aliases.shortlink":          "پیوند کوتاه:",
+	"aliases.shortlink.title":    "یک پیوند مستقیم به این سند",
+	"aliases.shortlinks":         "پیوندهای کوتاه:",
+	"aliases.shortlinks.title":   "پیوندهای مستقیم به این سند",
+	"aliases.redirect":           "هدایت به %s",
+	"export.print":               "چاپ",
+	"export.print.subtree":       "چاپ همراه با اسناد زیرمجموعه",
+	"export.pdf.subtree":         "PDF همراه با اسناد زیرمجموعه",
+	"print.toc":                  "فهرست مطالب",
+	"feed.rss":                   "RSS",
+	"feed.json":                  "خوراک JSON",
+	"feed.atom":                  "Atom",
+	"footer.search":              "جستجو",
+	"footer.tags":                "برچسب‌ها",
+	"footer.sitemap":             "نقشه سایت",
+	"footer.recent":              "تغییرات اخیر",
+	"footer.archive":             "بایگانی",
+	"footer.stats":               "آمار",
+	"footer.feed":                "خوراک RSS",
+	"footer.shortlinks":          "پیوندهای کوتاه",
+	"footer.colorscheme":         "روشن/تیره",
+	"footer.colorscheme.title":   "تغییر بین رنگ‌های روشن و تیره",
+	"footer.poweredby":           "قدرت گرفته از",
+	"presentation.goto":          "رفتن به اسلاید:",
+	"presentation.go":            "برو",
+	"tagmap.title":               "برچسب‌ها",
+	"tagmap.empty":               "-- در حال حاضر هیچ مورد برچسب‌داری وجود ندارد --",
+	"recent.title":               "تغییرات اخیر",
+	"recent.description":         "اسنادی از این مخزن که اخیراً تغییر کرده‌اند.",
+	"recent.empty":               "-- در حال حاضر هیچ سند تغییریافته‌ای وجود ندارد --",
+	"archive.title":              "بایگانی",
+	"archive.title.year":         "بایگانی %d",
+	"archive.title.month":        "بایگانی: %s",
+	"archive.description":        "همه اسناد بر اساس ماه ایجاد آن‌ها.",
+	"archive.empty":              "-- در حال حاضر هیچ سند تاریخ‌داری وجود ندارد --",
+	"archive.weekdays":           "د,س,چ,پ,ج,ش,ی",
+	"stats.title":                "آمار",
+	"stats.description":          "آمار اسناد و فایل‌های این مخزن.",
+	"stats.items":                "اسناد",
+	"stats.words":                "واژه‌ها",
+	"stats.files":                "فایل‌ها",
+	"stats.indexing":             "آخرین نمایه‌سازی",
+	"stats.duration":             "%d میلی‌ثانیه",
+	"stats.types":                "اسناد بر اساس نوع",
+	"stats.mimetypes":            "فایل‌ها بر اساس نوع",
+	"stats.tags":                 "اسناد بر اساس برچسب",
+	"sitemap.title":              "نقشه سایت",
+	"sitemap.description":        "فهرست همه موارد این مخزن.",
+	"aliasindex.title":           "پیوندهای کوتاه",
+	"aliasindex.description":     "فهرست همه پیوندهای کوتاه به موارد این مخزن.",
+	"aliasindex.empty":           "-- در حال حاضر هیچ موردی با پیوند کوتاه در این مخزن وجود ندارد --",
+	"error.notfound.title":       "یافت نشد",
+	"error.notfound.description": "منبع درخواست‌شده یافت نشد.",
+	"error.internal.title":       "خطای داخلی سرور",
+	"error.internal.description": "سرور قادر به پردازش درخواست شما نبود.",
+}
@@ -14,15 +14,20 @@ func init() {
 		breadcrumbNavigationSnippet +
 		itemNavigationSnippet +
 		childrenSnippet +
+		backlinksSnippet +
+		relatedSnippet +
 		tagcloudSnippet +
 		tagsSnippet +
 		publisherSnippet +
-		aliasesSnippet
+		aliasesSnippet +
+		tagmapTagSnippet
 
 	templates[templatenames.ToplevelNavigation] = toplevelNavigationSnippet
 	templates[templatenames.BreadcrumbNavigation] = breadcrumbNavigationSnippet
 	templates[templatenames.ItemNavigation] = itemNavigationSnippet
 	templates[templatenames.Children] = childrenSnippet
+	templates[templatenames.Backlinks] = backlinksSnippet
+	templates[templatenames.Related] = relatedSnippet
 	templates[templatenames.TagCloud] = tagcloudSnippet
 	templates[templatenames.Tags] = tagsSnippet
 	templates[templatenames.Publisher] = publisherSnippet
@@ -33,11 +38,15 @@ const masterTemplate = `<!DOCTYPE HTML>
 <html lang="{{.LanguageTag}}" dir="{{.DirectionTag}}" itemscope itemtype="http://schema.org/WebPage" prefix="og: http://ogp.me/ns#" prefix="article: http://ogp.me/ns/article#">
 <head>
 	<base href="{{ .BaseURL }}">
+	<meta name="allmark-basepath" content="{{ basepath }}">
 
 	<title>{{.PageTitle}}</title>
 	<meta name="description" content="{{.Description}}">
+	{{if .NoIndex}}
+	<meta name="robots" content="noindex">
+	{{end}}
 
-	<link rel="search" type="application/opensearchdescription+xml" title="{{.RepositoryName}}" href="/opensearch.xml" />
+	<link rel="search" type="application/opensearchdescription+xml" title="{{.RepositoryName}}" href="{{ basepath }}opensearch.xml" />
 
 	{{if .Publisher.Name }}
 	<meta name="publisher" content="{{.Publisher.Name}}">
@@ -59,31 +68,54 @@ const masterTemplate = `<!DOCTYPE HTML>
 	<meta property="og:description" content="{{.Description}}" />
 	<meta property="og:url" content="{{ .Route | absolute }}" />
 	{{if .LanguageTag}}<meta property="og:locale" content="{{ replace .LanguageTag "-" "_" }}" />{{end}}
-	{{if .Images}}{{range .Images}}
-	<meta property="og:image" content="{{ .Route | absolute }}" />{{end}}{{end}}
+	{{with .PreviewImage}}<meta property="og:image" content="{{ . | absolute }}" />{{end}}
 	{{if .CreationDate}}<meta property="article:published_time" content="{{.CreationDate}}" />{{end}}
 	{{if .LastModifiedDate}}<meta property="article:modified_time" content="{{.LastModifiedDate}}" />{{end}}
 	{{if .Tags}}{{range .Tags}}
 	<meta property="article:tag" content="{{ .Name }}" />{{end}}{{end}}
 
-	<link rel="canonical" href="{{ .Route | absolute }}">
-	<link rel="alternate" hreflang="{{.LanguageTag}}" href="{{.Route}}">
-	<link rel="alternate" type="application/rss+xml" title="RSS" href="/feed.rss">
-	<link rel="shortcut icon" href="/theme/favicon.ico">
+	<meta name="twitter:card" content="{{if .PreviewImage}}summary_large_image{{else}}summary{{end}}" />
+	<meta name="twitter:title" content="{{.PageTitle}}" />
+	<meta name="twitter:description" content="{{.Description}}" />
+	{{with .PreviewImage}}<meta name="twitter:image" content="{{ . | absolute }}" />{{end}}
+	{{with .Publisher.TwitterHandle}}<meta name="twitter:site" content="@{{ replace . "@" "" }}" />{{end}}
+	{{with .Author.TwitterHandle}}<meta name="twitter:creator" content="@{{ replace . "@" "" }}" />{{end}}
 
-	<link rel="stylesheet" href="/theme/screen.css" media="screen">
-	<link rel="stylesheet" href="/theme/print.css" media="print">
-	<link rel="stylesheet" href="/theme/codehighlighting/highlight.css" media="screen, print">
+	<script type="application/ld+json">{{ structureddata . }}</script>
 
-	<script src="/theme/modernizr.js"></script>
+	<link rel="canonical" href="{{ .Route | absolute }}">
+	<link rel="alternate" hreflang="{{.LanguageTag}}" href="{{.Route}}">
+	<link rel="alternate" type="application/rss+xml" title="{{ label "feed.rss" .Locale }}" href="{{ basepath }}feed.rss">
+	<link rel="alternate" type="application/feed+json" title="{{ label "feed.json" .Locale }}" href="{{ basepath }}feed.json">
+	<link rel="alternate" type="application/atom+xml" title="{{ label "feed.atom" .Locale }}" href="{{ basepath }}feed.atom">
+	<link rel="outline" type="text/x-opml" title="OPML" href="{{ basepath }}opml">
+	{{ if .WebAppEnabled }}<link rel="manifest" href="{{ basepath }}manifest.webmanifest">{{ end }}
+	{{ with branding.Icons }}
+	{{ range . }}
+	<link rel="{{.Rel}}" type="{{.Type}}" sizes="{{.Sizes}}" href="{{.Path}}">
+	{{ end }}
+	{{ else }}
+	<link rel="shortcut icon" href="{{ basepath }}theme/favicon.ico">
+	{{ end }}
+
+	<link rel="stylesheet" href="{{ basepath }}theme/screen.css" media="screen">
+	<link rel="stylesheet" href="{{ basepath }}theme/print.css" media="print">
+	<link rel="stylesheet" href="{{ basepath }}theme/codehighlighting/highlight.css" media="screen, print">
+	{{ range .Stylesheets }}
+	<link rel="stylesheet" href="{{.}}">
+	{{ end }}
+	<script>try { if (localStorage.getItem("allmark-color-scheme")) { document.documentElement.setAttribute("data-color-scheme", localStorage.getItem("allmark-color-scheme")); } } catch (e) {}</script>
+
+	<script src="{{ basepath }}theme/modernizr.js"></script>
+	{{ snippet "head" .HeadSnippet }}
 </head>
 <body>
 
 {{template "toplevelnavigation-snippet" .}}
 
 <nav class="search">
-	<form action="/search" method="GET">
-		<input class="typeahead" type="text" name="q" placeholder="search" autocomplete="off">
+	<form action="{{ basepath }}search" method="GET">
+		<input class="typeahead" type="text" name="q" placeholder="{{ label "search.placeholder" .Locale }}" autocomplete="off">
 		<input type="submit" style="visibility:hidden; position: fixed;"/>
 	</form>
 </nav>
@@ -100,19 +132,29 @@ const masterTemplate = `<!DOCTYPE HTML>
 
 	{{template "children-snippet" .}}
 
+	{{template "backlinks-snippet" .}}
+
+	{{template "related-snippet" .}}
+
 	{{template "tagcloud-snippet" .}}
 
 </aside>
 
 <div class="cleaner"></div>
 
-{{if or .PrintURL .JSONURL .MarkdownURL .DOCXURL}}
+{{if or .PrintURL .JSONURL .MarkdownURL .DOCXURL .ODTURL .PDFURL .EPUBURL .ZIPURL}}
 <aside class="export">
 <ul>
-	{{if .PrintURL}}<li><a href="{{.PrintURL}}">Print</a></li>{{end}}
+	{{if .PrintURL}}<li><a href="{{.PrintURL}}">{{ label "export.print" .Locale }}</a></li>{{end}}
+	{{if and .PrintURL .Children}}<li><a href="{{.PrintURL}}?subtree=true">{{ label "export.print.subtree" .Locale }}</a></li>{{end}}
 	{{if .JSONURL}}<li><a href="{{.JSONURL}}">JSON</a></li>{{end}}
 	{{if .MarkdownURL}}<li><a href="{{.MarkdownURL}}">Markdown</a></li>{{end}}
 	{{if .DOCXURL}}<li><a href="{{.DOCXURL}}">DOCX</a></li>{{end}}
+	{{if .ODTURL}}<li><a href="{{.ODTURL}}">ODT</a></li>{{end}}
+	{{if .PDFURL}}<li><a href="{{.PDFURL}}">PDF</a></li>{{end}}
+	{{if and .PDFURL .Children}}<li><a href="{{.PDFURL}}?subtree=true">{{ label "export.pdf.subtree" .Locale }}</a></li>{{end}}
+	{{if .EPUBURL}}<li><a href="{{.EPUBURL}}">EPUB</a></li>{{end}}
+	{{if .ZIPURL}}<li><a href="{{.ZIPURL}}">ZIP</a></li>{{end}}
 </ul>
 </aside>
 {{end}}
@@ -120,31 +162,36 @@ const masterTemplate = `<!DOCTYPE HTML>
 <footer>
 	<nav>
 		<ul>
-			<li><a href="/search">Search</a></li>
-			<li><a href="/tags.html">Tags</a></li>
-			<li><a href="/sitemap.html">Sitemap</a></li>
-			<li><a href="/feed.rss">RSS Feed</a></li>
-			<li><a href="/!">Shortlinks</a></li>
+			<li><a href="{{ basepath }}search">{{ label "footer.search" .Locale }}</a></li>
+			<li><a href="{{ basepath }}tags.html">{{ label "footer.tags" .Locale }}</a></li>
+			<li><a href="{{ basepath }}sitemap.html">{{ label "footer.sitemap" .Locale }}</a></li>
+			<li><a href="{{ basepath }}recent">{{ label "footer.recent" .Locale }}</a></li>
+			<li><a href="{{ basepath }}archive/">{{ label "footer.archive" .Locale }}</a></li>
+			<li><a href="{{ basepath }}-/stats">{{ label "footer.stats" .Locale }}</a></li>
+			<li><a href="{{ basepath }}feed.rss">{{ label "footer.feed" .Locale }}</a></li>
+			<li><a href="{{ basepath }}!">{{ label "footer.shortlinks" .Locale }}</a></li>
+			<li><button class="color-scheme-toggle" type="button" title="{{ label "footer.colorscheme.title" .Locale }}">{{ label "footer.colorscheme" .Locale }}</button></li>
 		</ul>
 	</nav>
 
 	<section class="allmark-promo">
-		powered by <a href="https://pooya.ir">Pooyc Doc Server</a>
+		{{ label "footer.poweredby" .Locale }} <a href="https://pooya.ir">Pooyc Doc Server</a>
 	</section>
 </footer>
 
-<script src="/theme/jquery.js"></script>
-<script src="/theme/jquery.tmpl.js"></script>
-<script src="/theme/lazysizes.js"></script>
-<script src="/theme/site.js"></script>
-<script src="/theme/typeahead.js"></script>
-<script src="/theme/search.js"></script>
+<script src="{{ basepath }}theme/jquery.js"></script>
+<script src="{{ basepath }}theme/jquery.tmpl.js"></script>
+<script src="{{ basepath }}theme/lazysizes.js"></script>
+<script src="{{ basepath }}theme/site.js"></script>
+<script src="{{ basepath }}theme/typeahead.js"></script>
+<script src="{{ basepath }}theme/search.js"></script>
 
 {{ if .IsRepositoryItem }}
-{{ if .LiveReloadEnabled }}<script src="/theme/autoupdate.js"></script>{{ end }}
-<script src="/theme/presentation.js"></script>
-<script src="/theme/latest.js"></script>
-<script src="/theme/codehighlighting/highlight.js"></script>
+{{ if .LiveReloadEnabled }}<script src="{{ basepath }}theme/autoupdate.js"></script>{{ end }}
+{{ if .WebAppEnabled }}<script>if ("serviceWorker" in navigator) { navigator.serviceWorker.register("{{ basepath }}service-worker.js"); }</script>{{ end }}
+<script src="{{ basepath }}theme/presentation.js"></script>
+<script src="{{ basepath }}theme/latest.js"></script>
+<script src="{{ basepath }}theme/codehighlighting/highlight.js"></script>
 <script type="text/javascript">
 $(function() {
 	// code highligting
@@ -170,6 +217,10 @@ $(function() {
 </script>
 {{ end }}
 
+{{ range .Scripts }}
+<script src="{{.}}"></script>
+{{ end }}
+
 {{if .Analytics.Enabled}}
 {{if .Analytics.GoogleAnalytics.Enabled}}
 <script>
@@ -184,16 +235,20 @@ $(function() {
 {{end}}
 {{end}}
 
+{{ snippet "footer" .FooterSnippet }}
 </body>
 </html>`
 
 const toplevelNavigationSnippet = `{{define "toplevelnavigation-snippet"}}
 <nav class="toplevel">
+{{ with branding.Logo }}
+	<a class="logo" href="{{ basepath }}"><img src="{{.}}" alt=""></a>
+{{ end }}
 {{ if .ToplevelNavigation}}
 	<ul>
 	{{range .ToplevelNavigation.Entries}}
 	<li>
-		<a href="{{.Path}}">{{.Title}}</a>
+		<a href="{{.Path}}"{{if .IsExternal}} class="external" rel="external"{{end}}>{{if .IconURL}}<img class="icon" src="{{.IconURL}}" alt="">{{else if .Icon}}<span class="icon">{{.Icon}}</span>{{end}}{{.Title}}</a>
 	</li>
 	{{end}}
 	</ul>
@@ -217,19 +272,19 @@ const itemNavigationSnippet = `{{define "itemnavigation-snippet"}}
 {{if .ItemNavigation.IsAvailable}}
 	<div class="navelement parent">
 		{{if .ItemNavigation.Parent.Path}}
-		<a href="{{.ItemNavigation.Parent.Path}}" title="{{.ItemNavigation.Parent.Title}}">↑ Parent</a>
+		<a href="{{.ItemNavigation.Parent.Path}}" title="{{.ItemNavigation.Parent.Title}}">↑ {{ label "navigation.parent" .Locale }}</a>
 		{{end}}
 	</div>
 
 	<div class="navelement previous">
 		{{if .ItemNavigation.Previous.Path}}
-		<a class="previous" href="{{.ItemNavigation.Previous.Path}}" title="{{.ItemNavigation.Previous.Title}}">← Previous</a>
+		<a class="previous" href="{{.ItemNavigation.Previous.Path}}" title="{{.ItemNavigation.Previous.Title}}">← {{ label "navigation.previous" .Locale }}</a>
 		{{end}}
 	</div>
 
 	<div class="navelement next">
 		{{if .ItemNavigation.Next.Path}}
-		<a class="next" href="{{.ItemNavigation.Next.Path}}" title="{{.ItemNavigation.Next.Title}}">Next →</a>
+		<a class="next" href="{{.ItemNavigation.Next.Path}}" title="{{.ItemNavigation.Next.Title}}">{{ label "navigation.next" .Locale }} →</a>
 		{{end}}
 	</div>
 {{end}}
@@ -240,7 +295,7 @@ const itemNavigationSnippet = `{{define "itemnavigation-snippet"}}
 const childrenSnippet = `{{define "children-snippet"}}
 <section class="children">
 {{ if .Children }}
-<h1>Child Documents</h1>
+<h1>{{ label "children.title" .Locale }}</h1>
 
 <ol class="list">
 {{range .Children}}
@@ -250,6 +305,52 @@ const childrenSnippet = `{{define "children-snippet"}}
 </li>
 {{end}}
 </ol>
+
+{{ with .ChildrenPager }}
+{{ if gt .TotalPages 1 }}
+<nav class="pager">
+	{{ if .PreviousURL }}<a class="previous" rel="prev" href="{{.PreviousURL}}">← {{ label "navigation.previous" $.Locale }}</a>{{ end }}
+	<span class="position">{{ printf (label "pager.position" $.Locale) .Page .TotalPages }}</span>
+	{{ if .NextURL }}<a class="next" rel="next" href="{{.NextURL}}">{{ label "navigation.next" $.Locale }} →</a>{{ end }}
+</nav>
+{{ end }}
+{{ end }}
+{{end}}
+</section>
+{{end}}
+`
+
+const backlinksSnippet = `{{define "backlinks-snippet"}}
+<section class="backlinks">
+{{ if .Backlinks }}
+<h1>{{ label "backlinks.title" .Locale }}</h1>
+
+<ol class="list">
+{{range .Backlinks}}
+<li class="backlink">
+	<a href="{{.Route}}" class="backlink-title backlink-link">{{.Title}}</a>
+	<p class="backlink-description">{{.Description}}</p>
+</li>
+{{end}}
+</ol>
+{{end}}
+</section>
+{{end}}
+`
+
+const relatedSnippet = `{{define "related-snippet"}}
+<section class="related">
+{{ if .Related }}
+<h1>{{ label "related.title" .Locale }}</h1>
+
+<ol class="list">
+{{range .Related}}
+<li class="relateditem">
+	<a href="{{.Route}}" class="relateditem-title relateditem-link">{{.Title}}</a>
+	<p class="relateditem-description">{{.Description}}</p>
+</li>
+{{end}}
+</ol>
 {{end}}
 </section>
 {{end}}
@@ -258,12 +359,12 @@ const childrenSnippet = `{{define "children-snippet"}}
 const tagcloudSnippet = `{{define "tagcloud-snippet"}}
 <section class="tagcloud">
 {{if .TagCloud}}
-	<h1>Tag Cloud</h1>
+	<h1>{{ label "tagcloud.title" .Locale }}</h1>
 
 	<div class="tags">
 	{{range .TagCloud}}
 	<span class="level-{{.Level}}">
-		<a href="{{.Route}}">{{.Name}}</a>
+		<a href="{{.Route}}" title="{{ printf (label "tagcloud.count" $.Locale) .NumberOfChildren }}">{{.Name}}</a>
 	</span>
 	{{end}}
 	</div>
@@ -277,7 +378,7 @@ const tagsSnippet = `{{define "tags-snippet"}}
 <section class="tags">
 {{ if .Tags }}
 	<header>
-		Tags:
+		{{ label "tags.title" .Locale }}
 	</header>
 
 	<ul>
@@ -296,7 +397,7 @@ const publisherSnippet = `{{define "publisher-snippet"}}
 {{if or .Author.Name .CreationDate}}
 {{if and .Author.Name .Author.URL}}
 
-	created by <span class="author" itemprop="author" rel="author">
+	{{ label "publisher.createdby" .Locale }} <span class="author" itemprop="author" rel="author">
 	<a href="{{ .Author.URL }}" title="{{ .Author.Name }}" target="_blank">
 	{{ .Author.Name }}
 	</a>
@@ -304,12 +405,12 @@ const publisherSnippet = `{{define "publisher-snippet"}}
 
 {{else if .Author.Name}}
 
-	created by <span class="author" itemprop="author" rel="author">{{ .Author.Name }}</span>
+	{{ label "publisher.createdby" .Locale }} <span class="author" itemprop="author" rel="author">{{ .Author.Name }}</span>
 
 {{end}}
 {{if .CreationDate}}
 
-	{{if not .Author.Name}}created{{end}} on <span class="creationdate" itemprop="dateCreated">{{ .CreationDate }}</span>
+	{{if not .Author.Name}}{{ label "publisher.created" .Locale }}{{end}} {{ label "publisher.on" .Locale }} <span class="creationdate" itemprop="dateCreated">{{ .CreationDate }}</span>
 
 {{end}}
 {{end}}
@@ -324,19 +425,19 @@ const aliasesSnippet = `{{define "aliases-snippet"}}
 {{ if .Aliases }}
 
 {{ if gt (len .Aliases) 1 }}
-	<header title="Direct links to this document">
-		Shortlinks:
+	<header title="{{ label "aliases.shortlinks.title" .Locale }}">
+		{{ label "aliases.shortlinks" .Locale }}
 	</header>
 {{else}}
-	<header title="A direct link to this document">
-		Shortlink:
+	<header title="{{ label "aliases.shortlink.title" .Locale }}">
+		{{ label "aliases.shortlink" .Locale }}
 	</header>
 {{end}}
 
 <ul>
 {{range .Aliases}}
 <li>
-	<input type="text" value="{{.Route | absolute}}" title="Redirects to {{.TargetRoute | absolute}}" readonly="readonly" />
+	<input type="text" value="{{.Route | absolute}}" title="{{ printf (label "aliases.redirect" $.Locale) (.TargetRoute | absolute) }}" readonly="readonly" />
 </li>
 {{end}}
 </ul>
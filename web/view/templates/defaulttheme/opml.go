@@ -0,0 +1,28 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+import (
+	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+)
+
+func init() {
+	templates[templatenames.OPML] = opmlTemplate
+}
+
+const opmlTemplate = `{{define "outline"}}<outline text="{{.Text | html}}"{{if .Description}} description="{{.Description | html}}"{{end}} type="{{.Type}}"{{if .URL}} url="{{.URL | html}}"{{end}}{{if .HTMLURL}} htmlUrl="{{.HTMLURL | html}}"{{end}}{{if .XMLURL}} xmlUrl="{{.XMLURL | html}}"{{end}}{{if .Created}} created="{{.Created}}"{{end}}{{if .Children}}>
+{{range .Children}}{{template "outline" .}}{{end}}</outline>
+{{else}}/>
+{{end}}{{end}}<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+<head>
+	<title>{{.Title | html}}</title>
+	{{if .DateModified}}<dateModified>{{.DateModified}}</dateModified>{{end}}
+	{{if .OwnerName}}<ownerName>{{.OwnerName | html}}</ownerName>{{end}}
+	<docs>http://opml.org/spec2.opml</docs>
+</head>
+<body>
+{{range .Outlines}}{{template "outline" .}}{{end}}</body>
+</opml>`
@@ -31,16 +31,16 @@ const presentationTemplate = `
 	</div>
 
 	<div class="nav-element controls">
-		<button class="deck-prev-link" title="Previous">&#8592;</button>
-		<button href="#" class="deck-next-link" title="Next">&#8594;</button>
+		<button class="deck-prev-link" title="{{ label "navigation.previous" .Locale }}">&#8592;</button>
+		<button href="#" class="deck-next-link" title="{{ label "navigation.next" .Locale }}">&#8594;</button>
 	</div>
 
 	<div class="nav-element jumper">
 		<form action="." method="get" class="goto-form">
-			<label for="goto-slide">Go to slide:</label>
+			<label for="goto-slide">{{ label "presentation.goto" .Locale }}</label>
 			<input type="text" name="slidenum" id="goto-slide" list="goto-datalist">
 			<datalist id="goto-datalist"></datalist>
-			<input type="submit" value="Go">
+			<input type="submit" value="{{ label "presentation.go" .Locale }}">
 		</form>
 	</div>
 </nav>
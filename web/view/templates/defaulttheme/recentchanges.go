@@ -0,0 +1,47 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+import (
+	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+)
+
+func init() {
+	templates[templatenames.RecentChanges] = recentChangesTemplate
+}
+
+const recentChangesTemplate = `
+<header>
+<h1 class="title">
+{{.Title}}
+</h1>
+</header>
+
+<section class="description">
+{{.Description}}
+<a href="{{.FeedURL}}" type="application/rss+xml">{{ label "feed.rss" .Locale }}</a>
+</section>
+
+<section class="content">
+{{ if .Days }}
+{{ range .Days }}
+<section class="day">
+	<h2><time datetime="{{.Date}}">{{ formatdate "January 2, 2006" .Date }}</time></h2>
+
+	<ol class="children">
+		{{range .Items}}
+		<li class="child">
+			<a href="{{.Route}}" class="child-title child-link">{{.Title}}</a>
+			<p class="child-description">{{.Description}}</p>
+		</li>
+		{{end}}
+	</ol>
+</section>
+{{ end }}
+{{ else }}
+{{ label "recent.empty" .Locale }}
+{{ end }}
+</section>
+`
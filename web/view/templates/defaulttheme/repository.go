@@ -27,7 +27,7 @@ const repositoryTemplate = `
 <section class="publisher">
 {{if and .Author.Name .Author.URL}}
 
-	by <span class="author" itemprop="author" rel="author">
+	{{ label "publisher.by" .Locale }} <span class="author" itemprop="author" rel="author">
 	<a href="{{ .Author.URL }}" title="{{ .Author.Name }}" target="_blank">
 	{{ .Author.Name }}
 	</a>
@@ -35,7 +35,7 @@ const repositoryTemplate = `
 
 {{else if .Author.Name}}
 
-	created by <span class="author" itemprop="author" rel="author">{{ .Author.Name }}</span>
+	{{ label "publisher.createdby" .Locale }} <span class="author" itemprop="author" rel="author">{{ .Author.Name }}</span>
 
 {{end}}
 </section>
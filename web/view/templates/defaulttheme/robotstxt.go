@@ -12,8 +12,9 @@ func init() {
 	templates[templatenames.RobotsTxt] = robotsTxtTemplate
 }
 
-const robotsTxtTemplate = `{{ range .Disallows }}User-agent: {{.UserAgent}}
-{{ range .Paths }}Disallow: {{.}}
-{{ end }}{{ end }}
-Sitemap: {{.SitemapURL}}
-`
+const robotsTxtTemplate = `{{ range .Groups }}User-agent: {{.UserAgent}}
+{{ range .Allows }}Allow: {{.}}
+{{ end }}{{ range .Disallows }}Disallow: {{.}}
+{{ end }}
+{{ end }}{{ if .SitemapURL }}Sitemap: {{.SitemapURL}}
+{{ end }}`
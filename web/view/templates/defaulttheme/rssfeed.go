@@ -28,6 +28,7 @@ var rssFeedTemplate = `<?xml version="1.0" encoding="UTF-8"?>
 	<description><![CDATA[ {{.Description}} ]]></description>
 	<link>{{.Link}}</link>
 	<pubDate>{{.PubDate}}</pubDate>
+	{{if .Enclosure}}<enclosure url="{{.Enclosure.URL | html}}" length="{{.Enclosure.Length}}" type="{{.Enclosure.Type | html}}"/>{{end}}
 </item>
 {{ end}}
 
@@ -28,29 +28,38 @@ const searchTemplate = `
 
 <section class="content">
 <nav>
-	<form action="/search" method="GET">
-		<input type="text" name="q" placeholder="search" value="{{.Query}}" autocomplete="off">
-		<input type="submit" value="Search">
+	<form action="{{ basepath }}search" method="GET">
+		<input type="text" name="q" placeholder="{{ label "search.placeholder" $.Locale }}" value="{{.Query}}" autocomplete="off">
+		<input type="submit" value="{{ label "search.submit" $.Locale }}">
 	</form>
 </nav>
 
 {{if .ResultCount}}
 <header>
-	Displaying {{.ResultCount}} of {{.TotalResultCount}} search results for "{{.Query}}":
+	{{ printf (label "search.results" $.Locale) .ResultCount .TotalResultCount .Query }}
 </header>
 
 <ol start="{{.StartIndex}}">
 	{{ range .Results }}
 	<li data-index="{{.Index}}">
 			<a class="title" href="{{.Route}}">{{.Title}}</a>
-			<p class="description">{{.Description}}</p>
+			{{if .File}}<a class="file" href="{{.FileRoute}}">{{.File}}</a>{{end}}
+			<p class="snippet">{{.Snippet}}</p>
 			<span class="path">{{.Path}}</span>
 	</li>
 	{{ end }}
 </ol>
+
+{{if gt .TotalPages 1}}
+<nav class="pager">
+	{{if .PreviousPage}}<a class="previous" rel="prev" href="{{ basepath }}search?q={{urlquery .Query}}&amp;page={{.PreviousPage}}&amp;pageSize={{.ItemsPerPage}}">← {{ label "navigation.previous" $.Locale }}</a>{{end}}
+	<span class="position">{{ printf (label "pager.position" $.Locale) .Page .TotalPages }}</span>
+	{{if .NextPage}}<a class="next" rel="next" href="{{ basepath }}search?q={{urlquery .Query}}&amp;page={{.NextPage}}&amp;pageSize={{.ItemsPerPage}}">{{ label "navigation.next" $.Locale }} →</a>{{end}}
+</nav>
+{{end}}
 {{else}}
 	{{if .Query}}
-	No results found for "{{.Query}}".
+	{{ printf (label "search.noresults" $.Locale) .Query }}
 	{{end}}
 {{end}}
 </section>
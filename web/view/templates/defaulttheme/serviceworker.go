@@ -0,0 +1,85 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+import (
+	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+)
+
+func init() {
+	templates[templatenames.ServiceWorker] = serviceWorkerTemplate
+}
+
+// serviceWorkerTemplate caches the start page, the theme and the configured precache URLs on installation.
+// Pages are loaded from the network first and from the cache if the network is not available; all other
+// files (e.g. the theme files and images) are loaded from the cache first. Every response is cached, so the
+// visited items remain readable offline.
+const serviceWorkerTemplate = `"use strict";
+
+var cacheName = {{ printf "%q" .CacheName }};
+var startURL = {{ printf "%q" .StartURL }};
+var precacheURLs = [{{ range $index, $url := .PrecacheURLs }}{{ if $index }},{{ end }}
+	{{ printf "%q" $url }}{{ end }}
+];
+
+// the live-reload, search and API routes only work with a connection to the server
+var uncachedPathPattern = /(\.(ws|events|zip)$|\/search(\/suggest)?$|\/search\.json$|\/api\/|\/graphql$)/;
+
+self.addEventListener("install", function (event) {
+	event.waitUntil(caches.open(cacheName).then(function (cache) {
+		return cache.addAll(precacheURLs);
+	}).then(function () {
+		return self.skipWaiting();
+	}));
+});
+
+self.addEventListener("activate", function (event) {
+	event.waitUntil(caches.keys().then(function (names) {
+		return Promise.all(names.filter(function (name) {
+			return name.indexOf("allmark-") === 0 && name !== cacheName;
+		}).map(function (name) {
+			return caches.delete(name);
+		}));
+	}).then(function () {
+		return self.clients.claim();
+	}));
+});
+
+function store(request, response) {
+	if (response.ok && response.type === "basic") {
+		var copy = response.clone();
+		caches.open(cacheName).then(function (cache) {
+			cache.put(request, copy);
+		});
+	}
+
+	return response;
+}
+
+self.addEventListener("fetch", function (event) {
+	var request = event.request;
+	var url = new URL(request.url);
+	if (request.method !== "GET" || request.headers.has("range") || url.origin !== self.location.origin || uncachedPathPattern.test(url.pathname)) {
+		return;
+	}
+
+	if (request.mode === "navigate") {
+		event.respondWith(fetch(request).then(function (response) {
+			return store(request, response);
+		}).catch(function () {
+			return caches.match(request).then(function (cached) {
+				return cached || caches.match(startURL);
+			});
+		}));
+		return;
+	}
+
+	event.respondWith(caches.match(request).then(function (cached) {
+		return cached || fetch(request).then(function (response) {
+			return store(request, response);
+		});
+	}));
+});
+`
@@ -0,0 +1,62 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package defaulttheme
+
+import (
+	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+)
+
+func init() {
+	templates[templatenames.Stats] = statsTemplate
+}
+
+const statsTemplate = `
+<header>
+<h1 class="title">
+{{.Title}}
+</h1>
+</header>
+
+<section class="description">
+{{.Description}}
+<a href="{{.JSONURL}}" type="application/json">JSON</a>
+</section>
+
+<section class="content">
+{{ with .Stats }}
+<table class="summary">
+	<tr><th>{{ label "stats.items" $.Locale }}</th><td>{{.NumberOfItems}}</td></tr>
+	<tr><th>{{ label "stats.words" $.Locale }}</th><td>{{.NumberOfWords}}</td></tr>
+	<tr><th>{{ label "stats.files" $.Locale }}</th><td>{{.Files.NumberOfFiles}} ({{.Files.Size}})</td></tr>
+	<tr><th>{{ label "stats.indexing" $.Locale }}</th><td>{{ printf (label "stats.duration" $.Locale) .LastIndexRun.DurationInMilliseconds }}{{ if .LastIndexRun.Date }}, <time datetime="{{.LastIndexRun.Date}}">{{ formatdate "January 2, 2006 15:04:05" .LastIndexRun.Date }}</time>{{ end }}</td></tr>
+</table>
+
+<h2>{{ label "stats.types" $.Locale }}</h2>
+<table class="types">
+	{{ range .ItemTypes }}
+	<tr><th>{{.Name}}</th><td>{{.NumberOfItems}}</td></tr>
+	{{ end }}
+</table>
+
+{{ if .Files.MimeTypes }}
+<h2>{{ label "stats.mimetypes" $.Locale }}</h2>
+<table class="mimetypes">
+	{{ range .Files.MimeTypes }}
+	<tr><th>{{.MimeType}}</th><td>{{.NumberOfFiles}}</td><td>{{.Size}}</td></tr>
+	{{ end }}
+</table>
+{{ end }}
+
+{{ if .Tags }}
+<h2>{{ label "stats.tags" $.Locale }}</h2>
+<table class="tags">
+	{{ range .Tags }}
+	<tr><th>{{.Name}}</th><td>{{.NumberOfItems}}</td></tr>
+	{{ end }}
+</table>
+{{ end }}
+{{ end }}
+</section>
+`
@@ -10,6 +10,7 @@ import (
 
 func init() {
 	templates[templatenames.TagMap] = tagmapTemplate
+	templates[templatenames.TagMapTag] = tagmapTagSnippet
 }
 
 const tagmapTemplate = `
@@ -25,10 +26,34 @@ const tagmapTemplate = `
 
 <section class="content">
 {{ if .Tags }}
-{{ range .Tags }}
 <ul class="tags">
+{{ range .Tags }}
+{{ template "tagmap-tag-snippet" . }}
+{{ end }}
+</ul>
+
+{{ with .Pager }}
+{{ if gt .TotalPages 1 }}
+<nav class="pager">
+	{{ if .PreviousURL }}<a class="previous" rel="prev" href="{{.PreviousURL}}">← {{ label "navigation.previous" $.Locale }}</a>{{ end }}
+	<span class="position">{{ printf (label "pager.position" $.Locale) .Page .TotalPages }}</span>
+	{{ if .NextURL }}<a class="next" rel="next" href="{{.NextURL}}">{{ label "navigation.next" $.Locale }} →</a>{{ end }}
+</nav>
+{{ end }}
+{{ end }}
+{{ else}}
+{{ label "tagmap.empty" .Locale }}
+{{ end }}
+
+</section>
+`
+
+// tagmapTagSnippet renders a tag of the tag map, its items and its sub tags. It is part of the master template
+// because the sub templates cannot contain template definitions.
+const tagmapTagSnippet = `{{define "tagmap-tag-snippet"}}
 <li class="tag">
-	<a name="{{.Anchor}}" href={{.Route}}>{{.Name}}</a>
+	<a name="{{.Anchor}}" href="{{.Route}}" title="{{.Name}}">{{.Label}}</a>
+	<span class="count">{{.NumberOfChildren}}</span>
 	{{ if .Children }}
 	<ol class="children">
 		{{range .Children}}
@@ -38,13 +63,14 @@ const tagmapTemplate = `
 		</li>
 		{{end}}
 	</ol>
+	{{ if .MoreURL }}<a class="more" href="{{.MoreURL}}">{{ label "pager.more" "" }}</a>{{ end }}
+	{{ end }}
+	{{ if .SubTags }}
+	<ul class="tags">
+		{{ range .SubTags }}
+		{{ template "tagmap-tag-snippet" . }}
+		{{ end }}
+	</ul>
 	{{ end }}
 </li>
-</ul>
-{{ end }}
-{{ else}}
--- There are currently not tagged items --
-{{ end }}
-
-</section>
-`
+{{end}}`
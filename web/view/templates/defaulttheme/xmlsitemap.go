@@ -10,21 +10,34 @@ import (
 
 func init() {
 	templates[templatenames.XMLSitemap] = xmlSitemapTemplate
+	templates[templatenames.XMLSitemapIndex] = xmlSitemapIndexTemplate
 }
 
 var xmlSitemapTemplate = `<?xml version="1.0" encoding="UTF-8"?>
 <urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:image="http://www.google.com/schemas/sitemap-image/1.1">
 {{ range .Entries }}
 <url>
-	<loc>{{.Loc}}</loc>
+	<loc>{{.Loc | html}}</loc>
 	{{if .LastModified}}<lastmod>{{.LastModified}}</lastmod>{{end}}
 	<changefreq>never</changefreq>
 	<priority>1.0</priority>
 	{{range .Images}}
 	<image:image>
-		<image:loc>{{.Loc}}</image:loc>
+		<image:loc>{{.Loc | html}}</image:loc>
+		{{if .Title}}<image:title>{{.Title | html}}</image:title>{{end}}
 	</image:image>
 	{{end}}
 </url>
 {{ end }}
 </urlset>`
+
+
+var xmlSitemapIndexTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+{{ range .Sitemaps }}
+<sitemap>
+	<loc>{{.Loc | html}}</loc>
+	{{if .LastModified}}<lastmod>{{.LastModified}}</lastmod>{{end}}
+</sitemap>
+{{ end }}
+</sitemapindex>`
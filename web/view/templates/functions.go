@@ -0,0 +1,178 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package templates
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+// builtInFunctionNames contains the names of the template functions which are provided by allmark itself
+// and cannot be replaced by registered functions.
+var builtInFunctionNames = map[string]bool{
+	"hostname":       true,
+	"basepath":       true,
+	"absolute":       true,
+	"replace":        true,
+	"formatdate":     true,
+	"first":          true,
+	"hastag":         true,
+	"withtag":        true,
+	"meta":           true,
+	"label":          true,
+	"snippet":        true,
+	"branding":       true,
+	"theme":          true,
+	"structureddata": true,
+}
+
+var (
+	registeredFunctionsLock sync.RWMutex
+	registeredFunctions     = make(map[string]interface{})
+)
+
+// RegisterFunction makes the given function available to all templates under the given name
+// (see text/template.FuncMap for the requirements). It returns an error if the name is empty,
+// is used by one of the built-in template functions or if the given value is not a function.
+// Templates which have already been parsed only get the function once they are reloaded.
+func RegisterFunction(name string, function interface{}) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("The name of a template function must not be empty.")
+	}
+
+	if builtInFunctionNames[name] {
+		return fmt.Errorf("The template function %q is built in and cannot be replaced.", name)
+	}
+
+	if function == nil || reflect.TypeOf(function).Kind() != reflect.Func {
+		return fmt.Errorf("The template function %q is not a function.", name)
+	}
+
+	// let the template package validate the signature
+	if _, err := template.New(name).Funcs(template.FuncMap{name: function}).Parse(""); err != nil {
+		return fmt.Errorf("The template function %q cannot be registered. Error: %s", name, err.Error())
+	}
+
+	registeredFunctionsLock.Lock()
+	defer registeredFunctionsLock.Unlock()
+
+	registeredFunctions[name] = function
+	return nil
+}
+
+// addRegisteredFunctions adds all registered template functions to the given helpers.
+func addRegisteredFunctions(helpers map[string]interface{}) {
+	registeredFunctionsLock.RLock()
+	defer registeredFunctionsLock.RUnlock()
+
+	for name, function := range registeredFunctions {
+		helpers[name] = function
+	}
+}
+
+// formatDate formats the given date (e.g. the creation date of a view model) with the given layout
+// (e.g. "January 2, 2006"). Dates which cannot be parsed are returned unchanged.
+func formatDate(layout, date string) string {
+	for _, dateLayout := range []string{"2006-01-02", time.RFC3339} {
+		if parsedDate, err := time.Parse(dateLayout, date); err == nil {
+			return parsedDate.Format(layout)
+		}
+	}
+
+	return date
+}
+
+// first returns the first n elements of the given slice (e.g. the children of a view model).
+func first(n int, list interface{}) (interface{}, error) {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("Cannot take the first %d elements of a %T.", n, list)
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	if n > value.Len() {
+		n = value.Len()
+	}
+
+	return value.Slice(0, n).Interface(), nil
+}
+
+// hasTag returns true if the given view model, list of tags or list of tag names contains a tag with
+// the given name (ignoring the case).
+func hasTag(name string, source interface{}) (bool, error) {
+	tagNames, err := getTagNames(source)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tagName := range tagNames {
+		if strings.EqualFold(strings.TrimSpace(tagName), strings.TrimSpace(name)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// withTag returns the view models of the given list which have a tag with the given name (ignoring the case).
+func withTag(name string, models []viewmodel.Base) []viewmodel.Base {
+	filteredModels := make([]viewmodel.Base, 0, len(models))
+	for _, model := range models {
+		if found, _ := hasTag(name, model); found {
+			filteredModels = append(filteredModels, model)
+		}
+	}
+
+	return filteredModels
+}
+
+// getTagNames returns the names of the tags of the given view model, list of tags or list of tag names.
+func getTagNames(source interface{}) ([]string, error) {
+	switch tags := source.(type) {
+	case viewmodel.Model:
+		return tags.TagNames, nil
+	case viewmodel.Base:
+		return tags.TagNames, nil
+	case []viewmodel.Tag:
+		tagNames := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+
+		return tagNames, nil
+	case []string:
+		return tags, nil
+	}
+
+	return nil, fmt.Errorf("Cannot read the tags of a %T.", source)
+}
+
+// meta returns the value of the meta data definition with the given key (ignoring the case) of the given
+// view model or meta data map; it returns an empty string if the definition does not exist.
+func meta(key string, source interface{}) (string, error) {
+	var fields map[string]string
+	switch model := source.(type) {
+	case viewmodel.Model:
+		fields = model.MetaData
+	case viewmodel.Base:
+		fields = model.MetaData
+	case map[string]string:
+		fields = model
+	default:
+		return "", fmt.Errorf("Cannot read the meta data of a %T.", source)
+	}
+
+	return fields[strings.ToLower(strings.Join(strings.Fields(key), " "))], nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package templates
+
+import (
+	"strings"
+
+	"github.com/andreaskoch/allmark/common/config"
+	"github.com/andreaskoch/allmark/web/view/templates/defaulttheme"
+)
+
+// labelCatalog contains the user interface labels of all locales.
+type labelCatalog struct {
+	defaultLocale string
+	locales       map[string]map[string]string
+}
+
+// newLabelCatalog creates a catalog of the built-in labels and the given labels (e.g. from the locale files)
+// which extend or replace the built-in ones. The given default locale (e.g. "de") is used for pages whose
+// language has no locale of its own.
+func newLabelCatalog(defaultLocale string, locales map[string]config.LocaleLabels) labelCatalog {
+	catalog := labelCatalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		locales:       make(map[string]map[string]string),
+	}
+
+	for locale, labels := range defaulttheme.Labels() {
+		catalog.add(locale, labels)
+	}
+
+	for locale, labels := range locales {
+		catalog.add(locale, labels)
+	}
+
+	return catalog
+}
+
+// add adds the given labels to the given locale.
+func (catalog labelCatalog) add(locale string, labels map[string]string) {
+	locale = normalizeLocale(locale)
+	if catalog.locales[locale] == nil {
+		catalog.locales[locale] = make(map[string]string)
+	}
+
+	for key, label := range labels {
+		catalog.locales[locale][key] = label
+	}
+}
+
+// Label returns the label with the given key (e.g. "search.placeholder") in the locale for the given language
+// (e.g. "de-CH"). Labels which the locale does not translate are taken from the default locale; unknown keys
+// are returned unchanged.
+func (catalog labelCatalog) Label(language, key string) string {
+	if label, exists := catalog.locales[catalog.getLocale(language)][key]; exists {
+		return label
+	}
+
+	if label, exists := catalog.locales[defaulttheme.DefaultLocale][key]; exists {
+		return label
+	}
+
+	return key
+}
+
+// getLocale returns the name of the locale for the given language: the locale of the language itself
+// (e.g. "de-ch" or "de"), the configured default locale or the built-in default locale.
+func (catalog labelCatalog) getLocale(language string) string {
+	for _, locale := range []string{normalizeLocale(language), catalog.defaultLocale} {
+		if locale == "" {
+			continue
+		}
+
+		if _, exists := catalog.locales[locale]; exists {
+			return locale
+		}
+
+		if baseLocale, _, isRegional := strings.Cut(locale, "-"); isRegional {
+			if _, exists := catalog.locales[baseLocale]; exists {
+				return baseLocale
+			}
+		}
+	}
+
+	return defaulttheme.DefaultLocale
+}
+
+// normalizeLocale returns the lowercase form of the given locale with "-" as the separator (e.g. "de-ch" for "de_CH").
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
@@ -6,40 +6,82 @@ package templates
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"text/template"
 
+	"github.com/andreaskoch/allmark/common/config"
 	"github.com/andreaskoch/allmark/web/view/templates/defaulttheme"
 	"github.com/andreaskoch/allmark/web/view/templates/templatenames"
+	"github.com/andreaskoch/allmark/web/view/themes"
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
 	"github.com/andreaskoch/allmark/web/webpaths"
 )
 
+// customTemplateNamePattern matches the names of the templates which can be selected for items (e.g. "landing").
+var customTemplateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// themeFileReferencePattern matches the references to the files of the theme in the templates (e.g. `{{ basepath }}theme/screen.css`).
+var themeFileReferencePattern = regexp.MustCompile(`\{\{\s*basepath\s*\}\}theme/([A-Za-z0-9_./-]+)`)
+
+// builtInItemTemplates contains the names of the default templates which can be selected for items.
+var builtInItemTemplates = map[string]bool{
+	templatenames.Document:     true,
+	templatenames.Presentation: true,
+	templatenames.Repository:   true,
+}
+
+// BrandingProvider returns the logo and the icon links of the site (e.g. BrandingOrchestrator.GetBranding).
+type BrandingProvider func() viewmodel.Branding
+
 // A Provider gives access to all required templates.
 type Provider struct {
 	Modified chan bool
 
-	folder              string
+	folders             []string
+	basePath            string
 	templatedefinitions map[string]*templateDefinition
+	labels              labelCatalog
+	snippets            config.Snippets
+	branding            BrandingProvider
+	themeFingerprints   *themes.Fingerprints
 }
 
-// NewProvider creates a new template provider with the given folder as the base.
-func NewProvider(templateFolder string) Provider {
+// NewProvider creates a new template provider with the given folders as the base. Templates are read from the
+// first folder that contains them; templates which none of the folders contains are taken from the default theme.
+// The basePath is the path prefix under which the repository is served (e.g. "/", "/wiki/"). The given locales
+// extend or replace the built-in user interface labels; the default locale is used for pages whose language
+// has no locale of its own. The snippets are inserted into the head and the body of every page. The branding
+// provider is optional and supplies the logo and the icon links of every page. If theme fingerprints are given,
+// the references to the style sheets and scripts of the theme are replaced with their fingerprinted paths.
+func NewProvider(templateFolders []string, basePath string, defaultLocale string, locales map[string]config.LocaleLabels, snippets config.Snippets, branding BrandingProvider, themeFingerprints *themes.Fingerprints) Provider {
 
 	// register all templates
 	templates := make(map[string]*templateDefinition)
 	for templateName, rawTemplate := range defaulttheme.RawTemplates() {
-		templates[templateName] = newTemplateDefinition(templateFolder, templateName, rawTemplate)
+		templates[templateName] = newTemplateDefinition(templateFolders, templateName, rawTemplate)
 	}
 
 	// create the provider
 	provider := Provider{
-		folder:              templateFolder,
+		folders:             templateFolders,
+		basePath:            basePath,
 		templatedefinitions: templates,
+		labels:              newLabelCatalog(defaultLocale, locales),
+		snippets:            snippets,
+		branding:            branding,
+		themeFingerprints:   themeFingerprints,
 	}
 
 	return provider
 }
 
+// Label returns the user interface label with the given key (e.g. "search.placeholder") for the given
+// language (e.g. "de"); if the language is empty the label of the default locale is returned.
+func (provider *Provider) Label(language, key string) string {
+	return provider.labels.Label(language, key)
+}
+
 // GetErrorTemplate returns the template for error pages.
 func (provider *Provider) GetErrorTemplate(hostname string) (*template.Template, error) {
 	return provider.getWrappedTemplate(templatenames.Error, hostname)
@@ -71,26 +113,76 @@ func (provider *Provider) GetItemTemplate(itemType, hostname string) (*template.
 	return provider.getWrappedTemplate(itemType, hostname)
 }
 
+// GetCustomItemTemplate returns the item template with the given name: one of the default item templates
+// (e.g. "document", "presentation") or a template that is stored under this name (e.g. "landing.gohtml")
+// in one of the template folders.
+func (provider *Provider) GetCustomItemTemplate(templateName, hostname string) (*template.Template, error) {
+	if !customTemplateNamePattern.MatchString(templateName) {
+		return nil, fmt.Errorf("%q is not a valid template name.", templateName)
+	}
+
+	if _, isDefaultTemplate := provider.templatedefinitions[templateName]; isDefaultTemplate && !builtInItemTemplates[templateName] {
+		return nil, fmt.Errorf("The template %q cannot be used for items.", templateName)
+	}
+
+	return provider.getWrappedTemplate(templateName, hostname)
+}
+
 // GetTagMapTemplate returns the template for tags.
 func (provider *Provider) GetTagMapTemplate(hostname string) (*template.Template, error) {
 	return provider.getWrappedTemplate(templatenames.TagMap, hostname)
 }
 
+// GetRecentChangesTemplate returns the template for the recent changes.
+func (provider *Provider) GetRecentChangesTemplate(hostname string) (*template.Template, error) {
+	return provider.getWrappedTemplate(templatenames.RecentChanges, hostname)
+}
+
+// GetArchiveTemplate returns the template for the archive pages.
+func (provider *Provider) GetArchiveTemplate(hostname string) (*template.Template, error) {
+	return provider.getWrappedTemplate(templatenames.Archive, hostname)
+}
+
+// GetStatsTemplate returns the template for the statistics of the repository.
+func (provider *Provider) GetStatsTemplate(hostname string) (*template.Template, error) {
+	return provider.getWrappedTemplate(templatenames.Stats, hostname)
+}
+
 // GetRSSTemplate returns the template for RSS feeds.
 func (provider *Provider) GetRSSTemplate(hostname string) (*template.Template, error) {
 	return provider.GetSimpleTemplate(templatenames.RSSFeed, hostname)
 }
 
+// GetAtomTemplate returns the template for Atom feeds.
+func (provider *Provider) GetAtomTemplate(hostname string) (*template.Template, error) {
+	return provider.GetSimpleTemplate(templatenames.AtomFeed, hostname)
+}
+
+// GetOPMLTemplate returns the template for OPML documents.
+func (provider *Provider) GetOPMLTemplate(hostname string) (*template.Template, error) {
+	return provider.GetSimpleTemplate(templatenames.OPML, hostname)
+}
+
 // GetXMLSitemapTemplate returns the template for XML sitemaps.
 func (provider *Provider) GetXMLSitemapTemplate(hostname string) (*template.Template, error) {
 	return provider.GetSimpleTemplate(templatenames.XMLSitemap, hostname)
 }
 
+// GetXMLSitemapIndexTemplate returns the template for XML sitemap indizes.
+func (provider *Provider) GetXMLSitemapIndexTemplate(hostname string) (*template.Template, error) {
+	return provider.GetSimpleTemplate(templatenames.XMLSitemapIndex, hostname)
+}
+
 // GetRobotsTxtTemplate returns the template for robots.txt.
 func (provider *Provider) GetRobotsTxtTemplate(hostname string) (*template.Template, error) {
 	return provider.GetSimpleTemplate(templatenames.RobotsTxt, hostname)
 }
 
+// GetServiceWorkerTemplate returns the template for the service worker.
+func (provider *Provider) GetServiceWorkerTemplate(hostname string) (*template.Template, error) {
+	return provider.GetSimpleTemplate(templatenames.ServiceWorker, hostname)
+}
+
 // GetConversionTemplate returns the template for conversion.
 func (provider *Provider) GetConversionTemplate(hostname string) (*template.Template, error) {
 	return provider.GetSimpleTemplate(templatenames.Conversion, hostname)
@@ -145,7 +237,7 @@ func (provider *Provider) GetSnippetTemplate(snippetName, hostname string) (*tem
 	return tmpl, nil
 }
 
-// StoreTemplatesOnDisc saves all templates to disc.
+// StoreTemplatesOnDisc saves all templates to the first template folder.
 func (provider *Provider) StoreTemplatesOnDisc() (success bool, err error) {
 
 	// store templates definitions on disk
@@ -190,8 +282,12 @@ func (provider *Provider) getWrappedTemplate(subTemplate, hostname string) (*tem
 
 // createTemplate creates a template from the lateName, templateCode, hostname string) (*template.Template, error) {
 func (provider *Provider) createTemplate(templateName, templateCode, hostname string) (*template.Template, error) {
+	if provider.themeFingerprints != nil {
+		templateCode = themeFileReferencePattern.ReplaceAllString(templateCode, `{{ basepath }}{{ theme "$1" }}`)
+	}
+
 	tmpl := template.Template{}
-	tmpl.New(templateName).Funcs(getTemplateHelpers(hostname))
+	tmpl.New(templateName).Funcs(getTemplateHelpers(hostname, provider.basePath, provider.labels, provider.snippets, provider.branding, provider.themeFingerprints))
 
 	// parse the template text
 	_, err := tmpl.Parse(templateCode)
@@ -209,17 +305,29 @@ func (provider *Provider) getTemplateText(templateName string) (string, error) {
 		return template.Text(), nil
 	}
 
+	// templates without a default (e.g. for items that select a template of their own) must exist on disc
+	if customTemplateNamePattern.MatchString(templateName) {
+		if template := newTemplateDefinition(provider.folders, templateName, ""); template.IsStoredOnDisc() {
+			return template.Text(), nil
+		}
+	}
+
 	return "", fmt.Errorf("The template with the name %q was not found.", templateName)
 }
 
 // getTemplateHelpers returns a map of utility functions that can be used in the templates.
-func getTemplateHelpers(hostname string) map[string]interface{} {
+func getTemplateHelpers(hostname, basePath string, labels labelCatalog, snippets config.Snippets, branding BrandingProvider, themeFingerprints *themes.Fingerprints) map[string]interface{} {
 
 	// Get the current hostname
 	getHostname := func() string {
 		return hostname
 	}
 
+	// Get the path prefix of the repository
+	getBasePath := func() string {
+		return basePath
+	}
+
 	// get the absolute url for a given (relative) uri
 	getAbsoluteURL := func(uri string) string {
 
@@ -231,18 +339,52 @@ func getTemplateHelpers(hostname string) map[string]interface{} {
 		uri = strings.TrimSpace(uri)
 
 		// add prefix
-		if !strings.HasPrefix(uri, "/") {
-			uri = "/" + uri
+		if !strings.HasPrefix(uri, basePath) {
+			uri = basePath + strings.TrimPrefix(uri, "/")
 		}
 
 		return getHostname() + uri
 	}
 
-	return map[string]interface{}{
-		"hostname": getHostname,
-		"absolute": getAbsoluteURL,
-		"replace":  replace,
+	// get the logo and the icon links of the site
+	getBranding := func() viewmodel.Branding {
+		if branding == nil {
+			return viewmodel.Branding{}
+		}
+
+		return branding()
+	}
+
+	// get the (fingerprinted) path of a theme file (e.g. "theme/screen.0a1b2c3d4e.css" for "screen.css")
+	getThemeFilePath := func(path string) string {
+		if themeFingerprints == nil {
+			return "theme/" + path
+		}
+
+		return "theme/" + themeFingerprints.Path(path)
 	}
+
+	helpers := map[string]interface{}{
+		"hostname":   getHostname,
+		"basepath":   getBasePath,
+		"absolute":   getAbsoluteURL,
+		"replace":    replace,
+		"formatdate": formatDate,
+		"first":      first,
+		"hastag":     hasTag,
+		"withtag":    withTag,
+		"meta":       meta,
+		"label":      func(key, language string) string { return labels.Label(language, key) },
+		"snippet":    snippets.Snippet,
+		"branding":   getBranding,
+		"theme":      getThemeFilePath,
+		"structureddata": func(model interface{}) (string, error) {
+			return structuredData(model, getAbsoluteURL, getBranding)
+		},
+	}
+
+	addRegisteredFunctions(helpers)
+	return helpers
 }
 
 // Replace all occurances of `textToReplace` in `text` with `replacement`.
@@ -0,0 +1,171 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/andreaskoch/allmark/web/view/viewmodel"
+)
+
+const schemaContext = "https://schema.org"
+
+// articleTypes contains the item types which are described as articles.
+var articleTypes = map[string]bool{
+	"document":     true,
+	"presentation": true,
+}
+
+// structuredData returns the schema.org JSON-LD description (WebSite with a SearchAction, BreadcrumbList and
+// Article) of the page with the given view model. The given functions return the absolute URL of a (relative)
+// path and the branding of the site.
+func structuredData(source interface{}, getAbsoluteURL func(uri string) string, getBranding func() viewmodel.Branding) (string, error) {
+	model, err := getModel(source)
+	if err != nil {
+		return "", err
+	}
+
+	graph := []interface{}{
+		getWebSite(model, getAbsoluteURL),
+	}
+
+	if breadcrumbs := getBreadcrumbList(model, getAbsoluteURL); breadcrumbs != nil {
+		graph = append(graph, breadcrumbs)
+	}
+
+	if articleTypes[model.Type] && model.Level > 0 {
+		graph = append(graph, getArticle(model, getAbsoluteURL, getBranding()))
+	}
+
+	// the default HTML escaping of the encoder keeps "</script>" out of the output
+	data, err := json.Marshal(map[string]interface{}{
+		"@context": schemaContext,
+		"@graph":   graph,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// getModel returns the given view model or the view model which is embedded in the given page model (e.g. viewmodel.Search).
+func getModel(source interface{}) (viewmodel.Model, error) {
+	if model, isModel := source.(viewmodel.Model); isModel {
+		return model, nil
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(source))
+	if value.Kind() == reflect.Struct {
+		if field := value.FieldByName("Model"); field.IsValid() {
+			if model, isModel := field.Interface().(viewmodel.Model); isModel {
+				return model, nil
+			}
+		}
+	}
+
+	return viewmodel.Model{}, fmt.Errorf("Cannot create the structured data of a %T.", source)
+}
+
+func getWebSite(model viewmodel.Model, getAbsoluteURL func(uri string) string) map[string]interface{} {
+	website := map[string]interface{}{
+		"@type": "WebSite",
+		"@id":   getAbsoluteURL("/") + "#website",
+		"url":   getAbsoluteURL("/"),
+		"name":  model.RepositoryName,
+		"potentialAction": map[string]interface{}{
+			"@type":       "SearchAction",
+			"target":      getAbsoluteURL("search?q={search_term_string}"),
+			"query-input": "required name=search_term_string",
+		},
+	}
+
+	addValue(website, "description", model.RepositoryDescription)
+	return website
+}
+
+// getBreadcrumbList returns the BreadcrumbList of the given model or nil if the model has no breadcrumbs.
+func getBreadcrumbList(model viewmodel.Model, getAbsoluteURL func(uri string) string) map[string]interface{} {
+	if !model.BreadcrumbNavigation.IsAvailable() {
+		return nil
+	}
+
+	elements := make([]interface{}, 0, len(model.BreadcrumbNavigation.Entries))
+	for index, breadcrumb := range model.BreadcrumbNavigation.Entries {
+		elements = append(elements, map[string]interface{}{
+			"@type":    "ListItem",
+			"position": index + 1,
+			"name":     breadcrumb.Title,
+			"item":     getAbsoluteURL(breadcrumb.Path),
+		})
+	}
+
+	return map[string]interface{}{
+		"@type":           "BreadcrumbList",
+		"itemListElement": elements,
+	}
+}
+
+func getArticle(model viewmodel.Model, getAbsoluteURL func(uri string) string, branding viewmodel.Branding) map[string]interface{} {
+	url := getAbsoluteURL(model.Route)
+	article := map[string]interface{}{
+		"@type":            "Article",
+		"@id":              url + "#article",
+		"url":              url,
+		"mainEntityOfPage": url,
+		"headline":         model.Title,
+		"isPartOf":         map[string]string{"@id": getAbsoluteURL("/") + "#website"},
+	}
+
+	addValue(article, "description", model.Description)
+	addValue(article, "inLanguage", model.LanguageTag)
+	addValue(article, "datePublished", model.CreationDate)
+	addValue(article, "dateModified", model.LastModifiedDate)
+	addValue(article, "keywords", strings.Join(model.TagNames, ", "))
+
+	if model.PreviewImage != "" {
+		article["image"] = getAbsoluteURL(model.PreviewImage)
+	}
+
+	if model.Author.Name != "" {
+		author := map[string]interface{}{
+			"@type": "Person",
+			"name":  model.Author.Name,
+		}
+
+		addValue(author, "url", model.Author.URL)
+		article["author"] = author
+	}
+
+	if model.Publisher.Name != "" {
+		publisher := map[string]interface{}{
+			"@type": "Organization",
+			"name":  model.Publisher.Name,
+		}
+
+		addValue(publisher, "url", model.Publisher.URL)
+		if branding.Logo != "" {
+			publisher["logo"] = map[string]interface{}{
+				"@type": "ImageObject",
+				"url":   getAbsoluteURL(branding.Logo),
+			}
+		}
+
+		article["publisher"] = publisher
+	}
+
+	return article
+}
+
+// addValue adds the given value to the given object if it is not empty.
+func addValue(object map[string]interface{}, key, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		object[key] = value
+	}
+}
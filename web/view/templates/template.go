@@ -17,27 +17,30 @@ const (
 )
 
 // newTemplateDefinition creates a new template definition with the given parameters.
-func newTemplateDefinition(templateFolder, name, text string) *templateDefinition {
+func newTemplateDefinition(templateFolders []string, name, text string) *templateDefinition {
 
-	// assemble the file path
+	// assemble the file paths
 	templateFilename := name + TemplateFileExtension
-	templateFilePath := filepath.Join(templateFolder, templateFilename)
+	templateFilePaths := make([]string, 0, len(templateFolders))
+	for _, templateFolder := range templateFolders {
+		templateFilePaths = append(templateFilePaths, filepath.Join(templateFolder, templateFilename))
+	}
 
 	// create a new template definition
 	templateDefinition := &templateDefinition{
-		name: name,
-		text: text,
-		path: templateFilePath,
+		name:  name,
+		text:  text,
+		paths: templateFilePaths,
 	}
 
 	return templateDefinition
 }
 
-// A templateDefinition contains template code, its name and the path on disc.
+// A templateDefinition contains template code, its name and the paths on disc (in the order of their precedence).
 type templateDefinition struct {
-	name string
-	text string
-	path string
+	name  string
+	text  string
+	paths []string
 }
 
 // Name returns the template name
@@ -45,33 +48,60 @@ func (template *templateDefinition) Name() string {
 	return template.name
 }
 
-// Text returns the template code. If the template was found on disc it will return the code from disc.
-// Otherwise it will return the default template code.
+// Text returns the template code. If the template was found on disc it will return the code from the first
+// path that exists. Otherwise it will return the default template code.
 func (template *templateDefinition) Text() string {
 
-	if !fsutil.FileExists(template.path) {
-		return template.text
+	for _, path := range template.paths {
+		if !fsutil.FileExists(path) {
+			continue
+		}
+
+		return template.readFile(path)
+	}
+
+	return template.text
+}
+
+// IsStoredOnDisc returns true if the template exists in one of its paths on disc.
+func (template *templateDefinition) IsStoredOnDisc() bool {
+	for _, path := range template.paths {
+		if fsutil.FileExists(path) {
+			return true
+		}
 	}
 
-	file, err := os.Open(template.path)
+	return false
+}
+
+// readFile returns the template code from the file with the given path or the default template code if the file cannot be read.
+func (template *templateDefinition) readFile(path string) string {
+
+	file, err := os.Open(path)
 	if err != nil {
-		fmt.Printf("Could not open the template file %q.", template.path)
+		fmt.Printf("Could not open the template file %q.", path)
 		return template.text
 	}
 
+	defer file.Close()
+
 	bytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		fmt.Printf("Could not read the template file %q.", template.path)
+		fmt.Printf("Could not read the template file %q.", path)
 		return template.text
 	}
 
 	return string(bytes)
 }
 
-// StoreOnDisc stores the current template definition to it's target path on disc.
+// StoreOnDisc stores the current template definition to it's first target path on disc.
 func (template *templateDefinition) StoreOnDisc() (success bool, err error) {
 
-	path := template.path
+	if len(template.paths) == 0 {
+		return false, fmt.Errorf("No template folder for the template %q.", template.name)
+	}
+
+	path := template.paths[0]
 
 	// make sure the directory exists
 	if success, _ := fsutil.CreateFile(path); !success {
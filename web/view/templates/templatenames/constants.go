@@ -17,13 +17,20 @@ const (
 	Sitemap      = "sitemap"
 	SitemapEntry = "sitemap-entry"
 
-	XMLSitemap = "xmlsitemap"
-	RSSFeed    = "rssfeed"
-	TagMap     = "tagmap"
-	AliasIndex = "aliasindex"
-	Search     = "search"
-	Conversion = "converter"
-	RobotsTxt  = "robotstxt"
+	XMLSitemap      = "xmlsitemap"
+	XMLSitemapIndex = "xmlsitemapindex"
+	RSSFeed         = "rssfeed"
+	AtomFeed        = "atomfeed"
+	OPML            = "opml"
+	TagMap          = "tagmap"
+	RecentChanges   = "recentchanges"
+	Stats           = "stats"
+	Archive         = "archive"
+	AliasIndex      = "aliasindex"
+	Search          = "search"
+	Conversion      = "converter"
+	RobotsTxt       = "robotstxt"
+	ServiceWorker   = "serviceworker"
 
 	Aliases              = "aliases-snippet"
 	Tags                 = "tags-snippet"
@@ -31,6 +38,9 @@ const (
 	ToplevelNavigation   = "toplevelnavigation-snippet"
 	BreadcrumbNavigation = "breadcrumbnavigation-snippet"
 	ItemNavigation       = "itemnavigation-snippet"
-	Children               = "children-snippet"
+	Children             = "children-snippet"
+	Backlinks            = "backlinks-snippet"
+	Related              = "related-snippet"
 	TagCloud             = "tagcloud-snippet"
+	TagMapTag            = "tagmap-tag-snippet"
 )
@@ -0,0 +1,166 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package themes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fingerprintLength is the number of characters of the content hash which are added to the paths of the theme files.
+const fingerprintLength = 10
+
+// fingerprintedPathPattern matches fingerprinted paths of theme files (e.g. "codehighlighting/highlight.0a1b2c3d4e.css").
+var fingerprintedPathPattern = regexp.MustCompile(`^(.+)\.([0-9a-f]{10})(\.[^./]+)$`)
+
+// fingerprintedExtensions contains the extensions of the theme files which are referenced by fingerprinted paths.
+var fingerprintedExtensions = map[string]bool{
+	".css": true,
+	".js":  true,
+}
+
+// Fingerprints adds the content hashes of the theme files to their paths, so the files can be cached forever
+// and clients get the new version as soon as a file changes. The files of the given theme folders take
+// precedence over the files of the built-in theme.
+type Fingerprints struct {
+	folders []string
+	theme   *Theme
+
+	lock   sync.Mutex
+	hashes map[string]fileHash
+}
+
+// fileHash is the content hash of a theme file on disc and the state of the file the hash was calculated for.
+type fileHash struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// NewFingerprints creates the fingerprints of the files of the given theme folders and the built-in theme.
+func NewFingerprints(folders []string) *Fingerprints {
+	return &Fingerprints{
+		folders: folders,
+		theme:   GetTheme(),
+		hashes:  make(map[string]fileHash),
+	}
+}
+
+// Path returns the fingerprinted path of the theme file with the given path (e.g. "screen.0a1b2c3d4e.css" for
+// "screen.css"). The paths of unknown files and of files which are not style sheets or scripts are returned unchanged.
+func (fingerprints *Fingerprints) Path(path string) string {
+	extension := filepath.Ext(path)
+	if !fingerprintedExtensions[strings.ToLower(extension)] {
+		return path
+	}
+
+	hash := fingerprints.hash(path)
+	if hash == "" {
+		return path
+	}
+
+	return strings.TrimSuffix(path, extension) + "." + hash + extension
+}
+
+// Resolve returns the path of the theme file which is referenced by the given fingerprinted path and true if the
+// fingerprint matches the current content of the file. Paths without a fingerprint are returned unchanged.
+func (fingerprints *Fingerprints) Resolve(path string) (string, bool) {
+	matches := fingerprintedPathPattern.FindStringSubmatch(path)
+	if matches == nil || !fingerprintedExtensions[strings.ToLower(matches[3])] {
+		return path, false
+	}
+
+	unfingerprintedPath := matches[1] + matches[3]
+	hash := fingerprints.hash(unfingerprintedPath)
+	if hash == "" {
+		return path, false
+	}
+
+	return unfingerprintedPath, hash == matches[2]
+}
+
+// Version returns a hash of the content hashes of the style sheets and scripts of the theme
+// which changes whenever one of these files changes.
+func (fingerprints *Fingerprints) Version() string {
+	hashes := make([]string, 0, len(fingerprints.theme.Files))
+	for _, themeFile := range fingerprints.theme.Files {
+		if !fingerprintedExtensions[strings.ToLower(filepath.Ext(themeFile.Path()))] {
+			continue
+		}
+
+		hashes = append(hashes, themeFile.Path()+"="+fingerprints.hash(themeFile.Path()))
+	}
+
+	return getContentHash([]byte(strings.Join(hashes, "\n")))
+}
+
+// hash returns the shortened content hash of the theme file with the given path or an empty string if the file does not exist.
+func (fingerprints *Fingerprints) hash(path string) string {
+	if path = strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+path)), "/"); path == "" {
+		return ""
+	}
+
+	for _, folder := range fingerprints.folders {
+		filePath := filepath.Join(folder, filepath.FromSlash(path))
+		fileInfo, err := os.Stat(filePath)
+		if err != nil || !fileInfo.Mode().IsRegular() {
+			continue
+		}
+
+		return fingerprints.fileHash(filePath, fileInfo)
+	}
+
+	if themeFile := fingerprints.theme.Get(path); themeFile != nil {
+		return fingerprints.builtInFileHash(themeFile)
+	}
+
+	return ""
+}
+
+// fileHash returns the content hash of the given file on disc; it is only calculated again if the file changed.
+func (fingerprints *Fingerprints) fileHash(filePath string, fileInfo os.FileInfo) string {
+	fingerprints.lock.Lock()
+	defer fingerprints.lock.Unlock()
+
+	if cached, exists := fingerprints.hashes[filePath]; exists && cached.size == fileInfo.Size() && cached.modTime.Equal(fileInfo.ModTime()) {
+		return cached.hash
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return ""
+	}
+
+	hash := getContentHash(data)
+	fingerprints.hashes[filePath] = fileHash{fileInfo.ModTime(), fileInfo.Size(), hash}
+	return hash
+}
+
+// builtInFileHash returns the content hash of the given file of the built-in theme.
+func (fingerprints *Fingerprints) builtInFileHash(themeFile *ThemeFile) string {
+	fingerprints.lock.Lock()
+	defer fingerprints.lock.Unlock()
+
+	key := "builtin:" + themeFile.Path()
+	if cached, exists := fingerprints.hashes[key]; exists {
+		return cached.hash
+	}
+
+	hash := getContentHash(themeFile.Data())
+	fingerprints.hashes[key] = fileHash{hash: hash}
+	return hash
+}
+
+// getContentHash returns the first characters of the SHA-256 hash of the given data.
+func getContentHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])[:fingerprintLength]
+}
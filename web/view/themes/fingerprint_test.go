@@ -0,0 +1,87 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package themes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Path_BuiltInStyleSheet_PathContainsTheContentHash(t *testing.T) {
+	// arrange
+	fingerprints := NewFingerprints(nil)
+	expectedPath := "codehighlighting/highlight." + getContentHash(GetTheme().Get("codehighlighting/highlight.css").Data()) + ".css"
+
+	// act
+	result := fingerprints.Path("codehighlighting/highlight.css")
+
+	// assert
+	if result != expectedPath {
+		t.Errorf("The fingerprinted path should be %q but was %q.", expectedPath, result)
+	}
+}
+
+func Test_Path_UnknownFileOrImage_PathIsNotChanged(t *testing.T) {
+	// arrange
+	fingerprints := NewFingerprints(nil)
+
+	// act
+	unknownFile := fingerprints.Path("unknown.css")
+	image := fingerprints.Path("favicon.ico")
+
+	// assert
+	if unknownFile != "unknown.css" || image != "favicon.ico" {
+		t.Errorf("The paths should not be changed but were %q and %q.", unknownFile, image)
+	}
+}
+
+func Test_Path_FileInThemeFolder_FingerprintChangesWithTheContent(t *testing.T) {
+	// arrange
+	folder := t.TempDir()
+	filePath := filepath.Join(folder, "screen.css")
+	os.WriteFile(filePath, []byte("body { color: black; }"), 0600)
+
+	fingerprints := NewFingerprints([]string{folder})
+	builtInPath := NewFingerprints(nil).Path("screen.css")
+
+	// act
+	firstPath := fingerprints.Path("screen.css")
+	os.WriteFile(filePath, []byte("body { color: white; background: black; }"), 0600)
+	secondPath := fingerprints.Path("screen.css")
+
+	// assert
+	if firstPath == builtInPath {
+		t.Errorf("The file of the theme folder should be used instead of the built-in file.")
+	}
+
+	if firstPath == secondPath {
+		t.Errorf("The fingerprint should change if the content of the file changes but was %q both times.", firstPath)
+	}
+}
+
+func Test_Resolve_FingerprintedPath_PathOfTheFileIsReturned(t *testing.T) {
+	// arrange
+	fingerprints := NewFingerprints(nil)
+	currentPath := fingerprints.Path("screen.css")
+
+	// act
+	currentFile, isCurrent := fingerprints.Resolve(currentPath)
+	outdatedFile, isOutdatedCurrent := fingerprints.Resolve("screen.0123456789.css")
+	plainFile, isPlainCurrent := fingerprints.Resolve("screen.css")
+
+	// assert
+	if currentFile != "screen.css" || !isCurrent {
+		t.Errorf("The current fingerprint should resolve to %q but resolved to %q (current: %t).", "screen.css", currentFile, isCurrent)
+	}
+
+	if outdatedFile != "screen.css" || isOutdatedCurrent {
+		t.Errorf("An outdated fingerprint should resolve to %q but resolved to %q (current: %t).", "screen.css", outdatedFile, isOutdatedCurrent)
+	}
+
+	if plainFile != "screen.css" || isPlainCurrent {
+		t.Errorf("A path without a fingerprint should not be changed but resolved to %q (current: %t).", plainFile, isPlainCurrent)
+	}
+}
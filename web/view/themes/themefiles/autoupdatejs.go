@@ -14,13 +14,7 @@ const AutoupdateJs = `var Autoupdate = (function () {
      * @return string The currently opened web route (e.g. "documents/Sample-Document")
      */
     var getCurrentRoute = function() {
-        var url = document.location.pathname;
-
-        // remove leading slash
-        var leadingSlash = /^\//;
-        url = url.replace(leadingSlash, "");
-
-        return url;
+        return getRouteFromPath(document.location.pathname);
     };
 
     /**
@@ -36,10 +30,23 @@ const AutoupdateJs = `var Autoupdate = (function () {
         }
 
         if (routeParameter === "") {
-            return protocol + "://" + host + "/" + "ws";
+            return protocol + "://" + host + getBasePath() + "ws";
+        }
+
+        return protocol + "://" + host + getBasePath() + routeParameter + ".ws";
+    };
+
+    /**
+     * Get the URL for the server-sent events
+     * @return string The url for the event stream (e.g. "/documents/Sample-Document.events")
+     */
+    var getEventStreamURL = function() {
+        var routeParameter = getCurrentRoute();
+        if (routeParameter === "") {
+            return getBasePath() + "events";
         }
 
-        return protocol + "://" + host + "/" + routeParameter + ".ws";
+        return getBasePath() + routeParameter + ".events";
     };
 
     /**
@@ -101,15 +108,32 @@ const AutoupdateJs = `var Autoupdate = (function () {
       }
     };
 
+    // the number of failed web socket connection attempts before using server-sent events
+    var failedConnectionAttempts = 0;
+    var maxFailedConnectionAttempts = 3;
+
     /**
-     * Connect to the server
+     * Connect to the server via a web socket. Falls back to server-sent events
+     * if the web socket connection cannot be established (e.g. because a proxy
+     * does not support connection upgrades).
      * @param string webSocketURL The url of the web-socket to connect to
      */
     var connect = function(webSocketURL) {
         var reconnectionTimeInSeconds = 3;
         var connection = new WebSocket(webSocketURL);
+        var established = false;
 
         connection.onclose = function(evt) {
+            if (!established) {
+                failedConnectionAttempts++;
+            }
+
+            if (failedConnectionAttempts >= maxFailedConnectionAttempts && window["EventSource"]) {
+                console.log("Unable to establish a web socket connection. Using server-sent events instead.");
+                connectEventStream(getEventStreamURL());
+                return;
+            }
+
             console.log("Connection closed. Trying to reconnect in " + reconnectionTimeInSeconds + " seconds.");
 
             setTimeout(function() {
@@ -121,76 +145,117 @@ const AutoupdateJs = `var Autoupdate = (function () {
         };
 
         connection.onopen = function() {
+            established = true;
+            failedConnectionAttempts = 0;
             console.log("Connection established.")
         };
 
-        connection.onmessage = function(evt) {
+        connection.onmessage = handleMessage;
+    };
 
-            // validate event data
-            if (typeof(evt) !== 'object' || typeof(evt.data) !== 'string') {
-                console.log("Invalid data from server.");
-                return;
-            }
+    /**
+     * Subscribe to the server-sent events (the browser reconnects automatically)
+     * @param string eventStreamURL The url of the event stream
+     */
+    var connectEventStream = function(eventStreamURL) {
+        var eventSource = new EventSource(eventStreamURL);
 
-            // unwrap the message
-            message = JSON.parse(evt.data);
+        eventSource.onopen = function() {
+            console.log("Event stream established.")
+        };
 
-            // check if all required fields are present
-            if (message === null || typeof(message) !== 'object' || typeof(message.route) !== 'string' || message.model === null || typeof(message.model) !== 'object') {
-                console.log("Invalid response format.", message);
-                return;
-            }
+        eventSource.addEventListener("update", handleMessage);
+        eventSource.addEventListener("reload", handleMessage);
+    };
 
-            // check the model structure
-            var model = message.model;
-            if (typeof(model.content) !== 'string' || typeof(model.description) !== 'string' || typeof(model.title) !== 'string') {
-                console.log("Cannot update the view with the given model object. Missing some required fields.", model);
-                return;
-            }
+    /**
+     * Handle a message from the server
+     * @param object evt The message event
+     */
+    var handleMessage = function(evt) {
 
-            // update the title
-            $('title').html(model.title);
-            $('.title').html(model.title);
+        // validate event data
+        if (typeof(evt) !== 'object' || typeof(evt.data) !== 'string') {
+            console.log("Invalid data from server.");
+            return;
+        }
 
-            // update the description
-            $('.description').html(model.description);
+        // unwrap the message
+        message = JSON.parse(evt.data);
 
-            // update the content
-            $('.content').html(model.content);
+        // check the message structure
+        if (message === null || typeof(message) !== 'object' || typeof(message.route) !== 'string') {
+            console.log("Invalid response format.", message);
+            return;
+        }
 
-            // on-change handlers
-            executeOnChangeCallbacks();
+        // the page can no longer be updated in place (e.g. because the item has been removed)
+        if (message.name === "reload") {
+            console.log("Reloading the page.");
+            location.reload();
+            return;
+        }
 
-            // snippets
-            if (typeof(model.snippets) !== 'object') {
-              return;
-            }
+        // check if all required fields are present
+        if (message.model === null || typeof(message.model) !== 'object') {
+            console.log("Invalid response format.", message);
+            return;
+        }
+
+        // check the model structure
+        var model = message.model;
+        if (typeof(model.content) !== 'string' || typeof(model.description) !== 'string' || typeof(model.title) !== 'string') {
+            console.log("Cannot update the view with the given model object. Missing some required fields.", model);
+            return;
+        }
 
-            for (var snippetName in model.snippets) {
+        // update the title
+        $('title').html(model.title);
+        $('.title').html(model.title);
 
-              // the new snippet content
-              var snippetContent = cleanupSnippetCode(model.snippets[snippetName]);
+        // update the description
+        $('.description').html(model.description);
 
-              // get the CSS selector of the snippet
-              var cssSelector = getCSSSelectorFromSnippetName(snippetName);
+        // update the content
+        $('.content').html(model.content);
 
-              // check if the snippet exists
-              var elementExists = $(cssSelector).length > 0;
-              if (elementExists == false) {
-                continue;
-              }
+        // on-change handlers
+        executeOnChangeCallbacks();
 
-              // replace the existing snippet
-              $(cssSelector).replaceWith(snippetContent);
-            }
+        // snippets
+        if (typeof(model.snippets) !== 'object') {
+          return;
+        }
+
+        for (var snippetName in model.snippets) {
+
+          // the new snippet content
+          var snippetContent = cleanupSnippetCode(model.snippets[snippetName]);
+
+          // get the CSS selector of the snippet
+          var cssSelector = getCSSSelectorFromSnippetName(snippetName);
+
+          // check if the snippet exists
+          var elementExists = $(cssSelector).length > 0;
+          if (elementExists == false) {
+            continue;
+          }
+
+          // replace the existing snippet
+          $(cssSelector).replaceWith(snippetContent);
+        }
 
-        };
     };
 
     Autoupdate.prototype.start = function () {
 
         // check if websockets are supported
         if(!window["WebSocket"]) {
+            if(window["EventSource"]) {
+                connectEventStream(getEventStreamURL());
+                return;
+            }
+
             console.log("Your browser does not support WebSockets.");
             return;
         }
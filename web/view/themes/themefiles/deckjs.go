@@ -1364,13 +1364,13 @@ body.deck-container {
   -ms-interpolation-mode: bicubic;
 }
 .deck-container, .deck-container select, .deck-container input, .deck-container textarea {
-  color: #444;
+  color: var(--text-color, #444);
 }
 .deck-container a {
-  color: #607890;
+  color: var(--presentation-link-color, #607890);
 }
 .deck-container a:hover, .deck-container a:focus {
-  color: #036;
+  color: var(--presentation-link-hover-color, #036);
 }
 .deck-container a:link {
   -webkit-tap-highlight-color: #fff;
@@ -1504,7 +1504,7 @@ body.deck-container {
   line-height: 1.75em;
   padding: 0.625em;
   display: none;
-  background: #ccc;
+  background: var(--presentation-control-background-color, #ccc);
   overflow: hidden;
 }
 .borderradius .deck-container .goto-form {
@@ -1545,7 +1545,7 @@ body.deck-container {
 }
 
 .deck-menu .slide {
-  background: #eee;
+  background: var(--presentation-slide-background-color, #eee);
   position: relative;
   left: 0;
   top: 0;
@@ -1567,7 +1567,7 @@ body.deck-container {
   max-width: 100%;
 }
 .deck-menu .deck-current, .no-touch .deck-menu .slide:hover {
-  background: #ddf;
+  background: var(--presentation-current-slide-background-color, #ddf);
 }
 .deck-menu.deck-container:hover .deck-prev-link, .deck-menu.deck-container:hover .deck-next-link {
   display: none;
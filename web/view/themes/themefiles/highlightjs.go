@@ -851,7 +851,7 @@ const HighlightCss = `/* Original style from softwaremaniacs.org (c) Ivan Sagala
   display: block;
   overflow-x: auto;
   padding: 0.5em;
-  background: #f0f0f0;
+  background: var(--highlight-background-color, #f0f0f0);
   -webkit-text-size-adjust: none;
 }
 
@@ -859,7 +859,7 @@ const HighlightCss = `/* Original style from softwaremaniacs.org (c) Ivan Sagala
 .hljs-subst,
 .hljs-tag .hljs-title,
 .nginx .hljs-title {
-  color: black;
+  color: var(--highlight-color, black);
 }
 
 .hljs-string,
@@ -888,7 +888,7 @@ const HighlightCss = `/* Original style from softwaremaniacs.org (c) Ivan Sagala
 .asciidoc .hljs-header,
 .markdown .hljs-header,
 .coffeescript .hljs-attribute {
-  color: #800;
+  color: var(--highlight-string-color, #800);
 }
 
 .smartquote,
@@ -899,7 +899,7 @@ const HighlightCss = `/* Original style from softwaremaniacs.org (c) Ivan Sagala
 .hljs-chunk,
 .asciidoc .hljs-blockquote,
 .markdown .hljs-blockquote {
-  color: #888;
+  color: var(--highlight-comment-color, #888);
 }
 
 .hljs-number,
@@ -917,7 +917,7 @@ const HighlightCss = `/* Original style from softwaremaniacs.org (c) Ivan Sagala
 .markdown .hljs-bullet,
 .asciidoc .hljs-link_url,
 .markdown .hljs-link_url {
-  color: #080;
+  color: var(--highlight-literal-color, #080);
 }
 
 .hljs-label,
@@ -949,7 +949,7 @@ const HighlightCss = `/* Original style from softwaremaniacs.org (c) Ivan Sagala
 .lasso .hljs-attribute,
 .coffeescript .hljs-property,
 .hljs-phony {
-  color: #88f;
+  color: var(--highlight-special-color, #88f);
 }
 
 .hljs-keyword,
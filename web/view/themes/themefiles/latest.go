@@ -12,17 +12,13 @@ $(function() {
 	 * @return string The currently opened web route (e.g. "documents/Sample-Document")
 	 */
 	var getURL = function() {
-	    var url = document.location.pathname;
-
-	    // remove leading slash
-	    var leadingSlash = /^\//;
-	    url = url.replace(leadingSlash, "");
+	    var url = getRouteFromPath(document.location.pathname);
 
 	    if (url === "") {
-	    	return "/latest"
+	    	return getBasePath() + "latest"
 	    }
 
-	    return "/" + url + ".latest";
+	    return getBasePath() + url + ".latest";
 	};
 
 	var markup = '<li><h1><a href="${route}">${title}</a></h1><p><a href="${route}">${description}</a></p><section>{{html content}}</section></li>';
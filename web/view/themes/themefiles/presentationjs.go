@@ -103,8 +103,8 @@ $(function() {
   });
 
     // load deck.js
-    appendStyleSheet("/theme/deck.css");
-    $.getScript("/theme/deck.js", function(){
+    appendStyleSheet(getBasePath() + "theme/deck.css");
+    $.getScript(getBasePath() + "theme/deck.js", function(){
 
     // render the presentaton
     renderPresentation();
@@ -123,4 +123,41 @@ body>footer {
 
 .presentation nav {
     display: none;
+}
+
+.toc ol {
+    list-style-type: none;
+    padding: 0;
+}
+
+.toc .level-2 { padding-left: 1.5em; }
+.toc .level-3 { padding-left: 3em; }
+.toc .level-4 { padding-left: 4.5em; }
+
+.subtree-section {
+    page-break-before: always;
+}
+
+/* the print view is displayed in dark colors on screen if the operating system prefers dark colors,
+   unless the light scheme has been selected with the color scheme toggle; printouts are always light */
+@media screen and (prefers-color-scheme: dark) {
+    html:not([data-color-scheme="light"]) * {
+        background: #161b22 !important;
+        color: #c9d1d9 !important;
+    }
+
+    html:not([data-color-scheme="light"]) a {
+        color: #58a6ff !important;
+    }
+}
+
+@media screen {
+    html[data-color-scheme="dark"] * {
+        background: #161b22 !important;
+        color: #c9d1d9 !important;
+    }
+
+    html[data-color-scheme="dark"] a {
+        color: #58a6ff !important;
+    }
 }`
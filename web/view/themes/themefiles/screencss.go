@@ -5,6 +5,99 @@
 package themefiles
 
 const ScreenCss = `
+/* colors of the light scheme (default) */
+:root {
+    --text-color: #444;
+    --background-color: #fefefe;
+    --outline-color: #FAFAFA;
+    --heading-color: #111;
+    --muted-color: #666666;
+    --link-color: #0645ad;
+    --link-visited-color: #0b0080;
+    --link-hover-color: #06e;
+    --border-color: #eee;
+    --code-color: #000;
+    --code-background-color: #F8F8F8;
+    --code-border-color: #CCC;
+    --navigation-color: #000000;
+    --surface-color: #fff;
+    --stripe-color: #eee;
+    --tag-color: #FFF;
+    --tag-background-color: #000;
+    --slide-shadow-color: #000000;
+}
+
+/* colors of the dark scheme: used if the operating system prefers dark colors,
+   unless the light scheme has been selected with the color scheme toggle */
+@media (prefers-color-scheme: dark) {
+    :root:not([data-color-scheme="light"]) {
+        color-scheme: dark;
+        --text-color: #c9d1d9;
+        --background-color: #161b22;
+        --outline-color: #0d1117;
+        --heading-color: #e6edf3;
+        --muted-color: #8b949e;
+        --link-color: #58a6ff;
+        --link-visited-color: #a5a0ff;
+        --link-hover-color: #79c0ff;
+        --border-color: #30363d;
+        --code-color: #e6edf3;
+        --code-background-color: #1f2428;
+        --code-border-color: #30363d;
+        --navigation-color: #c9d1d9;
+        --surface-color: #21262d;
+        --stripe-color: #21262d;
+        --tag-color: #161b22;
+        --tag-background-color: #c9d1d9;
+        --slide-shadow-color: rgba(255,255,255,0.3);
+        --highlight-background-color: #1f2428;
+        --highlight-color: #e6edf3;
+        --highlight-string-color: #ff7b72;
+        --highlight-comment-color: #8b949e;
+        --highlight-literal-color: #7ee787;
+        --highlight-special-color: #a5d6ff;
+        --presentation-link-color: #58a6ff;
+        --presentation-link-hover-color: #79c0ff;
+        --presentation-control-background-color: #30363d;
+        --presentation-slide-background-color: #21262d;
+        --presentation-current-slide-background-color: #30363d;
+    }
+}
+
+/* colors of the dark scheme if it has been selected with the color scheme toggle */
+:root[data-color-scheme="dark"] {
+    color-scheme: dark;
+    --text-color: #c9d1d9;
+    --background-color: #161b22;
+    --outline-color: #0d1117;
+    --heading-color: #e6edf3;
+    --muted-color: #8b949e;
+    --link-color: #58a6ff;
+    --link-visited-color: #a5a0ff;
+    --link-hover-color: #79c0ff;
+    --border-color: #30363d;
+    --code-color: #e6edf3;
+    --code-background-color: #1f2428;
+    --code-border-color: #30363d;
+    --navigation-color: #c9d1d9;
+    --surface-color: #21262d;
+    --stripe-color: #21262d;
+    --tag-color: #161b22;
+    --tag-background-color: #c9d1d9;
+    --slide-shadow-color: rgba(255,255,255,0.3);
+    --highlight-background-color: #1f2428;
+    --highlight-color: #e6edf3;
+    --highlight-string-color: #ff7b72;
+    --highlight-comment-color: #8b949e;
+    --highlight-literal-color: #7ee787;
+    --highlight-special-color: #a5d6ff;
+    --presentation-link-color: #58a6ff;
+    --presentation-link-hover-color: #79c0ff;
+    --presentation-control-background-color: #30363d;
+    --presentation-slide-background-color: #21262d;
+    --presentation-current-slide-background-color: #30363d;
+}
+
 html {
     min-height: 100%;
     font-size: 100%;
@@ -14,16 +107,16 @@ html {
 }
 
 body {
-    color: #444;
+    color: var(--text-color);
     font-family: Georgia, Palatino, 'Palatino Linotype', Times, 'Times New Roman', "Hiragino Sans GB", "STXihei", "微软雅黑", serif;
     font-size: 12px;
     line-height: 1.5em;
-    background: #fefefe;
+    background: var(--background-color);
     width: 75%;
     min-height: 100%;
     margin: 0px auto 1em auto;
     padding: 0 1em;
-    outline: 1300px solid #FAFAFA;
+    outline: 1300px solid var(--outline-color);
 }
 
 .cleaner {
@@ -31,16 +124,16 @@ body {
 }
 
 a {
-    color: #0645ad;
+    color: var(--link-color);
     text-decoration: none;
 }
 
 a:visited {
-    color: #0b0080;
+    color: var(--link-visited-color);
 }
 
 a:hover {
-    color: #06e;
+    color: var(--link-hover-color);
 }
 
 a:active {
@@ -56,9 +149,9 @@ a:hover, a:active {
 }
 
 span.backtick {
-    border: 1px solid #EAEAEA;
+    border: 1px solid var(--code-border-color);
     border-radius: 3px;
-    background: #F8F8F8;
+    background: var(--code-background-color);
     padding: 0 3px 0 3px;
 }
 
@@ -74,12 +167,12 @@ span.backtick {
 
 a::-moz-selection {
     background: rgba(255,255,0,0.3);
-    color: #0645ad;
+    color: var(--link-color);
 }
 
 a::selection {
     background: rgba(255,255,0,0.3);
-    color: #0645ad;
+    color: var(--link-color);
 }
 
 p {
@@ -97,7 +190,7 @@ img[data-sizes="auto"] {
 h1,h2,h3,h4,h5,h6 {
     line-height: 1em;
     font-weight: normal;
-    color: #111;
+    color: var(--heading-color);
 }
 
 h4,h5,h6 {
@@ -132,10 +225,10 @@ h6 {
 }
 
 blockquote {
-    color: #666666;
+    color: var(--muted-color);
     margin: 0;
     padding-left: 3em;
-    border-left: 0.5em #EEE solid;
+    border-left: 0.5em var(--border-color) solid;
 }
 
 hr {
@@ -143,7 +236,7 @@ hr {
     height: 2px;
     border: 0;
     border-top: 1px solid #aaa;
-    border-bottom: 1px solid #eee;
+    border-bottom: 1px solid var(--border-color);
     margin: 1em 0;a.deeplink {
     float: left;
     line-height: 0;
@@ -154,12 +247,12 @@ hr {
 }
 
 pre , code, kbd, samp {
-    color: #000;
+    color: var(--code-color);
     font-family: monospace;
     font-size: 0.88em;
     border-radius: 3px;
-    background-color: #F8F8F8;
-    border: 1px solid #CCC;
+    background-color: var(--code-background-color);
+    border: 1px solid var(--code-border-color);
     line-height: 1.3em;
 }
 
@@ -242,7 +335,7 @@ ul.tree li {
 }
 
 ul.tree li:last-child {
-    background: #fff url(tree-last-node.png) no-repeat;
+    background: var(--background-color) url(tree-last-node.png) no-repeat;
 }
 
 dd {
@@ -296,13 +389,13 @@ body>aside.export>ul>li {
 }
 
 body>aside.export>ul>li>a {
-    color: #000000;
+    color: var(--navigation-color);
     font-size: 0.8em;
 }
 
 body>footer {
     margin: 0;
-    border-top: 1px solid #eee;
+    border-top: 1px solid var(--border-color);
 }
 
 body>footer>nav {
@@ -321,7 +414,7 @@ body>footer>nav>ul>li {
 }
 
 body>footer>nav>ul>li>a {
-    color: #000000;
+    color: var(--navigation-color);
     font-size: 0.8em;
 }
 
@@ -340,7 +433,7 @@ body>nav.search {
 body>nav.search .typeahead {
     width: 20em;
     font-family: "Helvetia", "Verdana", "Sans-Serif";
-    color: #000000;
+    color: var(--text-color);
     text-align: left;
     padding: 0px 10px;
 }
@@ -356,7 +449,7 @@ body>nav.search .typeahead {
 }
 
 .typeahead {
-  background-color: #fff;
+  background-color: var(--surface-color);
 }
 
 .typeahead:focus {
@@ -377,7 +470,7 @@ body>nav.search .typeahead {
   width: 422px;
   margin-top: 12px;
   padding: 8px 0;
-  background-color: #fff;
+  background-color: var(--surface-color);
   border: 1px solid #ccc;
   border: 1px solid rgba(0, 0, 0, 0.2);
   -webkit-border-radius: 8px;
@@ -405,6 +498,12 @@ body>nav.search .typeahead {
   margin: 0;
 }
 
+body>nav.toplevel>a.logo>img {
+    height: 1.5em;
+    margin-right: 10px;
+    vertical-align: middle;
+}
+
 body>nav.toplevel>ul {
     display: inline;
     list-style: none;
@@ -414,25 +513,34 @@ body>nav.toplevel>ul {
 
 body>nav.toplevel>ul>li {
     display: inline-block;
-    border: 1px solid #000000;
-    background-color: #FFFFFF;
+    border: 1px solid var(--navigation-color);
+    background-color: var(--surface-color);
     margin: -1px -5px 0 -2px;
     padding: 0px 10px;
     white-space: nowrap;
 }
 
 body>nav.toplevel>ul>li:last-child {
-    border-right: 1px solid #000000;
+    border-right: 1px solid var(--navigation-color);
     clear: both;
 }
 
 body>nav.toplevel>ul>li>a {
     font-family: "Helvetia", "Verdana", "Sans-Serif";
-    color: #000000;
+    color: var(--navigation-color);
 }
 
 body>nav.toplevel>ul>li>a:hover {
-    color: #06e;
+    color: var(--link-hover-color);
+}
+
+body>nav.toplevel>ul>li>a>.icon {
+    margin-right: 0.3em;
+}
+
+body>nav.toplevel>ul>li>a>img.icon {
+    height: 1em;
+    vertical-align: middle;
 }
 
 body>nav.breadcrumb {
@@ -499,8 +607,8 @@ article>.tags>ul>li {
 }
 
 article>.tags>ul>li>a {
-  color: #FFF;
-  background-color: #000;
+  color: var(--tag-color);
+  background-color: var(--tag-background-color);
   line-height: 1.0em;
   font-size: 1.0em;
   padding: 3px 6px;
@@ -548,30 +656,42 @@ aside.sidebar {
     width: 20%;
 }
 
-aside.sidebar>.children {
+aside.sidebar>.children,
+aside.sidebar>.backlinks,
+aside.sidebar>.related {
     margin: 0 0 15px 0;
 }
 
-aside.sidebar>.children>h1 {
+aside.sidebar>.children>h1,
+aside.sidebar>.backlinks>h1,
+aside.sidebar>.related>h1 {
     font-size: 1.5em;
 }
 
-aside.sidebar>.children>.list {
+aside.sidebar>.children>.list,
+aside.sidebar>.backlinks>.list,
+aside.sidebar>.related>.list {
     list-style: none;
     padding: 0;
     margin: 0;
 }
 
-aside.sidebar>.children>.list>.child {
+aside.sidebar>.children>.list>.child,
+aside.sidebar>.backlinks>.list>.backlink,
+aside.sidebar>.related>.list>.relateditem {
     margin: 0;
     margin: 0 0 1.2em 0;
 }
 
-aside.sidebar>.children>.list>.child:nth-child(odd) {
-    background-color:#eee;
+aside.sidebar>.children>.list>.child:nth-child(odd),
+aside.sidebar>.backlinks>.list>.backlink:nth-child(odd),
+aside.sidebar>.related>.list>.relateditem:nth-child(odd) {
+    background-color:var(--stripe-color);
 }
 
-aside.sidebar>.children>.list>.child:nth-child(even) {
+aside.sidebar>.children>.list>.child:nth-child(even),
+aside.sidebar>.backlinks>.list>.backlink:nth-child(even),
+aside.sidebar>.related>.list>.relateditem:nth-child(even) {
     background-color:transparent;
 }
 
@@ -690,7 +810,7 @@ article.presentation .content {
 
 article.presentation .slide {
     float: none;
-    box-shadow: 0 0 10px #000000;
+    box-shadow: 0 0 10px var(--slide-shadow-color);
     padding: 10px;
 }
 
@@ -761,30 +881,108 @@ article.presentation .slide {
     font-size: 1.0em;
 }
 
-.tagmap>.content>.tags {
+.tagmap>.content .tags {
     list-style-type: none;
 }
 
-.tagmap>.content>.tags>.tag {
+.tagmap>.content .tags>.tag {
     margin: 0 0 1.5em 0;
 }
 
-.tagmap>.content>.tags>.tag>a {
-    color: #FFF;
-    background-color: #000;
+.tagmap>.content .tags>.tag>.tags {
+    margin: 1em 0 0 0;
+}
+
+.tagmap>.content .tags>.tag>.count {
+    font-size: 0.9em;
+    opacity: 0.7;
+}
+
+.tagmap>.content .tags>.tag>a {
+    color: var(--tag-color);
+    background-color: var(--tag-background-color);
     line-height: 1.2em;
     font-size: 1.2em;
     padding: 3px 6px;
 }
 
-.tagmap>.content>.tags>.tag>.children {
+.tagmap>.content .tags>.tag>.children {
+    list-style-type: none;
+}
+
+.tagmap>.content .tags>.tag>.children>.child {
+    margin: 0.7em 0 0;
+}
+
+.recentchanges>.content>.day {
+    margin: 0 0 1.5em 0;
+}
+
+.recentchanges>.content>.day>h2 {
+    font-size: 1.2em;
+}
+
+.recentchanges>.content>.day>.children {
     list-style-type: none;
+    padding: 0;
 }
 
-.tagmap>.content>.tags>.tag>.children>.child {
+.recentchanges>.content>.day>.children>.child {
     margin: 0.7em 0 0;
 }
 
+.archive>.content>.calendar {
+    border-collapse: collapse;
+    margin: 0 0 1.5em 0;
+}
+
+.archive>.content>.calendar th,
+.archive>.content>.calendar td {
+    width: 2.5em;
+    padding: 0.3em;
+    text-align: center;
+}
+
+.archive>.content>.calendar td.has-items {
+    background-color: var(--stripe-color);
+    font-weight: bold;
+}
+
+.archive>.content>.pager {
+    margin: 0 0 1.5em 0;
+}
+
+.archive>.content>.pager>.next {
+    float: right;
+}
+
+.archive>.content>.year>.months,
+.archive>.content>.day>.children {
+    list-style-type: none;
+    padding: 0;
+}
+
+.archive>.content .count {
+    font-size: 0.9em;
+    opacity: 0.7;
+}
+
+.stats>.content table {
+    border-collapse: collapse;
+    margin: 0 0 1.5em 0;
+}
+
+.stats>.content th,
+.stats>.content td {
+    padding: 0.3em 1em 0.3em 0;
+    text-align: left;
+    font-weight: normal;
+}
+
+.stats>.content td {
+    text-align: right;
+}
+
 .aliasindex>.content>.shortlinks {
     margin: 10px 0 0 0;
 }
@@ -813,11 +1011,52 @@ article.presentation .slide {
     font-size: 1.1em;
 }
 
-.search>.content>ol>li>.description {
+.search>.content>ol>li>.file {
+    margin: 0 0 0 0.5em;
+    font-size: 0.9em;
+}
+
+.search>.content>ol>li>.file:before {
+    content: "📎 ";
+}
+
+.search>.content>ol>li>.description,
+.search>.content>ol>li>.snippet {
     margin: 0px;
     font-size: 1.0em;
 }
 
+.search>.content>ol>li>.snippet>mark {
+    font-style: normal;
+}
+
+.search>.content>.pager {
+    margin: 10px 0 10px 0;
+}
+
+.search>.content>.pager>.previous,
+.search>.content>.pager>.position {
+    margin: 0 1em 0 0;
+}
+
+.children>.pager,
+.tagmap>.content>.pager {
+    margin: 10px 0 10px 0;
+}
+
+.children>.pager>.previous,
+.children>.pager>.position,
+.tagmap>.content>.pager>.previous,
+.tagmap>.content>.pager>.position {
+    margin: 0 1em 0 0;
+}
+
+.tagmap>.content .tags .more {
+    display: block;
+    margin: 0 0 0.7em 0;
+    font-size: 0.9em;
+}
+
 .search>.content>ol>li>.path {
     margin: 0px;
     font-size: 0.8em;
@@ -827,6 +1066,16 @@ article.presentation .slide {
   display: none;
 }
 
+.color-scheme-toggle {
+    font: inherit;
+    font-size: 0.8em;
+    color: var(--navigation-color);
+    background: none;
+    border: 0;
+    padding: 0;
+    cursor: pointer;
+}
+
 @media only screen and (max-height: 500px) {
     body>article {
         min-height: 300px;
@@ -5,23 +5,21 @@
 package themefiles
 
 const SearchJs = `
-// data source: titles
-var titlesDataSource = new Bloodhound({
+// data source: titles, aliases and tags
+var suggestionsDataSource = new Bloodhound({
 	datumTokenizer: Bloodhound.tokenizers.obj.whitespace('value'),
 	queryTokenizer: Bloodhound.tokenizers.whitespace,
 	limit: 10,
-	prefetch: {
-		url: '/titles.json',
-	}
+	remote: getBasePath() + 'search/suggest?q=%QUERY'
 });
 
-titlesDataSource.initialize();
+suggestionsDataSource.initialize();
 
 // data source: search
 var searchDataSource = new Bloodhound({
 	datumTokenizer: Bloodhound.tokenizers.obj.whitespace('value'),
 	queryTokenizer: Bloodhound.tokenizers.whitespace,
-	remote: '/search.json?q=%QUERY'
+	remote: getBasePath() + 'search.json?q=%QUERY'
 });
 
 searchDataSource.initialize();
@@ -33,9 +31,9 @@ $('.typeahead').typeahead(
 		highlight: true,
 	},
 	{
-		name: 'item-titles',
+		name: 'suggestions',
 		displayKey: 'value',
-		source: titlesDataSource.ttAdapter(),
+		source: suggestionsDataSource.ttAdapter(),
 		templates: {
 			header: '<h3>Items</h3>'
 		}
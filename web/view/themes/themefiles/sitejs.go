@@ -5,6 +5,34 @@
 package themefiles
 
 const SiteJs = `
+/**
+ * getBasePath returns the path prefix under which the repository is served
+ * @return {string} The base path of the repository (e.g. "/", "/wiki/")
+ */
+function getBasePath() {
+	var basePath = $('meta[name="allmark-basepath"]').attr('content');
+	if (typeof(basePath) !== 'string' || basePath === '') {
+		return '/';
+	}
+
+	return basePath;
+}
+
+/**
+ * getRouteFromPath returns the repository route for the given url path
+ * @param {string} path The url path (e.g. "/wiki/documents/Sample-Document")
+ * @return {string} The repository route (e.g. "documents/Sample-Document")
+ */
+function getRouteFromPath(path) {
+	var basePath = getBasePath();
+	if (path.indexOf(basePath) === 0) {
+		return path.substring(basePath.length);
+	}
+
+	// remove leading slash
+	return path.replace(/^\//, "");
+}
+
 /**
  * appendStyleSheet adds the style sheet with the given path to the page
  * @param {string} path The style-sheet file path
@@ -28,6 +56,59 @@ function getAnchorNameFromText(text) {
 	return anchorName.replace(/[^\w\d-]/g, "");
 }
 
+/**
+ * colorScheme switches between the light and the dark colors of the theme. The selected
+ * scheme is stored in the local storage; without a selection the scheme preferred by the
+ * operating system is used.
+ */
+var colorScheme = {
+	storageKey: 'allmark-color-scheme',
+
+	/**
+	 * get returns the current color scheme
+	 * @return {string} "light" or "dark"
+	 */
+	get: function() {
+		var selectedScheme = document.documentElement.getAttribute('data-color-scheme');
+		if (selectedScheme === 'light' || selectedScheme === 'dark') {
+			return selectedScheme;
+		}
+
+		if (window.matchMedia && window.matchMedia('(prefers-color-scheme: dark)').matches) {
+			return 'dark';
+		}
+
+		return 'light';
+	},
+
+	/**
+	 * set selects and stores the given color scheme
+	 * @param {string} scheme "light" or "dark"
+	 */
+	set: function(scheme) {
+		document.documentElement.setAttribute('data-color-scheme', scheme);
+
+		try {
+			localStorage.setItem(colorScheme.storageKey, scheme);
+		} catch (e) {
+			// the selection is not kept if the local storage is not available
+		}
+	},
+
+	/**
+	 * toggle switches to the other color scheme
+	 */
+	toggle: function() {
+		colorScheme.set(colorScheme.get() === 'dark' ? 'light' : 'dark');
+	}
+};
+
+$(function() {
+	$('.color-scheme-toggle').on('click', function() {
+		colorScheme.toggle();
+	});
+});
+
 /**
  * addDeepLinksToElements adds anchor links to the elements with the given selector
  * @param {string} cssSelector A CSS-selector
@@ -0,0 +1,169 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+import (
+	"time"
+)
+
+// The view models of the REST API. Unlike the other view models, which are tailored to the
+// templates, the field names of the API models are part of the versioned API contract (v1):
+// fields may be added, but they are never renamed or removed.
+
+// APIItem contains the metadata of a repository item.
+type APIItem struct {
+	Route       string `json:"route"`
+	URL         string `json:"url"`
+	ParentRoute string `json:"parentRoute"`
+
+	Type        string `json:"type"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	Language  string `json:"language"`
+	Direction string `json:"direction"`
+
+	Author  string   `json:"author"`
+	Tags    []string `json:"tags"`
+	Aliases []string `json:"aliases"`
+
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+
+	Hash string `json:"hash"`
+}
+
+// APIItemDetails contains a repository item including its content, children and files.
+type APIItemDetails struct {
+	APIItem
+
+	Content  string `json:"content"`
+	Markdown string `json:"markdown"`
+
+	Children []APIItem `json:"children"`
+	Files    []APIFile `json:"files"`
+
+	// Related contains the items which share tags with the item or are linked together with it by other items.
+	Related []APIItem `json:"related"`
+
+	Location *APILocation `json:"location,omitempty"`
+}
+
+// APIFile contains the metadata of a file that is attached to an item.
+type APIFile struct {
+	Route string `json:"route"`
+	URL   string `json:"url"`
+	Name  string `json:"name"`
+
+	MimeType     string    `json:"mimeType"`
+	Hash         string    `json:"hash"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// APILocation contains the geo location of an item.
+type APILocation struct {
+	PlaceName string `json:"placeName"`
+	Street    string `json:"street"`
+	City      string `json:"city"`
+	Postcode  string `json:"postcode"`
+	Country   string `json:"country"`
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
+}
+
+// APIItemList is a page of items.
+type APIItemList struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+
+	Items []APIItem `json:"items"`
+}
+
+// APITreeNode is an item in the hierarchy of the repository.
+type APITreeNode struct {
+	Route string `json:"route"`
+	URL   string `json:"url"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+
+	Children []APITreeNode `json:"children"`
+}
+
+// APISearchHit is an item which matches a search query.
+type APISearchHit struct {
+	APIItem
+
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+
+	// HighlightedSnippet is the HTML-escaped snippet in which the matched terms are wrapped in <mark> elements.
+	HighlightedSnippet string `json:"highlightedSnippet"`
+
+	// File is the attached file whose text matched the query; it is nil if the item itself matched.
+	File *APIFile `json:"file,omitempty"`
+}
+
+// APISearchResults is a page of search hits.
+type APISearchResults struct {
+	Query string `json:"query"`
+
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	TotalHits  int `json:"totalHits"`
+	TotalPages int `json:"totalPages"`
+
+	// Offset is the (zero-based) position of the first hit of the page among all hits.
+	Offset int `json:"offset"`
+
+	// NextCursor is the continuation token for the next page (see the "cursor" parameter); it is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	Hits []APISearchHit `json:"hits"`
+}
+
+// APITag is a tag with the number of items that are tagged with it.
+type APITag struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	NumberOfItems int    `json:"numberOfItems"`
+}
+
+// APITagDetails is a tag with the items that are tagged with it.
+type APITagDetails struct {
+	APITag
+
+	Items []APIItem `json:"items"`
+}
+
+// APIGraph contains all items and the links between them.
+type APIGraph struct {
+	Nodes []APIGraphNode `json:"nodes"`
+	Links []APIGraphLink `json:"links"`
+}
+
+// APIGraphNode is an item in the link graph of the repository.
+type APIGraphNode struct {
+	Route string `json:"route"`
+	URL   string `json:"url"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+
+	OutgoingLinks int `json:"outgoingLinks"`
+	Backlinks     int `json:"backlinks"`
+}
+
+// APIGraphLink is a link from the item with the source route to the item with the target route.
+type APIGraphLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// APIError describes why an API request failed.
+type APIError struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
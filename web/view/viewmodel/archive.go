@@ -0,0 +1,70 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// Archive is the view model of the archive pages which list the items by the year and month of their creation.
+type Archive struct {
+	Model
+
+	// Year and Month are the period of the page; they are zero on the archive overview (and Month on the year pages).
+	Year  int `json:"year"`
+	Month int `json:"month"`
+
+	// Years contains all years and months with items; on the year and month pages only the year of the page.
+	Years []ArchiveYear `json:"years"`
+
+	// Days contains the items of a month page grouped by the day of their creation.
+	Days []ArchiveDay `json:"days"`
+
+	// Calendar contains the weeks of the month of a month page; Weekdays contains the names of the days of a week.
+	Calendar []ArchiveWeek `json:"calendar"`
+	Weekdays []string      `json:"weekdays"`
+
+	// Previous and Next are the adjacent periods which contain items; they are nil if there are none.
+	Previous *ArchiveMonth `json:"previous,omitempty"`
+	Next     *ArchiveMonth `json:"next,omitempty"`
+}
+
+// ArchiveYear is a year with items and the months of the year with items.
+type ArchiveYear struct {
+	Year          int            `json:"year"`
+	Route         string         `json:"route"`
+	NumberOfItems int            `json:"numberOfItems"`
+	Months        []ArchiveMonth `json:"months"`
+}
+
+// ArchiveMonth is a month with items.
+type ArchiveMonth struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+
+	// Date is the first day of the month (e.g. "2015-08-01").
+	Date string `json:"date"`
+
+	Route         string `json:"route"`
+	NumberOfItems int    `json:"numberOfItems"`
+}
+
+// ArchiveDay contains the items which have been created on a given day.
+type ArchiveDay struct {
+	// Date is the day of the creation (e.g. "2015-08-03").
+	Date string `json:"date"`
+
+	Items []Base `json:"items"`
+}
+
+// ArchiveWeek contains the seven days of a calendar week.
+type ArchiveWeek []ArchiveCalendarDay
+
+// ArchiveCalendarDay is a day of the calendar of a month page.
+type ArchiveCalendarDay struct {
+	// Day is the day of the month; it is zero for the days of a week which belong to the previous or next month.
+	Day int `json:"day"`
+
+	// Date is the day (e.g. "2015-08-03"); it is empty if the day does not belong to the month.
+	Date string `json:"date"`
+
+	NumberOfItems int `json:"numberOfItems"`
+}
@@ -25,6 +25,10 @@ type Base struct {
 	JSONURL     string `json:"jsonURL"`
 	MarkdownURL string `json:"markdownURL"`
 	DOCXURL     string `json:"docxURL"`
+	ODTURL      string `json:"odtURL"`
+	PDFURL      string `json:"pdfURL"`
+	EPUBURL     string `json:"epubURL"`
+	ZIPURL      string `json:"zipURL"`
 
 	PageTitle   string `json:"pageTitle"`
 	Title       string `json:"title"`
@@ -35,7 +39,25 @@ type Base struct {
 	CreationDate     string `json:"creationdate"`
 	LastModifiedDate string `json:"lastmodifieddate"`
 
+	// Locale is the language of the user interface labels; if empty the configured locale is used.
+	Locale string `json:"-"`
+
+	NoIndex bool `json:"noindex"`
+
+	// Template is the name of the template the item is rendered with; if empty the template of the item type is used.
+	Template string `json:"template,omitempty"`
+
+	// HeadSnippet and FooterSnippet are the names of the HTML snippets the item selects instead of the default snippets.
+	HeadSnippet   string `json:"-"`
+	FooterSnippet string `json:"-"`
+
+	// TagNames contains the names of the tags of the item; MetaData contains the values of all single-line meta
+	// data definitions of the item by their lowercase key.
+	TagNames []string          `json:"tagNames,omitempty"`
+	MetaData map[string]string `json:"metadata,omitempty"`
+
 	LiveReloadEnabled bool
+	WebAppEnabled     bool
 }
 
 type SortBaseModelBy func(model1, model2 Base) bool
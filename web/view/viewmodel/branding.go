@@ -0,0 +1,19 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// Branding contains the logo of the site and the favicons and touch icons which are linked from every page.
+type Branding struct {
+	Logo  string         `json:"logo,omitempty"`
+	Icons []BrandingIcon `json:"icons,omitempty"`
+}
+
+// BrandingIcon describes the link to one of the favicons or touch icons of the site.
+type BrandingIcon struct {
+	Rel   string `json:"rel"`   // e.g. "icon", "apple-touch-icon"
+	Type  string `json:"type"`  // e.g. "image/png"
+	Sizes string `json:"sizes"` // e.g. "32x32"
+	Path  string `json:"path"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+import (
+	"time"
+)
+
+// Calendar contains the events of a repository or of the subtree of an item.
+type Calendar struct {
+	Name        string
+	Description string
+	URL         string
+
+	Events []CalendarEvent
+}
+
+// CalendarEvent is an item which describes an event. The end of all-day events is the last day of the event.
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Categories  []string
+
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+
+	Location  string
+	Latitude  string
+	Longitude string
+
+	Created      time.Time
+	LastModified time.Time
+}
@@ -9,5 +9,27 @@ type ConversionModel struct {
 
 	Content string `json:"content"`
 
+	Author Author `json:"author"`
+
 	Files []File `json:"files"`
+
+	// CoverImage is the route of the primary image of the item (e.g. "docs/sample/files/cover.jpg").
+	CoverImage string `json:"coverImage,omitempty"`
+
+	// Sections contains the converted descendants of the item if the whole subtree
+	// has been requested (depth-first, in the order of the child listings).
+	Sections []ConversionSection `json:"sections,omitempty"`
+}
+
+// ConversionSection is a descendant of a converted item.
+type ConversionSection struct {
+	Base
+
+	Content string `json:"content"`
+
+	// Depth is the level of the section below the converted item (1 for its children).
+	Depth int `json:"depth"`
+
+	// Anchor is the id of the section in the converted document (e.g. "section-docs-sample").
+	Anchor string `json:"anchor"`
 }
@@ -0,0 +1,47 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// DebugVars is a snapshot of the runtime statistics of the server for diagnosing performance problems.
+type DebugVars struct {
+	Uptime     string `json:"uptime"`
+	GoVersion  string `json:"goVersion"`
+	Goroutines int    `json:"goroutines"`
+	CPUs       int    `json:"cpus"`
+
+	Memory DebugMemory `json:"memory"`
+
+	RepositoryIndex StatsIndex `json:"repositoryIndex"`
+	SearchIndex     StatsIndex `json:"searchIndex"`
+
+	Budget    DebugBudget     `json:"budget"`
+	HTMLCache *DebugHTMLCache `json:"htmlCache,omitempty"`
+}
+
+// DebugMemory contains the heap and garbage collection statistics of the runtime.
+type DebugMemory struct {
+	HeapAllocBytes             uint64 `json:"heapAllocBytes"`
+	HeapInUseBytes             uint64 `json:"heapInUseBytes"`
+	HeapObjects                uint64 `json:"heapObjects"`
+	SystemBytes                uint64 `json:"systemBytes"`
+	TotalAllocBytes            uint64 `json:"totalAllocBytes"`
+	NumberOfGCs                uint32 `json:"numberOfGCs"`
+	GCPauseTotalInMicroseconds uint64 `json:"gcPauseTotalInMicroseconds"`
+}
+
+// DebugBudget contains the usage of the budget of the background tasks.
+type DebugBudget struct {
+	MemoryBytes  int64 `json:"memoryBytes"`
+	Tasks        int   `json:"tasks"`
+	WaitingTasks int   `json:"waitingTasks"`
+}
+
+// DebugHTMLCache contains the size and the hit rate of the HTML cache.
+type DebugHTMLCache struct {
+	Entries   int    `json:"entries"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+}
@@ -4,6 +4,10 @@
 
 package viewmodel
 
+import (
+	"time"
+)
+
 type Feed struct {
 	FeedEntry
 	Items []FeedEntry
@@ -14,4 +18,85 @@ type FeedEntry struct {
 	Description string `json:"description"`
 	Link        string `json:"link"`
 	PubDate     string `json:"pubDate"`
+
+	Enclosure *FeedEnclosure `json:"enclosure,omitempty"`
+}
+
+// FeedEnclosure is a media file which is attached to a FeedEntry (e.g. the audio file of a podcast episode).
+type FeedEnclosure struct {
+	URL    string `json:"url"`
+	Length int64  `json:"length"`
+	Type   string `json:"type"`
+}
+
+// JSONFeedVersion is the URL of the JSON Feed version that is implemented by the JSONFeed model.
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed is a feed in the JSON Feed format (see https://www.jsonfeed.org/version/1.1/).
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	NextURL     string           `json:"next_url,omitempty"`
+	Favicon     string           `json:"favicon,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Authors     []JSONFeedAuthor `json:"authors,omitempty"`
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+// JSONFeedItem is an entry of a JSONFeed.
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html"`
+	Summary       string               `json:"summary,omitempty"`
+	DatePublished *time.Time           `json:"date_published,omitempty"`
+	DateModified  *time.Time           `json:"date_modified,omitempty"`
+	Authors       []JSONFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Language      string               `json:"language,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+// JSONFeedAuthor is the author of a JSONFeed or of a JSONFeedItem.
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// JSONFeedAttachment is a file which is attached to a JSONFeedItem.
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	Title       string `json:"title,omitempty"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+// AtomFeed is a feed in the Atom format (see https://tools.ietf.org/html/rfc4287).
+// All timestamps are formatted according to RFC 3339.
+type AtomFeed struct {
+	ID       string
+	Title    string
+	Subtitle string
+	Updated  string
+	Link     string
+	SelfLink string
+	NextLink string
+	Author   string
+	Entries  []AtomEntry
+}
+
+// AtomEntry is an entry of an AtomFeed.
+type AtomEntry struct {
+	ID         string
+	Title      string
+	Link       string
+	Summary    string
+	Content    string
+	Published  string
+	Updated    string
+	Author     string
+	Categories []string
 }
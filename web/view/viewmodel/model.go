@@ -19,6 +19,15 @@ type Model struct {
 
 	Children []Base `json:"children"`
 
+	// ChildrenPager describes the page of the children if they are split into pages.
+	ChildrenPager Pager `json:"childrenPager"`
+
+	// Backlinks contains the items which link to or reference the item.
+	Backlinks []Base `json:"backlinks"`
+
+	// Related contains the items which share tags with the item or are linked together with it by other items.
+	Related []Base `json:"related"`
+
 	ToplevelNavigation   ToplevelNavigation   `json:"toplevelNavigation"`
 	BreadcrumbNavigation BreadcrumbNavigation `json:"breadcrumbNavigation"`
 	ItemNavigation       ItemNavigation       `json:"itemNavigation"`
@@ -29,6 +38,15 @@ type Model struct {
 	Files  []File  `json:"files"`
 	Images []Image `json:"images"`
 
+	// PreviewImage is the path of the image which is displayed in the previews of shared links to the item
+	// (e.g. the largest thumbnail of its first image).
+	PreviewImage string `json:"previewImage,omitempty"`
+
+	// Stylesheets and Scripts contain the URLs of the custom.css and custom.js attachments
+	// of the item and its ancestors (the ones of the root first).
+	Stylesheets []string `json:"stylesheets,omitempty"`
+	Scripts     []string `json:"scripts,omitempty"`
+
 	GeoLocation GeoLocation `json:"geoLocation"`
 
 	Analytics Analytics `json:"-"`
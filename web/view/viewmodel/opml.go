@@ -0,0 +1,28 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// OPML represents an OPML 2.0 document (see http://opml.org/spec2.opml).
+type OPML struct {
+	Title        string
+	DateModified string
+	OwnerName    string
+	Outlines     []OPMLOutline
+}
+
+// OPMLOutline is an outline element of an OPML document. Outlines of the type "rss" reference a feed (XMLURL)
+// and the web page of the feed (HTMLURL); outlines of the type "link" reference a web page (URL).
+type OPMLOutline struct {
+	Text        string
+	Description string
+	Type        string
+	Created     string
+
+	URL     string
+	HTMLURL string
+	XMLURL  string
+
+	Children []OPMLOutline
+}
@@ -0,0 +1,15 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// Pager describes the position of a page in a listing which is split into pages.
+type Pager struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"totalPages"`
+
+	// PreviousURL and NextURL are the URLs of the adjacent pages; empty if there is no such page.
+	PreviousURL string `json:"previousURL,omitempty"`
+	NextURL     string `json:"nextURL,omitempty"`
+}
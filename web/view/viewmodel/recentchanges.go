@@ -0,0 +1,23 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// RecentChanges is the view model of the page which lists the most recently modified items.
+type RecentChanges struct {
+	Model
+
+	// FeedURL is the URL of the feed of the recent changes.
+	FeedURL string `json:"feedURL"`
+
+	Days []RecentChangesDay `json:"days"`
+}
+
+// RecentChangesDay contains the items which have been modified on a given day.
+type RecentChangesDay struct {
+	// Date is the day of the modifications (e.g. "2015-08-03").
+	Date string `json:"date"`
+
+	Items []Base `json:"items"`
+}
@@ -4,14 +4,15 @@
 
 package viewmodel
 
-// RobotsTxtDisallow is the definition of disallow-section of a robots.txt
-type RobotsTxtDisallow struct {
+// RobotsTxtGroup is the definition of a user-agent section of a robots.txt
+type RobotsTxtGroup struct {
 	UserAgent string
-	Paths     []string
+	Allows    []string
+	Disallows []string
 }
 
 // RobotsTxt represents the content of a robots.txt file
 type RobotsTxt struct {
-	Disallows  []RobotsTxtDisallow
+	Groups     []RobotsTxtGroup
 	SitemapURL string
 }
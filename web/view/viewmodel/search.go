@@ -15,6 +15,11 @@ type SearchResults struct {
 
 	Page         int `json:"page"`
 	ItemsPerPage int `json:"itemPerPage"`
+	TotalPages   int `json:"totalPages"`
+
+	// PreviousPage and NextPage are the numbers of the adjacent pages; 0 if there is no such page.
+	PreviousPage int `json:"previousPage"`
+	NextPage     int `json:"nextPage"`
 
 	StartIndex       int `json:"startIndex"`
 	ResultCount      int `json:"resultCount"`
@@ -28,4 +33,11 @@ type SearchResult struct {
 	Description string `json:"description"`
 	Route       string `json:"route"`
 	Path        string `json:"path"`
+
+	// Snippet is an HTML excerpt of the item in which the matched terms are highlighted.
+	Snippet string `json:"snippet"`
+
+	// File and FileRoute are the name and the location of the attached file whose text matched the query (if any).
+	File      string `json:"file,omitempty"`
+	FileRoute string `json:"fileRoute,omitempty"`
 }
@@ -0,0 +1,15 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// SearchIndexEntry contains the searchable texts of an item (e.g. for the client-side search of an exported site).
+type SearchIndexEntry struct {
+	Route       string   `json:"route"`
+	Type        string   `json:"type"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Content     string   `json:"content"`
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// StatsPage is the view model of the page which displays the statistics of the repository.
+type StatsPage struct {
+	Model
+
+	// JSONURL is the URL of the statistics in JSON format.
+	JSONURL string `json:"jsonURL"`
+
+	Stats Stats `json:"stats"`
+}
+
+// Stats contains statistics about the items and files of the repository.
+type Stats struct {
+	NumberOfItems int         `json:"numberOfItems"`
+	ItemTypes     []StatsType `json:"itemTypes"`
+	NumberOfWords int         `json:"numberOfWords"`
+	Tags          []StatsTag  `json:"tags"`
+	Files         StatsFiles  `json:"files"`
+	LastIndexRun  StatsIndex  `json:"lastIndexRun"`
+}
+
+// StatsType contains the number of items of a given type (e.g. "document").
+type StatsType struct {
+	Name          string `json:"name"`
+	NumberOfItems int    `json:"numberOfItems"`
+}
+
+// StatsTag contains the number of items which have a given tag.
+type StatsTag struct {
+	Name          string `json:"name"`
+	NumberOfItems int    `json:"numberOfItems"`
+}
+
+// StatsFiles contains the number and the sizes of the files attached to the items.
+type StatsFiles struct {
+	NumberOfFiles int             `json:"numberOfFiles"`
+	SizeInBytes   int64           `json:"sizeInBytes"`
+	Size          string          `json:"size"`
+	MimeTypes     []StatsMimeType `json:"mimeTypes"`
+}
+
+// StatsMimeType contains the number and the total size of the files of a given mime type.
+type StatsMimeType struct {
+	MimeType      string `json:"mimeType"`
+	NumberOfFiles int    `json:"numberOfFiles"`
+	SizeInBytes   int64  `json:"sizeInBytes"`
+	Size          string `json:"size"`
+}
+
+// StatsIndex describes the last index run.
+type StatsIndex struct {
+	DurationInMilliseconds int64 `json:"durationInMilliseconds"`
+
+	// Date is the time at which the index run finished (RFC 3339); it is empty if the items have not been indexed yet.
+	Date string `json:"date,omitempty"`
+}
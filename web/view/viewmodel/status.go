@@ -0,0 +1,39 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// Status describes the state of the server and its background services.
+type Status struct {
+	Status     string          `json:"status"`
+	StartTime  string          `json:"startTime"`
+	Uptime     string          `json:"uptime"`
+	ConfigHash string          `json:"configHash"`
+	Items      int             `json:"items"`
+	Services   []ServiceStatus `json:"services"`
+
+	// Progress contains the progress of the last run of the long-running tasks (e.g. the parsing of the items).
+	Progress []ProgressStatus `json:"progress,omitempty"`
+}
+
+// ServiceStatus describes the state of a single background service (e.g. the search index).
+type ServiceStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Details string `json:"details,omitempty"`
+}
+
+// ProgressStatus describes the progress of a long-running task (e.g. the parsing of the items).
+type ProgressStatus struct {
+	Name  string `json:"name"`
+	Unit  string `json:"unit"`
+	Count int    `json:"count"`
+
+	// Total is the number of units which are processed by the task; it is omitted if the total is not known.
+	Total int `json:"total,omitempty"`
+
+	RatePerSecond          float64 `json:"ratePerSecond"`
+	DurationInMilliseconds int64   `json:"durationInMilliseconds"`
+	Finished               bool    `json:"finished"`
+}
@@ -11,6 +11,9 @@ import (
 type Tags struct {
 	Model
 	Tags []Tag
+
+	// Pager describes the page of the items if only a single tag is displayed.
+	Pager Pager
 }
 
 type Tag struct {
@@ -18,6 +21,18 @@ type Tag struct {
 	Anchor   string  `json:"anchor"`
 	Route    string  `json:"route"`
 	Children []Model `json:"children"`
+
+	// Label is the last component of hierarchical tag names (e.g. "alpha" for "project/alpha").
+	Label string `json:"label"`
+
+	// NumberOfChildren is the number of items which are tagged with the tag or one of its sub tags.
+	NumberOfChildren int `json:"numberofchildren"`
+
+	// SubTags contains the tags below the tag in the tag hierarchy (e.g. "project/alpha" for "project").
+	SubTags []Tag `json:"subtags"`
+
+	// MoreURL is the URL of the page with all items of the tag if only some of them are listed.
+	MoreURL string `json:"moreURL,omitempty"`
 }
 
 type SortTagBy func(tag1, tag2 Tag) bool
@@ -15,4 +15,11 @@ func (navigation *ToplevelNavigation) IsAvailable() bool {
 type ToplevelEntry struct {
 	Title string `json:"title"`
 	Path  string `json:"path"`
+
+	// Icon is a symbol (e.g. "★") and IconURL the url of an image which is displayed in front of the title.
+	Icon    string `json:"icon,omitempty"`
+	IconURL string `json:"iconUrl,omitempty"`
+
+	// IsExternal indicates that the entry links to a page outside of the repository.
+	IsExternal bool `json:"isExternal,omitempty"`
 }
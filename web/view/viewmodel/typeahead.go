@@ -15,3 +15,13 @@ type TypeAhead struct {
 	Value  string   `json:"value"`
 	Tokens []string `json:"tokens"`
 }
+
+// Suggestion is a title, alias or tag which completes the text that has been typed into the search box.
+type Suggestion struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+
+	// Route is the URL of the item (titles and aliases) or of the tag page (tags).
+	Route string `json:"route"`
+	Path  string `json:"path,omitempty"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Andreas Koch. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package viewmodel
+
+// WebAppManifest contains the web app manifest of the site which makes the site installable (see https://www.w3.org/TR/appmanifest/).
+type WebAppManifest struct {
+	Name            string       `json:"name"`
+	ShortName       string       `json:"short_name"`
+	Description     string       `json:"description,omitempty"`
+	Language        string       `json:"lang,omitempty"`
+	Direction       string       `json:"dir,omitempty"`
+	StartURL        string       `json:"start_url"`
+	Scope           string       `json:"scope"`
+	Display         string       `json:"display"`
+	ThemeColor      string       `json:"theme_color,omitempty"`
+	BackgroundColor string       `json:"background_color,omitempty"`
+	Icons           []WebAppIcon `json:"icons,omitempty"`
+}
+
+// WebAppIcon describes one of the icons of the installed app.
+type WebAppIcon struct {
+	Source string `json:"src"`
+	Sizes  string `json:"sizes"` // e.g. "192x192"
+	Type   string `json:"type"`  // e.g. "image/png"
+}
+
+// ServiceWorker contains the URLs which the service worker of the site caches when it is installed.
+type ServiceWorker struct {
+	// CacheName is the name of the cache of the service worker; it changes whenever the cached URLs change.
+	CacheName string
+
+	// StartURL is the page which is displayed for pages that are neither cached nor available.
+	StartURL string
+
+	PrecacheURLs []string
+}
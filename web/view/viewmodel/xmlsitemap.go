@@ -15,5 +15,17 @@ type XmlSitemapEntry struct {
 }
 
 type XmlSitemapEntryImage struct {
-	Loc string `json:"image:loc"`
+	Loc   string `json:"image:loc"`
+	Title string `json:"image:title"`
+}
+
+// XMLSitemapIndex lists the sitemaps of a repository whose sitemap is too large to be served as a single file.
+type XMLSitemapIndex struct {
+	Sitemaps []XMLSitemapIndexEntry
+}
+
+// XMLSitemapIndexEntry is a sitemap which is listed in an XMLSitemapIndex.
+type XMLSitemapIndexEntry struct {
+	Loc          string `json:"loc"`
+	LastModified string `json:"lastModified"`
 }